@@ -0,0 +1,61 @@
+package accrual
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/journal"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+const defaultChunkSize = 500
+
+type (
+	accrualService struct {
+		loanRepository    repository.LoanRepository
+		productRepository repository.ProductRepository
+		journalRepository repository.JournalRepository
+		transaction       repository.Transaction
+		generate          common.Generate
+		chart             *journal.Chart
+		chunkSize         int
+	}
+
+	// Service accrues the charges and the income of the installments for the business date, it is run by the end of day.
+	// Both are safe to run again for the same date, what has been accrued is skipped.
+	Service interface {
+		// AccruePenalty charges the penalty of the date of asOf into every OVERDUE installment issued by the product
+		// with the penalty rate : the rate of the installment amount (excluding the penalty) per day, up to the penalty cap
+		// of the product per installment. The penalty is booked at asOf. It returns the number of charged installments.
+		// The installment within the grace period of the product is not OVERDUE yet, so it isn't charged.
+		AccruePenalty(ctx context.Context, asOf time.Time) (int, error)
+
+		// AccrueInterest recognizes the interest and the fee of the PENDING installments due on the date of asOf as income,
+		// booked at asOf. It returns the number of accrued installments.
+		AccrueInterest(ctx context.Context, asOf time.Time) (int, error)
+	}
+)
+
+func NewAccrualService(
+	cfg configuration.Configuration,
+	loanRepository repository.LoanRepository,
+	productRepository repository.ProductRepository,
+	journalRepository repository.JournalRepository,
+	transaction repository.Transaction) Service {
+	chunkSize := int(cfg.GetInt("accrual.chunk"))
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	return &accrualService{
+		loanRepository:    loanRepository,
+		productRepository: productRepository,
+		journalRepository: journalRepository,
+		transaction:       transaction,
+		generate:          common.NewGenerate(),
+		chart:             journal.NewChart(cfg),
+		chunkSize:         chunkSize,
+	}
+}
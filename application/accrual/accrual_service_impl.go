@@ -0,0 +1,237 @@
+package accrual
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/journal"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/product"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+var errorFromDatabase = errors.New("from database")
+
+func (a *accrualService) AccruePenalty(ctx context.Context, asOf time.Time) (int, error) {
+	businessDate := common.StartOfDay(asOf)
+
+	charged := 0
+	afterID := uint64(0)
+	for {
+		loans, err := a.loanRepository.FindLoans(
+			ctx, &repository.LoanEntity{
+				Statuses: []repository.LoanStatus{repository.LoanOverdue},
+				Limit:    a.chunkSize,
+				AfterID:  afterID,
+			},
+		)
+
+		if err != nil && !errors.Is(err, repository.ErrorNoRows) {
+			return charged, errorFromDatabase
+		}
+
+		if len(loans) == 0 {
+			return charged, nil
+		}
+
+		afterID = loans[len(loans)-1].ID
+
+		products, err := a.findProducts(ctx, loans)
+		if err != nil {
+			return charged, err
+		}
+
+		chargedChunk, err := a.chargeChunk(ctx, loans, products, businessDate, asOf)
+		if err != nil {
+			log.Println("failed accrue penalty -> ", err)
+			return charged, errorFromDatabase
+		}
+
+		charged += chargedChunk
+		if len(loans) < a.chunkSize {
+			return charged, nil
+		}
+	}
+}
+
+func (a *accrualService) AccrueInterest(ctx context.Context, asOf time.Time) (int, error) {
+	dueBefore := common.StartOfDay(asOf).AddDate(0, 0, 1)
+
+	accrued := 0
+	afterID := uint64(0)
+	for {
+		loans, err := a.loanRepository.FindLoans(
+			ctx, &repository.LoanEntity{
+				Statuses: []repository.LoanStatus{repository.LoanPending},
+				DueDate:  dueBefore,
+				Limit:    a.chunkSize,
+				AfterID:  afterID,
+			},
+		)
+
+		if err != nil && !errors.Is(err, repository.ErrorNoRows) {
+			return accrued, errorFromDatabase
+		}
+
+		if len(loans) == 0 {
+			return accrued, nil
+		}
+
+		afterID = loans[len(loans)-1].ID
+
+		entries, err := a.buildAccruals(ctx, loans, asOf)
+		if err != nil {
+			return accrued, err
+		}
+
+		if len(entries) > 0 {
+			errTx := a.transaction.WithTransaction(
+				ctx, func(tx *sql.Tx) error {
+					return a.journalRepository.SaveEntries(ctx, tx, entries...)
+				},
+			)
+
+			if errTx != nil {
+				log.Println("failed accrue interest -> ", errTx)
+				return accrued, errorFromDatabase
+			}
+		}
+
+		accrued += len(entries)
+		if len(loans) < a.chunkSize {
+			return accrued, nil
+		}
+	}
+}
+
+// chargeChunk charges the chunk in one transaction, the installment which is paid meanwhile or charged for the date
+// already is skipped together with its entry.
+func (a *accrualService) chargeChunk(
+	ctx context.Context,
+	loans []*repository.LoanEntity,
+	products map[uint64]*repository.ProductEntity,
+	businessDate time.Time,
+	bookedAt time.Time) (int, error) {
+	charged := 0
+
+	errTx := a.transaction.WithTransaction(
+		ctx, func(tx *sql.Tx) error {
+			charged = 0
+
+			var entries []*repository.JournalEntryEntity
+			for _, loan := range loans {
+				amount := penaltyOf(loan, products[loan.ProductID])
+				if !amount.IsPositive() {
+					continue
+				}
+
+				errAccrue := a.loanRepository.AccruePenalty(
+					ctx, tx, &repository.LoanPenaltyAccrual{
+						ID:        loan.ID,
+						Amount:    amount,
+						AccruedOn: businessDate,
+					},
+				)
+
+				if errors.Is(errAccrue, repository.ErrorNoRows) {
+					continue
+				}
+
+				if errAccrue != nil {
+					return errAccrue
+				}
+
+				entries = append(
+					entries, a.chart.PenaltyCharge(a.generate.Uuid(), loan, amount, businessDate, bookedAt),
+				)
+				charged += 1
+			}
+
+			if len(entries) == 0 {
+				return nil
+			}
+
+			return a.journalRepository.SaveEntries(ctx, tx, entries...)
+		},
+	)
+
+	return charged, errTx
+}
+
+// buildAccruals builds the accrual entries of the installments which are not accrued yet.
+func (a *accrualService) buildAccruals(
+	ctx context.Context,
+	loans []*repository.LoanEntity,
+	bookedAt time.Time) ([]*repository.JournalEntryEntity, error) {
+	references := make([]string, 0, len(loans))
+	for _, loan := range loans {
+		references = append(references, journal.Reference(loan.ID))
+	}
+
+	booked, err := a.journalRepository.FindEntries(
+		ctx, &repository.JournalEntryFilter{
+			EntryType:  string(journal.Accrual),
+			References: references,
+		},
+	)
+
+	if err != nil {
+		log.Println("failed find accrual entries -> ", err)
+		return nil, errorFromDatabase
+	}
+
+	accrued := make(map[string]bool)
+	for _, entry := range booked {
+		accrued[entry.Reference] = true
+	}
+
+	var entries []*repository.JournalEntryEntity
+	for _, loan := range loans {
+		if accrued[journal.Reference(loan.ID)] {
+			continue
+		}
+
+		if entry := a.chart.Accrual(a.generate.Uuid(), loan, bookedAt); entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+func (a *accrualService) findProducts(
+	ctx context.Context,
+	loans []*repository.LoanEntity) (map[uint64]*repository.ProductEntity, error) {
+	products, err := product.FindProductsOf(ctx, a.productRepository, loans)
+	if err != nil {
+		log.Println("failed find products -> ", err)
+		return nil, errorFromDatabase
+	}
+
+	return products, nil
+}
+
+// penaltyOf is the penalty of one day, the installment without the product (issued before the catalogue)
+// isn't charged. The zero cap means the penalty isn't capped.
+func penaltyOf(loan *repository.LoanEntity, product *repository.ProductEntity) decimal.Decimal {
+	if product == nil || !product.PenaltyRate.IsPositive() {
+		return decimal.Zero
+	}
+
+	base := loan.Breakdown.Total().Sub(loan.Breakdown.Penalty)
+	amount := base.Mul(product.PenaltyRate).RoundDown(2)
+
+	if product.PenaltyCap.IsPositive() {
+		remaining := product.PenaltyCap.Sub(loan.Breakdown.Penalty)
+		if amount.GreaterThan(remaining) {
+			amount = remaining
+		}
+	}
+
+	return amount
+}
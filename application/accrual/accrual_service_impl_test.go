@@ -0,0 +1,320 @@
+package accrual
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/journal"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	mocksCommon "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/common"
+	mocksConfiguration "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/configuration"
+	mocksRepository "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/infrastructure/repository"
+)
+
+func newTestService(
+	mockLoanRepo *mocksRepository.LoanRepository,
+	mockProductRepo *mocksRepository.ProductRepository,
+	mockJournalRepo *mocksRepository.JournalRepository,
+	mockTransaction *mocksRepository.Transaction) *accrualService {
+	mockCfg := &mocksConfiguration.Configuration{}
+	mockCfg.On("GetString", mock.Anything).Return("")
+	mockGenerate := &mocksCommon.Generate{}
+	mockGenerate.On("Uuid").Return("entry-id")
+
+	return &accrualService{
+		loanRepository:    mockLoanRepo,
+		productRepository: mockProductRepo,
+		journalRepository: mockJournalRepo,
+		transaction:       mockTransaction,
+		generate:          mockGenerate,
+		chart:             journal.NewChart(mockCfg),
+		chunkSize:         4,
+	}
+}
+
+func newTestLoan(id uint64, status repository.LoanStatus, productID uint64, penalty float64) *repository.LoanEntity {
+	breakdown := repository.Breakdown{
+		Principal: decimal.NewFromFloat(100000),
+		Interest:  decimal.NewFromFloat(10000),
+		Penalty:   decimal.NewFromFloat(penalty),
+	}
+
+	return &repository.LoanEntity{
+		ID: id, Status: status, UserID: "abc", Amount: breakdown.Total(), Breakdown: breakdown, ProductID: productID,
+	}
+}
+
+func Test_accrualService_AccruePenalty(t *testing.T) {
+	asOf := time.Date(2026, 10, 17, 23, 59, 59, 0, time.UTC)
+	businessDate := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+	loans := []*repository.LoanEntity{
+		newTestLoan(1, repository.LoanOverdue, 7, 0),
+		newTestLoan(2, repository.LoanOverdue, 7, 49500),
+		newTestLoan(3, repository.LoanOverdue, 0, 0),
+	}
+
+	filter := &repository.LoanEntity{Statuses: []repository.LoanStatus{repository.LoanOverdue}, Limit: 4}
+	product := &repository.ProductEntity{
+		ID: 7, PenaltyRate: decimal.NewFromFloat(0.01), PenaltyCap: decimal.NewFromFloat(50000),
+	}
+
+	accrual := func(id uint64, amount float64) interface{} {
+		return mock.MatchedBy(
+			func(accrual *repository.LoanPenaltyAccrual) bool {
+				return accrual.ID == id && accrual.Amount.Equal(decimal.NewFromFloat(amount)) &&
+					accrual.AccruedOn.Equal(businessDate)
+			},
+		)
+	}
+
+	withTransaction := func(ctx context.Context, fn func(tx *sql.Tx) error) error {
+		return fn(nil)
+	}
+
+	tests := []struct {
+		name     string
+		want     int
+		wantErr  error
+		mockFunc func(
+			mockLoanRepo *mocksRepository.LoanRepository,
+			mockJournalRepo *mocksRepository.JournalRepository)
+	}{
+		{
+			name: "given the overdue installments," +
+				"when accruePenalty," +
+				"then the penalty of the date is charged up to the cap and booked",
+			want: 2,
+			mockFunc: func(
+				mockLoanRepo *mocksRepository.LoanRepository,
+				mockJournalRepo *mocksRepository.JournalRepository) {
+				mockLoanRepo.
+					On("AccruePenalty", mock.Anything, mock.Anything, accrual(1, 1100)).
+					Return(nil).
+					Once()
+
+				mockLoanRepo.
+					On("AccruePenalty", mock.Anything, mock.Anything, accrual(2, 500)).
+					Return(nil).
+					Once()
+
+				mockJournalRepo.
+					On("SaveEntries", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given the penalty of the date has been charged," +
+				"when accruePenalty again," +
+				"then the installment is skipped",
+			want: 1,
+			mockFunc: func(
+				mockLoanRepo *mocksRepository.LoanRepository,
+				mockJournalRepo *mocksRepository.JournalRepository) {
+				mockLoanRepo.
+					On("AccruePenalty", mock.Anything, mock.Anything, accrual(1, 1100)).
+					Return(repository.ErrorNoRows).
+					Once()
+
+				mockLoanRepo.
+					On("AccruePenalty", mock.Anything, mock.Anything, accrual(2, 500)).
+					Return(nil).
+					Once()
+
+				mockJournalRepo.
+					On("SaveEntries", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given accrue penalty is failed," +
+				"when accruePenalty," +
+				"then return error",
+			wantErr: errorFromDatabase,
+			mockFunc: func(
+				mockLoanRepo *mocksRepository.LoanRepository,
+				mockJournalRepo *mocksRepository.JournalRepository) {
+				mockLoanRepo.
+					On("AccruePenalty", mock.Anything, mock.Anything, accrual(1, 1100)).
+					Return(repository.ErrorFromDBLoan).
+					Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockLoanRepo := &mocksRepository.LoanRepository{}
+				mockProductRepo := &mocksRepository.ProductRepository{}
+				mockJournalRepo := &mocksRepository.JournalRepository{}
+				mockTransaction := &mocksRepository.Transaction{}
+
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, filter).
+					Return(loans, nil).
+					Once()
+
+				mockProductRepo.
+					On("FindProducts", mock.Anything, &repository.ProductFilter{IDs: []uint64{7}}).
+					Return([]*repository.ProductEntity{product}, nil).
+					Once()
+
+				mockTransaction.
+					On("WithTransaction", mock.Anything, mock.Anything).
+					Return(withTransaction).
+					Once()
+
+				tt.mockFunc(mockLoanRepo, mockJournalRepo)
+
+				a := newTestService(mockLoanRepo, mockProductRepo, mockJournalRepo, mockTransaction)
+				got, err := a.AccruePenalty(context.Background(), asOf)
+
+				assert.Equal(t, tt.want, got)
+				assert.Equal(t, tt.wantErr, err)
+				mockLoanRepo.AssertExpectations(t)
+				mockJournalRepo.AssertExpectations(t)
+			})
+	}
+}
+
+func Test_accrualService_AccruePenalty_findLoansFailed(t *testing.T) {
+	mockLoanRepo := &mocksRepository.LoanRepository{}
+	mockLoanRepo.
+		On("FindLoans", mock.Anything, mock.Anything).
+		Return(nil, errors.New("mock error")).
+		Once()
+
+	a := newTestService(
+		mockLoanRepo, &mocksRepository.ProductRepository{}, &mocksRepository.JournalRepository{},
+		&mocksRepository.Transaction{},
+	)
+
+	got, err := a.AccruePenalty(context.Background(), time.Now())
+
+	assert.Equal(t, 0, got)
+	assert.Equal(t, errorFromDatabase, err)
+}
+
+func Test_accrualService_AccrueInterest(t *testing.T) {
+	asOf := time.Date(2026, 10, 17, 23, 59, 59, 0, time.UTC)
+
+	loans := []*repository.LoanEntity{
+		newTestLoan(1, repository.LoanPending, 7, 0),
+		newTestLoan(2, repository.LoanPending, 7, 0),
+	}
+
+	filter := &repository.LoanEntity{
+		Statuses: []repository.LoanStatus{repository.LoanPending},
+		DueDate:  time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		Limit:    4,
+	}
+
+	entryFilter := &repository.JournalEntryFilter{EntryType: "ACCRUAL", References: []string{"1", "2"}}
+
+	withTransaction := func(ctx context.Context, fn func(tx *sql.Tx) error) error {
+		return fn(nil)
+	}
+
+	tests := []struct {
+		name     string
+		want     int
+		wantErr  error
+		mockFunc func(
+			mockJournalRepo *mocksRepository.JournalRepository,
+			mockTransaction *mocksRepository.Transaction)
+	}{
+		{
+			name: "given the installments due on the date," +
+				"when accrueInterest," +
+				"then the installments not yet accrued are accrued",
+			want: 1,
+			mockFunc: func(
+				mockJournalRepo *mocksRepository.JournalRepository,
+				mockTransaction *mocksRepository.Transaction) {
+				mockJournalRepo.
+					On("FindEntries", mock.Anything, entryFilter).
+					Return([]*repository.JournalEntryEntity{{EntryType: "ACCRUAL", Reference: "1"}}, nil).
+					Once()
+
+				mockTransaction.
+					On("WithTransaction", mock.Anything, mock.Anything).
+					Return(withTransaction).
+					Once()
+
+				mockJournalRepo.
+					On(
+						"SaveEntries", mock.Anything, mock.Anything,
+						mock.MatchedBy(
+							func(entry *repository.JournalEntryEntity) bool {
+								return entry.Reference == "2" && entry.BookedAt.Equal(asOf)
+							},
+						),
+					).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given the installments have been accrued," +
+				"when accrueInterest," +
+				"then nothing is booked",
+			mockFunc: func(
+				mockJournalRepo *mocksRepository.JournalRepository,
+				mockTransaction *mocksRepository.Transaction) {
+				mockJournalRepo.
+					On("FindEntries", mock.Anything, entryFilter).
+					Return([]*repository.JournalEntryEntity{{Reference: "1"}, {Reference: "2"}}, nil).
+					Once()
+			},
+		},
+		{
+			name: "given find entries is failed," +
+				"when accrueInterest," +
+				"then return error",
+			wantErr: errorFromDatabase,
+			mockFunc: func(
+				mockJournalRepo *mocksRepository.JournalRepository,
+				mockTransaction *mocksRepository.Transaction) {
+				mockJournalRepo.
+					On("FindEntries", mock.Anything, entryFilter).
+					Return(nil, repository.ErrorFromDBLoan).
+					Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockLoanRepo := &mocksRepository.LoanRepository{}
+				mockJournalRepo := &mocksRepository.JournalRepository{}
+				mockTransaction := &mocksRepository.Transaction{}
+
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, filter).
+					Return(loans, nil).
+					Once()
+
+				tt.mockFunc(mockJournalRepo, mockTransaction)
+
+				a := newTestService(mockLoanRepo, &mocksRepository.ProductRepository{}, mockJournalRepo, mockTransaction)
+				got, err := a.AccrueInterest(context.Background(), asOf)
+
+				assert.Equal(t, tt.want, got)
+				assert.Equal(t, tt.wantErr, err)
+				mockLoanRepo.AssertExpectations(t)
+				mockJournalRepo.AssertExpectations(t)
+				mockTransaction.AssertExpectations(t)
+			})
+	}
+}
@@ -0,0 +1,230 @@
+package delinquency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/product"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/calendar"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+const (
+	// MissedTolerance is the missed installments tolerated before the customer is delinquent for the loans
+	// issued before the catalogue, the others by the delinquency threshold of their product.
+	// The restructured schedule is already the concession, so it isn't tolerated.
+	MissedTolerance             = 2
+	RestructuredMissedTolerance = 0
+)
+
+type (
+	// Assessment is the delinquency of the customer from the due installments.
+	Assessment struct {
+		UserID       string
+		IsDelinquent bool
+		// UnpaidInstallments is the due unpaid installments, including the ones which are not late yet.
+		UnpaidInstallments   int
+		RemainingOutstanding decimal.Decimal
+		Breakdown            repository.Breakdown
+		// GraceOutstanding is the part of the remaining outstanding which is due but not late yet.
+		GraceOutstanding    decimal.Decimal
+		GraceInstallmentIDs []uint64
+	}
+
+	// Assessor assesses the customer from the installments stored, the write which is not committed yet
+	// tells its changes to be assessed as if they were.
+	Assessor struct {
+		loanRepository    repository.LoanRepository
+		productRepository repository.ProductRepository
+		calendar          calendar.Calendar
+	}
+)
+
+// DueStatuses is the statuses of the due installments assessed.
+var DueStatuses = []repository.LoanStatus{
+	repository.LoanPending, repository.LoanOverdue, repository.LoanClosed, repository.LoanWrittenOff,
+}
+
+func NewAssessor(
+	loanRepository repository.LoanRepository,
+	productRepository repository.ProductRepository,
+	calendar calendar.Calendar) *Assessor {
+	return &Assessor{
+		loanRepository:    loanRepository,
+		productRepository: productRepository,
+		calendar:          calendar,
+	}
+}
+
+// Assess assesses the installments of the customer due before now. The changed installments replace the stored
+// ones of the same id, the ones not stored in DueStatuses (e.g. the reopened) are added.
+func (a *Assessor) Assess(
+	ctx context.Context,
+	userID string,
+	now time.Time,
+	changed ...*repository.LoanEntity) (*Assessment, error) {
+	loans, err := a.loanRepository.FindLoans(
+		ctx, &repository.LoanEntity{
+			Statuses: DueStatuses,
+			UserID:   userID,
+			DueDate:  now,
+		},
+	)
+
+	if err != nil && !errors.Is(err, repository.ErrorNoRows) {
+		return nil, err
+	}
+
+	replaced := make(map[uint64]*repository.LoanEntity)
+	for _, loan := range changed {
+		replaced[loan.ID] = loan
+	}
+
+	due := make([]*repository.LoanEntity, 0, len(loans)+len(changed))
+	for _, loan := range loans {
+		if change, ok := replaced[loan.ID]; ok {
+			delete(replaced, loan.ID)
+			due = append(due, change)
+			continue
+		}
+
+		due = append(due, loan)
+	}
+
+	for _, loan := range changed {
+		if _, ok := replaced[loan.ID]; ok {
+			due = append(due, loan)
+		}
+	}
+
+	return a.AssessLoans(ctx, userID, due, now)
+}
+
+// AssessLoans assesses the installments of the customer loaded by the caller, the ones due from now are ignored.
+func (a *Assessor) AssessLoans(
+	ctx context.Context,
+	userID string,
+	loans []*repository.LoanEntity,
+	now time.Time) (*Assessment, error) {
+	var due []*repository.LoanEntity
+	for _, loan := range loans {
+		if loan.DueDate.Before(now) {
+			due = append(due, loan)
+		}
+	}
+
+	products, err := product.FindProductsOf(ctx, a.productRepository, due)
+	if err != nil {
+		return nil, err
+	}
+
+	return Evaluate(userID, due, products, a.calendar, now), nil
+}
+
+// WithStatus copies the installments into the status, e.g. to assess the write before it is committed.
+func WithStatus(loans []*repository.LoanEntity, status repository.LoanStatus) []*repository.LoanEntity {
+	copied := make([]*repository.LoanEntity, 0, len(loans))
+	for _, loan := range loans {
+		changed := *loan
+		changed.Status = status
+		copied = append(copied, &changed)
+	}
+
+	return copied
+}
+
+// Evaluate sums the due unpaid installments. The missed installments of the restructured schedule
+// are counted separately from the original schedule, each against its own tolerance. The installment due
+// on the non business day is not missed until the next business day, nor the installment within the grace
+// period of its product, both are due but not late yet and flagged as the grace outstanding.
+// The missed installments are tolerated by the lowest delinquency threshold of their products.
+// The written-off customer stays delinquent until the written-off installments are recovered.
+func Evaluate(
+	userID string,
+	loans []*repository.LoanEntity,
+	products map[uint64]*repository.ProductEntity,
+	c calendar.Calendar,
+	now time.Time) *Assessment {
+	totalClosed, totalPending, totalRestructuredPending, totalWrittenOff, totalUnpaid := 0, 0, 0, 0, 0
+	missedTolerance := -1
+	pendingAmountOutstanding := decimal.NewFromFloat(float64(0))
+	graceOutstanding := decimal.NewFromFloat(float64(0))
+	var graceInstallmentIDs []uint64
+	breakdown := repository.Breakdown{}
+	tomorrow := common.StartOfDay(now).AddDate(0, 0, 1)
+
+	for _, val := range loans {
+		if val.Status == repository.LoanWrittenOff {
+			pendingAmountOutstanding = pendingAmountOutstanding.Add(val.Amount)
+			breakdown = breakdown.Add(val.Breakdown)
+			totalWrittenOff += 1
+		}
+
+		if val.Status == repository.LoanPending || val.Status == repository.LoanOverdue {
+			pendingAmountOutstanding = pendingAmountOutstanding.Add(val.Amount)
+			breakdown = breakdown.Add(val.Breakdown)
+			totalUnpaid += 1
+
+			if !val.DueDate.Before(product.LateBefore(c, products[val.ProductID], tomorrow)) {
+				graceOutstanding = graceOutstanding.Add(val.Amount)
+				graceInstallmentIDs = append(graceInstallmentIDs, val.ID)
+				continue
+			}
+
+			if val.RestructureID != "" {
+				totalRestructuredPending += 1
+			} else {
+				totalPending += 1
+
+				tolerance := toleranceOf(products[val.ProductID])
+				if missedTolerance < 0 || tolerance < missedTolerance {
+					missedTolerance = tolerance
+				}
+			}
+		}
+
+		if val.Status == repository.LoanClosed {
+			totalClosed += 1
+		}
+	}
+
+	//none is missed, nothing to tolerate
+	if missedTolerance < 0 {
+		missedTolerance = MissedTolerance
+	}
+
+	//meaning : the customer already paid all the outstanding
+	if totalClosed == len(loans) {
+		return &Assessment{
+			UserID:               userID,
+			RemainingOutstanding: decimal.NewFromFloat(float64(0)),
+			IsDelinquent:         false,
+		}
+	}
+
+	return &Assessment{
+		UserID: userID,
+		IsDelinquent: totalPending > missedTolerance ||
+			totalRestructuredPending > RestructuredMissedTolerance ||
+			totalWrittenOff > 0,
+		UnpaidInstallments:   totalUnpaid,
+		RemainingOutstanding: pendingAmountOutstanding,
+		Breakdown:            breakdown,
+		GraceOutstanding:     graceOutstanding,
+		GraceInstallmentIDs:  graceInstallmentIDs,
+	}
+}
+
+// toleranceOf is the missed installments tolerated by the product, the product is nil for the loan issued
+// before the catalogue.
+func toleranceOf(product *repository.ProductEntity) int {
+	if product == nil {
+		return MissedTolerance
+	}
+
+	return product.DelinquencyThreshold
+}
@@ -0,0 +1,216 @@
+package delinquency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/calendar"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	mocksRepository "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/infrastructure/repository"
+)
+
+// noHolidays is the calendar whose every day is the business day.
+var noHolidays = calendar.NewCalendar(calendar.DefaultRegion, calendar.PolicyNone, nil, nil)
+
+func Test_Evaluate_Calendar(t *testing.T) {
+	// 2026-03-20 & 2026-03-23 are holidays, 2026-03-21 & 2026-03-22 is the weekend.
+	holidays := calendar.NewCalendar(
+		calendar.DefaultRegion, calendar.PolicyNone,
+		[]time.Weekday{time.Saturday, time.Sunday},
+		[]*calendar.Holiday{
+			{Region: calendar.DefaultRegion, Date: time.Date(2026, 3, 20, 0, 0, 0, 0, time.Local)},
+			{Region: calendar.DefaultRegion, Date: time.Date(2026, 3, 23, 0, 0, 0, 0, time.Local)},
+		})
+
+	loans := []*repository.LoanEntity{
+		{Status: repository.LoanOverdue, Amount: decimal.NewFromInt(100), DueDate: time.Date(2026, 3, 5, 0, 0, 0, 0, time.Local)},
+		{Status: repository.LoanOverdue, Amount: decimal.NewFromInt(100), DueDate: time.Date(2026, 3, 12, 0, 0, 0, 0, time.Local)},
+		{Status: repository.LoanPending, Amount: decimal.NewFromInt(100), DueDate: time.Date(2026, 3, 20, 0, 0, 0, 0, time.Local)},
+	}
+
+	tests := []struct {
+		name             string
+		calendar         calendar.Calendar
+		wantIsDelinquent bool
+	}{
+		{
+			name: "given installment due on the holiday and next business day is not over," +
+				"when evaluate," +
+				"then it is not counted as missed",
+			calendar:         holidays,
+			wantIsDelinquent: false,
+		},
+		{
+			name: "given installment due on the day without holidays," +
+				"when evaluate," +
+				"then it is counted as missed",
+			calendar:         noHolidays,
+			wantIsDelinquent: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got := Evaluate("abc", loans, nil, tt.calendar, time.Date(2026, 3, 23, 10, 0, 0, 0, time.Local))
+
+				assert.Equal(t, "abc", got.UserID)
+				assert.Equal(t, tt.wantIsDelinquent, got.IsDelinquent)
+				assert.Equal(t, 3, got.UnpaidInstallments)
+				assert.True(t, decimal.NewFromInt(300).Equal(got.RemainingOutstanding))
+			})
+	}
+}
+
+func Test_Evaluate_Threshold(t *testing.T) {
+	now := time.Date(2026, 3, 23, 10, 0, 0, 0, time.Local)
+	missed := func(id uint64, productID uint64) *repository.LoanEntity {
+		return &repository.LoanEntity{
+			ID: id, ProductID: productID, Status: repository.LoanOverdue, Amount: decimal.NewFromInt(100),
+			DueDate: time.Date(2026, 3, 5, 0, 0, 0, 0, time.Local),
+		}
+	}
+
+	products := map[uint64]*repository.ProductEntity{
+		1: {ID: 1, DelinquencyThreshold: 5},
+		2: {ID: 2, DelinquencyThreshold: 1},
+		3: {ID: 3, DelinquencyThreshold: 0},
+	}
+
+	tests := []struct {
+		name             string
+		loans            []*repository.LoanEntity
+		wantIsDelinquent bool
+	}{
+		{
+			name: "given 3 missed installments of the product tolerating 5," +
+				"when evaluate," +
+				"then the customer is not delinquent",
+			loans: []*repository.LoanEntity{missed(1, 1), missed(2, 1), missed(3, 1)},
+		},
+		{
+			name: "given 1 missed installment of the product tolerating none," +
+				"when evaluate," +
+				"then the customer is delinquent",
+			loans:            []*repository.LoanEntity{missed(1, 3)},
+			wantIsDelinquent: true,
+		},
+		{
+			name: "given 2 missed installments of the products tolerating 5 and 1," +
+				"when evaluate," +
+				"then the lowest threshold is applied",
+			loans:            []*repository.LoanEntity{missed(1, 1), missed(2, 2)},
+			wantIsDelinquent: true,
+		},
+		{
+			name: "given 2 missed installments of the loan issued before the catalogue," +
+				"when evaluate," +
+				"then the default tolerance is applied",
+			loans: []*repository.LoanEntity{missed(1, 0), missed(2, 0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got := Evaluate("abc", tt.loans, products, noHolidays, now)
+
+				assert.Equal(t, tt.wantIsDelinquent, got.IsDelinquent)
+			})
+	}
+}
+
+func Test_Assessor_Assess(t *testing.T) {
+	now := time.Date(2026, 3, 23, 10, 0, 0, 0, time.Local)
+	dueDate := time.Date(2026, 3, 5, 0, 0, 0, 0, time.Local)
+
+	unpaid := func(id uint64) *repository.LoanEntity {
+		return &repository.LoanEntity{
+			ID: id, Status: repository.LoanOverdue, UserID: "abc", DueDate: dueDate, Amount: decimal.NewFromInt(100),
+		}
+	}
+
+	tests := []struct {
+		name             string
+		stored           []*repository.LoanEntity
+		storedErr        error
+		changed          []*repository.LoanEntity
+		wantIsDelinquent bool
+		wantOutstanding  decimal.Decimal
+		wantErr          error
+	}{
+		{
+			name: "given 3 missed installments stored," +
+				"when assess," +
+				"then the customer is delinquent",
+			stored:           []*repository.LoanEntity{unpaid(1), unpaid(2), unpaid(3)},
+			wantIsDelinquent: true,
+			wantOutstanding:  decimal.NewFromInt(300),
+		},
+		{
+			name: "given one of the missed installments paid but not committed yet," +
+				"when assess," +
+				"then the paid one replaces the stored one",
+			stored:          []*repository.LoanEntity{unpaid(1), unpaid(2), unpaid(3)},
+			changed:         WithStatus([]*repository.LoanEntity{unpaid(3)}, repository.LoanPaid),
+			wantOutstanding: decimal.NewFromInt(200),
+		},
+		{
+			name: "given the reopened installment not stored in the due statuses," +
+				"when assess," +
+				"then the reopened one is added",
+			stored:           []*repository.LoanEntity{unpaid(1), unpaid(2)},
+			changed:          []*repository.LoanEntity{unpaid(3)},
+			wantIsDelinquent: true,
+			wantOutstanding:  decimal.NewFromInt(300),
+		},
+		{
+			name: "given no installments due," +
+				"when assess," +
+				"then the customer is not delinquent",
+			storedErr:       repository.ErrorNoRows,
+			wantOutstanding: decimal.NewFromInt(0),
+		},
+		{
+			name: "given find loans is failed," +
+				"when assess," +
+				"then return error",
+			storedErr: repository.ErrorFromDBLoan,
+			wantErr:   repository.ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockLoanRepo := &mocksRepository.LoanRepository{}
+				mockLoanRepo.
+					On(
+						"FindLoans", mock.Anything, &repository.LoanEntity{
+							Statuses: DueStatuses,
+							UserID:   "abc",
+							DueDate:  now,
+						},
+					).
+					Return(tt.stored, tt.storedErr).
+					Once()
+
+				a := NewAssessor(mockLoanRepo, &mocksRepository.ProductRepository{}, noHolidays)
+				got, err := a.Assess(context.Background(), "abc", now, tt.changed...)
+
+				assert.Equal(t, tt.wantErr, err)
+				if tt.wantErr != nil {
+					return
+				}
+
+				assert.Equal(t, tt.wantIsDelinquent, got.IsDelinquent)
+				assert.True(t, tt.wantOutstanding.Equal(got.RemainingOutstanding))
+				mockLoanRepo.AssertExpectations(t)
+			})
+	}
+}
@@ -0,0 +1,103 @@
+package delinquency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/event"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+// Tracker keeps the last known delinquency of the customer (table customer_delinquency), its transition is recorded
+// together with the CustomerBecameDelinquent or CustomerDelinquencyCleared event by the write which causes it.
+type Tracker struct {
+	delinquencyRepository repository.DelinquencyRepository
+	outboxRepository      repository.OutboxRepository
+	generate              common.Generate
+}
+
+func NewTracker(
+	delinquencyRepository repository.DelinquencyRepository,
+	outboxRepository repository.OutboxRepository) *Tracker {
+	return &Tracker{
+		delinquencyRepository: delinquencyRepository,
+		outboxRepository:      outboxRepository,
+		generate:              common.NewGenerate(),
+	}
+}
+
+// Transition returns the event when the assessment is different from the last known delinquency, nil otherwise.
+// The customer without any record is considered as not delinquent.
+func (t *Tracker) Transition(
+	ctx context.Context,
+	assessment *Assessment,
+	at time.Time) (*repository.OutboxEntity, error) {
+	current, err := t.delinquencyRepository.FindDelinquency(ctx, assessment.UserID)
+	if err != nil && !errors.Is(err, repository.ErrorNoRows) {
+		return nil, err
+	}
+
+	wasDelinquent := current != nil && current.IsDelinquent
+	if wasDelinquent == assessment.IsDelinquent {
+		return nil, nil
+	}
+
+	return t.Event(assessment, at)
+}
+
+// Event builds the event of the transition into the delinquency of the assessment.
+func (t *Tracker) Event(assessment *Assessment, at time.Time) (*repository.OutboxEntity, error) {
+	if assessment.IsDelinquent {
+		return event.NewOutbox(
+			t.generate.Uuid(), event.CustomerBecameDelinquent, assessment.UserID, at,
+			&event.CustomerBecameDelinquentPayload{
+				UserID:               assessment.UserID,
+				OverdueInstallments:  assessment.UnpaidInstallments,
+				RemainingOutstanding: assessment.RemainingOutstanding,
+				DelinquentAt:         at,
+			},
+		)
+	}
+
+	return event.NewOutbox(
+		t.generate.Uuid(), event.CustomerDelinquencyCleared, assessment.UserID, at,
+		&event.CustomerDelinquencyClearedPayload{
+			UserID:    assessment.UserID,
+			ClearedAt: at,
+		},
+	)
+}
+
+// Record saves the delinquency of the transitions as the last known together with their events,
+// in the transaction of the write which changes it. The nil transition (nothing changes) is skipped.
+func (t *Tracker) Record(ctx context.Context, tx *sql.Tx, transitions ...*repository.OutboxEntity) error {
+	var changed []*repository.OutboxEntity
+	for _, transition := range transitions {
+		if transition != nil {
+			changed = append(changed, transition)
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	for _, transition := range changed {
+		errUpsert := t.delinquencyRepository.UpsertDelinquency(
+			ctx, tx, &repository.DelinquencyEntity{
+				UserID:       transition.AggregateID,
+				IsDelinquent: transition.EventType == string(event.CustomerBecameDelinquent),
+				UpdatedAt:    transition.OccurredAt,
+			},
+		)
+
+		if errUpsert != nil {
+			return errUpsert
+		}
+	}
+
+	return t.outboxRepository.SaveOutbox(ctx, tx, changed...)
+}
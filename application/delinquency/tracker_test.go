@@ -0,0 +1,128 @@
+package delinquency
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/event"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	mocksRepository "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/infrastructure/repository"
+)
+
+func Test_Tracker_Transition(t *testing.T) {
+	tests := []struct {
+		name         string
+		current      *repository.DelinquencyEntity
+		currentErr   error
+		isDelinquent bool
+		wantEvent    string
+		wantErr      bool
+	}{
+		{
+			name: "given no record and not delinquent," +
+				"when transition," +
+				"then no event",
+			currentErr:   repository.ErrorNoRows,
+			isDelinquent: false,
+		},
+		{
+			name: "given no record and delinquent," +
+				"when transition," +
+				"then customer became delinquent",
+			currentErr:   repository.ErrorNoRows,
+			isDelinquent: true,
+			wantEvent:    "CustomerBecameDelinquent",
+		},
+		{
+			name: "given delinquent record and not delinquent anymore," +
+				"when transition," +
+				"then customer delinquency cleared",
+			current:      &repository.DelinquencyEntity{UserID: "abc", IsDelinquent: true},
+			isDelinquent: false,
+			wantEvent:    "CustomerDelinquencyCleared",
+		},
+		{
+			name: "given delinquent record and still delinquent," +
+				"when transition," +
+				"then no event",
+			current:      &repository.DelinquencyEntity{UserID: "abc", IsDelinquent: true},
+			isDelinquent: true,
+		},
+		{
+			name: "given unknown error from database," +
+				"when transition," +
+				"then return error",
+			currentErr: repository.ErrorFromDBLoan,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockDelinquencyRepo := &mocksRepository.DelinquencyRepository{}
+				mockDelinquencyRepo.
+					On("FindDelinquency", mock.Anything, "abc").
+					Return(tt.current, tt.currentErr).
+					Once()
+
+				tracker := NewTracker(mockDelinquencyRepo, &mocksRepository.OutboxRepository{})
+				got, err := tracker.Transition(
+					context.Background(),
+					&Assessment{UserID: "abc", IsDelinquent: tt.isDelinquent, UnpaidInstallments: 3},
+					time.Now())
+
+				assert.Equal(t, tt.wantErr, err != nil)
+				if tt.wantEvent == "" {
+					assert.Nil(t, got)
+					return
+				}
+
+				assert.Equal(t, tt.wantEvent, got.EventType)
+				assert.Equal(t, "abc", got.AggregateID)
+				assert.Equal(t, repository.OutboxStatusPending, got.Status)
+			})
+	}
+}
+
+func Test_Tracker_Record(t *testing.T) {
+	at := time.Date(2026, 3, 23, 23, 59, 59, 0, time.UTC)
+
+	mockDelinquencyRepo := &mocksRepository.DelinquencyRepository{}
+	mockOutboxRepo := &mocksRepository.OutboxRepository{}
+	tracker := NewTracker(mockDelinquencyRepo, mockOutboxRepo)
+
+	became, _ := tracker.Event(&Assessment{UserID: "abc", IsDelinquent: true}, at)
+	cleared, _ := tracker.Event(&Assessment{UserID: "def", IsDelinquent: false}, at)
+
+	mockDelinquencyRepo.
+		On(
+			"UpsertDelinquency", mock.Anything, (*sql.Tx)(nil),
+			&repository.DelinquencyEntity{UserID: "abc", IsDelinquent: true, UpdatedAt: at},
+		).
+		Return(nil).
+		Once()
+	mockDelinquencyRepo.
+		On(
+			"UpsertDelinquency", mock.Anything, (*sql.Tx)(nil),
+			&repository.DelinquencyEntity{UserID: "def", IsDelinquent: false, UpdatedAt: at},
+		).
+		Return(nil).
+		Once()
+	mockOutboxRepo.
+		On("SaveOutbox", mock.Anything, (*sql.Tx)(nil), became, cleared).
+		Return(nil).
+		Once()
+
+	assert.Nil(t, tracker.Record(context.Background(), nil, became, cleared))
+	assert.Nil(t, tracker.Record(context.Background(), nil))
+	assert.Equal(t, string(event.CustomerBecameDelinquent), became.EventType)
+	assert.Equal(t, string(event.CustomerDelinquencyCleared), cleared.EventType)
+	mockDelinquencyRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+}
@@ -0,0 +1,25 @@
+package eod
+
+import (
+	"net/http"
+)
+
+type (
+	eodController struct {
+		srv Service
+	}
+
+	Controller interface {
+		FindRuns(writer http.ResponseWriter, req *http.Request)
+
+		BusinessDate(writer http.ResponseWriter, req *http.Request)
+
+		AdvanceBusinessDate(writer http.ResponseWriter, req *http.Request)
+	}
+)
+
+func NewEodController(srv Service) Controller {
+	return &eodController{
+		srv: srv,
+	}
+}
@@ -0,0 +1,104 @@
+package eod
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/constant"
+)
+
+func (e *eodController) FindRuns(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	query := req.URL.Query()
+
+	request := RunsRequest{
+		Date: query.Get("date"),
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed <= 0 {
+			toErrorResponse(writer, errorValidation)
+			return
+		}
+		request.Limit = parsed
+	}
+
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	result, err := e.srv.FindRuns(ctx, &request)
+	if err != nil {
+		toErrorResponse(writer, err)
+		return
+	}
+
+	common.ToSuccessResponse(writer, nil, result)
+}
+
+func (e *eodController) BusinessDate(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	result, err := e.srv.BusinessDate(ctx)
+	if err != nil {
+		toErrorResponse(writer, err)
+		return
+	}
+
+	common.ToSuccessResponse(writer, nil, result)
+}
+
+func (e *eodController) AdvanceBusinessDate(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	var advanceRequest AdvanceRequest
+	err := common.DecodeJSONBody(writer, req, &advanceRequest)
+
+	if err != nil {
+		log.Println("validation decode json body -> ", err)
+		toErrorResponse(writer, errorValidation)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	result, err := e.srv.AdvanceBusinessDate(ctx, &advanceRequest)
+	if err != nil {
+		toErrorResponse(writer, err)
+		return
+	}
+
+	common.ToSuccessResponse(writer, nil, result)
+}
+
+func toErrorResponse(writer http.ResponseWriter, err error) {
+	billingErr := func() constant.BillingSrvHttpError {
+		switch {
+		case errors.Is(err, errorValidation):
+			return constant.Validation
+		case errors.Is(err, errorClockFixed):
+			return constant.FeatureDisabled
+		default:
+			return constant.GeneralError
+		}
+	}()
+
+	common.ToErrorResponse(
+		writer,
+		constant.HttpRc[billingErr],
+		constant.HttpRcDescription[billingErr],
+	)
+}
@@ -0,0 +1,133 @@
+package eod
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/accrual"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/delinquency"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/journal"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/overdue"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/calendar"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+// the steps of the end of day, run in this order.
+const (
+	StepOverdueMarking      = "OVERDUE_MARKING"
+	StepPenaltyAccrual      = "PENALTY_ACCRUAL"
+	StepInterestAccrual     = "INTEREST_ACCRUAL"
+	StepDelinquencySnapshot = "DELINQUENCY_SNAPSHOT"
+	StepGLExport            = "GL_EXPORT"
+)
+
+type (
+	eodService struct {
+		overdueService        overdue.Service
+		accrualService        accrual.Service
+		journalService        journal.Service
+		delinquencyRepository repository.DelinquencyRepository
+		eodRepository         repository.EodRepository
+		transaction           repository.Transaction
+		tracker               *delinquency.Tracker
+		calendar              calendar.Calendar
+		// clock tells the business date, generate tells when the run and its steps happen.
+		clock    common.Clock
+		generate common.Generate
+		glFormat string
+		glOutput string
+		// advanceEnabled allows the business date to be moved forward through the admin api, never in production.
+		advanceEnabled bool
+	}
+
+	CloseRequest struct {
+		// Date is YYYY-MM-DD, it is the day before the business date when empty.
+		Date string `json:"date,omitempty"`
+	}
+
+	RunsRequest struct {
+		Date  string `json:"date,omitempty"`
+		Limit int    `json:"limit,omitempty"`
+	}
+
+	StepResponse struct {
+		Step       string     `json:"step"`
+		Sequence   int        `json:"sequence"`
+		Status     string     `json:"status"`
+		Processed  int        `json:"processed"`
+		Error      string     `json:"error,omitempty"`
+		StartedAt  time.Time  `json:"started_at"`
+		FinishedAt *time.Time `json:"finished_at,omitempty"`
+	}
+
+	RunResponse struct {
+		BusinessDate string          `json:"business_date"`
+		Status       string          `json:"status"`
+		Error        string          `json:"error,omitempty"`
+		StartedAt    time.Time       `json:"started_at"`
+		FinishedAt   *time.Time      `json:"finished_at,omitempty"`
+		Steps        []*StepResponse `json:"steps"`
+	}
+
+	AdvanceRequest struct {
+		Days int `json:"days"`
+	}
+
+	BusinessDateResponse struct {
+		BusinessDate string    `json:"business_date"`
+		Now          time.Time `json:"now"`
+		// Mode is the clock configured by clock.mode : real, fixed or offset.
+		Mode string `json:"mode"`
+	}
+
+	// Service closes the business date : it marks the overdue installments, accrues the penalty and the interest,
+	// snapshots the delinquency and exports the general ledger, in this order. Every step is recorded (table eod_step),
+	// the run failed is resumed from the step which is not completed.
+	Service interface {
+		// Close closes the date, the dates are closed one by one in order. The date closed is returned as is.
+		Close(ctx context.Context, request *CloseRequest) (*RunResponse, error)
+
+		FindRuns(ctx context.Context, request *RunsRequest) ([]*RunResponse, error)
+
+		BusinessDate(ctx context.Context) (*BusinessDateResponse, error)
+
+		// AdvanceBusinessDate moves the clock forward by the days, only the fixed or offset clock can be moved
+		// and only when clock.advance.enabled is true. The dates passed by are not closed.
+		AdvanceBusinessDate(ctx context.Context, request *AdvanceRequest) (*BusinessDateResponse, error)
+	}
+)
+
+func NewEodService(
+	cfg configuration.Configuration,
+	overdueService overdue.Service,
+	accrualService accrual.Service,
+	journalService journal.Service,
+	delinquencyRepository repository.DelinquencyRepository,
+	outboxRepository repository.OutboxRepository,
+	eodRepository repository.EodRepository,
+	transaction repository.Transaction,
+	clock common.Clock,
+	calendar calendar.Calendar) Service {
+	glFormat := cfg.GetString("eod.gl.format")
+	if glFormat == "" {
+		glFormat = journal.FormatCSV
+	}
+
+	return &eodService{
+		overdueService:        overdueService,
+		accrualService:        accrualService,
+		journalService:        journalService,
+		delinquencyRepository: delinquencyRepository,
+		eodRepository:         eodRepository,
+		transaction:           transaction,
+		tracker:               delinquency.NewTracker(delinquencyRepository, outboxRepository),
+		calendar:              calendar,
+		clock:                 clock,
+		generate:              common.NewGenerate(),
+		glFormat:              glFormat,
+		glOutput:              cfg.GetString("eod.gl.output"),
+		advanceEnabled:        cfg.GetBool("clock.advance.enabled"),
+	}
+}
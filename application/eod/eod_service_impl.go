@@ -0,0 +1,509 @@
+package eod
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"runtime/debug"
+	"sort"
+	"time"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/delinquency"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/journal"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/product"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+const (
+	dateLayout     = "2006-01-02"
+	defaultLimit   = 10
+	maxLimit       = 100
+	maxAdvanceDays = 366
+	// maxDpdUsers bounds the customers whose days past due are updated at once.
+	maxDpdUsers = 1000
+)
+
+var (
+	errorValidation   = errors.New("validation request")
+	errorFromDatabase = errors.New("from database")
+	errorOutOfOrder   = errors.New("the previous business date is not closed")
+	errorStepFailed   = errors.New("step of the end of day is failed")
+	errorClockFixed   = errors.New("business clock can't be moved")
+	errorInProgress   = errors.New("the end of day is already running")
+)
+
+type step struct {
+	name string
+	// run returns the number of the records processed for the business date.
+	run func(ctx context.Context, businessDate time.Time) (int, error)
+}
+
+func (e *eodService) steps() []step {
+	return []step{
+		{
+			name: StepOverdueMarking,
+			run: func(ctx context.Context, businessDate time.Time) (int, error) {
+				return e.overdueService.MarkOverdueAsOf(ctx, endOfDay(businessDate))
+			},
+		},
+		{
+			name: StepPenaltyAccrual,
+			run: func(ctx context.Context, businessDate time.Time) (int, error) {
+				return e.accrualService.AccruePenalty(ctx, endOfDay(businessDate))
+			},
+		},
+		{
+			name: StepInterestAccrual,
+			run: func(ctx context.Context, businessDate time.Time) (int, error) {
+				return e.accrualService.AccrueInterest(ctx, endOfDay(businessDate))
+			},
+		},
+		{
+			name: StepDelinquencySnapshot,
+			run:  e.snapshotDelinquency,
+		},
+		{
+			name: StepGLExport,
+			run:  e.exportGeneralLedger,
+		},
+	}
+}
+
+func (e *eodService) Close(
+	ctx context.Context,
+	request *CloseRequest) (rsp *RunResponse, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Println("unidentified error (yet)", string(debug.Stack()))
+			err = errorFromDatabase
+			return
+		}
+	}()
+
+	today := common.StartOfDay(e.clock.Now())
+	businessDate := today.AddDate(0, 0, -1)
+	if request.Date != "" {
+		parsed, errParse := time.ParseInLocation(dateLayout, request.Date, today.Location())
+		if errParse != nil {
+			return nil, errorValidation
+		}
+
+		businessDate = parsed
+	}
+
+	//the business date is closed once it is over
+	if !businessDate.Before(today) {
+		return nil, errorValidation
+	}
+
+	//the dates are closed one at a time, the run is claimed and its steps are run under the lock
+	unlock, errLock := e.eodRepository.LockRun(ctx)
+	if errors.Is(errLock, repository.ErrorDuplicate) {
+		log.Println("[EOD] refused in progress -> ", businessDate.Format(dateLayout))
+		return nil, errorInProgress
+	}
+
+	if errLock != nil {
+		return nil, errorFromDatabase
+	}
+	defer unlock()
+
+	run, err := e.claimRun(ctx, businessDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if run.Status == repository.EodCompleted {
+		return toRunResponse(run), nil
+	}
+
+	completed := make(map[string]bool)
+	for _, s := range run.Steps {
+		completed[s.Step] = s.Status == repository.EodCompleted
+	}
+
+	for i, s := range e.steps() {
+		if completed[s.name] {
+			continue
+		}
+
+		if errStep := e.runStep(ctx, run, i+1, s); errStep != nil {
+			return nil, errStep
+		}
+	}
+
+	run.Status = repository.EodCompleted
+	run.FinishedAt = e.generate.Time()
+	run.UpdatedAt = run.FinishedAt
+	if errUpdate := e.eodRepository.UpdateRun(ctx, run); errUpdate != nil {
+		return nil, errorFromDatabase
+	}
+
+	log.Println("[EOD] business date is closed -> ", businessDate.Format(dateLayout))
+	return e.findRun(ctx, businessDate)
+}
+
+func (e *eodService) FindRuns(
+	ctx context.Context,
+	request *RunsRequest) (rsp []*RunResponse, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Println("unidentified error (yet)", string(debug.Stack()))
+			err = errorFromDatabase
+			return
+		}
+	}()
+
+	filter := &repository.EodRunFilter{Limit: request.Limit}
+	if request.Date != "" {
+		parsed, errParse := time.Parse(dateLayout, request.Date)
+		if errParse != nil {
+			return nil, errorValidation
+		}
+
+		filter.BusinessDate = parsed
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLimit
+	}
+
+	if filter.Limit > maxLimit {
+		return nil, errorValidation
+	}
+
+	runs, err := e.eodRepository.FindRuns(ctx, filter)
+	if err != nil {
+		return nil, errorFromDatabase
+	}
+
+	rsp = make([]*RunResponse, 0, len(runs))
+	for _, run := range runs {
+		rsp = append(rsp, toRunResponse(run))
+	}
+
+	return rsp, nil
+}
+
+func (e *eodService) BusinessDate(ctx context.Context) (*BusinessDateResponse, error) {
+	return e.toBusinessDateResponse(e.clock.Now()), nil
+}
+
+func (e *eodService) AdvanceBusinessDate(
+	ctx context.Context,
+	request *AdvanceRequest) (*BusinessDateResponse, error) {
+	adjustable, ok := e.clock.(common.AdjustableClock)
+	if !e.advanceEnabled || !ok {
+		return nil, errorClockFixed
+	}
+
+	if request.Days <= 0 || request.Days > maxAdvanceDays {
+		return nil, errorValidation
+	}
+
+	now := adjustable.Advance(time.Duration(request.Days) * 24 * time.Hour)
+	log.Println("[EOD] business date is moved forward -> ", now.Format(dateLayout), ", days -> ", request.Days)
+
+	return e.toBusinessDateResponse(now), nil
+}
+
+func (e *eodService) toBusinessDateResponse(now time.Time) *BusinessDateResponse {
+	mode := common.ClockReal
+	if adjustable, ok := e.clock.(common.AdjustableClock); ok {
+		mode = adjustable.Mode()
+	}
+
+	return &BusinessDateResponse{
+		BusinessDate: now.Format(dateLayout),
+		Now:          now,
+		Mode:         mode,
+	}
+}
+
+// claimRun returns the run of the business date to be resumed, or the new one when the previous date is closed.
+// The run completed is returned as is.
+func (e *eodService) claimRun(
+	ctx context.Context,
+	businessDate time.Time) (*repository.EodRunEntity, error) {
+	existing, err := e.eodRepository.FindRuns(ctx, &repository.EodRunFilter{BusinessDate: businessDate})
+	if err != nil {
+		return nil, errorFromDatabase
+	}
+
+	now := e.generate.Time()
+	if len(existing) > 0 {
+		run := existing[0]
+		if run.Status == repository.EodCompleted {
+			return run, nil
+		}
+
+		log.Println("[EOD] resume the run -> ", businessDate.Format(dateLayout), run.Status, run.Error)
+		run.Status = repository.EodRunning
+		run.Error = ""
+		run.StartedAt = now
+		run.FinishedAt = time.Time{}
+		run.UpdatedAt = now
+
+		if errUpdate := e.eodRepository.UpdateRun(ctx, run); errUpdate != nil {
+			return nil, errorFromDatabase
+		}
+
+		return run, nil
+	}
+
+	//the penalty is charged per date, so none of the dates can be skipped
+	latest, err := e.eodRepository.FindRuns(ctx, &repository.EodRunFilter{Limit: 1})
+	if err != nil {
+		return nil, errorFromDatabase
+	}
+
+	if len(latest) > 0 {
+		previous := latest[0]
+		expected := previous.BusinessDate.AddDate(0, 0, 1).Format(dateLayout)
+
+		if previous.Status != repository.EodCompleted || expected != businessDate.Format(dateLayout) {
+			log.Println(
+				"[EOD] refused out of order -> ", businessDate.Format(dateLayout),
+				", latest -> ", previous.BusinessDate.Format(dateLayout), previous.Status,
+			)
+			return nil, errorOutOfOrder
+		}
+	}
+
+	run := &repository.EodRunEntity{
+		BusinessDate: businessDate,
+		Status:       repository.EodRunning,
+		StartedAt:    now,
+		CreatedAt:    now,
+		Version:      0,
+		UpdatedAt:    now,
+	}
+
+	if errSave := e.eodRepository.SaveRun(ctx, run); errSave != nil {
+		return nil, errorFromDatabase
+	}
+
+	return run, nil
+}
+
+// runStep records the step as RUNNING, then COMPLETED or FAILED together with the run.
+func (e *eodService) runStep(
+	ctx context.Context,
+	run *repository.EodRunEntity,
+	sequence int,
+	s step) error {
+	record := &repository.EodStepEntity{
+		BusinessDate: run.BusinessDate,
+		Step:         s.name,
+		Sequence:     sequence,
+		Status:       repository.EodRunning,
+		StartedAt:    e.generate.Time(),
+	}
+
+	if errSave := e.eodRepository.SaveStep(ctx, record); errSave != nil {
+		return errorFromDatabase
+	}
+
+	processed, errRun := s.run(ctx, run.BusinessDate)
+
+	record.Processed = processed
+	record.FinishedAt = e.generate.Time()
+	record.Status = repository.EodCompleted
+	if errRun != nil {
+		log.Println("[EOD] step is failed -> ", run.BusinessDate.Format(dateLayout), s.name, errRun)
+		record.Status = repository.EodFailed
+		record.Error = errRun.Error()
+	}
+
+	if errSave := e.eodRepository.SaveStep(ctx, record); errSave != nil {
+		return errorFromDatabase
+	}
+
+	if errRun == nil {
+		return nil
+	}
+
+	run.Status = repository.EodFailed
+	run.Error = s.name + " : " + errRun.Error()
+	run.FinishedAt = record.FinishedAt
+	run.UpdatedAt = record.FinishedAt
+	if errUpdate := e.eodRepository.UpdateRun(ctx, run); errUpdate != nil {
+		return errorFromDatabase
+	}
+
+	return errorStepFailed
+}
+
+// snapshotDelinquency snapshots the customers with the installments due until the end of the date,
+// except the ones due on the non business days after the last business day of the date. The installments
+// within the grace period of their product are outstanding but not missed. The customers whose delinquency
+// in the snapshot is different from the last known are recorded with their events in the same transaction.
+func (e *eodService) snapshotDelinquency(ctx context.Context, businessDate time.Time) (int, error) {
+	snapshotted := 0
+	err := e.transaction.WithTransaction(
+		ctx, func(tx *sql.Tx) error {
+			snapshot := &repository.DelinquencySnapshot{
+				BusinessDate:                businessDate,
+				DueBefore:                   e.calendar.LateBefore(businessDate.AddDate(0, 0, 1)),
+				MissedTolerance:             delinquency.MissedTolerance,
+				RestructuredMissedTolerance: delinquency.RestructuredMissedTolerance,
+				CreatedAt:                   e.generate.Time(),
+			}
+
+			saved, errSave := e.delinquencyRepository.SaveSnapshot(ctx, tx, snapshot)
+			if errSave != nil {
+				return errSave
+			}
+
+			snapshotted = saved
+			if errDpd := e.updateDaysPastDue(ctx, tx, snapshot); errDpd != nil {
+				return errDpd
+			}
+
+			return e.recordTransitions(ctx, tx, businessDate)
+		},
+	)
+
+	return snapshotted, err
+}
+
+// updateDaysPastDue sets the days past due of the customers of the snapshot as of the business date, by
+// the same count as the write-off, i.e. from the business day the installment the most past due is due on
+// after the grace days of its product. The customers are updated together per days past due.
+func (e *eodService) updateDaysPastDue(ctx context.Context, tx *sql.Tx, snapshot *repository.DelinquencySnapshot) error {
+	dueDates, err := e.delinquencyRepository.FindSnapshotDueDates(ctx, tx, snapshot)
+	if err != nil {
+		return err
+	}
+
+	daysPastDue := make(map[string]int)
+	for _, dueDate := range dueDates {
+		grace := &repository.ProductEntity{GraceDays: dueDate.GraceDays}
+		dpd := product.DaysPastDue(e.calendar, grace, dueDate.OldestDueDate, snapshot.BusinessDate)
+		if current, ok := daysPastDue[dueDate.UserID]; !ok || dpd > current {
+			daysPastDue[dueDate.UserID] = dpd
+		}
+	}
+
+	userIDs := make(map[int][]string)
+	for userID, dpd := range daysPastDue {
+		//zero is the days past due the snapshot is saved with
+		if dpd > 0 {
+			userIDs[dpd] = append(userIDs[dpd], userID)
+		}
+	}
+
+	for dpd, ids := range userIDs {
+		sort.Strings(ids)
+		for start := 0; start < len(ids); start += maxDpdUsers {
+			end := start + maxDpdUsers
+			if end > len(ids) {
+				end = len(ids)
+			}
+
+			if errUpdate := e.delinquencyRepository.UpdateSnapshotDaysPastDue(
+				ctx, tx, snapshot.BusinessDate, dpd, ids[start:end]); errUpdate != nil {
+				return errUpdate
+			}
+		}
+	}
+
+	return nil
+}
+
+// recordTransitions records the delinquency transitions of the snapshot of the business date, they occur
+// at the end of the date.
+func (e *eodService) recordTransitions(ctx context.Context, tx *sql.Tx, businessDate time.Time) error {
+	found, err := e.delinquencyRepository.FindSnapshotTransitions(ctx, tx, businessDate)
+	if err != nil {
+		return err
+	}
+
+	var transitions []*repository.OutboxEntity
+	for _, val := range found {
+		transition, errEvent := e.tracker.Event(
+			&delinquency.Assessment{
+				UserID:               val.UserID,
+				IsDelinquent:         val.IsDelinquent,
+				UnpaidInstallments:   val.MissedInstallments,
+				RemainingOutstanding: val.OutstandingAmount,
+			}, endOfDay(businessDate),
+		)
+
+		if errEvent != nil {
+			return errEvent
+		}
+
+		transitions = append(transitions, transition)
+	}
+
+	return e.tracker.Record(ctx, tx, transitions...)
+}
+
+func (e *eodService) exportGeneralLedger(ctx context.Context, businessDate time.Time) (int, error) {
+	rsp, err := e.journalService.ExportGeneralLedger(
+		ctx, &journal.ExportRequest{
+			Date:   businessDate.Format(dateLayout),
+			Format: e.glFormat,
+			Output: e.glOutput,
+		},
+	)
+
+	if err != nil {
+		return 0, err
+	}
+
+	log.Println("[EOD] general ledger is exported -> ", rsp.File, rsp.Status)
+	return rsp.Manifest.Rows, nil
+}
+
+func (e *eodService) findRun(ctx context.Context, businessDate time.Time) (*RunResponse, error) {
+	runs, err := e.eodRepository.FindRuns(ctx, &repository.EodRunFilter{BusinessDate: businessDate})
+	if err != nil || len(runs) == 0 {
+		return nil, errorFromDatabase
+	}
+
+	return toRunResponse(runs[0]), nil
+}
+
+// endOfDay is the last second of the date, the records of the date are booked at it.
+func endOfDay(businessDate time.Time) time.Time {
+	return businessDate.AddDate(0, 0, 1).Add(-time.Second)
+}
+
+func toRunResponse(run *repository.EodRunEntity) *RunResponse {
+	rsp := &RunResponse{
+		BusinessDate: run.BusinessDate.Format(dateLayout),
+		Status:       run.Status,
+		Error:        run.Error,
+		StartedAt:    run.StartedAt,
+		FinishedAt:   optionalTime(run.FinishedAt),
+		Steps:        make([]*StepResponse, 0, len(run.Steps)),
+	}
+
+	for _, s := range run.Steps {
+		rsp.Steps = append(
+			rsp.Steps, &StepResponse{
+				Step:       s.Step,
+				Sequence:   s.Sequence,
+				Status:     s.Status,
+				Processed:  s.Processed,
+				Error:      s.Error,
+				StartedAt:  s.StartedAt,
+				FinishedAt: optionalTime(s.FinishedAt),
+			},
+		)
+	}
+
+	return rsp
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
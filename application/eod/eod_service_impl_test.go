@@ -0,0 +1,612 @@
+package eod
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/delinquency"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/event"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/journal"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/calendar"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	mocksAccrual "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/application/accrual"
+	mocksJournal "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/application/journal"
+	mocksOverdue "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/application/overdue"
+	mocksCommon "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/common"
+	mocksRepository "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/infrastructure/repository"
+)
+
+type eodMocks struct {
+	overdueService        *mocksOverdue.Service
+	accrualService        *mocksAccrual.Service
+	journalService        *mocksJournal.Service
+	delinquencyRepository *mocksRepository.DelinquencyRepository
+	outboxRepository      *mocksRepository.OutboxRepository
+	eodRepository         *mocksRepository.EodRepository
+	transaction           *mocksRepository.Transaction
+}
+
+func Test_eodService_Close(t *testing.T) {
+	now := time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC)
+	businessDate := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	asOf := time.Date(2026, 10, 18, 23, 59, 59, 0, time.UTC)
+	//2026-10-18 is sunday, the installments due in the weekend are not missed until monday is over
+	businessDays := calendar.NewCalendar(
+		calendar.DefaultRegion, calendar.PolicyForward, []time.Weekday{time.Saturday, time.Sunday}, nil)
+
+	byDate := &repository.EodRunFilter{BusinessDate: businessDate}
+	latest := &repository.EodRunFilter{Limit: 1}
+
+	withTransaction := func(ctx context.Context, fn func(tx *sql.Tx) error) error {
+		return fn(nil)
+	}
+
+	completedStep := func(name string, sequence int, processed int) *repository.EodStepEntity {
+		return &repository.EodStepEntity{
+			BusinessDate: businessDate,
+			Step:         name,
+			Sequence:     sequence,
+			Status:       repository.EodCompleted,
+			Processed:    processed,
+			StartedAt:    now,
+			FinishedAt:   now,
+		}
+	}
+
+	completedRun := &repository.EodRunEntity{
+		BusinessDate: businessDate,
+		Status:       repository.EodCompleted,
+		StartedAt:    now,
+		FinishedAt:   now,
+		Steps: []*repository.EodStepEntity{
+			completedStep(StepOverdueMarking, 1, 3),
+			completedStep(StepPenaltyAccrual, 2, 2),
+			completedStep(StepInterestAccrual, 3, 1),
+			completedStep(StepDelinquencySnapshot, 4, 5),
+			completedStep(StepGLExport, 5, 4),
+		},
+	}
+
+	mockSteps := func(m *eodMocks) {
+		m.overdueService.On("MarkOverdueAsOf", mock.Anything, asOf).Return(3, nil).Once()
+		m.accrualService.On("AccruePenalty", mock.Anything, asOf).Return(2, nil).Once()
+		m.accrualService.On("AccrueInterest", mock.Anything, asOf).Return(1, nil).Once()
+		m.transaction.On("WithTransaction", mock.Anything, mock.Anything).Return(withTransaction).Once()
+		m.delinquencyRepository.
+			On(
+				"SaveSnapshot", mock.Anything, (*sql.Tx)(nil), &repository.DelinquencySnapshot{
+					BusinessDate:                businessDate,
+					DueBefore:                   time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC),
+					MissedTolerance:             delinquency.MissedTolerance,
+					RestructuredMissedTolerance: delinquency.RestructuredMissedTolerance,
+					CreatedAt:                   now,
+				},
+			).
+			Return(5, nil).
+			Once()
+		//the days past due count from the business day after the grace days, e.g. due on sunday 2026-10-11 is 6
+		m.delinquencyRepository.
+			On("FindSnapshotDueDates", mock.Anything, (*sql.Tx)(nil), mock.Anything).
+			Return(
+				[]*repository.SnapshotDueDate{
+					{UserID: "abc", OldestDueDate: time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)},
+					{UserID: "abc", GraceDays: 2, OldestDueDate: time.Date(2026, 10, 9, 0, 0, 0, 0, time.UTC)},
+					{UserID: "def", OldestDueDate: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)},
+					{UserID: "jkl", OldestDueDate: time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC)},
+					{UserID: "ghi", OldestDueDate: time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC)},
+				}, nil,
+			).
+			Once()
+		m.delinquencyRepository.
+			On("UpdateSnapshotDaysPastDue", mock.Anything, (*sql.Tx)(nil), businessDate, 7, []string{"abc"}).
+			Return(nil).
+			Once()
+		m.delinquencyRepository.
+			On("UpdateSnapshotDaysPastDue", mock.Anything, (*sql.Tx)(nil), businessDate, 6, []string{"ghi", "jkl"}).
+			Return(nil).
+			Once()
+		m.delinquencyRepository.
+			On("FindSnapshotTransitions", mock.Anything, (*sql.Tx)(nil), businessDate).
+			Return(
+				[]*repository.DelinquencyTransition{
+					{UserID: "abc", IsDelinquent: true, MissedInstallments: 3, OutstandingAmount: decimal.NewFromInt(330000)},
+				}, nil,
+			).
+			Once()
+		m.delinquencyRepository.
+			On(
+				"UpsertDelinquency", mock.Anything, (*sql.Tx)(nil), &repository.DelinquencyEntity{
+					UserID:       "abc",
+					IsDelinquent: true,
+					UpdatedAt:    asOf,
+				},
+			).
+			Return(nil).
+			Once()
+		m.outboxRepository.
+			On(
+				"SaveOutbox", mock.Anything, (*sql.Tx)(nil),
+				mock.MatchedBy(func(outbox *repository.OutboxEntity) bool {
+					return outbox.EventType == string(event.CustomerBecameDelinquent) &&
+						outbox.AggregateID == "abc" && outbox.OccurredAt.Equal(asOf)
+				})).
+			Return(nil).
+			Once()
+		m.journalService.
+			On(
+				"ExportGeneralLedger", mock.Anything, &journal.ExportRequest{
+					Date:   "2026-10-18",
+					Format: journal.FormatCSV,
+					Output: "/tmp/gl",
+				},
+			).
+			Return(&journal.ExportResponse{File: "gl_20261018.csv", Manifest: &journal.Manifest{Rows: 4}}, nil).
+			Once()
+	}
+
+	tests := []struct {
+		name    string
+		request *CloseRequest
+		// locked tells the lock of the end of day is taken, it is released once the close returns.
+		locked   bool
+		want     *RunResponse
+		wantErr  error
+		mockFunc func(m *eodMocks)
+	}{
+		{
+			name: "given date is not over," +
+				"when close," +
+				"then return error validation",
+			request:  &CloseRequest{Date: "2026-10-19"},
+			wantErr:  errorValidation,
+			mockFunc: func(m *eodMocks) {},
+		},
+		{
+			name: "given invalid date," +
+				"when close," +
+				"then return error validation",
+			request:  &CloseRequest{Date: "18-10-2026"},
+			wantErr:  errorValidation,
+			mockFunc: func(m *eodMocks) {},
+		},
+		{
+			name: "given date is closed," +
+				"when close," +
+				"then return the run as is",
+			request: &CloseRequest{},
+			locked:  true,
+			want:    toRunResponse(completedRun),
+			mockFunc: func(m *eodMocks) {
+				m.eodRepository.
+					On("FindRuns", mock.Anything, byDate).
+					Return([]*repository.EodRunEntity{completedRun}, nil).
+					Once()
+			},
+		},
+		{
+			name: "given previous date is not closed," +
+				"when close," +
+				"then return error out of order",
+			request: &CloseRequest{Date: "2026-10-18"},
+			locked:  true,
+			wantErr: errorOutOfOrder,
+			mockFunc: func(m *eodMocks) {
+				m.eodRepository.On("FindRuns", mock.Anything, byDate).Return(nil, nil).Once()
+				m.eodRepository.
+					On("FindRuns", mock.Anything, latest).
+					Return(
+						[]*repository.EodRunEntity{
+							{BusinessDate: businessDate.AddDate(0, 0, -2), Status: repository.EodCompleted},
+						}, nil,
+					).
+					Once()
+			},
+		},
+		{
+			name: "given previous date is closed," +
+				"when close," +
+				"then run all steps and complete the run",
+			request: &CloseRequest{},
+			locked:  true,
+			want:    toRunResponse(completedRun),
+			mockFunc: func(m *eodMocks) {
+				m.eodRepository.On("FindRuns", mock.Anything, byDate).Return(nil, nil).Once()
+				m.eodRepository.
+					On("FindRuns", mock.Anything, latest).
+					Return(
+						[]*repository.EodRunEntity{
+							{BusinessDate: businessDate.AddDate(0, 0, -1), Status: repository.EodCompleted},
+						}, nil,
+					).
+					Once()
+				m.eodRepository.
+					On(
+						"SaveRun", mock.Anything, mock.MatchedBy(
+							func(run *repository.EodRunEntity) bool {
+								return run.BusinessDate.Equal(businessDate) && run.Status == repository.EodRunning
+							},
+						),
+					).
+					Return(nil).
+					Once()
+				m.eodRepository.On("SaveStep", mock.Anything, mock.Anything).Return(nil).Times(10)
+				mockSteps(m)
+				m.eodRepository.
+					On(
+						"UpdateRun", mock.Anything, mock.MatchedBy(
+							func(run *repository.EodRunEntity) bool {
+								return run.Status == repository.EodCompleted
+							},
+						),
+					).
+					Return(nil).
+					Once()
+				m.eodRepository.
+					On("FindRuns", mock.Anything, byDate).
+					Return([]*repository.EodRunEntity{completedRun}, nil).
+					Once()
+			},
+		},
+		{
+			name: "given failed run," +
+				"when close," +
+				"then resume from the step not completed",
+			request: &CloseRequest{Date: "2026-10-18"},
+			locked:  true,
+			want:    toRunResponse(completedRun),
+			mockFunc: func(m *eodMocks) {
+				failedStep := completedStep(StepInterestAccrual, 3, 0)
+				failedStep.Status = repository.EodFailed
+
+				m.eodRepository.
+					On("FindRuns", mock.Anything, byDate).
+					Return(
+						[]*repository.EodRunEntity{
+							{
+								BusinessDate: businessDate,
+								Status:       repository.EodFailed,
+								Error:        "INTEREST_ACCRUAL : mock error",
+								Steps: []*repository.EodStepEntity{
+									completedStep(StepOverdueMarking, 1, 3),
+									completedStep(StepPenaltyAccrual, 2, 2),
+									failedStep,
+								},
+							},
+						}, nil,
+					).
+					Once()
+				m.eodRepository.
+					On(
+						"UpdateRun", mock.Anything, mock.MatchedBy(
+							func(run *repository.EodRunEntity) bool {
+								return run.Status == repository.EodRunning && run.Error == ""
+							},
+						),
+					).
+					Return(nil).
+					Once()
+				m.eodRepository.On("SaveStep", mock.Anything, mock.Anything).Return(nil).Times(6)
+				m.accrualService.On("AccrueInterest", mock.Anything, asOf).Return(1, nil).Once()
+				m.transaction.On("WithTransaction", mock.Anything, mock.Anything).Return(withTransaction).Once()
+				m.delinquencyRepository.On("SaveSnapshot", mock.Anything, (*sql.Tx)(nil), mock.Anything).Return(5, nil).Once()
+				m.delinquencyRepository.
+					On("FindSnapshotDueDates", mock.Anything, (*sql.Tx)(nil), mock.Anything).
+					Return(nil, nil).
+					Once()
+				m.delinquencyRepository.
+					On("FindSnapshotTransitions", mock.Anything, (*sql.Tx)(nil), businessDate).
+					Return(nil, nil).
+					Once()
+				m.journalService.
+					On("ExportGeneralLedger", mock.Anything, mock.Anything).
+					Return(&journal.ExportResponse{Manifest: &journal.Manifest{Rows: 4}}, nil).
+					Once()
+				m.eodRepository.
+					On(
+						"UpdateRun", mock.Anything, mock.MatchedBy(
+							func(run *repository.EodRunEntity) bool {
+								return run.Status == repository.EodCompleted
+							},
+						),
+					).
+					Return(nil).
+					Once()
+				m.eodRepository.
+					On("FindRuns", mock.Anything, byDate).
+					Return([]*repository.EodRunEntity{completedRun}, nil).
+					Once()
+			},
+		},
+		{
+			name: "given step is failed," +
+				"when close," +
+				"then mark the run failed and stop",
+			request: &CloseRequest{},
+			locked:  true,
+			wantErr: errorStepFailed,
+			mockFunc: func(m *eodMocks) {
+				m.eodRepository.On("FindRuns", mock.Anything, byDate).Return(nil, nil).Once()
+				m.eodRepository.On("FindRuns", mock.Anything, latest).Return(nil, nil).Once()
+				m.eodRepository.On("SaveRun", mock.Anything, mock.Anything).Return(nil).Once()
+				m.eodRepository.On("SaveStep", mock.Anything, mock.Anything).Return(nil).Times(2)
+				m.overdueService.
+					On("MarkOverdueAsOf", mock.Anything, asOf).
+					Return(0, errors.New("mock error")).
+					Once()
+				m.eodRepository.
+					On(
+						"UpdateRun", mock.Anything, mock.MatchedBy(
+							func(run *repository.EodRunEntity) bool {
+								return run.Status == repository.EodFailed &&
+									run.Error == "OVERDUE_MARKING : mock error"
+							},
+						),
+					).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given another run holds the lock," +
+				"when close," +
+				"then return error in progress",
+			request: &CloseRequest{},
+			wantErr: errorInProgress,
+			mockFunc: func(m *eodMocks) {
+				m.eodRepository.On("LockRun", mock.Anything).Return(nil, repository.ErrorDuplicate).Once()
+			},
+		},
+		{
+			name: "given lock is failed," +
+				"when close," +
+				"then return error",
+			request: &CloseRequest{},
+			wantErr: errorFromDatabase,
+			mockFunc: func(m *eodMocks) {
+				m.eodRepository.On("LockRun", mock.Anything).Return(nil, repository.ErrorFromDBLoan).Once()
+			},
+		},
+		{
+			name: "given find runs is failed," +
+				"when close," +
+				"then return error",
+			request: &CloseRequest{},
+			locked:  true,
+			wantErr: errorFromDatabase,
+			mockFunc: func(m *eodMocks) {
+				m.eodRepository.
+					On("FindRuns", mock.Anything, byDate).
+					Return(nil, errors.New("mock error")).
+					Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				m := &eodMocks{
+					overdueService:        &mocksOverdue.Service{},
+					accrualService:        &mocksAccrual.Service{},
+					journalService:        &mocksJournal.Service{},
+					delinquencyRepository: &mocksRepository.DelinquencyRepository{},
+					outboxRepository:      &mocksRepository.OutboxRepository{},
+					eodRepository:         &mocksRepository.EodRepository{},
+					transaction:           &mocksRepository.Transaction{},
+				}
+
+				mockClock := &mocksCommon.Clock{}
+				mockClock.On("Now").Return(now)
+
+				mockGenerate := &mocksCommon.Generate{}
+				mockGenerate.On("Time").Return(now)
+
+				unlocked := 0
+				if tt.locked {
+					m.eodRepository.On("LockRun", mock.Anything).Return(func() { unlocked++ }, nil).Once()
+				}
+
+				tt.mockFunc(m)
+
+				e := &eodService{
+					overdueService:        m.overdueService,
+					accrualService:        m.accrualService,
+					journalService:        m.journalService,
+					delinquencyRepository: m.delinquencyRepository,
+					eodRepository:         m.eodRepository,
+					transaction:           m.transaction,
+					tracker:               delinquency.NewTracker(m.delinquencyRepository, m.outboxRepository),
+					calendar:              businessDays,
+					clock:                 mockClock,
+					generate:              mockGenerate,
+					glFormat:              journal.FormatCSV,
+					glOutput:              "/tmp/gl",
+				}
+
+				got, err := e.Close(context.Background(), tt.request)
+				assert.Equal(t, tt.wantErr, err)
+				assert.Equal(t, tt.want, got)
+				assert.Equal(t, tt.locked, unlocked == 1)
+
+				m.overdueService.AssertExpectations(t)
+				m.accrualService.AssertExpectations(t)
+				m.journalService.AssertExpectations(t)
+				m.delinquencyRepository.AssertExpectations(t)
+				m.outboxRepository.AssertExpectations(t)
+				m.eodRepository.AssertExpectations(t)
+				m.transaction.AssertExpectations(t)
+			},
+		)
+	}
+}
+
+func Test_eodService_FindRuns(t *testing.T) {
+	now := time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC)
+	businessDate := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	run := &repository.EodRunEntity{
+		BusinessDate: businessDate,
+		Status:       repository.EodFailed,
+		Error:        "GL_EXPORT : mock error",
+		StartedAt:    now,
+		FinishedAt:   now,
+	}
+
+	tests := []struct {
+		name     string
+		request  *RunsRequest
+		want     []*RunResponse
+		wantErr  error
+		mockFunc func(mockEodRepo *mocksRepository.EodRepository)
+	}{
+		{
+			name: "given limit over the max," +
+				"when findRuns," +
+				"then return error validation",
+			request:  &RunsRequest{Limit: 101},
+			wantErr:  errorValidation,
+			mockFunc: func(mockEodRepo *mocksRepository.EodRepository) {},
+		},
+		{
+			name: "given find runs is failed," +
+				"when findRuns," +
+				"then return error",
+			request: &RunsRequest{},
+			wantErr: errorFromDatabase,
+			mockFunc: func(mockEodRepo *mocksRepository.EodRepository) {
+				mockEodRepo.
+					On("FindRuns", mock.Anything, &repository.EodRunFilter{Limit: defaultLimit}).
+					Return(nil, errors.New("mock error")).
+					Once()
+			},
+		},
+		{
+			name: "given date," +
+				"when findRuns," +
+				"then return the run of the date",
+			request: &RunsRequest{Date: "2026-10-18"},
+			want: []*RunResponse{
+				{
+					BusinessDate: "2026-10-18",
+					Status:       repository.EodFailed,
+					Error:        "GL_EXPORT : mock error",
+					StartedAt:    now,
+					FinishedAt:   &now,
+					Steps:        []*StepResponse{},
+				},
+			},
+			mockFunc: func(mockEodRepo *mocksRepository.EodRepository) {
+				mockEodRepo.
+					On(
+						"FindRuns", mock.Anything,
+						&repository.EodRunFilter{BusinessDate: businessDate, Limit: defaultLimit},
+					).
+					Return([]*repository.EodRunEntity{run}, nil).
+					Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockEodRepo := &mocksRepository.EodRepository{}
+				tt.mockFunc(mockEodRepo)
+
+				e := &eodService{eodRepository: mockEodRepo}
+
+				got, err := e.FindRuns(context.Background(), tt.request)
+				assert.Equal(t, tt.wantErr, err)
+				assert.Equal(t, tt.want, got)
+
+				mockEodRepo.AssertExpectations(t)
+			},
+		)
+	}
+}
+
+func Test_eodService_AdvanceBusinessDate(t *testing.T) {
+	now := time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		realClock      bool
+		advanceEnabled bool
+		request        *AdvanceRequest
+		want           *BusinessDateResponse
+		wantErr        error
+		mockFunc       func(mockClock *mocksCommon.AdjustableClock)
+	}{
+		{
+			name: "given advance is disabled," +
+				"when advanceBusinessDate," +
+				"then return error clock fixed",
+			request:  &AdvanceRequest{Days: 1},
+			wantErr:  errorClockFixed,
+			mockFunc: func(mockClock *mocksCommon.AdjustableClock) {},
+		},
+		{
+			name: "given real clock," +
+				"when advanceBusinessDate," +
+				"then return error clock fixed",
+			realClock:      true,
+			advanceEnabled: true,
+			request:        &AdvanceRequest{Days: 1},
+			wantErr:        errorClockFixed,
+			mockFunc:       func(mockClock *mocksCommon.AdjustableClock) {},
+		},
+		{
+			name: "given days is not positive," +
+				"when advanceBusinessDate," +
+				"then return error validation",
+			advanceEnabled: true,
+			request:        &AdvanceRequest{Days: 0},
+			wantErr:        errorValidation,
+			mockFunc:       func(mockClock *mocksCommon.AdjustableClock) {},
+		},
+		{
+			name: "given advance is enabled," +
+				"when advanceBusinessDate," +
+				"then move the clock forward by the days",
+			advanceEnabled: true,
+			request:        &AdvanceRequest{Days: 3},
+			want: &BusinessDateResponse{
+				BusinessDate: "2026-10-22",
+				Now:          now.AddDate(0, 0, 3),
+				Mode:         common.ClockOffset,
+			},
+			mockFunc: func(mockClock *mocksCommon.AdjustableClock) {
+				mockClock.On("Advance", 72*time.Hour).Return(now.AddDate(0, 0, 3)).Once()
+				mockClock.On("Mode").Return(common.ClockOffset).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockClock := &mocksCommon.AdjustableClock{}
+				tt.mockFunc(mockClock)
+
+				e := &eodService{clock: mockClock, advanceEnabled: tt.advanceEnabled}
+				if tt.realClock {
+					e.clock = common.NewClock()
+				}
+
+				got, err := e.AdvanceBusinessDate(context.Background(), tt.request)
+				assert.Equal(t, tt.wantErr, err)
+				assert.Equal(t, tt.want, got)
+
+				mockClock.AssertExpectations(t)
+			},
+		)
+	}
+}
@@ -0,0 +1,147 @@
+package event
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+type Type string
+
+const (
+	PaymentSucceeded           Type = "PaymentSucceeded"
+	PaymentReversed            Type = "PaymentReversed"
+	InstallmentPaid            Type = "InstallmentPaid"
+	InstallmentOverdue         Type = "InstallmentOverdue"
+	LoanClosed                 Type = "LoanClosed"
+	LoanRestructured           Type = "LoanRestructured"
+	LoanWrittenOff             Type = "LoanWrittenOff"
+	WriteOffRecovered          Type = "WriteOffRecovered"
+	CustomerBecameDelinquent   Type = "CustomerBecameDelinquent"
+	CustomerDelinquencyCleared Type = "CustomerDelinquencyCleared"
+)
+
+type (
+	// Envelope is the message published to the downstream, Payload depends on the Type.
+	Envelope struct {
+		EventID     string          `json:"event_id"`
+		EventType   Type            `json:"event_type"`
+		AggregateID string          `json:"aggregate_id"`
+		OccurredAt  time.Time       `json:"occurred_at"`
+		Payload     json.RawMessage `json:"payload"`
+	}
+
+	PaymentSucceededPayload struct {
+		PaymentID      string          `json:"payment_id"`
+		UserID         string          `json:"user_id"`
+		Amount         decimal.Decimal `json:"amount"`
+		InstallmentIDs []uint64        `json:"installment_ids"`
+		PaidAt         time.Time       `json:"paid_at"`
+		// Allocation is the amount split into the components of the installments.
+		Allocation repository.Breakdown `json:"allocation"`
+	}
+
+	PaymentReversedPayload struct {
+		ReversalID     string          `json:"reversal_id"`
+		PaymentID      string          `json:"payment_id"`
+		UserID         string          `json:"user_id"`
+		Amount         decimal.Decimal `json:"amount"`
+		InstallmentIDs []uint64        `json:"installment_ids"`
+		Reason         string          `json:"reason"`
+		ReversedAt     time.Time       `json:"reversed_at"`
+	}
+
+	InstallmentPaidPayload struct {
+		PaymentID     string          `json:"payment_id"`
+		InstallmentID uint64          `json:"installment_id"`
+		UserID        string          `json:"user_id"`
+		Amount        decimal.Decimal `json:"amount"`
+		DueDate       time.Time       `json:"due_date"`
+		PaidAt        time.Time       `json:"paid_at"`
+		// Breakdown is the components of the amount.
+		Breakdown repository.Breakdown `json:"breakdown"`
+	}
+
+	InstallmentOverduePayload struct {
+		InstallmentID uint64          `json:"installment_id"`
+		UserID        string          `json:"user_id"`
+		Amount        decimal.Decimal `json:"amount"`
+		DueDate       time.Time       `json:"due_date"`
+		OverdueAt     time.Time       `json:"overdue_at"`
+	}
+
+	LoanClosedPayload struct {
+		UserID   string    `json:"user_id"`
+		ClosedAt time.Time `json:"closed_at"`
+	}
+
+	LoanRestructuredPayload struct {
+		RestructureID  string          `json:"restructure_id"`
+		UserID         string          `json:"user_id"`
+		InstallmentIDs []uint64        `json:"installment_ids"`
+		Outstanding    decimal.Decimal `json:"outstanding"`
+		Tenor          int             `json:"tenor"`
+		Frequency      string          `json:"frequency"`
+		RestructuredAt time.Time       `json:"restructured_at"`
+	}
+
+	LoanWrittenOffPayload struct {
+		WriteOffID     string          `json:"write_off_id"`
+		UserID         string          `json:"user_id"`
+		InstallmentIDs []uint64        `json:"installment_ids"`
+		Amount         decimal.Decimal `json:"amount"`
+		DaysPastDue    int             `json:"days_past_due"`
+		Reason         string          `json:"reason"`
+		WrittenOffAt   time.Time       `json:"written_off_at"`
+	}
+
+	WriteOffRecoveredPayload struct {
+		PaymentID      string          `json:"payment_id"`
+		UserID         string          `json:"user_id"`
+		Amount         decimal.Decimal `json:"amount"`
+		InstallmentIDs []uint64        `json:"installment_ids"`
+		RecoveredAt    time.Time       `json:"recovered_at"`
+	}
+
+	CustomerBecameDelinquentPayload struct {
+		UserID               string          `json:"user_id"`
+		OverdueInstallments  int             `json:"overdue_installments"`
+		RemainingOutstanding decimal.Decimal `json:"remaining_outstanding"`
+		DelinquentAt         time.Time       `json:"delinquent_at"`
+	}
+
+	CustomerDelinquencyClearedPayload struct {
+		UserID    string    `json:"user_id"`
+		ClearedAt time.Time `json:"cleared_at"`
+	}
+)
+
+// NewOutbox builds the outbox row of the event, it should be saved in the same transaction
+// as the state changes which raise the event.
+func NewOutbox(
+	eventID string,
+	eventType Type,
+	aggregateID string,
+	occurredAt time.Time,
+	payload interface{}) (*repository.OutboxEntity, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &repository.OutboxEntity{
+		EventID:     eventID,
+		EventType:   string(eventType),
+		AggregateID: aggregateID,
+		Payload:     data,
+		Status:      repository.OutboxStatusPending,
+		Attempts:    0,
+		OccurredAt:  occurredAt,
+		CreatedAt:   occurredAt,
+		Version:     0,
+		UpdatedAt:   occurredAt,
+	}, nil
+}
@@ -0,0 +1,101 @@
+package journal
+
+import (
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+)
+
+type EntryType string
+
+const (
+	Disbursement EntryType = "DISBURSEMENT"
+	Accrual      EntryType = "ACCRUAL"
+	Payment      EntryType = "PAYMENT"
+	Reversal     EntryType = "REVERSAL"
+	WriteOff     EntryType = "WRITE_OFF"
+	Restructure  EntryType = "RESTRUCTURE"
+	// Penalty is the penalty charged by the end of day into the overdue installment, once per business date.
+	Penalty EntryType = "PENALTY"
+)
+
+// defaultBranch is the branch of the entries when journal.branch is not configured.
+const defaultBranch = "HQ"
+
+// Chart is the chart of accounts, every account is configured by journal.account.<role>
+// and falls back to the default code.
+type Chart struct {
+	// Branch is the branch every entry is booked for, since the customer doesn't carry its branch yet.
+	Branch              string
+	Cash                string
+	PrincipalReceivable string
+	InterestReceivable  string
+	FeeReceivable       string
+	PenaltyReceivable   string
+	// UnearnedInterest and UnearnedFee hold the interest and the fee disbursed but not yet accrued.
+	UnearnedInterest string
+	UnearnedFee      string
+	// Suspense holds the amount paid above the installments.
+	Suspense        string
+	InterestIncome  string
+	FeeIncome       string
+	PenaltyIncome   string
+	RecoveryIncome  string
+	DiscountExpense string
+	WriteOffExpense string
+
+	// generate stamps when the entry is created, the entry is booked at the business date it is given.
+	generate common.Generate
+}
+
+type account struct {
+	role        string
+	name        string
+	defaultCode string
+	code        func(chart *Chart) *string
+}
+
+var accounts = []account{
+	{"cash", "Cash", "1101", func(c *Chart) *string { return &c.Cash }},
+	{"receivable.principal", "Principal Receivable", "1301", func(c *Chart) *string { return &c.PrincipalReceivable }},
+	{"receivable.interest", "Interest Receivable", "1302", func(c *Chart) *string { return &c.InterestReceivable }},
+	{"receivable.fee", "Fee Receivable", "1303", func(c *Chart) *string { return &c.FeeReceivable }},
+	{"receivable.penalty", "Penalty Receivable", "1304", func(c *Chart) *string { return &c.PenaltyReceivable }},
+	{"unearned.interest", "Unearned Interest", "2301", func(c *Chart) *string { return &c.UnearnedInterest }},
+	{"unearned.fee", "Unearned Fee", "2302", func(c *Chart) *string { return &c.UnearnedFee }},
+	{"suspense", "Unapplied Payment", "2901", func(c *Chart) *string { return &c.Suspense }},
+	{"income.interest", "Interest Income", "4101", func(c *Chart) *string { return &c.InterestIncome }},
+	{"income.fee", "Fee Income", "4102", func(c *Chart) *string { return &c.FeeIncome }},
+	{"income.penalty", "Penalty Income", "4103", func(c *Chart) *string { return &c.PenaltyIncome }},
+	{"income.recovery", "Recovery Income", "4104", func(c *Chart) *string { return &c.RecoveryIncome }},
+	{"expense.discount", "Discount Expense", "5101", func(c *Chart) *string { return &c.DiscountExpense }},
+	{"expense.writeoff", "Write-off Expense", "5102", func(c *Chart) *string { return &c.WriteOffExpense }},
+}
+
+func NewChart(cfg configuration.Configuration) *Chart {
+	chart := &Chart{Branch: cfg.GetString("journal.branch"), generate: common.NewGenerate()}
+	if chart.Branch == "" {
+		chart.Branch = defaultBranch
+	}
+
+	for _, a := range accounts {
+		code := cfg.GetString("journal.account." + a.role)
+		if code == "" {
+			code = a.defaultCode
+		}
+
+		*a.code(chart) = code
+	}
+
+	return chart
+}
+
+// Name returns the name of the account, it is empty for the account outside the chart.
+func (c *Chart) Name(code string) string {
+	for _, a := range accounts {
+		if *a.code(c) == code {
+			return a.name
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,305 @@
+package journal
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+// ledger aggregates the lines of the entry per account and side, in the order they are posted.
+type ledger struct {
+	lines   []*repository.JournalLineEntity
+	debits  map[string]*repository.JournalLineEntity
+	credits map[string]*repository.JournalLineEntity
+}
+
+func newLedger() *ledger {
+	return &ledger{
+		debits:  make(map[string]*repository.JournalLineEntity),
+		credits: make(map[string]*repository.JournalLineEntity),
+	}
+}
+
+// debit skips the zero amount, the negative amount is posted to the other side.
+func (l *ledger) debit(account string, amount decimal.Decimal) {
+	if amount.IsNegative() {
+		l.credit(account, amount.Neg())
+		return
+	}
+
+	if amount.IsZero() {
+		return
+	}
+
+	line, ok := l.debits[account]
+	if !ok {
+		line = &repository.JournalLineEntity{Account: account, Debit: decimal.Zero, Credit: decimal.Zero}
+		l.debits[account] = line
+		l.lines = append(l.lines, line)
+	}
+
+	line.Debit = line.Debit.Add(amount)
+}
+
+func (l *ledger) credit(account string, amount decimal.Decimal) {
+	if amount.IsNegative() {
+		l.debit(account, amount.Neg())
+		return
+	}
+
+	if amount.IsZero() {
+		return
+	}
+
+	line, ok := l.credits[account]
+	if !ok {
+		line = &repository.JournalLineEntity{Account: account, Debit: decimal.Zero, Credit: decimal.Zero}
+		l.credits[account] = line
+		l.lines = append(l.lines, line)
+	}
+
+	line.Credit = line.Credit.Add(amount)
+}
+
+// reverse swaps the sides of every line, e.g. to undo the payment.
+func (l *ledger) reverse() {
+	for _, line := range l.lines {
+		line.Debit, line.Credit = line.Credit, line.Debit
+	}
+
+	l.debits, l.credits = l.credits, l.debits
+}
+
+// Reference is the reference of the entry booked per installment, e.g. the accrual.
+func Reference(installmentID uint64) string {
+	return strconv.FormatUint(installmentID, 10)
+}
+
+// PenaltyReference is the reference of the penalty of the installment for the business date.
+func PenaltyReference(installmentID uint64, businessDate time.Time) string {
+	return Reference(installmentID) + "/" + businessDate.Format("20060102")
+}
+
+// Disbursement books the receivables of the new installments against the cash disbursed for the principal,
+// the interest and the fee are unearned until the installments are accrued. The penalty is booked by the accrual.
+func (c *Chart) Disbursement(
+	entryID string,
+	userID string,
+	loans []*repository.LoanEntity,
+	bookedAt time.Time) *repository.JournalEntryEntity {
+	l := newLedger()
+	breakdown := sumBreakdown(loans)
+	breakdown.Penalty = decimal.Zero
+
+	c.receivables(l.debit, breakdown)
+	l.credit(c.Cash, breakdown.Principal)
+	l.credit(c.UnearnedInterest, breakdown.Interest)
+	l.credit(c.UnearnedFee, breakdown.Fee)
+
+	return c.newEntry(entryID, Disbursement, userID, userID, "disbursement of "+userID, bookedAt, l)
+}
+
+// Accrual recognizes the interest and the fee of the installment as income, together with its penalty
+// which isn't receivable until then. It returns nil when the installment has nothing to accrue.
+func (c *Chart) Accrual(
+	entryID string,
+	loan *repository.LoanEntity,
+	bookedAt time.Time) *repository.JournalEntryEntity {
+	l := newLedger()
+
+	l.debit(c.UnearnedInterest, loan.Breakdown.Interest)
+	l.credit(c.InterestIncome, loan.Breakdown.Interest)
+	l.debit(c.UnearnedFee, loan.Breakdown.Fee)
+	l.credit(c.FeeIncome, loan.Breakdown.Fee)
+	l.debit(c.PenaltyReceivable, loan.Breakdown.Penalty)
+	l.credit(c.PenaltyIncome, loan.Breakdown.Penalty)
+
+	if len(l.lines) == 0 {
+		return nil
+	}
+
+	reference := Reference(loan.ID)
+	return c.newEntry(entryID, Accrual, reference, loan.UserID, "accrual of installment "+reference, bookedAt, l)
+}
+
+// PenaltyCharge books the penalty charged into the overdue installment for the business date as income,
+// the installment is accrued already so the penalty is receivable at once.
+func (c *Chart) PenaltyCharge(
+	entryID string,
+	loan *repository.LoanEntity,
+	amount decimal.Decimal,
+	businessDate time.Time,
+	bookedAt time.Time) *repository.JournalEntryEntity {
+	l := newLedger()
+	l.debit(c.PenaltyReceivable, amount)
+	l.credit(c.PenaltyIncome, amount)
+
+	date := businessDate.Format("2006-01-02")
+	return c.newEntry(
+		entryID, Penalty, PenaltyReference(loan.ID, businessDate), loan.UserID,
+		"penalty of installment "+Reference(loan.ID)+" on "+date, bookedAt, l,
+	)
+}
+
+// Payment books the cash received against the receivables of the paid installments, or against the recovery
+// income when they are written off. The installments above the amount (e.g. the payoff discount) are expensed,
+// the amount above the installments is held in suspense.
+func (c *Chart) Payment(
+	entryID string,
+	payment *repository.PaymentEntity,
+	loans []*repository.LoanEntity,
+	bookedAt time.Time) *repository.JournalEntryEntity {
+	l := c.payment(payment, loans)
+
+	return c.newEntry(
+		entryID, Payment, payment.PaymentID, payment.UserID, "payment "+payment.PaymentID, bookedAt, l,
+	)
+}
+
+// Reversal is the mirror of the payment entry.
+func (c *Chart) Reversal(
+	entryID string,
+	reversalID string,
+	payment *repository.PaymentEntity,
+	loans []*repository.LoanEntity,
+	bookedAt time.Time) *repository.JournalEntryEntity {
+	l := c.payment(payment, loans)
+	l.reverse()
+
+	return c.newEntry(
+		entryID, Reversal, reversalID, payment.UserID,
+		"reversal "+reversalID+" of payment "+payment.PaymentID, bookedAt, l,
+	)
+}
+
+// WriteOff charges the receivables of the installments to the expense. The interest and the fee of the installment
+// not yet accrued are still unearned, so they are reversed instead, and its penalty isn't receivable yet.
+func (c *Chart) WriteOff(
+	entryID string,
+	writeOffID string,
+	userID string,
+	loans []*repository.LoanEntity,
+	accrued map[uint64]bool,
+	bookedAt time.Time) *repository.JournalEntryEntity {
+	l := newLedger()
+
+	for _, loan := range loans {
+		breakdown := loan.Breakdown
+		if accrued[loan.ID] {
+			l.debit(c.WriteOffExpense, breakdown.Total())
+		} else {
+			breakdown.Penalty = decimal.Zero
+			l.debit(c.WriteOffExpense, breakdown.Principal)
+			l.debit(c.UnearnedInterest, breakdown.Interest)
+			l.debit(c.UnearnedFee, breakdown.Fee)
+		}
+
+		c.receivables(l.credit, breakdown)
+	}
+
+	return c.newEntry(entryID, WriteOff, writeOffID, userID, "write-off "+writeOffID, bookedAt, l)
+}
+
+// Restructure moves the income accrued from the restructured installments back to unearned, since their amount
+// is accrued again by the new schedule. It returns nil when none of them is accrued.
+func (c *Chart) Restructure(
+	entryID string,
+	restructureID string,
+	userID string,
+	loans []*repository.LoanEntity,
+	accrued map[uint64]bool,
+	bookedAt time.Time) *repository.JournalEntryEntity {
+	l := newLedger()
+
+	for _, loan := range loans {
+		if !accrued[loan.ID] {
+			continue
+		}
+
+		l.debit(c.InterestIncome, loan.Breakdown.Interest)
+		l.credit(c.UnearnedInterest, loan.Breakdown.Interest)
+		l.debit(c.FeeIncome, loan.Breakdown.Fee)
+		l.credit(c.UnearnedFee, loan.Breakdown.Fee)
+		l.debit(c.PenaltyIncome, loan.Breakdown.Penalty)
+		l.credit(c.PenaltyReceivable, loan.Breakdown.Penalty)
+	}
+
+	if len(l.lines) == 0 {
+		return nil
+	}
+
+	return c.newEntry(entryID, Restructure, restructureID, userID, "restructure "+restructureID, bookedAt, l)
+}
+
+func (c *Chart) payment(payment *repository.PaymentEntity, loans []*repository.LoanEntity) *ledger {
+	l := newLedger()
+	l.debit(c.Cash, payment.Amount)
+
+	total := decimal.Zero
+	for _, loan := range loans {
+		total = total.Add(loan.Breakdown.Total())
+
+		if payment.Recovery {
+			l.credit(c.RecoveryIncome, loan.Breakdown.Total())
+			continue
+		}
+
+		c.receivables(l.credit, loan.Breakdown)
+	}
+
+	difference := total.Sub(payment.Amount)
+	if difference.IsPositive() {
+		l.debit(c.DiscountExpense, difference)
+	} else {
+		l.credit(c.Suspense, difference.Neg())
+	}
+
+	return l
+}
+
+func (c *Chart) receivables(post func(account string, amount decimal.Decimal), breakdown repository.Breakdown) {
+	post(c.PrincipalReceivable, breakdown.Principal)
+	post(c.InterestReceivable, breakdown.Interest)
+	post(c.FeeReceivable, breakdown.Fee)
+	post(c.PenaltyReceivable, breakdown.Penalty)
+}
+
+func sumBreakdown(loans []*repository.LoanEntity) repository.Breakdown {
+	breakdown := repository.Breakdown{}
+	for _, loan := range loans {
+		breakdown = breakdown.Add(loan.Breakdown)
+	}
+
+	return breakdown
+}
+
+func (c *Chart) newEntry(
+	entryID string,
+	entryType EntryType,
+	reference string,
+	userID string,
+	description string,
+	bookedAt time.Time,
+	l *ledger) *repository.JournalEntryEntity {
+	for _, line := range l.lines {
+		line.EntryID = entryID
+	}
+
+	now := c.generate.Time()
+	return &repository.JournalEntryEntity{
+		EntryID:     entryID,
+		EntryType:   string(entryType),
+		Reference:   reference,
+		UserID:      userID,
+		Branch:      c.Branch,
+		Description: description,
+		BookedAt:    bookedAt,
+		Lines:       l.lines,
+		CreatedAt:   now,
+		Version:     0,
+		UpdatedAt:   now,
+	}
+}
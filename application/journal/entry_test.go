@@ -0,0 +1,223 @@
+package journal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	mocksConfiguration "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/configuration"
+)
+
+func newTestChart() *Chart {
+	mockCfg := &mocksConfiguration.Configuration{}
+	mockCfg.On("GetString", "journal.account.cash").Return("1000")
+	mockCfg.On("GetString", mock.Anything).Return("")
+
+	return NewChart(mockCfg)
+}
+
+// lines renders the lines as "<account> D|C <amount>", so the expectation reads like the journal.
+func lines(entry *repository.JournalEntryEntity) []string {
+	var rendered []string
+	for _, line := range entry.Lines {
+		if line.Debit.IsPositive() {
+			rendered = append(rendered, line.Account+" D "+line.Debit.String())
+		}
+
+		if line.Credit.IsPositive() {
+			rendered = append(rendered, line.Account+" C "+line.Credit.String())
+		}
+	}
+
+	return rendered
+}
+
+func newTestLoan(id uint64, principal, interest, fee, penalty float64) *repository.LoanEntity {
+	breakdown := repository.Breakdown{
+		Principal: decimal.NewFromFloat(principal),
+		Interest:  decimal.NewFromFloat(interest),
+		Fee:       decimal.NewFromFloat(fee),
+		Penalty:   decimal.NewFromFloat(penalty),
+	}
+
+	return &repository.LoanEntity{ID: id, UserID: "abc", Amount: breakdown.Total(), Breakdown: breakdown}
+}
+
+func Test_NewChart(t *testing.T) {
+	chart := newTestChart()
+
+	assert.Equal(t, "HQ", chart.Branch)
+	assert.Equal(t, "1000", chart.Cash)
+	assert.Equal(t, "1301", chart.PrincipalReceivable)
+	assert.Equal(t, "5102", chart.WriteOffExpense)
+	assert.Equal(t, "Cash", chart.Name("1000"))
+	assert.Equal(t, "", chart.Name("1101"))
+}
+
+func Test_Chart_entries(t *testing.T) {
+	chart := newTestChart()
+	bookedAt := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	loans := []*repository.LoanEntity{newTestLoan(1, 100, 10, 5, 0), newTestLoan(2, 100, 10, 5, 2)}
+	payment := &repository.PaymentEntity{PaymentID: "p-1", UserID: "abc", Amount: decimal.NewFromFloat(232)}
+
+	tests := []struct {
+		name          string
+		entry         func() *repository.JournalEntryEntity
+		wantType      EntryType
+		wantReference string
+		wantLines     []string
+	}{
+		{
+			name: "given the new installments," +
+				"when disbursement," +
+				"then the receivables are booked against the cash and the unearned income",
+			entry: func() *repository.JournalEntryEntity {
+				return chart.Disbursement("je-1", "abc", loans, bookedAt)
+			},
+			wantType:      Disbursement,
+			wantReference: "abc",
+			wantLines: []string{
+				"1301 D 200", "1302 D 20", "1303 D 10", "1000 C 200", "2301 C 20", "2302 C 10",
+			},
+		},
+		{
+			name: "given the installment with penalty," +
+				"when accrual," +
+				"then the interest, the fee and the penalty are recognized as income",
+			entry: func() *repository.JournalEntryEntity {
+				return chart.Accrual("je-1", loans[1], bookedAt)
+			},
+			wantType:      Accrual,
+			wantReference: "2",
+			wantLines:     []string{"2301 D 10", "4101 C 10", "2302 D 5", "4102 C 5", "1304 D 2", "4103 C 2"},
+		},
+		{
+			name: "given the overdue installment," +
+				"when penaltyCharge," +
+				"then the penalty of the date is recognized as income",
+			entry: func() *repository.JournalEntryEntity {
+				return chart.PenaltyCharge("je-1", loans[1], decimal.NewFromFloat(1.15), bookedAt, bookedAt)
+			},
+			wantType:      Penalty,
+			wantReference: "2/20261019",
+			wantLines:     []string{"1304 D 1.15", "4103 C 1.15"},
+		},
+		{
+			name: "given the payment of the installments," +
+				"when payment," +
+				"then the cash is booked against the receivables",
+			entry: func() *repository.JournalEntryEntity {
+				return chart.Payment("je-1", payment, loans, bookedAt)
+			},
+			wantType:      Payment,
+			wantReference: "p-1",
+			wantLines:     []string{"1000 D 232", "1301 C 200", "1302 C 20", "1303 C 10", "1304 C 2"},
+		},
+		{
+			name: "given the payoff discounted below the installments," +
+				"when payment," +
+				"then the discount is expensed",
+			entry: func() *repository.JournalEntryEntity {
+				discounted := &repository.PaymentEntity{PaymentID: "p-2", UserID: "abc", Amount: decimal.NewFromFloat(227)}
+				return chart.Payment("je-1", discounted, loans, bookedAt)
+			},
+			wantType:      Payment,
+			wantReference: "p-2",
+			wantLines:     []string{"1000 D 227", "1301 C 200", "1302 C 20", "1303 C 10", "1304 C 2", "5101 D 5"},
+		},
+		{
+			name: "given the amount above the installments," +
+				"when payment," +
+				"then the excess is held in suspense",
+			entry: func() *repository.JournalEntryEntity {
+				excess := &repository.PaymentEntity{PaymentID: "p-3", UserID: "abc", Amount: decimal.NewFromFloat(120)}
+				return chart.Payment("je-1", excess, loans[:1], bookedAt)
+			},
+			wantType:      Payment,
+			wantReference: "p-3",
+			wantLines:     []string{"1000 D 120", "1301 C 100", "1302 C 10", "1303 C 5", "2901 C 5"},
+		},
+		{
+			name: "given the recovery payment," +
+				"when payment," +
+				"then the cash is booked as recovery income",
+			entry: func() *repository.JournalEntryEntity {
+				recovery := &repository.PaymentEntity{
+					PaymentID: "p-4", UserID: "abc", Amount: decimal.NewFromFloat(115), Recovery: true,
+				}
+				return chart.Payment("je-1", recovery, loans[:1], bookedAt)
+			},
+			wantType:      Payment,
+			wantReference: "p-4",
+			wantLines:     []string{"1000 D 115", "4104 C 115"},
+		},
+		{
+			name: "given the reversal of the payment," +
+				"when reversal," +
+				"then the payment entry is mirrored",
+			entry: func() *repository.JournalEntryEntity {
+				return chart.Reversal("je-1", "r-1", payment, loans, bookedAt)
+			},
+			wantType:      Reversal,
+			wantReference: "r-1",
+			wantLines:     []string{"1000 C 232", "1301 D 200", "1302 D 20", "1303 D 10", "1304 D 2"},
+		},
+		{
+			name: "given the accrued and the not yet accrued installments," +
+				"when writeOff," +
+				"then the accrued is expensed and the unearned is reversed",
+			entry: func() *repository.JournalEntryEntity {
+				return chart.WriteOff("je-1", "w-1", "abc", loans, map[uint64]bool{1: true}, bookedAt)
+			},
+			wantType:      WriteOff,
+			wantReference: "w-1",
+			wantLines: []string{
+				"5102 D 215", "1301 C 200", "1302 C 20", "1303 C 10", "2301 D 10", "2302 D 5",
+			},
+		},
+		{
+			name: "given the accrued installment," +
+				"when restructure," +
+				"then the accrued income is moved back to unearned",
+			entry: func() *repository.JournalEntryEntity {
+				return chart.Restructure("je-1", "rs-1", "abc", loans, map[uint64]bool{2: true}, bookedAt)
+			},
+			wantType:      Restructure,
+			wantReference: "rs-1",
+			wantLines:     []string{"4101 D 10", "2301 C 10", "4102 D 5", "2302 C 5", "4103 D 2", "1304 C 2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				entry := tt.entry()
+
+				assert.True(t, entry.IsBalanced())
+				assert.Equal(t, string(tt.wantType), entry.EntryType)
+				assert.Equal(t, tt.wantReference, entry.Reference)
+				assert.Equal(t, "HQ", entry.Branch)
+				assert.Equal(t, tt.wantLines, lines(entry))
+
+				for _, line := range entry.Lines {
+					assert.Equal(t, "je-1", line.EntryID)
+				}
+			})
+	}
+}
+
+func Test_Chart_nothingToBook(t *testing.T) {
+	chart := newTestChart()
+	bookedAt := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	assert.Nil(t, chart.Accrual("je-1", newTestLoan(1, 100, 0, 0, 0), bookedAt))
+	assert.Nil(
+		t, chart.Restructure(
+			"je-1", "rs-1", "abc", []*repository.LoanEntity{newTestLoan(1, 100, 10, 0, 0)}, map[uint64]bool{}, bookedAt,
+		),
+	)
+}
@@ -0,0 +1,114 @@
+package journal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+var ledgerHeader = []string{
+	"posting_date", "journal_ref", "branch", "account", "account_name", "debit", "credit", "description",
+}
+
+type (
+	// LedgerLine is the line of the import format accepted by the ERP, the amounts are fixed to 2 decimals.
+	LedgerLine struct {
+		Branch      string `json:"branch"`
+		Account     string `json:"account"`
+		AccountName string `json:"account_name"`
+		Debit       string `json:"debit"`
+		Credit      string `json:"credit"`
+		Description string `json:"description"`
+	}
+
+	// Ledger is the journal of the day exported into the general ledger, one line per account and branch.
+	Ledger struct {
+		PostingDate string        `json:"posting_date"`
+		JournalRef  string        `json:"journal_ref"`
+		Lines       []*LedgerLine `json:"lines"`
+		TotalDebit  string        `json:"total_debit"`
+		TotalCredit string        `json:"total_credit"`
+	}
+
+	// Manifest describes the exported file, so the ERP can verify it before the import. It holds nothing
+	// about the run itself, hence the export of the same date gives the same manifest.
+	Manifest struct {
+		PostingDate string `json:"posting_date"`
+		Format      string `json:"format"`
+		File        string `json:"file"`
+		Rows        int    `json:"rows"`
+		// JournalLines is the number of the journal lines aggregated into the rows.
+		JournalLines int    `json:"journal_lines"`
+		TotalDebit   string `json:"total_debit"`
+		TotalCredit  string `json:"total_credit"`
+		// Checksum is the hex SHA-256 of the file.
+		Checksum string `json:"sha256"`
+	}
+)
+
+// WriteLedger writes the ledger in the format, csv or json.
+func WriteLedger(writer io.Writer, format string, ledger *Ledger) error {
+	if format == FormatJSON {
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(ledger)
+	}
+
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(ledgerHeader); err != nil {
+		return err
+	}
+
+	for _, line := range ledger.Lines {
+		errWrite := csvWriter.Write(
+			[]string{
+				ledger.PostingDate,
+				ledger.JournalRef,
+				line.Branch,
+				line.Account,
+				line.AccountName,
+				line.Debit,
+				line.Credit,
+				line.Description,
+			},
+		)
+
+		if errWrite != nil {
+			return errWrite
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// ledgerFile is the name of the file exported for the date, e.g. gl_20261017.csv.
+func ledgerFile(date string, format string) string {
+	return "gl_" + compactDate(date) + "." + format
+}
+
+func manifestFile(date string) string {
+	return "gl_" + compactDate(date) + ".manifest.json"
+}
+
+func compactDate(date string) string {
+	return strings.ReplaceAll(date, "-", "")
+}
+
+func journalRef(date string) string {
+	return "BILLING-" + compactDate(date)
+}
+
+func lineDescription(name string, account string, date string) string {
+	if name == "" {
+		name = "account " + account
+	}
+
+	return "billing " + name + " " + date
+}
@@ -0,0 +1,21 @@
+package journal
+
+import (
+	"net/http"
+)
+
+type (
+	journalController struct {
+		srv Service
+	}
+
+	Controller interface {
+		FindBalances(writer http.ResponseWriter, req *http.Request)
+	}
+)
+
+func NewJournalController(srv Service) Controller {
+	return &journalController{
+		srv: srv,
+	}
+}
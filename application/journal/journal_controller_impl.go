@@ -0,0 +1,50 @@
+package journal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/constant"
+)
+
+func (j *journalController) FindBalances(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	result, err := j.srv.FindBalances(
+		ctx, &BalanceRequest{
+			Date:    req.URL.Query().Get("date"),
+			Account: req.URL.Query().Get("account"),
+		},
+	)
+
+	if err != nil {
+		toErrorResponse(writer, err)
+		return
+	}
+
+	common.ToSuccessResponse(writer, nil, result)
+}
+
+func toErrorResponse(writer http.ResponseWriter, err error) {
+	billingErr := func() constant.BillingSrvHttpError {
+		switch {
+		case errors.Is(err, errorValidation):
+			return constant.Validation
+		default:
+			return constant.GeneralError
+		}
+	}()
+
+	common.ToErrorResponse(
+		writer,
+		constant.HttpRc[billingErr],
+		constant.HttpRcDescription[billingErr],
+	)
+}
@@ -0,0 +1,82 @@
+package journal
+
+import (
+	"context"
+
+	"github.com/shopspring/decimal"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+type (
+	journalService struct {
+		chart             *Chart
+		journalRepository repository.JournalRepository
+		// clock tells the date which is over, hence can be exported.
+		clock common.Clock
+	}
+
+	BalanceRequest struct {
+		// Date is YYYY-MM-DD, the balance is at the end of the day. It is today when empty.
+		Date    string `json:"date,omitempty"`
+		Account string `json:"account,omitempty"`
+	}
+
+	AccountBalance struct {
+		Account string          `json:"account"`
+		Name    string          `json:"name"`
+		Debit   decimal.Decimal `json:"debit"`
+		Credit  decimal.Decimal `json:"credit"`
+		// Balance is the debit minus the credit.
+		Balance decimal.Decimal `json:"balance"`
+	}
+
+	BalanceResponse struct {
+		Date        string            `json:"date"`
+		Accounts    []*AccountBalance `json:"accounts"`
+		TotalDebit  decimal.Decimal   `json:"total_debit"`
+		TotalCredit decimal.Decimal   `json:"total_credit"`
+		// Balanced reports whether the total debit is equal to the total credit,
+		// it is always true unless the ledger is corrupted or filtered by the account.
+		Balanced bool `json:"balanced"`
+	}
+
+	ExportRequest struct {
+		// Date is YYYY-MM-DD, the day should be over so its journal doesn't change anymore.
+		Date string `json:"date"`
+		// Format is csv or json, it is csv when empty.
+		Format string `json:"format,omitempty"`
+		// Output is the directory of the exported file and its manifest, it is the working directory when empty.
+		Output string `json:"output,omitempty"`
+	}
+
+	ExportResponse struct {
+		File     string    `json:"file"`
+		Manifest *Manifest `json:"manifest"`
+		// Status is CREATED for the first export of the date, UNCHANGED when the same file was exported before,
+		// REPLACED when the file exported before has a different checksum.
+		Status string `json:"status"`
+	}
+
+	// Service queries the double-entry journal booked by the financial events.
+	Service interface {
+		FindBalances(ctx context.Context, request *BalanceRequest) (*BalanceResponse, error)
+
+		// ExportGeneralLedger aggregates the journal lines of the date per account and branch into the file
+		// imported by the ERP, together with its checksum manifest. It is safe to run again for the same date.
+		ExportGeneralLedger(ctx context.Context, request *ExportRequest) (*ExportResponse, error)
+	}
+)
+
+func NewJournalService(
+	cfg configuration.Configuration,
+	journalRepository repository.JournalRepository,
+	clock common.Clock) Service {
+	return &journalService{
+		chart:             NewChart(cfg),
+		journalRepository: journalRepository,
+		clock:             clock,
+	}
+}
@@ -0,0 +1,248 @@
+package journal
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	ExportCreated   = "CREATED"
+	ExportUnchanged = "UNCHANGED"
+	ExportReplaced  = "REPLACED"
+)
+
+var (
+	errorValidation   = errors.New("validation request")
+	errorFromDatabase = errors.New("from database")
+	errorUnbalanced   = errors.New("journal of the date is not balanced")
+	errorWriteFile    = errors.New("write export file")
+)
+
+func (j *journalService) FindBalances(
+	ctx context.Context,
+	request *BalanceRequest) (rsp *BalanceResponse, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Println("unidentified error (yet)", string(debug.Stack()))
+			err = errorFromDatabase
+			return
+		}
+	}()
+
+	now := j.clock.Now()
+	date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if request.Date != "" {
+		parsed, errParse := time.ParseInLocation(dateLayout, request.Date, now.Location())
+		if errParse != nil {
+			return nil, errorValidation
+		}
+
+		date = parsed
+	}
+
+	balances, err := j.journalRepository.FindBalances(
+		ctx, &repository.JournalBalanceFilter{
+			BookedBefore: date.AddDate(0, 0, 1),
+			Account:      request.Account,
+		},
+	)
+
+	if err != nil {
+		return nil, errorFromDatabase
+	}
+
+	response := &BalanceResponse{
+		Date:        date.Format(dateLayout),
+		Accounts:    make([]*AccountBalance, 0, len(balances)),
+		TotalDebit:  decimal.Zero,
+		TotalCredit: decimal.Zero,
+	}
+
+	for _, balance := range balances {
+		response.Accounts = append(
+			response.Accounts, &AccountBalance{
+				Account: balance.Account,
+				Name:    j.chart.Name(balance.Account),
+				Debit:   balance.Debit,
+				Credit:  balance.Credit,
+				Balance: balance.Debit.Sub(balance.Credit),
+			},
+		)
+
+		response.TotalDebit = response.TotalDebit.Add(balance.Debit)
+		response.TotalCredit = response.TotalCredit.Add(balance.Credit)
+	}
+
+	response.Balanced = response.TotalDebit.Equal(response.TotalCredit)
+	return response, nil
+}
+
+func (j *journalService) ExportGeneralLedger(
+	ctx context.Context,
+	request *ExportRequest) (rsp *ExportResponse, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Println("unidentified error (yet)", string(debug.Stack()))
+			err = errorFromDatabase
+			return
+		}
+	}()
+
+	format := request.Format
+	if format == "" {
+		format = FormatCSV
+	}
+
+	if format != FormatCSV && format != FormatJSON {
+		return nil, errorValidation
+	}
+
+	now := j.clock.Now()
+	date, errParse := time.ParseInLocation(dateLayout, request.Date, now.Location())
+	if errParse != nil {
+		return nil, errorValidation
+	}
+
+	//the journal of the day is still booked until the day is over
+	if !date.AddDate(0, 0, 1).Before(now) {
+		log.Println("the day is not over yet -> ", request.Date)
+		return nil, errorValidation
+	}
+
+	totals, err := j.journalRepository.FindTotals(
+		ctx, &repository.JournalTotalFilter{
+			BookedFrom:   date,
+			BookedBefore: date.AddDate(0, 0, 1),
+		},
+	)
+
+	if err != nil {
+		return nil, errorFromDatabase
+	}
+
+	postingDate := date.Format(dateLayout)
+	ledger := &Ledger{
+		PostingDate: postingDate,
+		JournalRef:  journalRef(postingDate),
+		Lines:       make([]*LedgerLine, 0, len(totals)),
+	}
+
+	totalDebit, totalCredit, journalLines := decimal.Zero, decimal.Zero, 0
+	for _, total := range totals {
+		name := j.chart.Name(total.Account)
+		ledger.Lines = append(
+			ledger.Lines, &LedgerLine{
+				Branch:      total.Branch,
+				Account:     total.Account,
+				AccountName: name,
+				Debit:       total.Debit.StringFixed(2),
+				Credit:      total.Credit.StringFixed(2),
+				Description: lineDescription(name, total.Account, postingDate),
+			},
+		)
+
+		totalDebit = totalDebit.Add(total.Debit)
+		totalCredit = totalCredit.Add(total.Credit)
+		journalLines += total.Lines
+	}
+
+	if !totalDebit.Equal(totalCredit) {
+		log.Println("journal is not balanced -> ", postingDate, totalDebit, totalCredit)
+		return nil, errorUnbalanced
+	}
+
+	ledger.TotalDebit = totalDebit.StringFixed(2)
+	ledger.TotalCredit = totalCredit.StringFixed(2)
+
+	var content bytes.Buffer
+	if errWrite := WriteLedger(&content, format, ledger); errWrite != nil {
+		log.Println("failed write ledger -> ", errWrite)
+		return nil, errorWriteFile
+	}
+
+	checksum := sha256.Sum256(content.Bytes())
+	manifest := &Manifest{
+		PostingDate:  postingDate,
+		Format:       format,
+		File:         ledgerFile(postingDate, format),
+		Rows:         len(ledger.Lines),
+		JournalLines: journalLines,
+		TotalDebit:   ledger.TotalDebit,
+		TotalCredit:  ledger.TotalCredit,
+		Checksum:     hex.EncodeToString(checksum[:]),
+	}
+
+	rsp = &ExportResponse{
+		File:     filepath.Join(request.Output, manifest.File),
+		Manifest: manifest,
+		Status:   ExportCreated,
+	}
+
+	var replaced string
+	manifestPath := filepath.Join(request.Output, manifestFile(postingDate))
+	if previous, errRead := os.ReadFile(manifestPath); errRead == nil {
+		var exported Manifest
+		if errDecode := json.Unmarshal(previous, &exported); errDecode == nil && exported == *manifest {
+			if _, errStat := os.Stat(rsp.File); errStat == nil {
+				rsp.Status = ExportUnchanged
+				return rsp, nil
+			}
+		}
+
+		log.Println("the export of the date is replaced -> ", postingDate, exported.Format, exported.Checksum)
+		rsp.Status = ExportReplaced
+
+		//exported in the other format, its file is no longer described by the manifest
+		if exported.File != "" && exported.File != manifest.File {
+			replaced = filepath.Join(request.Output, filepath.Base(exported.File))
+		}
+	}
+
+	encoded, _ := json.MarshalIndent(manifest, "", "  ")
+
+	//the manifest is written last, so the ERP never verifies the file being written
+	if errWrite := writeFile(rsp.File, content.Bytes()); errWrite != nil {
+		log.Println("failed write ledger file -> ", errWrite)
+		return nil, errorWriteFile
+	}
+
+	if errWrite := writeFile(manifestPath, append(encoded, '\n')); errWrite != nil {
+		log.Println("failed write manifest file -> ", errWrite)
+		return nil, errorWriteFile
+	}
+
+	//removed after the manifest is replaced, so the manifest never describes the missing file
+	if replaced != "" {
+		if errRemove := os.Remove(replaced); errRemove != nil && !errors.Is(errRemove, os.ErrNotExist) {
+			log.Println("failed remove replaced ledger file -> ", errRemove)
+			return nil, errorWriteFile
+		}
+	}
+
+	return rsp, nil
+}
+
+// writeFile replaces the file through a temporary file, so the file is never read half written.
+func writeFile(path string, content []byte) error {
+	temporary := path + ".tmp"
+	if err := os.WriteFile(temporary, content, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(temporary, path)
+}
@@ -0,0 +1,265 @@
+package journal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	mocksCommon "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/common"
+	mocksRepository "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/infrastructure/repository"
+)
+
+func Test_journalService_FindBalances(t *testing.T) {
+	now := time.Date(2026, 10, 19, 21, 0, 0, 0, time.UTC)
+
+	balances := []*repository.AccountBalanceEntity{
+		{Account: "1000", Debit: decimal.NewFromFloat(232), Credit: decimal.NewFromFloat(200)},
+		{Account: "1301", Debit: decimal.NewFromFloat(200), Credit: decimal.NewFromFloat(200)},
+		{Account: "2901", Debit: decimal.Zero, Credit: decimal.NewFromFloat(32)},
+	}
+
+	tests := []struct {
+		name         string
+		request      *BalanceRequest
+		wantFilter   *repository.JournalBalanceFilter
+		repoErr      error
+		wantDate     string
+		wantBalanced bool
+		wantErr      error
+	}{
+		{
+			name: "given no date," +
+				"when findBalances," +
+				"then return the balances at the end of today",
+			request:      &BalanceRequest{},
+			wantFilter:   &repository.JournalBalanceFilter{BookedBefore: time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)},
+			wantDate:     "2026-10-19",
+			wantBalanced: true,
+		},
+		{
+			name: "given the date and the account," +
+				"when findBalances," +
+				"then return the balance of the account at the end of the date",
+			request: &BalanceRequest{Date: "2026-10-01", Account: "1000"},
+			wantFilter: &repository.JournalBalanceFilter{
+				BookedBefore: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC),
+				Account:      "1000",
+			},
+			wantDate:     "2026-10-01",
+			wantBalanced: true,
+		},
+		{
+			name: "given the invalid date," +
+				"when findBalances," +
+				"then return error validation",
+			request: &BalanceRequest{Date: "01-10-2026"},
+			wantErr: errorValidation,
+		},
+		{
+			name: "given find balances is failed," +
+				"when findBalances," +
+				"then return error",
+			request:    &BalanceRequest{},
+			wantFilter: &repository.JournalBalanceFilter{BookedBefore: time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)},
+			repoErr:    errors.New("mock error"),
+			wantErr:    errorFromDatabase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockJournalRepo := &mocksRepository.JournalRepository{}
+				mockClock := &mocksCommon.Clock{}
+				mockClock.On("Now").Return(now)
+
+				if tt.wantFilter != nil {
+					var result []*repository.AccountBalanceEntity
+					if tt.repoErr == nil {
+						result = balances
+					}
+
+					mockJournalRepo.
+						On("FindBalances", mock.Anything, tt.wantFilter).
+						Return(result, tt.repoErr).
+						Once()
+				}
+
+				j := &journalService{
+					chart:             newTestChart(),
+					journalRepository: mockJournalRepo,
+					clock:             mockClock,
+				}
+
+				got, err := j.FindBalances(context.Background(), tt.request)
+
+				assert.Equal(t, tt.wantErr, err)
+				if tt.wantErr == nil {
+					assert.Equal(t, tt.wantDate, got.Date)
+					assert.Equal(t, tt.wantBalanced, got.Balanced)
+					assert.Len(t, got.Accounts, 3)
+					assert.Equal(t, "Cash", got.Accounts[0].Name)
+					assert.True(t, got.Accounts[0].Balance.Equal(decimal.NewFromFloat(32)))
+					assert.True(t, got.Accounts[2].Balance.Equal(decimal.NewFromFloat(-32)))
+				}
+				mockJournalRepo.AssertExpectations(t)
+			})
+	}
+}
+
+func Test_journalService_ExportGeneralLedger(t *testing.T) {
+	now := time.Date(2026, 10, 19, 21, 0, 0, 0, time.UTC)
+	wantFilter := &repository.JournalTotalFilter{
+		BookedFrom:   time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC),
+		BookedBefore: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+	}
+
+	totals := func(cash float64) []*repository.AccountTotalEntity {
+		return []*repository.AccountTotalEntity{
+			{Account: "1000", Branch: "HQ", Debit: decimal.NewFromFloat(cash), Credit: decimal.Zero, Lines: 2},
+			{Account: "1301", Branch: "HQ", Debit: decimal.Zero, Credit: decimal.NewFromFloat(200), Lines: 2},
+			{Account: "2901", Branch: "HQ", Debit: decimal.Zero, Credit: decimal.NewFromFloat(32), Lines: 1},
+		}
+	}
+
+	wantCSV := "posting_date,journal_ref,branch,account,account_name,debit,credit,description\n" +
+		"2026-10-17,BILLING-20261017,HQ,1000,Cash,232.00,0.00,billing Cash 2026-10-17\n" +
+		"2026-10-17,BILLING-20261017,HQ,1301,Principal Receivable,0.00,200.00,billing Principal Receivable 2026-10-17\n" +
+		"2026-10-17,BILLING-20261017,HQ,2901,Unapplied Payment,0.00,32.00,billing Unapplied Payment 2026-10-17\n"
+
+	type export struct {
+		request    *ExportRequest
+		totals     []*repository.AccountTotalEntity
+		repoErr    error
+		wantStatus string
+		wantErr    error
+	}
+
+	tests := []struct {
+		name    string
+		exports []export
+		wantCSV bool
+	}{
+		{
+			name: "given the closed date," +
+				"when exportGeneralLedger," +
+				"then the csv and its manifest are created",
+			exports: []export{
+				{request: &ExportRequest{Date: "2026-10-17"}, totals: totals(232), wantStatus: ExportCreated},
+			},
+			wantCSV: true,
+		},
+		{
+			name: "given the date exported before," +
+				"when exportGeneralLedger again," +
+				"then the same file is unchanged",
+			exports: []export{
+				{request: &ExportRequest{Date: "2026-10-17"}, totals: totals(232), wantStatus: ExportCreated},
+				{request: &ExportRequest{Date: "2026-10-17"}, totals: totals(232), wantStatus: ExportUnchanged},
+			},
+			wantCSV: true,
+		},
+		{
+			name: "given the date exported before in the other format," +
+				"when exportGeneralLedger," +
+				"then the export is replaced and the file of the other format is removed",
+			exports: []export{
+				{request: &ExportRequest{Date: "2026-10-17", Format: "json"}, totals: totals(232), wantStatus: ExportCreated},
+				{request: &ExportRequest{Date: "2026-10-17", Format: "csv"}, totals: totals(232), wantStatus: ExportReplaced},
+			},
+			wantCSV: true,
+		},
+		{
+			name: "given the day is not over," +
+				"when exportGeneralLedger," +
+				"then return error validation",
+			exports: []export{
+				{request: &ExportRequest{Date: "2026-10-19"}, wantErr: errorValidation},
+			},
+		},
+		{
+			name: "given the unknown format," +
+				"when exportGeneralLedger," +
+				"then return error validation",
+			exports: []export{
+				{request: &ExportRequest{Date: "2026-10-17", Format: "xlsx"}, wantErr: errorValidation},
+			},
+		},
+		{
+			name: "given the journal of the date is not balanced," +
+				"when exportGeneralLedger," +
+				"then return error unbalanced and nothing is written",
+			exports: []export{
+				{request: &ExportRequest{Date: "2026-10-17"}, totals: totals(231), wantErr: errorUnbalanced},
+			},
+		},
+		{
+			name: "given find totals is failed," +
+				"when exportGeneralLedger," +
+				"then return error",
+			exports: []export{
+				{request: &ExportRequest{Date: "2026-10-17"}, repoErr: errors.New("mock error"), wantErr: errorFromDatabase},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				output := t.TempDir()
+				mockJournalRepo := &mocksRepository.JournalRepository{}
+				mockClock := &mocksCommon.Clock{}
+				mockClock.On("Now").Return(now)
+
+				j := &journalService{
+					chart:             newTestChart(),
+					journalRepository: mockJournalRepo,
+					clock:             mockClock,
+				}
+
+				for _, e := range tt.exports {
+					if e.totals != nil || e.repoErr != nil {
+						mockJournalRepo.
+							On("FindTotals", mock.Anything, wantFilter).
+							Return(e.totals, e.repoErr).
+							Once()
+					}
+
+					e.request.Output = output
+					got, err := j.ExportGeneralLedger(context.Background(), e.request)
+
+					assert.Equal(t, e.wantErr, err)
+					if e.wantErr == nil {
+						assert.Equal(t, e.wantStatus, got.Status)
+						assert.Equal(t, 3, got.Manifest.Rows)
+						assert.Equal(t, 5, got.Manifest.JournalLines)
+						assert.Equal(t, "232.00", got.Manifest.TotalDebit)
+						assert.Len(t, got.Manifest.Checksum, 64)
+					}
+				}
+
+				if tt.wantCSV {
+					content, err := os.ReadFile(filepath.Join(output, "gl_20261017.csv"))
+					assert.Nil(t, err)
+					assert.Equal(t, wantCSV, string(content))
+					assert.FileExists(t, filepath.Join(output, "gl_20261017.manifest.json"))
+
+					//nothing else is left behind, e.g. the file of the format exported before
+					files, _ := os.ReadDir(output)
+					assert.Len(t, files, 2)
+				} else {
+					files, _ := os.ReadDir(output)
+					assert.Empty(t, files)
+				}
+				mockJournalRepo.AssertExpectations(t)
+			})
+	}
+}
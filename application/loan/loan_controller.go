@@ -13,6 +13,18 @@ type (
 		FindOutstanding(writer http.ResponseWriter, req *http.Request)
 
 		Payment(writer http.ResponseWriter, req *http.Request)
+
+		CreateQris(writer http.ResponseWriter, req *http.Request)
+
+		FindPayment(writer http.ResponseWriter, req *http.Request)
+
+		PayoffQuote(writer http.ResponseWriter, req *http.Request)
+
+		ReversePayment(writer http.ResponseWriter, req *http.Request)
+
+		Restructure(writer http.ResponseWriter, req *http.Request)
+
+		WriteOff(writer http.ResponseWriter, req *http.Request)
 	}
 )
 
@@ -2,14 +2,9 @@ package loan
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"regexp"
-	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -39,28 +34,11 @@ func (l *loanController) FindOutstanding(
 
 	result, err := l.srv.FetchOutstanding(ctx, userID)
 	if err != nil {
-		if errors.Is(err, errorValidation) {
-			common.ToErrorResponse(
-				writer,
-				constant.HttpRc[constant.Validation],
-				constant.HttpRcDescription[constant.Validation],
-			)
-			return
-		}
-
-		if errors.Is(err, errorDataNotExists) {
-			common.ToErrorResponse(
-				writer,
-				constant.HttpRc[constant.DataNotFound],
-				constant.HttpRcDescription[constant.DataNotFound],
-			)
-			return
-		}
-
+		billingErr := MapError(err)
 		common.ToErrorResponse(
 			writer,
-			constant.HttpRc[constant.GeneralError],
-			constant.HttpRcDescription[constant.GeneralError],
+			constant.HttpRc[billingErr],
+			constant.HttpRcDescription[billingErr],
 		)
 		return
 	}
@@ -72,7 +50,7 @@ func (l *loanController) Payment(
 	writer http.ResponseWriter,
 	req *http.Request) {
 	var paymentRequest PaymentRequest
-	err := decodeJSONBody(writer, req, &paymentRequest)
+	err := common.DecodeJSONBody(writer, req, &paymentRequest)
 
 	if err != nil {
 		log.Println("validation decode json body -> ", err)
@@ -89,114 +67,236 @@ func (l *loanController) Payment(
 	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
 	defer cancelFunc()
 
-	errPayment := l.srv.Payment(ctx, &paymentRequest)
-
+	result, errPayment := l.srv.Payment(ctx, &paymentRequest)
 	if errPayment != nil {
-		if errors.Is(errPayment, errorValidation) {
-			common.ToErrorResponse(
-				writer,
-				constant.HttpRc[constant.Validation],
-				constant.HttpRcDescription[constant.Validation],
-			)
-			return
-		}
+		billingErr := MapError(errPayment)
+		common.ToErrorResponse(
+			writer,
+			constant.HttpRc[billingErr],
+			constant.HttpRcDescription[billingErr],
+		)
+		return
+	}
 
-		if errors.Is(errPayment, errorAmountShouldBeSame) {
-			common.ToErrorResponse(
-				writer,
-				constant.HttpRc[constant.PaymentAmountShouldBeEquals],
-				constant.HttpRcDescription[constant.PaymentAmountShouldBeEquals],
-			)
-			return
-		}
+	common.ToSuccessResponse(writer, nil, result)
+}
 
-		if errors.Is(errPayment, errorNoPendingOutstanding) {
-			common.ToErrorResponse(
-				writer,
-				constant.HttpRc[constant.ZeroOutstanding],
-				constant.HttpRcDescription[constant.ZeroOutstanding],
-			)
-			return
-		}
+func (l *loanController) CreateQris(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	var qrisRequest QrisRequest
+	err := common.DecodeJSONBody(writer, req, &qrisRequest)
+
+	if err != nil {
+		log.Println("validation decode json body -> ", err)
 
 		common.ToErrorResponse(
 			writer,
-			constant.HttpRc[constant.GeneralError],
-			constant.HttpRcDescription[constant.GeneralError],
+			constant.HttpRc[constant.Validation],
+			constant.HttpRcDescription[constant.Validation],
 		)
 		return
 	}
 
-	common.ToSuccessResponse(writer, nil, nil)
-}
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	result, errQris := l.srv.CreateQris(ctx, &qrisRequest)
+	if errQris != nil {
+		billingErr := MapError(errQris)
+		common.ToErrorResponse(
+			writer,
+			constant.HttpRc[billingErr],
+			constant.HttpRcDescription[billingErr],
+		)
+		return
+	}
 
-type malformedRequestError struct {
-	message string
+	common.ToSuccessResponse(writer, nil, result)
 }
 
-func (m malformedRequestError) Error() string {
-	return m.message
+func (l *loanController) FindPayment(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	query := mux.Vars(req)
+	paymentID, errEscape := escapeSpecialCharacter(query["paymentID"])
+
+	if errEscape != nil {
+		common.ToErrorResponse(
+			writer,
+			constant.HttpRc[constant.Validation],
+			constant.HttpRcDescription[constant.Validation],
+		)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	result, err := l.srv.FindPayment(ctx, paymentID)
+	if err != nil {
+		billingErr := MapError(err)
+		common.ToErrorResponse(
+			writer,
+			constant.HttpRc[billingErr],
+			constant.HttpRcDescription[billingErr],
+		)
+		return
+	}
+
+	common.ToSuccessResponse(writer, nil, result)
 }
 
-func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) error {
-	contentType := r.Header.Get("Content-Type")
+func (l *loanController) PayoffQuote(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	query := mux.Vars(req)
+	userID, errEscape := escapeSpecialCharacter(query["userID"])
 
-	if contentType != "application/json" {
-		message := "Content-Type header is not application/json"
-		return &malformedRequestError{message: message}
+	if errEscape != nil {
+		common.ToErrorResponse(
+			writer,
+			constant.HttpRc[constant.Validation],
+			constant.HttpRcDescription[constant.Validation],
+		)
+		return
 	}
 
-	r.Body = http.MaxBytesReader(w, r.Body, 1048576)
-	dec := json.NewDecoder(r.Body)
-	dec.DisallowUnknownFields()
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
 
-	err := dec.Decode(&dst)
+	result, err := l.srv.PayoffQuote(ctx, userID)
 	if err != nil {
-		var syntaxError *json.SyntaxError
-		var unmarshalTypeError *json.UnmarshalTypeError
+		billingErr := MapError(err)
+		common.ToErrorResponse(
+			writer,
+			constant.HttpRc[billingErr],
+			constant.HttpRcDescription[billingErr],
+		)
+		return
+	}
 
-		switch {
-		case errors.As(err, &syntaxError):
-			message := fmt.Sprintf(
-				"Request body contains badly-formed JSON (at position %d)",
-				syntaxError.Offset)
-			return &malformedRequestError{message: message}
+	common.ToSuccessResponse(writer, nil, result)
+}
 
-		case errors.Is(err, io.ErrUnexpectedEOF):
-			message := "Request body contains badly-formed JSON"
-			return &malformedRequestError{message: message}
+func (l *loanController) ReversePayment(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	query := mux.Vars(req)
+	paymentID, errEscape := escapeSpecialCharacter(query["paymentID"])
+
+	var reversalRequest ReversalRequest
+	err := common.DecodeJSONBody(writer, req, &reversalRequest)
+
+	if errEscape != nil || err != nil {
+		log.Println("validation decode json body -> ", err)
+
+		common.ToErrorResponse(
+			writer,
+			constant.HttpRc[constant.Validation],
+			constant.HttpRcDescription[constant.Validation],
+		)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	reversalRequest.PaymentID = paymentID
+	result, errReversal := l.srv.ReversePayment(ctx, &reversalRequest)
+	if errReversal != nil {
+		billingErr := MapError(errReversal)
+		common.ToErrorResponse(
+			writer,
+			constant.HttpRc[billingErr],
+			constant.HttpRcDescription[billingErr],
+		)
+		return
+	}
+
+	common.ToSuccessResponse(writer, nil, result)
+}
+
+func (l *loanController) Restructure(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	query := mux.Vars(req)
+	userID, errEscape := escapeSpecialCharacter(query["userID"])
+
+	var restructureRequest RestructureRequest
+	err := common.DecodeJSONBody(writer, req, &restructureRequest)
+
+	if errEscape != nil || err != nil {
+		log.Println("validation decode json body -> ", err)
+
+		common.ToErrorResponse(
+			writer,
+			constant.HttpRc[constant.Validation],
+			constant.HttpRcDescription[constant.Validation],
+		)
+		return
+	}
 
-		case errors.As(err, &unmarshalTypeError):
-			message := fmt.Sprintf(
-				"Request body contains an invalid value for the %q field (at position %d)",
-				unmarshalTypeError.Field, unmarshalTypeError.Offset)
-			return &malformedRequestError{message: message}
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
 
-		case strings.HasPrefix(err.Error(), "json: unknown field "):
-			fieldName := strings.TrimPrefix(err.Error(), "json: unknown field ")
-			message := fmt.Sprintf("Request body contains unknown field %s", fieldName)
-			return &malformedRequestError{message: message}
+	restructureRequest.UserID = userID
+	result, errRestructure := l.srv.Restructure(ctx, &restructureRequest)
+	if errRestructure != nil {
+		billingErr := MapError(errRestructure)
+		common.ToErrorResponse(
+			writer,
+			constant.HttpRc[billingErr],
+			constant.HttpRcDescription[billingErr],
+		)
+		return
+	}
 
-		case errors.Is(err, io.EOF):
-			message := "Request body must not be empty"
-			return &malformedRequestError{message: message}
+	common.ToSuccessResponse(writer, nil, result)
+}
 
-		case err.Error() == "http: request body too large":
-			message := "Request body must not be larger than 1MB"
-			return &malformedRequestError{message: message}
+func (l *loanController) WriteOff(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	query := mux.Vars(req)
+	userID, errEscape := escapeSpecialCharacter(query["userID"])
 
-		default:
-			return &malformedRequestError{message: err.Error()}
-		}
+	var writeOffRequest WriteOffRequest
+	err := common.DecodeJSONBody(writer, req, &writeOffRequest)
+
+	if errEscape != nil || err != nil {
+		log.Println("validation decode json body -> ", err)
+
+		common.ToErrorResponse(
+			writer,
+			constant.HttpRc[constant.Validation],
+			constant.HttpRcDescription[constant.Validation],
+		)
+		return
 	}
 
-	err = dec.Decode(&struct{}{})
-	if err != io.EOF {
-		message := "Request body must only contain a single JSON object"
-		return &malformedRequestError{message: message}
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	writeOffRequest.UserID = userID
+	result, errWriteOff := l.srv.WriteOff(ctx, &writeOffRequest)
+	if errWriteOff != nil {
+		billingErr := MapError(errWriteOff)
+		common.ToErrorResponse(
+			writer,
+			constant.HttpRc[billingErr],
+			constant.HttpRcDescription[billingErr],
+		)
+		return
 	}
 
-	return nil
+	common.ToSuccessResponse(writer, nil, result)
 }
 
 func escapeSpecialCharacter(string string) (string, error) {
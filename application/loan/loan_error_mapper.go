@@ -0,0 +1,38 @@
+package loan
+
+import (
+	"errors"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/constant"
+)
+
+// MapError maps the sentinel error of the service into billing error,
+// so every delivery (HTTP, gRPC) responds with the same rc.
+func MapError(err error) constant.BillingSrvHttpError {
+	switch {
+	case err == nil:
+		return constant.Success
+	case errors.Is(err, errorValidation):
+		return constant.Validation
+	case errors.Is(err, errorDataNotExists):
+		return constant.DataNotFound
+	case errors.Is(err, errorAmountShouldBeSame):
+		return constant.PaymentAmountShouldBeEquals
+	case errors.Is(err, errorNoPendingOutstanding):
+		return constant.ZeroOutstanding
+	case errors.Is(err, errorPaymentDeclined):
+		return constant.PaymentDeclined
+	case errors.Is(err, errorPaymentInProgress):
+		return constant.PaymentInProgress
+	case errors.Is(err, errorPaymentNotReversible):
+		return constant.PaymentNotReversible
+	case errors.Is(err, errorQuoteNotActive):
+		return constant.PayoffQuoteNotActive
+	case errors.Is(err, errorInstallmentsChanged):
+		return constant.InstallmentsChanged
+	case errors.Is(err, errorNotWriteOffEligible):
+		return constant.WriteOffNotEligible
+	default:
+		return constant.GeneralError
+	}
+}
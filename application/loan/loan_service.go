@@ -2,42 +2,226 @@ package loan
 
 import (
 	"context"
+	"time"
 
 	"github.com/shopspring/decimal"
 
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/delinquency"
 	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
 	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/calendar"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/gateway"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/qris"
 	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
 )
 
 type (
 	loanService struct {
-		cfg            configuration.Configuration
-		loanRepository repository.LoanRepository
-		generate       common.Generate
+		cfg                   configuration.Configuration
+		loanRepository        repository.LoanRepository
+		outboxRepository      repository.OutboxRepository
+		paymentRepository     repository.PaymentRepository
+		reversalRepository    repository.PaymentReversalRepository
+		payoffQuoteRepository repository.PayoffQuoteRepository
+		restructureRepository repository.LoanRestructureRepository
+		writeOffRepository    repository.LoanWriteOffRepository
+		productRepository     repository.ProductRepository
+		journalRepository     repository.JournalRepository
+		transaction           repository.Transaction
+		paymentGateway        gateway.PaymentGateway
+		qrisGenerator         qris.Generator
+		generate              common.Generate
+		// clock tells the business date the installments are due, overdue or past due as of.
+		clock common.Clock
+		// calendar tells the business days, the installment due on the non business day is late only after the next one.
+		calendar calendar.Calendar
+		assessor *delinquency.Assessor
+		tracker  *delinquency.Tracker
 	}
 
 	FetchOutstandingResponse struct {
 		RemainingOutstanding decimal.Decimal `json:"remaining_outstanding,omitempty"`
 		IsDelinquent         bool            `json:"is_delinquent"`
+		// RecoverableBalance is the written-off balance (including not yet due) still collected as recovery.
+		RecoverableBalance decimal.Decimal `json:"recoverable_balance"`
+		// Breakdown is the components of the remaining outstanding.
+		Breakdown repository.Breakdown `json:"breakdown"`
+		// GraceOutstanding is the part of the remaining outstanding which is due but not late yet, within the grace
+		// period of the product (or until the next business day), it isn't counted towards the delinquency.
+		GraceOutstanding decimal.Decimal `json:"grace_outstanding"`
+		// GraceInstallmentIDs is the installments of the grace outstanding.
+		GraceInstallmentIDs []uint64 `json:"grace_installment_ids,omitempty"`
 	}
 
 	PaymentRequest struct {
 		UserID string  `json:"user_id,omitempty"`
 		Amount float64 `json:"amount,omitempty"`
+		// QuoteID pays off the loan by the payoff quote, including the installments not yet due,
+		// the amount should be equals to the amount of the quote.
+		QuoteID string `json:"quote_id,omitempty"`
+		// Channel is set by the internal caller only, e.g. the virtual account credit which is already received,
+		// it is charged through the payment gateway when it is empty.
+		Channel           string `json:"-"`
+		Provider          string `json:"-"`
+		ProviderReference string `json:"-"`
+	}
+
+	QrisRequest struct {
+		UserID string `json:"user_id,omitempty"`
+		// InstallmentID is optional, the QRIS pays all the due installments when it is empty.
+		InstallmentID uint64 `json:"installment_id,omitempty"`
+	}
+
+	QrisResponse struct {
+		PaymentID string          `json:"payment_id"`
+		Amount    decimal.Decimal `json:"amount"`
+		Payload   string          `json:"payload"`
+		ExpiresAt time.Time       `json:"expires_at"`
+	}
+
+	PayoffQuoteResponse struct {
+		QuoteID        string          `json:"quote_id"`
+		UserID         string          `json:"user_id"`
+		InstallmentIDs []uint64        `json:"installment_ids"`
+		Outstanding    decimal.Decimal `json:"outstanding"`
+		Discount       decimal.Decimal `json:"discount"`
+		Amount         decimal.Decimal `json:"amount"`
+		ExpiresAt      time.Time       `json:"expires_at"`
+	}
+
+	ReversalRequest struct {
+		PaymentID   string `json:"-"`
+		Reason      string `json:"reason,omitempty"`
+		RequestedBy string `json:"requested_by,omitempty"`
+	}
+
+	ReversalResponse struct {
+		ReversalID      string   `json:"reversal_id"`
+		PaymentID       string   `json:"payment_id"`
+		InstallmentIDs  []uint64 `json:"installment_ids"`
+		RefundStatus    string   `json:"refund_status"`
+		RefundReference string   `json:"refund_reference,omitempty"`
+	}
+
+	RestructureRequest struct {
+		UserID string `json:"-"`
+		// Tenor is the number of installments of the new schedule.
+		Tenor int `json:"tenor,omitempty"`
+		// Frequency is WEEKLY (default), BIWEEKLY or MONTHLY.
+		Frequency string `json:"frequency,omitempty"`
+		// GracePeriodDays postpones the first period of the new schedule.
+		GracePeriodDays int    `json:"grace_period_days,omitempty"`
+		Reason          string `json:"reason,omitempty"`
+		RequestedBy     string `json:"requested_by,omitempty"`
+	}
+
+	RestructureResponse struct {
+		RestructureID  string                  `json:"restructure_id"`
+		UserID         string                  `json:"user_id"`
+		InstallmentIDs []uint64                `json:"installment_ids"`
+		Outstanding    decimal.Decimal         `json:"outstanding"`
+		Schedule       []*ScheduledInstallment `json:"schedule"`
+	}
+
+	ScheduledInstallment struct {
+		DueDate   time.Time            `json:"due_date"`
+		Amount    decimal.Decimal      `json:"amount"`
+		Breakdown repository.Breakdown `json:"breakdown"`
+	}
+
+	WriteOffRequest struct {
+		UserID     string `json:"-"`
+		Reason     string `json:"reason,omitempty"`
+		ApprovedBy string `json:"approved_by,omitempty"`
+	}
+
+	WriteOffResponse struct {
+		WriteOffID     string          `json:"write_off_id"`
+		UserID         string          `json:"user_id"`
+		InstallmentIDs []uint64        `json:"installment_ids"`
+		Amount         decimal.Decimal `json:"amount"`
+		DaysPastDue    int             `json:"days_past_due"`
+	}
+
+	PaymentResponse struct {
+		PaymentID     string `json:"payment_id"`
+		Status        string `json:"status"`
+		FailureReason string `json:"failure_reason,omitempty"`
+		// Allocation is the amount of the payment split into the components of the installments.
+		Allocation *repository.Breakdown `json:"allocation,omitempty"`
 	}
 
 	Service interface {
 		FetchOutstanding(ctx context.Context, uid string) (*FetchOutstandingResponse, error)
 
-		Payment(ctx context.Context, paymentRequest *PaymentRequest) error
+		// Payment debits the customer through the payment gateway, the installments are paid only
+		// when the gateway confirms it, otherwise the payment is held as PENDING_DEBIT.
+		Payment(ctx context.Context, paymentRequest *PaymentRequest) (*PaymentResponse, error)
+
+		// CreateQris issues the dynamic QRIS as payment intent (PENDING_DEBIT),
+		// it is settled through the callback of the provider when the customer pays it.
+		CreateQris(ctx context.Context, qrisRequest *QrisRequest) (*QrisResponse, error)
+
+		// PayoffQuote quotes the full remaining amount of the loan, including the installments not yet due
+		// whose fee is discounted. The quote is paid through Payment with its QuoteID before it expires.
+		PayoffQuote(ctx context.Context, uid string) (*PayoffQuoteResponse, error)
+
+		// FindPayment returns the payment, the pending one is refreshed from the payment gateway.
+		FindPayment(ctx context.Context, paymentID string) (*PaymentResponse, error)
+
+		// SettlePayment applies the result of the charge confirmed asynchronously by the payment gateway,
+		// it is idempotent since the payment is settled only once.
+		SettlePayment(ctx context.Context, charge *gateway.Charge) error
+
+		// ReversePayment undoes the paid payment, the installments are reopened and the reversal is recorded
+		// in one transaction. The money is refunded through the payment gateway afterward when it was debited by it.
+		ReversePayment(ctx context.Context, reversalRequest *ReversalRequest) (*ReversalResponse, error)
+
+		// Restructure cancels the unpaid installments of the customer into RESTRUCTURED and spreads their amount
+		// into the new schedule, both in one transaction. The new installments are linked to the restructuring.
+		Restructure(ctx context.Context, restructureRequest *RestructureRequest) (*RestructureResponse, error)
+
+		// WriteOff charges off the unpaid installments of the customer past due beyond the configured days into
+		// WRITTEN_OFF. They are still collected, the payment of them is recorded as recovery.
+		WriteOff(ctx context.Context, writeOffRequest *WriteOffRequest) (*WriteOffResponse, error)
 	}
 )
 
 func NewLoanService(
-	loanRepository repository.LoanRepository) Service {
+	cfg configuration.Configuration,
+	loanRepository repository.LoanRepository,
+	outboxRepository repository.OutboxRepository,
+	delinquencyRepository repository.DelinquencyRepository,
+	paymentRepository repository.PaymentRepository,
+	reversalRepository repository.PaymentReversalRepository,
+	payoffQuoteRepository repository.PayoffQuoteRepository,
+	restructureRepository repository.LoanRestructureRepository,
+	writeOffRepository repository.LoanWriteOffRepository,
+	productRepository repository.ProductRepository,
+	journalRepository repository.JournalRepository,
+	transaction repository.Transaction,
+	paymentGateway gateway.PaymentGateway,
+	qrisGenerator qris.Generator,
+	clock common.Clock,
+	calendar calendar.Calendar) Service {
 	return &loanService{
-		loanRepository: loanRepository,
-		generate:       common.NewGenerate(),
+		cfg:                   cfg,
+		loanRepository:        loanRepository,
+		outboxRepository:      outboxRepository,
+		paymentRepository:     paymentRepository,
+		reversalRepository:    reversalRepository,
+		payoffQuoteRepository: payoffQuoteRepository,
+		restructureRepository: restructureRepository,
+		writeOffRepository:    writeOffRepository,
+		productRepository:     productRepository,
+		journalRepository:     journalRepository,
+		transaction:           transaction,
+		paymentGateway:        paymentGateway,
+		qrisGenerator:         qrisGenerator,
+		generate:              common.NewGenerate(),
+		clock:                 clock,
+		calendar:              calendar,
+		assessor:              delinquency.NewAssessor(loanRepository, productRepository, calendar),
+		tracker:               delinquency.NewTracker(delinquencyRepository, outboxRepository),
 	}
 }
@@ -2,21 +2,47 @@ package loan
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"log"
 	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/shopspring/decimal"
 
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/delinquency"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/event"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/journal"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/product"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/calculator"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/calendar"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/gateway"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/qris"
 	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
 )
 
+const (
+	defaultQrisExpiry        = 30 * time.Minute
+	defaultPayoffQuoteExpiry = 60 * time.Minute
+	defaultWriteOffDpd       = 90
+	// unappliedReason is the failure reason of the UNAPPLIED payment.
+	unappliedReason = "installments are no longer collectible"
+)
+
 var (
 	errorValidation           = errors.New("validation request")
 	errorFromDatabase         = errors.New("from database")
 	errorDataNotExists        = errors.New("data is not exists")
 	errorAmountShouldBeSame   = errors.New("amount should be equals")
 	errorNoPendingOutstanding = errors.New("customer has no zero outstanding")
+	errorPaymentDeclined      = errors.New("payment is declined")
+	errorPaymentInProgress    = errors.New("payment is in progress")
+	errorPaymentNotReversible = errors.New("payment is not reversible")
+	errorQuoteNotActive       = errors.New("payoff quote is expired or used")
+	errorInstallmentsChanged  = errors.New("installments have been changed")
+	errorNotWriteOffEligible  = errors.New("not past due long enough to be written off")
 )
 
 func (l *loanService) FetchOutstanding(
@@ -36,9 +62,11 @@ func (l *loanService) FetchOutstanding(
 
 	loans, err := l.loanRepository.FindLoans(
 		ctx, &repository.LoanEntity{
-			Statuses: []string{"PENDING", "CLOSED"},
-			UserID:   uid,
-			DueDate:  l.generate.Time(),
+			Statuses: []repository.LoanStatus{
+				repository.LoanPending, repository.LoanOverdue, repository.LoanClosed, repository.LoanWrittenOff,
+			},
+			UserID:  uid,
+			DueDate: l.clock.Now(),
 		},
 	)
 
@@ -50,12 +78,36 @@ func (l *loanService) FetchOutstanding(
 		return nil, errorFromDatabase
 	}
 
-	return l.identifyOutstanding(loans)
+	assessment, err := l.assessor.AssessLoans(ctx, uid, loans, l.clock.Now())
+	if err != nil {
+		log.Println("failed assess outstanding -> ", err)
+		return nil, errorFromDatabase
+	}
+
+	rsp = toOutstandingResponse(assessment)
+
+	//the written-off balance is recoverable regardless of the due date
+	writtenOff, err := l.loanRepository.FindLoans(
+		ctx, &repository.LoanEntity{
+			Statuses: []repository.LoanStatus{repository.LoanWrittenOff},
+			UserID:   uid,
+		},
+	)
+
+	if err != nil && !errors.Is(err, repository.ErrorNoRows) {
+		return nil, errorFromDatabase
+	}
+
+	for _, loan := range writtenOff {
+		rsp.RecoverableBalance = rsp.RecoverableBalance.Add(loan.Amount)
+	}
+
+	return rsp, nil
 }
 
 func (l *loanService) Payment(
 	ctx context.Context,
-	paymentRequest *PaymentRequest) (err error) {
+	paymentRequest *PaymentRequest) (rsp *PaymentResponse, err error) {
 	defer func() {
 		if rec := recover(); rec != nil {
 			log.Println("unidentified error (yet)", string(debug.Stack()))
@@ -65,98 +117,1776 @@ func (l *loanService) Payment(
 	}()
 
 	if paymentRequest.UserID == "" || paymentRequest.Amount == 0 {
-		return errorValidation
+		return nil, errorValidation
+	}
+
+	if paymentRequest.QuoteID != "" {
+		return l.payoff(ctx, paymentRequest)
+	}
+
+	//the transfer is paid once, the credit applied again (e.g. reclaimed after a crash) gets its payment
+	if paymentRequest.Channel == repository.PaymentChannelVirtualAccount && paymentRequest.ProviderReference != "" {
+		existing, errExisting := l.paymentRepository.FindPayments(
+			ctx, &repository.PaymentFilter{
+				Provider:          paymentRequest.Provider,
+				ProviderReference: paymentRequest.ProviderReference,
+			},
+		)
+
+		if errExisting != nil {
+			return nil, errorFromDatabase
+		}
+
+		if len(existing) > 0 {
+			return l.receivedPayment(ctx, existing[0])
+		}
 	}
 
 	loans, errFindLoan := l.loanRepository.FindLoans(
 		ctx, &repository.LoanEntity{
-			Statuses: []string{"PENDING"},
+			Statuses: repository.LoanCollectible,
 			UserID:   paymentRequest.UserID,
-			DueDate:  l.generate.Time(),
+			DueDate:  l.clock.Now(),
 		},
 	)
 
 	if errFindLoan != nil {
-		return errorFromDatabase
+		return nil, errorFromDatabase
 	}
 
 	if len(loans) == 0 || loans == nil {
-		return errorNoPendingOutstanding
+		return nil, errorNoPendingOutstanding
+	}
+
+	if errInProgress := l.checkPaymentInProgress(ctx, paymentRequest.UserID); errInProgress != nil {
+		return nil, errInProgress
 	}
 
-	return l.makePayment(ctx, paymentRequest, loans)
+	return l.makePayment(ctx, paymentRequest, loans, nil)
 }
 
-func (l *loanService) identifyOutstanding(
-	loans []*repository.LoanEntity) (*FetchOutstandingResponse, error) {
-	totalClosed, totalPending := 0, 0
-	pendingAmountOutstanding := decimal.NewFromFloat(float64(0))
+func (l *loanService) CreateQris(
+	ctx context.Context,
+	qrisRequest *QrisRequest) (rsp *QrisResponse, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Println("unidentified error (yet)", string(debug.Stack()))
+			err = errorFromDatabase
+			return
+		}
+	}()
+
+	if qrisRequest.UserID == "" {
+		return nil, errorValidation
+	}
+
+	filter := &repository.LoanEntity{
+		Statuses: repository.LoanCollectible,
+		UserID:   qrisRequest.UserID,
+		DueDate:  l.clock.Now(),
+	}
+
+	//the specific installment can be paid before its due date
+	if qrisRequest.InstallmentID != 0 {
+		filter.ID = qrisRequest.InstallmentID
+		filter.DueDate = time.Time{}
+	}
+
+	loans, errFindLoan := l.loanRepository.FindLoans(ctx, filter)
+	if errFindLoan != nil {
+		return nil, errorFromDatabase
+	}
+
+	if len(loans) == 0 && qrisRequest.InstallmentID != 0 {
+		return nil, errorDataNotExists
+	}
+
+	if len(loans) == 0 {
+		return nil, errorNoPendingOutstanding
+	}
+
+	if errInProgress := l.checkPaymentInProgress(ctx, qrisRequest.UserID); errInProgress != nil {
+		return nil, errInProgress
+	}
+
+	amount := decimal.NewFromFloat(float64(0))
+	var loanIDs []uint64
+	for _, loan := range loans {
+		amount = amount.Add(loan.Amount)
+		loanIDs = append(loanIDs, loan.ID)
+	}
+
+	now := l.generate.Time()
+	expiresAt := now.Add(l.qrisExpiry())
+	paymentID := l.generate.Uuid()
 
-	for _, val := range loans {
-		if val.Status == "PENDING" {
-			pendingAmountOutstanding = pendingAmountOutstanding.Add(val.Amount)
-			totalPending += 1
+	//the acquirer sends back the bill number in the callback, so it is our payment id
+	payload, errQris := l.qrisGenerator.Dynamic(
+		&qris.DynamicRequest{
+			Amount:     amount,
+			BillNumber: paymentID,
+		},
+	)
+
+	if errQris != nil {
+		log.Println("failed generate qris -> ", errQris)
+		return nil, errorFromDatabase
+	}
+
+	payment := &repository.PaymentEntity{
+		PaymentID:      paymentID,
+		UserID:         qrisRequest.UserID,
+		Amount:         amount,
+		Channel:        repository.PaymentChannelQris,
+		PaymentCode:    payload,
+		ExpiresAt:      &expiresAt,
+		InstallmentIDs: loanIDs,
+		Status:         repository.PaymentPendingDebit,
+		Provider:       l.paymentGateway.Provider(),
+		Recovery:       hasWrittenOff(loans),
+		Allocation:     allocate(amount, loans, l.allocationOrder()),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	errSave := l.transaction.WithTransaction(
+		ctx, func(tx *sql.Tx) error {
+			return l.paymentRepository.SavePayment(ctx, tx, payment)
+		},
+	)
+
+	if errSave != nil {
+		log.Println("failed save payment -> ", errSave)
+		return nil, errorFromDatabase
+	}
+
+	return &QrisResponse{
+		PaymentID: paymentID,
+		Amount:    amount,
+		Payload:   payload,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (l *loanService) PayoffQuote(
+	ctx context.Context,
+	uid string) (rsp *PayoffQuoteResponse, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Println("unidentified error (yet)", string(debug.Stack()))
+			err = errorFromDatabase
+			return
 		}
+	}()
+
+	if uid == "" {
+		return nil, errorValidation
+	}
+
+	//not filtered by the due date, the payoff settles the installments not yet due as well
+	loans, errFindLoan := l.loanRepository.FindLoans(
+		ctx, &repository.LoanEntity{
+			Statuses: repository.LoanUnpaid,
+			UserID:   uid,
+		},
+	)
+
+	if errFindLoan != nil && !errors.Is(errFindLoan, repository.ErrorNoRows) {
+		return nil, errorFromDatabase
+	}
+
+	if len(loans) == 0 {
+		return nil, errorNoPendingOutstanding
+	}
+
+	now := l.generate.Time()
+	today := startOfDay(l.clock.Now())
 
-		if val.Status == "CLOSED" {
-			totalClosed += 1
+	discountRate := decimal.NewFromFloat(l.cfg.GetFloat("payoff.discount.percent")).Div(decimal.NewFromInt(100))
+
+	outstanding := decimal.NewFromFloat(float64(0))
+	discount := decimal.NewFromFloat(float64(0))
+	var loanIDs []uint64
+	for _, loan := range loans {
+		outstanding = outstanding.Add(loan.Amount)
+		loanIDs = append(loanIDs, loan.ID)
+
+		if loan.DueDate.Before(today) {
+			continue
 		}
+
+		//the fee of the installment is the one issued by the terms of its product, the penalty is never discounted
+		discount = discount.Add(loan.Breakdown.Fee.Mul(discountRate))
 	}
 
-	//meaning : the customer already paid all the outstanding
-	if totalClosed == len(loans) {
-		return &FetchOutstandingResponse{
-			RemainingOutstanding: decimal.NewFromFloat(float64(0)),
-			IsDelinquent:         false,
-		}, nil
+	discount = discount.Round(2)
+	quote := &repository.PayoffQuoteEntity{
+		QuoteID:        l.generate.Uuid(),
+		UserID:         uid,
+		InstallmentIDs: loanIDs,
+		Outstanding:    outstanding,
+		Discount:       discount,
+		Amount:         outstanding.Sub(discount),
+		Status:         repository.PayoffQuoteActive,
+		ExpiresAt:      now.Add(l.payoffQuoteExpiry()),
+		CreatedAt:      now,
+		UpdatedAt:      now,
 	}
 
-	if totalPending > 2 {
-		return &FetchOutstandingResponse{
-			RemainingOutstanding: pendingAmountOutstanding,
-			IsDelinquent:         true,
-		}, nil
+	if errSave := l.payoffQuoteRepository.SaveQuote(ctx, quote); errSave != nil {
+		log.Println("failed save payoff quote -> ", errSave)
+		return nil, errorFromDatabase
 	}
 
-	return &FetchOutstandingResponse{
-		RemainingOutstanding: pendingAmountOutstanding,
-		IsDelinquent:         false,
+	return &PayoffQuoteResponse{
+		QuoteID:        quote.QuoteID,
+		UserID:         quote.UserID,
+		InstallmentIDs: quote.InstallmentIDs,
+		Outstanding:    quote.Outstanding,
+		Discount:       quote.Discount,
+		Amount:         quote.Amount,
+		ExpiresAt:      quote.ExpiresAt,
 	}, nil
 }
 
-func (l *loanService) makePayment(
+func (l *loanService) FindPayment(
 	ctx context.Context,
-	paymentRequest *PaymentRequest,
-	loans []*repository.LoanEntity) error {
-	amount := decimal.NewFromFloat(paymentRequest.Amount)
-	totalAmount := decimal.NewFromFloat(float64(0))
+	paymentID string) (rsp *PaymentResponse, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Println("unidentified error (yet)", string(debug.Stack()))
+			err = errorFromDatabase
+			return
+		}
+	}()
+
+	if paymentID == "" {
+		return nil, errorValidation
+	}
+
+	payment, err := l.findPayment(ctx, &repository.PaymentFilter{PaymentID: paymentID})
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.Status != repository.PaymentPendingDebit {
+		return toPaymentResponse(payment), nil
+	}
+
+	//the callback might be lost, so ask the gateway directly
+	charge, errStatus := l.paymentGateway.Status(ctx, payment.PaymentID)
+	if errStatus != nil {
+		log.Println("failed query payment status -> ", payment.PaymentID, errStatus)
+		return toPaymentResponse(payment), nil
+	}
+
+	if errSettle := l.settlePayment(ctx, payment, charge); errSettle != nil {
+		return nil, errSettle
+	}
+
+	return toPaymentResponse(payment), nil
+}
+
+func (l *loanService) SettlePayment(
+	ctx context.Context,
+	charge *gateway.Charge) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Println("unidentified error (yet)", string(debug.Stack()))
+			err = errorFromDatabase
+			return
+		}
+	}()
+
+	if charge == nil || (charge.Reference == "" && charge.ProviderReference == "") {
+		return errorValidation
+	}
+
+	filter := &repository.PaymentFilter{PaymentID: charge.Reference}
+	if charge.Reference == "" {
+		filter = &repository.PaymentFilter{
+			Provider:          l.paymentGateway.Provider(),
+			ProviderReference: charge.ProviderReference,
+		}
+	}
+
+	payment, err := l.findPayment(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	if !payment.Amount.Equal(charge.Amount) && charge.Status == gateway.StatusSuccess {
+		log.Println("amount of charge is different from the payment -> ", payment.PaymentID, charge.Amount)
+		return errorAmountShouldBeSame
+	}
+
+	//the money is already moved, so the expired intent is still settled but it needs to be reviewed
+	if payment.ExpiresAt != nil && payment.ExpiresAt.Before(l.generate.Time()) {
+		log.Println("payment intent is paid after expired -> ", payment.PaymentID, charge.Status)
+	}
+
+	return l.settlePayment(ctx, payment, charge)
+}
+
+func (l *loanService) ReversePayment(
+	ctx context.Context,
+	reversalRequest *ReversalRequest) (rsp *ReversalResponse, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Println("unidentified error (yet)", string(debug.Stack()))
+			err = errorFromDatabase
+			return
+		}
+	}()
+
+	if reversalRequest.PaymentID == "" || reversalRequest.Reason == "" || reversalRequest.RequestedBy == "" {
+		return nil, errorValidation
+	}
+
+	payment, err := l.findPayment(ctx, &repository.PaymentFilter{PaymentID: reversalRequest.PaymentID})
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.Status != repository.PaymentPaid && payment.Status != repository.PaymentUnapplied {
+		return nil, errorPaymentNotReversible
+	}
+
+	//the unapplied payment paid nothing, so the reversal only returns the money held in suspense
+	var loans []*repository.LoanEntity
+	var reopenedIDs []uint64
+	if payment.Status == repository.PaymentPaid {
+		paidLoans, errPaid := l.findPaidInstallments(ctx, payment)
+		if errPaid != nil {
+			return nil, errPaid
+		}
+
+		loans = paidLoans
+		reopenedIDs = payment.InstallmentIDs
+	}
+
+	now := l.generate.Time()
+	reversal := &repository.PaymentReversalEntity{
+		ReversalID:     l.generate.Uuid(),
+		PaymentID:      payment.PaymentID,
+		UserID:         payment.UserID,
+		Amount:         payment.Amount,
+		InstallmentIDs: reopenedIDs,
+		Reason:         reversalRequest.Reason,
+		RequestedBy:    reversalRequest.RequestedBy,
+		RefundStatus:   repository.RefundNotApplicable,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if l.isRefundable(payment) {
+		reversal.RefundStatus = repository.RefundPending
+	}
+
+	outbox, errEvent := event.NewOutbox(
+		l.generate.Uuid(), event.PaymentReversed, payment.UserID, now,
+		&event.PaymentReversedPayload{
+			ReversalID:     reversal.ReversalID,
+			PaymentID:      payment.PaymentID,
+			UserID:         payment.UserID,
+			Amount:         payment.Amount,
+			InstallmentIDs: reversal.InstallmentIDs,
+			Reason:         reversal.Reason,
+			ReversedAt:     now,
+		},
+	)
+
+	if errEvent != nil {
+		log.Println("failed build reversal event -> ", errEvent)
+		return nil, errorFromDatabase
+	}
+
+	//booked at the business date, so the entry is exported with the date it is closed by
+	entry := l.chart().Reversal(l.generate.Uuid(), reversal.ReversalID, payment, loans, l.clock.Now())
+
+	//the reopened installments count again, as unpaid or written off for the reversed recovery
+	reopenedAs := repository.LoanPending
+	if payment.Recovery {
+		reopenedAs = repository.LoanWrittenOff
+	}
+
+	var transition *repository.OutboxEntity
+	if len(loans) > 0 {
+		var errDelinquency error
+		transition, errDelinquency = l.delinquencyTransition(
+			ctx, payment.UserID, delinquency.WithStatus(loans, reopenedAs))
+		if errDelinquency != nil {
+			log.Println("failed identify delinquency -> ", errDelinquency)
+			return nil, errorFromDatabase
+		}
+	}
+
+	errTx := l.transaction.WithTransaction(
+		ctx, func(tx *sql.Tx) error {
+			errPayment := l.paymentRepository.UpdatePayment(
+				ctx, tx, &repository.PaymentEntityUpdate{
+					PaymentID:  payment.PaymentID,
+					FromStatus: payment.Status,
+					Status:     repository.PaymentReversed,
+				},
+			)
+
+			if errPayment != nil {
+				return errPayment
+			}
+
+			if len(loans) > 0 {
+				errReopen := l.reopenInstallments(ctx, tx, reversal.ReversalID, loans, l.clock.Now(), payment.Recovery)
+				if errReopen != nil {
+					return errReopen
+				}
+			}
+
+			if errSave := l.reversalRepository.SaveReversal(ctx, tx, reversal); errSave != nil {
+				return errSave
+			}
+
+			if errJournal := l.journalRepository.SaveEntries(ctx, tx, entry); errJournal != nil {
+				return errJournal
+			}
+
+			if errOutbox := l.outboxRepository.SaveOutbox(ctx, tx, outbox); errOutbox != nil {
+				return errOutbox
+			}
+
+			return l.tracker.Record(ctx, tx, transition)
+		},
+	)
+
+	if errors.Is(errTx, repository.ErrorNoRows) ||
+		errors.Is(errTx, repository.ErrorDuplicate) ||
+		errors.Is(errTx, repository.ErrorIllegalTransition) {
+		return nil, errorPaymentNotReversible
+	}
+
+	if errTx != nil {
+		log.Println("failed reverse payment -> ", errTx)
+		return nil, errorFromDatabase
+	}
+
+	//the reversal is committed, the failed refund is kept as FAILED to be followed up manually
+	if reversal.RefundStatus == repository.RefundPending {
+		l.refundPayment(ctx, payment, reversal)
+	}
+
+	return &ReversalResponse{
+		ReversalID:      reversal.ReversalID,
+		PaymentID:       reversal.PaymentID,
+		InstallmentIDs:  reversal.InstallmentIDs,
+		RefundStatus:    reversal.RefundStatus,
+		RefundReference: reversal.RefundReference,
+	}, nil
+}
+
+func (l *loanService) Restructure(
+	ctx context.Context,
+	restructureRequest *RestructureRequest) (rsp *RestructureResponse, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Println("unidentified error (yet)", string(debug.Stack()))
+			err = errorFromDatabase
+			return
+		}
+	}()
+
+	if restructureRequest.Frequency == "" {
+		restructureRequest.Frequency = repository.RestructureWeekly
+	}
+
+	if restructureRequest.UserID == "" || restructureRequest.Tenor <= 0 || restructureRequest.GracePeriodDays < 0 ||
+		restructureRequest.Reason == "" || restructureRequest.RequestedBy == "" ||
+		!isRestructureFrequency(restructureRequest.Frequency) {
+		return nil, errorValidation
+	}
+
+	//not filtered by the due date, all the unpaid installments are replaced by the new schedule
+	loans, errFindLoan := l.loanRepository.FindLoans(
+		ctx, &repository.LoanEntity{
+			Statuses: repository.LoanUnpaid,
+			UserID:   restructureRequest.UserID,
+		},
+	)
+
+	if errFindLoan != nil && !errors.Is(errFindLoan, repository.ErrorNoRows) {
+		return nil, errorFromDatabase
+	}
 
+	if len(loans) == 0 {
+		return nil, errorNoPendingOutstanding
+	}
+
+	//the pending debit would pay the installments which are cancelled
+	if errInProgress := l.checkPaymentInProgress(ctx, restructureRequest.UserID); errInProgress != nil {
+		return nil, errInProgress
+	}
+
+	now := l.generate.Time()
+	outstanding := decimal.NewFromFloat(float64(0))
+	breakdown := repository.Breakdown{}
 	var loanIDs []uint64
 	for _, loan := range loans {
-		totalAmount = totalAmount.Add(loan.Amount)
+		outstanding = outstanding.Add(loan.Amount)
+		breakdown = breakdown.Add(loan.Breakdown)
 		loanIDs = append(loanIDs, loan.ID)
 	}
 
-	if amount.LessThan(totalAmount) || amount.GreaterThan(totalAmount) {
-		return errorAmountShouldBeSame
+	restructure := &repository.LoanRestructureEntity{
+		RestructureID:   l.generate.Uuid(),
+		UserID:          restructureRequest.UserID,
+		InstallmentIDs:  loanIDs,
+		Outstanding:     outstanding,
+		Tenor:           restructureRequest.Tenor,
+		Frequency:       restructureRequest.Frequency,
+		GracePeriodDays: restructureRequest.GracePeriodDays,
+		Reason:          restructureRequest.Reason,
+		RequestedBy:     restructureRequest.RequestedBy,
+		CreatedAt:       now,
+		UpdatedAt:       now,
 	}
 
-	//integrate with 3rd party for debit the money customer
-	//after the result of debit is success, then update the loan.
-	//push notif (if any)
-	//sent related marketing purposed, or any other activities.
+	schedule := buildSchedule(restructure, breakdown, l.clock.Now(), l.calendar)
+	newLoans := make([]*repository.LoanEntity, 0, len(schedule))
+	//the new schedule keeps the product version of the restructured loan
+	productID := loans[0].ProductID
+	for _, installment := range schedule {
+		newLoans = append(
+			newLoans, &repository.LoanEntity{
+				Status:        repository.LoanPending,
+				UserID:        restructure.UserID,
+				DueDate:       installment.DueDate,
+				Amount:        installment.Amount,
+				Breakdown:     installment.Breakdown,
+				CreatedAt:     now,
+				UpdatedAt:     now,
+				RestructureID: restructure.RestructureID,
+				ProductID:     productID,
+			},
+		)
+	}
 
-	errUpdate := l.loanRepository.UpdateLoan(
-		ctx, &repository.LoanEntityUpdate{
-			IDs:    loanIDs,
-			Status: "PAID",
+	outbox, errEvent := event.NewOutbox(
+		l.generate.Uuid(), event.LoanRestructured, restructure.UserID, now,
+		&event.LoanRestructuredPayload{
+			RestructureID:  restructure.RestructureID,
+			UserID:         restructure.UserID,
+			InstallmentIDs: restructure.InstallmentIDs,
+			Outstanding:    restructure.Outstanding,
+			Tenor:          restructure.Tenor,
+			Frequency:      restructure.Frequency,
+			RestructuredAt: now,
 		},
 	)
 
-	if errUpdate != nil {
-		log.Println("failed update loan -> ", errUpdate)
-		return errorFromDatabase
+	if errEvent != nil {
+		log.Println("failed build restructure event -> ", errEvent)
+		return nil, errorFromDatabase
 	}
 
-	return nil
+	accrued, errAccrued := l.findAccrued(ctx, loans)
+	if errAccrued != nil {
+		return nil, errAccrued
+	}
+
+	entry := l.chart().Restructure(
+		l.generate.Uuid(), restructure.RestructureID, restructure.UserID, loans, accrued, l.clock.Now())
+
+	//the restructured installments don't count anymore, the new schedule is due from now on
+	transition, errDelinquency := l.delinquencyTransition(
+		ctx, restructure.UserID, delinquency.WithStatus(loans, repository.LoanRestructured))
+	if errDelinquency != nil {
+		log.Println("failed identify delinquency -> ", errDelinquency)
+		return nil, errorFromDatabase
+	}
+
+	errTx := l.transaction.WithTransaction(
+		ctx, func(tx *sql.Tx) error {
+			errUpdate := l.loanRepository.UpdateLoan(
+				ctx, tx, &repository.LoanEntityUpdate{
+					IDs:    loanIDs,
+					Status: repository.LoanRestructured,
+					Reason: "restructure " + restructure.RestructureID,
+				},
+			)
+
+			if errUpdate != nil {
+				return errUpdate
+			}
+
+			if errSave := l.loanRepository.SaveLoans(ctx, tx, newLoans...); errSave != nil {
+				return errSave
+			}
+
+			if errSave := l.restructureRepository.SaveRestructure(ctx, tx, restructure); errSave != nil {
+				return errSave
+			}
+
+			//none of the installments is accrued, so there is no income to move back
+			if entry != nil {
+				if errJournal := l.journalRepository.SaveEntries(ctx, tx, entry); errJournal != nil {
+					return errJournal
+				}
+			}
+
+			if errOutbox := l.outboxRepository.SaveOutbox(ctx, tx, outbox); errOutbox != nil {
+				return errOutbox
+			}
+
+			return l.tracker.Record(ctx, tx, transition)
+		},
+	)
+
+	//the installment is paid meanwhile, so it can't be restructured anymore
+	if errors.Is(errTx, repository.ErrorNoRows) || errors.Is(errTx, repository.ErrorIllegalTransition) {
+		return nil, errorInstallmentsChanged
+	}
+
+	if errTx != nil {
+		log.Println("failed restructure -> ", errTx)
+		return nil, errorFromDatabase
+	}
+
+	return &RestructureResponse{
+		RestructureID:  restructure.RestructureID,
+		UserID:         restructure.UserID,
+		InstallmentIDs: restructure.InstallmentIDs,
+		Outstanding:    restructure.Outstanding,
+		Schedule:       schedule,
+	}, nil
+}
+
+func (l *loanService) WriteOff(
+	ctx context.Context,
+	writeOffRequest *WriteOffRequest) (rsp *WriteOffResponse, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Println("unidentified error (yet)", string(debug.Stack()))
+			err = errorFromDatabase
+			return
+		}
+	}()
+
+	if writeOffRequest.UserID == "" || writeOffRequest.Reason == "" || writeOffRequest.ApprovedBy == "" {
+		return nil, errorValidation
+	}
+
+	//not filtered by the due date, the remaining installments are written off as a whole
+	loans, errFindLoan := l.loanRepository.FindLoans(
+		ctx, &repository.LoanEntity{
+			Statuses: repository.LoanUnpaid,
+			UserID:   writeOffRequest.UserID,
+		},
+	)
+
+	if errFindLoan != nil && !errors.Is(errFindLoan, repository.ErrorNoRows) {
+		return nil, errorFromDatabase
+	}
+
+	if len(loans) == 0 {
+		return nil, errorNoPendingOutstanding
+	}
+
+	products, errProduct := product.FindProductsOf(ctx, l.productRepository, loans)
+	if errProduct != nil {
+		return nil, errorFromDatabase
+	}
+
+	now := l.generate.Time()
+	today := l.clock.Now()
+	amount := decimal.NewFromFloat(float64(0))
+	dpd := 0
+	var loanIDs []uint64
+	for _, loan := range loans {
+		amount = amount.Add(loan.Amount)
+		loanIDs = append(loanIDs, loan.ID)
+
+		//the days past due count from the installment the most past due, as the delinquency snapshot
+		if loanDpd := product.DaysPastDue(l.calendar, products[loan.ProductID], loan.DueDate, today); loanDpd > dpd {
+			dpd = loanDpd
+		}
+	}
+
+	if dpd < l.writeOffDpd() {
+		return nil, errorNotWriteOffEligible
+	}
+
+	//the pending debit would pay the installments which are written off
+	if errInProgress := l.checkPaymentInProgress(ctx, writeOffRequest.UserID); errInProgress != nil {
+		return nil, errInProgress
+	}
+
+	writeOff := &repository.LoanWriteOffEntity{
+		WriteOffID:     l.generate.Uuid(),
+		UserID:         writeOffRequest.UserID,
+		InstallmentIDs: loanIDs,
+		Amount:         amount,
+		DaysPastDue:    dpd,
+		Reason:         writeOffRequest.Reason,
+		ApprovedBy:     writeOffRequest.ApprovedBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	outbox, errEvent := event.NewOutbox(
+		l.generate.Uuid(), event.LoanWrittenOff, writeOff.UserID, now,
+		&event.LoanWrittenOffPayload{
+			WriteOffID:     writeOff.WriteOffID,
+			UserID:         writeOff.UserID,
+			InstallmentIDs: writeOff.InstallmentIDs,
+			Amount:         writeOff.Amount,
+			DaysPastDue:    writeOff.DaysPastDue,
+			Reason:         writeOff.Reason,
+			WrittenOffAt:   now,
+		},
+	)
+
+	if errEvent != nil {
+		log.Println("failed build write-off event -> ", errEvent)
+		return nil, errorFromDatabase
+	}
+
+	accrued, errAccrued := l.findAccrued(ctx, loans)
+	if errAccrued != nil {
+		return nil, errAccrued
+	}
+
+	entry := l.chart().WriteOff(l.generate.Uuid(), writeOff.WriteOffID, writeOff.UserID, loans, accrued, l.clock.Now())
+
+	//the written-off customer is delinquent until the written-off installments are recovered
+	transition, errDelinquency := l.delinquencyTransition(
+		ctx, writeOff.UserID, delinquency.WithStatus(loans, repository.LoanWrittenOff))
+	if errDelinquency != nil {
+		log.Println("failed identify delinquency -> ", errDelinquency)
+		return nil, errorFromDatabase
+	}
+
+	errTx := l.transaction.WithTransaction(
+		ctx, func(tx *sql.Tx) error {
+			errUpdate := l.loanRepository.UpdateLoan(
+				ctx, tx, &repository.LoanEntityUpdate{
+					IDs:    loanIDs,
+					Status: repository.LoanWrittenOff,
+					Reason: "write-off " + writeOff.WriteOffID,
+				},
+			)
+
+			if errUpdate != nil {
+				return errUpdate
+			}
+
+			if errSave := l.writeOffRepository.SaveWriteOff(ctx, tx, writeOff); errSave != nil {
+				return errSave
+			}
+
+			if errJournal := l.journalRepository.SaveEntries(ctx, tx, entry); errJournal != nil {
+				return errJournal
+			}
+
+			if errOutbox := l.outboxRepository.SaveOutbox(ctx, tx, outbox); errOutbox != nil {
+				return errOutbox
+			}
+
+			return l.tracker.Record(ctx, tx, transition)
+		},
+	)
+
+	//the installment is paid meanwhile, so the write-off should be reviewed again
+	if errors.Is(errTx, repository.ErrorNoRows) || errors.Is(errTx, repository.ErrorIllegalTransition) {
+		return nil, errorInstallmentsChanged
+	}
+
+	if errTx != nil {
+		log.Println("failed write-off -> ", errTx)
+		return nil, errorFromDatabase
+	}
+
+	return &WriteOffResponse{
+		WriteOffID:     writeOff.WriteOffID,
+		UserID:         writeOff.UserID,
+		InstallmentIDs: writeOff.InstallmentIDs,
+		Amount:         writeOff.Amount,
+		DaysPastDue:    writeOff.DaysPastDue,
+	}, nil
+}
+
+// makePayment records the payment of the installments then charges it, the payoff quote (if any)
+// is used by the payment and its amount is charged instead of the sum of the installments.
+func (l *loanService) makePayment(
+	ctx context.Context,
+	paymentRequest *PaymentRequest,
+	loans []*repository.LoanEntity,
+	quote *repository.PayoffQuoteEntity) (*PaymentResponse, error) {
+	amount := decimal.NewFromFloat(paymentRequest.Amount)
+	totalAmount := decimal.NewFromFloat(float64(0))
+
+	var loanIDs []uint64
+	for _, loan := range loans {
+		totalAmount = totalAmount.Add(loan.Amount)
+		loanIDs = append(loanIDs, loan.ID)
+	}
+
+	if quote != nil {
+		totalAmount = quote.Amount
+	}
+
+	if amount.LessThan(totalAmount) || amount.GreaterThan(totalAmount) {
+		return nil, errorAmountShouldBeSame
+	}
+
+	//the payoff discount is given on the fee, so the fee is allocated last
+	order := l.allocationOrder()
+	if quote != nil {
+		order = moveLast(order, repository.ComponentFee)
+	}
+
+	now := l.generate.Time()
+	payment := &repository.PaymentEntity{
+		PaymentID:      l.generate.Uuid(),
+		UserID:         paymentRequest.UserID,
+		Amount:         amount,
+		InstallmentIDs: loanIDs,
+		Status:         repository.PaymentPendingDebit,
+		Channel:        repository.PaymentChannelDirectDebit,
+		Provider:       l.paymentGateway.Provider(),
+		Recovery:       hasWrittenOff(loans),
+		Allocation:     allocate(amount, loans, order),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if paymentRequest.Channel == repository.PaymentChannelVirtualAccount {
+		payment.Channel = paymentRequest.Channel
+		payment.Provider = paymentRequest.Provider
+		payment.ProviderReference = paymentRequest.ProviderReference
+	}
+
+	//the payment is recorded before debit, so the callback always finds it
+	errSave := l.transaction.WithTransaction(
+		ctx, func(tx *sql.Tx) error {
+			if quote != nil {
+				errQuote := l.payoffQuoteRepository.UpdateQuote(
+					ctx, tx, &repository.PayoffQuoteUpdate{
+						QuoteID:    quote.QuoteID,
+						FromStatus: repository.PayoffQuoteActive,
+						Status:     repository.PayoffQuoteUsed,
+						PaymentID:  payment.PaymentID,
+					},
+				)
+
+				if errQuote != nil {
+					return errQuote
+				}
+			}
+
+			return l.paymentRepository.SavePayment(ctx, tx, payment)
+		},
+	)
+
+	if errors.Is(errSave, repository.ErrorNoRows) {
+		return nil, errorQuoteNotActive
+	}
+
+	if errSave != nil {
+		log.Println("failed save payment -> ", errSave)
+		return nil, errorFromDatabase
+	}
+
+	//the money is already received, the installments are paid right away
+	if payment.Channel == repository.PaymentChannelVirtualAccount {
+		return l.receivedPayment(ctx, payment)
+	}
+
+	charge, errCharge := l.paymentGateway.Charge(
+		ctx, &gateway.ChargeRequest{
+			Reference:   payment.PaymentID,
+			UserID:      payment.UserID,
+			Amount:      amount,
+			Description: "installment payment",
+		},
+	)
+
+	//the money may or may not be moved, keep it as PENDING_DEBIT until the gateway confirms it
+	if errors.Is(errCharge, gateway.ErrorTimeout) {
+		log.Println("payment gateway timeout, payment is pending -> ", payment.PaymentID)
+		return toPaymentResponse(payment), nil
+	}
+
+	if errCharge != nil {
+		log.Println("failed charge payment -> ", payment.PaymentID, errCharge)
+		charge = &gateway.Charge{
+			Reference:     payment.PaymentID,
+			Status:        gateway.StatusDeclined,
+			FailureReason: errCharge.Error(),
+		}
+	}
+
+	if errSettle := l.settlePayment(ctx, payment, charge); errSettle != nil {
+		return nil, errSettle
+	}
+
+	if payment.Status == repository.PaymentFailed {
+		return nil, errorPaymentDeclined
+	}
+
+	//push notif (if any)
+	//sent related marketing purposed, or any other activities.
+	//the follow-ups consume the events from outbox, published by the relay.
+	return toPaymentResponse(payment), nil
+}
+
+// payoff pays all the unpaid installments of the quote in one payment, the quote is used once
+// and it is no longer valid when any of its installments has been paid since.
+func (l *loanService) payoff(
+	ctx context.Context,
+	paymentRequest *PaymentRequest) (*PaymentResponse, error) {
+	quote, errQuote := l.payoffQuoteRepository.FindQuote(ctx, paymentRequest.QuoteID)
+	if errors.Is(errQuote, repository.ErrorNoRows) {
+		return nil, errorDataNotExists
+	}
+
+	if errQuote != nil {
+		return nil, errorFromDatabase
+	}
+
+	if quote.UserID != paymentRequest.UserID {
+		return nil, errorDataNotExists
+	}
+
+	if quote.Status != repository.PayoffQuoteActive || !quote.ExpiresAt.After(l.generate.Time()) {
+		return nil, errorQuoteNotActive
+	}
+
+	loans, errFindLoan := l.loanRepository.FindLoans(
+		ctx, &repository.LoanEntity{
+			Statuses: repository.LoanUnpaid,
+			UserID:   paymentRequest.UserID,
+		},
+	)
+
+	if errFindLoan != nil && !errors.Is(errFindLoan, repository.ErrorNoRows) {
+		return nil, errorFromDatabase
+	}
+
+	quoted := make(map[uint64]bool)
+	for _, id := range quote.InstallmentIDs {
+		quoted[id] = true
+	}
+
+	for _, loan := range loans {
+		if !quoted[loan.ID] {
+			return nil, errorQuoteNotActive
+		}
+	}
+
+	if len(loans) != len(quote.InstallmentIDs) {
+		return nil, errorQuoteNotActive
+	}
+
+	if errInProgress := l.checkPaymentInProgress(ctx, paymentRequest.UserID); errInProgress != nil {
+		return nil, errInProgress
+	}
+
+	return l.makePayment(ctx, paymentRequest, loans, quote)
+}
+
+func (l *loanService) receivedPayment(
+	ctx context.Context,
+	payment *repository.PaymentEntity) (*PaymentResponse, error) {
+	charge := &gateway.Charge{
+		Reference:         payment.PaymentID,
+		ProviderReference: payment.ProviderReference,
+		Status:            gateway.StatusSuccess,
+		Amount:            payment.Amount,
+	}
+
+	if errSettle := l.settlePayment(ctx, payment, charge); errSettle != nil {
+		return nil, errSettle
+	}
+
+	return toPaymentResponse(payment), nil
+}
+
+// settlePayment moves the PENDING_DEBIT payment based on the result of the charge,
+// the installments are paid in the same transaction as the payment.
+func (l *loanService) settlePayment(
+	ctx context.Context,
+	payment *repository.PaymentEntity,
+	charge *gateway.Charge) error {
+	if payment.Status != repository.PaymentPendingDebit {
+		return nil
+	}
+
+	switch charge.Status {
+	case gateway.StatusSuccess:
+		return l.paidPayment(ctx, payment, charge)
+	case gateway.StatusDeclined:
+		errTx := l.transaction.WithTransaction(
+			ctx, func(tx *sql.Tx) error {
+				return l.paymentRepository.UpdatePayment(
+					ctx, tx, &repository.PaymentEntityUpdate{
+						PaymentID:         payment.PaymentID,
+						FromStatus:        repository.PaymentPendingDebit,
+						Status:            repository.PaymentFailed,
+						ProviderReference: charge.ProviderReference,
+						FailureReason:     charge.FailureReason,
+					},
+				)
+			},
+		)
+
+		if errors.Is(errTx, repository.ErrorNoRows) {
+			return nil
+		}
+
+		if errTx != nil {
+			log.Println("failed update payment -> ", errTx)
+			return errorFromDatabase
+		}
+
+		payment.Status = repository.PaymentFailed
+		payment.FailureReason = charge.FailureReason
+		return nil
+	default:
+		if charge.ProviderReference == "" || payment.ProviderReference != "" {
+			return nil
+		}
+
+		//still pending, remember the provider reference for the callback
+		errTx := l.transaction.WithTransaction(
+			ctx, func(tx *sql.Tx) error {
+				return l.paymentRepository.UpdatePayment(
+					ctx, tx, &repository.PaymentEntityUpdate{
+						PaymentID:         payment.PaymentID,
+						FromStatus:        repository.PaymentPendingDebit,
+						Status:            repository.PaymentPendingDebit,
+						ProviderReference: charge.ProviderReference,
+					},
+				)
+			},
+		)
+
+		if errTx != nil && !errors.Is(errTx, repository.ErrorNoRows) {
+			log.Println("failed update payment -> ", errTx)
+			return errorFromDatabase
+		}
+
+		payment.ProviderReference = charge.ProviderReference
+		return nil
+	}
+}
+
+func (l *loanService) paidPayment(
+	ctx context.Context,
+	payment *repository.PaymentEntity,
+	charge *gateway.Charge) error {
+	//looking for all pending (including not yet due) to identify the loan is closed by this payment
+	allPending, errFindLoan := l.loanRepository.FindLoans(
+		ctx, &repository.LoanEntity{
+			Statuses: repository.LoanCollectible,
+			UserID:   payment.UserID,
+		},
+	)
+
+	if errFindLoan != nil {
+		return errorFromDatabase
+	}
+
+	installmentIDs := make(map[uint64]bool)
+	for _, id := range payment.InstallmentIDs {
+		installmentIDs[id] = true
+	}
+
+	var loans []*repository.LoanEntity
+	var loanIDs []uint64
+	for _, loan := range allPending {
+		if installmentIDs[loan.ID] {
+			loans = append(loans, loan)
+			loanIDs = append(loanIDs, loan.ID)
+		}
+	}
+
+	//the payment is never settled partially, the money is held as a whole until it is reversed
+	if len(loans) != len(payment.InstallmentIDs) {
+		log.Println("some installments of payment are no longer collectible -> ", payment.PaymentID)
+		return l.unappliedPayment(ctx, payment, charge)
+	}
+
+	outboxes, errEvent := l.buildPaymentEvents(payment, loans, len(allPending) <= len(loans))
+	if errEvent != nil {
+		log.Println("failed build payment events -> ", errEvent)
+		return errorFromDatabase
+	}
+
+	if payment.Recovery {
+		recovered, errRecovered := event.NewOutbox(
+			l.generate.Uuid(), event.WriteOffRecovered, payment.UserID, l.generate.Time(),
+			&event.WriteOffRecoveredPayload{
+				PaymentID:      payment.PaymentID,
+				UserID:         payment.UserID,
+				Amount:         payment.Amount,
+				InstallmentIDs: loanIDs,
+				RecoveredAt:    l.generate.Time(),
+			},
+		)
+
+		if errRecovered != nil {
+			log.Println("failed build recovery event -> ", errRecovered)
+			return errorFromDatabase
+		}
+
+		outboxes = append(outboxes, recovered)
+	}
+
+	//the customer is assessed from the collectible installments as if the paid ones were committed,
+	//the written-off customer stays delinquent until the written-off installments are fully recovered
+	paidAs := make(map[uint64]*repository.LoanEntity)
+	for _, loan := range delinquency.WithStatus(loans, repository.LoanPaid) {
+		paidAs[loan.ID] = loan
+	}
+
+	var remaining []*repository.LoanEntity
+	for _, loan := range allPending {
+		if paid, ok := paidAs[loan.ID]; ok {
+			remaining = append(remaining, paid)
+			continue
+		}
+
+		remaining = append(remaining, loan)
+	}
+
+	assessment, errAssess := l.assessor.AssessLoans(ctx, payment.UserID, remaining, l.clock.Now())
+	if errAssess != nil {
+		log.Println("failed assess delinquency -> ", errAssess)
+		return errorFromDatabase
+	}
+
+	transition, errDelinquency := l.tracker.Transition(ctx, assessment, l.generate.Time())
+	if errDelinquency != nil {
+		log.Println("failed identify delinquency -> ", errDelinquency)
+		return errorFromDatabase
+	}
+
+	entries := l.buildPaymentEntries(payment, loans)
+
+	errTx := l.transaction.WithTransaction(
+		ctx, func(tx *sql.Tx) error {
+			errPayment := l.paymentRepository.UpdatePayment(
+				ctx, tx, &repository.PaymentEntityUpdate{
+					PaymentID:         payment.PaymentID,
+					FromStatus:        repository.PaymentPendingDebit,
+					Status:            repository.PaymentPaid,
+					ProviderReference: charge.ProviderReference,
+				},
+			)
+
+			if errPayment != nil {
+				return errPayment
+			}
+
+			if len(loanIDs) > 0 {
+				errUpdate := l.loanRepository.UpdateLoan(
+					ctx, tx, &repository.LoanEntityUpdate{
+						IDs:    loanIDs,
+						Status: repository.LoanPaid,
+						Reason: "payment " + payment.PaymentID,
+					},
+				)
+
+				if errUpdate != nil {
+					return errUpdate
+				}
+			}
+
+			if errJournal := l.journalRepository.SaveEntries(ctx, tx, entries...); errJournal != nil {
+				return errJournal
+			}
+
+			if errOutbox := l.outboxRepository.SaveOutbox(ctx, tx, outboxes...); errOutbox != nil {
+				return errOutbox
+			}
+
+			return l.tracker.Record(ctx, tx, transition)
+		},
+	)
+
+	//settled by another process (e.g. callback and status query at the same time)
+	if errors.Is(errTx, repository.ErrorNoRows) {
+		return nil
+	}
+
+	if errTx != nil {
+		log.Println("failed update loan -> ", errTx)
+		return errorFromDatabase
+	}
+
+	payment.Status = repository.PaymentPaid
+	payment.ProviderReference = charge.ProviderReference
+	return nil
+}
+
+// unappliedPayment moves the PENDING_DEBIT payment whose installments are no longer collectible to UNAPPLIED,
+// the money received is booked into suspense and no installment is paid. It is refunded by the reversal.
+func (l *loanService) unappliedPayment(
+	ctx context.Context,
+	payment *repository.PaymentEntity,
+	charge *gateway.Charge) error {
+	//without the installments, the whole amount is credited into suspense
+	entry := l.chart().Payment(l.generate.Uuid(), payment, nil, l.clock.Now())
+
+	errTx := l.transaction.WithTransaction(
+		ctx, func(tx *sql.Tx) error {
+			errPayment := l.paymentRepository.UpdatePayment(
+				ctx, tx, &repository.PaymentEntityUpdate{
+					PaymentID:         payment.PaymentID,
+					FromStatus:        repository.PaymentPendingDebit,
+					Status:            repository.PaymentUnapplied,
+					ProviderReference: charge.ProviderReference,
+					FailureReason:     unappliedReason,
+				},
+			)
+
+			if errPayment != nil {
+				return errPayment
+			}
+
+			return l.journalRepository.SaveEntries(ctx, tx, entry)
+		},
+	)
+
+	//settled by another process (e.g. callback and status query at the same time)
+	if errors.Is(errTx, repository.ErrorNoRows) {
+		return nil
+	}
+
+	if errTx != nil {
+		log.Println("failed update payment -> ", errTx)
+		return errorFromDatabase
+	}
+
+	payment.Status = repository.PaymentUnapplied
+	payment.ProviderReference = charge.ProviderReference
+	payment.FailureReason = unappliedReason
+	return nil
+}
+
+// checkPaymentInProgress prevents the double debit, the installments are still PENDING
+// until the previous payment is confirmed. The expired intent (e.g. QRIS) is no longer in progress.
+func (l *loanService) checkPaymentInProgress(
+	ctx context.Context,
+	userID string) error {
+	inProgress, err := l.paymentRepository.FindPayments(
+		ctx, &repository.PaymentFilter{
+			UserID:   userID,
+			Statuses: []string{repository.PaymentPendingDebit},
+		},
+	)
+
+	if err != nil {
+		return errorFromDatabase
+	}
+
+	now := l.generate.Time()
+	for _, payment := range inProgress {
+		if payment.ExpiresAt == nil || payment.ExpiresAt.After(now) {
+			return errorPaymentInProgress
+		}
+	}
+
+	return nil
+}
+
+// reopenInstallments moves the paid installments through REVERSED back to unpaid, so both transitions
+// are kept in the status history. The installment past its due date (and the grace period) is reopened as OVERDUE,
+// the installments of the reversed recovery are written off again.
+// findPaidInstallments returns the installments paid by the payment, all of them should be still paid
+// since the payment is reversed as a whole.
+func (l *loanService) findPaidInstallments(
+	ctx context.Context,
+	payment *repository.PaymentEntity) ([]*repository.LoanEntity, error) {
+	paid, errFindLoan := l.loanRepository.FindLoans(
+		ctx, &repository.LoanEntity{
+			Statuses: []repository.LoanStatus{repository.LoanPaid},
+			UserID:   payment.UserID,
+		},
+	)
+
+	if errFindLoan != nil && !errors.Is(errFindLoan, repository.ErrorNoRows) {
+		return nil, errorFromDatabase
+	}
+
+	installmentIDs := make(map[uint64]bool)
+	for _, id := range payment.InstallmentIDs {
+		installmentIDs[id] = true
+	}
+
+	var loans []*repository.LoanEntity
+	for _, loan := range paid {
+		if installmentIDs[loan.ID] {
+			loans = append(loans, loan)
+		}
+	}
+
+	//the installments have been moved since (e.g. reversed), so the payment can't be undone as a whole
+	if len(loans) != len(payment.InstallmentIDs) {
+		log.Println("some installments of payment are no longer paid -> ", payment.PaymentID)
+		return nil, errorPaymentNotReversible
+	}
+
+	return loans, nil
+}
+
+func (l *loanService) reopenInstallments(
+	ctx context.Context,
+	tx *sql.Tx,
+	reversalID string,
+	loans []*repository.LoanEntity,
+	now time.Time,
+	recovery bool) error {
+	reason := "reversal " + reversalID
+
+	products, err := product.FindProductsOf(ctx, l.productRepository, loans)
+	if err != nil {
+		return err
+	}
+
+	var loanIDs, pendingIDs, overdueIDs, writtenOffIDs []uint64
+	for _, loan := range loans {
+		loanIDs = append(loanIDs, loan.ID)
+
+		if recovery {
+			writtenOffIDs = append(writtenOffIDs, loan.ID)
+			continue
+		}
+
+		if loan.DueDate.Before(product.LateBefore(l.calendar, products[loan.ProductID], now)) {
+			overdueIDs = append(overdueIDs, loan.ID)
+			continue
+		}
+
+		pendingIDs = append(pendingIDs, loan.ID)
+	}
+
+	errReversed := l.loanRepository.UpdateLoan(
+		ctx, tx, &repository.LoanEntityUpdate{
+			IDs:        loanIDs,
+			Status:     repository.LoanReversed,
+			FromStatus: repository.LoanPaid,
+			Reason:     reason,
+		},
+	)
+
+	if errReversed != nil {
+		return errReversed
+	}
+
+	for _, reopen := range []struct {
+		ids    []uint64
+		status repository.LoanStatus
+	}{
+		{ids: pendingIDs, status: repository.LoanPending},
+		{ids: overdueIDs, status: repository.LoanOverdue},
+		{ids: writtenOffIDs, status: repository.LoanWrittenOff},
+	} {
+		if len(reopen.ids) == 0 {
+			continue
+		}
+
+		errReopen := l.loanRepository.UpdateLoan(
+			ctx, tx, &repository.LoanEntityUpdate{
+				IDs:        reopen.ids,
+				Status:     reopen.status,
+				FromStatus: repository.LoanReversed,
+				Reason:     reason,
+			},
+		)
+
+		if errReopen != nil {
+			return errReopen
+		}
+	}
+
+	return nil
+}
+
+// isRefundable reports whether the money of the payment was debited by our payment gateway,
+// the bank transfer (virtual account) is returned by the bank instead.
+func (l *loanService) isRefundable(payment *repository.PaymentEntity) bool {
+	return payment.Channel != repository.PaymentChannelVirtualAccount &&
+		payment.ProviderReference != "" &&
+		payment.Provider == l.paymentGateway.Provider()
+}
+
+func (l *loanService) refundPayment(
+	ctx context.Context,
+	payment *repository.PaymentEntity,
+	reversal *repository.PaymentReversalEntity) {
+	update := &repository.PaymentReversalUpdate{
+		ReversalID:       reversal.ReversalID,
+		FromRefundStatus: repository.RefundPending,
+		RefundStatus:     repository.RefundSucceeded,
+	}
+
+	refund, errRefund := l.paymentGateway.Refund(
+		ctx, &gateway.RefundRequest{
+			ProviderReference: payment.ProviderReference,
+			Amount:            payment.Amount,
+			Reason:            reversal.Reason,
+		},
+	)
+
+	if errRefund != nil {
+		log.Println("failed refund payment -> ", payment.PaymentID, errRefund)
+		update.RefundStatus = repository.RefundFailed
+		update.FailureReason = errRefund.Error()
+	} else {
+		update.RefundReference = refund.RefundReference
+	}
+
+	if errUpdate := l.reversalRepository.UpdateReversal(ctx, update); errUpdate != nil {
+		log.Println("failed update refund of reversal -> ", reversal.ReversalID, update.RefundStatus, errUpdate)
+		return
+	}
+
+	reversal.RefundStatus = update.RefundStatus
+	reversal.RefundReference = update.RefundReference
+}
+
+// allocationOrder is the configured order of the components, comma separated. The unknown component is ignored,
+// the components which are not configured are allocated afterward in the default order.
+func (l *loanService) allocationOrder() []repository.LoanComponent {
+	var order []repository.LoanComponent
+	configured := make(map[repository.LoanComponent]bool)
+	for _, value := range strings.Split(l.cfg.GetString("payment.allocation.order"), ",") {
+		component := repository.LoanComponent(strings.ToLower(strings.TrimSpace(value)))
+		if !isLoanComponent(component) || configured[component] {
+			continue
+		}
+
+		configured[component] = true
+		order = append(order, component)
+	}
+
+	for _, component := range repository.LoanComponents {
+		if !configured[component] {
+			order = append(order, component)
+		}
+	}
+
+	return order
+}
+
+func (l *loanService) chart() *journal.Chart {
+	return journal.NewChart(l.cfg)
+}
+
+func (l *loanService) writeOffDpd() int {
+	dpd := int(l.cfg.GetInt("writeoff.dpd.threshold"))
+	if dpd <= 0 {
+		return defaultWriteOffDpd
+	}
+
+	return dpd
+}
+
+func (l *loanService) payoffQuoteExpiry() time.Duration {
+	expiry := time.Duration(l.cfg.GetInt("payoff.quote.expiry.minutes")) * time.Minute
+	if expiry <= 0 {
+		return defaultPayoffQuoteExpiry
+	}
+
+	return expiry
+}
+
+func (l *loanService) qrisExpiry() time.Duration {
+	expiry := time.Duration(l.cfg.GetInt("qris.expiry.minutes")) * time.Minute
+	if expiry <= 0 {
+		return defaultQrisExpiry
+	}
+
+	return expiry
+}
+
+func (l *loanService) findPayment(
+	ctx context.Context,
+	filter *repository.PaymentFilter) (*repository.PaymentEntity, error) {
+	payments, err := l.paymentRepository.FindPayments(ctx, filter)
+	if err != nil {
+		return nil, errorFromDatabase
+	}
+
+	if len(payments) == 0 {
+		return nil, errorDataNotExists
+	}
+
+	return payments[0], nil
+}
+
+// buildSchedule spreads each component of the outstanding evenly into the tenor, the rounding remainder
+// goes to the last installment. The first period starts after the grace period, the due date falling on
+// the non business day is moved by the policy of the calendar.
+func buildSchedule(
+	restructure *repository.LoanRestructureEntity,
+	breakdown repository.Breakdown,
+	now time.Time,
+	businessDays calendar.Calendar) []*ScheduledInstallment {
+	start := startOfDay(now).AddDate(0, 0, restructure.GracePeriodDays)
+	tenor := decimal.NewFromInt(int64(restructure.Tenor))
+	installment := repository.Breakdown{
+		Principal: breakdown.Principal.Div(tenor).RoundDown(2),
+		Interest:  breakdown.Interest.Div(tenor).RoundDown(2),
+		Fee:       breakdown.Fee.Div(tenor).RoundDown(2),
+		Penalty:   breakdown.Penalty.Div(tenor).RoundDown(2),
+	}
+	remaining := breakdown
+
+	schedule := make([]*ScheduledInstallment, 0, restructure.Tenor)
+	for i := 1; i <= restructure.Tenor; i++ {
+		dueDate := businessDays.Adjust(calculator.DueDate(start, restructure.Frequency, i))
+
+		if i == restructure.Tenor {
+			installment = remaining
+		}
+
+		remaining = remaining.Sub(installment)
+		schedule = append(
+			schedule, &ScheduledInstallment{DueDate: dueDate, Amount: installment.Total(), Breakdown: installment})
+	}
+
+	return schedule
+}
+
+// allocate splits the amount into the components of the installments, the oldest due date first
+// and the components of each installment in the order. The amount beyond the installments is not allocated.
+func allocate(
+	amount decimal.Decimal,
+	loans []*repository.LoanEntity,
+	order []repository.LoanComponent) repository.Breakdown {
+	sorted := make([]*repository.LoanEntity, len(loans))
+	copy(sorted, loans)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].DueDate.Before(sorted[j].DueDate)
+	})
+
+	allocation := repository.Breakdown{}
+	remaining := amount
+	for _, loan := range sorted {
+		due := loan.Breakdown
+		for _, component := range order {
+			if !remaining.IsPositive() {
+				return allocation
+			}
+
+			allocated := decimal.Min(remaining, *due.Component(component))
+			*allocation.Component(component) = allocation.Component(component).Add(allocated)
+			remaining = remaining.Sub(allocated)
+		}
+	}
+
+	return allocation
+}
+
+// moveLast keeps the order of the other components.
+func moveLast(order []repository.LoanComponent, last repository.LoanComponent) []repository.LoanComponent {
+	moved := make([]repository.LoanComponent, 0, len(order))
+	for _, component := range order {
+		if component != last {
+			moved = append(moved, component)
+		}
+	}
+
+	return append(moved, last)
+}
+
+func isLoanComponent(component repository.LoanComponent) bool {
+	for _, known := range repository.LoanComponents {
+		if component == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isRestructureFrequency(frequency string) bool {
+	return frequency == repository.RestructureWeekly ||
+		frequency == repository.RestructureBiweekly ||
+		frequency == repository.RestructureMonthly
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func hasWrittenOff(loans []*repository.LoanEntity) bool {
+	for _, loan := range loans {
+		if loan.Status == repository.LoanWrittenOff {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isUnpaid(loan *repository.LoanEntity) bool {
+	return loan.Status == repository.LoanPending || loan.Status == repository.LoanOverdue
+}
+
+func toPaymentResponse(payment *repository.PaymentEntity) *PaymentResponse {
+	return &PaymentResponse{
+		PaymentID:     payment.PaymentID,
+		Status:        payment.Status,
+		FailureReason: payment.FailureReason,
+		Allocation:    &payment.Allocation,
+	}
+}
+
+func (l *loanService) buildPaymentEvents(
+	payment *repository.PaymentEntity,
+	loans []*repository.LoanEntity,
+	isClosed bool) ([]*repository.OutboxEntity, error) {
+	paymentID, userID := payment.PaymentID, payment.UserID
+	paidAt := l.generate.Time()
+
+	var loanIDs []uint64
+	var outboxes []*repository.OutboxEntity
+
+	for _, loan := range loans {
+		loanIDs = append(loanIDs, loan.ID)
+
+		outbox, err := event.NewOutbox(
+			l.generate.Uuid(), event.InstallmentPaid, userID, paidAt,
+			&event.InstallmentPaidPayload{
+				PaymentID:     paymentID,
+				InstallmentID: loan.ID,
+				UserID:        userID,
+				Amount:        loan.Amount,
+				DueDate:       loan.DueDate,
+				PaidAt:        paidAt,
+				Breakdown:     loan.Breakdown,
+			},
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		outboxes = append(outboxes, outbox)
+	}
+
+	outbox, err := event.NewOutbox(
+		l.generate.Uuid(), event.PaymentSucceeded, userID, paidAt,
+		&event.PaymentSucceededPayload{
+			PaymentID:      paymentID,
+			UserID:         userID,
+			Amount:         payment.Amount,
+			InstallmentIDs: loanIDs,
+			PaidAt:         paidAt,
+			Allocation:     payment.Allocation,
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	outboxes = append(outboxes, outbox)
+
+	if !isClosed {
+		return outboxes, nil
+	}
+
+	outbox, err = event.NewOutbox(
+		l.generate.Uuid(), event.LoanClosed, userID, paidAt,
+		&event.LoanClosedPayload{
+			UserID:   userID,
+			ClosedAt: paidAt,
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append(outboxes, outbox), nil
+}
+
+// buildPaymentEntries books the accrual of the paid installments not accrued yet, then the payment itself.
+// They are booked at the business date, so they are exported with the date the payment is closed by.
+func (l *loanService) buildPaymentEntries(
+	payment *repository.PaymentEntity,
+	loans []*repository.LoanEntity) []*repository.JournalEntryEntity {
+	bookedAt := l.clock.Now()
+	chart := l.chart()
+
+	var entries []*repository.JournalEntryEntity
+	//the written-off installment has been charged off, its payment is booked as recovery
+	if !payment.Recovery {
+		for _, loan := range loans {
+			if accrual := chart.Accrual(l.generate.Uuid(), loan, bookedAt); accrual != nil {
+				entries = append(entries, accrual)
+			}
+		}
+	}
+
+	return append(entries, chart.Payment(l.generate.Uuid(), payment, loans, bookedAt))
+}
+
+// findAccrued returns the installments whose accrual is booked.
+func (l *loanService) findAccrued(
+	ctx context.Context,
+	loans []*repository.LoanEntity) (map[uint64]bool, error) {
+	references := make([]string, 0, len(loans))
+	ids := make(map[string]uint64)
+	for _, loan := range loans {
+		reference := journal.Reference(loan.ID)
+		references = append(references, reference)
+		ids[reference] = loan.ID
+	}
+
+	entries, err := l.journalRepository.FindEntries(
+		ctx, &repository.JournalEntryFilter{
+			EntryType:  string(journal.Accrual),
+			References: references,
+		},
+	)
+
+	if err != nil {
+		log.Println("failed find accrual entries -> ", err)
+		return nil, errorFromDatabase
+	}
+
+	accrued := make(map[uint64]bool)
+	for _, entry := range entries {
+		accrued[ids[entry.Reference]] = true
+	}
+
+	return accrued, nil
+}
+
+// delinquencyTransition assesses the customer as if the installments changed by the write were committed,
+// it returns the event to be recorded by the write when the delinquency of the customer changes.
+func (l *loanService) delinquencyTransition(
+	ctx context.Context,
+	userID string,
+	changed []*repository.LoanEntity) (*repository.OutboxEntity, error) {
+	assessment, err := l.assessor.Assess(ctx, userID, l.clock.Now(), changed...)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.tracker.Transition(ctx, assessment, l.generate.Time())
+}
+
+func toOutstandingResponse(assessment *delinquency.Assessment) *FetchOutstandingResponse {
+	return &FetchOutstandingResponse{
+		RemainingOutstanding: assessment.RemainingOutstanding,
+		IsDelinquent:         assessment.IsDelinquent,
+		Breakdown:            assessment.Breakdown,
+		GraceOutstanding:     assessment.GraceOutstanding,
+		GraceInstallmentIDs:  assessment.GraceInstallmentIDs,
+	}
 }
@@ -2,7 +2,10 @@ package loan
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,12 +13,44 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/delinquency"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/calendar"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/gateway"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/qris"
 	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/mocks/configuration"
+	mocks3 "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/infrastructure/gateway"
+	mocks4 "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/infrastructure/qris"
 	mocks2 "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/infrastructure/repository"
 )
 
+// noHolidays is the calendar whose every day is the business day.
+var noHolidays = calendar.NewCalendar(calendar.DefaultRegion, calendar.PolicyNone, nil, nil)
+
 func Test_loanService_FetchOutstanding(t *testing.T) {
 	mockLoanRepo := &mocks2.LoanRepository{}
+	mockOutboxRepo := &mocks2.OutboxRepository{}
+	mockDelinquencyRepo := &mocks2.DelinquencyRepository{}
+	mockProductRepo := &mocks2.ProductRepository{}
+	mockTransaction := &mocks2.Transaction{}
+	yesterday := time.Now().AddDate(0, 0, -1)
+
+	//the written-off balance is looked up separately, registered first to take precedence over the due loans
+	var writtenOff []*repository.LoanEntity
+	mockLoanRepo.
+		On(
+			"FindLoans", mock.Anything, mock.MatchedBy(func(filter *repository.LoanEntity) bool {
+				return len(filter.Statuses) == 1 && filter.Statuses[0] == repository.LoanWrittenOff
+			}),
+		).
+		Return(func(ctx context.Context, filter *repository.LoanEntity) ([]*repository.LoanEntity, error) {
+			if len(writtenOff) == 0 {
+				return nil, repository.ErrorNoRows
+			}
+
+			return writtenOff, nil
+		})
 
 	type args struct {
 		uid string
@@ -68,7 +103,7 @@ func Test_loanService_FetchOutstanding(t *testing.T) {
 				mockLoanRepo.
 					On(
 						"FindLoans", mock.Anything, repository.LoanEntity{
-							Statuses: []string{"PENDING", "CLOSED"},
+							Statuses: []repository.LoanStatus{repository.LoanPending, repository.LoanClosed},
 							UserID:   "asd",
 							DueDate:  time.Now(),
 						}).
@@ -202,12 +237,169 @@ func Test_loanService_FetchOutstanding(t *testing.T) {
 					Once()
 			},
 		},
+		{
+			name: "given one missed installment of the restructured schedule," +
+				"when fetchOutstanding:findLoans," +
+				"then return delinquent since the restructured schedule has no tolerance",
+			args: args{
+				uid: "abc",
+			},
+			want: &FetchOutstandingResponse{
+				RemainingOutstanding: decimal.NewFromFloat(float64(0)).Add(decimal.NewFromFloat(float64(10))),
+				IsDelinquent:         true,
+				Breakdown: repository.Breakdown{
+					Principal: decimal.NewFromFloat(float64(9)),
+					Fee:       decimal.NewFromFloat(float64(1)),
+				},
+			},
+			wantErr: nil,
+			mockFunc: func() {
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return(
+						[]*repository.LoanEntity{
+							{
+								Status: "OVERDUE",
+								Amount: decimal.NewFromFloat(float64(10)),
+								Breakdown: repository.Breakdown{
+									Principal: decimal.NewFromFloat(float64(9)),
+									Fee:       decimal.NewFromFloat(float64(1)),
+								},
+								RestructureID: "rs-1",
+							},
+						}, nil).
+					Once()
+			},
+		},
+		{
+			name: "given one missed installment of the original schedule," +
+				"when fetchOutstanding:findLoans," +
+				"then return not delinquent",
+			args: args{
+				uid: "abc",
+			},
+			want: &FetchOutstandingResponse{
+				RemainingOutstanding: decimal.NewFromFloat(float64(0)).Add(decimal.NewFromFloat(float64(10))),
+				IsDelinquent:         false,
+			},
+			wantErr: nil,
+			mockFunc: func() {
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return(
+						[]*repository.LoanEntity{
+							{
+								Status: "OVERDUE",
+								Amount: decimal.NewFromFloat(float64(10)),
+							},
+						}, nil).
+					Once()
+			},
+		},
+		{
+			name: "given written-off installments," +
+				"when fetchOutstanding:findLoans," +
+				"then return delinquent with the recoverable balance including the not yet due",
+			args: args{
+				uid: "abc",
+			},
+			want: &FetchOutstandingResponse{
+				RemainingOutstanding: decimal.NewFromFloat(float64(0)).Add(decimal.NewFromFloat(float64(10))),
+				IsDelinquent:         true,
+				RecoverableBalance: decimal.NewFromFloat(float64(0)).
+					Add(decimal.NewFromFloat(float64(10))).
+					Add(decimal.NewFromFloat(float64(13))),
+			},
+			wantErr: nil,
+			mockFunc: func() {
+				writtenOff = []*repository.LoanEntity{
+					{
+						Status: "WRITTEN_OFF",
+						Amount: decimal.NewFromFloat(float64(10)),
+					},
+					{
+						Status: "WRITTEN_OFF",
+						Amount: decimal.NewFromFloat(float64(13)),
+					},
+				}
+
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return(
+						[]*repository.LoanEntity{
+							{
+								Status: "WRITTEN_OFF",
+								Amount: decimal.NewFromFloat(float64(10)),
+							},
+						}, nil).
+					Once()
+			},
+		},
+		{
+			name: "given missed installments within the grace period of the product," +
+				"when fetchOutstanding," +
+				"then return not delinquent with the grace outstanding",
+			args: args{
+				uid: "abc",
+			},
+			want: &FetchOutstandingResponse{
+				RemainingOutstanding: decimal.NewFromFloat(float64(0)).
+					Add(decimal.NewFromFloat(float64(10))).
+					Add(decimal.NewFromFloat(float64(10))).
+					Add(decimal.NewFromFloat(float64(10))),
+				IsDelinquent:     false,
+				GraceOutstanding: decimal.NewFromFloat(float64(30)),
+			},
+			wantErr: nil,
+			mockFunc: func() {
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return(
+						[]*repository.LoanEntity{
+							{ID: 1, Status: "PENDING", Amount: decimal.NewFromFloat(float64(10)), DueDate: yesterday, ProductID: 7},
+							{ID: 2, Status: "PENDING", Amount: decimal.NewFromFloat(float64(10)), DueDate: yesterday, ProductID: 7},
+							{ID: 3, Status: "PENDING", Amount: decimal.NewFromFloat(float64(10)), DueDate: yesterday, ProductID: 7},
+						}, nil).
+					Once()
+
+				mockProductRepo.
+					On("FindProducts", mock.Anything, &repository.ProductFilter{IDs: []uint64{7}}).
+					Return([]*repository.ProductEntity{{ID: 7, GraceDays: 3}}, nil).
+					Once()
+			},
+		},
+		{
+			name: "given find products failed," +
+				"when fetchOutstanding," +
+				"then return error",
+			args: args{
+				uid: "abc",
+			},
+			wantErr: errorFromDatabase,
+			mockFunc: func() {
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return(
+						[]*repository.LoanEntity{
+							{ID: 1, Status: "PENDING", Amount: decimal.NewFromFloat(float64(10)), DueDate: yesterday, ProductID: 7},
+						}, nil).
+					Once()
+
+				mockProductRepo.
+					On("FindProducts", mock.Anything, mock.Anything).
+					Return(nil, errors.New("mock error")).
+					Once()
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(
 			tt.name, func(t *testing.T) {
-				l := NewLoanService(mockLoanRepo)
+				l := NewLoanService(
+					nil, mockLoanRepo, mockOutboxRepo, mockDelinquencyRepo, nil, nil, nil, nil, nil, mockProductRepo, nil,
+					mockTransaction, nil, nil, common.NewClock(), noHolidays)
+				writtenOff = nil
 				tt.mockFunc()
 
 				got, err := l.FetchOutstanding(context.Background(), tt.args.uid)
@@ -215,29 +407,164 @@ func Test_loanService_FetchOutstanding(t *testing.T) {
 				if got != nil {
 					assert.Equal(t, tt.want.RemainingOutstanding, got.RemainingOutstanding)
 					assert.Equal(t, tt.want.IsDelinquent, got.IsDelinquent)
+					assert.Equal(t, tt.want.RecoverableBalance, got.RecoverableBalance)
+					assert.Equal(t, tt.want.Breakdown.Principal.String(), got.Breakdown.Principal.String())
+					assert.Equal(t, tt.want.Breakdown.Fee.String(), got.Breakdown.Fee.String())
+					assert.Equal(t, tt.want.GraceOutstanding.String(), got.GraceOutstanding.String())
 				}
 
 				assert.Equal(t, tt.wantErr, err)
 			})
 	}
+
+	//the outstanding is only read, the delinquency transition is recorded by the writes which cause it
+	mockDelinquencyRepo.AssertNotCalled(t, "UpsertDelinquency", mock.Anything, mock.Anything, mock.Anything)
+	mockOutboxRepo.AssertNotCalled(t, "SaveOutbox", mock.Anything, mock.Anything, mock.Anything)
+	mockTransaction.AssertNotCalled(t, "WithTransaction", mock.Anything, mock.Anything)
 }
 
 func Test_loanService_Payment(t *testing.T) {
 	mockLoanRepo := &mocks2.LoanRepository{}
+	mockOutboxRepo := &mocks2.OutboxRepository{}
+	mockDelinquencyRepo := &mocks2.DelinquencyRepository{}
+	mockPaymentRepo := &mocks2.PaymentRepository{}
+	mockQuoteRepo := &mocks2.PayoffQuoteRepository{}
+	mockJournalRepo := &mocks2.JournalRepository{}
+	mockTransaction := &mocks2.Transaction{}
+	mockGateway := &mocks3.PaymentGateway{}
+	mockCfg := &mocks.Configuration{}
+
+	mockDelinquencyRepo.
+		On("FindDelinquency", mock.Anything, mock.Anything).
+		Return(nil, repository.ErrorNoRows)
+
+	mockCfg.
+		On("GetString", "payment.allocation.order").
+		Return("")
+
+	mockCfg.
+		On("GetString", mock.Anything).
+		Return("")
+
+	mockGateway.
+		On("Provider").
+		Return(gateway.ProviderFake)
+
+	withTransaction := func(ctx context.Context, fn func(tx *sql.Tx) error) error {
+		return fn(nil)
+	}
+
+	allPending := []*repository.LoanEntity{
+		{
+			ID:     1,
+			Status: "PENDING",
+			Amount: decimal.NewFromFloat(float64(20)),
+		},
+		{
+			ID:     2,
+			Status: "PENDING",
+			Amount: decimal.NewFromFloat(float64(5)),
+		},
+		{
+			ID:     3,
+			Status: "PENDING",
+			Amount: decimal.NewFromFloat(float64(5)),
+		},
+	}
 
 	payReq := &PaymentRequest{
 		UserID: "abc",
 		Amount: float64(25),
 	}
 
+	quote := &repository.PayoffQuoteEntity{
+		QuoteID:        "q-1",
+		UserID:         "abc",
+		InstallmentIDs: []uint64{1, 2, 3},
+		Outstanding:    decimal.NewFromFloat(float64(30)),
+		Discount:       decimal.NewFromFloat(float64(1)),
+		Amount:         decimal.NewFromFloat(float64(29)),
+		Status:         repository.PayoffQuoteActive,
+		ExpiresAt:      time.Now().Add(time.Hour),
+	}
+
+	payoffReq := &PaymentRequest{
+		UserID:  "abc",
+		Amount:  float64(29),
+		QuoteID: "q-1",
+	}
+
+	//the due installments found and no payment in progress, then the payment is recorded as PENDING_DEBIT
+	pendingDebit := func() {
+		mockLoanRepo.
+			On("FindLoans", mock.Anything, mock.Anything).
+			Return(allPending[:2], nil).
+			Once()
+
+		mockPaymentRepo.
+			On("FindPayments", mock.Anything, mock.Anything).
+			Return(nil, nil).
+			Once()
+
+		mockTransaction.
+			On("WithTransaction", mock.Anything, mock.Anything).
+			Return(withTransaction).
+			Once()
+
+		mockPaymentRepo.
+			On(
+				"SavePayment", mock.Anything, mock.Anything,
+				mock.MatchedBy(func(payment *repository.PaymentEntity) bool {
+					return payment.Status == repository.PaymentPendingDebit &&
+						assert.ObjectsAreEqual([]uint64{1, 2}, payment.InstallmentIDs)
+				})).
+			Return(nil).
+			Once()
+	}
+
+	charged := func(status gateway.Status) {
+		mockGateway.
+			On("Charge", mock.Anything, mock.Anything).
+			Return(&gateway.Charge{ProviderReference: "fake-1", Status: status, Amount: decimal.NewFromFloat(25)}, nil).
+			Once()
+	}
+
+	paid := func() {
+		mockTransaction.
+			On("WithTransaction", mock.Anything, mock.Anything).
+			Return(withTransaction).
+			Once()
+
+		mockPaymentRepo.
+			On(
+				"UpdatePayment", mock.Anything, mock.Anything,
+				mock.MatchedBy(func(payment *repository.PaymentEntityUpdate) bool {
+					return payment.Status == repository.PaymentPaid
+				})).
+			Return(nil).
+			Once()
+	}
+
+	booked := func() {
+		mockJournalRepo.
+			On(
+				"SaveEntries", mock.Anything, mock.Anything,
+				mock.MatchedBy(func(entry *repository.JournalEntryEntity) bool {
+					return entry.EntryType == "PAYMENT" && entry.IsBalanced()
+				})).
+			Return(nil).
+			Once()
+	}
+
 	type args struct {
 		paymentRequest *PaymentRequest
 	}
 	tests := []struct {
-		name     string
-		args     args
-		wantErr  error
-		mockFunc func()
+		name       string
+		args       args
+		wantStatus string
+		wantErr    error
+		mockFunc   func()
 	}{
 		{
 			name: "given intentionally panic," +
@@ -251,7 +578,7 @@ func Test_loanService_Payment(t *testing.T) {
 				mockLoanRepo.
 					On(
 						"FindLoans", mock.Anything, repository.LoanEntity{
-							Statuses: []string{"PENDING", "CLOSED"},
+							Statuses: []repository.LoanStatus{repository.LoanPending, repository.LoanClosed},
 							UserID:   "asd",
 							DueDate:  time.Now(),
 						}).
@@ -301,68 +628,78 @@ func Test_loanService_Payment(t *testing.T) {
 			},
 		},
 		{
-			name: "given the validation because total amount > pending amount outstanding," +
+			name: "given previous payment is still pending debit," +
 				"when payment," +
 				"then return error",
 			args: args{
 				paymentRequest: payReq,
 			},
-			wantErr: errorAmountShouldBeSame,
+			wantErr: errorPaymentInProgress,
 			mockFunc: func() {
 				mockLoanRepo.
 					On("FindLoans", mock.Anything, mock.Anything).
-					Return(
-						[]*repository.LoanEntity{
-							{
-								Status: "PENDING",
-								Amount: decimal.NewFromFloat(float64(10)),
-							},
-							{
-								Status: "PENDING",
-								Amount: decimal.NewFromFloat(float64(13)),
-							},
-						}, nil).
+					Return(allPending[:2], nil).
+					Once()
+
+				mockPaymentRepo.
+					On("FindPayments", mock.Anything, mock.Anything).
+					Return([]*repository.PaymentEntity{{PaymentID: "p-1", Status: repository.PaymentPendingDebit}}, nil).
 					Once()
 			},
 		},
 		{
-			name: "given update loan is failed unknown error from database," +
+			name: "given previous QRIS is expired," +
 				"when payment," +
-				"then return error",
+				"then the customer is charged",
 			args: args{
 				paymentRequest: payReq,
 			},
-			wantErr: errorFromDatabase,
+			wantStatus: repository.PaymentPendingDebit,
 			mockFunc: func() {
+				expired := time.Now().Add(-time.Minute)
+
 				mockLoanRepo.
 					On("FindLoans", mock.Anything, mock.Anything).
+					Return(allPending[:2], nil).
+					Once()
+
+				mockPaymentRepo.
+					On("FindPayments", mock.Anything, mock.Anything).
 					Return(
-						[]*repository.LoanEntity{
-							{
-								Status: "PENDING",
-								Amount: decimal.NewFromFloat(float64(20)),
-							},
+						[]*repository.PaymentEntity{
 							{
-								Status: "PENDING",
-								Amount: decimal.NewFromFloat(float64(5)),
+								PaymentID: "p-1",
+								Channel:   repository.PaymentChannelQris,
+								Status:    repository.PaymentPendingDebit,
+								ExpiresAt: &expired,
 							},
 						}, nil).
 					Once()
 
-				mockLoanRepo.
-					On("UpdateLoan", mock.Anything, mock.Anything).
-					Return(errors.New("mock error")).
+				mockTransaction.
+					On("WithTransaction", mock.Anything, mock.Anything).
+					Return(withTransaction).
+					Once()
+
+				mockPaymentRepo.
+					On("SavePayment", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+
+				mockGateway.
+					On("Charge", mock.Anything, mock.Anything).
+					Return(nil, gateway.ErrorTimeout).
 					Once()
 			},
 		},
 		{
-			name: "given update loan is success," +
+			name: "given the validation because total amount > pending amount outstanding," +
 				"when payment," +
-				"then return nil",
+				"then return error",
 			args: args{
 				paymentRequest: payReq,
 			},
-			wantErr: nil,
+			wantErr: errorAmountShouldBeSame,
 			mockFunc: func() {
 				mockLoanRepo.
 					On("FindLoans", mock.Anything, mock.Anything).
@@ -370,30 +707,2319 @@ func Test_loanService_Payment(t *testing.T) {
 						[]*repository.LoanEntity{
 							{
 								Status: "PENDING",
-								Amount: decimal.NewFromFloat(float64(20)),
+								Amount: decimal.NewFromFloat(float64(10)),
 							},
 							{
 								Status: "PENDING",
-								Amount: decimal.NewFromFloat(float64(5)),
+								Amount: decimal.NewFromFloat(float64(13)),
 							},
 						}, nil).
 					Once()
 
+				mockPaymentRepo.
+					On("FindPayments", mock.Anything, mock.Anything).
+					Return(nil, nil).
+					Once()
+			},
+		},
+		{
+			name: "given save payment is failed," +
+				"when payment," +
+				"then return error without charging the customer",
+			args: args{
+				paymentRequest: payReq,
+			},
+			wantErr: errorFromDatabase,
+			mockFunc: func() {
 				mockLoanRepo.
-					On("UpdateLoan", mock.Anything, mock.Anything).
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return(allPending[:2], nil).
+					Once()
+
+				mockPaymentRepo.
+					On("FindPayments", mock.Anything, mock.Anything).
+					Return(nil, nil).
+					Once()
+
+				mockTransaction.
+					On("WithTransaction", mock.Anything, mock.Anything).
+					Return(withTransaction).
+					Once()
+
+				mockPaymentRepo.
+					On("SavePayment", mock.Anything, mock.Anything, mock.Anything).
+					Return(errors.New("mock error")).
+					Once()
+			},
+		},
+		{
+			name: "given the gateway declines the charge," +
+				"when payment," +
+				"then the payment is failed and installments are not paid",
+			args: args{
+				paymentRequest: payReq,
+			},
+			wantErr: errorPaymentDeclined,
+			mockFunc: func() {
+				pendingDebit()
+				charged(gateway.StatusDeclined)
+
+				mockTransaction.
+					On("WithTransaction", mock.Anything, mock.Anything).
+					Return(withTransaction).
+					Once()
+
+				mockPaymentRepo.
+					On(
+						"UpdatePayment", mock.Anything, mock.Anything,
+						mock.MatchedBy(func(payment *repository.PaymentEntityUpdate) bool {
+							return payment.Status == repository.PaymentFailed
+						})).
 					Return(nil).
 					Once()
 			},
 		},
-	}
-	for _, tt := range tests {
-		t.Run(
-			tt.name, func(t *testing.T) {
-				tt.mockFunc()
-				l := NewLoanService(mockLoanRepo)
+		{
+			name: "given the gateway timeout," +
+				"when payment," +
+				"then the payment is held as pending debit",
+			args: args{
+				paymentRequest: payReq,
+			},
+			wantStatus: repository.PaymentPendingDebit,
+			mockFunc: func() {
+				pendingDebit()
 
-				err := l.Payment(context.Background(), tt.args.paymentRequest)
-				assert.Equal(t, tt.wantErr, err)
+				mockGateway.
+					On("Charge", mock.Anything, mock.Anything).
+					Return(nil, gateway.ErrorTimeout).
+					Once()
+			},
+		},
+		{
+			name: "given the gateway confirms later," +
+				"when payment," +
+				"then the payment is held as pending debit with provider reference",
+			args: args{
+				paymentRequest: payReq,
+			},
+			wantStatus: repository.PaymentPendingDebit,
+			mockFunc: func() {
+				pendingDebit()
+				charged(gateway.StatusPending)
+
+				mockTransaction.
+					On("WithTransaction", mock.Anything, mock.Anything).
+					Return(withTransaction).
+					Once()
+
+				mockPaymentRepo.
+					On(
+						"UpdatePayment", mock.Anything, mock.Anything,
+						mock.MatchedBy(func(payment *repository.PaymentEntityUpdate) bool {
+							return payment.Status == repository.PaymentPendingDebit &&
+								payment.ProviderReference == "fake-1"
+						})).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given update loan is failed unknown error from database," +
+				"when payment," +
+				"then return error",
+			args: args{
+				paymentRequest: payReq,
+			},
+			wantErr: errorFromDatabase,
+			mockFunc: func() {
+				pendingDebit()
+				charged(gateway.StatusSuccess)
+
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return(allPending, nil).
+					Once()
+
+				paid()
+
+				mockLoanRepo.
+					On("UpdateLoan", mock.Anything, mock.Anything, mock.Anything).
+					Return(errors.New("mock error")).
+					Once()
+			},
+		},
+		{
+			name: "given update loan is success," +
+				"when payment," +
+				"then return paid",
+			args: args{
+				paymentRequest: payReq,
+			},
+			wantStatus: repository.PaymentPaid,
+			mockFunc: func() {
+				pendingDebit()
+				charged(gateway.StatusSuccess)
+
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return(allPending, nil).
+					Once()
+
+				paid()
+				booked()
+
+				mockLoanRepo.
+					On(
+						"UpdateLoan", mock.Anything, mock.Anything,
+						mock.MatchedBy(func(update *repository.LoanEntityUpdate) bool {
+							return reflect.DeepEqual(update.IDs, []uint64{1, 2}) &&
+								update.Status == repository.LoanPaid &&
+								strings.HasPrefix(update.Reason, "payment ")
+						})).
+					Return(nil).
+					Once()
+
+				//2 installment paid + payment succeeded
+				mockOutboxRepo.
+					On("SaveOutbox", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given the transfer is already received through virtual account," +
+				"when payment," +
+				"then return paid without charging the customer",
+			args: args{
+				paymentRequest: &PaymentRequest{
+					UserID:            "abc",
+					Amount:            float64(25),
+					Channel:           repository.PaymentChannelVirtualAccount,
+					Provider:          "bca",
+					ProviderReference: "trf-1",
+				},
+			},
+			wantStatus: repository.PaymentPaid,
+			mockFunc: func() {
+				mockPaymentRepo.
+					On("FindPayments", mock.Anything, &repository.PaymentFilter{Provider: "bca", ProviderReference: "trf-1"}).
+					Return(nil, nil).
+					Once()
+
+				pendingDebit()
+
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return(allPending, nil).
+					Once()
+
+				paid()
+				booked()
+
+				mockLoanRepo.
+					On("UpdateLoan", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+
+				mockOutboxRepo.
+					On("SaveOutbox", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given the transfer is already paid by the payment of the same bank reference," +
+				"when payment," +
+				"then return the payment without paying again",
+			args: args{
+				paymentRequest: &PaymentRequest{
+					UserID:            "abc",
+					Amount:            float64(25),
+					Channel:           repository.PaymentChannelVirtualAccount,
+					Provider:          "bca",
+					ProviderReference: "trf-1",
+				},
+			},
+			wantStatus: repository.PaymentPaid,
+			mockFunc: func() {
+				mockPaymentRepo.
+					On("FindPayments", mock.Anything, &repository.PaymentFilter{Provider: "bca", ProviderReference: "trf-1"}).
+					Return([]*repository.PaymentEntity{
+						{
+							PaymentID:         "p-1",
+							UserID:            "abc",
+							Amount:            decimal.NewFromFloat(25),
+							Channel:           repository.PaymentChannelVirtualAccount,
+							Status:            repository.PaymentPaid,
+							Provider:          "bca",
+							ProviderReference: "trf-1",
+						},
+					}, nil).
+					Once()
+			},
+		},
+		{
+			name: "given payment settles all the pending installments," +
+				"when payment," +
+				"then return paid and loan closed event is saved",
+			args: args{
+				paymentRequest: payReq,
+			},
+			wantStatus: repository.PaymentPaid,
+			mockFunc: func() {
+				pendingDebit()
+				charged(gateway.StatusSuccess)
+
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return(allPending[:2], nil).
+					Once()
+
+				paid()
+				booked()
+
+				mockLoanRepo.
+					On("UpdateLoan", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+
+				//2 installment paid + payment succeeded + loan closed
+				mockOutboxRepo.
+					On("SaveOutbox", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given save outbox is failed," +
+				"when payment," +
+				"then return error",
+			args: args{
+				paymentRequest: payReq,
+			},
+			wantErr: errorFromDatabase,
+			mockFunc: func() {
+				pendingDebit()
+				charged(gateway.StatusSuccess)
+
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return(allPending[:2], nil).
+					Once()
+
+				paid()
+				booked()
+
+				mockLoanRepo.
+					On("UpdateLoan", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+
+				mockOutboxRepo.
+					On("SaveOutbox", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Return(errors.New("mock error")).
+					Once()
+			},
+		},
+		{
+			name: "given the payoff quote is expired," +
+				"when payment with the quote," +
+				"then return error",
+			args: args{
+				paymentRequest: payoffReq,
+			},
+			wantErr: errorQuoteNotActive,
+			mockFunc: func() {
+				expired := *quote
+				expired.ExpiresAt = time.Now().Add(-time.Minute)
+
+				mockQuoteRepo.
+					On("FindQuote", mock.Anything, "q-1").
+					Return(&expired, nil).
+					Once()
+			},
+		},
+		{
+			name: "given one of the quoted installments has been paid since," +
+				"when payment with the quote," +
+				"then return error",
+			args: args{
+				paymentRequest: payoffReq,
+			},
+			wantErr: errorQuoteNotActive,
+			mockFunc: func() {
+				mockQuoteRepo.
+					On("FindQuote", mock.Anything, "q-1").
+					Return(quote, nil).
+					Once()
+
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return(allPending[1:], nil).
+					Once()
+			},
+		},
+		{
+			name: "given the quote is used by another payment in the meantime," +
+				"when payment with the quote," +
+				"then return error",
+			args: args{
+				paymentRequest: payoffReq,
+			},
+			wantErr: errorQuoteNotActive,
+			mockFunc: func() {
+				mockQuoteRepo.
+					On("FindQuote", mock.Anything, "q-1").
+					Return(quote, nil).
+					Once()
+
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return(allPending, nil).
+					Once()
+
+				mockPaymentRepo.
+					On("FindPayments", mock.Anything, mock.Anything).
+					Return(nil, nil).
+					Once()
+
+				mockTransaction.
+					On("WithTransaction", mock.Anything, mock.Anything).
+					Return(withTransaction).
+					Once()
+
+				mockQuoteRepo.
+					On("UpdateQuote", mock.Anything, mock.Anything, mock.Anything).
+					Return(repository.ErrorNoRows).
+					Once()
+			},
+		},
+		{
+			name: "given the active quote," +
+				"when payment with the quoted amount," +
+				"then all the installments are paid and the quote is used",
+			args: args{
+				paymentRequest: payoffReq,
+			},
+			wantStatus: repository.PaymentPaid,
+			mockFunc: func() {
+				mockQuoteRepo.
+					On("FindQuote", mock.Anything, "q-1").
+					Return(quote, nil).
+					Once()
+
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return(allPending, nil).
+					Once()
+
+				mockPaymentRepo.
+					On("FindPayments", mock.Anything, mock.Anything).
+					Return(nil, nil).
+					Once()
+
+				mockTransaction.
+					On("WithTransaction", mock.Anything, mock.Anything).
+					Return(withTransaction).
+					Once()
+
+				mockQuoteRepo.
+					On(
+						"UpdateQuote", mock.Anything, mock.Anything,
+						mock.MatchedBy(func(update *repository.PayoffQuoteUpdate) bool {
+							return update.QuoteID == "q-1" && update.Status == repository.PayoffQuoteUsed &&
+								update.PaymentID != ""
+						})).
+					Return(nil).
+					Once()
+
+				mockPaymentRepo.
+					On(
+						"SavePayment", mock.Anything, mock.Anything,
+						mock.MatchedBy(func(payment *repository.PaymentEntity) bool {
+							return payment.Amount.Equal(decimal.NewFromFloat(29)) &&
+								assert.ObjectsAreEqual([]uint64{1, 2, 3}, payment.InstallmentIDs)
+						})).
+					Return(nil).
+					Once()
+
+				charged(gateway.StatusSuccess)
+
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return(allPending, nil).
+					Once()
+
+				paid()
+				booked()
+
+				mockLoanRepo.
+					On("UpdateLoan", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+
+				mockOutboxRepo.
+					On("SaveOutbox", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.mockFunc()
+				l := &loanService{
+					cfg:                   mockCfg,
+					loanRepository:        mockLoanRepo,
+					outboxRepository:      mockOutboxRepo,
+					paymentRepository:     mockPaymentRepo,
+					payoffQuoteRepository: mockQuoteRepo,
+					journalRepository:     mockJournalRepo,
+					transaction:           mockTransaction,
+					paymentGateway:        mockGateway,
+					generate:              common.NewGenerate(),
+					clock:                 common.NewClock(),
+					calendar:              noHolidays,
+					assessor:              delinquency.NewAssessor(mockLoanRepo, nil, noHolidays),
+					tracker:               delinquency.NewTracker(mockDelinquencyRepo, mockOutboxRepo),
+				}
+
+				got, err := l.Payment(context.Background(), tt.args.paymentRequest)
+				assert.Equal(t, tt.wantErr, err)
+
+				if tt.wantStatus != "" {
+					assert.Equal(t, tt.wantStatus, got.Status)
+				}
+			})
+	}
+
+	mockPaymentRepo.AssertExpectations(t)
+	mockQuoteRepo.AssertExpectations(t)
+	mockGateway.AssertExpectations(t)
+	mockJournalRepo.AssertExpectations(t)
+}
+
+func Test_loanService_PayoffQuote(t *testing.T) {
+	now := time.Now()
+	installments := func(principal, fee float64) []*repository.LoanEntity {
+		breakdown := repository.Breakdown{Principal: decimal.NewFromFloat(principal), Fee: decimal.NewFromFloat(fee)}
+		amount := decimal.NewFromFloat(principal + fee)
+		return []*repository.LoanEntity{
+			{
+				ID:        1,
+				Status:    repository.LoanOverdue,
+				Amount:    amount,
+				Breakdown: breakdown,
+				DueDate:   now.AddDate(0, 0, -7),
+			},
+			{
+				ID:        2,
+				Status:    repository.LoanPending,
+				Amount:    amount,
+				Breakdown: breakdown,
+				DueDate:   now,
+			},
+			{
+				ID:        3,
+				Status:    repository.LoanPending,
+				Amount:    amount,
+				Breakdown: breakdown,
+				DueDate:   now.AddDate(0, 0, 7),
+			},
+		}
+	}
+
+	unpaid := installments(10, 1)
+
+	tests := []struct {
+		name         string
+		uid          string
+		loans        []*repository.LoanEntity
+		saveErr      error
+		wantDiscount string
+		wantAmount   string
+		wantErr      error
+	}{
+		{
+			name: "given not passed the validation," +
+				"when payoff quote," +
+				"then return error",
+			wantErr: errorValidation,
+		},
+		{
+			name: "given no unpaid installments," +
+				"when payoff quote," +
+				"then return error",
+			uid:     "abc",
+			wantErr: errorNoPendingOutstanding,
+		},
+		{
+			name: "given save quote is failed," +
+				"when payoff quote," +
+				"then return error",
+			uid:     "abc",
+			loans:   unpaid,
+			saveErr: errors.New("mock error"),
+			wantErr: errorFromDatabase,
+		},
+		{
+			name: "given overdue and not yet due installments," +
+				"when payoff quote," +
+				"then only the fee of the installments not yet due is discounted",
+			uid:          "abc",
+			loans:        unpaid,
+			wantDiscount: "1",
+			wantAmount:   "32",
+		},
+		{
+			name: "given installments issued by the product with the fee rate of 37.5%," +
+				"when payoff quote," +
+				"then the fee of the installments is discounted, not the one of the amount",
+			uid:          "abc",
+			loans:        installments(8, 3),
+			wantDiscount: "3",
+			wantAmount:   "30",
+		},
+		{
+			name: "given installments without fee," +
+				"when payoff quote," +
+				"then nothing is discounted",
+			uid:          "abc",
+			loans:        installments(11, 0),
+			wantDiscount: "0",
+			wantAmount:   "33",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockCfg := &mocks.Configuration{}
+				mockLoanRepo := &mocks2.LoanRepository{}
+				mockQuoteRepo := &mocks2.PayoffQuoteRepository{}
+
+				mockCfg.
+					On("GetFloat", "payoff.discount.percent").
+					Return(float64(50))
+
+				mockCfg.
+					On("GetInt", "payoff.quote.expiry.minutes").
+					Return(int64(0))
+
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return(tt.loans, nil)
+
+				mockQuoteRepo.
+					On(
+						"SaveQuote", mock.Anything,
+						mock.MatchedBy(func(quote *repository.PayoffQuoteEntity) bool {
+							return quote.Status == repository.PayoffQuoteActive &&
+								assert.ObjectsAreEqual([]uint64{1, 2, 3}, quote.InstallmentIDs)
+						})).
+					Return(tt.saveErr)
+
+				l := NewLoanService(
+					mockCfg, mockLoanRepo, nil, nil, nil, nil, mockQuoteRepo, nil, nil, nil, nil, nil, nil, nil,
+					common.NewClock(), noHolidays)
+
+				got, err := l.PayoffQuote(context.Background(), tt.uid)
+				assert.Equal(t, tt.wantErr, err)
+
+				if tt.wantErr == nil {
+					assert.Equal(t, "33", got.Outstanding.String())
+					assert.Equal(t, tt.wantDiscount, got.Discount.String())
+					assert.Equal(t, tt.wantAmount, got.Amount.String())
+					assert.WithinDuration(t, time.Now().Add(time.Hour), got.ExpiresAt, time.Minute)
+					mockQuoteRepo.AssertExpectations(t)
+				}
+			})
+	}
+}
+
+func Test_loanService_CreateQris(t *testing.T) {
+	withTransaction := func(ctx context.Context, fn func(tx *sql.Tx) error) error {
+		return fn(nil)
+	}
+
+	pending := []*repository.LoanEntity{
+		{
+			ID:     1,
+			Status: "PENDING",
+			Amount: decimal.NewFromFloat(float64(20)),
+		},
+		{
+			ID:     2,
+			Status: "PENDING",
+			Amount: decimal.NewFromFloat(float64(5)),
+		},
+	}
+
+	tests := []struct {
+		name       string
+		request    *QrisRequest
+		loans      []*repository.LoanEntity
+		inProgress []*repository.PaymentEntity
+		qrisErr    error
+		wantErr    error
+	}{
+		{
+			name: "given not passed the validation," +
+				"when create qris," +
+				"then return error",
+			request: &QrisRequest{},
+			wantErr: errorValidation,
+		},
+		{
+			name: "given no due installments," +
+				"when create qris," +
+				"then return error",
+			request: &QrisRequest{UserID: "abc"},
+			wantErr: errorNoPendingOutstanding,
+		},
+		{
+			name: "given the installment is not pending," +
+				"when create qris," +
+				"then return error",
+			request: &QrisRequest{UserID: "abc", InstallmentID: 9},
+			wantErr: errorDataNotExists,
+		},
+		{
+			name: "given previous payment is still pending debit," +
+				"when create qris," +
+				"then return error",
+			request:    &QrisRequest{UserID: "abc"},
+			loans:      pending,
+			inProgress: []*repository.PaymentEntity{{PaymentID: "p-1", Status: repository.PaymentPendingDebit}},
+			wantErr:    errorPaymentInProgress,
+		},
+		{
+			name: "given the qris can not be generated," +
+				"when create qris," +
+				"then return error",
+			request: &QrisRequest{UserID: "abc"},
+			loans:   pending,
+			qrisErr: qris.ErrorValueTooLong,
+			wantErr: errorFromDatabase,
+		},
+		{
+			name: "given the due installments," +
+				"when create qris," +
+				"then the qris is recorded as pending debit",
+			request: &QrisRequest{UserID: "abc"},
+			loans:   pending,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockCfg := &mocks.Configuration{}
+				mockLoanRepo := &mocks2.LoanRepository{}
+				mockPaymentRepo := &mocks2.PaymentRepository{}
+				mockTransaction := &mocks2.Transaction{}
+				mockGateway := &mocks3.PaymentGateway{}
+				mockQris := &mocks4.Generator{}
+
+				mockCfg.
+					On("GetInt", "qris.expiry.minutes").
+					Return(int64(15))
+
+				mockCfg.
+					On("GetString", "payment.allocation.order").
+					Return("penalty,fee,interest,principal")
+
+				mockGateway.
+					On("Provider").
+					Return(gateway.ProviderFake)
+
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return(tt.loans, nil)
+
+				mockPaymentRepo.
+					On("FindPayments", mock.Anything, mock.Anything).
+					Return(tt.inProgress, nil)
+
+				mockQris.
+					On("Dynamic", mock.Anything).
+					Return("000201", tt.qrisErr)
+
+				mockTransaction.
+					On("WithTransaction", mock.Anything, mock.Anything).
+					Return(withTransaction)
+
+				mockPaymentRepo.
+					On(
+						"SavePayment", mock.Anything, mock.Anything,
+						mock.MatchedBy(func(payment *repository.PaymentEntity) bool {
+							return payment.Channel == repository.PaymentChannelQris &&
+								payment.Status == repository.PaymentPendingDebit &&
+								payment.PaymentCode == "000201" &&
+								payment.ExpiresAt != nil &&
+								assert.ObjectsAreEqual([]uint64{1, 2}, payment.InstallmentIDs)
+						})).
+					Return(nil).
+					Once()
+
+				l := NewLoanService(
+					mockCfg, mockLoanRepo, nil, nil, mockPaymentRepo, nil, nil, nil, nil, nil, nil, mockTransaction,
+					mockGateway, mockQris, common.NewClock(), noHolidays)
+
+				got, err := l.CreateQris(context.Background(), tt.request)
+				assert.Equal(t, tt.wantErr, err)
+
+				if tt.wantErr == nil {
+					assert.Equal(t, decimal.NewFromFloat(float64(25)).String(), got.Amount.String())
+					assert.Equal(t, "000201", got.Payload)
+					assert.WithinDuration(t, time.Now().Add(15*time.Minute), got.ExpiresAt, time.Minute)
+					mockPaymentRepo.AssertExpectations(t)
+				}
+			})
+	}
+}
+
+func Test_loanService_SettlePayment(t *testing.T) {
+	withTransaction := func(ctx context.Context, fn func(tx *sql.Tx) error) error {
+		return fn(nil)
+	}
+
+	//the payment is moved by the settlement, so every case has its own
+	pendingDebit := func() *repository.PaymentEntity {
+		return &repository.PaymentEntity{
+			PaymentID:      "p-1",
+			UserID:         "abc",
+			Amount:         decimal.NewFromFloat(25),
+			InstallmentIDs: []uint64{1, 2},
+			Status:         repository.PaymentPendingDebit,
+		}
+	}
+
+	success := &gateway.Charge{
+		Reference:         "p-1",
+		ProviderReference: "fake-1",
+		Status:            gateway.StatusSuccess,
+		Amount:            decimal.NewFromFloat(25),
+	}
+
+	tests := []struct {
+		name     string
+		charge   *gateway.Charge
+		payments []*repository.PaymentEntity
+		// delinquent is the last known delinquency of the customer.
+		delinquent bool
+		// wantDelinquent is the delinquency recorded by the payment, nil when it doesn't change.
+		wantDelinquent *bool
+		wantErr        error
+		mockFunc       func(
+			loanRepo *mocks2.LoanRepository,
+			paymentRepo *mocks2.PaymentRepository,
+			outboxRepo *mocks2.OutboxRepository,
+			journalRepo *mocks2.JournalRepository)
+	}{
+		{
+			name: "given charge without reference," +
+				"when settlePayment," +
+				"then return error",
+			charge:  &gateway.Charge{Status: gateway.StatusSuccess},
+			wantErr: errorValidation,
+		},
+		{
+			name: "given payment is not exists," +
+				"when settlePayment," +
+				"then return error",
+			charge:  success,
+			wantErr: errorDataNotExists,
+		},
+		{
+			name: "given payment is already paid," +
+				"when settlePayment," +
+				"then nothing changes",
+			charge:   success,
+			payments: []*repository.PaymentEntity{{PaymentID: "p-1", Amount: decimal.NewFromFloat(25), Status: repository.PaymentPaid}},
+		},
+		{
+			name: "given amount of charge is different," +
+				"when settlePayment," +
+				"then return error",
+			charge: &gateway.Charge{
+				Reference: "p-1",
+				Status:    gateway.StatusSuccess,
+				Amount:    decimal.NewFromFloat(20),
+			},
+			payments: []*repository.PaymentEntity{pendingDebit()},
+			wantErr:  errorAmountShouldBeSame,
+		},
+		{
+			name: "given payment is pending debit and charge succeeds," +
+				"when settlePayment," +
+				"then installments are paid",
+			charge:   success,
+			payments: []*repository.PaymentEntity{pendingDebit()},
+			mockFunc: func(
+				loanRepo *mocks2.LoanRepository,
+				paymentRepo *mocks2.PaymentRepository,
+				outboxRepo *mocks2.OutboxRepository,
+				journalRepo *mocks2.JournalRepository) {
+				loanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return([]*repository.LoanEntity{
+						{ID: 1, Breakdown: repository.Breakdown{Principal: decimal.NewFromFloat(10), Interest: decimal.NewFromFloat(2.5)}},
+						{ID: 2, Breakdown: repository.Breakdown{Principal: decimal.NewFromFloat(10), Interest: decimal.NewFromFloat(2.5)}},
+						{ID: 3},
+					}, nil).
+					Once()
+
+				paymentRepo.
+					On("UpdatePayment", mock.Anything, mock.Anything, &repository.PaymentEntityUpdate{
+						PaymentID:         "p-1",
+						FromStatus:        repository.PaymentPendingDebit,
+						Status:            repository.PaymentPaid,
+						ProviderReference: "fake-1",
+					}).
+					Return(nil).
+					Once()
+
+				loanRepo.
+					On(
+						"UpdateLoan", mock.Anything, mock.Anything,
+						mock.MatchedBy(func(update *repository.LoanEntityUpdate) bool {
+							return reflect.DeepEqual(update.IDs, []uint64{1, 2}) &&
+								update.Status == repository.LoanPaid &&
+								strings.HasPrefix(update.Reason, "payment ")
+						})).
+					Return(nil).
+					Once()
+
+				journalRepo.
+					On(
+						"SaveEntries", mock.Anything, mock.Anything,
+						mock.MatchedBy(func(entry *repository.JournalEntryEntity) bool {
+							return entry.EntryType == "ACCRUAL" && entry.Reference == "1"
+						}),
+						mock.MatchedBy(func(entry *repository.JournalEntryEntity) bool {
+							return entry.EntryType == "ACCRUAL" && entry.Reference == "2"
+						}),
+						mock.MatchedBy(func(entry *repository.JournalEntryEntity) bool {
+							return entry.EntryType == "PAYMENT" && entry.Reference == "p-1" && entry.IsBalanced() &&
+								len(entry.Lines) == 3
+						})).
+					Return(nil).
+					Once()
+
+				outboxRepo.
+					On("SaveOutbox", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given recovery payment of written-off installments," +
+				"when settlePayment," +
+				"then installments are paid and the recovery is published",
+			charge: success,
+			payments: []*repository.PaymentEntity{
+				{
+					PaymentID:      "p-1",
+					UserID:         "abc",
+					Amount:         decimal.NewFromFloat(25),
+					InstallmentIDs: []uint64{1, 2},
+					Status:         repository.PaymentPendingDebit,
+					Recovery:       true,
+				},
+			},
+			//the remaining written-off installment keeps the customer delinquent
+			delinquent: true,
+			mockFunc: func(
+				loanRepo *mocks2.LoanRepository,
+				paymentRepo *mocks2.PaymentRepository,
+				outboxRepo *mocks2.OutboxRepository,
+				journalRepo *mocks2.JournalRepository) {
+				loanRepo.
+					On("FindLoans", mock.Anything, &repository.LoanEntity{
+						Statuses: repository.LoanCollectible,
+						UserID:   "abc",
+					}).
+					Return([]*repository.LoanEntity{
+						{ID: 1, Status: repository.LoanWrittenOff},
+						{ID: 2, Status: repository.LoanWrittenOff},
+						{ID: 3, Status: repository.LoanWrittenOff},
+					}, nil).
+					Once()
+
+				paymentRepo.
+					On("UpdatePayment", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+
+				loanRepo.
+					On("UpdateLoan", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+
+				//the written-off installments are not accrued again, the payment is booked as recovery
+				journalRepo.
+					On(
+						"SaveEntries", mock.Anything, mock.Anything,
+						mock.MatchedBy(func(entry *repository.JournalEntryEntity) bool {
+							return entry.EntryType == "PAYMENT" && entry.IsBalanced()
+						})).
+					Return(nil).
+					Once()
+
+				outboxRepo.
+					On(
+						"SaveOutbox", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+						mock.MatchedBy(func(outbox *repository.OutboxEntity) bool {
+							return outbox.EventType == "WriteOffRecovered"
+						})).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given delinquent customer pays all the missed installments," +
+				"when settlePayment," +
+				"then the delinquency is cleared in the same transaction",
+			charge:         success,
+			payments:       []*repository.PaymentEntity{pendingDebit()},
+			delinquent:     true,
+			wantDelinquent: new(bool),
+			mockFunc: func(
+				loanRepo *mocks2.LoanRepository,
+				paymentRepo *mocks2.PaymentRepository,
+				outboxRepo *mocks2.OutboxRepository,
+				journalRepo *mocks2.JournalRepository) {
+				missed := time.Now().AddDate(0, 0, -14)
+				loanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return([]*repository.LoanEntity{
+						{ID: 1, Status: repository.LoanOverdue, DueDate: missed, Amount: decimal.NewFromFloat(12.5)},
+						{ID: 2, Status: repository.LoanOverdue, DueDate: missed, Amount: decimal.NewFromFloat(12.5)},
+						{ID: 3, Status: repository.LoanPending, DueDate: time.Now().AddDate(0, 0, 7)},
+					}, nil).
+					Once()
+
+				paymentRepo.
+					On("UpdatePayment", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+
+				loanRepo.
+					On("UpdateLoan", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+
+				journalRepo.
+					On("SaveEntries", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+
+				outboxRepo.
+					On("SaveOutbox", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+
+				outboxRepo.
+					On(
+						"SaveOutbox", mock.Anything, mock.Anything,
+						mock.MatchedBy(func(outbox *repository.OutboxEntity) bool {
+							return outbox.EventType == "CustomerDelinquencyCleared" && outbox.AggregateID == "abc"
+						})).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given one of the installments is no longer collectible," +
+				"when settlePayment," +
+				"then nothing is paid and the whole payment is unapplied into suspense",
+			charge:   success,
+			payments: []*repository.PaymentEntity{pendingDebit()},
+			mockFunc: func(
+				loanRepo *mocks2.LoanRepository,
+				paymentRepo *mocks2.PaymentRepository,
+				outboxRepo *mocks2.OutboxRepository,
+				journalRepo *mocks2.JournalRepository) {
+				//the installment 2 is restructured meanwhile
+				loanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return([]*repository.LoanEntity{
+						{ID: 1, Breakdown: repository.Breakdown{Principal: decimal.NewFromFloat(10), Interest: decimal.NewFromFloat(2.5)}},
+						{ID: 3},
+					}, nil).
+					Once()
+
+				paymentRepo.
+					On("UpdatePayment", mock.Anything, mock.Anything, &repository.PaymentEntityUpdate{
+						PaymentID:         "p-1",
+						FromStatus:        repository.PaymentPendingDebit,
+						Status:            repository.PaymentUnapplied,
+						ProviderReference: "fake-1",
+						FailureReason:     unappliedReason,
+					}).
+					Return(nil).
+					Once()
+
+				journalRepo.
+					On(
+						"SaveEntries", mock.Anything, mock.Anything,
+						mock.MatchedBy(func(entry *repository.JournalEntryEntity) bool {
+							return entry.EntryType == "PAYMENT" && entry.IsBalanced() && len(entry.Lines) == 2 &&
+								entry.Lines[0].Account == "1101" && entry.Lines[1].Account == "2901" &&
+								entry.Lines[1].Credit.Equal(decimal.NewFromFloat(25))
+						})).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given payment is settled by another process," +
+				"when settlePayment," +
+				"then return nil",
+			charge:   success,
+			payments: []*repository.PaymentEntity{pendingDebit()},
+			mockFunc: func(
+				loanRepo *mocks2.LoanRepository,
+				paymentRepo *mocks2.PaymentRepository,
+				outboxRepo *mocks2.OutboxRepository,
+				journalRepo *mocks2.JournalRepository) {
+				loanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return([]*repository.LoanEntity{{ID: 1}, {ID: 2}}, nil).
+					Once()
+
+				paymentRepo.
+					On("UpdatePayment", mock.Anything, mock.Anything, mock.Anything).
+					Return(repository.ErrorNoRows).
+					Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockLoanRepo := &mocks2.LoanRepository{}
+				mockOutboxRepo := &mocks2.OutboxRepository{}
+				mockDelinquencyRepo := &mocks2.DelinquencyRepository{}
+				mockPaymentRepo := &mocks2.PaymentRepository{}
+				mockJournalRepo := &mocks2.JournalRepository{}
+				mockTransaction := &mocks2.Transaction{}
+				mockGateway := &mocks3.PaymentGateway{}
+				mockCfg := &mocks.Configuration{}
+
+				mockCfg.
+					On("GetString", mock.Anything).
+					Return("")
+
+				mockDelinquencyRepo.
+					On("FindDelinquency", mock.Anything, "abc").
+					Return(&repository.DelinquencyEntity{UserID: "abc", IsDelinquent: tt.delinquent}, nil)
+
+				mockDelinquencyRepo.
+					On("UpsertDelinquency", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil)
+
+				mockTransaction.
+					On("WithTransaction", mock.Anything, mock.Anything).
+					Return(withTransaction)
+
+				mockPaymentRepo.
+					On("FindPayments", mock.Anything, &repository.PaymentFilter{PaymentID: "p-1"}).
+					Return(tt.payments, nil)
+
+				if tt.mockFunc != nil {
+					tt.mockFunc(mockLoanRepo, mockPaymentRepo, mockOutboxRepo, mockJournalRepo)
+				}
+
+				l := NewLoanService(
+					mockCfg, mockLoanRepo, mockOutboxRepo, mockDelinquencyRepo, mockPaymentRepo, nil, nil, nil, nil, nil,
+					mockJournalRepo, mockTransaction, mockGateway, nil, common.NewClock(), noHolidays)
+
+				err := l.SettlePayment(context.Background(), tt.charge)
+				assert.Equal(t, tt.wantErr, err)
+
+				mockLoanRepo.AssertExpectations(t)
+				mockOutboxRepo.AssertExpectations(t)
+				mockJournalRepo.AssertExpectations(t)
+
+				if tt.wantDelinquent == nil {
+					mockDelinquencyRepo.AssertNotCalled(t, "UpsertDelinquency", mock.Anything, mock.Anything, mock.Anything)
+					return
+				}
+
+				mockDelinquencyRepo.AssertCalled(
+					t, "UpsertDelinquency", mock.Anything, mock.Anything,
+					mock.MatchedBy(func(delinquency *repository.DelinquencyEntity) bool {
+						return delinquency.UserID == "abc" && delinquency.IsDelinquent == *tt.wantDelinquent
+					}))
+			})
+	}
+}
+
+func Test_loanService_FindPayment(t *testing.T) {
+	tests := []struct {
+		name       string
+		payments   []*repository.PaymentEntity
+		charge     *gateway.Charge
+		chargeErr  error
+		wantStatus string
+		wantErr    error
+	}{
+		{
+			name: "given payment is not exists," +
+				"when findPayment," +
+				"then return error",
+			wantErr: errorDataNotExists,
+		},
+		{
+			name: "given payment is failed," +
+				"when findPayment," +
+				"then return it without asking the gateway",
+			payments:   []*repository.PaymentEntity{{PaymentID: "p-1", Status: repository.PaymentFailed}},
+			wantStatus: repository.PaymentFailed,
+		},
+		{
+			name: "given payment is pending debit and gateway is unreachable," +
+				"when findPayment," +
+				"then return pending debit",
+			payments:   []*repository.PaymentEntity{{PaymentID: "p-1", Status: repository.PaymentPendingDebit}},
+			chargeErr:  gateway.ErrorTimeout,
+			wantStatus: repository.PaymentPendingDebit,
+		},
+		{
+			name: "given payment is pending debit and gateway declines it," +
+				"when findPayment," +
+				"then return failed",
+			payments:   []*repository.PaymentEntity{{PaymentID: "p-1", Status: repository.PaymentPendingDebit}},
+			charge:     &gateway.Charge{Reference: "p-1", Status: gateway.StatusDeclined, FailureReason: "insufficient balance"},
+			wantStatus: repository.PaymentFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockPaymentRepo := &mocks2.PaymentRepository{}
+				mockTransaction := &mocks2.Transaction{}
+				mockGateway := &mocks3.PaymentGateway{}
+
+				mockPaymentRepo.
+					On("FindPayments", mock.Anything, &repository.PaymentFilter{PaymentID: "p-1"}).
+					Return(tt.payments, nil)
+
+				mockGateway.
+					On("Status", mock.Anything, "p-1").
+					Return(tt.charge, tt.chargeErr)
+
+				mockTransaction.
+					On("WithTransaction", mock.Anything, mock.Anything).
+					Return(func(ctx context.Context, fn func(tx *sql.Tx) error) error {
+						return fn(nil)
+					})
+
+				mockPaymentRepo.
+					On("UpdatePayment", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil)
+
+				l := NewLoanService(
+					nil, nil, nil, nil, mockPaymentRepo, nil, nil, nil, nil, nil, nil, mockTransaction, mockGateway, nil,
+					common.NewClock(), noHolidays)
+
+				got, err := l.FindPayment(context.Background(), "p-1")
+				assert.Equal(t, tt.wantErr, err)
+
+				if tt.wantStatus != "" {
+					assert.Equal(t, tt.wantStatus, got.Status)
+				}
+			})
+	}
+}
+
+func Test_loanService_ReversePayment(t *testing.T) {
+	now := time.Now()
+	principal := func(amount float64) repository.Breakdown {
+		return repository.Breakdown{Principal: decimal.NewFromFloat(amount)}
+	}
+
+	paid := []*repository.LoanEntity{
+		{ID: 1, Status: repository.LoanPaid, UserID: "abc", DueDate: now.AddDate(0, 0, -7), Amount: decimal.NewFromFloat(10), Breakdown: principal(10)},
+		{ID: 2, Status: repository.LoanPaid, UserID: "abc", DueDate: now.AddDate(0, 0, 7), Amount: decimal.NewFromFloat(15), Breakdown: principal(15)},
+		{ID: 3, Status: repository.LoanPaid, UserID: "abc", DueDate: now.AddDate(0, 0, -14), Amount: decimal.NewFromFloat(15), Breakdown: principal(15)},
+	}
+
+	directDebit := &repository.PaymentEntity{
+		PaymentID:         "p-1",
+		UserID:            "abc",
+		Amount:            decimal.NewFromFloat(25),
+		Channel:           repository.PaymentChannelDirectDebit,
+		InstallmentIDs:    []uint64{1, 2},
+		Status:            repository.PaymentPaid,
+		Provider:          gateway.ProviderFake,
+		ProviderReference: "fake-1",
+	}
+
+	virtualAccount := &repository.PaymentEntity{
+		PaymentID:         "p-1",
+		UserID:            "abc",
+		Amount:            decimal.NewFromFloat(25),
+		Channel:           repository.PaymentChannelVirtualAccount,
+		InstallmentIDs:    []uint64{1, 2},
+		Status:            repository.PaymentPaid,
+		Provider:          "bca",
+		ProviderReference: "bank-ref-1",
+	}
+
+	request := &ReversalRequest{PaymentID: "p-1", Reason: "duplicate debit", RequestedBy: "ops-1"}
+
+	tests := []struct {
+		name             string
+		request          *ReversalRequest
+		payment          *repository.PaymentEntity
+		paid             []*repository.LoanEntity
+		updatePaymentErr error
+		refundErr        error
+		wantRefund       string
+		wantErr          error
+	}{
+		{
+			name: "given the reason is empty," +
+				"when reversePayment," +
+				"then return error validation",
+			request: &ReversalRequest{PaymentID: "p-1", RequestedBy: "ops-1"},
+			wantErr: errorValidation,
+		},
+		{
+			name: "given the payment is still pending debit," +
+				"when reversePayment," +
+				"then return error not reversible",
+			request: request,
+			payment: &repository.PaymentEntity{PaymentID: "p-1", Status: repository.PaymentPendingDebit},
+			wantErr: errorPaymentNotReversible,
+		},
+		{
+			name: "given one of the installments is no longer paid," +
+				"when reversePayment," +
+				"then return error not reversible",
+			request: request,
+			payment: directDebit,
+			paid:    paid[:1],
+			wantErr: errorPaymentNotReversible,
+		},
+		{
+			name: "given the payment is reversed concurrently," +
+				"when reversePayment," +
+				"then return error not reversible",
+			request:          request,
+			payment:          directDebit,
+			paid:             paid,
+			updatePaymentErr: repository.ErrorNoRows,
+			wantErr:          errorPaymentNotReversible,
+		},
+		{
+			name: "given the payment is debited by the gateway," +
+				"when reversePayment," +
+				"then reopen the installments and refund it",
+			request:    request,
+			payment:    directDebit,
+			paid:       paid,
+			wantRefund: repository.RefundSucceeded,
+		},
+		{
+			name: "given the refund is failed," +
+				"when reversePayment," +
+				"then the reversal is kept with failed refund",
+			request:    request,
+			payment:    directDebit,
+			paid:       paid,
+			refundErr:  gateway.ErrorGateway,
+			wantRefund: repository.RefundFailed,
+		},
+		{
+			name: "given the payment is received through virtual account," +
+				"when reversePayment," +
+				"then reopen the installments without refund",
+			request:    request,
+			payment:    virtualAccount,
+			paid:       paid,
+			wantRefund: repository.RefundNotApplicable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockLoanRepo := &mocks2.LoanRepository{}
+				mockOutboxRepo := &mocks2.OutboxRepository{}
+				mockDelinquencyRepo := &mocks2.DelinquencyRepository{}
+				mockPaymentRepo := &mocks2.PaymentRepository{}
+				mockReversalRepo := &mocks2.PaymentReversalRepository{}
+				mockJournalRepo := &mocks2.JournalRepository{}
+				mockTransaction := &mocks2.Transaction{}
+				mockGateway := &mocks3.PaymentGateway{}
+				mockCfg := &mocks.Configuration{}
+
+				mockCfg.
+					On("GetString", mock.Anything).
+					Return("")
+
+				var payments []*repository.PaymentEntity
+				if tt.payment != nil {
+					payments = append(payments, tt.payment)
+				}
+
+				mockPaymentRepo.
+					On("FindPayments", mock.Anything, &repository.PaymentFilter{PaymentID: "p-1"}).
+					Return(payments, nil)
+
+				mockLoanRepo.
+					On(
+						"FindLoans", mock.Anything, &repository.LoanEntity{
+							Statuses: []repository.LoanStatus{repository.LoanPaid},
+							UserID:   "abc",
+						}).
+					Return(tt.paid, nil)
+
+				mockGateway.On("Provider").Return(gateway.ProviderFake).Maybe()
+
+				mockTransaction.
+					On("WithTransaction", mock.Anything, mock.Anything).
+					Return(func(ctx context.Context, fn func(tx *sql.Tx) error) error {
+						return fn(nil)
+					})
+
+				mockPaymentRepo.
+					On(
+						"UpdatePayment", mock.Anything, mock.Anything, &repository.PaymentEntityUpdate{
+							PaymentID:  "p-1",
+							FromStatus: repository.PaymentPaid,
+							Status:     repository.PaymentReversed,
+						}).
+					Return(tt.updatePaymentErr)
+
+				//the customer has missed 2 installments, the reopened one makes the customer delinquent again
+				missed := func(id uint64) *repository.LoanEntity {
+					return &repository.LoanEntity{
+						ID: id, Status: repository.LoanOverdue, UserID: "abc", DueDate: now.AddDate(0, 0, -21),
+					}
+				}
+
+				mockLoanRepo.
+					On(
+						"FindLoans", mock.Anything,
+						mock.MatchedBy(func(filter *repository.LoanEntity) bool {
+							return reflect.DeepEqual(filter.Statuses, delinquency.DueStatuses) && filter.UserID == "abc"
+						})).
+					Return([]*repository.LoanEntity{missed(4), missed(5)}, nil).
+					Once()
+
+				mockDelinquencyRepo.
+					On("FindDelinquency", mock.Anything, "abc").
+					Return(nil, repository.ErrorNoRows).
+					Once()
+
+				if tt.wantErr == nil {
+					reopened := func(status repository.LoanStatus, ids []uint64) interface{} {
+						return mock.MatchedBy(func(update *repository.LoanEntityUpdate) bool {
+							return update.Status == status && reflect.DeepEqual(update.IDs, ids) &&
+								strings.HasPrefix(update.Reason, "reversal ")
+						})
+					}
+
+					mockLoanRepo.
+						On("UpdateLoan", mock.Anything, mock.Anything, reopened(repository.LoanReversed, []uint64{1, 2})).
+						Return(nil).
+						Once()
+
+					mockLoanRepo.
+						On("UpdateLoan", mock.Anything, mock.Anything, reopened(repository.LoanPending, []uint64{2})).
+						Return(nil).
+						Once()
+
+					mockLoanRepo.
+						On("UpdateLoan", mock.Anything, mock.Anything, reopened(repository.LoanOverdue, []uint64{1})).
+						Return(nil).
+						Once()
+
+					mockReversalRepo.
+						On(
+							"SaveReversal", mock.Anything, mock.Anything,
+							mock.MatchedBy(func(reversal *repository.PaymentReversalEntity) bool {
+								return reversal.PaymentID == "p-1" && reversal.RequestedBy == "ops-1"
+							})).
+						Return(nil).
+						Once()
+
+					//the mirror of the payment, the cash is credited back against the receivables
+					mockJournalRepo.
+						On(
+							"SaveEntries", mock.Anything, mock.Anything,
+							mock.MatchedBy(func(entry *repository.JournalEntryEntity) bool {
+								return entry.EntryType == "REVERSAL" && entry.IsBalanced() && len(entry.Lines) == 2 &&
+									entry.Lines[0].Account == "1101" && entry.Lines[0].Credit.Equal(decimal.NewFromFloat(25)) &&
+									entry.Lines[1].Account == "1301" && entry.Lines[1].Debit.Equal(decimal.NewFromFloat(25))
+							})).
+						Return(nil).
+						Once()
+
+					mockOutboxRepo.
+						On(
+							"SaveOutbox", mock.Anything, mock.Anything,
+							mock.MatchedBy(func(outbox *repository.OutboxEntity) bool {
+								return outbox.EventType == "PaymentReversed"
+							})).
+						Return(nil).
+						Once()
+
+					mockDelinquencyRepo.
+						On(
+							"UpsertDelinquency", mock.Anything, mock.Anything,
+							mock.MatchedBy(func(delinquency *repository.DelinquencyEntity) bool {
+								return delinquency.UserID == "abc" && delinquency.IsDelinquent
+							})).
+						Return(nil).
+						Once()
+
+					mockOutboxRepo.
+						On(
+							"SaveOutbox", mock.Anything, mock.Anything,
+							mock.MatchedBy(func(outbox *repository.OutboxEntity) bool {
+								return outbox.EventType == "CustomerBecameDelinquent" && outbox.AggregateID == "abc"
+							})).
+						Return(nil).
+						Once()
+				}
+
+				if tt.wantRefund != "" && tt.wantRefund != repository.RefundNotApplicable {
+					mockGateway.
+						On(
+							"Refund", mock.Anything, &gateway.RefundRequest{
+								ProviderReference: "fake-1",
+								Amount:            decimal.NewFromFloat(25),
+								Reason:            "duplicate debit",
+							}).
+						Return(&gateway.Refund{RefundReference: "fake-refund-1", Status: gateway.StatusRefunded}, tt.refundErr).
+						Once()
+
+					mockReversalRepo.
+						On(
+							"UpdateReversal", mock.Anything,
+							mock.MatchedBy(func(update *repository.PaymentReversalUpdate) bool {
+								return update.FromRefundStatus == repository.RefundPending &&
+									update.RefundStatus == tt.wantRefund
+							})).
+						Return(nil).
+						Once()
+				}
+
+				l := NewLoanService(
+					mockCfg, mockLoanRepo, mockOutboxRepo, mockDelinquencyRepo, mockPaymentRepo, mockReversalRepo, nil, nil,
+					nil, nil, mockJournalRepo, mockTransaction, mockGateway, nil, common.NewClock(), noHolidays)
+
+				got, err := l.ReversePayment(context.Background(), tt.request)
+				assert.Equal(t, tt.wantErr, err)
+
+				if tt.wantErr == nil {
+					assert.Equal(t, tt.wantRefund, got.RefundStatus)
+					assert.Equal(t, []uint64{1, 2}, got.InstallmentIDs)
+					mockLoanRepo.AssertExpectations(t)
+					mockGateway.AssertExpectations(t)
+					mockOutboxRepo.AssertExpectations(t)
+					mockDelinquencyRepo.AssertExpectations(t)
+				}
+
+				mockReversalRepo.AssertExpectations(t)
+				mockJournalRepo.AssertExpectations(t)
+			})
+	}
+}
+
+func Test_loanService_ReversePayment_Unapplied(t *testing.T) {
+	mockLoanRepo := &mocks2.LoanRepository{}
+	mockOutboxRepo := &mocks2.OutboxRepository{}
+	mockPaymentRepo := &mocks2.PaymentRepository{}
+	mockReversalRepo := &mocks2.PaymentReversalRepository{}
+	mockJournalRepo := &mocks2.JournalRepository{}
+	mockTransaction := &mocks2.Transaction{}
+	mockGateway := &mocks3.PaymentGateway{}
+	mockCfg := &mocks.Configuration{}
+
+	mockCfg.
+		On("GetString", mock.Anything).
+		Return("")
+
+	//none of the installments is paid by the unapplied payment, so none of them is looked up nor reopened
+	mockPaymentRepo.
+		On("FindPayments", mock.Anything, &repository.PaymentFilter{PaymentID: "p-1"}).
+		Return(
+			[]*repository.PaymentEntity{
+				{
+					PaymentID:         "p-1",
+					UserID:            "abc",
+					Amount:            decimal.NewFromFloat(25),
+					Channel:           repository.PaymentChannelDirectDebit,
+					InstallmentIDs:    []uint64{1, 2},
+					Status:            repository.PaymentUnapplied,
+					Provider:          gateway.ProviderFake,
+					ProviderReference: "fake-1",
+				},
+			}, nil)
+
+	mockGateway.On("Provider").Return(gateway.ProviderFake).Maybe()
+
+	mockTransaction.
+		On("WithTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(tx *sql.Tx) error) error {
+			return fn(nil)
+		})
+
+	mockPaymentRepo.
+		On(
+			"UpdatePayment", mock.Anything, mock.Anything, &repository.PaymentEntityUpdate{
+				PaymentID:  "p-1",
+				FromStatus: repository.PaymentUnapplied,
+				Status:     repository.PaymentReversed,
+			}).
+		Return(nil).
+		Once()
+
+	mockReversalRepo.
+		On(
+			"SaveReversal", mock.Anything, mock.Anything,
+			mock.MatchedBy(func(reversal *repository.PaymentReversalEntity) bool {
+				return reversal.PaymentID == "p-1" && len(reversal.InstallmentIDs) == 0
+			})).
+		Return(nil).
+		Once()
+
+	//the money held in suspense is paid back
+	mockJournalRepo.
+		On(
+			"SaveEntries", mock.Anything, mock.Anything,
+			mock.MatchedBy(func(entry *repository.JournalEntryEntity) bool {
+				return entry.EntryType == "REVERSAL" && entry.IsBalanced() && len(entry.Lines) == 2 &&
+					entry.Lines[0].Account == "1101" && entry.Lines[0].Credit.Equal(decimal.NewFromFloat(25)) &&
+					entry.Lines[1].Account == "2901" && entry.Lines[1].Debit.Equal(decimal.NewFromFloat(25))
+			})).
+		Return(nil).
+		Once()
+
+	mockOutboxRepo.
+		On(
+			"SaveOutbox", mock.Anything, mock.Anything,
+			mock.MatchedBy(func(outbox *repository.OutboxEntity) bool {
+				return outbox.EventType == "PaymentReversed"
+			})).
+		Return(nil).
+		Once()
+
+	mockGateway.
+		On(
+			"Refund", mock.Anything, &gateway.RefundRequest{
+				ProviderReference: "fake-1",
+				Amount:            decimal.NewFromFloat(25),
+				Reason:            "no longer collectible",
+			}).
+		Return(&gateway.Refund{RefundReference: "fake-refund-1", Status: gateway.StatusRefunded}, nil).
+		Once()
+
+	mockReversalRepo.
+		On(
+			"UpdateReversal", mock.Anything,
+			mock.MatchedBy(func(update *repository.PaymentReversalUpdate) bool {
+				return update.RefundStatus == repository.RefundSucceeded
+			})).
+		Return(nil).
+		Once()
+
+	l := NewLoanService(
+		mockCfg, mockLoanRepo, mockOutboxRepo, nil, mockPaymentRepo, mockReversalRepo, nil, nil,
+		nil, nil, mockJournalRepo, mockTransaction, mockGateway, nil, common.NewClock(), noHolidays)
+
+	got, err := l.ReversePayment(
+		context.Background(),
+		&ReversalRequest{PaymentID: "p-1", Reason: "no longer collectible", RequestedBy: "ops-1"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, repository.RefundSucceeded, got.RefundStatus)
+	assert.Empty(t, got.InstallmentIDs)
+	mockLoanRepo.AssertNotCalled(t, "UpdateLoan", mock.Anything, mock.Anything, mock.Anything)
+	mockPaymentRepo.AssertExpectations(t)
+	mockReversalRepo.AssertExpectations(t)
+	mockJournalRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	mockGateway.AssertExpectations(t)
+}
+
+func Test_loanService_Restructure(t *testing.T) {
+	now := time.Now()
+	breakdown := func(principal, interest float64) repository.Breakdown {
+		return repository.Breakdown{Principal: decimal.NewFromFloat(principal), Interest: decimal.NewFromFloat(interest)}
+	}
+
+	unpaid := []*repository.LoanEntity{
+		{ID: 6, Status: repository.LoanOverdue, UserID: "abc", DueDate: now.AddDate(0, 0, -7), Amount: decimal.NewFromFloat(40), Breakdown: breakdown(30, 10)},
+		{ID: 7, Status: repository.LoanPending, UserID: "abc", DueDate: now.AddDate(0, 0, 7), Amount: decimal.NewFromFloat(30), Breakdown: breakdown(25, 5)},
+		{ID: 8, Status: repository.LoanPending, UserID: "abc", DueDate: now.AddDate(0, 0, 14), Amount: decimal.NewFromFloat(30), Breakdown: breakdown(25, 5)},
+	}
+
+	request := func() *RestructureRequest {
+		return &RestructureRequest{
+			UserID:          "abc",
+			Tenor:           3,
+			Frequency:       repository.RestructureMonthly,
+			GracePeriodDays: 30,
+			Reason:          "hardship",
+			RequestedBy:     "ops-1",
+		}
+	}
+
+	tests := []struct {
+		name       string
+		request    *RestructureRequest
+		unpaid     []*repository.LoanEntity
+		inProgress []*repository.PaymentEntity
+		updateErr  error
+		wantErr    error
+	}{
+		{
+			name: "given the tenor is empty," +
+				"when restructure," +
+				"then return error validation",
+			request: &RestructureRequest{UserID: "abc", Reason: "hardship", RequestedBy: "ops-1"},
+			wantErr: errorValidation,
+		},
+		{
+			name: "given the frequency is unknown," +
+				"when restructure," +
+				"then return error validation",
+			request: &RestructureRequest{
+				UserID: "abc", Tenor: 3, Frequency: "DAILY", Reason: "hardship", RequestedBy: "ops-1",
+			},
+			wantErr: errorValidation,
+		},
+		{
+			name: "given no unpaid installments," +
+				"when restructure," +
+				"then return error",
+			request: request(),
+			wantErr: errorNoPendingOutstanding,
+		},
+		{
+			name: "given previous payment is still pending debit," +
+				"when restructure," +
+				"then return error",
+			request:    request(),
+			unpaid:     unpaid,
+			inProgress: []*repository.PaymentEntity{{PaymentID: "p-1", Status: repository.PaymentPendingDebit}},
+			wantErr:    errorPaymentInProgress,
+		},
+		{
+			name: "given the installment is paid meanwhile," +
+				"when restructure," +
+				"then return error installments changed",
+			request:   request(),
+			unpaid:    unpaid,
+			updateErr: repository.ErrorIllegalTransition,
+			wantErr:   errorInstallmentsChanged,
+		},
+		{
+			name: "given the unpaid installments," +
+				"when restructure," +
+				"then they are restructured into the new schedule linked to the restructuring",
+			request: request(),
+			unpaid:  unpaid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockLoanRepo := &mocks2.LoanRepository{}
+				mockOutboxRepo := &mocks2.OutboxRepository{}
+				mockDelinquencyRepo := &mocks2.DelinquencyRepository{}
+				mockPaymentRepo := &mocks2.PaymentRepository{}
+				mockRestructureRepo := &mocks2.LoanRestructureRepository{}
+				mockJournalRepo := &mocks2.JournalRepository{}
+				mockTransaction := &mocks2.Transaction{}
+				mockCfg := &mocks.Configuration{}
+
+				mockCfg.
+					On("GetString", mock.Anything).
+					Return("")
+
+				mockLoanRepo.
+					On(
+						"FindLoans", mock.Anything, &repository.LoanEntity{
+							Statuses: repository.LoanUnpaid,
+							UserID:   "abc",
+						}).
+					Return(tt.unpaid, nil)
+
+				mockPaymentRepo.
+					On("FindPayments", mock.Anything, mock.Anything).
+					Return(tt.inProgress, nil)
+
+				//only the overdue installment is accrued
+				mockJournalRepo.
+					On(
+						"FindEntries", mock.Anything, &repository.JournalEntryFilter{
+							EntryType:  "ACCRUAL",
+							References: []string{"6", "7", "8"},
+						}).
+					Return([]*repository.JournalEntryEntity{{EntryType: "ACCRUAL", Reference: "6"}}, nil)
+
+				//the delinquent customer is no longer delinquent once the overdue installment is restructured
+				mockLoanRepo.
+					On(
+						"FindLoans", mock.Anything,
+						mock.MatchedBy(func(filter *repository.LoanEntity) bool {
+							return reflect.DeepEqual(filter.Statuses, delinquency.DueStatuses) && filter.UserID == "abc"
+						})).
+					Return(unpaid[:1], nil)
+
+				mockDelinquencyRepo.
+					On("FindDelinquency", mock.Anything, "abc").
+					Return(&repository.DelinquencyEntity{UserID: "abc", IsDelinquent: true}, nil)
+
+				mockTransaction.
+					On("WithTransaction", mock.Anything, mock.Anything).
+					Return(func(ctx context.Context, fn func(tx *sql.Tx) error) error {
+						return fn(nil)
+					})
+
+				mockLoanRepo.
+					On(
+						"UpdateLoan", mock.Anything, mock.Anything,
+						mock.MatchedBy(func(update *repository.LoanEntityUpdate) bool {
+							return update.Status == repository.LoanRestructured &&
+								reflect.DeepEqual(update.IDs, []uint64{6, 7, 8}) &&
+								strings.HasPrefix(update.Reason, "restructure ")
+						})).
+					Return(tt.updateErr)
+
+				if tt.wantErr == nil {
+					mockLoanRepo.
+						On(
+							"SaveLoans", mock.Anything, mock.Anything,
+							mock.MatchedBy(func(loan *repository.LoanEntity) bool {
+								return loan.Status == repository.LoanPending && loan.RestructureID != ""
+							}),
+							mock.Anything, mock.Anything).
+						Return(nil).
+						Once()
+
+					mockRestructureRepo.
+						On(
+							"SaveRestructure", mock.Anything, mock.Anything,
+							mock.MatchedBy(func(restructure *repository.LoanRestructureEntity) bool {
+								return restructure.Outstanding.Equal(decimal.NewFromFloat(100)) &&
+									restructure.RequestedBy == "ops-1"
+							})).
+						Return(nil).
+						Once()
+
+					//the income accrued from the overdue installment is accrued again by the new schedule
+					mockJournalRepo.
+						On(
+							"SaveEntries", mock.Anything, mock.Anything,
+							mock.MatchedBy(func(entry *repository.JournalEntryEntity) bool {
+								return entry.EntryType == "RESTRUCTURE" && entry.IsBalanced() && len(entry.Lines) == 2 &&
+									entry.Lines[0].Account == "4101" && entry.Lines[0].Debit.Equal(decimal.NewFromFloat(10)) &&
+									entry.Lines[1].Account == "2301" && entry.Lines[1].Credit.Equal(decimal.NewFromFloat(10))
+							})).
+						Return(nil).
+						Once()
+
+					mockOutboxRepo.
+						On(
+							"SaveOutbox", mock.Anything, mock.Anything,
+							mock.MatchedBy(func(outbox *repository.OutboxEntity) bool {
+								return outbox.EventType == "LoanRestructured"
+							})).
+						Return(nil).
+						Once()
+
+					mockDelinquencyRepo.
+						On(
+							"UpsertDelinquency", mock.Anything, mock.Anything,
+							mock.MatchedBy(func(delinquency *repository.DelinquencyEntity) bool {
+								return delinquency.UserID == "abc" && !delinquency.IsDelinquent
+							})).
+						Return(nil).
+						Once()
+
+					mockOutboxRepo.
+						On(
+							"SaveOutbox", mock.Anything, mock.Anything,
+							mock.MatchedBy(func(outbox *repository.OutboxEntity) bool {
+								return outbox.EventType == "CustomerDelinquencyCleared"
+							})).
+						Return(nil).
+						Once()
+				}
+
+				l := NewLoanService(
+					mockCfg, mockLoanRepo, mockOutboxRepo, mockDelinquencyRepo, mockPaymentRepo, nil, nil, mockRestructureRepo,
+					nil, nil, mockJournalRepo, mockTransaction, nil, nil, common.NewClock(), noHolidays)
+
+				got, err := l.Restructure(context.Background(), tt.request)
+				assert.Equal(t, tt.wantErr, err)
+
+				if tt.wantErr == nil {
+					assert.Equal(t, []uint64{6, 7, 8}, got.InstallmentIDs)
+					assert.Len(t, got.Schedule, 3)
+					mockLoanRepo.AssertExpectations(t)
+					mockOutboxRepo.AssertExpectations(t)
+					mockJournalRepo.AssertExpectations(t)
+					mockDelinquencyRepo.AssertExpectations(t)
+				}
+
+				mockRestructureRepo.AssertExpectations(t)
+			})
+	}
+}
+
+func Test_loanService_WriteOff(t *testing.T) {
+	now := time.Now()
+	breakdown := func(principal, interest float64) repository.Breakdown {
+		return repository.Breakdown{Principal: decimal.NewFromFloat(principal), Interest: decimal.NewFromFloat(interest)}
+	}
+
+	unpaid := []*repository.LoanEntity{
+		{ID: 6, Status: repository.LoanOverdue, UserID: "abc", DueDate: now.AddDate(0, 0, -91), Amount: decimal.NewFromFloat(40), Breakdown: breakdown(30, 10)},
+		{ID: 7, Status: repository.LoanOverdue, UserID: "abc", DueDate: now.AddDate(0, 0, -84), Amount: decimal.NewFromFloat(30), Breakdown: breakdown(25, 5)},
+		{ID: 8, Status: repository.LoanPending, UserID: "abc", DueDate: now.AddDate(0, 0, 7), Amount: decimal.NewFromFloat(30), Breakdown: breakdown(25, 5)},
+	}
+
+	//the grace days of the product defer the days past due of the oldest installment below the threshold
+	graced := []*repository.LoanEntity{
+		{ID: 6, Status: repository.LoanOverdue, UserID: "abc", ProductID: 3, DueDate: now.AddDate(0, 0, -91), Amount: decimal.NewFromFloat(40), Breakdown: breakdown(30, 10)},
+	}
+
+	request := func() *WriteOffRequest {
+		return &WriteOffRequest{UserID: "abc", Reason: "uncollectible", ApprovedBy: "risk-1"}
+	}
+
+	tests := []struct {
+		name       string
+		request    *WriteOffRequest
+		unpaid     []*repository.LoanEntity
+		inProgress []*repository.PaymentEntity
+		updateErr  error
+		wantErr    error
+	}{
+		{
+			name: "given the approver is empty," +
+				"when writeOff," +
+				"then return error validation",
+			request: &WriteOffRequest{UserID: "abc", Reason: "uncollectible"},
+			wantErr: errorValidation,
+		},
+		{
+			name: "given no unpaid installments," +
+				"when writeOff," +
+				"then return error",
+			request: request(),
+			wantErr: errorNoPendingOutstanding,
+		},
+		{
+			name: "given the oldest installment is not past due long enough," +
+				"when writeOff," +
+				"then return error not eligible",
+			request: request(),
+			unpaid:  unpaid[1:],
+			wantErr: errorNotWriteOffEligible,
+		},
+		{
+			name: "given the grace days of the product," +
+				"when writeOff," +
+				"then the days past due count after the grace days and return error not eligible",
+			request: request(),
+			unpaid:  graced,
+			wantErr: errorNotWriteOffEligible,
+		},
+		{
+			name: "given previous payment is still pending debit," +
+				"when writeOff," +
+				"then return error",
+			request:    request(),
+			unpaid:     unpaid,
+			inProgress: []*repository.PaymentEntity{{PaymentID: "p-1", Status: repository.PaymentPendingDebit}},
+			wantErr:    errorPaymentInProgress,
+		},
+		{
+			name: "given the installment is paid meanwhile," +
+				"when writeOff," +
+				"then return error installments changed",
+			request:   request(),
+			unpaid:    unpaid,
+			updateErr: repository.ErrorNoRows,
+			wantErr:   errorInstallmentsChanged,
+		},
+		{
+			name: "given the loan past due beyond the threshold," +
+				"when writeOff," +
+				"then all the unpaid installments are written off",
+			request: request(),
+			unpaid:  unpaid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockCfg := &mocks.Configuration{}
+				mockLoanRepo := &mocks2.LoanRepository{}
+				mockOutboxRepo := &mocks2.OutboxRepository{}
+				mockDelinquencyRepo := &mocks2.DelinquencyRepository{}
+				mockPaymentRepo := &mocks2.PaymentRepository{}
+				mockWriteOffRepo := &mocks2.LoanWriteOffRepository{}
+				mockJournalRepo := &mocks2.JournalRepository{}
+				mockProductRepo := &mocks2.ProductRepository{}
+				mockTransaction := &mocks2.Transaction{}
+
+				mockCfg.
+					On("GetInt", "writeoff.dpd.threshold").
+					Return(int64(0))
+
+				mockCfg.
+					On("GetString", mock.Anything).
+					Return("")
+
+				mockLoanRepo.
+					On(
+						"FindLoans", mock.Anything, &repository.LoanEntity{
+							Statuses: repository.LoanUnpaid,
+							UserID:   "abc",
+						}).
+					Return(tt.unpaid, nil)
+
+				mockProductRepo.
+					On("FindProducts", mock.Anything, &repository.ProductFilter{IDs: []uint64{3}}).
+					Return([]*repository.ProductEntity{{ID: 3, GraceDays: 2}}, nil)
+
+				mockPaymentRepo.
+					On("FindPayments", mock.Anything, mock.Anything).
+					Return(tt.inProgress, nil)
+
+				mockJournalRepo.
+					On("FindEntries", mock.Anything, mock.Anything).
+					Return([]*repository.JournalEntryEntity{
+						{EntryType: "ACCRUAL", Reference: "6"},
+						{EntryType: "ACCRUAL", Reference: "7"},
+					}, nil)
+
+				//the 2 missed installments are tolerated until they are written off
+				mockLoanRepo.
+					On(
+						"FindLoans", mock.Anything,
+						mock.MatchedBy(func(filter *repository.LoanEntity) bool {
+							return reflect.DeepEqual(filter.Statuses, delinquency.DueStatuses) && filter.UserID == "abc"
+						})).
+					Return(unpaid[:2], nil)
+
+				mockDelinquencyRepo.
+					On("FindDelinquency", mock.Anything, "abc").
+					Return(nil, repository.ErrorNoRows)
+
+				mockTransaction.
+					On("WithTransaction", mock.Anything, mock.Anything).
+					Return(func(ctx context.Context, fn func(tx *sql.Tx) error) error {
+						return fn(nil)
+					})
+
+				mockLoanRepo.
+					On(
+						"UpdateLoan", mock.Anything, mock.Anything,
+						mock.MatchedBy(func(update *repository.LoanEntityUpdate) bool {
+							return update.Status == repository.LoanWrittenOff &&
+								reflect.DeepEqual(update.IDs, []uint64{6, 7, 8}) &&
+								strings.HasPrefix(update.Reason, "write-off ")
+						})).
+					Return(tt.updateErr)
+
+				if tt.wantErr == nil {
+					mockWriteOffRepo.
+						On(
+							"SaveWriteOff", mock.Anything, mock.Anything,
+							mock.MatchedBy(func(writeOff *repository.LoanWriteOffEntity) bool {
+								return writeOff.Amount.Equal(decimal.NewFromFloat(100)) &&
+									writeOff.DaysPastDue == 91 &&
+									writeOff.ApprovedBy == "risk-1"
+							})).
+						Return(nil).
+						Once()
+
+					//the accrued installments are expensed as a whole,
+					//the interest of the installment not yet due is still unearned
+					mockJournalRepo.
+						On(
+							"SaveEntries", mock.Anything, mock.Anything,
+							mock.MatchedBy(func(entry *repository.JournalEntryEntity) bool {
+								//booked at the business date, not when it is created
+								return entry.EntryType == "WRITE_OFF" && entry.IsBalanced() && len(entry.Lines) == 4 &&
+									entry.BookedAt.Equal(now) &&
+									entry.Lines[0].Account == "5102" && entry.Lines[0].Debit.Equal(decimal.NewFromFloat(95)) &&
+									entry.Lines[3].Account == "2301" && entry.Lines[3].Debit.Equal(decimal.NewFromFloat(5))
+							})).
+						Return(nil).
+						Once()
+
+					mockOutboxRepo.
+						On(
+							"SaveOutbox", mock.Anything, mock.Anything,
+							mock.MatchedBy(func(outbox *repository.OutboxEntity) bool {
+								return outbox.EventType == "LoanWrittenOff"
+							})).
+						Return(nil).
+						Once()
+
+					mockDelinquencyRepo.
+						On(
+							"UpsertDelinquency", mock.Anything, mock.Anything,
+							mock.MatchedBy(func(delinquency *repository.DelinquencyEntity) bool {
+								return delinquency.UserID == "abc" && delinquency.IsDelinquent
+							})).
+						Return(nil).
+						Once()
+
+					mockOutboxRepo.
+						On(
+							"SaveOutbox", mock.Anything, mock.Anything,
+							mock.MatchedBy(func(outbox *repository.OutboxEntity) bool {
+								return outbox.EventType == "CustomerBecameDelinquent"
+							})).
+						Return(nil).
+						Once()
+				}
+
+				l := NewLoanService(
+					mockCfg, mockLoanRepo, mockOutboxRepo, mockDelinquencyRepo, mockPaymentRepo, nil, nil, nil,
+					mockWriteOffRepo, mockProductRepo, mockJournalRepo, mockTransaction, nil, nil,
+					common.NewFixedClock(now), noHolidays)
+
+				got, err := l.WriteOff(context.Background(), tt.request)
+				assert.Equal(t, tt.wantErr, err)
+
+				if tt.wantErr == nil {
+					assert.Equal(t, []uint64{6, 7, 8}, got.InstallmentIDs)
+					assert.Equal(t, 91, got.DaysPastDue)
+					mockLoanRepo.AssertExpectations(t)
+					mockOutboxRepo.AssertExpectations(t)
+					mockJournalRepo.AssertExpectations(t)
+					mockDelinquencyRepo.AssertExpectations(t)
+				}
+
+				mockWriteOffRepo.AssertExpectations(t)
+			})
+	}
+}
+
+func Test_buildSchedule(t *testing.T) {
+	now := time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		restructure *repository.LoanRestructureEntity
+		breakdown   repository.Breakdown
+		wantDates   []time.Time
+		wantAmounts []string
+		wantFees    []string
+	}{
+		{
+			name: "given weekly without grace period," +
+				"when buildSchedule," +
+				"then the remainder goes to the last installment",
+			restructure: &repository.LoanRestructureEntity{
+				Outstanding: decimal.NewFromFloat(100),
+				Tenor:       3,
+				Frequency:   repository.RestructureWeekly,
+			},
+			breakdown: repository.Breakdown{Principal: decimal.NewFromFloat(100)},
+			wantDates: []time.Time{
+				time.Date(2026, 10, 26, 0, 0, 0, 0, time.UTC),
+				time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC),
+				time.Date(2026, 11, 9, 0, 0, 0, 0, time.UTC),
+			},
+			wantAmounts: []string{"33.33", "33.33", "33.34"},
+			wantFees:    []string{"0", "0", "0"},
+		},
+		{
+			name: "given monthly with grace period," +
+				"when buildSchedule," +
+				"then the first period starts after the grace period",
+			restructure: &repository.LoanRestructureEntity{
+				Outstanding:     decimal.NewFromFloat(90),
+				Tenor:           2,
+				Frequency:       repository.RestructureMonthly,
+				GracePeriodDays: 12,
+			},
+			breakdown: repository.Breakdown{Principal: decimal.NewFromFloat(80), Fee: decimal.NewFromFloat(10)},
+			wantDates: []time.Time{
+				time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC),
+				time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC),
+			},
+			wantAmounts: []string{"45", "45"},
+			wantFees:    []string{"5", "5"},
+		},
+		{
+			name: "given the fee is not divisible by the tenor," +
+				"when buildSchedule," +
+				"then the remainder of each component goes to the last installment",
+			restructure: &repository.LoanRestructureEntity{
+				Outstanding: decimal.NewFromFloat(100),
+				Tenor:       3,
+				Frequency:   repository.RestructureBiweekly,
+			},
+			breakdown: repository.Breakdown{Principal: decimal.NewFromFloat(90), Fee: decimal.NewFromFloat(10)},
+			wantDates: []time.Time{
+				time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC),
+				time.Date(2026, 11, 16, 0, 0, 0, 0, time.UTC),
+				time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC),
+			},
+			wantAmounts: []string{"33.33", "33.33", "33.34"},
+			wantFees:    []string{"3.33", "3.33", "3.34"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				schedule := buildSchedule(tt.restructure, tt.breakdown, now, noHolidays)
+
+				var dates []time.Time
+				var amounts, fees []string
+				for _, installment := range schedule {
+					dates = append(dates, installment.DueDate)
+					amounts = append(amounts, installment.Amount.String())
+					fees = append(fees, installment.Breakdown.Fee.String())
+				}
+
+				assert.Equal(t, tt.wantDates, dates)
+				assert.Equal(t, tt.wantAmounts, amounts)
+				assert.Equal(t, tt.wantFees, fees)
+			})
+	}
+}
+
+func Test_allocate(t *testing.T) {
+	now := time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC)
+	loans := []*repository.LoanEntity{
+		{
+			ID:      2,
+			DueDate: now.AddDate(0, 0, 7),
+			Amount:  decimal.NewFromFloat(33),
+			Breakdown: repository.Breakdown{
+				Principal: decimal.NewFromFloat(20),
+				Interest:  decimal.NewFromFloat(10),
+				Fee:       decimal.NewFromFloat(3),
+			},
+		},
+		{
+			ID:      1,
+			DueDate: now,
+			Amount:  decimal.NewFromFloat(35),
+			Breakdown: repository.Breakdown{
+				Principal: decimal.NewFromFloat(20),
+				Interest:  decimal.NewFromFloat(10),
+				Fee:       decimal.NewFromFloat(3),
+				Penalty:   decimal.NewFromFloat(2),
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		amount decimal.Decimal
+		order  []repository.LoanComponent
+		want   []string
+	}{
+		{
+			name: "given the exact amount," +
+				"when allocate," +
+				"then all the components are allocated",
+			amount: decimal.NewFromFloat(68),
+			order:  repository.LoanComponents,
+			want:   []string{"40", "20", "6", "2"},
+		},
+		{
+			name: "given the amount less than the installments," +
+				"when allocate," +
+				"then the oldest installment is allocated first in the order",
+			amount: decimal.NewFromFloat(40),
+			order:  repository.LoanComponents,
+			want:   []string{"20", "12", "6", "2"},
+		},
+		{
+			name: "given the principal first order," +
+				"when allocate," +
+				"then the principal of the oldest installment is allocated first",
+			amount: decimal.NewFromFloat(25),
+			order: []repository.LoanComponent{
+				repository.ComponentPrincipal, repository.ComponentInterest,
+				repository.ComponentFee, repository.ComponentPenalty,
+			},
+			want: []string{"20", "5", "0", "0"},
+		},
+		{
+			name: "given the payoff amount discounted on the fee," +
+				"when allocate," +
+				"then the discount is left on the fee",
+			amount: decimal.NewFromFloat(65),
+			order:  moveLast(repository.LoanComponents, repository.ComponentFee),
+			want:   []string{"40", "20", "3", "2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got := allocate(tt.amount, loans, tt.order)
+
+				assert.Equal(
+					t, tt.want,
+					[]string{got.Principal.String(), got.Interest.String(), got.Fee.String(), got.Penalty.String()})
+				assert.Equal(t, uint64(2), loans[0].ID)
+			})
+	}
+}
+
+func Test_loanService_allocationOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		want       []repository.LoanComponent
+	}{
+		{
+			name: "given nothing configured," +
+				"when allocationOrder," +
+				"then return the default order",
+			want: repository.LoanComponents,
+		},
+		{
+			name: "given partial order with unknown component," +
+				"when allocationOrder," +
+				"then the unknown is ignored and the rest follow the default order",
+			configured: "Principal, tax,interest",
+			want: []repository.LoanComponent{
+				repository.ComponentPrincipal, repository.ComponentInterest,
+				repository.ComponentPenalty, repository.ComponentFee,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockCfg := &mocks.Configuration{}
+				mockCfg.
+					On("GetString", "payment.allocation.order").
+					Return(tt.configured)
+
+				l := &loanService{cfg: mockCfg}
+				assert.Equal(t, tt.want, l.allocationOrder())
 			})
 	}
 }
@@ -0,0 +1,64 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/publisher"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+const (
+	defaultBatchSize   = 100
+	defaultMaxAttempts = 10
+	defaultInterval    = 5 * time.Second
+)
+
+type (
+	relayService struct {
+		outboxRepository repository.OutboxRepository
+		publisher        publisher.Publisher
+		generate         common.Generate
+		batchSize        int
+		maxAttempts      int
+		interval         time.Duration
+	}
+
+	// RelayService publishes the pending outbox rows through the publisher (at least once).
+	RelayService interface {
+		RelayOnce(ctx context.Context) (int, error)
+
+		Run(ctx context.Context)
+	}
+)
+
+func NewRelayService(
+	cfg configuration.Configuration,
+	outboxRepository repository.OutboxRepository,
+	publisher publisher.Publisher) RelayService {
+	batchSize := int(cfg.GetInt("outbox.relay.batch"))
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	maxAttempts := int(cfg.GetInt("outbox.relay.max.attempts"))
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	interval := time.Duration(cfg.GetInt("outbox.relay.interval.seconds")) * time.Second
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	return &relayService{
+		outboxRepository: outboxRepository,
+		publisher:        publisher,
+		generate:         common.NewGenerate(),
+		batchSize:        batchSize,
+		maxAttempts:      maxAttempts,
+		interval:         interval,
+	}
+}
@@ -0,0 +1,112 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/publisher"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+const (
+	maxLastError = 255
+)
+
+var (
+	errorFromDatabase = errors.New("from database")
+)
+
+func (r *relayService) RelayOnce(ctx context.Context) (int, error) {
+	outboxes, err := r.outboxRepository.FindOutbox(
+		ctx, &repository.OutboxEntityFilter{
+			Status:      repository.OutboxStatusPending,
+			MaxAttempts: r.maxAttempts,
+			Limit:       r.batchSize,
+		},
+	)
+
+	if err != nil {
+		return 0, errorFromDatabase
+	}
+
+	published := 0
+	for _, outbox := range outboxes {
+		errPublish := r.publisher.Publish(
+			ctx, &publisher.Message{
+				ID:         outbox.EventID,
+				Type:       outbox.EventType,
+				Key:        outbox.AggregateID,
+				Payload:    outbox.Payload,
+				OccurredAt: outbox.OccurredAt,
+			},
+		)
+
+		update := r.toUpdate(outbox, errPublish)
+		if errUpdate := r.outboxRepository.UpdateOutbox(ctx, update); errUpdate != nil {
+			//the event would be published again on the next run, the consumer should be idempotent
+			log.Println("failed update outbox -> ", outbox.EventID, errUpdate)
+			return published, errorFromDatabase
+		}
+
+		if errPublish == nil {
+			published += 1
+		}
+	}
+
+	return published, nil
+}
+
+func (r *relayService) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		published, err := r.RelayOnce(ctx)
+		if err != nil {
+			log.Println("[Outbox Relay] failed relay outbox -> ", err)
+		}
+
+		if published > 0 {
+			log.Println("[Outbox Relay] published events -> ", published)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *relayService) toUpdate(
+	outbox *repository.OutboxEntity,
+	errPublish error) *repository.OutboxEntityUpdate {
+	if errPublish == nil {
+		publishedAt := r.generate.Time()
+
+		return &repository.OutboxEntityUpdate{
+			ID:          outbox.ID,
+			Status:      repository.OutboxStatusPublished,
+			PublishedAt: &publishedAt,
+		}
+	}
+
+	lastError := errPublish.Error()
+	if len(lastError) > maxLastError {
+		lastError = lastError[:maxLastError]
+	}
+
+	//give up after max attempts, so it does not block the batch forever
+	status := repository.OutboxStatusPending
+	if outbox.Attempts+1 >= r.maxAttempts {
+		status = repository.OutboxStatusFailed
+	}
+
+	return &repository.OutboxEntityUpdate{
+		ID:        outbox.ID,
+		Status:    status,
+		LastError: lastError,
+	}
+}
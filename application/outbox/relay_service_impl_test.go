@@ -0,0 +1,194 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	mocksCommon "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/common"
+	mocksPublisher "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/infrastructure/publisher"
+	mocksRepository "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/infrastructure/repository"
+)
+
+func Test_relayService_RelayOnce(t *testing.T) {
+	now := time.Date(2024, 6, 23, 21, 0, 0, 0, time.UTC)
+
+	outboxes := []*repository.OutboxEntity{
+		{
+			ID:          1,
+			EventID:     "event-1",
+			EventType:   "PaymentSucceeded",
+			AggregateID: "abc",
+			Payload:     []byte(`{}`),
+			Attempts:    0,
+		},
+		{
+			ID:          2,
+			EventID:     "event-2",
+			EventType:   "LoanClosed",
+			AggregateID: "abc",
+			Payload:     []byte(`{}`),
+			Attempts:    2,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		want     int
+		wantErr  error
+		mockFunc func(
+			mockOutboxRepo *mocksRepository.OutboxRepository,
+			mockPublisher *mocksPublisher.Publisher)
+	}{
+		{
+			name: "given find outbox is failed," +
+				"when relayOnce," +
+				"then return error",
+			want:    0,
+			wantErr: errorFromDatabase,
+			mockFunc: func(
+				mockOutboxRepo *mocksRepository.OutboxRepository,
+				mockPublisher *mocksPublisher.Publisher) {
+				mockOutboxRepo.
+					On("FindOutbox", mock.Anything, mock.Anything).
+					Return(nil, errors.New("mock error")).
+					Once()
+			},
+		},
+		{
+			name: "given all events published," +
+				"when relayOnce," +
+				"then mark as published",
+			want: 2,
+			mockFunc: func(
+				mockOutboxRepo *mocksRepository.OutboxRepository,
+				mockPublisher *mocksPublisher.Publisher) {
+				mockOutboxRepo.
+					On("FindOutbox", mock.Anything, mock.Anything).
+					Return(outboxes, nil).
+					Once()
+
+				mockPublisher.
+					On("Publish", mock.Anything, mock.Anything).
+					Return(nil).
+					Twice()
+
+				mockOutboxRepo.
+					On(
+						"UpdateOutbox", mock.Anything, &repository.OutboxEntityUpdate{
+							ID:          1,
+							Status:      repository.OutboxStatusPublished,
+							PublishedAt: &now,
+						}).
+					Return(nil).
+					Once()
+
+				mockOutboxRepo.
+					On(
+						"UpdateOutbox", mock.Anything, &repository.OutboxEntityUpdate{
+							ID:          2,
+							Status:      repository.OutboxStatusPublished,
+							PublishedAt: &now,
+						}).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given publish is failed and max attempts reached," +
+				"when relayOnce," +
+				"then mark as pending and failed",
+			want: 0,
+			mockFunc: func(
+				mockOutboxRepo *mocksRepository.OutboxRepository,
+				mockPublisher *mocksPublisher.Publisher) {
+				mockOutboxRepo.
+					On("FindOutbox", mock.Anything, mock.Anything).
+					Return(outboxes, nil).
+					Once()
+
+				mockPublisher.
+					On("Publish", mock.Anything, mock.Anything).
+					Return(errors.New("broker down")).
+					Twice()
+
+				mockOutboxRepo.
+					On(
+						"UpdateOutbox", mock.Anything, &repository.OutboxEntityUpdate{
+							ID:        1,
+							Status:    repository.OutboxStatusPending,
+							LastError: "broker down",
+						}).
+					Return(nil).
+					Once()
+
+				mockOutboxRepo.
+					On(
+						"UpdateOutbox", mock.Anything, &repository.OutboxEntityUpdate{
+							ID:        2,
+							Status:    repository.OutboxStatusFailed,
+							LastError: "broker down",
+						}).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given update outbox is failed," +
+				"when relayOnce," +
+				"then return error",
+			want:    0,
+			wantErr: errorFromDatabase,
+			mockFunc: func(
+				mockOutboxRepo *mocksRepository.OutboxRepository,
+				mockPublisher *mocksPublisher.Publisher) {
+				mockOutboxRepo.
+					On("FindOutbox", mock.Anything, mock.Anything).
+					Return(outboxes, nil).
+					Once()
+
+				mockPublisher.
+					On("Publish", mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+
+				mockOutboxRepo.
+					On("UpdateOutbox", mock.Anything, mock.Anything).
+					Return(errors.New("mock error")).
+					Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockOutboxRepo := &mocksRepository.OutboxRepository{}
+				mockPublisher := &mocksPublisher.Publisher{}
+				mockGenerate := &mocksCommon.Generate{}
+				mockGenerate.On("Time").Return(now)
+
+				tt.mockFunc(mockOutboxRepo, mockPublisher)
+
+				r := &relayService{
+					outboxRepository: mockOutboxRepo,
+					publisher:        mockPublisher,
+					generate:         mockGenerate,
+					batchSize:        defaultBatchSize,
+					maxAttempts:      3,
+					interval:         defaultInterval,
+				}
+
+				got, err := r.RelayOnce(context.Background())
+
+				assert.Equal(t, tt.want, got)
+				assert.Equal(t, tt.wantErr, err)
+				mockOutboxRepo.AssertExpectations(t)
+			})
+	}
+}
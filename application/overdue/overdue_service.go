@@ -0,0 +1,92 @@
+package overdue
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/delinquency"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/journal"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/calendar"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+const (
+	defaultChunkSize    = 500
+	defaultMaxConflicts = 3
+	defaultInterval     = 24 * time.Hour
+)
+
+type (
+	overdueService struct {
+		loanRepository    repository.LoanRepository
+		outboxRepository  repository.OutboxRepository
+		productRepository repository.ProductRepository
+		journalRepository repository.JournalRepository
+		transaction       repository.Transaction
+		generate          common.Generate
+		clock             common.Clock
+		calendar          calendar.Calendar
+		chart             *journal.Chart
+		assessor          *delinquency.Assessor
+		tracker           *delinquency.Tracker
+		chunkSize         int
+		maxConflicts      int
+		interval          time.Duration
+	}
+
+	// Service persists the overdue state of the installments, so the reports and the collections
+	// don't need to recalculate it from the due date.
+	Service interface {
+		// MarkOverdue moves the PENDING installments whose due date (and the grace period of the product)
+		// has passed to OVERDUE in chunks, together with the InstallmentOverdue events, the accrual of their income
+		// and the delinquency of their customers when it changes.
+		// It returns the number of marked installments.
+		MarkOverdue(ctx context.Context) (int, error)
+
+		// MarkOverdueAsOf is MarkOverdue as of the time, e.g. the end of the business date closed by the end of day.
+		// The installments due before the date of asOf are marked, their events and accruals occur at asOf.
+		MarkOverdueAsOf(ctx context.Context, asOf time.Time) (int, error)
+
+		Run(ctx context.Context)
+	}
+)
+
+func NewOverdueService(
+	cfg configuration.Configuration,
+	loanRepository repository.LoanRepository,
+	outboxRepository repository.OutboxRepository,
+	productRepository repository.ProductRepository,
+	delinquencyRepository repository.DelinquencyRepository,
+	journalRepository repository.JournalRepository,
+	transaction repository.Transaction,
+	clock common.Clock,
+	calendar calendar.Calendar) Service {
+	chunkSize := int(cfg.GetInt("overdue.job.chunk"))
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	interval := time.Duration(cfg.GetInt("overdue.job.interval.minutes")) * time.Minute
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	return &overdueService{
+		loanRepository:    loanRepository,
+		outboxRepository:  outboxRepository,
+		productRepository: productRepository,
+		journalRepository: journalRepository,
+		transaction:       transaction,
+		generate:          common.NewGenerate(),
+		clock:             clock,
+		calendar:          calendar,
+		chart:             journal.NewChart(cfg),
+		assessor:          delinquency.NewAssessor(loanRepository, productRepository, calendar),
+		tracker:           delinquency.NewTracker(delinquencyRepository, outboxRepository),
+		chunkSize:         chunkSize,
+		maxConflicts:      defaultMaxConflicts,
+		interval:          interval,
+	}
+}
@@ -0,0 +1,232 @@
+package overdue
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/event"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/product"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+var (
+	errorFromDatabase = errors.New("from database")
+	errorConflict     = errors.New("installments keep changing while marked as overdue")
+)
+
+func (o *overdueService) MarkOverdue(ctx context.Context) (int, error) {
+	return o.MarkOverdueAsOf(ctx, o.clock.Now())
+}
+
+func (o *overdueService) MarkOverdueAsOf(ctx context.Context, asOf time.Time) (int, error) {
+	//the installment is past due starting the day after its due date, or after the next business day
+	//when it is due on the non business day
+	lateBefore := o.calendar.LateBefore(asOf)
+
+	marked, conflicts := 0, 0
+	afterID := uint64(0)
+	for {
+		loans, err := o.loanRepository.FindLoans(
+			ctx, &repository.LoanEntity{
+				Statuses: []repository.LoanStatus{repository.LoanPending},
+				DueDate:  lateBefore,
+				Limit:    o.chunkSize,
+				AfterID:  afterID,
+			},
+		)
+
+		if err != nil && !errors.Is(err, repository.ErrorNoRows) {
+			return marked, errorFromDatabase
+		}
+
+		if len(loans) == 0 {
+			return marked, nil
+		}
+
+		late, err := o.withoutGrace(ctx, loans, asOf)
+		if err != nil {
+			return marked, err
+		}
+
+		transitions, err := o.delinquencyTransitions(ctx, late, asOf)
+		if err != nil {
+			return marked, err
+		}
+
+		errMark := o.markChunk(ctx, late, transitions, asOf)
+
+		//some of the chunk is paid concurrently, the chunk is rolled back and selected again
+		if errors.Is(errMark, repository.ErrorNoRows) {
+			conflicts += 1
+			if conflicts > o.maxConflicts {
+				return marked, errorConflict
+			}
+
+			continue
+		}
+
+		if errMark != nil {
+			log.Println("failed mark overdue -> ", errMark)
+			return marked, errorFromDatabase
+		}
+
+		//the installments in the grace period stay PENDING, so the next chunk is selected after them
+		afterID = loans[len(loans)-1].ID
+		marked += len(late)
+		if len(loans) < o.chunkSize {
+			return marked, nil
+		}
+	}
+}
+
+func (o *overdueService) Run(ctx context.Context) {
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+
+	for {
+		marked, err := o.MarkOverdue(ctx)
+		if err != nil {
+			log.Println("[Overdue Job] failed mark overdue -> ", err)
+		}
+
+		if marked > 0 {
+			log.Println("[Overdue Job] marked installments as overdue -> ", marked)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// withoutGrace excludes the installments which are still in the grace period of their product as of asOf.
+func (o *overdueService) withoutGrace(
+	ctx context.Context,
+	loans []*repository.LoanEntity,
+	asOf time.Time) ([]*repository.LoanEntity, error) {
+	products, err := product.FindProductsOf(ctx, o.productRepository, loans)
+	if err != nil {
+		log.Println("failed find products -> ", err)
+		return nil, errorFromDatabase
+	}
+
+	var late []*repository.LoanEntity
+	for _, loan := range loans {
+		if loan.DueDate.Before(product.LateBefore(o.calendar, products[loan.ProductID], asOf)) {
+			late = append(late, loan)
+		}
+	}
+
+	return late, nil
+}
+
+// delinquencyTransitions assesses the customers of the installments getting late as of asOf,
+// it returns the events of the customers whose delinquency changes.
+func (o *overdueService) delinquencyTransitions(
+	ctx context.Context,
+	loans []*repository.LoanEntity,
+	asOf time.Time) ([]*repository.OutboxEntity, error) {
+	var transitions []*repository.OutboxEntity
+	assessed := make(map[string]bool)
+	for _, loan := range loans {
+		if assessed[loan.UserID] {
+			continue
+		}
+
+		assessed[loan.UserID] = true
+
+		//the installments are assessed the same whether PENDING or OVERDUE, so the chunk needn't be marked first
+		assessment, err := o.assessor.Assess(ctx, loan.UserID, asOf)
+		if err != nil {
+			log.Println("failed assess delinquency -> ", loan.UserID, err)
+			return nil, errorFromDatabase
+		}
+
+		transition, err := o.tracker.Transition(ctx, assessment, asOf)
+		if err != nil {
+			log.Println("failed identify delinquency -> ", loan.UserID, err)
+			return nil, errorFromDatabase
+		}
+
+		if transition != nil {
+			transitions = append(transitions, transition)
+		}
+	}
+
+	return transitions, nil
+}
+
+// markChunk moves the chunk to OVERDUE with its events, accruals and the delinquency transitions
+// in one transaction, only when every installment of the chunk is still PENDING.
+func (o *overdueService) markChunk(
+	ctx context.Context,
+	loans []*repository.LoanEntity,
+	transitions []*repository.OutboxEntity,
+	overdueAt time.Time) error {
+	if len(loans) == 0 {
+		return nil
+	}
+
+	var loanIDs []uint64
+	var outboxes []*repository.OutboxEntity
+	var entries []*repository.JournalEntryEntity
+
+	for _, loan := range loans {
+		loanIDs = append(loanIDs, loan.ID)
+
+		outbox, err := event.NewOutbox(
+			o.generate.Uuid(), event.InstallmentOverdue, loan.UserID, overdueAt,
+			&event.InstallmentOverduePayload{
+				InstallmentID: loan.ID,
+				UserID:        loan.UserID,
+				Amount:        loan.Amount,
+				DueDate:       loan.DueDate,
+				OverdueAt:     overdueAt,
+			},
+		)
+
+		if err != nil {
+			return err
+		}
+
+		outboxes = append(outboxes, outbox)
+
+		if accrual := o.chart.Accrual(o.generate.Uuid(), loan, overdueAt); accrual != nil {
+			entries = append(entries, accrual)
+		}
+	}
+
+	return o.transaction.WithTransaction(
+		ctx, func(tx *sql.Tx) error {
+			errUpdate := o.loanRepository.UpdateLoan(
+				ctx, tx, &repository.LoanEntityUpdate{
+					IDs:        loanIDs,
+					Status:     repository.LoanOverdue,
+					FromStatus: repository.LoanPending,
+					Reason:     "overdue job",
+				},
+			)
+
+			if errUpdate != nil {
+				return errUpdate
+			}
+
+			if len(entries) > 0 {
+				if errJournal := o.journalRepository.SaveEntries(ctx, tx, entries...); errJournal != nil {
+					return errJournal
+				}
+			}
+
+			if errOutbox := o.outboxRepository.SaveOutbox(ctx, tx, outboxes...); errOutbox != nil {
+				return errOutbox
+			}
+
+			return o.tracker.Record(ctx, tx, transitions...)
+		},
+	)
+}
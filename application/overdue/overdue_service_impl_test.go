@@ -0,0 +1,488 @@
+package overdue
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/delinquency"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/event"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/journal"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/calendar"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	mocksCommon "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/common"
+	mocksConfiguration "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/configuration"
+	mocksRepository "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/infrastructure/repository"
+)
+
+func Test_overdueService_MarkOverdue(t *testing.T) {
+	now := time.Date(2024, 6, 23, 21, 0, 0, 0, time.UTC)
+	startOfDay := time.Date(2024, 6, 23, 0, 0, 0, 0, time.UTC)
+
+	loan := func(id uint64) *repository.LoanEntity {
+		return &repository.LoanEntity{
+			ID:      id,
+			Status:  repository.LoanPending,
+			UserID:  "abc",
+			DueDate: startOfDay.AddDate(0, 0, -7),
+			Amount:  decimal.NewFromFloat(110000),
+			Breakdown: repository.Breakdown{
+				Principal: decimal.NewFromFloat(100000),
+				Interest:  decimal.NewFromFloat(10000),
+			},
+		}
+	}
+
+	filter := func(afterID uint64) *repository.LoanEntity {
+		return &repository.LoanEntity{
+			Statuses: []repository.LoanStatus{repository.LoanPending},
+			DueDate:  startOfDay,
+			Limit:    2,
+			AfterID:  afterID,
+		}
+	}
+
+	withTransaction := func(ctx context.Context, fn func(tx *sql.Tx) error) error {
+		return fn(nil)
+	}
+
+	tests := []struct {
+		name     string
+		want     int
+		wantErr  error
+		mockFunc func(
+			mockLoanRepo *mocksRepository.LoanRepository,
+			mockOutboxRepo *mocksRepository.OutboxRepository,
+			mockJournalRepo *mocksRepository.JournalRepository,
+			mockTransaction *mocksRepository.Transaction)
+	}{
+		{
+			name: "given find loans is failed," +
+				"when markOverdue," +
+				"then return error",
+			wantErr: errorFromDatabase,
+			mockFunc: func(
+				mockLoanRepo *mocksRepository.LoanRepository,
+				mockOutboxRepo *mocksRepository.OutboxRepository,
+				mockJournalRepo *mocksRepository.JournalRepository,
+				mockTransaction *mocksRepository.Transaction) {
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, filter(0)).
+					Return(nil, errors.New("mock error")).
+					Once()
+			},
+		},
+		{
+			name: "given no past due installments," +
+				"when markOverdue," +
+				"then nothing is marked",
+			mockFunc: func(
+				mockLoanRepo *mocksRepository.LoanRepository,
+				mockOutboxRepo *mocksRepository.OutboxRepository,
+				mockJournalRepo *mocksRepository.JournalRepository,
+				mockTransaction *mocksRepository.Transaction) {
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, filter(0)).
+					Return(nil, nil).
+					Once()
+			},
+		},
+		{
+			name: "given past due installments more than a chunk," +
+				"when markOverdue," +
+				"then mark every chunk with the events",
+			want: 3,
+			mockFunc: func(
+				mockLoanRepo *mocksRepository.LoanRepository,
+				mockOutboxRepo *mocksRepository.OutboxRepository,
+				mockJournalRepo *mocksRepository.JournalRepository,
+				mockTransaction *mocksRepository.Transaction) {
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, filter(0)).
+					Return([]*repository.LoanEntity{loan(1), loan(2)}, nil).
+					Once()
+
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, filter(2)).
+					Return([]*repository.LoanEntity{loan(3)}, nil).
+					Once()
+
+				mockTransaction.
+					On("WithTransaction", mock.Anything, mock.Anything).
+					Return(withTransaction).
+					Twice()
+
+				mockLoanRepo.
+					On(
+						"UpdateLoan", mock.Anything, mock.Anything, &repository.LoanEntityUpdate{
+							IDs:        []uint64{1, 2},
+							Status:     repository.LoanOverdue,
+							FromStatus: repository.LoanPending,
+							Reason:     "overdue job",
+						}).
+					Return(nil).
+					Once()
+
+				mockLoanRepo.
+					On(
+						"UpdateLoan", mock.Anything, mock.Anything, &repository.LoanEntityUpdate{
+							IDs:        []uint64{3},
+							Status:     repository.LoanOverdue,
+							FromStatus: repository.LoanPending,
+							Reason:     "overdue job",
+						}).
+					Return(nil).
+					Once()
+
+				mockOutboxRepo.
+					On(
+						"SaveOutbox", mock.Anything, mock.Anything,
+						mock.MatchedBy(func(outbox *repository.OutboxEntity) bool {
+							return outbox.EventType == string(event.InstallmentOverdue)
+						}),
+						mock.Anything).
+					Return(nil).
+					Once()
+
+				mockJournalRepo.
+					On(
+						"SaveEntries", mock.Anything, mock.Anything,
+						mock.MatchedBy(func(entry *repository.JournalEntryEntity) bool {
+							return entry.EntryType == string(journal.Accrual) && entry.IsBalanced()
+						}),
+						mock.Anything).
+					Return(nil).
+					Once()
+
+				mockJournalRepo.
+					On(
+						"SaveEntries", mock.Anything, mock.Anything,
+						mock.MatchedBy(func(entry *repository.JournalEntryEntity) bool {
+							return entry.EntryType == string(journal.Accrual) && entry.IsBalanced()
+						})).
+					Return(nil).
+					Once()
+
+				mockOutboxRepo.
+					On(
+						"SaveOutbox", mock.Anything, mock.Anything,
+						mock.MatchedBy(func(outbox *repository.OutboxEntity) bool {
+							return outbox.EventType == string(event.InstallmentOverdue)
+						})).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given the chunk is paid concurrently," +
+				"when markOverdue," +
+				"then select the chunk again",
+			want: 1,
+			mockFunc: func(
+				mockLoanRepo *mocksRepository.LoanRepository,
+				mockOutboxRepo *mocksRepository.OutboxRepository,
+				mockJournalRepo *mocksRepository.JournalRepository,
+				mockTransaction *mocksRepository.Transaction) {
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, filter(0)).
+					Return([]*repository.LoanEntity{loan(1), loan(2)}, nil).
+					Once()
+
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, filter(0)).
+					Return([]*repository.LoanEntity{loan(2)}, nil).
+					Once()
+
+				mockTransaction.
+					On("WithTransaction", mock.Anything, mock.Anything).
+					Return(withTransaction).
+					Twice()
+
+				mockLoanRepo.
+					On("UpdateLoan", mock.Anything, mock.Anything, mock.Anything).
+					Return(repository.ErrorNoRows).
+					Once()
+
+				mockLoanRepo.
+					On("UpdateLoan", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+
+				mockOutboxRepo.
+					On("SaveOutbox", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+
+				mockJournalRepo.
+					On("SaveEntries", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given update loan is failed," +
+				"when markOverdue," +
+				"then return error",
+			wantErr: errorFromDatabase,
+			mockFunc: func(
+				mockLoanRepo *mocksRepository.LoanRepository,
+				mockOutboxRepo *mocksRepository.OutboxRepository,
+				mockJournalRepo *mocksRepository.JournalRepository,
+				mockTransaction *mocksRepository.Transaction) {
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, filter(0)).
+					Return([]*repository.LoanEntity{loan(1)}, nil).
+					Once()
+
+				mockTransaction.
+					On("WithTransaction", mock.Anything, mock.Anything).
+					Return(withTransaction).
+					Once()
+
+				mockLoanRepo.
+					On("UpdateLoan", mock.Anything, mock.Anything, mock.Anything).
+					Return(repository.ErrorFromDBLoan).
+					Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockLoanRepo := &mocksRepository.LoanRepository{}
+				mockOutboxRepo := &mocksRepository.OutboxRepository{}
+				mockJournalRepo := &mocksRepository.JournalRepository{}
+				mockTransaction := &mocksRepository.Transaction{}
+				mockDelinquencyRepo := &mocksRepository.DelinquencyRepository{}
+				mockCfg := &mocksConfiguration.Configuration{}
+				mockCfg.On("GetString", mock.Anything).Return("")
+				mockGenerate := &mocksCommon.Generate{}
+				mockGenerate.On("Uuid").Return("event-id")
+				mockClock := &mocksCommon.Clock{}
+				mockClock.On("Now").Return(now)
+
+				tt.mockFunc(mockLoanRepo, mockOutboxRepo, mockJournalRepo, mockTransaction)
+
+				//the delinquency of the customer doesn't change
+				mockLoanRepo.
+					On(
+						"FindLoans", mock.Anything, &repository.LoanEntity{
+							Statuses: delinquency.DueStatuses,
+							UserID:   "abc",
+							DueDate:  now,
+						},
+					).
+					Return([]*repository.LoanEntity{loan(1)}, nil).
+					Maybe()
+				mockDelinquencyRepo.On("FindDelinquency", mock.Anything, "abc").Return(nil, repository.ErrorNoRows).Maybe()
+
+				businessDays := calendar.NewCalendar(calendar.DefaultRegion, calendar.PolicyNone, nil, nil)
+
+				o := &overdueService{
+					loanRepository:    mockLoanRepo,
+					outboxRepository:  mockOutboxRepo,
+					journalRepository: mockJournalRepo,
+					transaction:       mockTransaction,
+					generate:          mockGenerate,
+					clock:             mockClock,
+					calendar:          businessDays,
+					chart:             journal.NewChart(mockCfg),
+					assessor:          delinquency.NewAssessor(mockLoanRepo, nil, businessDays),
+					tracker:           delinquency.NewTracker(mockDelinquencyRepo, mockOutboxRepo),
+					chunkSize:         2,
+					maxConflicts:      defaultMaxConflicts,
+					interval:          defaultInterval,
+				}
+
+				got, err := o.MarkOverdue(context.Background())
+
+				assert.Equal(t, tt.want, got)
+				assert.Equal(t, tt.wantErr, err)
+				mockLoanRepo.AssertExpectations(t)
+				mockOutboxRepo.AssertExpectations(t)
+				mockJournalRepo.AssertExpectations(t)
+			})
+	}
+}
+
+func Test_overdueService_MarkOverdueAsOf_Calendar(t *testing.T) {
+	//2024-06-21 is friday, the installments due in the weekend are not late until monday is over
+	asOf := time.Date(2024, 6, 24, 21, 0, 0, 0, time.UTC)
+
+	mockLoanRepo := &mocksRepository.LoanRepository{}
+	mockLoanRepo.
+		On(
+			"FindLoans", mock.Anything, &repository.LoanEntity{
+				Statuses: []repository.LoanStatus{repository.LoanPending},
+				DueDate:  time.Date(2024, 6, 22, 0, 0, 0, 0, time.UTC),
+				Limit:    2,
+			},
+		).
+		Return(nil, repository.ErrorNoRows).
+		Once()
+
+	o := &overdueService{
+		loanRepository: mockLoanRepo,
+		calendar: calendar.NewCalendar(
+			calendar.DefaultRegion, calendar.PolicyForward, []time.Weekday{time.Saturday, time.Sunday}, nil),
+		chunkSize:    2,
+		maxConflicts: defaultMaxConflicts,
+	}
+
+	got, err := o.MarkOverdueAsOf(context.Background(), asOf)
+
+	assert.Equal(t, 0, got)
+	assert.Nil(t, err)
+	mockLoanRepo.AssertExpectations(t)
+}
+
+func Test_overdueService_MarkOverdueAsOf_Grace(t *testing.T) {
+	//the product gives 3 days of grace, so its installment due on 2024-06-22 is not late until 2024-06-26
+	asOf := time.Date(2024, 6, 24, 21, 0, 0, 0, time.UTC)
+	dueDate := time.Date(2024, 6, 22, 0, 0, 0, 0, time.UTC)
+
+	inGrace := &repository.LoanEntity{ID: 1, Status: repository.LoanPending, UserID: "abc", DueDate: dueDate, ProductID: 7}
+	withoutProduct := &repository.LoanEntity{ID: 2, Status: repository.LoanPending, UserID: "abc", DueDate: dueDate}
+	//the customer has missed 2 installments already, the one getting late makes the customer delinquent
+	missed := func(id uint64) *repository.LoanEntity {
+		return &repository.LoanEntity{
+			ID: id, Status: repository.LoanOverdue, UserID: "abc", DueDate: dueDate.AddDate(0, 0, -7),
+		}
+	}
+
+	mockLoanRepo := &mocksRepository.LoanRepository{}
+	mockProductRepo := &mocksRepository.ProductRepository{}
+	mockOutboxRepo := &mocksRepository.OutboxRepository{}
+	mockJournalRepo := &mocksRepository.JournalRepository{}
+	mockDelinquencyRepo := &mocksRepository.DelinquencyRepository{}
+	mockTransaction := &mocksRepository.Transaction{}
+	mockCfg := &mocksConfiguration.Configuration{}
+	mockCfg.On("GetString", mock.Anything).Return("")
+	mockGenerate := &mocksCommon.Generate{}
+	mockGenerate.On("Uuid").Return("event-id")
+
+	mockLoanRepo.
+		On(
+			"FindLoans", mock.Anything, &repository.LoanEntity{
+				Statuses: []repository.LoanStatus{repository.LoanPending},
+				DueDate:  time.Date(2024, 6, 24, 0, 0, 0, 0, time.UTC),
+				Limit:    2,
+			},
+		).
+		Return([]*repository.LoanEntity{inGrace, withoutProduct}, nil).
+		Once()
+
+	//the installment in the grace period stays PENDING, the next chunk is selected after it
+	mockLoanRepo.
+		On(
+			"FindLoans", mock.Anything, &repository.LoanEntity{
+				Statuses: []repository.LoanStatus{repository.LoanPending},
+				DueDate:  time.Date(2024, 6, 24, 0, 0, 0, 0, time.UTC),
+				Limit:    2,
+				AfterID:  2,
+			},
+		).
+		Return(nil, repository.ErrorNoRows).
+		Once()
+
+	mockProductRepo.
+		On("FindProducts", mock.Anything, &repository.ProductFilter{IDs: []uint64{7}}).
+		Return([]*repository.ProductEntity{{ID: 7, GraceDays: 3}}, nil).
+		Twice()
+
+	mockLoanRepo.
+		On(
+			"FindLoans", mock.Anything, &repository.LoanEntity{
+				Statuses: delinquency.DueStatuses,
+				UserID:   "abc",
+				DueDate:  asOf,
+			},
+		).
+		Return([]*repository.LoanEntity{missed(3), missed(4), inGrace, withoutProduct}, nil).
+		Once()
+
+	mockDelinquencyRepo.
+		On("FindDelinquency", mock.Anything, "abc").
+		Return(nil, repository.ErrorNoRows).
+		Once()
+
+	mockTransaction.
+		On("WithTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(tx *sql.Tx) error) error {
+			return fn(nil)
+		}).
+		Once()
+
+	mockLoanRepo.
+		On(
+			"UpdateLoan", mock.Anything, mock.Anything, &repository.LoanEntityUpdate{
+				IDs:        []uint64{2},
+				Status:     repository.LoanOverdue,
+				FromStatus: repository.LoanPending,
+				Reason:     "overdue job",
+			}).
+		Return(nil).
+		Once()
+
+	mockOutboxRepo.
+		On(
+			"SaveOutbox", mock.Anything, mock.Anything,
+			mock.MatchedBy(func(outbox *repository.OutboxEntity) bool {
+				return outbox.EventType == string(event.InstallmentOverdue)
+			})).
+		Return(nil).
+		Once()
+
+	//the delinquency transition is recorded in the transaction of the marking
+	mockDelinquencyRepo.
+		On(
+			"UpsertDelinquency", mock.Anything, mock.Anything, &repository.DelinquencyEntity{
+				UserID:       "abc",
+				IsDelinquent: true,
+				UpdatedAt:    asOf,
+			},
+		).
+		Return(nil).
+		Once()
+
+	mockOutboxRepo.
+		On(
+			"SaveOutbox", mock.Anything, mock.Anything,
+			mock.MatchedBy(func(outbox *repository.OutboxEntity) bool {
+				return outbox.EventType == string(event.CustomerBecameDelinquent) && outbox.AggregateID == "abc"
+			})).
+		Return(nil).
+		Once()
+
+	businessDays := calendar.NewCalendar(calendar.DefaultRegion, calendar.PolicyNone, nil, nil)
+	o := &overdueService{
+		loanRepository:    mockLoanRepo,
+		outboxRepository:  mockOutboxRepo,
+		productRepository: mockProductRepo,
+		journalRepository: mockJournalRepo,
+		transaction:       mockTransaction,
+		generate:          mockGenerate,
+		calendar:          businessDays,
+		chart:             journal.NewChart(mockCfg),
+		assessor:          delinquency.NewAssessor(mockLoanRepo, mockProductRepo, businessDays),
+		tracker:           delinquency.NewTracker(mockDelinquencyRepo, mockOutboxRepo),
+		chunkSize:         2,
+		maxConflicts:      defaultMaxConflicts,
+	}
+
+	got, err := o.MarkOverdueAsOf(context.Background(), asOf)
+
+	assert.Equal(t, 1, got)
+	assert.Nil(t, err)
+	mockLoanRepo.AssertExpectations(t)
+	mockProductRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	mockDelinquencyRepo.AssertExpectations(t)
+}
@@ -0,0 +1,21 @@
+package payment
+
+import (
+	"net/http"
+)
+
+type (
+	paymentController struct {
+		srv Service
+	}
+
+	Controller interface {
+		Callback(writer http.ResponseWriter, req *http.Request)
+	}
+)
+
+func NewPaymentController(srv Service) Controller {
+	return &paymentController{
+		srv: srv,
+	}
+}
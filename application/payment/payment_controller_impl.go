@@ -0,0 +1,73 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/constant"
+)
+
+const (
+	maxCallbackBody = 1048576
+)
+
+func (p *paymentController) Callback(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	provider := mux.Vars(req)["provider"]
+
+	//the raw body is needed to verify the signature, so it is not decoded here
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxCallbackBody))
+	if err != nil || len(body) == 0 {
+		log.Println("validation read callback body -> ", err)
+		toErrorResponse(writer, errorValidation)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	err = p.srv.Callback(
+		ctx, &CallbackRequest{
+			Provider: provider,
+			Header:   req.Header,
+			Body:     body,
+		},
+	)
+
+	if err != nil {
+		toErrorResponse(writer, err)
+		return
+	}
+
+	common.ToSuccessResponse(writer, nil, nil)
+}
+
+func toErrorResponse(writer http.ResponseWriter, err error) {
+	billingErr := func() constant.BillingSrvHttpError {
+		switch {
+		case errors.Is(err, errorValidation):
+			return constant.Validation
+		case errors.Is(err, errorDataNotExists):
+			return constant.DataNotFound
+		case errors.Is(err, errorUnauthorized):
+			return constant.Unauthorized
+		default:
+			return constant.GeneralError
+		}
+	}()
+
+	common.ToErrorResponse(
+		writer,
+		constant.HttpRc[billingErr],
+		constant.HttpRcDescription[billingErr],
+	)
+}
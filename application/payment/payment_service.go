@@ -0,0 +1,79 @@
+package payment
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/loan"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/gateway"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+const (
+	defaultBatchSize   = 100
+	defaultMaxAttempts = 20
+	defaultInterval    = 10 * time.Second
+)
+
+type (
+	paymentService struct {
+		callbackRepository repository.PaymentCallbackRepository
+		paymentGateway     gateway.PaymentGateway
+		loanSrv            loan.Service
+		generate           common.Generate
+		batchSize          int
+		maxAttempts        int
+		interval           time.Duration
+	}
+
+	CallbackRequest struct {
+		Provider string
+		Header   http.Header
+		Body     []byte
+	}
+
+	Service interface {
+		// Callback verifies the callback of the provider, then settles the payment through loan.Service.
+		// The callback is queued when it can't be applied yet, e.g. the payment is not recorded yet.
+		Callback(ctx context.Context, request *CallbackRequest) error
+
+		// ProcessQueuedCallbacks applies the queued callbacks again, it returns the number of applied.
+		ProcessQueuedCallbacks(ctx context.Context) (int, error)
+
+		Run(ctx context.Context)
+	}
+)
+
+func NewPaymentService(
+	cfg configuration.Configuration,
+	callbackRepository repository.PaymentCallbackRepository,
+	paymentGateway gateway.PaymentGateway,
+	loanSrv loan.Service) Service {
+	batchSize := int(cfg.GetInt("payment.callback.retry.batch"))
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	maxAttempts := int(cfg.GetInt("payment.callback.retry.max.attempts"))
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	interval := time.Duration(cfg.GetInt("payment.callback.retry.interval.seconds")) * time.Second
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	return &paymentService{
+		callbackRepository: callbackRepository,
+		paymentGateway:     paymentGateway,
+		loanSrv:            loanSrv,
+		generate:           common.NewGenerate(),
+		batchSize:          batchSize,
+		maxAttempts:        maxAttempts,
+		interval:           interval,
+	}
+}
@@ -0,0 +1,189 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/loan"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/constant"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/gateway"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+const (
+	maxLastError = 255
+)
+
+var (
+	errorValidation    = errors.New("validation request")
+	errorFromDatabase  = errors.New("from database")
+	errorDataNotExists = errors.New("data is not exists")
+	errorUnauthorized  = errors.New("unauthorized")
+)
+
+func (p *paymentService) Callback(
+	ctx context.Context,
+	request *CallbackRequest) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Println("unidentified error (yet)", string(debug.Stack()))
+			err = errorFromDatabase
+			return
+		}
+	}()
+
+	if request.Provider != p.paymentGateway.Provider() {
+		return errorDataNotExists
+	}
+
+	charge, err := p.paymentGateway.ParseCallback(request.Header, request.Body)
+	if errors.Is(err, gateway.ErrorSignature) {
+		return errorUnauthorized
+	}
+
+	if err != nil {
+		log.Println("invalid callback -> ", request.Provider, err)
+		return errorValidation
+	}
+
+	now := p.generate.Time()
+	errSave := p.callbackRepository.SaveCallback(
+		ctx, &repository.PaymentCallbackEntity{
+			Provider:          request.Provider,
+			ProviderReference: charge.ProviderReference,
+			Reference:         charge.Reference,
+			ProviderStatus:    string(charge.Status),
+			Amount:            charge.Amount,
+			FailureReason:     charge.FailureReason,
+			Payload:           request.Body,
+			Status:            repository.PaymentCallbackQueued,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		},
+	)
+
+	if errSave != nil {
+		return errorFromDatabase
+	}
+
+	//the provider retries the same callback, so apply the stored one only once
+	callbacks, errFind := p.callbackRepository.FindCallbacks(
+		ctx, &repository.PaymentCallbackFilter{
+			Provider:          request.Provider,
+			ProviderReference: charge.ProviderReference,
+			ProviderStatus:    string(charge.Status),
+		},
+	)
+
+	if errFind != nil || len(callbacks) == 0 {
+		return errorFromDatabase
+	}
+
+	if callbacks[0].Status != repository.PaymentCallbackQueued {
+		return nil
+	}
+
+	//the callback is stored, so it is acknowledged even though it is not applied yet
+	if _, errApply := p.apply(ctx, callbacks[0]); errApply != nil {
+		log.Println("failed apply callback -> ", charge.ProviderReference, errApply)
+	}
+
+	return nil
+}
+
+func (p *paymentService) ProcessQueuedCallbacks(ctx context.Context) (int, error) {
+	callbacks, err := p.callbackRepository.FindCallbacks(
+		ctx, &repository.PaymentCallbackFilter{
+			Status: repository.PaymentCallbackQueued,
+			Limit:  p.batchSize,
+		},
+	)
+
+	if err != nil {
+		return 0, errorFromDatabase
+	}
+
+	applied := 0
+	for _, callback := range callbacks {
+		ok, errApply := p.apply(ctx, callback)
+		if errApply != nil {
+			return applied, errApply
+		}
+
+		if ok {
+			applied += 1
+		}
+	}
+
+	return applied, nil
+}
+
+func (p *paymentService) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		applied, err := p.ProcessQueuedCallbacks(ctx)
+		if err != nil {
+			log.Println("[Payment Callback] failed process queued callbacks -> ", err)
+		}
+
+		if applied > 0 {
+			log.Println("[Payment Callback] applied queued callbacks -> ", applied)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// apply settles the payment of the callback, the callback stays QUEUED when it fails
+// (e.g. the callback arrives before the payment is recorded) until max attempts.
+func (p *paymentService) apply(
+	ctx context.Context,
+	callback *repository.PaymentCallbackEntity) (bool, error) {
+	errSettle := p.loanSrv.SettlePayment(
+		ctx, &gateway.Charge{
+			Reference:         callback.Reference,
+			ProviderReference: callback.ProviderReference,
+			Status:            gateway.Status(callback.ProviderStatus),
+			Amount:            callback.Amount,
+			FailureReason:     callback.FailureReason,
+		},
+	)
+
+	update := &repository.PaymentCallbackUpdate{
+		ID:       callback.ID,
+		Status:   repository.PaymentCallbackProcessed,
+		Attempts: callback.Attempts + 1,
+	}
+
+	if errSettle != nil {
+		if loan.MapError(errSettle) == constant.DataNotFound {
+			log.Println("payment of callback is not exists yet, callback is queued -> ", callback.ProviderReference)
+		}
+
+		update.Status = repository.PaymentCallbackQueued
+		if update.Attempts >= p.maxAttempts {
+			update.Status = repository.PaymentCallbackFailed
+		}
+
+		update.LastError = errSettle.Error()
+		if len(update.LastError) > maxLastError {
+			update.LastError = update.LastError[:maxLastError]
+		}
+	}
+
+	if errUpdate := p.callbackRepository.UpdateCallback(ctx, update); errUpdate != nil {
+		log.Println("failed update callback -> ", callback.ProviderReference, errUpdate)
+		return false, errorFromDatabase
+	}
+
+	return errSettle == nil, nil
+}
@@ -0,0 +1,298 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/gateway"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	mocksLoan "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/application/loan"
+	mocksGateway "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/infrastructure/gateway"
+	mocksRepository "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/infrastructure/repository"
+)
+
+func Test_paymentService_Callback(t *testing.T) {
+	charge := &gateway.Charge{
+		Reference:         "p-1",
+		ProviderReference: "fake-1",
+		Status:            gateway.StatusSuccess,
+		Amount:            decimal.NewFromInt(25),
+	}
+
+	queued := &repository.PaymentCallbackEntity{
+		ID:                1,
+		Provider:          gateway.ProviderFake,
+		ProviderReference: "fake-1",
+		Reference:         "p-1",
+		ProviderStatus:    string(gateway.StatusSuccess),
+		Amount:            decimal.NewFromInt(25),
+		Status:            repository.PaymentCallbackQueued,
+	}
+
+	tests := []struct {
+		name     string
+		provider string
+		wantErr  error
+		mockFunc func(
+			mockGateway *mocksGateway.PaymentGateway,
+			mockRepo *mocksRepository.PaymentCallbackRepository,
+			mockLoanSrv *mocksLoan.Service)
+	}{
+		{
+			name: "given unknown provider," +
+				"when callback," +
+				"then return not exists",
+			provider: "unknown",
+			wantErr:  errorDataNotExists,
+		},
+		{
+			name: "given invalid signature," +
+				"when callback," +
+				"then return unauthorized",
+			provider: gateway.ProviderFake,
+			wantErr:  errorUnauthorized,
+			mockFunc: func(
+				mockGateway *mocksGateway.PaymentGateway,
+				mockRepo *mocksRepository.PaymentCallbackRepository,
+				mockLoanSrv *mocksLoan.Service) {
+				mockGateway.
+					On("ParseCallback", mock.Anything, mock.Anything).
+					Return(nil, gateway.ErrorSignature).
+					Once()
+			},
+		},
+		{
+			name: "given valid callback," +
+				"when callback," +
+				"then the payment is settled and callback is processed",
+			provider: gateway.ProviderFake,
+			mockFunc: func(
+				mockGateway *mocksGateway.PaymentGateway,
+				mockRepo *mocksRepository.PaymentCallbackRepository,
+				mockLoanSrv *mocksLoan.Service) {
+				mockGateway.
+					On("ParseCallback", mock.Anything, mock.Anything).
+					Return(charge, nil).
+					Once()
+
+				mockRepo.
+					On("SaveCallback", mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+
+				mockRepo.
+					On("FindCallbacks", mock.Anything, mock.Anything).
+					Return([]*repository.PaymentCallbackEntity{queued}, nil).
+					Once()
+
+				mockLoanSrv.
+					On("SettlePayment", mock.Anything, charge).
+					Return(nil).
+					Once()
+
+				mockRepo.
+					On("UpdateCallback", mock.Anything, &repository.PaymentCallbackUpdate{
+						ID:       1,
+						Status:   repository.PaymentCallbackProcessed,
+						Attempts: 1,
+					}).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given the same callback is already processed," +
+				"when callback," +
+				"then the payment is not settled again",
+			provider: gateway.ProviderFake,
+			mockFunc: func(
+				mockGateway *mocksGateway.PaymentGateway,
+				mockRepo *mocksRepository.PaymentCallbackRepository,
+				mockLoanSrv *mocksLoan.Service) {
+				mockGateway.
+					On("ParseCallback", mock.Anything, mock.Anything).
+					Return(charge, nil).
+					Once()
+
+				mockRepo.
+					On("SaveCallback", mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+
+				mockRepo.
+					On("FindCallbacks", mock.Anything, mock.Anything).
+					Return([]*repository.PaymentCallbackEntity{{ID: 1, Status: repository.PaymentCallbackProcessed}}, nil).
+					Once()
+			},
+		},
+		{
+			name: "given callback arrives before the payment is recorded," +
+				"when callback," +
+				"then callback is queued and acknowledged",
+			provider: gateway.ProviderFake,
+			mockFunc: func(
+				mockGateway *mocksGateway.PaymentGateway,
+				mockRepo *mocksRepository.PaymentCallbackRepository,
+				mockLoanSrv *mocksLoan.Service) {
+				mockGateway.
+					On("ParseCallback", mock.Anything, mock.Anything).
+					Return(charge, nil).
+					Once()
+
+				mockRepo.
+					On("SaveCallback", mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+
+				mockRepo.
+					On("FindCallbacks", mock.Anything, mock.Anything).
+					Return([]*repository.PaymentCallbackEntity{queued}, nil).
+					Once()
+
+				mockLoanSrv.
+					On("SettlePayment", mock.Anything, mock.Anything).
+					Return(errors.New("data is not exists")).
+					Once()
+
+				mockRepo.
+					On("UpdateCallback", mock.Anything, &repository.PaymentCallbackUpdate{
+						ID:        1,
+						Status:    repository.PaymentCallbackQueued,
+						Attempts:  1,
+						LastError: "data is not exists",
+					}).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given save callback is failed," +
+				"when callback," +
+				"then return error so the provider retries",
+			provider: gateway.ProviderFake,
+			wantErr:  errorFromDatabase,
+			mockFunc: func(
+				mockGateway *mocksGateway.PaymentGateway,
+				mockRepo *mocksRepository.PaymentCallbackRepository,
+				mockLoanSrv *mocksLoan.Service) {
+				mockGateway.
+					On("ParseCallback", mock.Anything, mock.Anything).
+					Return(charge, nil).
+					Once()
+
+				mockRepo.
+					On("SaveCallback", mock.Anything, mock.Anything).
+					Return(repository.ErrorFromDBLoan).
+					Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockGateway := &mocksGateway.PaymentGateway{}
+				mockRepo := &mocksRepository.PaymentCallbackRepository{}
+				mockLoanSrv := &mocksLoan.Service{}
+
+				mockGateway.On("Provider").Return(gateway.ProviderFake)
+				if tt.mockFunc != nil {
+					tt.mockFunc(mockGateway, mockRepo, mockLoanSrv)
+				}
+
+				p := &paymentService{
+					callbackRepository: mockRepo,
+					paymentGateway:     mockGateway,
+					loanSrv:            mockLoanSrv,
+					generate:           common.NewGenerate(),
+					batchSize:          defaultBatchSize,
+					maxAttempts:        defaultMaxAttempts,
+					interval:           time.Second,
+				}
+
+				err := p.Callback(
+					context.Background(), &CallbackRequest{
+						Provider: tt.provider,
+						Header:   http.Header{},
+						Body:     []byte(`{}`),
+					},
+				)
+
+				assert.Equal(t, tt.wantErr, err)
+				mockRepo.AssertExpectations(t)
+				mockLoanSrv.AssertExpectations(t)
+			})
+	}
+}
+
+func Test_paymentService_ProcessQueuedCallbacks(t *testing.T) {
+	mockRepo := &mocksRepository.PaymentCallbackRepository{}
+	mockLoanSrv := &mocksLoan.Service{}
+
+	mockRepo.
+		On("FindCallbacks", mock.Anything, &repository.PaymentCallbackFilter{
+			Status: repository.PaymentCallbackQueued,
+			Limit:  defaultBatchSize,
+		}).
+		Return(
+			[]*repository.PaymentCallbackEntity{
+				{ID: 1, ProviderReference: "fake-1", ProviderStatus: "SUCCESS", Attempts: 3},
+				{ID: 2, ProviderReference: "fake-2", ProviderStatus: "SUCCESS", Attempts: defaultMaxAttempts - 1},
+			}, nil).
+		Once()
+
+	mockLoanSrv.
+		On("SettlePayment", mock.Anything, mock.MatchedBy(func(charge *gateway.Charge) bool {
+			return charge.ProviderReference == "fake-1"
+		})).
+		Return(nil).
+		Once()
+
+	mockLoanSrv.
+		On("SettlePayment", mock.Anything, mock.MatchedBy(func(charge *gateway.Charge) bool {
+			return charge.ProviderReference == "fake-2"
+		})).
+		Return(errors.New("data is not exists")).
+		Once()
+
+	mockRepo.
+		On("UpdateCallback", mock.Anything, &repository.PaymentCallbackUpdate{
+			ID:       1,
+			Status:   repository.PaymentCallbackProcessed,
+			Attempts: 4,
+		}).
+		Return(nil).
+		Once()
+
+	//give up after max attempts, so it does not block the batch forever
+	mockRepo.
+		On("UpdateCallback", mock.Anything, &repository.PaymentCallbackUpdate{
+			ID:        2,
+			Status:    repository.PaymentCallbackFailed,
+			Attempts:  defaultMaxAttempts,
+			LastError: "data is not exists",
+		}).
+		Return(nil).
+		Once()
+
+	p := &paymentService{
+		callbackRepository: mockRepo,
+		loanSrv:            mockLoanSrv,
+		generate:           common.NewGenerate(),
+		batchSize:          defaultBatchSize,
+		maxAttempts:        defaultMaxAttempts,
+	}
+
+	applied, err := p.ProcessQueuedCallbacks(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, applied)
+	mockRepo.AssertExpectations(t)
+}
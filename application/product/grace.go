@@ -0,0 +1,74 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/calendar"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+// LateBefore is the start of the day the installments of the product due before it are late on the day, it is
+// the grace days of the product before the calendar's. The installments due since then are due but not late yet,
+// so they are neither marked overdue (hence not penalised) nor counted towards the delinquency.
+// The product is nil for the loan issued before the catalogue, which has no grace period.
+func LateBefore(c calendar.Calendar, product *repository.ProductEntity, day time.Time) time.Time {
+	lateBefore := c.LateBefore(day)
+	if product == nil || product.GraceDays <= 0 {
+		return lateBefore
+	}
+
+	return lateBefore.AddDate(0, 0, -product.GraceDays)
+}
+
+// DaysPastDue is the days the installment of the product due on the due date is past due on the day, counted
+// from the business day it is due on after the grace days of the product, 0 when it is not past due yet.
+// The write-off and the delinquency snapshot count the days past due by it, so they always agree.
+func DaysPastDue(c calendar.Calendar, product *repository.ProductEntity, dueDate time.Time, day time.Time) int {
+	dueOn := c.NextBusinessDay(common.StartOfDay(dueDate))
+	if product != nil && product.GraceDays > 0 {
+		dueOn = dueOn.AddDate(0, 0, product.GraceDays)
+	}
+
+	dpd := int(common.StartOfDay(day).Sub(dueOn).Hours() / 24)
+	if dpd < 0 {
+		return 0
+	}
+
+	return dpd
+}
+
+// FindProductsOf returns the product versions the loans are issued by, keyed by the id.
+func FindProductsOf(
+	ctx context.Context,
+	productRepository repository.ProductRepository,
+	loans []*repository.LoanEntity) (map[uint64]*repository.ProductEntity, error) {
+	var ids []uint64
+	seen := make(map[uint64]bool)
+	for _, loan := range loans {
+		if loan.ProductID == 0 || seen[loan.ProductID] {
+			continue
+		}
+
+		seen[loan.ProductID] = true
+		ids = append(ids, loan.ProductID)
+	}
+
+	products := make(map[uint64]*repository.ProductEntity)
+	if len(ids) == 0 {
+		return products, nil
+	}
+
+	found, err := productRepository.FindProducts(ctx, &repository.ProductFilter{IDs: ids})
+	if err != nil && !errors.Is(err, repository.ErrorNoRows) {
+		return nil, err
+	}
+
+	for _, product := range found {
+		products[product.ID] = product
+	}
+
+	return products, nil
+}
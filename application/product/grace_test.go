@@ -0,0 +1,138 @@
+package product
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/calendar"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	mocksRepository "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/infrastructure/repository"
+)
+
+func Test_LateBefore(t *testing.T) {
+	//2026-10-17 & 2026-10-18 is the weekend
+	weekend := calendar.NewCalendar(
+		calendar.DefaultRegion, calendar.PolicyNone, []time.Weekday{time.Saturday, time.Sunday}, nil)
+
+	tests := []struct {
+		name    string
+		product *repository.ProductEntity
+		day     time.Time
+		want    time.Time
+	}{
+		{
+			name: "given loan issued before the catalogue," +
+				"when lateBefore," +
+				"then return the late before of the calendar",
+			day:  time.Date(2026, 10, 21, 10, 0, 0, 0, time.UTC),
+			want: time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "given product with the grace days," +
+				"when lateBefore," +
+				"then return the late before of the calendar minus the grace days",
+			product: &repository.ProductEntity{GraceDays: 2},
+			day:     time.Date(2026, 10, 21, 10, 0, 0, 0, time.UTC),
+			want:    time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "given product with the grace days after the weekend," +
+				"when lateBefore," +
+				"then the grace days are counted after the next business day",
+			product: &repository.ProductEntity{GraceDays: 1},
+			day:     time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC),
+			want:    time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.want, LateBefore(weekend, tt.product, tt.day))
+			},
+		)
+	}
+}
+
+func Test_DaysPastDue(t *testing.T) {
+	//2026-10-17 & 2026-10-18 is the weekend
+	weekend := calendar.NewCalendar(
+		calendar.DefaultRegion, calendar.PolicyNone, []time.Weekday{time.Saturday, time.Sunday}, nil)
+
+	tests := []struct {
+		name    string
+		product *repository.ProductEntity
+		dueDate time.Time
+		day     time.Time
+		want    int
+	}{
+		{
+			name: "given loan issued before the catalogue," +
+				"when daysPastDue," +
+				"then return the days from the due date",
+			dueDate: time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC),
+			day:     time.Date(2026, 10, 21, 10, 0, 0, 0, time.UTC),
+			want:    7,
+		},
+		{
+			name: "given installment due on the weekend," +
+				"when daysPastDue," +
+				"then return the days from the next business day",
+			dueDate: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC),
+			day:     time.Date(2026, 10, 21, 10, 0, 0, 0, time.UTC),
+			want:    2,
+		},
+		{
+			name: "given product with the grace days," +
+				"when daysPastDue," +
+				"then return the days from the due date after the grace days",
+			product: &repository.ProductEntity{GraceDays: 2},
+			dueDate: time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC),
+			day:     time.Date(2026, 10, 21, 10, 0, 0, 0, time.UTC),
+			want:    5,
+		},
+		{
+			name: "given installment within the grace days," +
+				"when daysPastDue," +
+				"then return zero",
+			product: &repository.ProductEntity{GraceDays: 3},
+			dueDate: time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC),
+			day:     time.Date(2026, 10, 21, 10, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.want, DaysPastDue(weekend, tt.product, tt.dueDate, tt.day))
+			},
+		)
+	}
+}
+
+func Test_FindProductsOf(t *testing.T) {
+	mockProductRepo := &mocksRepository.ProductRepository{}
+	mockProductRepo.
+		On("FindProducts", mock.Anything, &repository.ProductFilter{IDs: []uint64{7, 8}}).
+		Return([]*repository.ProductEntity{{ID: 7, GraceDays: 3}, {ID: 8}}, nil).
+		Once()
+
+	got, err := FindProductsOf(
+		context.Background(), mockProductRepo, []*repository.LoanEntity{
+			{ID: 1, ProductID: 7},
+			{ID: 2, ProductID: 7},
+			{ID: 3},
+			{ID: 4, ProductID: 8},
+		},
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, got[7].GraceDays)
+	assert.NotNil(t, got[8])
+	assert.Nil(t, got[0])
+	mockProductRepo.AssertExpectations(t)
+}
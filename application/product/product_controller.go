@@ -0,0 +1,29 @@
+package product
+
+import (
+	"net/http"
+)
+
+type (
+	productController struct {
+		srv Service
+	}
+
+	Controller interface {
+		CreateProduct(writer http.ResponseWriter, req *http.Request)
+
+		UpdateProduct(writer http.ResponseWriter, req *http.Request)
+
+		FindProducts(writer http.ResponseWriter, req *http.Request)
+
+		FindProduct(writer http.ResponseWriter, req *http.Request)
+
+		RetireProduct(writer http.ResponseWriter, req *http.Request)
+	}
+)
+
+func NewProductController(srv Service) Controller {
+	return &productController{
+		srv: srv,
+	}
+}
@@ -0,0 +1,150 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/constant"
+)
+
+func (p *productController) CreateProduct(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	var productRequest ProductRequest
+	err := common.DecodeJSONBody(writer, req, &productRequest)
+
+	if err != nil {
+		log.Println("validation decode json body -> ", err)
+		toErrorResponse(writer, errorValidation)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	result, err := p.srv.CreateProduct(ctx, &productRequest)
+	if err != nil {
+		toErrorResponse(writer, err)
+		return
+	}
+
+	common.ToSuccessResponse(writer, nil, result)
+}
+
+func (p *productController) UpdateProduct(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	var productRequest ProductRequest
+	err := common.DecodeJSONBody(writer, req, &productRequest)
+
+	if err != nil {
+		log.Println("validation decode json body -> ", err)
+		toErrorResponse(writer, errorValidation)
+		return
+	}
+
+	code := mux.Vars(req)["code"]
+	if productRequest.Code != "" && productRequest.Code != code {
+		toErrorResponse(writer, errorValidation)
+		return
+	}
+	productRequest.Code = code
+
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	result, err := p.srv.UpdateProduct(ctx, &productRequest)
+	if err != nil {
+		toErrorResponse(writer, err)
+		return
+	}
+
+	common.ToSuccessResponse(writer, nil, result)
+}
+
+func (p *productController) FindProducts(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	result, err := p.srv.FindProducts(ctx, req.URL.Query().Get("status"))
+	if err != nil {
+		toErrorResponse(writer, err)
+		return
+	}
+
+	common.ToSuccessResponse(writer, nil, result)
+}
+
+func (p *productController) FindProduct(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	version := 0
+	if query := req.URL.Query().Get("version"); query != "" {
+		parsed, err := strconv.Atoi(query)
+		if err != nil || parsed <= 0 {
+			toErrorResponse(writer, errorValidation)
+			return
+		}
+		version = parsed
+	}
+
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	result, err := p.srv.FindProduct(ctx, mux.Vars(req)["code"], version)
+	if err != nil {
+		toErrorResponse(writer, err)
+		return
+	}
+
+	common.ToSuccessResponse(writer, nil, result)
+}
+
+func (p *productController) RetireProduct(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	if err := p.srv.RetireProduct(ctx, mux.Vars(req)["code"]); err != nil {
+		toErrorResponse(writer, err)
+		return
+	}
+
+	common.ToSuccessResponse(writer, nil, nil)
+}
+
+func toErrorResponse(writer http.ResponseWriter, err error) {
+	billingErr := func() constant.BillingSrvHttpError {
+		switch {
+		case errors.Is(err, errorValidation):
+			return constant.Validation
+		case errors.Is(err, errorDataNotExists):
+			return constant.DataNotFound
+		case errors.Is(err, errorConflict):
+			return constant.ProductConflict
+		default:
+			return constant.GeneralError
+		}
+	}()
+
+	common.ToErrorResponse(
+		writer,
+		constant.HttpRc[billingErr],
+		constant.HttpRcDescription[billingErr],
+	)
+}
@@ -0,0 +1,81 @@
+package product
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+type (
+	productService struct {
+		productRepository repository.ProductRepository
+		generate          common.Generate
+	}
+
+	ProductRequest struct {
+		// Code is taken from the path when the product is updated.
+		Code           string          `json:"code,omitempty"`
+		Name           string          `json:"name,omitempty"`
+		MinAmount      decimal.Decimal `json:"min_amount"`
+		MaxAmount      decimal.Decimal `json:"max_amount"`
+		Tenors         []int           `json:"tenors,omitempty"`
+		Frequency      string          `json:"frequency,omitempty"`
+		InterestMethod string          `json:"interest_method,omitempty"`
+		AnnualRate     decimal.Decimal `json:"annual_rate"`
+		FeeRate        decimal.Decimal `json:"fee_rate"`
+		PenaltyRate    decimal.Decimal `json:"penalty_rate"`
+		PenaltyCap     decimal.Decimal `json:"penalty_cap"`
+		GraceDays      int             `json:"grace_days,omitempty"`
+		// DelinquencyThreshold is the missed installments tolerated before the customer is delinquent.
+		DelinquencyThreshold int `json:"delinquency_threshold,omitempty"`
+	}
+
+	ProductResponse struct {
+		ID                   uint64          `json:"id"`
+		Code                 string          `json:"code"`
+		ProductVersion       int             `json:"product_version"`
+		Name                 string          `json:"name"`
+		Status               string          `json:"status"`
+		MinAmount            decimal.Decimal `json:"min_amount"`
+		MaxAmount            decimal.Decimal `json:"max_amount"`
+		Tenors               []int           `json:"tenors"`
+		Frequency            string          `json:"frequency"`
+		InterestMethod       string          `json:"interest_method"`
+		AnnualRate           decimal.Decimal `json:"annual_rate"`
+		FeeRate              decimal.Decimal `json:"fee_rate"`
+		PenaltyRate          decimal.Decimal `json:"penalty_rate"`
+		PenaltyCap           decimal.Decimal `json:"penalty_cap"`
+		GraceDays            int             `json:"grace_days"`
+		DelinquencyThreshold int             `json:"delinquency_threshold"`
+		CreatedAt            time.Time       `json:"created_at"`
+	}
+
+	// Service manages the catalogue of the loan products. The product is versioned, every update is saved
+	// as the next version and the loans keep referencing the version they are issued by.
+	Service interface {
+		CreateProduct(ctx context.Context, request *ProductRequest) (*ProductResponse, error)
+
+		// UpdateProduct saves the request as the next version of the active product.
+		UpdateProduct(ctx context.Context, request *ProductRequest) (*ProductResponse, error)
+
+		// FindProducts returns the latest version of every product, filtered by the status when it is not empty.
+		FindProducts(ctx context.Context, status string) ([]*ProductResponse, error)
+
+		// FindProduct returns the version of the product, the latest one when the version is 0.
+		FindProduct(ctx context.Context, code string, version int) (*ProductResponse, error)
+
+		// RetireProduct stops offering the product, the loans issued by it are not changed.
+		RetireProduct(ctx context.Context, code string) error
+	}
+)
+
+func NewProductService(productRepository repository.ProductRepository) Service {
+	return &productService{
+		productRepository: productRepository,
+		generate:          common.NewGenerate(),
+	}
+}
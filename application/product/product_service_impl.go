@@ -0,0 +1,248 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"log"
+	"runtime/debug"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/calculator"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+var (
+	errorValidation    = errors.New("validation request")
+	errorFromDatabase  = errors.New("from database")
+	errorDataNotExists = errors.New("data is not exists")
+	errorConflict      = errors.New("product exists or changed meanwhile")
+)
+
+func (p *productService) CreateProduct(
+	ctx context.Context,
+	request *ProductRequest) (rsp *ProductResponse, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Println("unidentified error (yet)", string(debug.Stack()))
+			err = errorFromDatabase
+			return
+		}
+	}()
+
+	if err = validate(request); err != nil {
+		return nil, err
+	}
+
+	return p.save(ctx, request, 1)
+}
+
+func (p *productService) UpdateProduct(
+	ctx context.Context,
+	request *ProductRequest) (rsp *ProductResponse, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Println("unidentified error (yet)", string(debug.Stack()))
+			err = errorFromDatabase
+			return
+		}
+	}()
+
+	if err = validate(request); err != nil {
+		return nil, err
+	}
+
+	latest, err := p.findLatest(ctx, request.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	//the retired product is no longer offered, it is created again under the new code
+	if latest.Status != repository.ProductActive {
+		return nil, errorDataNotExists
+	}
+
+	return p.save(ctx, request, latest.ProductVersion+1)
+}
+
+func (p *productService) FindProducts(
+	ctx context.Context,
+	status string) ([]*ProductResponse, error) {
+	products, err := p.productRepository.FindProducts(
+		ctx, &repository.ProductFilter{
+			Status: status,
+			Latest: true,
+		},
+	)
+
+	if err != nil {
+		return nil, errorFromDatabase
+	}
+
+	rsp := make([]*ProductResponse, 0, len(products))
+	for _, product := range products {
+		rsp = append(rsp, toProductResponse(product))
+	}
+
+	return rsp, nil
+}
+
+func (p *productService) FindProduct(
+	ctx context.Context,
+	code string,
+	version int) (*ProductResponse, error) {
+	if version == 0 {
+		latest, err := p.findLatest(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+
+		return toProductResponse(latest), nil
+	}
+
+	products, err := p.productRepository.FindProducts(
+		ctx, &repository.ProductFilter{
+			Code:           code,
+			ProductVersion: version,
+		},
+	)
+
+	if err != nil {
+		return nil, errorFromDatabase
+	}
+
+	if len(products) == 0 {
+		return nil, errorDataNotExists
+	}
+
+	return toProductResponse(products[0]), nil
+}
+
+func (p *productService) RetireProduct(
+	ctx context.Context,
+	code string) error {
+	err := p.productRepository.RetireProduct(ctx, code)
+	if errors.Is(err, repository.ErrorNoRows) {
+		return errorDataNotExists
+	}
+
+	if err != nil {
+		return errorFromDatabase
+	}
+
+	return nil
+}
+
+func (p *productService) findLatest(
+	ctx context.Context,
+	code string) (*repository.ProductEntity, error) {
+	products, err := p.productRepository.FindProducts(
+		ctx, &repository.ProductFilter{
+			Code:   code,
+			Latest: true,
+		},
+	)
+
+	if err != nil {
+		return nil, errorFromDatabase
+	}
+
+	if len(products) == 0 {
+		return nil, errorDataNotExists
+	}
+
+	return products[0], nil
+}
+
+func (p *productService) save(
+	ctx context.Context,
+	request *ProductRequest,
+	version int) (*ProductResponse, error) {
+	now := p.generate.Time()
+	product := &repository.ProductEntity{
+		Code:                 request.Code,
+		ProductVersion:       version,
+		Name:                 request.Name,
+		Status:               repository.ProductActive,
+		MinAmount:            request.MinAmount,
+		MaxAmount:            request.MaxAmount,
+		Tenors:               request.Tenors,
+		Frequency:            request.Frequency,
+		InterestMethod:       request.InterestMethod,
+		AnnualRate:           request.AnnualRate,
+		FeeRate:              request.FeeRate,
+		PenaltyRate:          request.PenaltyRate,
+		PenaltyCap:           request.PenaltyCap,
+		GraceDays:            request.GraceDays,
+		DelinquencyThreshold: request.DelinquencyThreshold,
+		CreatedAt:            now,
+		Version:              0,
+		UpdatedAt:            now,
+	}
+
+	id, err := p.productRepository.SaveProduct(ctx, product)
+
+	//the code has been created or the product has been updated concurrently
+	if errors.Is(err, repository.ErrorDuplicate) {
+		return nil, errorConflict
+	}
+
+	if err != nil {
+		return nil, errorFromDatabase
+	}
+
+	product.ID = id
+
+	return toProductResponse(product), nil
+}
+
+func validate(request *ProductRequest) error {
+	if request.Code == "" || request.Name == "" || len(request.Tenors) == 0 {
+		return errorValidation
+	}
+
+	if !request.MinAmount.IsPositive() || request.MaxAmount.LessThan(request.MinAmount) {
+		return errorValidation
+	}
+
+	for _, tenor := range request.Tenors {
+		if tenor <= 0 {
+			return errorValidation
+		}
+	}
+
+	if !calculator.IsFrequency(request.Frequency) {
+		return errorValidation
+	}
+
+	if _, err := calculator.NewCalculator(request.InterestMethod); err != nil {
+		return errorValidation
+	}
+
+	if request.AnnualRate.IsNegative() || request.FeeRate.IsNegative() || request.PenaltyRate.IsNegative() ||
+		request.PenaltyCap.IsNegative() || request.GraceDays < 0 || request.DelinquencyThreshold < 0 {
+		return errorValidation
+	}
+
+	return nil
+}
+
+func toProductResponse(product *repository.ProductEntity) *ProductResponse {
+	return &ProductResponse{
+		ID:                   product.ID,
+		Code:                 product.Code,
+		ProductVersion:       product.ProductVersion,
+		Name:                 product.Name,
+		Status:               product.Status,
+		MinAmount:            product.MinAmount,
+		MaxAmount:            product.MaxAmount,
+		Tenors:               product.Tenors,
+		Frequency:            product.Frequency,
+		InterestMethod:       product.InterestMethod,
+		AnnualRate:           product.AnnualRate,
+		FeeRate:              product.FeeRate,
+		PenaltyRate:          product.PenaltyRate,
+		PenaltyCap:           product.PenaltyCap,
+		GraceDays:            product.GraceDays,
+		DelinquencyThreshold: product.DelinquencyThreshold,
+		CreatedAt:            product.CreatedAt,
+	}
+}
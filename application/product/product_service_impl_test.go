@@ -0,0 +1,346 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	mocksCommon "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/common"
+	mocksRepository "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/infrastructure/repository"
+)
+
+func newTestProductService(
+	mockProductRepo *mocksRepository.ProductRepository,
+	now time.Time) *productService {
+	mockGenerate := &mocksCommon.Generate{}
+	mockGenerate.On("Time").Return(now)
+
+	return &productService{
+		productRepository: mockProductRepo,
+		generate:          mockGenerate,
+	}
+}
+
+func newTestProductRequest() *ProductRequest {
+	return &ProductRequest{
+		Code:                 "MODAL-50W",
+		Name:                 "Modal 50 weeks",
+		MinAmount:            decimal.NewFromFloat(1000000),
+		MaxAmount:            decimal.NewFromFloat(5000000),
+		Tenors:               []int{25, 50},
+		Frequency:            "WEEKLY",
+		InterestMethod:       "FLAT",
+		AnnualRate:           decimal.NewFromFloat(0.24),
+		FeeRate:              decimal.NewFromFloat(0.1),
+		GraceDays:            3,
+		DelinquencyThreshold: 2,
+	}
+}
+
+func Test_productService_CreateProduct(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		request  func() *ProductRequest
+		wantErr  error
+		mockFunc func(mockProductRepo *mocksRepository.ProductRepository)
+	}{
+		{
+			name: "given valid product," +
+				"when createProduct," +
+				"then the first version is saved",
+			request: newTestProductRequest,
+			mockFunc: func(mockProductRepo *mocksRepository.ProductRepository) {
+				mockProductRepo.
+					On(
+						"SaveProduct", mock.Anything,
+						mock.MatchedBy(func(p *repository.ProductEntity) bool {
+							return p.Code == "MODAL-50W" &&
+								p.ProductVersion == 1 &&
+								p.Status == repository.ProductActive &&
+								p.CreatedAt.Equal(now)
+						})).
+					Return(uint64(7), nil).
+					Once()
+			},
+		},
+		{
+			name: "given max amount below min amount," +
+				"when createProduct," +
+				"then return error validation",
+			request: func() *ProductRequest {
+				request := newTestProductRequest()
+				request.MaxAmount = decimal.NewFromFloat(500000)
+				return request
+			},
+			wantErr:  errorValidation,
+			mockFunc: func(mockProductRepo *mocksRepository.ProductRepository) {},
+		},
+		{
+			name: "given unknown interest method," +
+				"when createProduct," +
+				"then return error validation",
+			request: func() *ProductRequest {
+				request := newTestProductRequest()
+				request.InterestMethod = "BALLOON"
+				return request
+			},
+			wantErr:  errorValidation,
+			mockFunc: func(mockProductRepo *mocksRepository.ProductRepository) {},
+		},
+		{
+			name: "given the code exists," +
+				"when createProduct," +
+				"then return error conflict",
+			request: newTestProductRequest,
+			wantErr: errorConflict,
+			mockFunc: func(mockProductRepo *mocksRepository.ProductRepository) {
+				mockProductRepo.
+					On("SaveProduct", mock.Anything, mock.Anything).
+					Return(uint64(0), repository.ErrorDuplicate).
+					Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockProductRepo := &mocksRepository.ProductRepository{}
+				tt.mockFunc(mockProductRepo)
+
+				p := newTestProductService(mockProductRepo, now)
+				got, err := p.CreateProduct(context.Background(), tt.request())
+
+				assert.Equal(t, tt.wantErr, err)
+				if tt.wantErr == nil {
+					assert.Equal(t, uint64(7), got.ID)
+					assert.Equal(t, 1, got.ProductVersion)
+				}
+				mockProductRepo.AssertExpectations(t)
+			})
+	}
+}
+
+func Test_productService_UpdateProduct(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	latest := func(status string) []*repository.ProductEntity {
+		return []*repository.ProductEntity{{ID: 7, Code: "MODAL-50W", ProductVersion: 2, Status: status}}
+	}
+
+	tests := []struct {
+		name     string
+		wantErr  error
+		mockFunc func(mockProductRepo *mocksRepository.ProductRepository)
+	}{
+		{
+			name: "given the active product," +
+				"when updateProduct," +
+				"then the next version is saved",
+			mockFunc: func(mockProductRepo *mocksRepository.ProductRepository) {
+				mockProductRepo.
+					On("FindProducts", mock.Anything, &repository.ProductFilter{Code: "MODAL-50W", Latest: true}).
+					Return(latest(repository.ProductActive), nil).
+					Once()
+
+				mockProductRepo.
+					On(
+						"SaveProduct", mock.Anything,
+						mock.MatchedBy(func(p *repository.ProductEntity) bool {
+							return p.ProductVersion == 3 && p.Status == repository.ProductActive
+						})).
+					Return(uint64(8), nil).
+					Once()
+			},
+		},
+		{
+			name: "given the product is not exists," +
+				"when updateProduct," +
+				"then return error data not exists",
+			wantErr: errorDataNotExists,
+			mockFunc: func(mockProductRepo *mocksRepository.ProductRepository) {
+				mockProductRepo.
+					On("FindProducts", mock.Anything, mock.Anything).
+					Return(nil, nil).
+					Once()
+			},
+		},
+		{
+			name: "given the retired product," +
+				"when updateProduct," +
+				"then return error data not exists",
+			wantErr: errorDataNotExists,
+			mockFunc: func(mockProductRepo *mocksRepository.ProductRepository) {
+				mockProductRepo.
+					On("FindProducts", mock.Anything, mock.Anything).
+					Return(latest(repository.ProductRetired), nil).
+					Once()
+			},
+		},
+		{
+			name: "given the product is updated concurrently," +
+				"when updateProduct," +
+				"then return error conflict",
+			wantErr: errorConflict,
+			mockFunc: func(mockProductRepo *mocksRepository.ProductRepository) {
+				mockProductRepo.
+					On("FindProducts", mock.Anything, mock.Anything).
+					Return(latest(repository.ProductActive), nil).
+					Once()
+
+				mockProductRepo.
+					On("SaveProduct", mock.Anything, mock.Anything).
+					Return(uint64(0), repository.ErrorDuplicate).
+					Once()
+			},
+		},
+		{
+			name: "given find products is failed," +
+				"when updateProduct," +
+				"then return error",
+			wantErr: errorFromDatabase,
+			mockFunc: func(mockProductRepo *mocksRepository.ProductRepository) {
+				mockProductRepo.
+					On("FindProducts", mock.Anything, mock.Anything).
+					Return(nil, errors.New("mock error")).
+					Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockProductRepo := &mocksRepository.ProductRepository{}
+				tt.mockFunc(mockProductRepo)
+
+				p := newTestProductService(mockProductRepo, now)
+				got, err := p.UpdateProduct(context.Background(), newTestProductRequest())
+
+				assert.Equal(t, tt.wantErr, err)
+				if tt.wantErr == nil {
+					assert.Equal(t, uint64(8), got.ID)
+					assert.Equal(t, 3, got.ProductVersion)
+				}
+				mockProductRepo.AssertExpectations(t)
+			})
+	}
+}
+
+func Test_productService_FindProduct(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     int
+		wantVersion int
+		wantErr     error
+		mockFunc    func(mockProductRepo *mocksRepository.ProductRepository)
+	}{
+		{
+			name: "given no version," +
+				"when findProduct," +
+				"then return the latest version",
+			wantVersion: 2,
+			mockFunc: func(mockProductRepo *mocksRepository.ProductRepository) {
+				mockProductRepo.
+					On("FindProducts", mock.Anything, &repository.ProductFilter{Code: "MODAL-50W", Latest: true}).
+					Return([]*repository.ProductEntity{{ID: 7, ProductVersion: 2}}, nil).
+					Once()
+			},
+		},
+		{
+			name: "given the version," +
+				"when findProduct," +
+				"then return the version",
+			version:     1,
+			wantVersion: 1,
+			mockFunc: func(mockProductRepo *mocksRepository.ProductRepository) {
+				mockProductRepo.
+					On("FindProducts", mock.Anything, &repository.ProductFilter{Code: "MODAL-50W", ProductVersion: 1}).
+					Return([]*repository.ProductEntity{{ID: 3, ProductVersion: 1}}, nil).
+					Once()
+			},
+		},
+		{
+			name: "given the version is not exists," +
+				"when findProduct," +
+				"then return error data not exists",
+			version: 9,
+			wantErr: errorDataNotExists,
+			mockFunc: func(mockProductRepo *mocksRepository.ProductRepository) {
+				mockProductRepo.
+					On("FindProducts", mock.Anything, mock.Anything).
+					Return(nil, nil).
+					Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockProductRepo := &mocksRepository.ProductRepository{}
+				tt.mockFunc(mockProductRepo)
+
+				p := newTestProductService(mockProductRepo, time.Now())
+				got, err := p.FindProduct(context.Background(), "MODAL-50W", tt.version)
+
+				assert.Equal(t, tt.wantErr, err)
+				if tt.wantErr == nil {
+					assert.Equal(t, tt.wantVersion, got.ProductVersion)
+				}
+				mockProductRepo.AssertExpectations(t)
+			})
+	}
+}
+
+func Test_productService_RetireProduct(t *testing.T) {
+	tests := []struct {
+		name    string
+		repoErr error
+		wantErr error
+	}{
+		{
+			name: "given the active product," +
+				"when retireProduct," +
+				"then return nil",
+		},
+		{
+			name: "given the product is not exists or retired," +
+				"when retireProduct," +
+				"then return error data not exists",
+			repoErr: repository.ErrorNoRows,
+			wantErr: errorDataNotExists,
+		},
+		{
+			name: "given retire is failed," +
+				"when retireProduct," +
+				"then return error",
+			repoErr: repository.ErrorFromDBLoan,
+			wantErr: errorFromDatabase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockProductRepo := &mocksRepository.ProductRepository{}
+				mockProductRepo.
+					On("RetireProduct", mock.Anything, "MODAL-50W").
+					Return(tt.repoErr).
+					Once()
+
+				p := newTestProductService(mockProductRepo, time.Now())
+				err := p.RetireProduct(context.Background(), "MODAL-50W")
+
+				assert.Equal(t, tt.wantErr, err)
+				mockProductRepo.AssertExpectations(t)
+			})
+	}
+}
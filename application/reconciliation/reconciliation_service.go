@@ -0,0 +1,52 @@
+package reconciliation
+
+import (
+	"context"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/statement"
+)
+
+type (
+	reconciliationService struct {
+		reconciliationRepository repository.ReconciliationRepository
+		paymentRepository        repository.PaymentRepository
+		virtualAccountRepository repository.VirtualAccountRepository
+		transaction              repository.Transaction
+		generate                 common.Generate
+	}
+
+	ReconcileRequest struct {
+		SourceFile string
+		Format     string
+		Lines      []*statement.Line
+	}
+
+	ReconcileResponse struct {
+		RunID string
+		Items []*repository.ReconciliationItemEntity
+		// Skipped is the debit lines, they are not repayment.
+		Skipped int
+	}
+
+	Service interface {
+		// Reconcile matches the credit lines of the bank statement against the recorded payments,
+		// by the reference first then by the virtual account number, and stores the result per line.
+		Reconcile(ctx context.Context, request *ReconcileRequest) (*ReconcileResponse, error)
+	}
+)
+
+func NewReconciliationService(
+	reconciliationRepository repository.ReconciliationRepository,
+	paymentRepository repository.PaymentRepository,
+	virtualAccountRepository repository.VirtualAccountRepository,
+	transaction repository.Transaction) Service {
+	return &reconciliationService{
+		reconciliationRepository: reconciliationRepository,
+		paymentRepository:        paymentRepository,
+		virtualAccountRepository: virtualAccountRepository,
+		transaction:              transaction,
+		generate:                 common.NewGenerate(),
+	}
+}
@@ -0,0 +1,219 @@
+package reconciliation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/shopspring/decimal"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/statement"
+)
+
+var (
+	errorValidation   = errors.New("validation request")
+	errorFromDatabase = errors.New("from database")
+)
+
+func (r *reconciliationService) Reconcile(
+	ctx context.Context,
+	request *ReconcileRequest) (*ReconcileResponse, error) {
+	if request.SourceFile == "" || request.Format == "" {
+		return nil, errorValidation
+	}
+
+	rsp := &ReconcileResponse{RunID: r.generate.Uuid()}
+	now := r.generate.Time()
+
+	//the credit of virtual account is matched once per run, the same transfer can't be matched twice
+	used := make(map[uint64]bool)
+
+	for _, line := range request.Lines {
+		if !line.Credit {
+			rsp.Skipped += 1
+			continue
+		}
+
+		item := &repository.ReconciliationItemEntity{
+			RunID:          rsp.RunID,
+			SourceFile:     filepath.Base(request.SourceFile),
+			Format:         request.Format,
+			LineNumber:     line.Number,
+			StatementDate:  line.Date,
+			Reference:      line.Reference,
+			VaNumber:       line.VaNumber,
+			Amount:         line.Amount,
+			RecordedAmount: decimal.NewFromFloat(float64(0)),
+			Status:         repository.ReconciliationUnmatched,
+			CreatedAt:      now,
+		}
+
+		if err := r.match(ctx, line, item, used); err != nil {
+			return nil, err
+		}
+
+		rsp.Items = append(rsp.Items, item)
+	}
+
+	if len(rsp.Items) == 0 {
+		return rsp, nil
+	}
+
+	errTx := r.transaction.WithTransaction(
+		ctx, func(tx *sql.Tx) error {
+			return r.reconciliationRepository.SaveItems(ctx, tx, rsp.Items...)
+		},
+	)
+
+	if errTx != nil {
+		log.Println("failed save reconciliation items -> ", errTx)
+		return nil, errorFromDatabase
+	}
+
+	return rsp, nil
+}
+
+func (r *reconciliationService) match(
+	ctx context.Context,
+	line *statement.Line,
+	item *repository.ReconciliationItemEntity,
+	used map[uint64]bool) error {
+	if line.Reference != "" {
+		matched, err := r.matchReference(ctx, line, item, used)
+		if err != nil || matched {
+			return err
+		}
+	}
+
+	if line.VaNumber != "" {
+		_, err := r.matchVaNumber(ctx, line, item, used)
+		return err
+	}
+
+	item.Note = "no recorded payment for the reference"
+	return nil
+}
+
+// matchReference looks for the credit of virtual account by the bank reference,
+// then the payment by the reference of the provider or the payment id.
+func (r *reconciliationService) matchReference(
+	ctx context.Context,
+	line *statement.Line,
+	item *repository.ReconciliationItemEntity,
+	used map[uint64]bool) (bool, error) {
+	credits, err := r.virtualAccountRepository.FindCredits(
+		ctx, &repository.VirtualAccountCreditFilter{Reference: line.Reference},
+	)
+
+	if err != nil {
+		return false, errorFromDatabase
+	}
+
+	if len(credits) > 0 {
+		used[credits[0].ID] = true
+		matchCredit(line, item, credits[0], repository.ReconciliationByReference)
+		return true, nil
+	}
+
+	for _, filter := range []*repository.PaymentFilter{
+		{ProviderReference: line.Reference},
+		{PaymentID: line.Reference},
+	} {
+		payments, errFind := r.paymentRepository.FindPayments(ctx, filter)
+		if errFind != nil {
+			return false, errorFromDatabase
+		}
+
+		if len(payments) > 0 {
+			matchPayment(line, item, payments[0])
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// matchVaNumber takes the unmatched credit of the virtual account with the same amount,
+// otherwise the first unmatched credit is reported as amount mismatch.
+func (r *reconciliationService) matchVaNumber(
+	ctx context.Context,
+	line *statement.Line,
+	item *repository.ReconciliationItemEntity,
+	used map[uint64]bool) (bool, error) {
+	credits, err := r.virtualAccountRepository.FindCredits(
+		ctx, &repository.VirtualAccountCreditFilter{Number: line.VaNumber},
+	)
+
+	if err != nil {
+		return false, errorFromDatabase
+	}
+
+	var candidate *repository.VirtualAccountCreditEntity
+	for _, credit := range credits {
+		if used[credit.ID] {
+			continue
+		}
+
+		if credit.Amount.Equal(line.Amount) {
+			candidate = credit
+			break
+		}
+
+		if candidate == nil {
+			candidate = credit
+		}
+	}
+
+	if candidate == nil {
+		item.Note = "no recorded credit for the virtual account"
+		return false, nil
+	}
+
+	used[candidate.ID] = true
+	matchCredit(line, item, candidate, repository.ReconciliationByVaNumber)
+	return true, nil
+}
+
+func matchCredit(
+	line *statement.Line,
+	item *repository.ReconciliationItemEntity,
+	credit *repository.VirtualAccountCreditEntity,
+	matchedBy string) {
+	item.MatchedBy = matchedBy
+	item.PaymentID = credit.PaymentID
+	item.RecordedAmount = credit.Amount
+	item.VaNumber = credit.Number
+	item.Status = compareAmount(line.Amount, credit.Amount)
+
+	if credit.Status != repository.VirtualAccountCreditApplied {
+		item.Note = fmt.Sprintf("credit %s is %s %s", credit.Reference, credit.Status, credit.FailureReason)
+	}
+}
+
+func matchPayment(
+	line *statement.Line,
+	item *repository.ReconciliationItemEntity,
+	payment *repository.PaymentEntity) {
+	item.MatchedBy = repository.ReconciliationByReference
+	item.PaymentID = payment.PaymentID
+	item.RecordedAmount = payment.Amount
+	item.Status = compareAmount(line.Amount, payment.Amount)
+
+	//the money is received but the payment is not paid, so it is not matched
+	if payment.Status != repository.PaymentPaid {
+		item.Status = repository.ReconciliationUnmatched
+		item.Note = fmt.Sprintf("payment %s is %s", payment.PaymentID, payment.Status)
+	}
+}
+
+func compareAmount(amount, recordedAmount decimal.Decimal) string {
+	if amount.Equal(recordedAmount) {
+		return repository.ReconciliationMatched
+	}
+
+	return repository.ReconciliationAmountMismatch
+}
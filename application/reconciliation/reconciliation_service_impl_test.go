@@ -0,0 +1,227 @@
+package reconciliation
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/statement"
+	mocksRepository "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/infrastructure/repository"
+)
+
+func Test_reconciliationService_Reconcile(t *testing.T) {
+	withTransaction := func(ctx context.Context, fn func(tx *sql.Tx) error) error {
+		return fn(nil)
+	}
+
+	date := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	credit := func(reference string, amount int64) *statement.Line {
+		return &statement.Line{Date: date, Credit: true, Reference: reference, Amount: decimal.NewFromInt(amount)}
+	}
+
+	tests := []struct {
+		name       string
+		line       *statement.Line
+		wantStatus string
+		wantBy     string
+		mockFunc   func(
+			mockPaymentRepo *mocksRepository.PaymentRepository,
+			mockVirtualAccountRepo *mocksRepository.VirtualAccountRepository)
+	}{
+		{
+			name: "given the reference of applied virtual account credit," +
+				"when reconcile," +
+				"then it is matched",
+			line:       credit("trf-1", 25),
+			wantStatus: repository.ReconciliationMatched,
+			wantBy:     repository.ReconciliationByReference,
+			mockFunc: func(
+				mockPaymentRepo *mocksRepository.PaymentRepository,
+				mockVirtualAccountRepo *mocksRepository.VirtualAccountRepository) {
+				mockVirtualAccountRepo.
+					On("FindCredits", mock.Anything, &repository.VirtualAccountCreditFilter{Reference: "trf-1"}).
+					Return([]*repository.VirtualAccountCreditEntity{
+						{
+							ID:        1,
+							Reference: "trf-1",
+							Amount:    decimal.NewFromInt(25),
+							PaymentID: "p-1",
+							Status:    repository.VirtualAccountCreditApplied,
+						},
+					}, nil).
+					Once()
+			},
+		},
+		{
+			name: "given the reference of the paid payment with different amount," +
+				"when reconcile," +
+				"then it is amount mismatch",
+			line:       credit("fake-1", 30),
+			wantStatus: repository.ReconciliationAmountMismatch,
+			wantBy:     repository.ReconciliationByReference,
+			mockFunc: func(
+				mockPaymentRepo *mocksRepository.PaymentRepository,
+				mockVirtualAccountRepo *mocksRepository.VirtualAccountRepository) {
+				mockVirtualAccountRepo.
+					On("FindCredits", mock.Anything, mock.Anything).
+					Return(nil, nil).
+					Once()
+
+				mockPaymentRepo.
+					On("FindPayments", mock.Anything, &repository.PaymentFilter{ProviderReference: "fake-1"}).
+					Return([]*repository.PaymentEntity{
+						{PaymentID: "p-1", Amount: decimal.NewFromInt(25), Status: repository.PaymentPaid},
+					}, nil).
+					Once()
+			},
+		},
+		{
+			name: "given the reference of the failed payment," +
+				"when reconcile," +
+				"then it is unmatched",
+			line:       credit("p-1", 25),
+			wantStatus: repository.ReconciliationUnmatched,
+			wantBy:     repository.ReconciliationByReference,
+			mockFunc: func(
+				mockPaymentRepo *mocksRepository.PaymentRepository,
+				mockVirtualAccountRepo *mocksRepository.VirtualAccountRepository) {
+				mockVirtualAccountRepo.
+					On("FindCredits", mock.Anything, mock.Anything).
+					Return(nil, nil).
+					Once()
+
+				mockPaymentRepo.
+					On("FindPayments", mock.Anything, &repository.PaymentFilter{ProviderReference: "p-1"}).
+					Return(nil, nil).
+					Once()
+
+				mockPaymentRepo.
+					On("FindPayments", mock.Anything, &repository.PaymentFilter{PaymentID: "p-1"}).
+					Return([]*repository.PaymentEntity{
+						{PaymentID: "p-1", Amount: decimal.NewFromInt(25), Status: repository.PaymentFailed},
+					}, nil).
+					Once()
+			},
+		},
+		{
+			name: "given only the virtual account number," +
+				"when reconcile," +
+				"then the credit with the same amount is matched",
+			line: &statement.Line{
+				Date:     date,
+				Credit:   true,
+				VaNumber: "3935800000000018",
+				Amount:   decimal.NewFromInt(25),
+			},
+			wantStatus: repository.ReconciliationMatched,
+			wantBy:     repository.ReconciliationByVaNumber,
+			mockFunc: func(
+				mockPaymentRepo *mocksRepository.PaymentRepository,
+				mockVirtualAccountRepo *mocksRepository.VirtualAccountRepository) {
+				mockVirtualAccountRepo.
+					On("FindCredits", mock.Anything, &repository.VirtualAccountCreditFilter{Number: "3935800000000018"}).
+					Return([]*repository.VirtualAccountCreditEntity{
+						{ID: 1, Amount: decimal.NewFromInt(10), Status: repository.VirtualAccountCreditApplied},
+						{ID: 2, Amount: decimal.NewFromInt(25), Status: repository.VirtualAccountCreditApplied},
+					}, nil).
+					Once()
+			},
+		},
+		{
+			name: "given unknown reference," +
+				"when reconcile," +
+				"then it is unmatched",
+			line:       credit("unknown", 25),
+			wantStatus: repository.ReconciliationUnmatched,
+			mockFunc: func(
+				mockPaymentRepo *mocksRepository.PaymentRepository,
+				mockVirtualAccountRepo *mocksRepository.VirtualAccountRepository) {
+				mockVirtualAccountRepo.
+					On("FindCredits", mock.Anything, mock.Anything).
+					Return(nil, nil).
+					Once()
+
+				mockPaymentRepo.
+					On("FindPayments", mock.Anything, mock.Anything).
+					Return(nil, nil).
+					Twice()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockReconciliationRepo := &mocksRepository.ReconciliationRepository{}
+				mockPaymentRepo := &mocksRepository.PaymentRepository{}
+				mockVirtualAccountRepo := &mocksRepository.VirtualAccountRepository{}
+				mockTransaction := &mocksRepository.Transaction{}
+
+				tt.mockFunc(mockPaymentRepo, mockVirtualAccountRepo)
+
+				mockTransaction.
+					On("WithTransaction", mock.Anything, mock.Anything).
+					Return(withTransaction).
+					Once()
+
+				mockReconciliationRepo.
+					On("SaveItems", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+
+				r := NewReconciliationService(mockReconciliationRepo, mockPaymentRepo, mockVirtualAccountRepo, mockTransaction)
+				got, err := r.Reconcile(
+					context.Background(), &ReconcileRequest{
+						SourceFile: "/tmp/statement.csv",
+						Format:     statement.FormatBank,
+						Lines: []*statement.Line{
+							tt.line,
+							{Date: date, Reference: "fee-1", Amount: decimal.NewFromInt(5)},
+						},
+					},
+				)
+
+				assert.Nil(t, err)
+				assert.Equal(t, 1, got.Skipped)
+				assert.Len(t, got.Items, 1)
+				assert.Equal(t, tt.wantStatus, got.Items[0].Status)
+				assert.Equal(t, tt.wantBy, got.Items[0].MatchedBy)
+				assert.Equal(t, "statement.csv", got.Items[0].SourceFile)
+
+				mockPaymentRepo.AssertExpectations(t)
+				mockVirtualAccountRepo.AssertExpectations(t)
+				mockReconciliationRepo.AssertExpectations(t)
+			})
+	}
+}
+
+func Test_WriteExceptions(t *testing.T) {
+	date := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	items := []*repository.ReconciliationItemEntity{
+		{LineNumber: 2, StatementDate: date, Reference: "trf-1", Amount: decimal.NewFromInt(25), Status: repository.ReconciliationMatched},
+		{
+			LineNumber:     3,
+			StatementDate:  date,
+			Reference:      "trf-2",
+			Amount:         decimal.NewFromInt(30),
+			RecordedAmount: decimal.NewFromInt(25),
+			PaymentID:      "p-2",
+			Status:         repository.ReconciliationAmountMismatch,
+		},
+	}
+
+	var buffer bytes.Buffer
+	assert.Nil(t, WriteExceptions(&buffer, items))
+
+	rows := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "3,2026-10-19,trf-2,,30.00,25.00,p-2,AMOUNT_MISMATCH,", rows[1])
+}
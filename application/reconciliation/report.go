@@ -0,0 +1,89 @@
+package reconciliation
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/shopspring/decimal"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+var exceptionHeader = []string{
+	"line_number", "statement_date", "reference", "va_number", "amount",
+	"recorded_amount", "payment_id", "status", "note",
+}
+
+// WriteExceptions writes the items which are not matched into csv, to be followed up by finance.
+func WriteExceptions(writer io.Writer, items []*repository.ReconciliationItemEntity) error {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(exceptionHeader); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if item.Status == repository.ReconciliationMatched {
+			continue
+		}
+
+		errWrite := csvWriter.Write(
+			[]string{
+				strconv.Itoa(item.LineNumber),
+				item.StatementDate.Format("2006-01-02"),
+				item.Reference,
+				item.VaNumber,
+				item.Amount.StringFixed(2),
+				item.RecordedAmount.StringFixed(2),
+				item.PaymentID,
+				item.Status,
+				item.Note,
+			},
+		)
+
+		if errWrite != nil {
+			return errWrite
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// WriteSummary writes the number of lines and the total amount per status as table.
+func WriteSummary(writer io.Writer, rsp *ReconcileResponse) error {
+	type total struct {
+		lines  int
+		amount decimal.Decimal
+	}
+
+	totals := make(map[string]*total)
+	for _, status := range []string{
+		repository.ReconciliationMatched,
+		repository.ReconciliationAmountMismatch,
+		repository.ReconciliationUnmatched,
+	} {
+		totals[status] = &total{}
+	}
+
+	for _, item := range rsp.Items {
+		totals[item.Status].lines += 1
+		totals[item.Status].amount = totals[item.Status].amount.Add(item.Amount)
+	}
+
+	tw := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "RUN\t%s\n", rsp.RunID)
+	_, _ = fmt.Fprintln(tw, "STATUS\tLINES\tAMOUNT")
+	for _, status := range []string{
+		repository.ReconciliationMatched,
+		repository.ReconciliationAmountMismatch,
+		repository.ReconciliationUnmatched,
+	} {
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%s\n", status, totals[status].lines, totals[status].amount.StringFixed(2))
+	}
+	_, _ = fmt.Fprintf(tw, "SKIPPED (DEBIT)\t%d\t\n", rsp.Skipped)
+
+	return tw.Flush()
+}
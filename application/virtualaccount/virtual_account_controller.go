@@ -0,0 +1,25 @@
+package virtualaccount
+
+import (
+	"net/http"
+)
+
+type (
+	virtualAccountController struct {
+		srv Service
+	}
+
+	Controller interface {
+		Issue(writer http.ResponseWriter, req *http.Request)
+
+		Lookup(writer http.ResponseWriter, req *http.Request)
+
+		Credit(writer http.ResponseWriter, req *http.Request)
+	}
+)
+
+func NewVirtualAccountController(srv Service) Controller {
+	return &virtualAccountController{
+		srv: srv,
+	}
+}
@@ -0,0 +1,120 @@
+package virtualaccount
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/constant"
+)
+
+const (
+	headerBankKey = "X-Bank-Key"
+)
+
+func (v *virtualAccountController) Issue(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	var issueRequest IssueRequest
+	err := common.DecodeJSONBody(writer, req, &issueRequest)
+
+	if err != nil {
+		log.Println("validation decode json body -> ", err)
+		toErrorResponse(writer, errorValidation)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	result, errIssue := v.srv.Issue(ctx, &issueRequest)
+	if errIssue != nil {
+		toErrorResponse(writer, errIssue)
+		return
+	}
+
+	common.ToSuccessResponse(writer, nil, result)
+}
+
+func (v *virtualAccountController) Lookup(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	query := mux.Vars(req)
+
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	result, err := v.srv.Lookup(
+		ctx, &LookupRequest{
+			BankCode: query["bankCode"],
+			Number:   query["vaNumber"],
+			BankKey:  req.Header.Get(headerBankKey),
+		},
+	)
+
+	if err != nil {
+		toErrorResponse(writer, err)
+		return
+	}
+
+	common.ToSuccessResponse(writer, nil, result)
+}
+
+func (v *virtualAccountController) Credit(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	var creditRequest CreditRequest
+	err := common.DecodeJSONBody(writer, req, &creditRequest)
+
+	if err != nil {
+		log.Println("validation decode json body -> ", err)
+		toErrorResponse(writer, errorValidation)
+		return
+	}
+
+	query := mux.Vars(req)
+	creditRequest.BankCode = query["bankCode"]
+	creditRequest.Number = query["vaNumber"]
+	creditRequest.BankKey = req.Header.Get(headerBankKey)
+
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	//the credit which can't be applied is still acknowledged, the bank should not send it again
+	result, errCredit := v.srv.Credit(ctx, &creditRequest)
+	if errCredit != nil {
+		toErrorResponse(writer, errCredit)
+		return
+	}
+
+	common.ToSuccessResponse(writer, nil, result)
+}
+
+func toErrorResponse(writer http.ResponseWriter, err error) {
+	billingErr := func() constant.BillingSrvHttpError {
+		switch {
+		case errors.Is(err, errorValidation):
+			return constant.Validation
+		case errors.Is(err, errorDataNotExists):
+			return constant.DataNotFound
+		case errors.Is(err, errorUnauthorized):
+			return constant.Unauthorized
+		default:
+			return constant.GeneralError
+		}
+	}()
+
+	common.ToErrorResponse(
+		writer,
+		constant.HttpRc[billingErr],
+		constant.HttpRcDescription[billingErr],
+	)
+}
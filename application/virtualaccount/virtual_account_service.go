@@ -0,0 +1,136 @@
+package virtualaccount
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/loan"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/virtualaccount"
+)
+
+const (
+	defaultMaxAttempts       = 10
+	defaultReclaimBatch      = 100
+	defaultReclaimInterval   = time.Minute
+	defaultProcessingTimeout = 5 * time.Minute
+)
+
+type (
+	virtualAccountService struct {
+		cfg                      configuration.Configuration
+		virtualAccountRepository repository.VirtualAccountRepository
+		loanRepository           repository.LoanRepository
+		generator                virtualaccount.Generator
+		loanSrv                  loan.Service
+		// clock tells the business date the installments are due as of, the same as the payment of the credit.
+		clock       common.Clock
+		generate    common.Generate
+		maxAttempts int
+		// processingTimeout is how long the credit stays PROCESSING before it is reclaimed, e.g. after a crash.
+		// It should be longer than the credit takes to be applied, otherwise it is applied twice concurrently.
+		processingTimeout time.Duration
+		reclaimBatch      int
+		reclaimInterval   time.Duration
+	}
+
+	IssueRequest struct {
+		UserID string `json:"user_id,omitempty"`
+		// BankCode is optional, the virtual account of every configured bank is issued when it is empty.
+		BankCode string `json:"bank_code,omitempty"`
+	}
+
+	VirtualAccountResponse struct {
+		BankCode string `json:"bank_code"`
+		Number   string `json:"va_number"`
+		UserID   string `json:"user_id"`
+	}
+
+	LookupRequest struct {
+		BankCode string
+		Number   string
+		BankKey  string
+	}
+
+	LookupResponse struct {
+		BankCode       string          `json:"bank_code"`
+		Number         string          `json:"va_number"`
+		UserID         string          `json:"user_id"`
+		DueAmount      decimal.Decimal `json:"due_amount"`
+		InstallmentIDs []uint64        `json:"installment_ids,omitempty"`
+	}
+
+	CreditRequest struct {
+		BankCode  string          `json:"-"`
+		Number    string          `json:"-"`
+		BankKey   string          `json:"-"`
+		Reference string          `json:"reference,omitempty"`
+		Amount    decimal.Decimal `json:"amount"`
+	}
+
+	CreditResponse struct {
+		Reference     string `json:"reference"`
+		Status        string `json:"status"`
+		PaymentID     string `json:"payment_id,omitempty"`
+		FailureReason string `json:"failure_reason,omitempty"`
+	}
+
+	Service interface {
+		// Issue returns the virtual account of the customer per bank, the same number is returned for the next call.
+		Issue(ctx context.Context, request *IssueRequest) ([]*VirtualAccountResponse, error)
+
+		// Lookup is the inquiry of the bank before the transfer, it returns the customer and the due amount.
+		Lookup(ctx context.Context, request *LookupRequest) (*LookupResponse, error)
+
+		// Credit applies the transfer received by the bank as payment through loan.Service,
+		// the credit which can't be applied is kept as UNAPPLIED to be reviewed, since the money is already received.
+		Credit(ctx context.Context, request *CreditRequest) (*CreditResponse, error)
+
+		// ReclaimCredits applies again the credits left PROCESSING longer than the timeout (e.g. by a crash),
+		// it returns the number of reclaimed.
+		ReclaimCredits(ctx context.Context) (int, error)
+
+		Run(ctx context.Context)
+	}
+)
+
+func NewVirtualAccountService(
+	cfg configuration.Configuration,
+	virtualAccountRepository repository.VirtualAccountRepository,
+	loanRepository repository.LoanRepository,
+	generator virtualaccount.Generator,
+	loanSrv loan.Service,
+	clock common.Clock) Service {
+	processingTimeout := time.Duration(cfg.GetInt("virtualaccount.credit.processing.timeout.seconds")) * time.Second
+	if processingTimeout <= 0 {
+		processingTimeout = defaultProcessingTimeout
+	}
+
+	reclaimBatch := int(cfg.GetInt("virtualaccount.credit.reclaim.batch"))
+	if reclaimBatch <= 0 {
+		reclaimBatch = defaultReclaimBatch
+	}
+
+	reclaimInterval := time.Duration(cfg.GetInt("virtualaccount.credit.reclaim.interval.seconds")) * time.Second
+	if reclaimInterval <= 0 {
+		reclaimInterval = defaultReclaimInterval
+	}
+
+	return &virtualAccountService{
+		cfg:                      cfg,
+		virtualAccountRepository: virtualAccountRepository,
+		loanRepository:           loanRepository,
+		generator:                generator,
+		loanSrv:                  loanSrv,
+		clock:                    clock,
+		generate:                 common.NewGenerate(),
+		maxAttempts:              defaultMaxAttempts,
+		processingTimeout:        processingTimeout,
+		reclaimBatch:             reclaimBatch,
+		reclaimInterval:          reclaimInterval,
+	}
+}
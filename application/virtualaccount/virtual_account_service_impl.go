@@ -0,0 +1,433 @@
+package virtualaccount
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/loan"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/constant"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/virtualaccount"
+)
+
+const (
+	maxFailureReason = 255
+)
+
+var (
+	errorValidation    = errors.New("validation request")
+	errorFromDatabase  = errors.New("from database")
+	errorDataNotExists = errors.New("data is not exists")
+	errorUnauthorized  = errors.New("unauthorized")
+)
+
+func (v *virtualAccountService) Issue(
+	ctx context.Context,
+	request *IssueRequest) (rsp []*VirtualAccountResponse, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Println("unidentified error (yet)", string(debug.Stack()))
+			err = errorFromDatabase
+			return
+		}
+	}()
+
+	if request.UserID == "" {
+		return nil, errorValidation
+	}
+
+	banks := v.generator.Banks()
+	if request.BankCode != "" {
+		banks = nil
+		for _, bank := range v.generator.Banks() {
+			if bank.Code == strings.ToLower(request.BankCode) {
+				banks = append(banks, bank)
+			}
+		}
+	}
+
+	if len(banks) == 0 {
+		return nil, errorDataNotExists
+	}
+
+	loans, errFindLoan := v.loanRepository.FindLoans(
+		ctx, &repository.LoanEntity{
+			Statuses: []repository.LoanStatus{repository.LoanPending, repository.LoanOverdue, repository.LoanPaid},
+			UserID:   request.UserID,
+		},
+	)
+
+	if errFindLoan != nil && !errors.Is(errFindLoan, repository.ErrorNoRows) {
+		return nil, errorFromDatabase
+	}
+
+	if len(loans) == 0 {
+		return nil, errorDataNotExists
+	}
+
+	for _, bank := range banks {
+		virtualAccount, errIssue := v.issue(ctx, bank, request.UserID)
+		if errIssue != nil {
+			return nil, errIssue
+		}
+
+		rsp = append(
+			rsp, &VirtualAccountResponse{
+				BankCode: virtualAccount.BankCode,
+				Number:   virtualAccount.Number,
+				UserID:   virtualAccount.UserID,
+			},
+		)
+	}
+
+	return rsp, nil
+}
+
+func (v *virtualAccountService) Lookup(
+	ctx context.Context,
+	request *LookupRequest) (rsp *LookupResponse, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Println("unidentified error (yet)", string(debug.Stack()))
+			err = errorFromDatabase
+			return
+		}
+	}()
+
+	virtualAccount, err := v.findVirtualAccount(ctx, request.BankCode, request.Number, request.BankKey)
+	if err != nil {
+		return nil, err
+	}
+
+	loans, errFindLoan := v.loanRepository.FindLoans(
+		ctx, &repository.LoanEntity{
+			Statuses: repository.LoanCollectible,
+			UserID:   virtualAccount.UserID,
+			DueDate:  v.clock.Now(),
+		},
+	)
+
+	if errFindLoan != nil && !errors.Is(errFindLoan, repository.ErrorNoRows) {
+		return nil, errorFromDatabase
+	}
+
+	rsp = &LookupResponse{
+		BankCode:  virtualAccount.BankCode,
+		Number:    virtualAccount.Number,
+		UserID:    virtualAccount.UserID,
+		DueAmount: decimal.NewFromFloat(float64(0)),
+	}
+
+	for _, val := range loans {
+		rsp.DueAmount = rsp.DueAmount.Add(val.Amount)
+		rsp.InstallmentIDs = append(rsp.InstallmentIDs, val.ID)
+	}
+
+	return rsp, nil
+}
+
+func (v *virtualAccountService) Credit(
+	ctx context.Context,
+	request *CreditRequest) (rsp *CreditResponse, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Println("unidentified error (yet)", string(debug.Stack()))
+			err = errorFromDatabase
+			return
+		}
+	}()
+
+	if request.Reference == "" || !request.Amount.IsPositive() {
+		return nil, errorValidation
+	}
+
+	virtualAccount, err := v.findVirtualAccount(ctx, request.BankCode, request.Number, request.BankKey)
+	if err != nil {
+		return nil, err
+	}
+
+	now := v.generate.Time()
+	errSave := v.virtualAccountRepository.SaveCredit(
+		ctx, &repository.VirtualAccountCreditEntity{
+			BankCode:  virtualAccount.BankCode,
+			Number:    virtualAccount.Number,
+			Reference: request.Reference,
+			UserID:    virtualAccount.UserID,
+			Amount:    request.Amount,
+			Status:    repository.VirtualAccountCreditReceived,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	)
+
+	if errSave != nil {
+		return nil, errorFromDatabase
+	}
+
+	//the bank retries the same notification, so the stored credit is applied only once
+	credits, errFind := v.virtualAccountRepository.FindCredits(
+		ctx, &repository.VirtualAccountCreditFilter{
+			BankCode:  virtualAccount.BankCode,
+			Reference: request.Reference,
+		},
+	)
+
+	if errFind != nil || len(credits) == 0 {
+		return nil, errorFromDatabase
+	}
+
+	credit := credits[0]
+	if credit.Status != repository.VirtualAccountCreditReceived {
+		return toCreditResponse(credit), nil
+	}
+
+	errClaim := v.virtualAccountRepository.UpdateCredit(
+		ctx, &repository.VirtualAccountCreditUpdate{
+			ID:         credit.ID,
+			FromStatus: repository.VirtualAccountCreditReceived,
+			Status:     repository.VirtualAccountCreditProcessing,
+		},
+	)
+
+	//claimed by another notification of the same transfer
+	if errors.Is(errClaim, repository.ErrorNoRows) {
+		credit.Status = repository.VirtualAccountCreditProcessing
+		return toCreditResponse(credit), nil
+	}
+
+	if errClaim != nil {
+		return nil, errorFromDatabase
+	}
+
+	return v.apply(ctx, credit)
+}
+
+func (v *virtualAccountService) ReclaimCredits(ctx context.Context) (reclaimed int, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Println("unidentified error (yet)", string(debug.Stack()))
+			err = errorFromDatabase
+			return
+		}
+	}()
+
+	staleBefore := v.generate.Time().Add(-v.processingTimeout)
+	credits, err := v.virtualAccountRepository.FindCredits(
+		ctx, &repository.VirtualAccountCreditFilter{
+			Status:        repository.VirtualAccountCreditProcessing,
+			UpdatedBefore: staleBefore,
+			Limit:         v.reclaimBatch,
+		},
+	)
+
+	if err != nil {
+		return 0, errorFromDatabase
+	}
+
+	for _, credit := range credits {
+		//touching the credit claims it, so it is reclaimed only once
+		errClaim := v.virtualAccountRepository.UpdateCredit(
+			ctx, &repository.VirtualAccountCreditUpdate{
+				ID:            credit.ID,
+				FromStatus:    repository.VirtualAccountCreditProcessing,
+				Status:        repository.VirtualAccountCreditProcessing,
+				UpdatedBefore: staleBefore,
+			},
+		)
+
+		//applied meanwhile or reclaimed by another instance
+		if errors.Is(errClaim, repository.ErrorNoRows) {
+			continue
+		}
+
+		if errClaim != nil {
+			return reclaimed, errorFromDatabase
+		}
+
+		log.Println("credit of virtual account is reclaimed -> ", credit.BankCode, credit.Reference)
+		if _, errApply := v.apply(ctx, credit); errApply != nil {
+			return reclaimed, errApply
+		}
+
+		reclaimed += 1
+	}
+
+	return reclaimed, nil
+}
+
+func (v *virtualAccountService) Run(ctx context.Context) {
+	ticker := time.NewTicker(v.reclaimInterval)
+	defer ticker.Stop()
+
+	for {
+		reclaimed, err := v.ReclaimCredits(ctx)
+		if err != nil {
+			log.Println("[Virtual Account Credit] failed reclaim credits -> ", err)
+		}
+
+		if reclaimed > 0 {
+			log.Println("[Virtual Account Credit] reclaimed credits -> ", reclaimed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// apply pays the installments with the credit, the credit ends up APPLIED or UNAPPLIED.
+func (v *virtualAccountService) apply(
+	ctx context.Context,
+	credit *repository.VirtualAccountCreditEntity) (*CreditResponse, error) {
+	update := &repository.VirtualAccountCreditUpdate{
+		ID:         credit.ID,
+		FromStatus: repository.VirtualAccountCreditProcessing,
+		Status:     repository.VirtualAccountCreditApplied,
+	}
+
+	payment, errPayment := v.loanSrv.Payment(
+		ctx, &loan.PaymentRequest{
+			UserID:            credit.UserID,
+			Amount:            credit.Amount.InexactFloat64(),
+			Channel:           repository.PaymentChannelVirtualAccount,
+			Provider:          credit.BankCode,
+			ProviderReference: credit.Reference,
+		},
+	)
+
+	if errPayment != nil {
+		log.Println("credit of virtual account is unapplied -> ", credit.BankCode, credit.Reference, errPayment)
+
+		update.Status = repository.VirtualAccountCreditUnapplied
+		update.FailureReason = truncate(constant.HttpRcDescription[loan.MapError(errPayment)], maxFailureReason)
+	} else {
+		update.PaymentID = payment.PaymentID
+	}
+
+	//the installments are settled meanwhile (e.g. restructured), the money is held by the payment in suspense
+	if errPayment == nil && payment.Status == repository.PaymentUnapplied {
+		log.Println("credit of virtual account is unapplied -> ", credit.BankCode, credit.Reference, payment.PaymentID)
+
+		update.Status = repository.VirtualAccountCreditUnapplied
+		update.FailureReason = truncate(payment.FailureReason, maxFailureReason)
+	}
+
+	if errUpdate := v.virtualAccountRepository.UpdateCredit(ctx, update); errUpdate != nil {
+		log.Println("failed update credit of virtual account -> ", credit.Reference, errUpdate)
+		return nil, errorFromDatabase
+	}
+
+	credit.Status = update.Status
+	credit.PaymentID = update.PaymentID
+	credit.FailureReason = update.FailureReason
+	return toCreditResponse(credit), nil
+}
+
+// issue returns the existing virtual account of the customer, otherwise the next free number is assigned.
+func (v *virtualAccountService) issue(
+	ctx context.Context,
+	bank *virtualaccount.Bank,
+	userID string) (*repository.VirtualAccountEntity, error) {
+	filter := &repository.VirtualAccountFilter{
+		BankCode: bank.Code,
+		UserID:   userID,
+	}
+
+	for attempt := 0; attempt < v.maxAttempts; attempt++ {
+		existing, err := v.virtualAccountRepository.FindVirtualAccounts(ctx, filter)
+		if err != nil {
+			return nil, errorFromDatabase
+		}
+
+		if len(existing) > 0 {
+			return existing[0], nil
+		}
+
+		number, err := v.generator.Number(bank.Code, userID, attempt)
+		if err != nil {
+			return nil, errorDataNotExists
+		}
+
+		now := v.generate.Time()
+		virtualAccount := &repository.VirtualAccountEntity{
+			BankCode:  bank.Code,
+			Number:    number,
+			UserID:    userID,
+			Status:    repository.VirtualAccountActive,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+
+		err = v.virtualAccountRepository.SaveVirtualAccount(ctx, virtualAccount)
+		if err == nil {
+			return virtualAccount, nil
+		}
+
+		//the number is taken by another customer, or it is issued concurrently for the same customer
+		if !errors.Is(err, repository.ErrorDuplicate) {
+			return nil, errorFromDatabase
+		}
+	}
+
+	log.Println("failed issue virtual account, no free number -> ", bank.Code, userID)
+	return nil, errorFromDatabase
+}
+
+func (v *virtualAccountService) findVirtualAccount(
+	ctx context.Context,
+	bankCode, number, bankKey string) (*repository.VirtualAccountEntity, error) {
+	bankCode = strings.ToLower(bankCode)
+
+	//every bank has its own key, the bank without key is rejected
+	apiKey := v.cfg.GetString("virtualaccount.bank." + bankCode + ".api.key")
+	if apiKey == "" || subtle.ConstantTimeCompare([]byte(bankKey), []byte(apiKey)) != 1 {
+		return nil, errorUnauthorized
+	}
+
+	if _, err := v.generator.Validate(bankCode, number); err != nil {
+		return nil, errorValidation
+	}
+
+	virtualAccounts, err := v.virtualAccountRepository.FindVirtualAccounts(
+		ctx, &repository.VirtualAccountFilter{
+			BankCode: bankCode,
+			Number:   number,
+		},
+	)
+
+	if err != nil {
+		return nil, errorFromDatabase
+	}
+
+	if len(virtualAccounts) == 0 {
+		return nil, errorDataNotExists
+	}
+
+	return virtualAccounts[0], nil
+}
+
+func toCreditResponse(credit *repository.VirtualAccountCreditEntity) *CreditResponse {
+	return &CreditResponse{
+		Reference:     credit.Reference,
+		Status:        credit.Status,
+		PaymentID:     credit.PaymentID,
+		FailureReason: credit.FailureReason,
+	}
+}
+
+func truncate(value string, max int) string {
+	if len(value) > max {
+		return value[:max]
+	}
+
+	return value
+}
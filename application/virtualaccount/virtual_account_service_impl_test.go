@@ -0,0 +1,572 @@
+package virtualaccount
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/loan"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/virtualaccount"
+	mocksLoan "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/application/loan"
+	mocksConfiguration "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/configuration"
+	mocksRepository "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/infrastructure/repository"
+	mocksVirtualAccount "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/infrastructure/virtualaccount"
+)
+
+func Test_virtualAccountService_Issue(t *testing.T) {
+	bca := &virtualaccount.Bank{Code: "bca", Prefix: "39358", Length: 16, CheckDigit: virtualaccount.CheckDigitLuhn}
+	issued := &repository.VirtualAccountEntity{BankCode: "bca", Number: "3935800000000018", UserID: "abc"}
+
+	tests := []struct {
+		name     string
+		request  *IssueRequest
+		want     []*VirtualAccountResponse
+		wantErr  error
+		mockFunc func(
+			mockRepo *mocksRepository.VirtualAccountRepository,
+			mockLoanRepo *mocksRepository.LoanRepository,
+			mockGenerator *mocksVirtualAccount.Generator)
+	}{
+		{
+			name: "given not passed the validation," +
+				"when issue," +
+				"then return error",
+			request: &IssueRequest{},
+			wantErr: errorValidation,
+		},
+		{
+			name: "given unknown bank," +
+				"when issue," +
+				"then return not exists",
+			request: &IssueRequest{UserID: "abc", BankCode: "xyz"},
+			wantErr: errorDataNotExists,
+		},
+		{
+			name: "given unknown customer," +
+				"when issue," +
+				"then return not exists",
+			request: &IssueRequest{UserID: "abc"},
+			wantErr: errorDataNotExists,
+			mockFunc: func(
+				mockRepo *mocksRepository.VirtualAccountRepository,
+				mockLoanRepo *mocksRepository.LoanRepository,
+				mockGenerator *mocksVirtualAccount.Generator) {
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return(nil, nil).
+					Once()
+			},
+		},
+		{
+			name: "given the virtual account is already issued," +
+				"when issue," +
+				"then return the same number",
+			request: &IssueRequest{UserID: "abc", BankCode: "BCA"},
+			want:    []*VirtualAccountResponse{{BankCode: "bca", Number: "3935800000000018", UserID: "abc"}},
+			mockFunc: func(
+				mockRepo *mocksRepository.VirtualAccountRepository,
+				mockLoanRepo *mocksRepository.LoanRepository,
+				mockGenerator *mocksVirtualAccount.Generator) {
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return([]*repository.LoanEntity{{ID: 1}}, nil).
+					Once()
+
+				mockRepo.
+					On("FindVirtualAccounts", mock.Anything, &repository.VirtualAccountFilter{BankCode: "bca", UserID: "abc"}).
+					Return([]*repository.VirtualAccountEntity{issued}, nil).
+					Once()
+			},
+		},
+		{
+			name: "given the number is taken by another customer," +
+				"when issue," +
+				"then the next attempt is issued",
+			request: &IssueRequest{UserID: "abc"},
+			want:    []*VirtualAccountResponse{{BankCode: "bca", Number: "3935800000000026", UserID: "abc"}},
+			mockFunc: func(
+				mockRepo *mocksRepository.VirtualAccountRepository,
+				mockLoanRepo *mocksRepository.LoanRepository,
+				mockGenerator *mocksVirtualAccount.Generator) {
+				mockLoanRepo.
+					On("FindLoans", mock.Anything, mock.Anything).
+					Return([]*repository.LoanEntity{{ID: 1}}, nil).
+					Once()
+
+				mockRepo.
+					On("FindVirtualAccounts", mock.Anything, mock.Anything).
+					Return(nil, nil).
+					Twice()
+
+				mockGenerator.
+					On("Number", "bca", "abc", 0).
+					Return("3935800000000018", nil).
+					Once()
+
+				mockRepo.
+					On("SaveVirtualAccount", mock.Anything, mock.Anything).
+					Return(repository.ErrorDuplicate).
+					Once()
+
+				mockGenerator.
+					On("Number", "bca", "abc", 1).
+					Return("3935800000000026", nil).
+					Once()
+
+				mockRepo.
+					On(
+						"SaveVirtualAccount", mock.Anything,
+						mock.MatchedBy(func(virtualAccount *repository.VirtualAccountEntity) bool {
+							return virtualAccount.Number == "3935800000000026" &&
+								virtualAccount.Status == repository.VirtualAccountActive
+						})).
+					Return(nil).
+					Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockRepo := &mocksRepository.VirtualAccountRepository{}
+				mockLoanRepo := &mocksRepository.LoanRepository{}
+				mockGenerator := &mocksVirtualAccount.Generator{}
+
+				mockCfg := &mocksConfiguration.Configuration{}
+				mockCfg.On("GetInt", mock.Anything).Return(int64(0))
+
+				mockGenerator.On("Banks").Return([]*virtualaccount.Bank{bca})
+				if tt.mockFunc != nil {
+					tt.mockFunc(mockRepo, mockLoanRepo, mockGenerator)
+				}
+
+				v := NewVirtualAccountService(mockCfg, mockRepo, mockLoanRepo, mockGenerator, nil, common.NewClock())
+				got, err := v.Issue(context.Background(), tt.request)
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.Equal(t, tt.want, got)
+				mockRepo.AssertExpectations(t)
+			})
+	}
+}
+
+func Test_virtualAccountService_Lookup(t *testing.T) {
+	virtualAccount := &repository.VirtualAccountEntity{BankCode: "bca", Number: "3935800000000018", UserID: "abc"}
+	//the business date is behind the wall clock, the lookup is due as of it like the payment of the credit
+	businessDate := time.Date(2026, 10, 5, 10, 0, 0, 0, time.UTC)
+
+	mockCfg := &mocksConfiguration.Configuration{}
+	mockRepo := &mocksRepository.VirtualAccountRepository{}
+	mockLoanRepo := &mocksRepository.LoanRepository{}
+	mockGenerator := &mocksVirtualAccount.Generator{}
+
+	mockCfg.On("GetString", "virtualaccount.bank.bca.api.key").Return("bank-secret")
+	mockCfg.On("GetInt", mock.Anything).Return(int64(0))
+	mockGenerator.On("Validate", "bca", "3935800000000018").Return(nil, nil)
+	mockRepo.
+		On("FindVirtualAccounts", mock.Anything, mock.Anything).
+		Return([]*repository.VirtualAccountEntity{virtualAccount}, nil).
+		Once()
+	mockLoanRepo.
+		On(
+			"FindLoans", mock.Anything, &repository.LoanEntity{
+				Statuses: repository.LoanCollectible,
+				UserID:   "abc",
+				DueDate:  businessDate,
+			}).
+		Return(
+			[]*repository.LoanEntity{
+				{ID: 1, Amount: decimal.NewFromInt(110000)},
+				{ID: 2, Amount: decimal.NewFromInt(110000)},
+			}, nil).
+		Once()
+
+	v := NewVirtualAccountService(
+		mockCfg, mockRepo, mockLoanRepo, mockGenerator, nil, common.NewFixedClock(businessDate))
+	got, err := v.Lookup(
+		context.Background(), &LookupRequest{BankCode: "bca", Number: "3935800000000018", BankKey: "bank-secret"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "220000", got.DueAmount.String())
+	assert.Equal(t, []uint64{1, 2}, got.InstallmentIDs)
+	mockLoanRepo.AssertExpectations(t)
+}
+
+func Test_virtualAccountService_Credit(t *testing.T) {
+	virtualAccount := &repository.VirtualAccountEntity{BankCode: "bca", Number: "3935800000000018", UserID: "abc"}
+	received := func() *repository.VirtualAccountCreditEntity {
+		return &repository.VirtualAccountCreditEntity{
+			ID:        1,
+			BankCode:  "bca",
+			Number:    "3935800000000018",
+			Reference: "trf-1",
+			UserID:    "abc",
+			Amount:    decimal.NewFromInt(25),
+			Status:    repository.VirtualAccountCreditReceived,
+		}
+	}
+
+	claimed := func(mockRepo *mocksRepository.VirtualAccountRepository) {
+		mockRepo.
+			On("SaveCredit", mock.Anything, mock.Anything).
+			Return(nil).
+			Once()
+
+		mockRepo.
+			On("FindCredits", mock.Anything, &repository.VirtualAccountCreditFilter{BankCode: "bca", Reference: "trf-1"}).
+			Return([]*repository.VirtualAccountCreditEntity{received()}, nil).
+			Once()
+
+		mockRepo.
+			On("UpdateCredit", mock.Anything, &repository.VirtualAccountCreditUpdate{
+				ID:         1,
+				FromStatus: repository.VirtualAccountCreditReceived,
+				Status:     repository.VirtualAccountCreditProcessing,
+			}).
+			Return(nil).
+			Once()
+	}
+
+	tests := []struct {
+		name     string
+		bankKey  string
+		want     *CreditResponse
+		wantErr  error
+		mockFunc func(
+			mockRepo *mocksRepository.VirtualAccountRepository,
+			mockLoanSrv *mocksLoan.Service)
+	}{
+		{
+			name: "given wrong key of the bank," +
+				"when credit," +
+				"then return unauthorized",
+			bankKey: "wrong",
+			wantErr: errorUnauthorized,
+		},
+		{
+			name: "given unknown virtual account," +
+				"when credit," +
+				"then return not exists",
+			bankKey: "bank-secret",
+			wantErr: errorDataNotExists,
+			mockFunc: func(
+				mockRepo *mocksRepository.VirtualAccountRepository,
+				mockLoanSrv *mocksLoan.Service) {
+				mockRepo.
+					On("FindVirtualAccounts", mock.Anything, mock.Anything).
+					Return(nil, nil).
+					Once()
+			},
+		},
+		{
+			name: "given the transfer pays the due installments," +
+				"when credit," +
+				"then the credit is applied",
+			bankKey: "bank-secret",
+			want:    &CreditResponse{Reference: "trf-1", Status: repository.VirtualAccountCreditApplied, PaymentID: "p-1"},
+			mockFunc: func(
+				mockRepo *mocksRepository.VirtualAccountRepository,
+				mockLoanSrv *mocksLoan.Service) {
+				mockRepo.
+					On("FindVirtualAccounts", mock.Anything, mock.Anything).
+					Return([]*repository.VirtualAccountEntity{virtualAccount}, nil).
+					Once()
+
+				claimed(mockRepo)
+
+				mockLoanSrv.
+					On("Payment", mock.Anything, &loan.PaymentRequest{
+						UserID:            "abc",
+						Amount:            25,
+						Channel:           repository.PaymentChannelVirtualAccount,
+						Provider:          "bca",
+						ProviderReference: "trf-1",
+					}).
+					Return(&loan.PaymentResponse{PaymentID: "p-1", Status: repository.PaymentPaid}, nil).
+					Once()
+
+				mockRepo.
+					On("UpdateCredit", mock.Anything, &repository.VirtualAccountCreditUpdate{
+						ID:         1,
+						FromStatus: repository.VirtualAccountCreditProcessing,
+						Status:     repository.VirtualAccountCreditApplied,
+						PaymentID:  "p-1",
+					}).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given the transfer can't pay the installments," +
+				"when credit," +
+				"then the credit is unapplied and acknowledged",
+			bankKey: "bank-secret",
+			want:    &CreditResponse{Reference: "trf-1", Status: repository.VirtualAccountCreditUnapplied},
+			mockFunc: func(
+				mockRepo *mocksRepository.VirtualAccountRepository,
+				mockLoanSrv *mocksLoan.Service) {
+				mockRepo.
+					On("FindVirtualAccounts", mock.Anything, mock.Anything).
+					Return([]*repository.VirtualAccountEntity{virtualAccount}, nil).
+					Once()
+
+				claimed(mockRepo)
+
+				mockLoanSrv.
+					On("Payment", mock.Anything, mock.Anything).
+					Return(nil, assert.AnError).
+					Once()
+
+				mockRepo.
+					On("UpdateCredit", mock.Anything, mock.MatchedBy(func(update *repository.VirtualAccountCreditUpdate) bool {
+						return update.Status == repository.VirtualAccountCreditUnapplied
+					})).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given the installments are no longer collectible when the payment is settled," +
+				"when credit," +
+				"then the credit is unapplied with the payment holding the money",
+			bankKey: "bank-secret",
+			want: &CreditResponse{
+				Reference:     "trf-1",
+				Status:        repository.VirtualAccountCreditUnapplied,
+				PaymentID:     "p-1",
+				FailureReason: "installments are no longer collectible",
+			},
+			mockFunc: func(
+				mockRepo *mocksRepository.VirtualAccountRepository,
+				mockLoanSrv *mocksLoan.Service) {
+				mockRepo.
+					On("FindVirtualAccounts", mock.Anything, mock.Anything).
+					Return([]*repository.VirtualAccountEntity{virtualAccount}, nil).
+					Once()
+
+				claimed(mockRepo)
+
+				mockLoanSrv.
+					On("Payment", mock.Anything, mock.Anything).
+					Return(&loan.PaymentResponse{
+						PaymentID:     "p-1",
+						Status:        repository.PaymentUnapplied,
+						FailureReason: "installments are no longer collectible",
+					}, nil).
+					Once()
+
+				mockRepo.
+					On("UpdateCredit", mock.Anything, &repository.VirtualAccountCreditUpdate{
+						ID:            1,
+						FromStatus:    repository.VirtualAccountCreditProcessing,
+						Status:        repository.VirtualAccountCreditUnapplied,
+						PaymentID:     "p-1",
+						FailureReason: "installments are no longer collectible",
+					}).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given the same transfer is already applied," +
+				"when credit," +
+				"then the payment is not made again",
+			bankKey: "bank-secret",
+			want:    &CreditResponse{Reference: "trf-1", Status: repository.VirtualAccountCreditApplied, PaymentID: "p-1"},
+			mockFunc: func(
+				mockRepo *mocksRepository.VirtualAccountRepository,
+				mockLoanSrv *mocksLoan.Service) {
+				mockRepo.
+					On("FindVirtualAccounts", mock.Anything, mock.Anything).
+					Return([]*repository.VirtualAccountEntity{virtualAccount}, nil).
+					Once()
+
+				mockRepo.
+					On("SaveCredit", mock.Anything, mock.Anything).
+					Return(nil).
+					Once()
+
+				applied := received()
+				applied.Status = repository.VirtualAccountCreditApplied
+				applied.PaymentID = "p-1"
+
+				mockRepo.
+					On("FindCredits", mock.Anything, mock.Anything).
+					Return([]*repository.VirtualAccountCreditEntity{applied}, nil).
+					Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockCfg := &mocksConfiguration.Configuration{}
+				mockRepo := &mocksRepository.VirtualAccountRepository{}
+				mockGenerator := &mocksVirtualAccount.Generator{}
+				mockLoanSrv := &mocksLoan.Service{}
+
+				mockCfg.On("GetString", "virtualaccount.bank.bca.api.key").Return("bank-secret")
+				mockGenerator.On("Validate", "bca", "3935800000000018").Return(nil, nil)
+				if tt.mockFunc != nil {
+					tt.mockFunc(mockRepo, mockLoanSrv)
+				}
+
+				v := &virtualAccountService{
+					cfg:                      mockCfg,
+					virtualAccountRepository: mockRepo,
+					generator:                mockGenerator,
+					loanSrv:                  mockLoanSrv,
+					generate:                 common.NewGenerate(),
+					maxAttempts:              defaultMaxAttempts,
+				}
+
+				got, err := v.Credit(
+					context.Background(), &CreditRequest{
+						BankCode:  "bca",
+						Number:    "3935800000000018",
+						BankKey:   tt.bankKey,
+						Reference: "trf-1",
+						Amount:    decimal.NewFromInt(25),
+					},
+				)
+
+				assert.Equal(t, tt.wantErr, err)
+				if tt.want != nil && got != nil {
+					assert.Equal(t, tt.want.Status, got.Status)
+					assert.Equal(t, tt.want.PaymentID, got.PaymentID)
+				} else {
+					assert.Equal(t, tt.want, got)
+				}
+
+				mockRepo.AssertExpectations(t)
+				mockLoanSrv.AssertExpectations(t)
+			})
+	}
+}
+
+func Test_virtualAccountService_ReclaimCredits(t *testing.T) {
+	stale := func() *repository.VirtualAccountCreditEntity {
+		return &repository.VirtualAccountCreditEntity{
+			ID:        1,
+			BankCode:  "bca",
+			Number:    "3935800000000018",
+			Reference: "trf-1",
+			UserID:    "abc",
+			Amount:    decimal.NewFromInt(25),
+			Status:    repository.VirtualAccountCreditProcessing,
+		}
+	}
+
+	reclaim := func(update *repository.VirtualAccountCreditUpdate) bool {
+		return update.ID == 1 && update.FromStatus == repository.VirtualAccountCreditProcessing &&
+			update.Status == repository.VirtualAccountCreditProcessing && !update.UpdatedBefore.IsZero()
+	}
+
+	tests := []struct {
+		name          string
+		want          int
+		wantErr       error
+		mockFunc      func(mockRepo *mocksRepository.VirtualAccountRepository, mockLoanSrv *mocksLoan.Service)
+		wantPaymentID string
+	}{
+		{
+			name: "given the credit left processing longer than the timeout," +
+				"when reclaimCredits," +
+				"then it is applied again",
+			want: 1,
+			mockFunc: func(mockRepo *mocksRepository.VirtualAccountRepository, mockLoanSrv *mocksLoan.Service) {
+				mockRepo.
+					On("FindCredits", mock.Anything, mock.MatchedBy(func(filter *repository.VirtualAccountCreditFilter) bool {
+						return filter.Status == repository.VirtualAccountCreditProcessing &&
+							!filter.UpdatedBefore.IsZero() && filter.Limit == defaultReclaimBatch
+					})).
+					Return([]*repository.VirtualAccountCreditEntity{stale()}, nil).
+					Once()
+
+				mockRepo.
+					On("UpdateCredit", mock.Anything, mock.MatchedBy(reclaim)).
+					Return(nil).
+					Once()
+
+				//the payment made before the crash is returned instead of a new one
+				mockLoanSrv.
+					On("Payment", mock.Anything, mock.MatchedBy(func(request *loan.PaymentRequest) bool {
+						return request.Channel == repository.PaymentChannelVirtualAccount && request.ProviderReference == "trf-1"
+					})).
+					Return(&loan.PaymentResponse{PaymentID: "p-1", Status: repository.PaymentPaid}, nil).
+					Once()
+
+				mockRepo.
+					On("UpdateCredit", mock.Anything, &repository.VirtualAccountCreditUpdate{
+						ID:         1,
+						FromStatus: repository.VirtualAccountCreditProcessing,
+						Status:     repository.VirtualAccountCreditApplied,
+						PaymentID:  "p-1",
+					}).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given the credit is reclaimed by another instance meanwhile," +
+				"when reclaimCredits," +
+				"then it is skipped",
+			want: 0,
+			mockFunc: func(mockRepo *mocksRepository.VirtualAccountRepository, mockLoanSrv *mocksLoan.Service) {
+				mockRepo.
+					On("FindCredits", mock.Anything, mock.Anything).
+					Return([]*repository.VirtualAccountCreditEntity{stale()}, nil).
+					Once()
+
+				mockRepo.
+					On("UpdateCredit", mock.Anything, mock.MatchedBy(reclaim)).
+					Return(repository.ErrorNoRows).
+					Once()
+			},
+		},
+		{
+			name: "given negative case find credits failed," +
+				"when reclaimCredits," +
+				"then return error",
+			wantErr: errorFromDatabase,
+			mockFunc: func(mockRepo *mocksRepository.VirtualAccountRepository, mockLoanSrv *mocksLoan.Service) {
+				mockRepo.
+					On("FindCredits", mock.Anything, mock.Anything).
+					Return(nil, repository.ErrorFromDBLoan).
+					Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockRepo := &mocksRepository.VirtualAccountRepository{}
+				mockLoanSrv := &mocksLoan.Service{}
+				tt.mockFunc(mockRepo, mockLoanSrv)
+
+				v := &virtualAccountService{
+					virtualAccountRepository: mockRepo,
+					loanSrv:                  mockLoanSrv,
+					generate:                 common.NewGenerate(),
+					processingTimeout:        defaultProcessingTimeout,
+					reclaimBatch:             defaultReclaimBatch,
+				}
+
+				got, err := v.ReclaimCredits(context.Background())
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.Equal(t, tt.want, got)
+				mockRepo.AssertExpectations(t)
+				mockLoanSrv.AssertExpectations(t)
+			})
+	}
+}
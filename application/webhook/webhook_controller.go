@@ -0,0 +1,27 @@
+package webhook
+
+import (
+	"net/http"
+)
+
+type (
+	webhookController struct {
+		srv Service
+	}
+
+	Controller interface {
+		CreateSubscription(writer http.ResponseWriter, req *http.Request)
+
+		FindSubscriptions(writer http.ResponseWriter, req *http.Request)
+
+		FindDeliveries(writer http.ResponseWriter, req *http.Request)
+
+		ReplayDelivery(writer http.ResponseWriter, req *http.Request)
+	}
+)
+
+func NewWebhookController(srv Service) Controller {
+	return &webhookController{
+		srv: srv,
+	}
+}
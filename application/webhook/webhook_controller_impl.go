@@ -0,0 +1,133 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/constant"
+)
+
+func (w *webhookController) CreateSubscription(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	var subscriptionRequest SubscriptionRequest
+	err := common.DecodeJSONBody(writer, req, &subscriptionRequest)
+
+	if err != nil {
+		log.Println("validation decode json body -> ", err)
+		toErrorResponse(writer, errorValidation)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	result, err := w.srv.CreateSubscription(ctx, &subscriptionRequest)
+	if err != nil {
+		toErrorResponse(writer, err)
+		return
+	}
+
+	common.ToSuccessResponse(writer, nil, result)
+}
+
+func (w *webhookController) FindSubscriptions(
+	writer http.ResponseWriter,
+	_ *http.Request) {
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	result, err := w.srv.FindSubscriptions(ctx)
+	if err != nil {
+		toErrorResponse(writer, err)
+		return
+	}
+
+	common.ToSuccessResponse(writer, nil, result)
+}
+
+func (w *webhookController) FindDeliveries(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	query := req.URL.Query()
+
+	request := FindDeliveriesRequest{
+		Status: query.Get("status"),
+		Limit:  100,
+	}
+
+	if subscriptionID := query.Get("subscription_id"); subscriptionID != "" {
+		parsed, err := strconv.ParseUint(subscriptionID, 10, 64)
+		if err != nil {
+			toErrorResponse(writer, errorValidation)
+			return
+		}
+		request.SubscriptionID = parsed
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed <= 0 {
+			toErrorResponse(writer, errorValidation)
+			return
+		}
+		request.Limit = parsed
+	}
+
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	result, err := w.srv.FindDeliveries(ctx, &request)
+	if err != nil {
+		toErrorResponse(writer, err)
+		return
+	}
+
+	common.ToSuccessResponse(writer, nil, result)
+}
+
+func (w *webhookController) ReplayDelivery(
+	writer http.ResponseWriter,
+	req *http.Request) {
+	deliveryID := mux.Vars(req)["deliveryID"]
+
+	ctx := context.Background()
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	if err := w.srv.ReplayDelivery(ctx, deliveryID); err != nil {
+		toErrorResponse(writer, err)
+		return
+	}
+
+	common.ToSuccessResponse(writer, nil, nil)
+}
+
+func toErrorResponse(writer http.ResponseWriter, err error) {
+	billingErr := func() constant.BillingSrvHttpError {
+		switch {
+		case errors.Is(err, errorValidation):
+			return constant.Validation
+		case errors.Is(err, errorDataNotExists):
+			return constant.DataNotFound
+		default:
+			return constant.GeneralError
+		}
+	}()
+
+	common.ToErrorResponse(
+		writer,
+		constant.HttpRc[billingErr],
+		constant.HttpRcDescription[billingErr],
+	)
+}
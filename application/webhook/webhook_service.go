@@ -0,0 +1,131 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/publisher"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/webhook"
+)
+
+const (
+	defaultMaxAttempts = 8
+	defaultBaseBackoff = 30 * time.Second
+	defaultMaxBackoff  = time.Hour
+	defaultBatchSize   = 50
+	defaultInterval    = 5 * time.Second
+	defaultTimeout     = 10 * time.Second
+)
+
+type (
+	webhookService struct {
+		webhookRepository repository.WebhookRepository
+		sender            webhook.Sender
+		generate          common.Generate
+		maxAttempts       int
+		baseBackoff       time.Duration
+		maxBackoff        time.Duration
+		batchSize         int
+		interval          time.Duration
+	}
+
+	SubscriptionRequest struct {
+		Partner    string   `json:"partner,omitempty"`
+		URL        string   `json:"url,omitempty"`
+		Secret     string   `json:"secret,omitempty"`
+		EventTypes []string `json:"event_types,omitempty"`
+	}
+
+	SubscriptionResponse struct {
+		ID         uint64   `json:"id"`
+		Partner    string   `json:"partner"`
+		URL        string   `json:"url"`
+		EventTypes []string `json:"event_types"`
+		Status     string   `json:"status"`
+	}
+
+	FindDeliveriesRequest struct {
+		SubscriptionID uint64 `json:"subscription_id,omitempty"`
+		Status         string `json:"status,omitempty"`
+		Limit          int    `json:"limit,omitempty"`
+	}
+
+	DeliveryResponse struct {
+		DeliveryID       string     `json:"delivery_id"`
+		SubscriptionID   uint64     `json:"subscription_id"`
+		EventID          string     `json:"event_id"`
+		EventType        string     `json:"event_type"`
+		Status           string     `json:"status"`
+		Attempts         int        `json:"attempts"`
+		NextAttemptAt    time.Time  `json:"next_attempt_at"`
+		LastResponseCode int        `json:"last_response_code,omitempty"`
+		LastError        string     `json:"last_error,omitempty"`
+		DeliveredAt      *time.Time `json:"delivered_at,omitempty"`
+	}
+
+	// Service fans out the published events into webhook deliveries (as publisher of the outbox relay)
+	// and dispatches them to the partners with HMAC-SHA256 signature.
+	Service interface {
+		publisher.Publisher
+
+		DispatchOnce(ctx context.Context) (int, error)
+
+		Run(ctx context.Context)
+
+		CreateSubscription(ctx context.Context, request *SubscriptionRequest) (*SubscriptionResponse, error)
+
+		FindSubscriptions(ctx context.Context) ([]*SubscriptionResponse, error)
+
+		FindDeliveries(ctx context.Context, request *FindDeliveriesRequest) ([]*DeliveryResponse, error)
+
+		ReplayDelivery(ctx context.Context, deliveryID string) error
+	}
+)
+
+func NewWebhookService(
+	cfg configuration.Configuration,
+	webhookRepository repository.WebhookRepository) Service {
+	maxAttempts := int(cfg.GetInt("webhook.retry.max.attempts"))
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	baseBackoff := time.Duration(cfg.GetInt("webhook.retry.base.seconds")) * time.Second
+	if baseBackoff <= 0 {
+		baseBackoff = defaultBaseBackoff
+	}
+
+	maxBackoff := time.Duration(cfg.GetInt("webhook.retry.max.seconds")) * time.Second
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+
+	batchSize := int(cfg.GetInt("webhook.dispatch.batch"))
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	interval := time.Duration(cfg.GetInt("webhook.dispatch.interval.seconds")) * time.Second
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	timeout := time.Duration(cfg.GetInt("webhook.timeout.seconds")) * time.Second
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &webhookService{
+		webhookRepository: webhookRepository,
+		sender:            webhook.NewHttpSender(timeout),
+		generate:          common.NewGenerate(),
+		maxAttempts:       maxAttempts,
+		baseBackoff:       baseBackoff,
+		maxBackoff:        maxBackoff,
+		batchSize:         batchSize,
+		interval:          interval,
+	}
+}
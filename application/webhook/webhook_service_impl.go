@@ -0,0 +1,394 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/event"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/publisher"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/webhook"
+)
+
+const (
+	HeaderSignature  = "X-Billing-Signature"
+	HeaderEvent      = "X-Billing-Event"
+	HeaderDeliveryID = "X-Billing-Delivery-Id"
+
+	maxLastError = 255
+)
+
+var (
+	errorValidation    = errors.New("validation request")
+	errorFromDatabase  = errors.New("from database")
+	errorDataNotExists = errors.New("data is not exists")
+)
+
+// Publish fans out the message into delivery per active subscription of the event type.
+func (w *webhookService) Publish(ctx context.Context, message *publisher.Message) error {
+	subscriptions, err := w.webhookRepository.FindSubscriptions(
+		ctx, &repository.WebhookSubscriptionFilter{
+			Status:    repository.WebhookSubscriptionActive,
+			EventType: message.Type,
+		},
+	)
+
+	if err != nil {
+		return errorFromDatabase
+	}
+
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(
+		&event.Envelope{
+			EventID:     message.ID,
+			EventType:   event.Type(message.Type),
+			AggregateID: message.Key,
+			OccurredAt:  message.OccurredAt,
+			Payload:     message.Payload,
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	now := w.generate.Time()
+	deliveries := make([]*repository.WebhookDeliveryEntity, 0, len(subscriptions))
+
+	for _, subscription := range subscriptions {
+		deliveries = append(
+			deliveries, &repository.WebhookDeliveryEntity{
+				DeliveryID:     w.generate.Uuid(),
+				SubscriptionID: subscription.ID,
+				EventID:        message.ID,
+				EventType:      message.Type,
+				Payload:        body,
+				Status:         repository.WebhookDeliveryPending,
+				Attempts:       0,
+				NextAttemptAt:  now,
+				CreatedAt:      now,
+				Version:        0,
+				UpdatedAt:      now,
+			},
+		)
+	}
+
+	if err = w.webhookRepository.SaveDeliveries(ctx, deliveries...); err != nil {
+		return errorFromDatabase
+	}
+
+	return nil
+}
+
+func (w *webhookService) DispatchOnce(ctx context.Context) (int, error) {
+	now := w.generate.Time()
+
+	deliveries, err := w.webhookRepository.FindDeliveries(
+		ctx, &repository.WebhookDeliveryFilter{
+			Statuses:  []string{repository.WebhookDeliveryPending, repository.WebhookDeliveryRetrying},
+			DueBefore: now,
+			Limit:     w.batchSize,
+		},
+	)
+
+	if err != nil {
+		return 0, errorFromDatabase
+	}
+
+	if len(deliveries) == 0 {
+		return 0, nil
+	}
+
+	subscriptions, err := w.webhookRepository.FindSubscriptions(ctx, &repository.WebhookSubscriptionFilter{})
+	if err != nil {
+		return 0, errorFromDatabase
+	}
+
+	subscriptionByID := make(map[uint64]*repository.WebhookSubscriptionEntity, len(subscriptions))
+	for _, subscription := range subscriptions {
+		subscriptionByID[subscription.ID] = subscription
+	}
+
+	delivered := 0
+	for _, delivery := range deliveries {
+		update := w.deliver(ctx, delivery, subscriptionByID[delivery.SubscriptionID])
+
+		if errUpdate := w.webhookRepository.UpdateDelivery(ctx, update); errUpdate != nil {
+			log.Println("failed update webhook delivery -> ", delivery.DeliveryID, errUpdate)
+			return delivered, errorFromDatabase
+		}
+
+		if update.Status == repository.WebhookDeliverySucceeded {
+			delivered += 1
+		}
+	}
+
+	return delivered, nil
+}
+
+func (w *webhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		delivered, err := w.DispatchOnce(ctx)
+		if err != nil {
+			log.Println("[Webhook Dispatcher] failed dispatch webhook -> ", err)
+		}
+
+		if delivered > 0 {
+			log.Println("[Webhook Dispatcher] delivered webhooks -> ", delivered)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *webhookService) CreateSubscription(
+	ctx context.Context,
+	request *SubscriptionRequest) (rsp *SubscriptionResponse, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Println("unidentified error (yet)", string(debug.Stack()))
+			err = errorFromDatabase
+			return
+		}
+	}()
+
+	if request.Partner == "" || request.Secret == "" || len(request.EventTypes) == 0 {
+		return nil, errorValidation
+	}
+
+	parsedURL, errParse := url.ParseRequestURI(request.URL)
+	if errParse != nil || (parsedURL.Scheme != "https" && parsedURL.Scheme != "http") {
+		return nil, errorValidation
+	}
+
+	now := w.generate.Time()
+	subscription := &repository.WebhookSubscriptionEntity{
+		Partner:    request.Partner,
+		URL:        request.URL,
+		Secret:     request.Secret,
+		EventTypes: request.EventTypes,
+		Status:     repository.WebhookSubscriptionActive,
+		CreatedAt:  now,
+		Version:    0,
+		UpdatedAt:  now,
+	}
+
+	id, errSave := w.webhookRepository.SaveSubscription(ctx, subscription)
+	if errSave != nil {
+		return nil, errorFromDatabase
+	}
+
+	subscription.ID = id
+
+	return toSubscriptionResponse(subscription), nil
+}
+
+func (w *webhookService) FindSubscriptions(
+	ctx context.Context) ([]*SubscriptionResponse, error) {
+	subscriptions, err := w.webhookRepository.FindSubscriptions(ctx, &repository.WebhookSubscriptionFilter{})
+	if err != nil {
+		return nil, errorFromDatabase
+	}
+
+	rsp := make([]*SubscriptionResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		rsp = append(rsp, toSubscriptionResponse(subscription))
+	}
+
+	return rsp, nil
+}
+
+func (w *webhookService) FindDeliveries(
+	ctx context.Context,
+	request *FindDeliveriesRequest) ([]*DeliveryResponse, error) {
+	filter := &repository.WebhookDeliveryFilter{
+		SubscriptionID: request.SubscriptionID,
+		Limit:          request.Limit,
+	}
+
+	if request.Status != "" {
+		filter.Statuses = []string{request.Status}
+	}
+
+	deliveries, err := w.webhookRepository.FindDeliveries(ctx, filter)
+	if err != nil {
+		return nil, errorFromDatabase
+	}
+
+	rsp := make([]*DeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		rsp = append(
+			rsp, &DeliveryResponse{
+				DeliveryID:       delivery.DeliveryID,
+				SubscriptionID:   delivery.SubscriptionID,
+				EventID:          delivery.EventID,
+				EventType:        delivery.EventType,
+				Status:           delivery.Status,
+				Attempts:         delivery.Attempts,
+				NextAttemptAt:    delivery.NextAttemptAt,
+				LastResponseCode: delivery.LastResponseCode,
+				LastError:        delivery.LastError,
+				DeliveredAt:      delivery.DeliveredAt,
+			},
+		)
+	}
+
+	return rsp, nil
+}
+
+// ReplayDelivery schedules the delivery to be sent again immediately with fresh attempts,
+// including the delivery which already succeeded or dead.
+func (w *webhookService) ReplayDelivery(ctx context.Context, deliveryID string) error {
+	if deliveryID == "" {
+		return errorValidation
+	}
+
+	deliveries, err := w.webhookRepository.FindDeliveries(
+		ctx, &repository.WebhookDeliveryFilter{
+			DeliveryID: deliveryID,
+			Limit:      1,
+		},
+	)
+
+	if err != nil {
+		return errorFromDatabase
+	}
+
+	if len(deliveries) == 0 {
+		return errorDataNotExists
+	}
+
+	errUpdate := w.webhookRepository.UpdateDelivery(
+		ctx, &repository.WebhookDeliveryUpdate{
+			ID:            deliveries[0].ID,
+			Status:        repository.WebhookDeliveryPending,
+			Attempts:      0,
+			NextAttemptAt: w.generate.Time(),
+		},
+	)
+
+	if errUpdate != nil {
+		return errorFromDatabase
+	}
+
+	return nil
+}
+
+func (w *webhookService) deliver(
+	ctx context.Context,
+	delivery *repository.WebhookDeliveryEntity,
+	subscription *repository.WebhookSubscriptionEntity) *repository.WebhookDeliveryUpdate {
+	now := w.generate.Time()
+	attempts := delivery.Attempts + 1
+
+	if subscription == nil || subscription.Status != repository.WebhookSubscriptionActive {
+		return &repository.WebhookDeliveryUpdate{
+			ID:            delivery.ID,
+			Status:        repository.WebhookDeliveryDead,
+			Attempts:      delivery.Attempts,
+			NextAttemptAt: delivery.NextAttemptAt,
+			LastError:     "subscription is not active",
+		}
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	statusCode, errSend := w.sender.Send(
+		ctx, &webhook.Request{
+			URL: subscription.URL,
+			Headers: map[string]string{
+				HeaderSignature:  Sign(subscription.Secret, timestamp, delivery.Payload),
+				HeaderEvent:      delivery.EventType,
+				HeaderDeliveryID: delivery.DeliveryID,
+			},
+			Body: delivery.Payload,
+		},
+	)
+
+	if errSend == nil && statusCode >= 200 && statusCode < 300 {
+		return &repository.WebhookDeliveryUpdate{
+			ID:               delivery.ID,
+			Status:           repository.WebhookDeliverySucceeded,
+			Attempts:         attempts,
+			NextAttemptAt:    delivery.NextAttemptAt,
+			LastResponseCode: statusCode,
+			DeliveredAt:      &now,
+		}
+	}
+
+	lastError := fmt.Sprintf("partner responds with status %d", statusCode)
+	if errSend != nil {
+		lastError = errSend.Error()
+	}
+
+	if len(lastError) > maxLastError {
+		lastError = lastError[:maxLastError]
+	}
+
+	status := repository.WebhookDeliveryRetrying
+	if attempts >= w.maxAttempts {
+		status = repository.WebhookDeliveryDead
+	}
+
+	return &repository.WebhookDeliveryUpdate{
+		ID:               delivery.ID,
+		Status:           status,
+		Attempts:         attempts,
+		NextAttemptAt:    now.Add(w.backoff(attempts)),
+		LastResponseCode: statusCode,
+		LastError:        lastError,
+	}
+}
+
+// backoff is exponential, base * 2^(attempts-1) capped by max backoff.
+func (w *webhookService) backoff(attempts int) time.Duration {
+	backoff := w.baseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= w.maxBackoff {
+			return w.maxBackoff
+		}
+	}
+
+	return backoff
+}
+
+// Sign returns the value of signature header, partner verifies it by computing
+// HMAC-SHA256 of "<t>.<body>" with the shared secret.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func toSubscriptionResponse(subscription *repository.WebhookSubscriptionEntity) *SubscriptionResponse {
+	return &SubscriptionResponse{
+		ID:         subscription.ID,
+		Partner:    subscription.Partner,
+		URL:        subscription.URL,
+		EventTypes: subscription.EventTypes,
+		Status:     subscription.Status,
+	}
+}
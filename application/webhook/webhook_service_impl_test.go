@@ -0,0 +1,311 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/publisher"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	mocksCommon "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/common"
+	mocksRepository "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/infrastructure/repository"
+	mocksWebhook "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/infrastructure/webhook"
+)
+
+func newTestWebhookService(
+	mockWebhookRepo *mocksRepository.WebhookRepository,
+	mockSender *mocksWebhook.Sender,
+	now time.Time) *webhookService {
+	mockGenerate := &mocksCommon.Generate{}
+	mockGenerate.On("Time").Return(now)
+	mockGenerate.On("Uuid").Return("delivery-1")
+
+	return &webhookService{
+		webhookRepository: mockWebhookRepo,
+		sender:            mockSender,
+		generate:          mockGenerate,
+		maxAttempts:       3,
+		baseBackoff:       30 * time.Second,
+		maxBackoff:        time.Hour,
+		batchSize:         defaultBatchSize,
+		interval:          defaultInterval,
+	}
+}
+
+func Test_webhookService_Publish(t *testing.T) {
+	now := time.Date(2024, 6, 23, 21, 0, 0, 0, time.UTC)
+	message := &publisher.Message{
+		ID:         "event-1",
+		Type:       "PaymentSucceeded",
+		Key:        "abc",
+		Payload:    []byte(`{"user_id":"abc"}`),
+		OccurredAt: now,
+	}
+
+	tests := []struct {
+		name     string
+		wantErr  error
+		mockFunc func(mockWebhookRepo *mocksRepository.WebhookRepository)
+	}{
+		{
+			name: "given no subscription of the event," +
+				"when publish," +
+				"then no delivery",
+			mockFunc: func(mockWebhookRepo *mocksRepository.WebhookRepository) {
+				mockWebhookRepo.
+					On("FindSubscriptions", mock.Anything, mock.Anything).
+					Return(nil, nil).
+					Once()
+			},
+		},
+		{
+			name: "given active subscription of the event," +
+				"when publish," +
+				"then delivery is saved",
+			mockFunc: func(mockWebhookRepo *mocksRepository.WebhookRepository) {
+				mockWebhookRepo.
+					On(
+						"FindSubscriptions", mock.Anything, &repository.WebhookSubscriptionFilter{
+							Status:    repository.WebhookSubscriptionActive,
+							EventType: "PaymentSucceeded",
+						}).
+					Return([]*repository.WebhookSubscriptionEntity{{ID: 7}}, nil).
+					Once()
+
+				mockWebhookRepo.
+					On(
+						"SaveDeliveries", mock.Anything,
+						mock.MatchedBy(func(d *repository.WebhookDeliveryEntity) bool {
+							return d.SubscriptionID == 7 &&
+								d.EventID == "event-1" &&
+								d.Status == repository.WebhookDeliveryPending &&
+								d.NextAttemptAt.Equal(now)
+						})).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "given find subscriptions is failed," +
+				"when publish," +
+				"then return error",
+			wantErr: errorFromDatabase,
+			mockFunc: func(mockWebhookRepo *mocksRepository.WebhookRepository) {
+				mockWebhookRepo.
+					On("FindSubscriptions", mock.Anything, mock.Anything).
+					Return(nil, errors.New("mock error")).
+					Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockWebhookRepo := &mocksRepository.WebhookRepository{}
+				tt.mockFunc(mockWebhookRepo)
+
+				w := newTestWebhookService(mockWebhookRepo, &mocksWebhook.Sender{}, now)
+				err := w.Publish(context.Background(), message)
+
+				assert.Equal(t, tt.wantErr, err)
+				mockWebhookRepo.AssertExpectations(t)
+			})
+	}
+}
+
+func Test_webhookService_DispatchOnce(t *testing.T) {
+	now := time.Date(2024, 6, 23, 21, 0, 0, 0, time.UTC)
+	subscription := &repository.WebhookSubscriptionEntity{
+		ID:     7,
+		URL:    "https://partner.example.com/hook",
+		Secret: "secret",
+		Status: repository.WebhookSubscriptionActive,
+	}
+
+	delivery := func(attempts int) *repository.WebhookDeliveryEntity {
+		return &repository.WebhookDeliveryEntity{
+			ID:             1,
+			DeliveryID:     "delivery-1",
+			SubscriptionID: 7,
+			EventType:      "PaymentSucceeded",
+			Payload:        []byte(`{}`),
+			Status:         repository.WebhookDeliveryRetrying,
+			Attempts:       attempts,
+			NextAttemptAt:  now,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		delivery   *repository.WebhookDeliveryEntity
+		statusCode int
+		sendErr    error
+		want       int
+		wantUpdate *repository.WebhookDeliveryUpdate
+	}{
+		{
+			name: "given partner responds 2xx," +
+				"when dispatchOnce," +
+				"then delivery succeeded",
+			delivery:   delivery(0),
+			statusCode: 200,
+			want:       1,
+			wantUpdate: &repository.WebhookDeliveryUpdate{
+				ID:               1,
+				Status:           repository.WebhookDeliverySucceeded,
+				Attempts:         1,
+				NextAttemptAt:    now,
+				LastResponseCode: 200,
+				DeliveredAt:      &now,
+			},
+		},
+		{
+			name: "given partner responds 5xx," +
+				"when dispatchOnce," +
+				"then delivery is retried with exponential backoff",
+			delivery:   delivery(1),
+			statusCode: 503,
+			want:       0,
+			wantUpdate: &repository.WebhookDeliveryUpdate{
+				ID:               1,
+				Status:           repository.WebhookDeliveryRetrying,
+				Attempts:         2,
+				NextAttemptAt:    now.Add(time.Minute),
+				LastResponseCode: 503,
+				LastError:        "partner responds with status 503",
+			},
+		},
+		{
+			name: "given partner is timeout on the last attempt," +
+				"when dispatchOnce," +
+				"then delivery is dead",
+			delivery: delivery(2),
+			sendErr:  errors.New("timeout"),
+			want:     0,
+			wantUpdate: &repository.WebhookDeliveryUpdate{
+				ID:            1,
+				Status:        repository.WebhookDeliveryDead,
+				Attempts:      3,
+				NextAttemptAt: now.Add(2 * time.Minute),
+				LastError:     "timeout",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockWebhookRepo := &mocksRepository.WebhookRepository{}
+				mockSender := &mocksWebhook.Sender{}
+
+				mockWebhookRepo.
+					On("FindDeliveries", mock.Anything, mock.Anything).
+					Return([]*repository.WebhookDeliveryEntity{tt.delivery}, nil).
+					Once()
+
+				mockWebhookRepo.
+					On("FindSubscriptions", mock.Anything, mock.Anything).
+					Return([]*repository.WebhookSubscriptionEntity{subscription}, nil).
+					Once()
+
+				mockSender.
+					On(
+						"Send", mock.Anything,
+						mock.MatchedBy(func(r interface{}) bool { return r != nil })).
+					Return(tt.statusCode, tt.sendErr).
+					Once()
+
+				mockWebhookRepo.
+					On("UpdateDelivery", mock.Anything, tt.wantUpdate).
+					Return(nil).
+					Once()
+
+				w := newTestWebhookService(mockWebhookRepo, mockSender, now)
+				got, err := w.DispatchOnce(context.Background())
+
+				assert.Nil(t, err)
+				assert.Equal(t, tt.want, got)
+				mockWebhookRepo.AssertExpectations(t)
+			})
+	}
+}
+
+func Test_webhookService_ReplayDelivery(t *testing.T) {
+	now := time.Date(2024, 6, 23, 21, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		deliveryID string
+		wantErr    error
+		mockFunc   func(mockWebhookRepo *mocksRepository.WebhookRepository)
+	}{
+		{
+			name: "given empty delivery id," +
+				"when replayDelivery," +
+				"then return error",
+			wantErr:  errorValidation,
+			mockFunc: func(mockWebhookRepo *mocksRepository.WebhookRepository) {},
+		},
+		{
+			name: "given delivery is not exists," +
+				"when replayDelivery," +
+				"then return error",
+			deliveryID: "delivery-1",
+			wantErr:    errorDataNotExists,
+			mockFunc: func(mockWebhookRepo *mocksRepository.WebhookRepository) {
+				mockWebhookRepo.
+					On("FindDeliveries", mock.Anything, mock.Anything).
+					Return(nil, nil).
+					Once()
+			},
+		},
+		{
+			name: "given dead delivery," +
+				"when replayDelivery," +
+				"then rescheduled with fresh attempts",
+			deliveryID: "delivery-1",
+			mockFunc: func(mockWebhookRepo *mocksRepository.WebhookRepository) {
+				mockWebhookRepo.
+					On("FindDeliveries", mock.Anything, mock.Anything).
+					Return([]*repository.WebhookDeliveryEntity{{ID: 1, Status: repository.WebhookDeliveryDead, Attempts: 8}}, nil).
+					Once()
+
+				mockWebhookRepo.
+					On(
+						"UpdateDelivery", mock.Anything, &repository.WebhookDeliveryUpdate{
+							ID:            1,
+							Status:        repository.WebhookDeliveryPending,
+							Attempts:      0,
+							NextAttemptAt: now,
+						}).
+					Return(nil).
+					Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockWebhookRepo := &mocksRepository.WebhookRepository{}
+				tt.mockFunc(mockWebhookRepo)
+
+				w := newTestWebhookService(mockWebhookRepo, &mocksWebhook.Sender{}, now)
+				err := w.ReplayDelivery(context.Background(), tt.deliveryID)
+
+				assert.Equal(t, tt.wantErr, err)
+				mockWebhookRepo.AssertExpectations(t)
+			})
+	}
+}
+
+func Test_Sign(t *testing.T) {
+	got := Sign("secret", "1719176400", []byte(`{"event_id":"event-1"}`))
+
+	assert.Equal(t, "t=1719176400,v1=5f1c31b52c0e1d524085bfe79abfcb689df82553eaee57997432b142c9d4d85d", got)
+}
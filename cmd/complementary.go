@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"context"
+	"database/sql"
 	"log"
+	"os"
 
 	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/calendar"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
 )
 
 const (
@@ -28,3 +33,48 @@ func fetchConfiguration() (
 
 	return cfg, cre
 }
+
+// loadCalendar loads the holidays of calendar.region from calendar.holiday.source :
+// file (default, the csv of calendar.holiday.file) or table (table holiday).
+func loadCalendar(cfg configuration.Configuration, masterDB *sql.DB) calendar.Calendar {
+	var holidays []*calendar.Holiday
+
+	switch source := cfg.GetString("calendar.holiday.source"); source {
+	case calendar.SourceTable:
+		entities, err := repository.NewHolidayRepository(masterDB).FindHolidays(context.Background(), calendar.Region(cfg))
+		if err != nil {
+			log.Println("[MAIN] error retrieving holidays from table")
+			panic(err)
+		}
+
+		for _, entity := range entities {
+			holidays = append(
+				holidays, &calendar.Holiday{Region: entity.Region, Date: entity.HolidayDate, Name: entity.Name},
+			)
+		}
+	default:
+		if source != "" && source != calendar.SourceFile {
+			log.Println("unknown holiday source, fallback to file -> ", source)
+		}
+
+		path := cfg.GetString("calendar.holiday.file")
+		if path == "" {
+			break
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			log.Println("[MAIN] error opening holiday file -> ", path)
+			panic(err)
+		}
+		defer file.Close()
+
+		holidays, err = calendar.ReadHolidays(file)
+		if err != nil {
+			log.Println("[MAIN] error reading holiday file -> ", path)
+			panic(err)
+		}
+	}
+
+	return calendar.NewCalendarFromConfig(cfg, holidays)
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/journal"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+var export = &cobra.Command{
+	Use:   "export",
+	Short: "Export the data of amartha billing service to the other system",
+	Long:  "Cobra CLI : export the data of Billing service into the file imported by the other system, e.g. the ERP",
+}
+
+var exportGL = &cobra.Command{
+	Use:   "gl",
+	Short: "Export the journal of the date into the general ledger",
+	Long: "Cobra CLI : aggregate the journal lines booked in the date per account and branch into the import file " +
+		"of the ERP (csv or json) together with its checksum manifest, the export of the same date gives the same file",
+	Run: func(cmd *cobra.Command, args []string) {
+		date, _ := cmd.Flags().GetString("date")
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+
+		//init configuration and credential
+		cfg, cre := fetchConfiguration()
+
+		//init database master
+		initDB := configuration.NewStoreImpl(cre)
+		masterDB, err := initDB.InitDBMaster()
+
+		if err != nil {
+			panic(err)
+		}
+
+		journalService := journal.NewJournalService(
+			cfg, repository.NewJournalRepository(masterDB), common.NewBusinessClock(cfg))
+
+		rsp, err := journalService.ExportGeneralLedger(
+			context.Background(), &journal.ExportRequest{
+				Date:   date,
+				Format: strings.ToLower(format),
+				Output: output,
+			},
+		)
+
+		if err != nil {
+			log.Println("[Export GL] failed export general ledger -> ", err)
+			os.Exit(1)
+		}
+
+		log.Println(
+			"[Export GL] ", rsp.Status, " -> ", rsp.File, ", rows -> ", rsp.Manifest.Rows,
+			", sha256 -> ", rsp.Manifest.Checksum,
+		)
+	},
+}
+
+func init() {
+	exportGL.Flags().String("date", "", "date of the journal (YYYY-MM-DD), the day should be over")
+	exportGL.Flags().String("format", journal.FormatCSV, "format of the file : csv or json")
+	exportGL.Flags().String("output", "", "directory of the file and its manifest, default the working directory")
+	_ = exportGL.MarkFlagRequired("date")
+
+	export.AddCommand(exportGL)
+}
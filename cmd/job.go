@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/accrual"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/eod"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/journal"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/overdue"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+var job = &cobra.Command{
+	Use:   "job",
+	Short: "Run the batch job of amartha billing service once",
+	Long:  "Cobra CLI : run the batch job of Billing service once, e.g. from the external scheduler",
+}
+
+var jobOverdue = &cobra.Command{
+	Use:   "overdue",
+	Short: "Mark the past due installments as overdue",
+	Long: "Cobra CLI : move the PENDING installments whose due date has passed to OVERDUE in chunks " +
+		"and record the InstallmentOverdue events into table outbox together with the accrual journal entries",
+	Run: func(cmd *cobra.Command, args []string) {
+		//init configuration and credential
+		cfg, cre := fetchConfiguration()
+
+		//init database master
+		initDB := configuration.NewStoreImpl(cre)
+		masterDB, err := initDB.InitDBMaster()
+
+		if err != nil {
+			panic(err)
+		}
+
+		overdueService := overdue.NewOverdueService(
+			cfg,
+			repository.NewLoanRepository(masterDB),
+			repository.NewOutboxRepository(masterDB),
+			repository.NewProductRepository(masterDB),
+			repository.NewDelinquencyRepository(masterDB),
+			repository.NewJournalRepository(masterDB),
+			repository.NewTransaction(masterDB),
+			common.NewBusinessClock(cfg),
+			loadCalendar(cfg, masterDB),
+		)
+
+		marked, err := overdueService.MarkOverdue(context.Background())
+		if err != nil {
+			log.Println("[Overdue Job] failed mark overdue -> ", err, ", marked before failed -> ", marked)
+			os.Exit(1)
+		}
+
+		log.Println("[Overdue Job] marked installments as overdue -> ", marked)
+	},
+}
+
+var jobEod = &cobra.Command{
+	Use:   "eod",
+	Short: "Close the business date by the end of day",
+	Long: "Cobra CLI : mark the overdue installments, accrue the penalty and the interest, snapshot the delinquency " +
+		"and export the general ledger of the business date in order, the failed run is resumed from the failed step",
+	Run: func(cmd *cobra.Command, args []string) {
+		date, _ := cmd.Flags().GetString("date")
+
+		//init configuration and credential
+		cfg, cre := fetchConfiguration()
+
+		//init database master
+		initDB := configuration.NewStoreImpl(cre)
+		masterDB, err := initDB.InitDBMaster()
+
+		if err != nil {
+			panic(err)
+		}
+
+		loanRepository := repository.NewLoanRepository(masterDB)
+		productRepository := repository.NewProductRepository(masterDB)
+		journalRepository := repository.NewJournalRepository(masterDB)
+		outboxRepository := repository.NewOutboxRepository(masterDB)
+		delinquencyRepository := repository.NewDelinquencyRepository(masterDB)
+		transaction := repository.NewTransaction(masterDB)
+		clock := common.NewBusinessClock(cfg)
+		businessDays := loadCalendar(cfg, masterDB)
+
+		eodService := eod.NewEodService(
+			cfg,
+			overdue.NewOverdueService(
+				cfg, loanRepository, outboxRepository, productRepository, delinquencyRepository, journalRepository,
+				transaction, clock, businessDays),
+			accrual.NewAccrualService(cfg, loanRepository, productRepository, journalRepository, transaction),
+			journal.NewJournalService(cfg, journalRepository, clock),
+			delinquencyRepository,
+			outboxRepository,
+			repository.NewEodRepository(masterDB),
+			transaction,
+			clock,
+			businessDays,
+		)
+
+		rsp, err := eodService.Close(context.Background(), &eod.CloseRequest{Date: date})
+		if err != nil {
+			log.Println("[EOD] failed close business date -> ", date, err)
+			os.Exit(1)
+		}
+
+		for _, step := range rsp.Steps {
+			log.Println("[EOD] ", step.Step, " -> ", step.Status, ", processed -> ", step.Processed)
+		}
+
+		log.Println("[EOD] business date -> ", rsp.BusinessDate, rsp.Status)
+	},
+}
+
+func init() {
+	jobEod.Flags().String("date", "", "business date to close (YYYY-MM-DD), default the day before the business date")
+
+	job.AddCommand(jobOverdue)
+	job.AddCommand(jobEod)
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/reconciliation"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/statement"
+)
+
+var reconcile = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Reconcile the bank statement against the recorded payments",
+	Long: "Cobra CLI : match the lines of bank statement (bank csv or mt940) against the recorded payments by reference " +
+		"or virtual account number, store the result into table reconciliation_item and write the exceptions csv",
+	Run: func(cmd *cobra.Command, args []string) {
+		file, _ := cmd.Flags().GetString("file")
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+
+		parser, err := statement.NewParser(strings.ToLower(format))
+		if err != nil {
+			log.Println("[Reconcile] format should be bank or mt940 -> ", format)
+			os.Exit(1)
+		}
+
+		source, err := os.Open(file)
+		if err != nil {
+			log.Println("[Reconcile] failed open statement -> ", err)
+			os.Exit(1)
+		}
+		defer source.Close()
+
+		lines, err := parser.Parse(source)
+		if err != nil {
+			log.Println("[Reconcile] failed parse statement -> ", err)
+			os.Exit(1)
+		}
+
+		//init configuration and credential
+		_, cre := fetchConfiguration()
+
+		//init database master
+		initDB := configuration.NewStoreImpl(cre)
+		masterDB, err := initDB.InitDBMaster()
+
+		if err != nil {
+			panic(err)
+		}
+
+		reconciliationService := reconciliation.NewReconciliationService(
+			repository.NewReconciliationRepository(masterDB),
+			repository.NewPaymentRepository(masterDB),
+			repository.NewVirtualAccountRepository(masterDB),
+			repository.NewTransaction(masterDB),
+		)
+
+		rsp, err := reconciliationService.Reconcile(
+			context.Background(), &reconciliation.ReconcileRequest{
+				SourceFile: file,
+				Format:     strings.ToLower(format),
+				Lines:      lines,
+			},
+		)
+
+		if err != nil {
+			log.Println("[Reconcile] failed reconcile statement -> ", err)
+			os.Exit(1)
+		}
+
+		if output == "" {
+			output = strings.TrimSuffix(file, ".csv") + ".exceptions.csv"
+		}
+
+		exceptions, err := os.Create(output)
+		if err != nil {
+			log.Println("[Reconcile] failed create exceptions -> ", err)
+			os.Exit(1)
+		}
+		defer exceptions.Close()
+
+		if errWrite := reconciliation.WriteExceptions(exceptions, rsp.Items); errWrite != nil {
+			log.Println("[Reconcile] failed write exceptions -> ", errWrite)
+			os.Exit(1)
+		}
+
+		_ = reconciliation.WriteSummary(os.Stdout, rsp)
+		log.Println("[Reconcile] exceptions are written into -> ", output)
+	},
+}
+
+func init() {
+	reconcile.Flags().String("file", "", "path of the bank statement")
+	reconcile.Flags().String("format", statement.FormatBank, "format of the bank statement : bank or mt940")
+	reconcile.Flags().String("output", "", "path of the exceptions csv, default <file>.exceptions.csv")
+	_ = reconcile.MarkFlagRequired("file")
+}
@@ -31,6 +31,10 @@ func init() {
 	rootCmd.AddCommand(
 		serveDummy,
 		serveHttp,
+		serveRelay,
+		reconcile,
+		job,
+		export,
 	)
 }
 
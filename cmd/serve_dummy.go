@@ -9,8 +9,10 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/spf13/cobra"
 
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/journal"
 	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
 	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/calculator"
 	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
 )
 
@@ -31,59 +33,89 @@ var serveDummy = &cobra.Command{
 		}
 
 		loanRepository := repository.NewLoanRepository(masterDB)
+		productRepository := repository.NewProductRepository(masterDB)
+		journalRepository := repository.NewJournalRepository(masterDB)
+		chart := journal.NewChart(cfg)
+		businessDays := loadCalendar(cfg, masterDB)
 
 		numberOfCustomers := int(cfg.GetInt("custom.dummy.customers"))
-		numberOfWeeks := int(cfg.GetInt("custom.weeks"))
+
+		ctx := context.Background()
+		ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+		defer cancelFunc()
+
+		product, err := findDummyProduct(ctx, productRepository, cfg.GetString("custom.product.code"))
+		if err != nil {
+			panic(err)
+		}
+
+		loanCalculator, err := calculator.NewCalculator(product.InterestMethod)
+		if err != nil {
+			panic(err)
+		}
 
 		var loans []*repository.LoanEntity
+		var entries []*repository.JournalEntryEntity
 		for i := 0; i < numberOfCustomers; i++ {
 			userID := common.NewGenerate().Uuid()
 			currentTime := time.Date(2023, 8, 23, 18, 58, 0, 0, time.UTC)
 
-			amount := float64(5000000) / float64(numberOfWeeks)
-			amountWithFee := amount + (amount * 0.1)
-
-			le := repository.LoanEntity{
-				UserID:    userID,
-				Amount:    decimal.NewFromFloat(amountWithFee),
-				CreatedAt: currentTime,
-				Version:   0,
-				UpdatedAt: currentTime,
+			//the loan takes the maximum amount and the longest tenor of the product
+			schedule, errSchedule := loanCalculator.Schedule(
+				&calculator.ScheduleRequest{
+					Principal:    product.MaxAmount,
+					AnnualRate:   product.AnnualRate,
+					Tenor:        product.Tenors[len(product.Tenors)-1],
+					Frequency:    product.Frequency,
+					FirstDueDate: currentTime,
+					Calendar:     businessDays,
+				},
+			)
+
+			if errSchedule != nil {
+				panic(errSchedule)
 			}
 
-			for j := 0; j < numberOfWeeks; j++ {
-				d := currentTime.AddDate(0, 0, j*7)
-				status := func() string {
+			var customerLoans []*repository.LoanEntity
+			for j, installment := range schedule {
+				status := func() repository.LoanStatus {
 					//no outstanding
 					if i == 1 {
-						return "CLOSED"
+						return repository.LoanClosed
 					}
 
 					//partially paid
 					if j < 5 || i == 2 {
-						return "PAID"
+						return repository.LoanPaid
 					}
 
-					return "PENDING"
+					return repository.LoanPending
 				}()
 
-				loans = append(
-					loans, &repository.LoanEntity{
-						Status:    status,
-						UserID:    userID,
-						DueDate:   d,
-						Amount:    le.Amount,
+				fee := installment.Principal.Mul(product.FeeRate).Round(2)
+				customerLoans = append(
+					customerLoans, &repository.LoanEntity{
+						Status:  status,
+						UserID:  userID,
+						DueDate: installment.DueDate,
+						Amount:  installment.Amount.Add(fee),
+						Breakdown: repository.Breakdown{
+							Principal: installment.Principal,
+							Interest:  installment.Interest,
+							Fee:       fee,
+						},
 						CreatedAt: currentTime,
 						Version:   0,
 						UpdatedAt: currentTime,
+						ProductID: product.ID,
 					},
 				)
 			}
-		}
 
-		ctx := context.Background()
-		ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
-		defer cancelFunc()
+			//only the disbursement is booked, the seeded statuses are not booked as payments
+			loans = append(loans, customerLoans...)
+			entries = append(entries, chart.Disbursement(common.NewGenerate().Uuid(), userID, customerLoans, currentTime))
+		}
 
 		tx, errTx := masterDB.Begin()
 		defer commitOrRollback(tx, &errTx)
@@ -93,9 +125,64 @@ var serveDummy = &cobra.Command{
 			log.Println("failed during saveLoans -> ", errInsert)
 			return
 		}
+
+		errJournal := journalRepository.SaveEntries(ctx, tx, entries...)
+		if errJournal != nil {
+			log.Println("failed during saveEntries -> ", errJournal)
+			return
+		}
 	},
 }
 
+// findDummyProduct returns the latest active version of the product, the product of 50 weeks
+// with 10% fee is created when it is not exists yet.
+func findDummyProduct(
+	ctx context.Context,
+	productRepository repository.ProductRepository,
+	code string) (*repository.ProductEntity, error) {
+	products, err := productRepository.FindProducts(
+		ctx, &repository.ProductFilter{
+			Code:   code,
+			Status: repository.ProductActive,
+			Latest: true,
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(products) > 0 {
+		return products[0], nil
+	}
+
+	now := time.Now()
+	product := &repository.ProductEntity{
+		Code:                 code,
+		ProductVersion:       1,
+		Name:                 "Dummy 50 weeks",
+		Status:               repository.ProductActive,
+		MinAmount:            decimal.NewFromFloat(1000000),
+		MaxAmount:            decimal.NewFromFloat(5000000),
+		Tenors:               []int{50},
+		Frequency:            calculator.FrequencyWeekly,
+		InterestMethod:       calculator.MethodFlat,
+		AnnualRate:           decimal.Zero,
+		FeeRate:              decimal.NewFromFloat(0.1),
+		DelinquencyThreshold: 2,
+		CreatedAt:            now,
+		Version:              0,
+		UpdatedAt:            now,
+	}
+
+	product.ID, err = productRepository.SaveProduct(ctx, product)
+	if err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
 func commitOrRollback(tx *sql.Tx, sqlErr *error) {
 	var err error
 	if *sqlErr != nil {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"log"
+	"net"
 	http2 "net/http"
 	"os"
 	"os/signal"
@@ -11,11 +12,26 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/spf13/cobra"
+	grpc2 "google.golang.org/grpc"
 
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/accrual"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/eod"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/journal"
 	"gitlab.com/2024/Juni/amartha-billing-srv2/application/loan"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/overdue"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/payment"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/product"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/virtualaccount"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/webhook"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
 	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/delivery/grpc"
 	"gitlab.com/2024/Juni/amartha-billing-srv2/delivery/http"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/gateway"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/qris"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/ratelimit"
 	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+	virtualaccount2 "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/virtualaccount"
 )
 
 var serveHttp = &cobra.Command{
@@ -35,13 +51,84 @@ var serveHttp = &cobra.Command{
 		}
 
 		loanRepository := repository.NewLoanRepository(masterDB)
-		loanService := loan.NewLoanService(loanRepository)
+		outboxRepository := repository.NewOutboxRepository(masterDB)
+		delinquencyRepository := repository.NewDelinquencyRepository(masterDB)
+		webhookRepository := repository.NewWebhookRepository(masterDB)
+		paymentRepository := repository.NewPaymentRepository(masterDB)
+		paymentReversalRepository := repository.NewPaymentReversalRepository(masterDB)
+		payoffQuoteRepository := repository.NewPayoffQuoteRepository(masterDB)
+		loanRestructureRepository := repository.NewLoanRestructureRepository(masterDB)
+		loanWriteOffRepository := repository.NewLoanWriteOffRepository(masterDB)
+		paymentCallbackRepository := repository.NewPaymentCallbackRepository(masterDB)
+		virtualAccountRepository := repository.NewVirtualAccountRepository(masterDB)
+		productRepository := repository.NewProductRepository(masterDB)
+		journalRepository := repository.NewJournalRepository(masterDB)
+		eodRepository := repository.NewEodRepository(masterDB)
+		transaction := repository.NewTransaction(masterDB)
+
+		//the services share the clock, so the business date moved forward through the admin api is seen by all of them
+		clock := common.NewBusinessClock(cfg)
+		businessDays := loadCalendar(cfg, masterDB)
+
+		//the gateway confirms the pending debit asynchronously into the loan service
+		var loanService loan.Service
+		paymentGateway, err := gateway.NewPaymentGateway(
+			cfg, func(ctx context.Context, charge *gateway.Charge) {
+				if errSettle := loanService.SettlePayment(ctx, charge); errSettle != nil {
+					log.Println("failed settle payment from gateway -> ", charge.Reference, errSettle)
+				}
+			},
+		)
+
+		if err != nil {
+			panic(err)
+		}
+
+		qrisGenerator := qris.NewGenerator(cfg)
+
+		loanService = loan.NewLoanService(
+			cfg, loanRepository, outboxRepository, delinquencyRepository, paymentRepository, paymentReversalRepository,
+			payoffQuoteRepository, loanRestructureRepository, loanWriteOffRepository, productRepository, journalRepository,
+			transaction, paymentGateway, qrisGenerator, clock, businessDays)
 		loanController := loan.NewLoanController(loanService)
 
+		paymentService := payment.NewPaymentService(cfg, paymentCallbackRepository, paymentGateway, loanService)
+		paymentController := payment.NewPaymentController(paymentService)
+
+		virtualAccountService := virtualaccount.NewVirtualAccountService(
+			cfg, virtualAccountRepository, loanRepository, virtualaccount2.NewGenerator(cfg), loanService,
+			clock)
+		virtualAccountController := virtualaccount.NewVirtualAccountController(virtualAccountService)
+
+		overdueService := overdue.NewOverdueService(
+			cfg, loanRepository, outboxRepository, productRepository, delinquencyRepository, journalRepository,
+			transaction, clock, businessDays)
+
+		webhookService := webhook.NewWebhookService(cfg, webhookRepository)
+		webhookController := webhook.NewWebhookController(webhookService)
+
+		productService := product.NewProductService(productRepository)
+		productController := product.NewProductController(productService)
+
+		journalService := journal.NewJournalService(cfg, journalRepository, clock)
+		journalController := journal.NewJournalController(journalService)
+
+		accrualService := accrual.NewAccrualService(
+			cfg, loanRepository, productRepository, journalRepository, transaction)
+		eodService := eod.NewEodService(
+			cfg, overdueService, accrualService, journalService, delinquencyRepository, outboxRepository, eodRepository,
+			transaction, clock, businessDays)
+		eodController := eod.NewEodController(eodService)
+
+		//shared store (e.g. redis) should be plugged here when running more than one instance
+		rateLimitStore := ratelimit.NewMemoryStore()
+
 		billingHttpServerAddress := cfg.GetString("server.address.http")
 		router := mux.NewRouter()
 
-		billingHandler := http.NewBillingHandler(cfg, loanController).BuildHttp(router)
+		billingHandler := http.NewBillingHandler(
+			cfg, loanController, webhookController, paymentController, virtualAccountController, productController,
+			journalController, eodController, rateLimitStore).BuildHttp(router)
 		billingHttpServer := http2.Server{
 			Addr:    billingHttpServerAddress,
 			Handler: billingHandler,
@@ -58,14 +145,67 @@ var serveHttp = &cobra.Command{
 			}
 		}()
 
+		//gRPC is served alongside HTTP when the address is configured
+		billingGrpcServerAddress := cfg.GetString("server.address.grpc")
+		billingGrpcServer := grpc.NewBillingHandler(cfg, loanService).BuildGrpc(grpc2.NewServer())
+
+		if billingGrpcServerAddress != "" {
+			go func() {
+				listener, err := net.Listen("tcp", billingGrpcServerAddress)
+				if err != nil {
+					log.Println("error on listen grpc : " + err.Error())
+					return
+				}
+
+				log.Println(
+					"[Billing Service gRPC] server started. Listening on port",
+					billingGrpcServerAddress)
+
+				if err := billingGrpcServer.Serve(listener); err != nil &&
+					!errors.Is(err, grpc2.ErrServerStopped) {
+					log.Println("error on close grpc : " + err.Error())
+				}
+			}()
+		}
+
+		//the queued callbacks are applied once the payment is recorded
+		ctx, cancelFunc := context.WithCancel(context.Background())
+		defer cancelFunc()
+
+		go func() {
+			log.Println("[Payment Callback] started.")
+			paymentService.Run(ctx)
+			log.Println("[Payment Callback] stopped.")
+		}()
+
+		//the credits left processing (e.g. by a crash) are applied again
+		go func() {
+			log.Println("[Virtual Account Credit] started.")
+			virtualAccountService.Run(ctx)
+			log.Println("[Virtual Account Credit] stopped.")
+		}()
+
+		//the installments can be marked by the external scheduler instead (billingService job overdue)
+		if cfg.GetBool("overdue.job.enabled") {
+			go func() {
+				log.Println("[Overdue Job] started.")
+				overdueService.Run(ctx)
+				log.Println("[Overdue Job] stopped.")
+			}()
+		}
+
 		done := make(chan os.Signal, 1)
 		signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
 		<-done
+		cancelFunc()
 		if err := billingHttpServer.Shutdown(context.Background()); err != nil {
 			log.Println("[Billing Service HTTP], shutdown has error", err)
 		} else {
 			log.Println("[Billing Service HTTP] server stopped.")
 		}
+
+		billingGrpcServer.GracefulStop()
+		log.Println("[Billing Service gRPC] server stopped.")
 	},
 }
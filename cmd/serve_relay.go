@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/outbox"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/webhook"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/publisher"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+var serveRelay = &cobra.Command{
+	Use:   "serveRelay",
+	Short: "Turn on amartha billing service outbox relay and webhook dispatcher",
+	Long:  "Cobra CLI : turn on Billing service outbox relay, publish the domain events from table outbox and deliver the webhooks",
+	Run: func(cmd *cobra.Command, args []string) {
+		//init configuration and credential
+		cfg, cre := fetchConfiguration()
+
+		//init database master
+		initDB := configuration.NewStoreImpl(cre)
+		masterDB, err := initDB.InitDBMaster()
+
+		if err != nil {
+			panic(err)
+		}
+
+		outboxRepository := repository.NewOutboxRepository(masterDB)
+		webhookRepository := repository.NewWebhookRepository(masterDB)
+
+		//the webhook service fans out the events into deliveries for the partners
+		webhookService := webhook.NewWebhookService(cfg, webhookRepository)
+		relayService := outbox.NewRelayService(
+			cfg, outboxRepository,
+			publisher.NewMultiPublisher(publisher.NewPublisher(cfg), webhookService))
+
+		ctx, cancelFunc := context.WithCancel(context.Background())
+		defer cancelFunc()
+
+		go func() {
+			done := make(chan os.Signal, 1)
+			signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+
+			<-done
+			cancelFunc()
+		}()
+
+		go func() {
+			log.Println("[Webhook Dispatcher] started.")
+			webhookService.Run(ctx)
+			log.Println("[Webhook Dispatcher] stopped.")
+		}()
+
+		log.Println("[Outbox Relay] started.")
+		relayService.Run(ctx)
+		log.Println("[Outbox Relay] stopped.")
+	},
+}
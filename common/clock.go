@@ -0,0 +1,134 @@
+package common
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+)
+
+const (
+	ClockReal   = "real"
+	ClockFixed  = "fixed"
+	ClockOffset = "offset"
+)
+
+type (
+	// Clock tells the business time, the business date is the date of Now. Unlike Generate.Time,
+	// it is the time the financial processes (e.g. the end of day) run as of.
+	Clock interface {
+		Now() time.Time
+	}
+
+	// AdjustableClock is the clock which can be moved forward, used to simulate the days passing by.
+	AdjustableClock interface {
+		Clock
+
+		Mode() string
+
+		// Advance moves the clock forward by d and returns the time after.
+		Advance(d time.Duration) time.Time
+	}
+
+	clock struct {
+	}
+
+	fixedClock struct {
+		mu  sync.RWMutex
+		now time.Time
+	}
+
+	offsetClock struct {
+		mu     sync.RWMutex
+		offset time.Duration
+	}
+)
+
+func NewClock() Clock {
+	return &clock{}
+}
+
+// NewFixedClock returns the clock which stays at now until it is advanced.
+func NewFixedClock(now time.Time) AdjustableClock {
+	return &fixedClock{now: now}
+}
+
+// NewOffsetClock returns the clock which runs offset ahead of the real time.
+func NewOffsetClock(offset time.Duration) AdjustableClock {
+	return &offsetClock{offset: offset}
+}
+
+// NewBusinessClock returns the clock configured by clock.mode : real (default), fixed at clock.fixed.date
+// (YYYY-MM-DD or RFC3339) or offset by clock.offset.days from the real time.
+func NewBusinessClock(cfg configuration.Configuration) Clock {
+	switch mode := cfg.GetString("clock.mode"); mode {
+	case "", ClockReal:
+		return NewClock()
+	case ClockFixed:
+		fixed := cfg.GetString("clock.fixed.date")
+		now, err := time.ParseInLocation("2006-01-02", fixed, time.Local)
+		if err != nil {
+			now, err = time.Parse(time.RFC3339, fixed)
+		}
+
+		if err != nil {
+			log.Println("invalid clock.fixed.date, fallback to real clock -> ", fixed)
+			return NewClock()
+		}
+
+		return NewFixedClock(now)
+	case ClockOffset:
+		return NewOffsetClock(time.Duration(cfg.GetInt("clock.offset.days")) * 24 * time.Hour)
+	default:
+		log.Println("unknown clock mode, fallback to real clock -> ", mode)
+		return NewClock()
+	}
+}
+
+func (c *clock) Now() time.Time {
+	return time.Now()
+}
+
+func (f *fixedClock) Now() time.Time {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.now
+}
+
+func (f *fixedClock) Mode() string {
+	return ClockFixed
+}
+
+func (f *fixedClock) Advance(d time.Duration) time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+	return f.now
+}
+
+func (o *offsetClock) Now() time.Time {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	return time.Now().Add(o.offset)
+}
+
+func (o *offsetClock) Mode() string {
+	return ClockOffset
+}
+
+func (o *offsetClock) Advance(d time.Duration) time.Time {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.offset += d
+	return time.Now().Add(o.offset)
+}
+
+// StartOfDay returns the midnight of the date of t, in the location of t.
+func StartOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,110 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	mocksConfiguration "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/configuration"
+)
+
+func Test_NewBusinessClock(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      string
+		fixedDate string
+		offset    int64
+		wantMode  string
+		wantNow   time.Time
+	}{
+		{
+			name: "given no mode," +
+				"when new business clock," +
+				"then return real clock",
+			wantMode: ClockReal,
+		},
+		{
+			name: "given unknown mode," +
+				"when new business clock," +
+				"then return real clock",
+			mode:     "frozen",
+			wantMode: ClockReal,
+		},
+		{
+			name: "given fixed mode with date," +
+				"when new business clock," +
+				"then return clock fixed at the date",
+			mode:      ClockFixed,
+			fixedDate: "2026-10-19",
+			wantMode:  ClockFixed,
+			wantNow:   time.Date(2026, 10, 19, 0, 0, 0, 0, time.Local),
+		},
+		{
+			name: "given fixed mode with time," +
+				"when new business clock," +
+				"then return clock fixed at the time",
+			mode:      ClockFixed,
+			fixedDate: "2026-10-19T08:30:00Z",
+			wantMode:  ClockFixed,
+			wantNow:   time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "given fixed mode with invalid date," +
+				"when new business clock," +
+				"then return real clock",
+			mode:      ClockFixed,
+			fixedDate: "19-10-2026",
+			wantMode:  ClockReal,
+		},
+		{
+			name: "given offset mode," +
+				"when new business clock," +
+				"then return clock ahead by the days",
+			mode:     ClockOffset,
+			offset:   3,
+			wantMode: ClockOffset,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockCfg := &mocksConfiguration.Configuration{}
+				mockCfg.On("GetString", "clock.mode").Return(tt.mode)
+				mockCfg.On("GetString", "clock.fixed.date").Return(tt.fixedDate)
+				mockCfg.On("GetInt", "clock.offset.days").Return(tt.offset)
+
+				got := NewBusinessClock(mockCfg)
+
+				adjustable, ok := got.(AdjustableClock)
+				if tt.wantMode == ClockReal {
+					assert.False(t, ok)
+					return
+				}
+
+				assert.True(t, ok)
+				assert.Equal(t, tt.wantMode, adjustable.Mode())
+
+				switch tt.wantMode {
+				case ClockFixed:
+					assert.Equal(t, tt.wantNow, got.Now())
+				case ClockOffset:
+					ahead := got.Now().Sub(time.Now())
+					assert.InDelta(t, float64(time.Duration(tt.offset)*24*time.Hour), float64(ahead), float64(time.Minute))
+				}
+			},
+		)
+	}
+}
+
+func Test_AdjustableClock_Advance(t *testing.T) {
+	fixed := NewFixedClock(time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2026, 10, 21, 8, 0, 0, 0, time.UTC), fixed.Advance(48*time.Hour))
+	assert.Equal(t, time.Date(2026, 10, 21, 8, 0, 0, 0, time.UTC), fixed.Now())
+
+	offset := NewOffsetClock(24 * time.Hour)
+	advanced := offset.Advance(24 * time.Hour)
+	assert.InDelta(t, float64(48*time.Hour), float64(advanced.Sub(time.Now())), float64(time.Minute))
+	assert.InDelta(t, float64(48*time.Hour), float64(offset.Now().Sub(time.Now())), float64(time.Minute))
+}
@@ -0,0 +1,80 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type malformedRequestError struct {
+	message string
+}
+
+func (m malformedRequestError) Error() string {
+	return m.message
+}
+
+// DecodeJSONBody decodes the body of request into dst, unknown field is not allowed.
+func DecodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	contentType := r.Header.Get("Content-Type")
+
+	if contentType != "application/json" {
+		message := "Content-Type header is not application/json"
+		return &malformedRequestError{message: message}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1048576)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(&dst)
+	if err != nil {
+		var syntaxError *json.SyntaxError
+		var unmarshalTypeError *json.UnmarshalTypeError
+
+		switch {
+		case errors.As(err, &syntaxError):
+			message := fmt.Sprintf(
+				"Request body contains badly-formed JSON (at position %d)",
+				syntaxError.Offset)
+			return &malformedRequestError{message: message}
+
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			message := "Request body contains badly-formed JSON"
+			return &malformedRequestError{message: message}
+
+		case errors.As(err, &unmarshalTypeError):
+			message := fmt.Sprintf(
+				"Request body contains an invalid value for the %q field (at position %d)",
+				unmarshalTypeError.Field, unmarshalTypeError.Offset)
+			return &malformedRequestError{message: message}
+
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			fieldName := strings.TrimPrefix(err.Error(), "json: unknown field ")
+			message := fmt.Sprintf("Request body contains unknown field %s", fieldName)
+			return &malformedRequestError{message: message}
+
+		case errors.Is(err, io.EOF):
+			message := "Request body must not be empty"
+			return &malformedRequestError{message: message}
+
+		case err.Error() == "http: request body too large":
+			message := "Request body must not be larger than 1MB"
+			return &malformedRequestError{message: message}
+
+		default:
+			return &malformedRequestError{message: err.Error()}
+		}
+	}
+
+	err = dec.Decode(&struct{}{})
+	if err != io.EOF {
+		message := "Request body must only contain a single JSON object"
+		return &malformedRequestError{message: message}
+	}
+
+	return nil
+}
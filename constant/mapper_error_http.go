@@ -13,6 +13,17 @@ const (
 	GeneralError
 	PaymentAmountShouldBeEquals
 	ZeroOutstanding
+	TooManyRequests
+	Unauthorized
+	PaymentDeclined
+	PaymentInProgress
+	PaymentNotReversible
+	PayoffQuoteNotActive
+	InstallmentsChanged
+	WriteOffNotEligible
+	ProductConflict
+	FeatureDisabled
+	RequestTooLarge
 )
 
 var HttpRc = map[BillingSrvHttpError]string{
@@ -21,6 +32,17 @@ var HttpRc = map[BillingSrvHttpError]string{
 	DataNotFound:                "0002",
 	PaymentAmountShouldBeEquals: "0003",
 	ZeroOutstanding:             "0004",
+	TooManyRequests:             "0005",
+	Unauthorized:                "0006",
+	PaymentDeclined:             "0007",
+	PaymentInProgress:           "0008",
+	PaymentNotReversible:        "0009",
+	PayoffQuoteNotActive:        "0010",
+	InstallmentsChanged:         "0011",
+	WriteOffNotEligible:         "0012",
+	ProductConflict:             "0013",
+	FeatureDisabled:             "0014",
+	RequestTooLarge:             "0015",
 	GeneralError:                "9999",
 }
 
@@ -30,6 +52,17 @@ var HttpRcDescription = map[BillingSrvHttpError]string{
 	DataNotFound:                "data is not exist",
 	PaymentAmountShouldBeEquals: "amount of payment should be exact",
 	ZeroOutstanding:             "Congrats, you are not having any pending outstanding",
+	TooManyRequests:             "too many requests, please try again later",
+	Unauthorized:                "unauthorized",
+	PaymentDeclined:             "payment is declined by the payment gateway",
+	PaymentInProgress:           "previous payment is still in progress, please check the status",
+	PaymentNotReversible:        "payment is not paid or has been reversed",
+	PayoffQuoteNotActive:        "payoff quote is expired or used, please request a new one",
+	InstallmentsChanged:         "installments have been changed meanwhile, please try again",
+	WriteOffNotEligible:         "loan is not past due long enough to be written off",
+	ProductConflict:             "product already exists or has been changed meanwhile, please try again",
+	FeatureDisabled:             "feature is disabled in this environment",
+	RequestTooLarge:             "request body is too large",
 	GeneralError:                "General error",
 }
 
@@ -39,5 +72,16 @@ var BillingCodeToHttpCode = map[string]int{
 	"0002": http.StatusNotFound,
 	"0003": http.StatusBadRequest,
 	"0004": http.StatusOK,
+	"0005": http.StatusTooManyRequests,
+	"0006": http.StatusUnauthorized,
+	"0007": http.StatusPaymentRequired,
+	"0008": http.StatusConflict,
+	"0009": http.StatusConflict,
+	"0010": http.StatusConflict,
+	"0011": http.StatusConflict,
+	"0012": http.StatusConflict,
+	"0013": http.StatusConflict,
+	"0014": http.StatusForbidden,
+	"0015": http.StatusRequestEntityTooLarge,
 	"9999": http.StatusInternalServerError,
 }
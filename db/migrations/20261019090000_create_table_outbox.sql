@@ -0,0 +1,25 @@
+-- migrate:up
+create table outbox
+(
+    id           bigint auto_increment,
+    event_id     varchar(50)  not null COMMENT 'unique id of the event (uuid)',
+    event_type   varchar(50)  not null COMMENT 'PaymentSucceeded, InstallmentPaid, LoanClosed, CustomerBecameDelinquent',
+    aggregate_id varchar(50)  not null COMMENT 'user id of the customer',
+    payload      json         not null COMMENT 'payload of the event',
+    status       varchar(10)  not null COMMENT 'PENDING (not yet published), PUBLISHED, FAILED (max attempts reached)',
+    attempts     int          not null default 0 COMMENT 'number of publish attempts',
+    last_error   varchar(255) null COMMENT 'last error during publish',
+    occurred_at  timestamp    not null COMMENT 'time of the event occurred',
+    published_at timestamp    null COMMENT 'time of the event published',
+    created_at   timestamp    not null COMMENT 'created_at of the transaction',
+    version      int          not null COMMENT 'versioning',
+    updated_at   timestamp    not null on update current_timestamp COMMENT 'updated_at of the transaction',
+    constraint pk_id primary key (id),
+    constraint uq_event_id unique (event_id)
+);
+
+create index idx_status_attempts
+    on outbox (status, attempts);
+
+-- migrate:down
+drop table outbox;
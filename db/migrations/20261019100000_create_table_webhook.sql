@@ -0,0 +1,54 @@
+-- migrate:up
+create table customer_delinquency
+(
+    user_id       varchar(50) not null COMMENT 'user id of the customer',
+    is_delinquent tinyint(1)  not null COMMENT 'last known delinquency of the customer',
+    created_at    timestamp   not null COMMENT 'created_at of the transaction',
+    version       int         not null COMMENT 'versioning',
+    updated_at    timestamp   not null on update current_timestamp COMMENT 'updated_at of the transaction',
+    constraint pk_user_id primary key (user_id)
+);
+
+create table webhook_subscription
+(
+    id          bigint auto_increment,
+    partner     varchar(50)  not null COMMENT 'name of the partner, e.g. lender, mobile app backend',
+    url         varchar(255) not null COMMENT 'endpoint of the partner',
+    secret      varchar(100) not null COMMENT 'secret of HMAC-SHA256 signature',
+    event_types varchar(255) not null COMMENT 'comma separated event types subscribed',
+    status      varchar(10)  not null COMMENT 'ACTIVE, INACTIVE',
+    created_at  timestamp    not null COMMENT 'created_at of the transaction',
+    version     int          not null COMMENT 'versioning',
+    updated_at  timestamp    not null on update current_timestamp COMMENT 'updated_at of the transaction',
+    constraint pk_id primary key (id)
+);
+
+create table webhook_delivery
+(
+    id                 bigint auto_increment,
+    delivery_id        varchar(50)  not null COMMENT 'unique id of the delivery (uuid)',
+    subscription_id    bigint       not null COMMENT 'id of webhook_subscription',
+    event_id           varchar(50)  not null COMMENT 'event id of outbox',
+    event_type         varchar(50)  not null COMMENT 'type of the event',
+    payload            json         not null COMMENT 'body sent to the partner',
+    status             varchar(10)  not null COMMENT 'PENDING, RETRYING, SUCCEEDED, DEAD (max attempts reached)',
+    attempts           int          not null default 0 COMMENT 'number of delivery attempts',
+    next_attempt_at    timestamp    not null COMMENT 'time of the next attempt',
+    last_response_code int          null COMMENT 'last http status code from partner',
+    last_error         varchar(255) null COMMENT 'last error during delivery',
+    delivered_at       timestamp    null COMMENT 'time of the delivery succeeded',
+    created_at         timestamp    not null COMMENT 'created_at of the transaction',
+    version            int          not null COMMENT 'versioning',
+    updated_at         timestamp    not null on update current_timestamp COMMENT 'updated_at of the transaction',
+    constraint pk_id primary key (id),
+    constraint uq_delivery_id unique (delivery_id),
+    constraint uq_subscription_event unique (subscription_id, event_id)
+);
+
+create index idx_status_next_attempt_at
+    on webhook_delivery (status, next_attempt_at);
+
+-- migrate:down
+drop table webhook_delivery;
+drop table webhook_subscription;
+drop table customer_delinquency;
@@ -0,0 +1,25 @@
+-- migrate:up
+create table payment
+(
+    id                 bigint auto_increment,
+    payment_id         varchar(50)    not null COMMENT 'unique id of the payment (uuid), the reference sent to the provider',
+    user_id            varchar(50)    not null COMMENT 'user id of the customer',
+    amount             decimal(20, 2) not null COMMENT 'amount debited from the customer',
+    installment_ids    varchar(1000)  not null COMMENT 'comma separated id of loan paid by the payment',
+    status             varchar(15)    not null COMMENT 'PENDING_DEBIT (waiting confirmation of provider), PAID, FAILED',
+    provider           varchar(20)    not null COMMENT 'payment gateway provider',
+    provider_reference varchar(100)   null COMMENT 'reference of the charge in the provider',
+    failure_reason     varchar(255)   null COMMENT 'reason of the failed payment',
+    created_at         timestamp      not null COMMENT 'created_at of the transaction',
+    version            int            not null COMMENT 'versioning',
+    updated_at         timestamp      not null on update current_timestamp COMMENT 'updated_at of the transaction',
+    constraint pk_id primary key (id),
+    constraint uq_payment_id unique (payment_id),
+    constraint uq_provider_reference unique (provider, provider_reference)
+);
+
+create index idx_user_id_status
+    on payment (user_id, status);
+
+-- migrate:down
+drop table payment;
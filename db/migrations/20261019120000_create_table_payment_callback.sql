@@ -0,0 +1,26 @@
+-- migrate:up
+create table payment_callback
+(
+    id                 bigint auto_increment,
+    provider           varchar(20)    not null COMMENT 'payment gateway provider',
+    provider_reference varchar(100)   not null COMMENT 'reference of the charge in the provider',
+    reference          varchar(50)    null COMMENT 'payment id sent to the provider',
+    provider_status    varchar(15)    not null COMMENT 'status mapped from the provider : PENDING, SUCCESS, DECLINED, REFUNDED',
+    amount             decimal(20, 2) not null COMMENT 'amount of the charge',
+    failure_reason     varchar(255)   null COMMENT 'reason of the declined charge',
+    payload            json           not null COMMENT 'raw body of the callback',
+    status             varchar(10)    not null COMMENT 'QUEUED (not yet applied, e.g. payment is not exists yet), PROCESSED, FAILED (max attempts reached)',
+    attempts           int            not null default 0 COMMENT 'number of apply attempts',
+    last_error         varchar(255)   null COMMENT 'last error during apply',
+    created_at         timestamp      not null COMMENT 'created_at of the transaction',
+    version            int            not null COMMENT 'versioning',
+    updated_at         timestamp      not null on update current_timestamp COMMENT 'updated_at of the transaction',
+    constraint pk_id primary key (id),
+    constraint uq_provider_reference_status unique (provider, provider_reference, provider_status)
+);
+
+create index idx_status
+    on payment_callback (status);
+
+-- migrate:down
+drop table payment_callback;
@@ -0,0 +1,14 @@
+-- migrate:up
+alter table payment
+    add channel varchar(15) not null default 'DIRECT_DEBIT' comment 'DIRECT_DEBIT (charged through gateway), QRIS (intent paid by the customer)';
+
+alter table payment
+    add payment_code varchar(512) null comment 'code shown to the customer, e.g. QRIS payload';
+
+alter table payment
+    add expires_at timestamp null comment 'the intent can not be paid after it expires';
+
+-- migrate:down
+alter table payment drop column channel;
+alter table payment drop column payment_code;
+alter table payment drop column expires_at;
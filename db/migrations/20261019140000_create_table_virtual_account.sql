@@ -0,0 +1,40 @@
+-- migrate:up
+create table virtual_account
+(
+    id         bigint auto_increment,
+    bank_code  varchar(10) not null COMMENT 'bank of the virtual account, e.g. bca',
+    va_number  varchar(30) not null COMMENT 'virtual account number, prefix of the bank + derived digits + check digit',
+    user_id    varchar(50) not null COMMENT 'user id of the customer',
+    status     varchar(10) not null COMMENT 'ACTIVE',
+    created_at timestamp   not null COMMENT 'created_at of the transaction',
+    version    int         not null COMMENT 'versioning',
+    updated_at timestamp   not null on update current_timestamp COMMENT 'updated_at of the transaction',
+    constraint pk_id primary key (id),
+    constraint uq_va_number unique (va_number),
+    constraint uq_bank_code_user_id unique (bank_code, user_id)
+);
+
+create table virtual_account_credit
+(
+    id             bigint auto_increment,
+    bank_code      varchar(10)    not null COMMENT 'bank of the virtual account',
+    va_number      varchar(30)    not null COMMENT 'virtual account number credited',
+    reference      varchar(100)   not null COMMENT 'reference of the transfer in the bank',
+    user_id        varchar(50)    not null COMMENT 'user id of the customer',
+    amount         decimal(20, 2) not null COMMENT 'amount transferred',
+    payment_id     varchar(50)    null COMMENT 'payment created from the credit',
+    status         varchar(10)    not null COMMENT 'RECEIVED, PROCESSING, APPLIED (paid the installments), UNAPPLIED (needs review)',
+    failure_reason varchar(255)   null COMMENT 'reason of the unapplied credit',
+    created_at     timestamp      not null COMMENT 'created_at of the transaction',
+    version        int            not null COMMENT 'versioning',
+    updated_at     timestamp      not null on update current_timestamp COMMENT 'updated_at of the transaction',
+    constraint pk_id primary key (id),
+    constraint uq_bank_code_reference unique (bank_code, reference)
+);
+
+create index idx_status
+    on virtual_account_credit (status);
+
+-- migrate:down
+drop table virtual_account_credit;
+drop table virtual_account;
@@ -0,0 +1,26 @@
+-- migrate:up
+create table reconciliation_item
+(
+    id              bigint auto_increment,
+    run_id          varchar(50)    not null COMMENT 'id of the reconcile run (uuid), a run is one statement file',
+    source_file     varchar(255)   not null COMMENT 'name of the statement file',
+    format          varchar(10)    not null COMMENT 'format of the statement file : bank, mt940',
+    line_number     int            not null COMMENT 'position of the line in the statement file',
+    statement_date  date           not null COMMENT 'date of the transaction in the statement',
+    reference       varchar(100)   null COMMENT 'reference of the transaction in the statement',
+    va_number       varchar(30)    null COMMENT 'virtual account credited in the statement',
+    amount          decimal(20, 2) not null COMMENT 'amount in the statement',
+    recorded_amount decimal(20, 2) not null COMMENT 'amount of the matched payment, 0 when unmatched',
+    payment_id      varchar(50)    null COMMENT 'matched payment',
+    matched_by      varchar(10)    null COMMENT 'REFERENCE, VA_NUMBER',
+    status          varchar(15)    not null COMMENT 'MATCHED, UNMATCHED, AMOUNT_MISMATCH',
+    note            varchar(255)   null COMMENT 'detail of the exception',
+    created_at      timestamp      not null COMMENT 'created_at of the transaction',
+    constraint pk_id primary key (id)
+);
+
+create index idx_run_id_status
+    on reconciliation_item (run_id, status);
+
+-- migrate:down
+drop table reconciliation_item;
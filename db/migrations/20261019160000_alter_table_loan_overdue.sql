@@ -0,0 +1,12 @@
+-- migrate:up
+alter table loan
+    modify status varchar(10) not null comment 'PENDING (not yet paid), OVERDUE (not yet paid after due date), PAID, CLOSED';
+
+create index idx_status_due_date
+    on loan (status, due_date);
+
+-- migrate:down
+drop index idx_status_due_date on loan;
+
+alter table loan
+    modify status varchar(10) not null comment 'PENDING (not yet paid), PAID';
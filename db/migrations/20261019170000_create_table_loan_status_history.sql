@@ -0,0 +1,23 @@
+-- migrate:up
+create table loan_status_history
+(
+    id          bigint auto_increment,
+    loan_id     bigint       not null COMMENT 'id of the installment (table loan)',
+    from_status varchar(10)  not null COMMENT 'status before the transition',
+    to_status   varchar(10)  not null COMMENT 'status after the transition',
+    reason      varchar(255) not null default '' COMMENT 'reason of the transition, e.g. the payment id',
+    created_at  timestamp    not null COMMENT 'created_at of the transition',
+    constraint pk_id primary key (id)
+);
+
+create index idx_loan_id
+    on loan_status_history (loan_id);
+
+alter table loan
+    modify status varchar(10) not null comment 'PENDING (not yet paid), OVERDUE (not yet paid after due date), PAID, CLOSED, REVERSED (payment reversed, reopened afterward)';
+
+-- migrate:down
+drop table loan_status_history;
+
+alter table loan
+    modify status varchar(10) not null comment 'PENDING (not yet paid), OVERDUE (not yet paid after due date), PAID, CLOSED';
@@ -0,0 +1,30 @@
+-- migrate:up
+create table payment_reversal
+(
+    id               bigint auto_increment,
+    reversal_id      varchar(50)    not null COMMENT 'uuid of the reversal',
+    payment_id       varchar(50)    not null COMMENT 'payment id which is reversed (table payment)',
+    user_id          varchar(50)    not null COMMENT 'user id of the customer',
+    amount           decimal(20, 2) not null COMMENT 'amount of the reversed payment',
+    installment_ids  varchar(1000)  not null COMMENT 'comma separated installment ids reopened by the reversal',
+    reason           varchar(255)   not null COMMENT 'reason of the reversal, e.g. bounced transfer, duplicate debit',
+    requested_by     varchar(50)    not null COMMENT 'admin who requested the reversal',
+    refund_status    varchar(15)    not null COMMENT 'NOT_APPLICABLE (nothing to return), PENDING, REFUNDED, FAILED',
+    refund_reference varchar(100)   null COMMENT 'reference of the refund in the provider',
+    failure_reason   varchar(255)   null COMMENT 'reason of the failed refund',
+    created_at       timestamp      not null COMMENT 'created_at of the transaction',
+    version          int            not null COMMENT 'versioning',
+    updated_at       timestamp      not null on update current_timestamp COMMENT 'updated_at of the transaction',
+    constraint pk_id primary key (id),
+    constraint uq_reversal_id unique (reversal_id),
+    constraint uq_payment_id unique (payment_id)
+);
+
+alter table payment
+    modify status varchar(15) not null comment 'PENDING_DEBIT (waiting confirmation of provider), PAID, FAILED, REVERSED';
+
+-- migrate:down
+drop table payment_reversal;
+
+alter table payment
+    modify status varchar(15) not null comment 'PENDING_DEBIT (waiting confirmation of provider), PAID, FAILED';
@@ -0,0 +1,22 @@
+-- migrate:up
+create table payoff_quote
+(
+    id              bigint auto_increment,
+    quote_id        varchar(50)    not null COMMENT 'uuid of the quote, sent back by the payoff payment',
+    user_id         varchar(50)    not null COMMENT 'user id of the customer',
+    installment_ids varchar(1000)  not null COMMENT 'comma separated id of loan settled by the quote, including not yet due',
+    outstanding     decimal(20, 2) not null COMMENT 'sum amount of the installments',
+    discount        decimal(20, 2) not null COMMENT 'early settlement discount on the fee of not yet due installments',
+    amount          decimal(20, 2) not null COMMENT 'amount to be paid, outstanding - discount',
+    status          varchar(10)    not null COMMENT 'ACTIVE, USED (paid by payment_id)',
+    payment_id      varchar(50)    null COMMENT 'payment id which uses the quote',
+    expires_at      timestamp      not null COMMENT 'the quote can not be paid after it expires',
+    created_at      timestamp      not null COMMENT 'created_at of the transaction',
+    version         int            not null COMMENT 'versioning',
+    updated_at      timestamp      not null on update current_timestamp COMMENT 'updated_at of the transaction',
+    constraint pk_id primary key (id),
+    constraint uq_quote_id unique (quote_id)
+);
+
+-- migrate:down
+drop table payoff_quote;
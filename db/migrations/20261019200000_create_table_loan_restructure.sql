@@ -0,0 +1,43 @@
+-- migrate:up
+create table loan_restructure
+(
+    id                bigint auto_increment,
+    restructure_id    varchar(50)    not null COMMENT 'uuid of the restructuring',
+    user_id           varchar(50)    not null COMMENT 'user id of the customer',
+    installment_ids   varchar(1000)  not null COMMENT 'comma separated original installment ids moved to RESTRUCTURED',
+    outstanding       decimal(20, 2) not null COMMENT 'unpaid amount of the original installments, spread into the new schedule',
+    tenor             int            not null COMMENT 'number of installments of the new schedule',
+    frequency         varchar(10)    not null COMMENT 'WEEKLY, BIWEEKLY, MONTHLY',
+    grace_period_days int            not null COMMENT 'days before the period of the first new installment starts',
+    reason            varchar(255)   not null COMMENT 'reason of the restructuring, e.g. hardship',
+    requested_by      varchar(50)    not null COMMENT 'admin who requested the restructuring',
+    created_at        timestamp      not null COMMENT 'created_at of the transaction',
+    version           int            not null COMMENT 'versioning',
+    updated_at        timestamp      not null on update current_timestamp COMMENT 'updated_at of the transaction',
+    constraint pk_id primary key (id),
+    constraint uq_restructure_id unique (restructure_id)
+);
+
+alter table loan
+    modify status varchar(15) not null comment 'PENDING (not yet paid), OVERDUE (not yet paid after due date), PAID, CLOSED, REVERSED (payment reversed, reopened afterward), RESTRUCTURED (replaced by the new schedule)',
+    add restructure_id varchar(50) null comment 'restructuring which generated the installment (table loan_restructure)';
+
+alter table loan_status_history
+    modify from_status varchar(15) not null comment 'status before the transition',
+    modify to_status varchar(15) not null comment 'status after the transition';
+
+create index idx_restructure_id
+    on loan (restructure_id);
+
+-- migrate:down
+drop index idx_restructure_id on loan;
+
+alter table loan_status_history
+    modify from_status varchar(10) not null comment 'status before the transition',
+    modify to_status varchar(10) not null comment 'status after the transition';
+
+alter table loan
+    modify status varchar(10) not null comment 'PENDING (not yet paid), OVERDUE (not yet paid after due date), PAID, CLOSED, REVERSED (payment reversed, reopened afterward)',
+    drop column restructure_id;
+
+drop table loan_restructure;
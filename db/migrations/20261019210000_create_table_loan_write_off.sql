@@ -0,0 +1,32 @@
+-- migrate:up
+create table loan_write_off
+(
+    id              bigint auto_increment,
+    write_off_id    varchar(50)    not null COMMENT 'uuid of the write-off',
+    user_id         varchar(50)    not null COMMENT 'user id of the customer',
+    installment_ids varchar(1000)  not null COMMENT 'comma separated installment ids moved to WRITTEN_OFF',
+    amount          decimal(20, 2) not null COMMENT 'written-off balance, collected afterward as recovery',
+    days_past_due   int            not null COMMENT 'days past due of the oldest unpaid installment when written off',
+    reason          varchar(255)   not null COMMENT 'reason of the write-off',
+    approved_by     varchar(50)    not null COMMENT 'admin who approved the write-off',
+    created_at      timestamp      not null COMMENT 'created_at of the transaction',
+    version         int            not null COMMENT 'versioning',
+    updated_at      timestamp      not null on update current_timestamp COMMENT 'updated_at of the transaction',
+    constraint pk_id primary key (id),
+    constraint uq_write_off_id unique (write_off_id)
+);
+
+alter table loan
+    modify status varchar(15) not null comment 'PENDING (not yet paid), OVERDUE (not yet paid after due date), PAID, CLOSED, REVERSED (payment reversed, reopened afterward), RESTRUCTURED (replaced by the new schedule), WRITTEN_OFF (charged off, collected as recovery)';
+
+alter table payment
+    add recovery tinyint(1) not null default 0 comment 'payment of the written-off installments';
+
+-- migrate:down
+alter table payment
+    drop column recovery;
+
+alter table loan
+    modify status varchar(15) not null comment 'PENDING (not yet paid), OVERDUE (not yet paid after due date), PAID, CLOSED, REVERSED (payment reversed, reopened afterward), RESTRUCTURED (replaced by the new schedule)';
+
+drop table loan_write_off;
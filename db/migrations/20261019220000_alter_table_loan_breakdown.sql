@@ -0,0 +1,30 @@
+-- migrate:up
+alter table loan
+    add principal decimal(20, 2) not null default 0 comment 'principal component of the amount',
+    add interest  decimal(20, 2) not null default 0 comment 'interest component of the amount',
+    add fee       decimal(20, 2) not null default 0 comment 'fee component of the amount',
+    add penalty   decimal(20, 2) not null default 0 comment 'penalty component of the amount';
+
+-- the existing installments were issued with 10% fee baked into the amount
+update loan
+set fee       = round(amount * 0.1 / 1.1, 2),
+    principal = amount - round(amount * 0.1 / 1.1, 2);
+
+alter table payment
+    add principal decimal(20, 2) not null default 0 comment 'amount allocated into the principal of the installments',
+    add interest  decimal(20, 2) not null default 0 comment 'amount allocated into the interest of the installments',
+    add fee       decimal(20, 2) not null default 0 comment 'amount allocated into the fee of the installments',
+    add penalty   decimal(20, 2) not null default 0 comment 'amount allocated into the penalty of the installments';
+
+-- migrate:down
+alter table payment
+    drop column principal,
+    drop column interest,
+    drop column fee,
+    drop column penalty;
+
+alter table loan
+    drop column principal,
+    drop column interest,
+    drop column fee,
+    drop column penalty;
@@ -0,0 +1,34 @@
+-- migrate:up
+create table product
+(
+    id                    bigint auto_increment,
+    code                  varchar(50)    not null COMMENT 'code of the product, shared by all of its versions',
+    product_version       int            not null COMMENT 'version of the product, every edit is saved as the next version',
+    name                  varchar(100)   not null COMMENT 'name of the product',
+    status                varchar(10)    not null COMMENT 'ACTIVE, RETIRED (no longer offered, the existing loans keep it)',
+    min_amount            decimal(20, 2) not null COMMENT 'minimum principal of the loan',
+    max_amount            decimal(20, 2) not null COMMENT 'maximum principal of the loan',
+    tenors                varchar(100)   not null COMMENT 'comma separated tenor options (number of installments)',
+    frequency             varchar(10)    not null COMMENT 'WEEKLY, BIWEEKLY, MONTHLY',
+    interest_method       varchar(10)    not null COMMENT 'FLAT, EFFECTIVE, ANNUITY',
+    annual_rate           decimal(9, 6)  not null COMMENT 'interest rate per year, e.g. 0.24 for 24%',
+    fee_rate              decimal(9, 6)  not null COMMENT 'fee of the installment by its principal, e.g. 0.1 for 10%',
+    penalty_rate          decimal(9, 6)  not null COMMENT 'penalty of the overdue installment by its amount',
+    penalty_cap           decimal(20, 2) not null COMMENT 'maximum penalty per installment, 0 is unlimited',
+    grace_days            int            not null COMMENT 'days after the due date before the installment is counted as missed',
+    delinquency_threshold int            not null COMMENT 'missed installments tolerated before the customer is delinquent',
+    created_at            timestamp      not null COMMENT 'created_at of the transaction',
+    version               int            not null COMMENT 'versioning',
+    updated_at            timestamp      not null on update current_timestamp COMMENT 'updated_at of the transaction',
+    constraint pk_id primary key (id),
+    constraint uq_code_product_version unique (code, product_version)
+);
+
+alter table loan
+    add product_id bigint null comment 'id of the product version the loan is issued by, empty for the loans issued before the catalogue';
+
+-- migrate:down
+alter table loan
+    drop column product_id;
+
+drop table product;
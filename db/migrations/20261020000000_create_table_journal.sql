@@ -0,0 +1,40 @@
+-- migrate:up
+create table journal_entry
+(
+    id          bigint auto_increment,
+    entry_id    varchar(50)  not null COMMENT 'uuid of the entry',
+    entry_type  varchar(20)  not null COMMENT 'DISBURSEMENT, ACCRUAL, PAYMENT, REVERSAL, WRITE_OFF, RESTRUCTURE',
+    reference   varchar(50)  not null COMMENT 'id of the source of the event, e.g. payment id, installment id',
+    user_id     varchar(50)  not null COMMENT 'user id of the customer',
+    description varchar(255) not null COMMENT 'description of the entry',
+    booked_at   timestamp    not null COMMENT 'time the entry is booked into the ledger',
+    created_at  timestamp    not null COMMENT 'created_at of the transaction',
+    version     int          not null COMMENT 'versioning',
+    updated_at  timestamp    not null on update current_timestamp COMMENT 'updated_at of the transaction',
+    constraint pk_id primary key (id),
+    constraint uq_entry_id unique (entry_id),
+    constraint uq_entry_type_reference unique (entry_type, reference)
+);
+
+create index idx_booked_at
+    on journal_entry (booked_at);
+
+create table journal_line
+(
+    id       bigint auto_increment,
+    entry_id varchar(50)    not null COMMENT 'entry_id of journal_entry',
+    account  varchar(20)    not null COMMENT 'account code of the chart of accounts',
+    debit    decimal(20, 2) not null default 0 COMMENT 'debit amount, 0 for the credit line',
+    credit   decimal(20, 2) not null default 0 COMMENT 'credit amount, 0 for the debit line',
+    constraint pk_id primary key (id)
+);
+
+create index idx_entry_id
+    on journal_line (entry_id);
+
+create index idx_account
+    on journal_line (account);
+
+-- migrate:down
+drop table journal_line;
+drop table journal_entry;
@@ -0,0 +1,12 @@
+-- migrate:up
+alter table journal_entry
+    add branch varchar(20) not null default 'HQ' COMMENT 'branch the entry is booked for, exported into the general ledger' after user_id;
+
+create index idx_booked_at_branch
+    on journal_entry (booked_at, branch);
+
+-- migrate:down
+drop index idx_booked_at_branch on journal_entry;
+
+alter table journal_entry
+    drop column branch;
@@ -0,0 +1,58 @@
+-- migrate:up
+alter table loan
+    add penalty_accrued_on date null COMMENT 'last business date the penalty is accrued for, so it is charged once per date';
+
+create table delinquency_snapshot
+(
+    id                    bigint auto_increment,
+    business_date         date           not null COMMENT 'business date the snapshot is taken for',
+    user_id               varchar(50)    not null COMMENT 'user id of the customer',
+    missed_installments   int            not null COMMENT 'unpaid installments due before the date, original schedule',
+    missed_restructured   int            not null COMMENT 'unpaid installments due before the date, restructured schedule',
+    written_off           int            not null COMMENT 'written-off installments due before the date',
+    outstanding_amount    decimal(20, 2) not null COMMENT 'sum of the installments above',
+    oldest_due_date       date           not null COMMENT 'oldest due date of the installments above',
+    days_past_due         int            not null COMMENT 'days from the oldest due date to the business date',
+    is_delinquent         boolean        not null COMMENT 'delinquency of the customer at the date',
+    created_at            timestamp      not null COMMENT 'created_at of the snapshot',
+    constraint pk_id primary key (id),
+    constraint uq_business_date_user_id unique (business_date, user_id)
+);
+
+create table eod_run
+(
+    id            bigint auto_increment,
+    business_date date         not null COMMENT 'business date closed by the run',
+    status        varchar(20)  not null COMMENT 'RUNNING, FAILED, COMPLETED',
+    error         varchar(255) not null default '' COMMENT 'error of the failed step',
+    started_at    timestamp    not null COMMENT 'started_at of the last attempt',
+    finished_at   timestamp    null COMMENT 'finished_at of the last attempt',
+    created_at    timestamp    not null COMMENT 'created_at of the run',
+    version       int          not null COMMENT 'versioning',
+    updated_at    timestamp    not null on update current_timestamp COMMENT 'updated_at of the run',
+    constraint pk_id primary key (id),
+    constraint uq_business_date unique (business_date)
+);
+
+create table eod_step
+(
+    id            bigint auto_increment,
+    business_date date         not null COMMENT 'business_date of eod_run',
+    step          varchar(30)  not null COMMENT 'OVERDUE_MARKING, PENALTY_ACCRUAL, INTEREST_ACCRUAL, DELINQUENCY_SNAPSHOT, GL_EXPORT',
+    sequence      int          not null COMMENT 'order of the step in the run',
+    status        varchar(20)  not null COMMENT 'RUNNING, FAILED, COMPLETED',
+    processed     int          not null default 0 COMMENT 'number of records processed by the step',
+    error         varchar(255) not null default '' COMMENT 'error of the failed step',
+    started_at    timestamp    not null COMMENT 'started_at of the last attempt',
+    finished_at   timestamp    null COMMENT 'finished_at of the last attempt',
+    constraint pk_id primary key (id),
+    constraint uq_business_date_step unique (business_date, step)
+);
+
+-- migrate:down
+drop table eod_step;
+drop table eod_run;
+drop table delinquency_snapshot;
+
+alter table loan
+    drop column penalty_accrued_on;
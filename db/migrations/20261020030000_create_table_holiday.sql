@@ -0,0 +1,14 @@
+-- migrate:up
+create table holiday
+(
+    id           bigint auto_increment,
+    region       varchar(10)  not null COMMENT 'region of the calendar, e.g. ID',
+    holiday_date date         not null COMMENT 'date of the holiday, it is not a business day',
+    name         varchar(100) not null default '' COMMENT 'name of the holiday, e.g. Idul Fitri',
+    created_at   timestamp    not null default current_timestamp COMMENT 'created_at of the holiday',
+    constraint pk_id primary key (id),
+    constraint uq_region_holiday_date unique (region, holiday_date)
+);
+
+-- migrate:down
+drop table holiday;
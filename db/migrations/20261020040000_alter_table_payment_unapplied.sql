@@ -0,0 +1,7 @@
+-- migrate:up
+alter table payment
+    modify status varchar(15) not null comment 'PENDING_DEBIT (waiting confirmation of provider), PAID, FAILED, REVERSED, UNAPPLIED (installments no longer collectible, held in suspense)';
+
+-- migrate:down
+alter table payment
+    modify status varchar(15) not null comment 'PENDING_DEBIT (waiting confirmation of provider), PAID, FAILED, REVERSED';
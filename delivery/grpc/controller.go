@@ -0,0 +1,177 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/loan"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/constant"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/delivery/grpc/pb"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+const (
+	headerRc = "x-billing-rc"
+)
+
+var billingErrorToGrpcCode = map[constant.BillingSrvHttpError]codes.Code{
+	constant.Validation:                  codes.InvalidArgument,
+	constant.DataNotFound:                codes.NotFound,
+	constant.PaymentAmountShouldBeEquals: codes.InvalidArgument,
+	constant.ZeroOutstanding:             codes.FailedPrecondition,
+	constant.TooManyRequests:             codes.ResourceExhausted,
+	constant.PaymentDeclined:             codes.FailedPrecondition,
+	constant.PaymentInProgress:           codes.Aborted,
+	constant.PaymentNotReversible:        codes.FailedPrecondition,
+	constant.PayoffQuoteNotActive:        codes.FailedPrecondition,
+	constant.InstallmentsChanged:         codes.Aborted,
+	constant.WriteOffNotEligible:         codes.FailedPrecondition,
+	constant.GeneralError:                codes.Internal,
+}
+
+func (b *billingHandler) FetchOutstanding(
+	ctx context.Context,
+	req *pb.FetchOutstandingRequest) (*pb.FetchOutstandingResponse, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	result, err := b.loanSrv.FetchOutstanding(ctx, req.GetUserId())
+	if err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+
+	return &pb.FetchOutstandingResponse{
+		RemainingOutstanding: result.RemainingOutstanding.String(),
+		IsDelinquent:         result.IsDelinquent,
+		RecoverableBalance:   result.RecoverableBalance.String(),
+		Breakdown:            toBreakdown(&result.Breakdown),
+		GraceOutstanding:     result.GraceOutstanding.String(),
+		GraceInstallmentIds:  result.GraceInstallmentIDs,
+	}, nil
+}
+
+func (b *billingHandler) Payment(
+	ctx context.Context,
+	req *pb.PaymentRequest) (*pb.PaymentResponse, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	result, err := b.loanSrv.Payment(
+		ctx, &loan.PaymentRequest{
+			UserID:  req.GetUserId(),
+			Amount:  req.GetAmount(),
+			QuoteID: req.GetQuoteId(),
+		},
+	)
+
+	if err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+
+	return toPaymentResponse(result), nil
+}
+
+func (b *billingHandler) CreateQris(
+	ctx context.Context,
+	req *pb.CreateQrisRequest) (*pb.CreateQrisResponse, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	result, err := b.loanSrv.CreateQris(
+		ctx, &loan.QrisRequest{
+			UserID:        req.GetUserId(),
+			InstallmentID: req.GetInstallmentId(),
+		},
+	)
+
+	if err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+
+	return &pb.CreateQrisResponse{
+		Rc:        constant.HttpRc[constant.Success],
+		Message:   constant.HttpRcDescription[constant.Success],
+		PaymentId: result.PaymentID,
+		Amount:    result.Amount.String(),
+		Payload:   result.Payload,
+		ExpiresAt: result.ExpiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+func (b *billingHandler) FindPayment(
+	ctx context.Context,
+	req *pb.FindPaymentRequest) (*pb.PaymentResponse, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	result, err := b.loanSrv.FindPayment(ctx, req.GetPaymentId())
+	if err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+
+	return toPaymentResponse(result), nil
+}
+
+func (b *billingHandler) PayoffQuote(
+	ctx context.Context,
+	req *pb.PayoffQuoteRequest) (*pb.PayoffQuoteResponse, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Minute)
+	defer cancelFunc()
+
+	result, err := b.loanSrv.PayoffQuote(ctx, req.GetUserId())
+	if err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+
+	return &pb.PayoffQuoteResponse{
+		Rc:             constant.HttpRc[constant.Success],
+		Message:        constant.HttpRcDescription[constant.Success],
+		QuoteId:        result.QuoteID,
+		InstallmentIds: result.InstallmentIDs,
+		Outstanding:    result.Outstanding.String(),
+		Discount:       result.Discount.String(),
+		Amount:         result.Amount.String(),
+		ExpiresAt:      result.ExpiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+func toPaymentResponse(result *loan.PaymentResponse) *pb.PaymentResponse {
+	return &pb.PaymentResponse{
+		Rc:         constant.HttpRc[constant.Success],
+		Message:    constant.HttpRcDescription[constant.Success],
+		PaymentId:  result.PaymentID,
+		Status:     result.Status,
+		Allocation: toBreakdown(result.Allocation),
+	}
+}
+
+func toBreakdown(breakdown *repository.Breakdown) *pb.Breakdown {
+	if breakdown == nil {
+		return nil
+	}
+
+	return &pb.Breakdown{
+		Principal: breakdown.Principal.String(),
+		Interest:  breakdown.Interest.String(),
+		Fee:       breakdown.Fee.String(),
+		Penalty:   breakdown.Penalty.String(),
+	}
+}
+
+// toStatusError maps the error the same way with the HTTP delivery, the rc is sent through header.
+func toStatusError(ctx context.Context, err error) error {
+	billingErr := loan.MapError(err)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(headerRc, constant.HttpRc[billingErr]))
+
+	code, ok := billingErrorToGrpcCode[billingErr]
+	if !ok {
+		code = codes.Internal
+	}
+
+	return status.Error(code, constant.HttpRcDescription[billingErr])
+}
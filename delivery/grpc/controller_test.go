@@ -0,0 +1,155 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/loan"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/delivery/grpc/pb"
+	mocksLoan "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/application/loan"
+	mocksConfiguration "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/configuration"
+)
+
+func Test_billingHandler_FetchOutstanding(t *testing.T) {
+	tests := []struct {
+		name     string
+		want     *pb.FetchOutstandingResponse
+		wantCode codes.Code
+		mockFunc func(mockSrv *mocksLoan.Service)
+	}{
+		{
+			name: "given valid request," +
+				"when fetchOutstanding," +
+				"then return the outstanding",
+			want: &pb.FetchOutstandingResponse{
+				RemainingOutstanding: "4400000",
+				IsDelinquent:         true,
+				GraceOutstanding:     "0",
+			},
+			wantCode: codes.OK,
+			mockFunc: func(mockSrv *mocksLoan.Service) {
+				mockSrv.
+					On("FetchOutstanding", mock.Anything, "abc").
+					Return(
+						&loan.FetchOutstandingResponse{
+							RemainingOutstanding: decimal.NewFromInt(4400000),
+							IsDelinquent:         true,
+						}, nil).
+					Once()
+			},
+		},
+		{
+			name: "given installments within the grace period," +
+				"when fetchOutstanding," +
+				"then return the grace outstanding",
+			want: &pb.FetchOutstandingResponse{
+				RemainingOutstanding: "4400000",
+				IsDelinquent:         false,
+				GraceOutstanding:     "1100000",
+				GraceInstallmentIds:  []uint64{7},
+			},
+			wantCode: codes.OK,
+			mockFunc: func(mockSrv *mocksLoan.Service) {
+				mockSrv.
+					On("FetchOutstanding", mock.Anything, "abc").
+					Return(
+						&loan.FetchOutstandingResponse{
+							RemainingOutstanding: decimal.NewFromInt(4400000),
+							GraceOutstanding:     decimal.NewFromInt(1100000),
+							GraceInstallmentIDs:  []uint64{7},
+						}, nil).
+					Once()
+			},
+		},
+		{
+			name: "given unknown error from service," +
+				"when fetchOutstanding," +
+				"then return internal",
+			wantCode: codes.Internal,
+			mockFunc: func(mockSrv *mocksLoan.Service) {
+				mockSrv.
+					On("FetchOutstanding", mock.Anything, "abc").
+					Return(nil, errors.New("new error")).
+					Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockSrv := &mocksLoan.Service{}
+				tt.mockFunc(mockSrv)
+
+				b := NewBillingHandler(&mocksConfiguration.Configuration{}, mockSrv)
+				got, err := b.FetchOutstanding(
+					context.Background(), &pb.FetchOutstandingRequest{UserId: "abc"})
+
+				assert.Equal(t, tt.wantCode, status.Code(err))
+				if tt.want != nil {
+					assert.Equal(t, tt.want.RemainingOutstanding, got.RemainingOutstanding)
+					assert.Equal(t, tt.want.IsDelinquent, got.IsDelinquent)
+					assert.Equal(t, tt.want.GraceOutstanding, got.GraceOutstanding)
+					assert.Equal(t, tt.want.GraceInstallmentIds, got.GraceInstallmentIds)
+				}
+			})
+	}
+}
+
+func Test_billingHandler_Payment(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantCode codes.Code
+		mockFunc func(mockSrv *mocksLoan.Service)
+	}{
+		{
+			name: "given valid request," +
+				"when payment," +
+				"then return ok",
+			wantCode: codes.OK,
+			mockFunc: func(mockSrv *mocksLoan.Service) {
+				mockSrv.
+					On("Payment", mock.Anything, &loan.PaymentRequest{UserID: "abc", Amount: 25}).
+					Return(&loan.PaymentResponse{PaymentID: "p-1", Status: "PAID"}, nil).
+					Once()
+			},
+		},
+		{
+			name: "given unknown error from service," +
+				"when payment," +
+				"then return internal",
+			wantCode: codes.Internal,
+			mockFunc: func(mockSrv *mocksLoan.Service) {
+				mockSrv.
+					On("Payment", mock.Anything, mock.Anything).
+					Return(nil, errors.New("new error")).
+					Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockSrv := &mocksLoan.Service{}
+				tt.mockFunc(mockSrv)
+
+				b := NewBillingHandler(&mocksConfiguration.Configuration{}, mockSrv)
+				got, err := b.Payment(
+					context.Background(), &pb.PaymentRequest{UserId: "abc", Amount: 25})
+
+				assert.Equal(t, tt.wantCode, status.Code(err))
+				if err == nil {
+					assert.Equal(t, "p-1", got.PaymentId)
+					assert.Equal(t, "PAID", got.Status)
+				}
+			})
+	}
+}
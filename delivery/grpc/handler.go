@@ -0,0 +1,40 @@
+package grpc
+
+import (
+	"log"
+
+	"google.golang.org/grpc"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/loan"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/delivery/grpc/pb"
+)
+
+type billingHandler struct {
+	pb.UnimplementedBillingServiceServer
+
+	configuration configuration.Configuration
+	loanSrv       loan.Service
+}
+
+func NewBillingHandler(
+	configuration configuration.Configuration,
+	loanSrv loan.Service) *billingHandler {
+	return &billingHandler{
+		configuration: configuration,
+		loanSrv:       loanSrv,
+	}
+}
+
+func (b *billingHandler) showVersion() {
+	version := b.configuration.GetString("app.billing.version")
+	log.Println("show-billing-version (grpc) -> ", version)
+}
+
+func (b *billingHandler) BuildGrpc(server *grpc.Server) *grpc.Server {
+	b.showVersion()
+
+	pb.RegisterBillingServiceServer(server, b)
+
+	return server
+}
@@ -0,0 +1,273 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: billing.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	BillingService_FetchOutstanding_FullMethodName = "/billing.v1.BillingService/FetchOutstanding"
+	BillingService_Payment_FullMethodName          = "/billing.v1.BillingService/Payment"
+	BillingService_CreateQris_FullMethodName       = "/billing.v1.BillingService/CreateQris"
+	BillingService_FindPayment_FullMethodName      = "/billing.v1.BillingService/FindPayment"
+	BillingService_PayoffQuote_FullMethodName      = "/billing.v1.BillingService/PayoffQuote"
+)
+
+// BillingServiceClient is the client API for BillingService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type BillingServiceClient interface {
+	// FetchOutstanding returns the remaining outstanding and delinquency status of the customer.
+	FetchOutstanding(ctx context.Context, in *FetchOutstandingRequest, opts ...grpc.CallOption) (*FetchOutstandingResponse, error)
+	// Payment pays all the pending outstanding of the customer, the amount should be exact.
+	// The installments are paid only when the payment gateway confirms the debit, otherwise status is PENDING_DEBIT.
+	Payment(ctx context.Context, in *PaymentRequest, opts ...grpc.CallOption) (*PaymentResponse, error)
+	// CreateQris issues the dynamic QRIS for the due installments, or the given installment, of the customer.
+	// It is held as PENDING_DEBIT payment until the customer pays it or it expires.
+	CreateQris(ctx context.Context, in *CreateQrisRequest, opts ...grpc.CallOption) (*CreateQrisResponse, error)
+	// FindPayment returns the status of the payment, the pending one is refreshed from the payment gateway.
+	FindPayment(ctx context.Context, in *FindPaymentRequest, opts ...grpc.CallOption) (*PaymentResponse, error)
+	// PayoffQuote returns the full remaining amount of the customer including the installments not yet due,
+	// the quote_id is sent on Payment to pay it off before it expires.
+	PayoffQuote(ctx context.Context, in *PayoffQuoteRequest, opts ...grpc.CallOption) (*PayoffQuoteResponse, error)
+}
+
+type billingServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBillingServiceClient(cc grpc.ClientConnInterface) BillingServiceClient {
+	return &billingServiceClient{cc}
+}
+
+func (c *billingServiceClient) FetchOutstanding(ctx context.Context, in *FetchOutstandingRequest, opts ...grpc.CallOption) (*FetchOutstandingResponse, error) {
+	out := new(FetchOutstandingResponse)
+	err := c.cc.Invoke(ctx, BillingService_FetchOutstanding_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billingServiceClient) Payment(ctx context.Context, in *PaymentRequest, opts ...grpc.CallOption) (*PaymentResponse, error) {
+	out := new(PaymentResponse)
+	err := c.cc.Invoke(ctx, BillingService_Payment_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billingServiceClient) CreateQris(ctx context.Context, in *CreateQrisRequest, opts ...grpc.CallOption) (*CreateQrisResponse, error) {
+	out := new(CreateQrisResponse)
+	err := c.cc.Invoke(ctx, BillingService_CreateQris_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billingServiceClient) FindPayment(ctx context.Context, in *FindPaymentRequest, opts ...grpc.CallOption) (*PaymentResponse, error) {
+	out := new(PaymentResponse)
+	err := c.cc.Invoke(ctx, BillingService_FindPayment_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billingServiceClient) PayoffQuote(ctx context.Context, in *PayoffQuoteRequest, opts ...grpc.CallOption) (*PayoffQuoteResponse, error) {
+	out := new(PayoffQuoteResponse)
+	err := c.cc.Invoke(ctx, BillingService_PayoffQuote_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BillingServiceServer is the server API for BillingService service.
+// All implementations must embed UnimplementedBillingServiceServer
+// for forward compatibility
+type BillingServiceServer interface {
+	// FetchOutstanding returns the remaining outstanding and delinquency status of the customer.
+	FetchOutstanding(context.Context, *FetchOutstandingRequest) (*FetchOutstandingResponse, error)
+	// Payment pays all the pending outstanding of the customer, the amount should be exact.
+	// The installments are paid only when the payment gateway confirms the debit, otherwise status is PENDING_DEBIT.
+	Payment(context.Context, *PaymentRequest) (*PaymentResponse, error)
+	// CreateQris issues the dynamic QRIS for the due installments, or the given installment, of the customer.
+	// It is held as PENDING_DEBIT payment until the customer pays it or it expires.
+	CreateQris(context.Context, *CreateQrisRequest) (*CreateQrisResponse, error)
+	// FindPayment returns the status of the payment, the pending one is refreshed from the payment gateway.
+	FindPayment(context.Context, *FindPaymentRequest) (*PaymentResponse, error)
+	// PayoffQuote returns the full remaining amount of the customer including the installments not yet due,
+	// the quote_id is sent on Payment to pay it off before it expires.
+	PayoffQuote(context.Context, *PayoffQuoteRequest) (*PayoffQuoteResponse, error)
+	mustEmbedUnimplementedBillingServiceServer()
+}
+
+// UnimplementedBillingServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedBillingServiceServer struct {
+}
+
+func (UnimplementedBillingServiceServer) FetchOutstanding(context.Context, *FetchOutstandingRequest) (*FetchOutstandingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchOutstanding not implemented")
+}
+func (UnimplementedBillingServiceServer) Payment(context.Context, *PaymentRequest) (*PaymentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Payment not implemented")
+}
+func (UnimplementedBillingServiceServer) CreateQris(context.Context, *CreateQrisRequest) (*CreateQrisResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateQris not implemented")
+}
+func (UnimplementedBillingServiceServer) FindPayment(context.Context, *FindPaymentRequest) (*PaymentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindPayment not implemented")
+}
+func (UnimplementedBillingServiceServer) PayoffQuote(context.Context, *PayoffQuoteRequest) (*PayoffQuoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PayoffQuote not implemented")
+}
+func (UnimplementedBillingServiceServer) mustEmbedUnimplementedBillingServiceServer() {}
+
+// UnsafeBillingServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BillingServiceServer will
+// result in compilation errors.
+type UnsafeBillingServiceServer interface {
+	mustEmbedUnimplementedBillingServiceServer()
+}
+
+func RegisterBillingServiceServer(s grpc.ServiceRegistrar, srv BillingServiceServer) {
+	s.RegisterService(&BillingService_ServiceDesc, srv)
+}
+
+func _BillingService_FetchOutstanding_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchOutstandingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).FetchOutstanding(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillingService_FetchOutstanding_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).FetchOutstanding(ctx, req.(*FetchOutstandingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillingService_Payment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).Payment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillingService_Payment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).Payment(ctx, req.(*PaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillingService_CreateQris_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateQrisRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).CreateQris(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillingService_CreateQris_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).CreateQris(ctx, req.(*CreateQrisRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillingService_FindPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).FindPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillingService_FindPayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).FindPayment(ctx, req.(*FindPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillingService_PayoffQuote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PayoffQuoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).PayoffQuote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillingService_PayoffQuote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).PayoffQuote(ctx, req.(*PayoffQuoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BillingService_ServiceDesc is the grpc.ServiceDesc for BillingService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BillingService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "billing.v1.BillingService",
+	HandlerType: (*BillingServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "FetchOutstanding",
+			Handler:    _BillingService_FetchOutstanding_Handler,
+		},
+		{
+			MethodName: "Payment",
+			Handler:    _BillingService_Payment_Handler,
+		},
+		{
+			MethodName: "CreateQris",
+			Handler:    _BillingService_CreateQris_Handler,
+		},
+		{
+			MethodName: "FindPayment",
+			Handler:    _BillingService_FindPayment_Handler,
+		},
+		{
+			MethodName: "PayoffQuote",
+			Handler:    _BillingService_PayoffQuote_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "billing.proto",
+}
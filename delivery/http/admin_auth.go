@@ -0,0 +1,43 @@
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/constant"
+)
+
+const (
+	headerAdminKey = "X-Admin-Key"
+)
+
+type adminAuth struct {
+	apiKey string
+}
+
+func newAdminAuth(configuration configuration.Configuration) *adminAuth {
+	return &adminAuth{
+		apiKey: configuration.GetString("admin.api.key"),
+	}
+}
+
+// middleware protects the admin routes, all of them are rejected when "admin.api.key" is not configured.
+func (a *adminAuth) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			apiKey := req.Header.Get(headerAdminKey)
+
+			if a.apiKey == "" || subtle.ConstantTimeCompare([]byte(apiKey), []byte(a.apiKey)) != 1 {
+				common.ToErrorResponse(
+					writer,
+					constant.HttpRc[constant.Unauthorized],
+					constant.HttpRcDescription[constant.Unauthorized],
+				)
+				return
+			}
+
+			next.ServeHTTP(writer, req)
+		})
+}
@@ -6,10 +6,99 @@ import (
 	"github.com/gorilla/mux"
 )
 
+func (b *billingHandler) routeDocumentation(r *mux.Router) {
+	r.HandleFunc("/openapi.json", serveOpenApi).
+		Methods(http.MethodGet)
+}
+
 func (b *billingHandler) routeBilling(r *mux.Router) {
-	r.HandleFunc("/v1/customer/outstanding/{userID}", b.loanSrv.FindOutstanding).
+	defaultPolicy := newRateLimitPolicy(b.configuration, policyDefault)
+	paymentPolicy := newRateLimitPolicy(b.configuration, policyPayment)
+
+	b.limiter.route(defaultPolicy, r.HandleFunc("/v1/customer/outstanding/{userID}", b.loanSrv.FindOutstanding)).
+		Methods(http.MethodGet)
+
+	b.limiter.route(paymentPolicy, r.HandleFunc("/v1/customer/payment", b.loanSrv.Payment)).
+		Methods(http.MethodPost)
+
+	b.limiter.route(paymentPolicy, r.HandleFunc("/v1/customer/payment/qris", b.loanSrv.CreateQris)).
+		Methods(http.MethodPost)
+
+	b.limiter.route(defaultPolicy, r.HandleFunc("/v1/customer/payment/{paymentID}", b.loanSrv.FindPayment)).
+		Methods(http.MethodGet)
+
+	b.limiter.route(defaultPolicy, r.HandleFunc("/v1/loans/{userID}/payoff-quote", b.loanSrv.PayoffQuote)).
+		Methods(http.MethodGet)
+
+	b.limiter.route(defaultPolicy, r.HandleFunc("/v1/customer/virtual-account", b.vaSrv.Issue)).
+		Methods(http.MethodPost)
+}
+
+// routePayment is called by the payment gateway provider, it is protected by the signature of the provider.
+func (b *billingHandler) routePayment(r *mux.Router) {
+	r.HandleFunc("/v1/payment/callback/{provider}", b.paymentSrv.Callback).
+		Methods(http.MethodPost)
+}
+
+// routeVirtualAccount is called by the bank, it is protected by the key of the bank.
+func (b *billingHandler) routeVirtualAccount(r *mux.Router) {
+	r.HandleFunc("/v1/virtual-account/{bankCode}/{vaNumber}", b.vaSrv.Lookup).
+		Methods(http.MethodGet)
+
+	r.HandleFunc("/v1/virtual-account/{bankCode}/{vaNumber}/credit", b.vaSrv.Credit).
+		Methods(http.MethodPost)
+}
+
+func (b *billingHandler) routeAdmin(r *mux.Router) {
+	admin := r.PathPrefix("/v1/admin").Subrouter()
+	admin.Use(b.adminAuth.middleware)
+
+	admin.HandleFunc("/webhooks/subscriptions", b.webhookSrv.CreateSubscription).
+		Methods(http.MethodPost)
+
+	admin.HandleFunc("/webhooks/subscriptions", b.webhookSrv.FindSubscriptions).
+		Methods(http.MethodGet)
+
+	admin.HandleFunc("/webhooks/deliveries", b.webhookSrv.FindDeliveries).
+		Methods(http.MethodGet)
+
+	admin.HandleFunc("/webhooks/deliveries/{deliveryID}/replay", b.webhookSrv.ReplayDelivery).
+		Methods(http.MethodPost)
+
+	admin.HandleFunc("/products", b.productSrv.CreateProduct).
+		Methods(http.MethodPost)
+
+	admin.HandleFunc("/products", b.productSrv.FindProducts).
+		Methods(http.MethodGet)
+
+	admin.HandleFunc("/products/{code}", b.productSrv.FindProduct).
+		Methods(http.MethodGet)
+
+	admin.HandleFunc("/products/{code}", b.productSrv.UpdateProduct).
+		Methods(http.MethodPut)
+
+	admin.HandleFunc("/products/{code}", b.productSrv.RetireProduct).
+		Methods(http.MethodDelete)
+
+	admin.HandleFunc("/journal/balances", b.journalSrv.FindBalances).
+		Methods(http.MethodGet)
+
+	admin.HandleFunc("/eod/runs", b.eodSrv.FindRuns).
+		Methods(http.MethodGet)
+
+	admin.HandleFunc("/eod/business-date", b.eodSrv.BusinessDate).
 		Methods(http.MethodGet)
 
-	r.HandleFunc("/v1/customer/payment", b.loanSrv.Payment).
+	admin.HandleFunc("/eod/business-date/advance", b.eodSrv.AdvanceBusinessDate).
+		Methods(http.MethodPost)
+
+	//the reversal, the restructuring and the write-off are addressed by the payment and the loan, but protected as the admin api
+	r.Handle("/v1/payments/{paymentID}/reverse", b.adminAuth.middleware(http.HandlerFunc(b.loanSrv.ReversePayment))).
+		Methods(http.MethodPost)
+
+	r.Handle("/v1/loans/{userID}/restructure", b.adminAuth.middleware(http.HandlerFunc(b.loanSrv.Restructure))).
+		Methods(http.MethodPost)
+
+	r.Handle("/v1/loans/{userID}/write-off", b.adminAuth.middleware(http.HandlerFunc(b.loanSrv.WriteOff))).
 		Methods(http.MethodPost)
 }
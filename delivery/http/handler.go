@@ -6,21 +6,59 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/eod"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/journal"
 	"gitlab.com/2024/Juni/amartha-billing-srv2/application/loan"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/payment"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/product"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/virtualaccount"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/application/webhook"
 	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/ratelimit"
 )
 
 type billingHandler struct {
 	configuration configuration.Configuration
 	loanSrv       loan.Controller
+	webhookSrv    webhook.Controller
+	paymentSrv    payment.Controller
+	vaSrv         virtualaccount.Controller
+	productSrv    product.Controller
+	journalSrv    journal.Controller
+	eodSrv        eod.Controller
+	limiter       *rateLimiter
+	validator     *requestValidator
+	adminAuth     *adminAuth
 }
 
 func NewBillingHandler(
 	configuration configuration.Configuration,
-	loanSrv loan.Controller) *billingHandler {
+	loanSrv loan.Controller,
+	webhookSrv webhook.Controller,
+	paymentSrv payment.Controller,
+	vaSrv virtualaccount.Controller,
+	productSrv product.Controller,
+	journalSrv journal.Controller,
+	eodSrv eod.Controller,
+	rateLimitStore ratelimit.Store) *billingHandler {
+	document, err := loadOpenApiDocument()
+	if err != nil {
+		//the spec is embedded, so it only happens when the openapi.json is broken
+		panic(err)
+	}
+
 	return &billingHandler{
 		configuration: configuration,
 		loanSrv:       loanSrv,
+		webhookSrv:    webhookSrv,
+		paymentSrv:    paymentSrv,
+		vaSrv:         vaSrv,
+		productSrv:    productSrv,
+		journalSrv:    journalSrv,
+		eodSrv:        eodSrv,
+		limiter:       newRateLimiter(configuration, rateLimitStore),
+		validator:     newRequestValidator(document),
+		adminAuth:     newAdminAuth(configuration),
 	}
 }
 
@@ -32,7 +70,14 @@ func (b *billingHandler) showVersion() {
 func (b *billingHandler) BuildHttp(router *mux.Router) http.Handler {
 	b.showVersion()
 
+	//the limiter goes first, so the requests refused by the validator still count against the limit
+	router.Use(b.limiter.middleware, b.validator.middleware)
+
+	b.routeDocumentation(router)
 	b.routeBilling(router)
+	b.routePayment(router)
+	b.routeVirtualAccount(router)
+	b.routeAdmin(router)
 
 	return router
 }
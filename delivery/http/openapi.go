@@ -0,0 +1,261 @@
+package http
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+)
+
+const (
+	contentType     = "Content-Type"
+	applicationJson = "application/json"
+
+	refSchemas    = "#/components/schemas/"
+	refParameters = "#/components/parameters/"
+)
+
+//go:embed openapi.json
+var openApiSpec []byte
+
+type (
+	openApiSchema struct {
+		Ref                  string                    `json:"$ref,omitempty"`
+		Type                 string                    `json:"type,omitempty"`
+		Required             []string                  `json:"required,omitempty"`
+		Properties           map[string]*openApiSchema `json:"properties,omitempty"`
+		AdditionalProperties *bool                     `json:"additionalProperties,omitempty"`
+		Items                *openApiSchema            `json:"items,omitempty"`
+		AllOf                []*openApiSchema          `json:"allOf,omitempty"`
+		Enum                 []interface{}             `json:"enum,omitempty"`
+		MinLength            *int                      `json:"minLength,omitempty"`
+		MaxLength            *int                      `json:"maxLength,omitempty"`
+		Minimum              *float64                  `json:"minimum,omitempty"`
+		Maximum              *float64                  `json:"maximum,omitempty"`
+		ExclusiveMinimum     bool                      `json:"exclusiveMinimum,omitempty"`
+	}
+
+	openApiParameter struct {
+		Ref      string         `json:"$ref,omitempty"`
+		Name     string         `json:"name,omitempty"`
+		In       string         `json:"in,omitempty"`
+		Required bool           `json:"required,omitempty"`
+		Schema   *openApiSchema `json:"schema,omitempty"`
+	}
+
+	openApiMediaType struct {
+		Schema *openApiSchema `json:"schema,omitempty"`
+	}
+
+	openApiRequestBody struct {
+		Required bool                        `json:"required,omitempty"`
+		Content  map[string]openApiMediaType `json:"content,omitempty"`
+	}
+
+	openApiOperation struct {
+		OperationID string              `json:"operationId,omitempty"`
+		Parameters  []*openApiParameter `json:"parameters,omitempty"`
+		RequestBody *openApiRequestBody `json:"requestBody,omitempty"`
+	}
+
+	openApiComponents struct {
+		Schemas    map[string]*openApiSchema    `json:"schemas,omitempty"`
+		Parameters map[string]*openApiParameter `json:"parameters,omitempty"`
+	}
+
+	openApiDocument struct {
+		OpenApi    string                                  `json:"openapi"`
+		Paths      map[string]map[string]*openApiOperation `json:"paths"`
+		Components openApiComponents                       `json:"components"`
+	}
+)
+
+func loadOpenApiDocument() (*openApiDocument, error) {
+	var doc openApiDocument
+	if err := json.Unmarshal(openApiSpec, &doc); err != nil {
+		log.Println("error unmarshal openapi spec -> ", err)
+		return nil, err
+	}
+
+	return &doc, nil
+}
+
+func serveOpenApi(writer http.ResponseWriter, _ *http.Request) {
+	writer.Header().Set(contentType, applicationJson)
+	writer.WriteHeader(http.StatusOK)
+	_, _ = writer.Write(openApiSpec)
+}
+
+// operation returns the operation of the given path template (mux format is the same with openapi) and method.
+func (d *openApiDocument) operation(pathTemplate, method string) *openApiOperation {
+	item, ok := d.Paths[pathTemplate]
+	if !ok {
+		return nil
+	}
+
+	return item[strings.ToLower(method)]
+}
+
+func (d *openApiDocument) resolveParameter(p *openApiParameter) *openApiParameter {
+	if p.Ref == "" {
+		return p
+	}
+
+	resolved, ok := d.Components.Parameters[strings.TrimPrefix(p.Ref, refParameters)]
+	if !ok {
+		return p
+	}
+
+	return resolved
+}
+
+func (d *openApiDocument) resolveSchema(s *openApiSchema) *openApiSchema {
+	if s == nil || s.Ref == "" {
+		return s
+	}
+
+	resolved, ok := d.Components.Schemas[strings.TrimPrefix(s.Ref, refSchemas)]
+	if !ok {
+		return s
+	}
+
+	return resolved
+}
+
+// validateValue validate the decoded json value against the subset of json schema used by this service.
+func (d *openApiDocument) validateValue(field string, value interface{}, schema *openApiSchema) error {
+	schema = d.resolveSchema(schema)
+	if schema == nil {
+		return nil
+	}
+
+	for _, sub := range schema.AllOf {
+		if err := d.validateValue(field, value, sub); err != nil {
+			return err
+		}
+	}
+
+	if len(schema.Enum) > 0 && !containsValue(schema.Enum, value) {
+		return fmt.Errorf("%s should be one of %v", field, schema.Enum)
+	}
+
+	switch schema.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s should be an object", field)
+		}
+
+		return d.validateObject(field, obj, schema)
+
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s should be an array", field)
+		}
+
+		for idx, item := range arr {
+			if err := d.validateValue(fmt.Sprintf("%s[%d]", field, idx), item, schema.Items); err != nil {
+				return err
+			}
+		}
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s should be a string", field)
+		}
+
+		return validateString(field, str, schema)
+
+	case "number", "integer":
+		num, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("%s should be a %s", field, schema.Type)
+		}
+
+		if schema.Type == "integer" && num != math.Trunc(num) {
+			return fmt.Errorf("%s should be an integer", field)
+		}
+
+		return validateNumber(field, num, schema)
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s should be a boolean", field)
+		}
+	}
+
+	return nil
+}
+
+func (d *openApiDocument) validateObject(
+	field string,
+	obj map[string]interface{},
+	schema *openApiSchema) error {
+	for _, required := range schema.Required {
+		if _, ok := obj[required]; !ok {
+			return fmt.Errorf("%s.%s is required", field, required)
+		}
+	}
+
+	for key, val := range obj {
+		prop, ok := schema.Properties[key]
+		if !ok {
+			if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+				return fmt.Errorf("%s.%s is unknown field", field, key)
+			}
+			continue
+		}
+
+		if err := d.validateValue(field+"."+key, val, prop); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateString(field, str string, schema *openApiSchema) error {
+	length := len([]rune(str))
+	if schema.MinLength != nil && length < *schema.MinLength {
+		return fmt.Errorf("%s should have at least %d characters", field, *schema.MinLength)
+	}
+
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		return fmt.Errorf("%s should have at most %d characters", field, *schema.MaxLength)
+	}
+
+	return nil
+}
+
+func validateNumber(field string, num float64, schema *openApiSchema) error {
+	if schema.Minimum != nil {
+		if schema.ExclusiveMinimum && num <= *schema.Minimum {
+			return fmt.Errorf("%s should be greater than %v", field, *schema.Minimum)
+		}
+
+		if num < *schema.Minimum {
+			return fmt.Errorf("%s should be greater than or equals %v", field, *schema.Minimum)
+		}
+	}
+
+	if schema.Maximum != nil && num > *schema.Maximum {
+		return fmt.Errorf("%s should be less than or equals %v", field, *schema.Maximum)
+	}
+
+	return nil
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
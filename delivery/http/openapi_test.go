@@ -0,0 +1,290 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/constant"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/ratelimit"
+	mocksEod "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/application/eod"
+	mocksJournal "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/application/journal"
+	mocksLoan "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/application/loan"
+	mocksPayment "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/application/payment"
+	mocksProduct "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/application/product"
+	mocksVirtualAccount "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/application/virtualaccount"
+	mocksWebhook "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/application/webhook"
+	mocksConfiguration "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/configuration"
+)
+
+const testAdminKey = "admin-secret"
+
+// newTestRouter builds the routes of the billing, they are limited 1 request per client when the store is given.
+func newTestRouter(rateLimitStore ratelimit.Store) *mux.Router {
+	mockCfg := &mocksConfiguration.Configuration{}
+	if rateLimitStore != nil {
+		mockCfg.On("GetBool", "ratelimit.enabled").Return(true)
+		mockCfg.On("GetInt", mock.MatchedBy(func(key string) bool {
+			return strings.HasSuffix(key, ".client.rate") || strings.HasSuffix(key, ".client.burst")
+		})).Return(int64(1))
+	}
+
+	mockCfg.On("GetString", "admin.api.key").Return(testAdminKey)
+	mockCfg.On("GetString", mock.Anything).Return("")
+	mockCfg.On("GetBool", mock.Anything).Return(false)
+	mockCfg.On("GetInt", mock.Anything).Return(int64(0))
+	mockCfg.On("GetArray", mock.Anything).Return(nil)
+
+	mockController := &mocksLoan.Controller{}
+	ok := func(args mock.Arguments) {
+		args.Get(0).(http.ResponseWriter).WriteHeader(http.StatusOK)
+	}
+	mockController.On("FindOutstanding", mock.Anything, mock.Anything).Run(ok).Return()
+	mockController.On("Payment", mock.Anything, mock.Anything).Run(ok).Return()
+	mockController.On("FindPayment", mock.Anything, mock.Anything).Run(ok).Return()
+
+	mockPaymentController := &mocksPayment.Controller{}
+	mockPaymentController.On("Callback", mock.Anything, mock.Anything).Run(ok).Return()
+
+	mockVirtualAccountController := &mocksVirtualAccount.Controller{}
+	mockVirtualAccountController.On("Issue", mock.Anything, mock.Anything).Run(ok).Return()
+	mockVirtualAccountController.On("Lookup", mock.Anything, mock.Anything).Run(ok).Return()
+	mockVirtualAccountController.On("Credit", mock.Anything, mock.Anything).Run(ok).Return()
+
+	mockWebhookController := &mocksWebhook.Controller{}
+	mockWebhookController.On("CreateSubscription", mock.Anything, mock.Anything).Run(ok).Return()
+	mockWebhookController.On("FindSubscriptions", mock.Anything, mock.Anything).Run(ok).Return()
+	mockWebhookController.On("FindDeliveries", mock.Anything, mock.Anything).Run(ok).Return()
+	mockWebhookController.On("ReplayDelivery", mock.Anything, mock.Anything).Run(ok).Return()
+
+	mockProductController := &mocksProduct.Controller{}
+	mockProductController.On("CreateProduct", mock.Anything, mock.Anything).Run(ok).Return()
+	mockProductController.On("FindProducts", mock.Anything, mock.Anything).Run(ok).Return()
+
+	mockJournalController := &mocksJournal.Controller{}
+	mockJournalController.On("FindBalances", mock.Anything, mock.Anything).Run(ok).Return()
+
+	mockEodController := &mocksEod.Controller{}
+	mockEodController.On("FindRuns", mock.Anything, mock.Anything).Run(ok).Return()
+	mockEodController.On("BusinessDate", mock.Anything, mock.Anything).Run(ok).Return()
+	mockEodController.On("AdvanceBusinessDate", mock.Anything, mock.Anything).Run(ok).Return()
+
+	router := mux.NewRouter()
+	NewBillingHandler(
+		mockCfg, mockController, mockWebhookController, mockPaymentController, mockVirtualAccountController,
+		mockProductController, mockJournalController, mockEodController, rateLimitStore).BuildHttp(router)
+
+	return router
+}
+
+func Test_openApi_routesShouldMatchSpec(t *testing.T) {
+	router := newTestRouter(nil)
+	document, err := loadOpenApiDocument()
+	assert.Nil(t, err)
+
+	var routes []string
+	errWalk := router.Walk(
+		func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+			pathTemplate, _ := route.GetPathTemplate()
+			methods, _ := route.GetMethods()
+
+			for _, method := range methods {
+				routes = append(routes, method+" "+pathTemplate)
+			}
+			return nil
+		})
+	assert.Nil(t, errWalk)
+
+	var specs []string
+	for path, item := range document.Paths {
+		for method := range item {
+			specs = append(specs, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	sort.Strings(routes)
+	sort.Strings(specs)
+
+	assert.Equal(t, routes, specs, "handlers and openapi.json are diverged")
+}
+
+func Test_openApi_rcShouldMatchSpec(t *testing.T) {
+	document, err := loadOpenApiDocument()
+	assert.Nil(t, err)
+
+	var rcs []string
+	for _, rc := range constant.HttpRc {
+		rcs = append(rcs, rc)
+	}
+
+	var specs []string
+	for _, rc := range document.Components.Schemas["BillingResponse"].Properties["rc"].Enum {
+		specs = append(specs, rc.(string))
+	}
+
+	sort.Strings(rcs)
+	sort.Strings(specs)
+
+	assert.Equal(t, rcs, specs, "constant.HttpRc and openapi.json are diverged")
+}
+
+func Test_requestValidator_middleware(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		adminKey    string
+		body        string
+		want        int
+	}{
+		{
+			name: "given valid payment request," +
+				"when validate," +
+				"then passed to the handler",
+			method:      http.MethodPost,
+			path:        "/v1/customer/payment",
+			contentType: "application/json",
+			body:        `{"user_id":"abc","amount":100}`,
+			want:        http.StatusOK,
+		},
+		{
+			name: "given payment request without user_id," +
+				"when validate," +
+				"then return bad request",
+			method:      http.MethodPost,
+			path:        "/v1/customer/payment",
+			contentType: "application/json",
+			body:        `{"amount":100}`,
+			want:        http.StatusBadRequest,
+		},
+		{
+			name: "given payment request with unknown field," +
+				"when validate," +
+				"then return bad request",
+			method:      http.MethodPost,
+			path:        "/v1/customer/payment",
+			contentType: "application/json",
+			body:        `{"user_id":"abc","amount":100,"fee":1}`,
+			want:        http.StatusBadRequest,
+		},
+		{
+			name: "given payment request with zero amount," +
+				"when validate," +
+				"then return bad request",
+			method:      http.MethodPost,
+			path:        "/v1/customer/payment",
+			contentType: "application/json",
+			body:        `{"user_id":"abc","amount":0}`,
+			want:        http.StatusBadRequest,
+		},
+		{
+			name: "given payment request with wrong type," +
+				"when validate," +
+				"then return bad request",
+			method:      http.MethodPost,
+			path:        "/v1/customer/payment",
+			contentType: "application/json",
+			body:        `{"user_id":"abc","amount":"100"}`,
+			want:        http.StatusBadRequest,
+		},
+		{
+			name: "given payment request with unsupported content type," +
+				"when validate," +
+				"then return bad request",
+			method:      http.MethodPost,
+			path:        "/v1/customer/payment",
+			contentType: "text/plain",
+			body:        `{"user_id":"abc","amount":100}`,
+			want:        http.StatusBadRequest,
+		},
+		{
+			name: "given payment request larger than the peek limit," +
+				"when validate," +
+				"then return request entity too large instead of truncating it",
+			method:      http.MethodPost,
+			path:        "/v1/customer/payment",
+			contentType: "application/json",
+			body:        `{"user_id":"abc","amount":100}` + strings.Repeat(" ", maxPeekBody),
+			want:        http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "given valid outstanding request," +
+				"when validate," +
+				"then passed to the handler",
+			method: http.MethodGet,
+			path:   "/v1/customer/outstanding/abc",
+			want:   http.StatusOK,
+		},
+		{
+			name: "given admin request without admin key," +
+				"when validate," +
+				"then return unauthorized",
+			method: http.MethodGet,
+			path:   "/v1/admin/webhooks/subscriptions",
+			want:   http.StatusUnauthorized,
+		},
+		{
+			name: "given admin request with wrong admin key," +
+				"when validate," +
+				"then return unauthorized",
+			method:   http.MethodGet,
+			path:     "/v1/admin/webhooks/subscriptions",
+			adminKey: "wrong",
+			want:     http.StatusUnauthorized,
+		},
+		{
+			name: "given valid admin request," +
+				"when validate," +
+				"then passed to the handler",
+			method:   http.MethodGet,
+			path:     "/v1/admin/webhooks/deliveries?status=DEAD&limit=10",
+			adminKey: testAdminKey,
+			want:     http.StatusOK,
+		},
+		{
+			name: "given admin request with invalid query," +
+				"when validate," +
+				"then return bad request",
+			method:   http.MethodGet,
+			path:     "/v1/admin/webhooks/deliveries?status=UNKNOWN",
+			adminKey: testAdminKey,
+			want:     http.StatusBadRequest,
+		},
+		{
+			name: "given the spec itself," +
+				"when request," +
+				"then return the spec",
+			method: http.MethodGet,
+			path:   "/openapi.json",
+			want:   http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				router := newTestRouter(nil)
+
+				req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+				if tt.contentType != "" {
+					req.Header.Set("Content-Type", tt.contentType)
+				}
+
+				if tt.adminKey != "" {
+					req.Header.Set(headerAdminKey, tt.adminKey)
+				}
+
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+
+				assert.Equal(t, tt.want, rec.Code)
+			})
+	}
+}
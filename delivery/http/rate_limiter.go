@@ -0,0 +1,211 @@
+package http
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/constant"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/ratelimit"
+)
+
+const (
+	headerApiKey     = "X-API-Key"
+	headerRetryAfter = "Retry-After"
+
+	policyDefault = "default"
+	policyPayment = "payment"
+)
+
+type (
+	rateLimitPolicy struct {
+		name   string
+		client ratelimit.Limit
+		user   ratelimit.Limit
+	}
+
+	rateLimiter struct {
+		enabled bool
+		store   ratelimit.Store
+		// clientKeys are the API keys issued to the clients, the request with any other key is limited by its ip,
+		// so the caller can't get a fresh bucket by sending a random key.
+		clientKeys []string
+		// policies are the policies of the limited routes, registered by route while the routes are built.
+		policies map[*mux.Route]rateLimitPolicy
+	}
+)
+
+func newRateLimiter(
+	configuration configuration.Configuration,
+	store ratelimit.Store) *rateLimiter {
+	return &rateLimiter{
+		enabled:    configuration.GetBool("ratelimit.enabled") && store != nil,
+		store:      store,
+		clientKeys: configuration.GetArray("ratelimit.client.keys"),
+		policies:   make(map[*mux.Route]rateLimitPolicy),
+	}
+}
+
+func newRateLimitPolicy(
+	configuration configuration.Configuration,
+	name string) rateLimitPolicy {
+	baseKey := "ratelimit." + name
+
+	return rateLimitPolicy{
+		name: name,
+		client: ratelimit.Limit{
+			Rate:  configuration.GetInt(baseKey + ".client.rate"),
+			Burst: configuration.GetInt(baseKey + ".client.burst"),
+		},
+		user: ratelimit.Limit{
+			Rate:  configuration.GetInt(baseKey + ".user.rate"),
+			Burst: configuration.GetInt(baseKey + ".user.burst"),
+		},
+	}
+}
+
+// route limits the route by the policy through the middleware.
+func (r *rateLimiter) route(policy rateLimitPolicy, route *mux.Route) *mux.Route {
+	r.policies[route] = policy
+	return route
+}
+
+// middleware limits the matched route by its policy, the route which is not limited is passed as is.
+// It runs before the request is validated, so the malformed and the oversized requests are counted as well.
+func (r *rateLimiter) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			route := mux.CurrentRoute(req)
+			if route == nil {
+				next.ServeHTTP(writer, req)
+				return
+			}
+
+			policy, ok := r.policies[route]
+			if !ok {
+				next.ServeHTTP(writer, req)
+				return
+			}
+
+			r.limit(policy, next.ServeHTTP)(writer, req)
+		})
+}
+
+func (r *rateLimiter) limit(
+	policy rateLimitPolicy,
+	next http.HandlerFunc) http.HandlerFunc {
+	if !r.enabled {
+		return next
+	}
+
+	return func(writer http.ResponseWriter, req *http.Request) {
+		clientKey := policy.name + ":client:" + r.findClientKey(req)
+		if !r.allow(writer, req, clientKey, policy.client) {
+			return
+		}
+
+		userID, err := findUserID(req)
+		if errors.Is(err, errorBodyTooLarge) {
+			toBodyTooLargeResponse(writer)
+			return
+		}
+
+		if userID != "" {
+			userKey := policy.name + ":user:" + userID
+			if !r.allow(writer, req, userKey, policy.user) {
+				return
+			}
+		}
+
+		next(writer, req)
+	}
+}
+
+func (r *rateLimiter) allow(
+	writer http.ResponseWriter,
+	req *http.Request,
+	key string,
+	limit ratelimit.Limit) bool {
+	decision, err := r.store.Take(req.Context(), key, limit)
+
+	//fail open, the rate limiter should not take down the billing when the store is unhealthy
+	if err != nil {
+		log.Println("failed take token from rate limit store -> ", err)
+		return true
+	}
+
+	if decision.Allowed {
+		return true
+	}
+
+	retryAfter := int64(decision.RetryAfter.Seconds())
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+
+	writer.Header().Set(headerRetryAfter, strconv.FormatInt(retryAfter, 10))
+	common.ToErrorResponse(
+		writer,
+		constant.HttpRc[constant.TooManyRequests],
+		constant.HttpRcDescription[constant.TooManyRequests],
+	)
+
+	return false
+}
+
+// findClientKey returns the API key of the client when it is one of the issued keys, the client ip otherwise.
+func (r *rateLimiter) findClientKey(req *http.Request) string {
+	apiKey := req.Header.Get(headerApiKey)
+	if apiKey != "" && r.isClientKey(apiKey) {
+		return apiKey
+	}
+
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+
+	return host
+}
+
+func (r *rateLimiter) isClientKey(apiKey string) bool {
+	for _, clientKey := range r.clientKeys {
+		if subtle.ConstantTimeCompare([]byte(apiKey), []byte(clientKey)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findUserID looking for the userID from the path first, then from the body of request.
+// The body is restored, so the next handler still able to decode it.
+func findUserID(req *http.Request) (string, error) {
+	userID, ok := mux.Vars(req)["userID"]
+	if ok {
+		return userID, nil
+	}
+
+	body, err := peekBody(req)
+	if err != nil || body == nil {
+		return "", err
+	}
+
+	var payload struct {
+		UserID string `json:"user_id"`
+	}
+
+	if err = json.Unmarshal(body, &payload); err != nil {
+		return "", nil
+	}
+
+	return payload.UserID, nil
+}
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/ratelimit"
+)
+
+func Test_rateLimiter_limit(t *testing.T) {
+	type call struct {
+		apiKey     string
+		remoteAddr string
+		body       string
+		want       int
+	}
+
+	tests := []struct {
+		name  string
+		calls []call
+	}{
+		{
+			name: "given the client exceeds its burst," +
+				"when limit," +
+				"then return too many requests with retry after",
+			calls: []call{
+				{apiKey: "key-a", remoteAddr: "10.0.0.1:1000", want: http.StatusOK},
+				{apiKey: "key-a", remoteAddr: "10.0.0.1:1000", want: http.StatusTooManyRequests},
+			},
+		},
+		{
+			name: "given two issued keys behind the same ip," +
+				"when limit," +
+				"then every key has its own bucket",
+			calls: []call{
+				{apiKey: "key-a", remoteAddr: "10.0.0.1:1000", want: http.StatusOK},
+				{apiKey: "key-b", remoteAddr: "10.0.0.1:1000", want: http.StatusOK},
+				{apiKey: "key-a", remoteAddr: "10.0.0.1:1000", want: http.StatusTooManyRequests},
+			},
+		},
+		{
+			name: "given the keys which are not issued," +
+				"when limit," +
+				"then they share the bucket of the client ip",
+			calls: []call{
+				{apiKey: "random-1", remoteAddr: "10.0.0.1:1000", want: http.StatusOK},
+				{apiKey: "random-2", remoteAddr: "10.0.0.1:2000", want: http.StatusTooManyRequests},
+				{remoteAddr: "10.0.0.1:3000", want: http.StatusTooManyRequests},
+				{apiKey: "random-3", remoteAddr: "10.0.0.2:1000", want: http.StatusOK},
+			},
+		},
+		{
+			name: "given the body larger than the peek limit," +
+				"when limit," +
+				"then return request entity too large instead of limiting by the truncated body",
+			calls: []call{
+				{
+					apiKey:     "key-a",
+					remoteAddr: "10.0.0.1:1000",
+					body:       `{"user_id":"abc"}` + strings.Repeat(" ", maxPeekBody),
+					want:       http.StatusRequestEntityTooLarge,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				limiter := &rateLimiter{
+					enabled:    true,
+					store:      ratelimit.NewMemoryStore(),
+					clientKeys: []string{"key-a", "key-b"},
+				}
+				policy := rateLimitPolicy{name: policyDefault, client: ratelimit.Limit{Rate: 1, Burst: 1}}
+				handler := limiter.limit(
+					policy, func(writer http.ResponseWriter, _ *http.Request) {
+						writer.WriteHeader(http.StatusOK)
+					})
+
+				for _, c := range tt.calls {
+					req := httptest.NewRequest(http.MethodPost, "/v1/customer/payment", strings.NewReader(c.body))
+					req.RemoteAddr = c.remoteAddr
+					if c.apiKey != "" {
+						req.Header.Set(headerApiKey, c.apiKey)
+					}
+
+					rec := httptest.NewRecorder()
+					handler(rec, req)
+
+					assert.Equal(t, c.want, rec.Code)
+					if c.want == http.StatusTooManyRequests {
+						assert.Equal(t, "60", rec.Header().Get(headerRetryAfter))
+					} else {
+						assert.Empty(t, rec.Header().Get(headerRetryAfter))
+					}
+				}
+			})
+	}
+}
+
+func Test_rateLimiter_middleware(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		wants []int
+	}{
+		{
+			name: "given the malformed request," +
+				"when it is sent again," +
+				"then it is limited before it is validated",
+			body:  `{"amount":100}`,
+			wants: []int{http.StatusBadRequest, http.StatusTooManyRequests},
+		},
+		{
+			name: "given the request larger than the peek limit," +
+				"when it is sent again," +
+				"then it is limited before its body is read",
+			body:  `{"user_id":"abc","amount":100}` + strings.Repeat(" ", maxPeekBody),
+			wants: []int{http.StatusRequestEntityTooLarge, http.StatusTooManyRequests},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				router := newTestRouter(ratelimit.NewMemoryStore())
+
+				for _, want := range tt.wants {
+					req := httptest.NewRequest(http.MethodPost, "/v1/customer/payment", strings.NewReader(tt.body))
+					req.Header.Set(contentType, "application/json")
+					req.RemoteAddr = "10.0.0.1:1000"
+
+					rec := httptest.NewRecorder()
+					router.ServeHTTP(rec, req)
+
+					assert.Equal(t, want, rec.Code)
+				}
+			})
+	}
+}
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/constant"
+)
+
+const (
+	// maxPeekBody is the largest body the middlewares read before the handler, 1 MiB.
+	maxPeekBody = 1048576
+)
+
+var (
+	errorBodyTooLarge = errors.New("request body is too large")
+)
+
+// peekBody reads the body of the request and restores it, so the next handler is still able to decode it.
+// The body larger than maxPeekBody returns errorBodyTooLarge instead of being truncated.
+func peekBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxPeekBody+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(body) > maxPeekBody {
+		return nil, errorBodyTooLarge
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func toBodyTooLargeResponse(writer http.ResponseWriter) {
+	common.ToErrorResponse(
+		writer,
+		constant.HttpRc[constant.RequestTooLarge],
+		constant.HttpRcDescription[constant.RequestTooLarge],
+	)
+}
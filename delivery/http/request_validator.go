@@ -0,0 +1,163 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/constant"
+)
+
+type requestValidator struct {
+	document *openApiDocument
+}
+
+func newRequestValidator(document *openApiDocument) *requestValidator {
+	return &requestValidator{
+		document: document,
+	}
+}
+
+// middleware validates the request against the openapi spec, the route which is not exists
+// on the spec will be passed as is.
+func (v *requestValidator) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			route := mux.CurrentRoute(req)
+			if route == nil {
+				next.ServeHTTP(writer, req)
+				return
+			}
+
+			pathTemplate, err := route.GetPathTemplate()
+			if err != nil {
+				next.ServeHTTP(writer, req)
+				return
+			}
+
+			operation := v.document.operation(pathTemplate, req.Method)
+			if operation == nil {
+				next.ServeHTTP(writer, req)
+				return
+			}
+
+			err = v.validate(req, operation)
+			if errors.Is(err, errorBodyTooLarge) {
+				toBodyTooLargeResponse(writer)
+				return
+			}
+
+			if err != nil {
+				log.Println("validation openapi -> ", err)
+
+				common.ToErrorResponse(
+					writer,
+					constant.HttpRc[constant.Validation],
+					constant.HttpRcDescription[constant.Validation],
+				)
+				return
+			}
+
+			next.ServeHTTP(writer, req)
+		})
+}
+
+func (v *requestValidator) validate(req *http.Request, operation *openApiOperation) error {
+	vars := mux.Vars(req)
+
+	for _, param := range operation.Parameters {
+		p := v.document.resolveParameter(param)
+
+		value, exists := func() (string, bool) {
+			switch p.In {
+			case "path":
+				val, ok := vars[p.Name]
+				return val, ok
+			case "query":
+				val, ok := req.URL.Query()[p.Name]
+				if !ok || len(val) == 0 {
+					return "", false
+				}
+				return val[0], true
+			case "header":
+				val := req.Header.Get(p.Name)
+				return val, val != ""
+			}
+
+			return "", false
+		}()
+
+		if !exists {
+			if p.Required {
+				return fmt.Errorf("%s %s is required", p.In, p.Name)
+			}
+			continue
+		}
+
+		if err := v.document.validateValue(p.Name, parseParameter(value, p.Schema), p.Schema); err != nil {
+			return err
+		}
+	}
+
+	if operation.RequestBody == nil {
+		return nil
+	}
+
+	return v.validateBody(req, operation.RequestBody)
+}
+
+func (v *requestValidator) validateBody(req *http.Request, requestBody *openApiRequestBody) error {
+	body, err := peekBody(req)
+	if err != nil {
+		return err
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if requestBody.Required {
+			return fmt.Errorf("request body is required")
+		}
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get(contentType))
+	if err != nil {
+		return fmt.Errorf("invalid content type: %w", err)
+	}
+
+	media, ok := requestBody.Content[mediaType]
+	if !ok {
+		return fmt.Errorf("content type %s is not supported", mediaType)
+	}
+
+	var value interface{}
+	if err = json.Unmarshal(body, &value); err != nil {
+		return fmt.Errorf("request body contains badly-formed JSON: %w", err)
+	}
+
+	return v.document.validateValue("body", value, media.Schema)
+}
+
+// parseParameter converts the raw parameter into the type of the schema, so it can be
+// validated the same way as the body.
+func parseParameter(value string, schema *openApiSchema) interface{} {
+	if schema == nil {
+		return value
+	}
+
+	switch schema.Type {
+	case "number", "integer", "boolean":
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(value), &parsed); err == nil {
+			return parsed
+		}
+	}
+
+	return value
+}
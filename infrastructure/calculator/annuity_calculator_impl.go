@@ -0,0 +1,30 @@
+package calculator
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+type annuityCalculator struct{}
+
+func (a *annuityCalculator) Schedule(request *ScheduleRequest) ([]*Installment, error) {
+	if err := validate(request); err != nil {
+		return nil, err
+	}
+
+	rate := periodicRate(request)
+	tenor := decimal.NewFromInt(int64(request.Tenor))
+
+	//payment = principal * rate / (1 - (1 + rate)^-tenor), without interest it is the equal principal
+	payment := request.Principal.Div(tenor).RoundDown(2)
+	if rate.IsPositive() {
+		discount := decimal.NewFromInt(1).Sub(decimal.NewFromInt(1).Div(decimal.NewFromInt(1).Add(rate).Pow(tenor)))
+		payment = request.Principal.Mul(rate).Div(discount).Round(2)
+	}
+
+	return schedule(
+		request, func(sequence int, balance decimal.Decimal) (decimal.Decimal, decimal.Decimal) {
+			interest := balance.Mul(rate).Round(2)
+			return payment.Sub(interest), interest
+		},
+	), nil
+}
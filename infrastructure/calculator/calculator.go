@@ -0,0 +1,166 @@
+package calculator
+
+import (
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/calendar"
+)
+
+// the interest methods, they differ on how the interest of each period is derived.
+const (
+	// MethodFlat charges the interest on the original principal every period.
+	MethodFlat = "FLAT"
+	// MethodEffective charges the interest on the remaining balance (declining balance), the principal is equal.
+	MethodEffective = "EFFECTIVE"
+	// MethodAnnuity keeps the installment amount equal, the principal grows as the interest declines.
+	MethodAnnuity = "ANNUITY"
+)
+
+// the frequencies of the installments.
+const (
+	FrequencyWeekly   = "WEEKLY"
+	FrequencyBiweekly = "BIWEEKLY"
+	FrequencyMonthly  = "MONTHLY"
+)
+
+var (
+	ErrorUnknownMethod  = errors.New("unknown interest method")
+	ErrorInvalidRequest = errors.New("invalid schedule request")
+)
+
+type (
+	ScheduleRequest struct {
+		Principal decimal.Decimal `json:"principal"`
+		// AnnualRate is the interest rate per year, e.g. 0.24 for 24%.
+		AnnualRate decimal.Decimal `json:"annual_rate"`
+		Tenor      int             `json:"tenor"`
+		Frequency  string          `json:"frequency"`
+		// FirstDueDate is the due date of the first installment, the next ones follow the frequency.
+		FirstDueDate time.Time `json:"first_due_date"`
+		// Calendar moves the due date falling on the non business day by its policy, nil keeps the due dates.
+		// The periods still follow the frequency from the first due date, so the shifts don't accumulate.
+		Calendar calendar.Calendar `json:"-"`
+	}
+
+	Installment struct {
+		Sequence  int             `json:"sequence"`
+		DueDate   time.Time       `json:"due_date"`
+		Principal decimal.Decimal `json:"principal"`
+		Interest  decimal.Decimal `json:"interest"`
+		// Amount is the principal and the interest.
+		Amount decimal.Decimal `json:"amount"`
+		// Balance is the remaining principal after the installment is paid.
+		Balance decimal.Decimal `json:"balance"`
+	}
+
+	// Calculator produces the installment schedule, rounded into cents. The rounding is deterministic
+	// and the last installment absorbs the remainder, so the principal is always fully scheduled.
+	Calculator interface {
+		Schedule(request *ScheduleRequest) ([]*Installment, error)
+	}
+)
+
+// NewCalculator returns the calculator of the interest method.
+func NewCalculator(method string) (Calculator, error) {
+	switch method {
+	case MethodFlat:
+		return &flatCalculator{}, nil
+	case MethodEffective:
+		return &effectiveCalculator{}, nil
+	case MethodAnnuity:
+		return &annuityCalculator{}, nil
+	}
+
+	return nil, ErrorUnknownMethod
+}
+
+// DueDate is the due date of the given period after the start, by the frequency.
+func DueDate(start time.Time, frequency string, period int) time.Time {
+	switch frequency {
+	case FrequencyBiweekly:
+		return start.AddDate(0, 0, 14*period)
+	case FrequencyMonthly:
+		return addMonths(start, period)
+	}
+
+	return start.AddDate(0, 0, 7*period)
+}
+
+// IsFrequency reports whether the frequency is supported.
+func IsFrequency(frequency string) bool {
+	return frequency == FrequencyWeekly || frequency == FrequencyBiweekly || frequency == FrequencyMonthly
+}
+
+// addMonths keeps the day of month, it is clamped into the last day of the shorter month instead of
+// overflowing into the next one (e.g. 31 Oct + 1 month is 30 Nov).
+func addMonths(t time.Time, months int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month()+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+
+	return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+// periodicRate is the annual rate divided by the periods in a year.
+func periodicRate(request *ScheduleRequest) decimal.Decimal {
+	periods := int64(52)
+	switch request.Frequency {
+	case FrequencyBiweekly:
+		periods = 26
+	case FrequencyMonthly:
+		periods = 12
+	}
+
+	return request.AnnualRate.Div(decimal.NewFromInt(periods))
+}
+
+func validate(request *ScheduleRequest) error {
+	if !request.Principal.IsPositive() || request.AnnualRate.IsNegative() || request.Tenor <= 0 ||
+		!IsFrequency(request.Frequency) {
+		return ErrorInvalidRequest
+	}
+
+	return nil
+}
+
+// schedule builds the installments by the principal and the interest of each period given the remaining balance,
+// the principal of the last installment is the remaining balance.
+func schedule(
+	request *ScheduleRequest,
+	next func(sequence int, balance decimal.Decimal) (principal, interest decimal.Decimal)) []*Installment {
+	balance := request.Principal
+	installments := make([]*Installment, 0, request.Tenor)
+
+	for sequence := 1; sequence <= request.Tenor; sequence++ {
+		principal, interest := next(sequence, balance)
+		if sequence == request.Tenor {
+			principal = balance
+		}
+
+		dueDate := DueDate(request.FirstDueDate, request.Frequency, sequence-1)
+		if request.Calendar != nil {
+			dueDate = request.Calendar.Adjust(dueDate)
+		}
+
+		balance = balance.Sub(principal)
+		installments = append(
+			installments, &Installment{
+				Sequence:  sequence,
+				DueDate:   dueDate,
+				Principal: principal,
+				Interest:  interest,
+				Amount:    principal.Add(interest),
+				Balance:   balance,
+			},
+		)
+	}
+
+	return installments
+}
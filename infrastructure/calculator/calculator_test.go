@@ -0,0 +1,221 @@
+package calculator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/calendar"
+)
+
+func Test_Calculator_Schedule(t *testing.T) {
+	firstDueDate := time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)
+	monthly := func() *ScheduleRequest {
+		return &ScheduleRequest{
+			Principal:    decimal.NewFromFloat(1000),
+			AnnualRate:   decimal.NewFromFloat(0.12),
+			Tenor:        3,
+			Frequency:    FrequencyMonthly,
+			FirstDueDate: firstDueDate,
+		}
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		request        *ScheduleRequest
+		wantPrincipals []string
+		wantInterests  []string
+		wantErr        error
+	}{
+		{
+			name: "given flat method," +
+				"when schedule," +
+				"then the interest is charged on the original principal",
+			method:         MethodFlat,
+			request:        monthly(),
+			wantPrincipals: []string{"333.33", "333.33", "333.34"},
+			wantInterests:  []string{"10", "10", "10"},
+		},
+		{
+			name: "given effective method," +
+				"when schedule," +
+				"then the interest is charged on the remaining balance",
+			method:         MethodEffective,
+			request:        monthly(),
+			wantPrincipals: []string{"333.33", "333.33", "333.34"},
+			wantInterests:  []string{"10", "6.67", "3.33"},
+		},
+		{
+			name: "given annuity method," +
+				"when schedule," +
+				"then the amount is equal except the last which absorbs the remainder",
+			method:         MethodAnnuity,
+			request:        monthly(),
+			wantPrincipals: []string{"330.02", "333.32", "336.66"},
+			wantInterests:  []string{"10", "6.7", "3.37"},
+		},
+		{
+			name: "given annuity method without interest," +
+				"when schedule," +
+				"then the principal is equal",
+			method: MethodAnnuity,
+			request: &ScheduleRequest{
+				Principal:    decimal.NewFromFloat(100),
+				Tenor:        3,
+				Frequency:    FrequencyWeekly,
+				FirstDueDate: firstDueDate,
+			},
+			wantPrincipals: []string{"33.33", "33.33", "33.34"},
+			wantInterests:  []string{"0", "0", "0"},
+		},
+		{
+			name: "given flat interest not divisible by the tenor," +
+				"when schedule," +
+				"then the last installment absorbs the interest remainder",
+			method: MethodFlat,
+			request: &ScheduleRequest{
+				Principal:    decimal.NewFromFloat(1000),
+				AnnualRate:   decimal.NewFromFloat(0.04),
+				Tenor:        3,
+				Frequency:    FrequencyMonthly,
+				FirstDueDate: firstDueDate,
+			},
+			wantPrincipals: []string{"333.33", "333.33", "333.34"},
+			wantInterests:  []string{"3.33", "3.33", "3.34"},
+		},
+		{
+			name: "given unknown frequency," +
+				"when schedule," +
+				"then return error",
+			method: MethodFlat,
+			request: &ScheduleRequest{
+				Principal: decimal.NewFromFloat(1000),
+				Tenor:     3,
+				Frequency: "DAILY",
+			},
+			wantErr: ErrorInvalidRequest,
+		},
+		{
+			name: "given zero tenor," +
+				"when schedule," +
+				"then return error",
+			method: MethodEffective,
+			request: &ScheduleRequest{
+				Principal: decimal.NewFromFloat(1000),
+				Frequency: FrequencyWeekly,
+			},
+			wantErr: ErrorInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				c, err := NewCalculator(tt.method)
+				assert.Nil(t, err)
+
+				got, err := c.Schedule(tt.request)
+				assert.Equal(t, tt.wantErr, err)
+
+				if tt.wantErr != nil {
+					return
+				}
+
+				var principals, interests []string
+				total := decimal.Zero
+				for _, installment := range got {
+					principals = append(principals, installment.Principal.String())
+					interests = append(interests, installment.Interest.String())
+					total = total.Add(installment.Principal)
+
+					assert.True(t, installment.Amount.Equal(installment.Principal.Add(installment.Interest)))
+				}
+
+				assert.Equal(t, tt.wantPrincipals, principals)
+				assert.Equal(t, tt.wantInterests, interests)
+				assert.True(t, total.Equal(tt.request.Principal))
+				assert.True(t, got[len(got)-1].Balance.IsZero())
+			})
+	}
+}
+
+func Test_Calculator_Schedule_Calendar(t *testing.T) {
+	//2026-10-31 is saturday, 2026-12-31 is a holiday
+	weekend := []time.Weekday{time.Saturday, time.Sunday}
+	holidays := []*calendar.Holiday{{Region: "ID", Date: time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)}}
+
+	tests := []struct {
+		name         string
+		policy       string
+		wantDueDates []time.Time
+	}{
+		{
+			name: "given policy forward," +
+				"when schedule," +
+				"then the due dates are moved into the next business day without accumulating",
+			policy: calendar.PolicyForward,
+			wantDueDates: []time.Time{
+				time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC),
+				time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC),
+				time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "given policy backward," +
+				"when schedule," +
+				"then the due dates are moved into the previous business day",
+			policy: calendar.PolicyBackward,
+			wantDueDates: []time.Time{
+				time.Date(2026, 10, 30, 0, 0, 0, 0, time.UTC),
+				time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC),
+				time.Date(2026, 12, 30, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				c, err := NewCalculator(MethodFlat)
+				assert.Nil(t, err)
+
+				got, err := c.Schedule(
+					&ScheduleRequest{
+						Principal:    decimal.NewFromFloat(1000),
+						AnnualRate:   decimal.NewFromFloat(0.12),
+						Tenor:        3,
+						Frequency:    FrequencyMonthly,
+						FirstDueDate: time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC),
+						Calendar:     calendar.NewCalendar("ID", tt.policy, weekend, holidays),
+					},
+				)
+				assert.Nil(t, err)
+
+				var dueDates []time.Time
+				for _, installment := range got {
+					dueDates = append(dueDates, installment.DueDate)
+				}
+
+				assert.Equal(t, tt.wantDueDates, dueDates)
+			})
+	}
+}
+
+func Test_NewCalculator(t *testing.T) {
+	_, err := NewCalculator("BALLOON")
+	assert.Equal(t, ErrorUnknownMethod, err)
+}
+
+func Test_DueDate(t *testing.T) {
+	start := time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2026, 11, 14, 0, 0, 0, 0, time.UTC), DueDate(start, FrequencyWeekly, 2))
+	assert.Equal(t, time.Date(2026, 11, 28, 0, 0, 0, 0, time.UTC), DueDate(start, FrequencyBiweekly, 2))
+	//the day of month is clamped into the shorter month, not carried into the next one
+	assert.Equal(t, time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC), DueDate(start, FrequencyMonthly, 1))
+	assert.Equal(t, time.Date(2027, 2, 28, 0, 0, 0, 0, time.UTC), DueDate(start, FrequencyMonthly, 4))
+	assert.Equal(t, time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC), DueDate(start, FrequencyMonthly, 2))
+}
@@ -0,0 +1,22 @@
+package calculator
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+type effectiveCalculator struct{}
+
+func (e *effectiveCalculator) Schedule(request *ScheduleRequest) ([]*Installment, error) {
+	if err := validate(request); err != nil {
+		return nil, err
+	}
+
+	rate := periodicRate(request)
+	principal := request.Principal.Div(decimal.NewFromInt(int64(request.Tenor))).RoundDown(2)
+
+	return schedule(
+		request, func(sequence int, balance decimal.Decimal) (decimal.Decimal, decimal.Decimal) {
+			return principal, balance.Mul(rate).Round(2)
+		},
+	), nil
+}
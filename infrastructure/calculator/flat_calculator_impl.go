@@ -0,0 +1,29 @@
+package calculator
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+type flatCalculator struct{}
+
+func (f *flatCalculator) Schedule(request *ScheduleRequest) ([]*Installment, error) {
+	if err := validate(request); err != nil {
+		return nil, err
+	}
+
+	tenor := decimal.NewFromInt(int64(request.Tenor))
+	totalInterest := request.Principal.Mul(periodicRate(request)).Mul(tenor).Round(2)
+	principal := request.Principal.Div(tenor).RoundDown(2)
+	interest := totalInterest.Div(tenor).RoundDown(2)
+
+	//the interest is spread evenly the same way as the principal, the remainder goes to the last installment
+	return schedule(
+		request, func(sequence int, balance decimal.Decimal) (decimal.Decimal, decimal.Decimal) {
+			if sequence == request.Tenor {
+				return balance, totalInterest.Sub(interest.Mul(decimal.NewFromInt(int64(request.Tenor - 1))))
+			}
+
+			return principal, interest
+		},
+	), nil
+}
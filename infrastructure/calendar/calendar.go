@@ -0,0 +1,174 @@
+package calendar
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+)
+
+// the policies moving the due date which falls on the non business day.
+const (
+	// PolicyForward moves the due date into the next business day.
+	PolicyForward = "FORWARD"
+	// PolicyBackward moves the due date into the previous business day.
+	PolicyBackward = "BACKWARD"
+	// PolicyNone keeps the due date, it is still not late until the next business day is over.
+	PolicyNone = "NONE"
+)
+
+const (
+	SourceFile  = "file"
+	SourceTable = "table"
+
+	DefaultRegion = "ID"
+
+	dateLayout = "2006-01-02"
+	// maxShift bounds the search of the business day, the calendar without any is kept as is.
+	maxShift = 366
+)
+
+var ErrorInvalidHoliday = errors.New("invalid holiday")
+
+type (
+	Holiday struct {
+		Region string
+		Date   time.Time
+		Name   string
+	}
+
+	// Calendar tells the business days of the region, the day which is neither the weekend nor the holiday.
+	Calendar interface {
+		Region() string
+
+		IsBusinessDay(date time.Time) bool
+
+		// NextBusinessDay returns the date itself when it is the business day, otherwise the first business day after it.
+		NextBusinessDay(date time.Time) time.Time
+
+		// Adjust moves the due date falling on the non business day by the policy, the time of the day is kept.
+		Adjust(date time.Time) time.Time
+
+		// LateBefore is the start of the day the installments due before it are late on the given day.
+		// The installment due on the non business day is late only once the next business day is over,
+		// hence the installments due after the last business day before the day are not late yet.
+		LateBefore(day time.Time) time.Time
+	}
+
+	calendar struct {
+		region   string
+		policy   string
+		weekend  map[time.Weekday]bool
+		holidays map[string]bool
+	}
+)
+
+// NewCalendar returns the calendar of the region, the holidays of the other regions are ignored.
+func NewCalendar(region string, policy string, weekend []time.Weekday, holidays []*Holiday) Calendar {
+	c := &calendar{
+		region:   region,
+		policy:   policy,
+		weekend:  make(map[time.Weekday]bool),
+		holidays: make(map[string]bool),
+	}
+
+	for _, day := range weekend {
+		c.weekend[day] = true
+	}
+
+	for _, holiday := range holidays {
+		if holiday.Region == region {
+			c.holidays[holiday.Date.Format(dateLayout)] = true
+		}
+	}
+
+	return c
+}
+
+// NewCalendarFromConfig returns the calendar of calendar.region (default ID) with calendar.policy (default FORWARD)
+// and the weekend days of calendar.weekend (e.g. SATURDAY,SUNDAY).
+func NewCalendarFromConfig(cfg configuration.Configuration, holidays []*Holiday) Calendar {
+	policy := strings.ToUpper(cfg.GetString("calendar.policy"))
+	switch policy {
+	case PolicyForward, PolicyBackward, PolicyNone:
+	case "":
+		policy = PolicyForward
+	default:
+		log.Println("unknown calendar policy, fallback to forward -> ", policy)
+		policy = PolicyForward
+	}
+
+	var weekend []time.Weekday
+	for _, name := range cfg.GetArray("calendar.weekend") {
+		day, ok := parseWeekday(name)
+		if !ok {
+			log.Println("unknown weekend day, ignored -> ", name)
+			continue
+		}
+
+		weekend = append(weekend, day)
+	}
+
+	return NewCalendar(Region(cfg), policy, weekend, holidays)
+}
+
+// Region is the region of the calendar configured by calendar.region.
+func Region(cfg configuration.Configuration) string {
+	if region := cfg.GetString("calendar.region"); region != "" {
+		return region
+	}
+
+	return DefaultRegion
+}
+
+func (c *calendar) Region() string {
+	return c.region
+}
+
+func (c *calendar) IsBusinessDay(date time.Time) bool {
+	return !c.weekend[date.Weekday()] && !c.holidays[date.Format(dateLayout)]
+}
+
+func (c *calendar) NextBusinessDay(date time.Time) time.Time {
+	return c.shift(date, 1)
+}
+
+func (c *calendar) Adjust(date time.Time) time.Time {
+	switch c.policy {
+	case PolicyForward:
+		return c.shift(date, 1)
+	case PolicyBackward:
+		return c.shift(date, -1)
+	}
+
+	return date
+}
+
+func (c *calendar) LateBefore(day time.Time) time.Time {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	return c.shift(start.AddDate(0, 0, -1), -1).AddDate(0, 0, 1)
+}
+
+// shift returns the date itself when it is the business day, otherwise the nearest business day in the direction.
+func (c *calendar) shift(date time.Time, direction int) time.Time {
+	for i := 0; i < maxShift; i++ {
+		shifted := date.AddDate(0, 0, i*direction)
+		if c.IsBusinessDay(shifted) {
+			return shifted
+		}
+	}
+
+	return date
+}
+
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(strings.TrimSpace(name), day.String()) {
+			return day, true
+		}
+	}
+
+	return time.Sunday, false
+}
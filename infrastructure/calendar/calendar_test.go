@@ -0,0 +1,178 @@
+package calendar
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	mocksConfiguration "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/configuration"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// 2026-03-20 & 2026-03-23 are holidays, 2026-03-21 & 2026-03-22 is the weekend.
+var holidays = []*Holiday{
+	{Region: "ID", Date: date(2026, 3, 20), Name: "Idul Fitri"},
+	{Region: "ID", Date: date(2026, 3, 23), Name: "Cuti Bersama"},
+	{Region: "SG", Date: date(2026, 3, 24), Name: "other region"},
+}
+
+var weekend = []time.Weekday{time.Saturday, time.Sunday}
+
+func Test_calendar_Adjust(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		date   time.Time
+		want   time.Time
+	}{
+		{
+			name: "given business day," +
+				"when adjust," +
+				"then return the date as is",
+			policy: PolicyForward,
+			date:   date(2026, 3, 19),
+			want:   date(2026, 3, 19),
+		},
+		{
+			name: "given holiday and policy forward," +
+				"when adjust," +
+				"then return the next business day",
+			policy: PolicyForward,
+			date:   time.Date(2026, 3, 20, 18, 58, 0, 0, time.UTC),
+			want:   time.Date(2026, 3, 24, 18, 58, 0, 0, time.UTC),
+		},
+		{
+			name: "given weekend and policy backward," +
+				"when adjust," +
+				"then return the previous business day",
+			policy: PolicyBackward,
+			date:   date(2026, 3, 22),
+			want:   date(2026, 3, 19),
+		},
+		{
+			name: "given holiday and policy none," +
+				"when adjust," +
+				"then return the date as is",
+			policy: PolicyNone,
+			date:   date(2026, 3, 20),
+			want:   date(2026, 3, 20),
+		},
+		{
+			name: "given holiday of the other region," +
+				"when adjust," +
+				"then return the date as is",
+			policy: PolicyForward,
+			date:   date(2026, 3, 24),
+			want:   date(2026, 3, 24),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				c := NewCalendar("ID", tt.policy, weekend, holidays)
+				assert.Equal(t, tt.want, c.Adjust(tt.date))
+			},
+		)
+	}
+}
+
+func Test_calendar_LateBefore(t *testing.T) {
+	tests := []struct {
+		name string
+		day  time.Time
+		want time.Time
+	}{
+		{
+			name: "given day after the business day," +
+				"when lateBefore," +
+				"then the installments due before the day are late",
+			day:  time.Date(2026, 3, 20, 10, 0, 0, 0, time.UTC),
+			want: date(2026, 3, 20),
+		},
+		{
+			name: "given day after the holidays," +
+				"when lateBefore," +
+				"then the installments due in the holidays are not late yet",
+			day:  date(2026, 3, 24),
+			want: date(2026, 3, 20),
+		},
+		{
+			name: "given day after the next business day," +
+				"when lateBefore," +
+				"then the installments due in the holidays are late",
+			day:  date(2026, 3, 25),
+			want: date(2026, 3, 25),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				c := NewCalendar("ID", PolicyNone, weekend, holidays)
+				assert.Equal(t, tt.want, c.LateBefore(tt.day))
+			},
+		)
+	}
+}
+
+func Test_NewCalendarFromConfig(t *testing.T) {
+	mockCfg := &mocksConfiguration.Configuration{}
+	mockCfg.On("GetString", "calendar.policy").Return("backward")
+	mockCfg.On("GetString", "calendar.region").Return("")
+	mockCfg.On("GetArray", "calendar.weekend").Return([]string{"SATURDAY", " sunday", "holiday"})
+
+	c := NewCalendarFromConfig(mockCfg, holidays)
+
+	assert.Equal(t, DefaultRegion, c.Region())
+	assert.False(t, c.IsBusinessDay(date(2026, 3, 22)))
+	assert.Equal(t, date(2026, 3, 19), c.Adjust(date(2026, 3, 23)))
+	assert.Equal(t, date(2026, 3, 24), c.NextBusinessDay(date(2026, 3, 20)))
+}
+
+func Test_ReadHolidays(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    []*Holiday
+		wantErr error
+	}{
+		{
+			name: "given holidays csv," +
+				"when readHolidays," +
+				"then return the holidays",
+			csv: "region,date,name\n" +
+				"# lebaran\n" +
+				"ID, 2026-03-20, Idul Fitri\n" +
+				"\n" +
+				"SG,2026-03-24\n",
+			want: []*Holiday{
+				{Region: "ID", Date: date(2026, 3, 20), Name: "Idul Fitri"},
+				{Region: "SG", Date: date(2026, 3, 24)},
+			},
+		},
+		{
+			name: "given invalid date," +
+				"when readHolidays," +
+				"then return error invalid holiday",
+			csv:     "region,date,name\nID,20-03-2026,Idul Fitri\n",
+			wantErr: ErrorInvalidHoliday,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := ReadHolidays(strings.NewReader(tt.csv))
+				assert.True(t, errors.Is(err, tt.wantErr))
+				assert.Equal(t, tt.want, got)
+			},
+		)
+	}
+}
@@ -0,0 +1,65 @@
+package calendar
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// the columns of the holiday csv, the first row is the header : region, date (YYYY-MM-DD), name
+const (
+	columnRegion = iota
+	columnDate
+	columnName
+
+	totalColumns
+)
+
+// ReadHolidays reads the holidays of every region from the csv.
+func ReadHolidays(reader io.Reader) ([]*Holiday, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+	csvReader.Comment = '#'
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var holidays []*Holiday
+	for i, record := range records {
+		//header
+		if i == 0 {
+			continue
+		}
+
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		if len(record) < totalColumns-1 {
+			return nil, fmt.Errorf("%w : row %d has %d columns", ErrorInvalidHoliday, i+1, len(record))
+		}
+
+		date, errDate := time.Parse(dateLayout, strings.TrimSpace(record[columnDate]))
+		if errDate != nil {
+			return nil, fmt.Errorf("%w : row %d date %s", ErrorInvalidHoliday, i+1, record[columnDate])
+		}
+
+		holiday := &Holiday{
+			Region: strings.TrimSpace(record[columnRegion]),
+			Date:   date,
+		}
+
+		if len(record) > columnName {
+			holiday.Name = strings.TrimSpace(record[columnName])
+		}
+
+		holidays = append(holidays, holiday)
+	}
+
+	return holidays, nil
+}
@@ -0,0 +1,298 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+)
+
+const (
+	FakeScenarioSuccess = "success"
+	FakeScenarioDecline = "decline"
+	FakeScenarioTimeout = "timeout"
+	FakeScenarioDelayed = "delayed"
+
+	HeaderFakeSignature = "X-Fake-Signature"
+
+	fakeStatusSettlement = "settlement"
+	fakeStatusPending    = "pending"
+	fakeStatusDeny       = "deny"
+	fakeStatusExpire     = "expire"
+	fakeStatusRefund     = "refund"
+)
+
+type (
+	fakeCharge struct {
+		charge   Charge
+		refunded decimal.Decimal
+	}
+
+	// fakeNotification is the callback body of the fake provider, it uses the vocabulary of the provider
+	// (not ours), so the mapping is exercised the same way as the real provider.
+	fakeNotification struct {
+		OrderID           string `json:"order_id"`
+		TransactionID     string `json:"transaction_id"`
+		TransactionStatus string `json:"transaction_status"`
+		GrossAmount       string `json:"gross_amount"`
+		StatusMessage     string `json:"status_message,omitempty"`
+	}
+
+	// fakeGateway is in-process provider for local runs and tests, the scenario is configured
+	// through "payment.gateway.fake.scenario" :
+	//   - success, the charge succeeds immediately.
+	//   - decline, the charge is declined immediately.
+	//   - timeout, the charge returns ErrorTimeout but it succeeds after the delay (response is lost).
+	//   - delayed, the charge is pending and it succeeds after the delay.
+	//
+	// the delayed result is sent after "payment.gateway.fake.delay.seconds", through http POST into
+	// "payment.gateway.fake.callback.url" signed by "payment.gateway.fake.callback.secret" when it is configured,
+	// otherwise through the in-process callback.
+	fakeGateway struct {
+		scenario       string
+		delay          time.Duration
+		callback       Callback
+		callbackURL    string
+		callbackSecret string
+		httpClient     *http.Client
+		mu             sync.Mutex
+		charges        map[string]*fakeCharge
+		references     map[string]string
+		generate       common.Generate
+	}
+)
+
+func NewFakeGateway(cfg configuration.Configuration, callback Callback) PaymentGateway {
+	scenario := cfg.GetString("payment.gateway.fake.scenario")
+	if scenario == "" {
+		scenario = FakeScenarioSuccess
+	}
+
+	return &fakeGateway{
+		scenario:       scenario,
+		delay:          time.Duration(cfg.GetInt("payment.gateway.fake.delay.seconds")) * time.Second,
+		callback:       callback,
+		callbackURL:    cfg.GetString("payment.gateway.fake.callback.url"),
+		callbackSecret: cfg.GetString("payment.gateway.fake.callback.secret"),
+		httpClient:     &http.Client{Timeout: 10 * time.Second},
+		charges:        make(map[string]*fakeCharge),
+		references:     make(map[string]string),
+		generate:       common.NewGenerate(),
+	}
+}
+
+func (f *fakeGateway) Provider() string {
+	return ProviderFake
+}
+
+func (f *fakeGateway) Charge(
+	ctx context.Context,
+	request *ChargeRequest) (*Charge, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	//idempotent by our reference, the same as the real provider
+	if providerReference, ok := f.references[request.Reference]; ok {
+		charge := f.charges[providerReference].charge
+		return &charge, nil
+	}
+
+	charge := Charge{
+		Reference:         request.Reference,
+		ProviderReference: ProviderFake + "-" + f.generate.Uuid(),
+		Status:            StatusPending,
+		Amount:            request.Amount,
+	}
+
+	switch f.scenario {
+	case FakeScenarioDecline:
+		charge.Status = StatusDeclined
+		charge.FailureReason = "insufficient balance"
+	case FakeScenarioTimeout, FakeScenarioDelayed:
+		f.settleLater(charge.ProviderReference)
+	default:
+		charge.Status = StatusSuccess
+	}
+
+	f.charges[charge.ProviderReference] = &fakeCharge{charge: charge, refunded: decimal.Zero}
+	f.references[request.Reference] = charge.ProviderReference
+
+	if f.scenario == FakeScenarioTimeout {
+		return nil, ErrorTimeout
+	}
+
+	return &charge, nil
+}
+
+func (f *fakeGateway) Status(
+	ctx context.Context,
+	reference string) (*Charge, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	providerReference, ok := f.references[reference]
+	if !ok {
+		return nil, ErrorNotFound
+	}
+
+	charge := f.charges[providerReference].charge
+	return &charge, nil
+}
+
+func (f *fakeGateway) Refund(
+	ctx context.Context,
+	request *RefundRequest) (*Refund, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stored, ok := f.charges[request.ProviderReference]
+	if !ok {
+		return nil, ErrorNotFound
+	}
+
+	refundable := stored.charge.Amount.Sub(stored.refunded)
+	if stored.charge.Status != StatusSuccess || request.Amount.GreaterThan(refundable) {
+		return &Refund{
+			ProviderReference: request.ProviderReference,
+			Status:            StatusDeclined,
+		}, nil
+	}
+
+	stored.refunded = stored.refunded.Add(request.Amount)
+	if stored.refunded.Equal(stored.charge.Amount) {
+		stored.charge.Status = StatusRefunded
+	}
+
+	return &Refund{
+		ProviderReference: request.ProviderReference,
+		RefundReference:   ProviderFake + "-refund-" + f.generate.Uuid(),
+		Status:            StatusRefunded,
+	}, nil
+}
+
+// settleLater succeeds the pending charge after the delay, then notifies through the callback.
+func (f *fakeGateway) settleLater(providerReference string) {
+	time.AfterFunc(
+		f.delay, func() {
+			f.mu.Lock()
+			stored := f.charges[providerReference]
+			stored.charge.Status = StatusSuccess
+			charge := stored.charge
+			f.mu.Unlock()
+
+			log.Println("fake gateway settles charge -> ", providerReference)
+
+			if f.callbackURL != "" {
+				f.sendCallback(&charge)
+				return
+			}
+
+			if f.callback != nil {
+				f.callback(context.Background(), &charge)
+			}
+		},
+	)
+}
+
+func (f *fakeGateway) ParseCallback(
+	header http.Header,
+	body []byte) (*Charge, error) {
+	signature := header.Get(HeaderFakeSignature)
+	expected := SignFakeCallback(f.callbackSecret, body)
+
+	//no secret means callback is disabled, so nobody can settle the payment by guessing it
+	if f.callbackSecret == "" || !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, ErrorSignature
+	}
+
+	var notification fakeNotification
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return nil, ErrorCallback
+	}
+
+	amount, err := decimal.NewFromString(notification.GrossAmount)
+	if err != nil || notification.TransactionID == "" {
+		return nil, ErrorCallback
+	}
+
+	charge := &Charge{
+		Reference:         notification.OrderID,
+		ProviderReference: notification.TransactionID,
+		Amount:            amount,
+	}
+
+	switch notification.TransactionStatus {
+	case fakeStatusSettlement:
+		charge.Status = StatusSuccess
+	case fakeStatusPending:
+		charge.Status = StatusPending
+	case fakeStatusDeny, fakeStatusExpire:
+		charge.Status = StatusDeclined
+		charge.FailureReason = notification.StatusMessage
+	case fakeStatusRefund:
+		charge.Status = StatusRefunded
+	default:
+		return nil, ErrorCallback
+	}
+
+	return charge, nil
+}
+
+func (f *fakeGateway) sendCallback(charge *Charge) {
+	status := fakeStatusSettlement
+	if charge.Status == StatusDeclined {
+		status = fakeStatusDeny
+	}
+
+	body, err := json.Marshal(
+		&fakeNotification{
+			OrderID:           charge.Reference,
+			TransactionID:     charge.ProviderReference,
+			TransactionStatus: status,
+			GrossAmount:       charge.Amount.StringFixed(2),
+			StatusMessage:     charge.FailureReason,
+		},
+	)
+
+	if err != nil {
+		log.Println("fake gateway failed marshal callback -> ", err)
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, f.callbackURL, bytes.NewReader(body))
+	if err != nil {
+		log.Println("fake gateway failed build callback -> ", err)
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderFakeSignature, SignFakeCallback(f.callbackSecret, body))
+
+	rsp, err := f.httpClient.Do(req)
+	if err != nil {
+		log.Println("fake gateway failed send callback -> ", err)
+		return
+	}
+	defer rsp.Body.Close()
+
+	log.Println("fake gateway sent callback -> ", charge.ProviderReference, rsp.StatusCode)
+}
+
+// SignFakeCallback is the signature of the fake provider, hex of HMAC-SHA256 of the body.
+func SignFakeCallback(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,249 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	mocks "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/configuration"
+)
+
+func newFakeConfiguration(scenario, callbackSecret string) *mocks.Configuration {
+	cfg := &mocks.Configuration{}
+	cfg.On("GetString", "payment.gateway.fake.scenario").Return(scenario)
+	cfg.On("GetString", "payment.gateway.fake.callback.url").Return("")
+	cfg.On("GetString", "payment.gateway.fake.callback.secret").Return(callbackSecret)
+	cfg.On("GetInt", "payment.gateway.fake.delay.seconds").Return(int64(0))
+
+	return cfg
+}
+
+func Test_fakeGateway_Charge(t *testing.T) {
+	request := &ChargeRequest{
+		Reference: "p-1",
+		UserID:    "abc",
+		Amount:    decimal.NewFromInt(25),
+	}
+
+	tests := []struct {
+		name         string
+		scenario     string
+		wantStatus   Status
+		wantErr      error
+		wantCallback bool
+	}{
+		{
+			name: "given scenario success," +
+				"when charge," +
+				"then return success",
+			scenario:   FakeScenarioSuccess,
+			wantStatus: StatusSuccess,
+		},
+		{
+			name: "given scenario decline," +
+				"when charge," +
+				"then return declined",
+			scenario:   FakeScenarioDecline,
+			wantStatus: StatusDeclined,
+		},
+		{
+			name: "given scenario timeout," +
+				"when charge," +
+				"then return timeout and the charge succeeds later",
+			scenario:     FakeScenarioTimeout,
+			wantErr:      ErrorTimeout,
+			wantCallback: true,
+		},
+		{
+			name: "given scenario delayed," +
+				"when charge," +
+				"then return pending and the charge succeeds later",
+			scenario:     FakeScenarioDelayed,
+			wantStatus:   StatusPending,
+			wantCallback: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				callbacks := make(chan *Charge, 1)
+				f := NewFakeGateway(
+					newFakeConfiguration(tt.scenario, ""), func(ctx context.Context, charge *Charge) {
+						callbacks <- charge
+					},
+				)
+
+				got, err := f.Charge(context.Background(), request)
+				assert.Equal(t, tt.wantErr, err)
+				if err == nil {
+					assert.Equal(t, tt.wantStatus, got.Status)
+				}
+
+				if !tt.wantCallback {
+					return
+				}
+
+				select {
+				case charge := <-callbacks:
+					assert.Equal(t, StatusSuccess, charge.Status)
+					assert.Equal(t, "p-1", charge.Reference)
+				case <-time.After(time.Second):
+					t.Error("callback is not sent")
+				}
+
+				status, errStatus := f.Status(context.Background(), "p-1")
+				assert.Nil(t, errStatus)
+				assert.Equal(t, StatusSuccess, status.Status)
+			})
+	}
+}
+
+func Test_fakeGateway_idempotent(t *testing.T) {
+	f := NewFakeGateway(newFakeConfiguration(FakeScenarioSuccess, ""), nil)
+	request := &ChargeRequest{Reference: "p-1", Amount: decimal.NewFromInt(25)}
+
+	first, _ := f.Charge(context.Background(), request)
+	second, _ := f.Charge(context.Background(), request)
+	assert.Equal(t, first.ProviderReference, second.ProviderReference)
+
+	_, err := f.Status(context.Background(), "p-2")
+	assert.Equal(t, ErrorNotFound, err)
+}
+
+func Test_fakeGateway_Refund(t *testing.T) {
+	f := NewFakeGateway(newFakeConfiguration(FakeScenarioSuccess, ""), nil)
+	charge, _ := f.Charge(context.Background(), &ChargeRequest{Reference: "p-1", Amount: decimal.NewFromInt(25)})
+
+	refund, err := f.Refund(
+		context.Background(), &RefundRequest{ProviderReference: charge.ProviderReference, Amount: decimal.NewFromInt(10)})
+	assert.Nil(t, err)
+	assert.Equal(t, StatusRefunded, refund.Status)
+
+	//only 15 is left to be refunded
+	refund, err = f.Refund(
+		context.Background(), &RefundRequest{ProviderReference: charge.ProviderReference, Amount: decimal.NewFromInt(20)})
+	assert.Nil(t, err)
+	assert.Equal(t, StatusDeclined, refund.Status)
+
+	_, err = f.Refund(context.Background(), &RefundRequest{ProviderReference: "unknown", Amount: decimal.NewFromInt(1)})
+	assert.Equal(t, ErrorNotFound, err)
+}
+
+func Test_fakeGateway_ParseCallback(t *testing.T) {
+	f := NewFakeGateway(newFakeConfiguration(FakeScenarioDelayed, "callback-secret"), nil)
+
+	body := []byte(`{"order_id":"p-1","transaction_id":"fake-1","transaction_status":"settlement","gross_amount":"25.00"}`)
+
+	tests := []struct {
+		name       string
+		body       []byte
+		signature  string
+		wantStatus Status
+		wantErr    error
+	}{
+		{
+			name: "given valid signature," +
+				"when parseCallback," +
+				"then return the charge with our status",
+			body:       body,
+			signature:  SignFakeCallback("callback-secret", body),
+			wantStatus: StatusSuccess,
+		},
+		{
+			name: "given denied transaction," +
+				"when parseCallback," +
+				"then return declined",
+			body: []byte(`{"order_id":"p-1","transaction_id":"fake-1","transaction_status":"deny","gross_amount":"25.00"}`),
+			signature: SignFakeCallback(
+				"callback-secret",
+				[]byte(`{"order_id":"p-1","transaction_id":"fake-1","transaction_status":"deny","gross_amount":"25.00"}`)),
+			wantStatus: StatusDeclined,
+		},
+		{
+			name: "given signature by another secret," +
+				"when parseCallback," +
+				"then return error signature",
+			body:      body,
+			signature: SignFakeCallback("another-secret", body),
+			wantErr:   ErrorSignature,
+		},
+		{
+			name: "given unknown transaction status," +
+				"when parseCallback," +
+				"then return error callback",
+			body: []byte(`{"order_id":"p-1","transaction_id":"fake-1","transaction_status":"unknown","gross_amount":"25.00"}`),
+			signature: SignFakeCallback(
+				"callback-secret",
+				[]byte(`{"order_id":"p-1","transaction_id":"fake-1","transaction_status":"unknown","gross_amount":"25.00"}`)),
+			wantErr: ErrorCallback,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				header := http.Header{}
+				header.Set(HeaderFakeSignature, tt.signature)
+
+				got, err := f.ParseCallback(header, tt.body)
+				assert.Equal(t, tt.wantErr, err)
+				if err == nil {
+					assert.Equal(t, tt.wantStatus, got.Status)
+					assert.Equal(t, "p-1", got.Reference)
+					assert.Equal(t, "fake-1", got.ProviderReference)
+				}
+			})
+	}
+}
+
+func Test_NewPaymentGateway(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		wantErr  error
+	}{
+		{
+			name: "given provider fake," +
+				"when newPaymentGateway," +
+				"then return the fake gateway",
+			provider: ProviderFake,
+		},
+		{
+			name: "given no provider," +
+				"when newPaymentGateway," +
+				"then return error unknown provider",
+			wantErr: ErrorUnknownProvider,
+		},
+		{
+			name: "given misspelled provider," +
+				"when newPaymentGateway," +
+				"then return error unknown provider",
+			provider: "fakee",
+			wantErr:  ErrorUnknownProvider,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				cfg := newFakeConfiguration(FakeScenarioSuccess, "")
+				cfg.On("GetString", "payment.gateway.provider").Return(tt.provider)
+
+				got, err := NewPaymentGateway(cfg, nil)
+
+				assert.True(t, errors.Is(err, tt.wantErr))
+				if tt.wantErr != nil {
+					assert.Nil(t, got)
+					return
+				}
+
+				assert.Equal(t, ProviderFake, got.Provider())
+			})
+	}
+}
@@ -0,0 +1,100 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/shopspring/decimal"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+)
+
+const (
+	ProviderFake = "fake"
+
+	StatusPending  Status = "PENDING"
+	StatusSuccess  Status = "SUCCESS"
+	StatusDeclined Status = "DECLINED"
+	StatusRefunded Status = "REFUNDED"
+)
+
+var (
+	// ErrorTimeout means the result of the request is unknown, the money may or may not be moved,
+	// so the caller should query the status (or wait for the callback) instead of retrying the charge.
+	ErrorTimeout  = errors.New("payment gateway timeout")
+	ErrorNotFound = errors.New("payment gateway reference is not found")
+	ErrorGateway  = errors.New("payment gateway error")
+	// ErrorSignature means the callback is not sent by the provider (or it is tampered).
+	ErrorSignature = errors.New("invalid callback signature")
+	ErrorCallback  = errors.New("invalid callback payload")
+	// ErrorUnknownProvider refuses to start with the provider which is not integrated (e.g. the typo),
+	// rather than debiting through the fake provider which settles the payment without moving the money.
+	ErrorUnknownProvider = errors.New("unknown payment gateway provider")
+)
+
+type (
+	Status string
+
+	ChargeRequest struct {
+		// Reference is our payment id, the provider uses it as idempotency key.
+		Reference   string          `json:"reference"`
+		UserID      string          `json:"user_id"`
+		Amount      decimal.Decimal `json:"amount"`
+		Description string          `json:"description,omitempty"`
+	}
+
+	Charge struct {
+		Reference         string          `json:"reference"`
+		ProviderReference string          `json:"provider_reference"`
+		Status            Status          `json:"status"`
+		Amount            decimal.Decimal `json:"amount"`
+		FailureReason     string          `json:"failure_reason,omitempty"`
+	}
+
+	RefundRequest struct {
+		ProviderReference string          `json:"provider_reference"`
+		Amount            decimal.Decimal `json:"amount"`
+		Reason            string          `json:"reason,omitempty"`
+	}
+
+	Refund struct {
+		ProviderReference string `json:"provider_reference"`
+		RefundReference   string `json:"refund_reference"`
+		Status            Status `json:"status"`
+	}
+
+	// Callback receives the result of the charge which is confirmed asynchronously by the provider.
+	Callback func(ctx context.Context, charge *Charge)
+
+	// PaymentGateway debits the money of the customer through 3rd party provider.
+	PaymentGateway interface {
+		Provider() string
+
+		// Charge debits the customer, the result can be PENDING when the provider confirms it later.
+		Charge(ctx context.Context, request *ChargeRequest) (*Charge, error)
+
+		// Status queries the latest status of the charge by our reference, it still works
+		// when the charge timed out before the provider reference is known.
+		Status(ctx context.Context, reference string) (*Charge, error)
+
+		Refund(ctx context.Context, request *RefundRequest) (*Refund, error)
+
+		// ParseCallback verifies the signature of the callback sent by the provider,
+		// then maps the status of the provider into our status.
+		ParseCallback(header http.Header, body []byte) (*Charge, error)
+	}
+)
+
+// NewPaymentGateway builds the gateway from configuration "payment.gateway.provider", fake is the only provider
+// until the real provider is integrated and it should be chosen explicitly. ErrorUnknownProvider is returned
+// for any other provider, including the empty one.
+func NewPaymentGateway(cfg configuration.Configuration, callback Callback) (PaymentGateway, error) {
+	switch provider := cfg.GetString("payment.gateway.provider"); provider {
+	case ProviderFake:
+		return NewFakeGateway(cfg, callback), nil
+	default:
+		return nil, fmt.Errorf("%w : %q", ErrorUnknownProvider, provider)
+	}
+}
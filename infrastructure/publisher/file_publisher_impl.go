@@ -0,0 +1,61 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+)
+
+type filePublisher struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFilePublisher appends every message as json line into the file, used for local runs.
+func NewFilePublisher(path string) Publisher {
+	return &filePublisher{
+		path: path,
+	}
+}
+
+func (f *filePublisher) Publish(_ context.Context, message *Message) error {
+	line, err := json.Marshal(
+		struct {
+			ID         string          `json:"id"`
+			Type       string          `json:"type"`
+			Key        string          `json:"key"`
+			Payload    json.RawMessage `json:"payload"`
+			OccurredAt string          `json:"occurred_at"`
+		}{
+			ID:         message.ID,
+			Type:       message.Type,
+			Key:        message.Key,
+			Payload:    message.Payload,
+			OccurredAt: message.OccurredAt.Format("2006-01-02T15:04:05Z07:00"),
+		},
+	)
+
+	if err != nil {
+		log.Println("error during encode message -> ", err)
+		return ErrorPublish
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Println("error open file publisher -> ", err)
+		return ErrorPublish
+	}
+	defer file.Close()
+
+	if _, err = file.Write(append(line, '\n')); err != nil {
+		log.Println("error write file publisher -> ", err)
+		return ErrorPublish
+	}
+
+	return nil
+}
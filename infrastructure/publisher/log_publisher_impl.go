@@ -0,0 +1,21 @@
+package publisher
+
+import (
+	"context"
+	"log"
+)
+
+type logPublisher struct {
+}
+
+func NewLogPublisher() Publisher {
+	return &logPublisher{}
+}
+
+func (l *logPublisher) Publish(_ context.Context, message *Message) error {
+	log.Println(
+		"[Publisher] publish event -> ",
+		message.Type, message.ID, message.Key, string(message.Payload))
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package publisher
+
+import (
+	"context"
+)
+
+type multiPublisher struct {
+	publishers []Publisher
+}
+
+// NewMultiPublisher publishes the message into every publisher in order, it stops at the first error
+// so the relay retries the message (the publisher which already succeeded receives it again).
+func NewMultiPublisher(publishers ...Publisher) Publisher {
+	return &multiPublisher{
+		publishers: publishers,
+	}
+}
+
+func (m *multiPublisher) Publish(ctx context.Context, message *Message) error {
+	for _, p := range m.publishers {
+		if err := p.Publish(ctx, message); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
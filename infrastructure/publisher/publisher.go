@@ -0,0 +1,44 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+)
+
+const (
+	TypeLog  = "log"
+	TypeFile = "file"
+)
+
+var (
+	ErrorPublish = errors.New("failed publish message")
+)
+
+type (
+	Message struct {
+		ID         string    `json:"id"`
+		Type       string    `json:"type"`
+		Key        string    `json:"key"`
+		Payload    []byte    `json:"payload"`
+		OccurredAt time.Time `json:"occurred_at"`
+	}
+
+	// Publisher sends the message to the downstream (e.g. kafka, pubsub), it should be safe
+	// to publish the same message more than once since the relay is at least once delivery.
+	Publisher interface {
+		Publish(ctx context.Context, message *Message) error
+	}
+)
+
+// NewPublisher builds the publisher from configuration "outbox.publisher", default is log.
+func NewPublisher(cfg configuration.Configuration) Publisher {
+	switch cfg.GetString("outbox.publisher") {
+	case TypeFile:
+		return NewFilePublisher(cfg.GetString("outbox.publisher.file.path"))
+	default:
+		return NewLogPublisher()
+	}
+}
@@ -0,0 +1,118 @@
+package qris
+
+import (
+	"fmt"
+	"strings"
+)
+
+type generator struct {
+	merchant Merchant
+}
+
+func (g *generator) Dynamic(request *DynamicRequest) (string, error) {
+	merchantAccount, err := encode(
+		field{subTagGlobalID, g.merchant.GlobalID},
+		field{subTagMerchantID, g.merchant.MerchantID},
+		field{subTagCriteria, g.merchant.Criteria},
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	additionalData, err := encode(
+		field{subTagBillNumber, request.BillNumber},
+		field{subTagReferenceLabel, request.ReferenceLabel},
+		field{subTagTerminalLabel, g.merchant.Terminal},
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := encode(
+		field{tagPayloadFormatIndicator, payloadFormatIndicator},
+		field{tagPointOfInitiation, pointOfInitiationDynamic},
+		field{tagMerchantAccountQris, merchantAccount},
+		field{tagMerchantCategoryCode, g.merchant.MCC},
+		field{tagTransactionCurrency, currencyRupiah},
+		field{tagTransactionAmount, request.Amount.StringFixed(2)},
+		field{tagCountryCode, countryIndonesia},
+		field{tagMerchantName, g.merchant.Name},
+		field{tagMerchantCity, g.merchant.City},
+		field{tagPostalCode, g.merchant.PostalCode},
+		field{tagAdditionalData, additionalData},
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	//the crc covers the payload including the tag and length of the crc itself
+	payload += tagCRC + "04"
+	return payload + CRC16(payload), nil
+}
+
+// Parse reads the top level fields of the payload after the crc is verified.
+func Parse(payload string) (map[string]string, error) {
+	if len(payload) < 8 || CRC16(payload[:len(payload)-4]) != payload[len(payload)-4:] {
+		return nil, ErrorInvalid
+	}
+
+	fields := make(map[string]string)
+	for idx := 0; idx < len(payload); {
+		if idx+4 > len(payload) {
+			return nil, ErrorInvalid
+		}
+
+		var length int
+		if _, err := fmt.Sscanf(payload[idx+2:idx+4], "%02d", &length); err != nil || idx+4+length > len(payload) {
+			return nil, ErrorInvalid
+		}
+
+		fields[payload[idx:idx+2]] = payload[idx+4 : idx+4+length]
+		idx += 4 + length
+	}
+
+	return fields, nil
+}
+
+// CRC16 is CRC-16/CCITT-FALSE (polynomial 0x1021, initial 0xFFFF) in 4 uppercase hex, as required by EMVCo.
+func CRC16(data string) string {
+	crc := uint16(0xFFFF)
+	for idx := 0; idx < len(data); idx++ {
+		crc ^= uint16(data[idx]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return fmt.Sprintf("%04X", crc)
+}
+
+type field struct {
+	tag   string
+	value string
+}
+
+// encode writes the fields as TLV (2 digits tag, 2 digits length, value), the empty value is omitted.
+func encode(fields ...field) (string, error) {
+	var sb strings.Builder
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+
+		if len(f.value) > 99 {
+			return "", ErrorValueTooLong
+		}
+
+		sb.WriteString(fmt.Sprintf("%s%02d%s", f.tag, len(f.value), f.value))
+	}
+
+	return sb.String(), nil
+}
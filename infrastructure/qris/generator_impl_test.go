@@ -0,0 +1,92 @@
+package qris
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CRC16(t *testing.T) {
+	//check value of CRC-16/CCITT-FALSE
+	assert.Equal(t, "29B1", CRC16("123456789"))
+}
+
+func Test_generator_Dynamic(t *testing.T) {
+	g := &generator{
+		merchant: Merchant{
+			GlobalID:   "ID.CO.QRIS.WWW",
+			MerchantID: "ID1020000000001",
+			Criteria:   "UMI",
+			MCC:        "6012",
+			Name:       "AMARTHA",
+			City:       "JAKARTA",
+			PostalCode: "12190",
+		},
+	}
+
+	tests := []struct {
+		name       string
+		request    *DynamicRequest
+		wantFields map[string]string
+		wantErr    error
+	}{
+		{
+			name: "given valid request," +
+				"when dynamic," +
+				"then return payload with amount and bill number",
+			request: &DynamicRequest{
+				Amount:     decimal.NewFromInt(4290000),
+				BillNumber: "0b7a5cf2-3c1f-4a53-9a0e-1f0f5a3c9d11",
+			},
+			wantFields: map[string]string{
+				"00": "01",
+				"01": "12",
+				"51": "0014ID.CO.QRIS.WWW0215ID10200000000010303UMI",
+				"52": "6012",
+				"53": "360",
+				"54": "4290000.00",
+				"58": "ID",
+				"59": "AMARTHA",
+				"60": "JAKARTA",
+				"61": "12190",
+				"62": "01360b7a5cf2-3c1f-4a53-9a0e-1f0f5a3c9d11",
+			},
+		},
+		{
+			name: "given too long bill number," +
+				"when dynamic," +
+				"then return error",
+			request: &DynamicRequest{
+				Amount:     decimal.NewFromInt(1),
+				BillNumber: strings.Repeat("a", 100),
+			},
+			wantErr: ErrorValueTooLong,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := g.Dynamic(tt.request)
+				assert.Equal(t, tt.wantErr, err)
+				if err != nil {
+					return
+				}
+
+				fields, errParse := Parse(got)
+				assert.Nil(t, errParse)
+
+				crc := fields["63"]
+				delete(fields, "63")
+				assert.Equal(t, tt.wantFields, fields)
+				assert.Equal(t, CRC16(got[:len(got)-4]), crc)
+			})
+	}
+}
+
+func Test_Parse(t *testing.T) {
+	_, err := Parse("000201010212630400FF")
+	assert.Equal(t, ErrorInvalid, err)
+}
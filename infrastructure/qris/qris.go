@@ -0,0 +1,82 @@
+package qris
+
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+)
+
+// the tags of EMVCo merchant-presented mode used by QRIS.
+const (
+	tagPayloadFormatIndicator = "00"
+	tagPointOfInitiation      = "01"
+	tagMerchantAccountQris    = "51"
+	tagMerchantCategoryCode   = "52"
+	tagTransactionCurrency    = "53"
+	tagTransactionAmount      = "54"
+	tagCountryCode            = "58"
+	tagMerchantName           = "59"
+	tagMerchantCity           = "60"
+	tagPostalCode             = "61"
+	tagAdditionalData         = "62"
+	tagCRC                    = "63"
+
+	subTagGlobalID       = "00"
+	subTagMerchantID     = "02"
+	subTagCriteria       = "03"
+	subTagBillNumber     = "01"
+	subTagReferenceLabel = "05"
+	subTagTerminalLabel  = "07"
+
+	payloadFormatIndicator   = "01"
+	pointOfInitiationDynamic = "12"
+	currencyRupiah           = "360"
+	countryIndonesia         = "ID"
+)
+
+var (
+	ErrorValueTooLong = errors.New("qris value is too long")
+	ErrorInvalid      = errors.New("invalid qris payload")
+)
+
+type (
+	Merchant struct {
+		GlobalID   string `json:"global_id"`
+		MerchantID string `json:"merchant_id"`
+		Criteria   string `json:"criteria"`
+		MCC        string `json:"mcc"`
+		Name       string `json:"name"`
+		City       string `json:"city"`
+		PostalCode string `json:"postal_code"`
+		Terminal   string `json:"terminal"`
+	}
+
+	DynamicRequest struct {
+		Amount decimal.Decimal `json:"amount"`
+		// BillNumber is sent back by the acquirer in the callback, it is our payment id.
+		BillNumber     string `json:"bill_number"`
+		ReferenceLabel string `json:"reference_label,omitempty"`
+	}
+
+	// Generator builds the dynamic QRIS (point of initiation 12) payload of the merchant.
+	Generator interface {
+		Dynamic(request *DynamicRequest) (string, error)
+	}
+)
+
+func NewGenerator(cfg configuration.Configuration) Generator {
+	return &generator{
+		merchant: Merchant{
+			GlobalID:   cfg.GetString("qris.merchant.global.id"),
+			MerchantID: cfg.GetString("qris.merchant.id"),
+			Criteria:   cfg.GetString("qris.merchant.criteria"),
+			MCC:        cfg.GetString("qris.merchant.mcc"),
+			Name:       cfg.GetString("qris.merchant.name"),
+			City:       cfg.GetString("qris.merchant.city"),
+			PostalCode: cfg.GetString("qris.merchant.postal.code"),
+			Terminal:   cfg.GetString("qris.merchant.terminal"),
+		},
+	}
+}
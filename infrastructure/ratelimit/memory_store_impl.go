@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/common"
+)
+
+const (
+	sweepEvery = 1000
+)
+
+type (
+	bucket struct {
+		tokens     float64
+		lastRefill time.Time
+		limit      Limit
+	}
+
+	memoryStore struct {
+		mu       sync.Mutex
+		buckets  map[string]*bucket
+		counter  int
+		generate common.Generate
+	}
+)
+
+func NewMemoryStore() Store {
+	return &memoryStore{
+		buckets:  make(map[string]*bucket),
+		generate: common.NewGenerate(),
+	}
+}
+
+func (m *memoryStore) Take(
+	ctx context.Context,
+	key string,
+	limit Limit) (*Decision, error) {
+	if limit.IsUnlimited() {
+		return &Decision{Allowed: true}, nil
+	}
+
+	now := m.generate.Time()
+	perSecond := float64(limit.Rate) / time.Minute.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.counter += 1
+	if m.counter%sweepEvery == 0 {
+		m.sweep(now)
+	}
+
+	b, ok := m.buckets[key]
+	if !ok || b.limit != limit {
+		b = &bucket{
+			tokens:     float64(limit.Burst),
+			lastRefill: now,
+			limit:      limit,
+		}
+		m.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.lastRefill).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+(elapsed*perSecond))
+		b.lastRefill = now
+	}
+
+	if b.tokens < 1 {
+		missing := 1 - b.tokens
+		retryAfter := time.Duration(math.Ceil(missing/perSecond)) * time.Second
+
+		return &Decision{
+			Allowed:    false,
+			Remaining:  0,
+			RetryAfter: retryAfter,
+		}, nil
+	}
+
+	b.tokens -= 1
+
+	return &Decision{
+		Allowed:   true,
+		Remaining: int64(b.tokens),
+	}, nil
+}
+
+// sweep removes the bucket which already full, since it has the same state as a new one.
+func (m *memoryStore) sweep(now time.Time) {
+	for key, b := range m.buckets {
+		perSecond := float64(b.limit.Rate) / time.Minute.Seconds()
+		tokens := b.tokens + (now.Sub(b.lastRefill).Seconds() * perSecond)
+
+		if tokens >= float64(b.limit.Burst) {
+			delete(m.buckets, key)
+		}
+	}
+}
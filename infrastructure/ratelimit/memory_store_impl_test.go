@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	mocks "gitlab.com/2024/Juni/amartha-billing-srv2/mocks/common"
+)
+
+func Test_memoryStore_Take(t *testing.T) {
+	start := time.Date(2024, 6, 23, 21, 0, 0, 0, time.UTC)
+	limit := Limit{Rate: 60, Burst: 2}
+
+	type take struct {
+		at   time.Time
+		want Decision
+	}
+
+	tests := []struct {
+		name  string
+		limit Limit
+		takes []take
+	}{
+		{
+			name: "given unlimited limit," +
+				"when take," +
+				"then always allowed",
+			limit: Limit{},
+			takes: []take{
+				{at: start, want: Decision{Allowed: true}},
+				{at: start, want: Decision{Allowed: true}},
+			},
+		},
+		{
+			name: "given burst is exhausted," +
+				"when take," +
+				"then rejected with retry after",
+			limit: limit,
+			takes: []take{
+				{at: start, want: Decision{Allowed: true, Remaining: 1}},
+				{at: start, want: Decision{Allowed: true, Remaining: 0}},
+				{at: start, want: Decision{Allowed: false, RetryAfter: time.Second}},
+			},
+		},
+		{
+			name: "given tokens refilled after waiting," +
+				"when take," +
+				"then allowed again",
+			limit: limit,
+			takes: []take{
+				{at: start, want: Decision{Allowed: true, Remaining: 1}},
+				{at: start, want: Decision{Allowed: true, Remaining: 0}},
+				{at: start.Add(time.Second), want: Decision{Allowed: true, Remaining: 0}},
+				{at: start.Add(time.Hour), want: Decision{Allowed: true, Remaining: 1}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockGenerate := &mocks.Generate{}
+				store := &memoryStore{
+					buckets:  make(map[string]*bucket),
+					generate: mockGenerate,
+				}
+
+				for _, tk := range tt.takes {
+					mockGenerate.On("Time").Return(tk.at).Once()
+
+					got, err := store.Take(context.Background(), "client:abc", tt.limit)
+
+					assert.Nil(t, err)
+					assert.Equal(t, tk.want, *got)
+				}
+			})
+	}
+}
@@ -0,0 +1,38 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrorFromStore = errors.New("error from rate limit store")
+)
+
+type (
+	// Limit is the token bucket definition, Rate is the number of tokens
+	// refilled per minute and Burst is the capacity of the bucket.
+	Limit struct {
+		Rate  int64
+		Burst int64
+	}
+
+	// Decision is the result of taking a token from the bucket.
+	Decision struct {
+		Allowed    bool
+		Remaining  int64
+		RetryAfter time.Duration
+	}
+
+	// Store is the backend which keep the buckets. The in-memory implementation is
+	// only valid for a single instance, a shared store (e.g. redis) should be
+	// plugged in when the service is running with more than one replica.
+	Store interface {
+		Take(ctx context.Context, key string, limit Limit) (*Decision, error)
+	}
+)
+
+func (l Limit) IsUnlimited() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
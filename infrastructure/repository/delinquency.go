@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type (
+	// DelinquencyEntity is the last known delinquency state of the customer,
+	// used to identify the transition of delinquency.
+	DelinquencyEntity struct {
+		UserID       string    `db:"user_id" json:"user_id,omitempty"`
+		IsDelinquent bool      `db:"is_delinquent" json:"is_delinquent"`
+		CreatedAt    time.Time `db:"created_at" json:"created_at,omitempty"`
+		Version      int       `db:"version" json:"version,omitempty"`
+		UpdatedAt    time.Time `db:"updated_at" json:"updated_at,omitempty"`
+	}
+
+	// DelinquencySnapshot takes the delinquency of the business date for every customer with the unpaid
+	// (or written-off) installments due before DueBefore, by the same tolerances as the outstanding.
+	// The installments within the grace days of their product before DueBefore are outstanding but not missed.
+	// The missed installments are tolerated by the lowest delinquency threshold of their products, MissedTolerance
+	// is the one of the loans issued before the catalogue.
+	DelinquencySnapshot struct {
+		BusinessDate                time.Time `json:"business_date,omitempty"`
+		DueBefore                   time.Time `json:"due_before,omitempty"`
+		MissedTolerance             int       `json:"missed_tolerance"`
+		RestructuredMissedTolerance int       `json:"restructured_missed_tolerance"`
+		CreatedAt                   time.Time `json:"created_at,omitempty"`
+	}
+
+	// SnapshotDueDate is the oldest due date of the installments of the customer in the snapshot,
+	// per the grace days of their products, the days past due of the customer are counted from it.
+	SnapshotDueDate struct {
+		UserID        string
+		GraceDays     int
+		OldestDueDate time.Time
+	}
+
+	// DelinquencyTransition is the customer whose delinquency in the snapshot of the business date is different
+	// from the last known, the delinquent customer not in the snapshot has nothing due unpaid anymore.
+	DelinquencyTransition struct {
+		UserID             string
+		IsDelinquent       bool
+		MissedInstallments int
+		OutstandingAmount  decimal.Decimal
+	}
+
+	DelinquencyRepository interface {
+		FindDelinquency(ctx context.Context, userID string) (*DelinquencyEntity, error)
+
+		UpsertDelinquency(ctx context.Context, tx *sql.Tx, delinquency *DelinquencyEntity) error
+
+		// SaveSnapshot replaces the snapshot of the business date (table delinquency_snapshot),
+		// it returns the number of the customers snapshotted.
+		SaveSnapshot(ctx context.Context, tx *sql.Tx, snapshot *DelinquencySnapshot) (int, error)
+
+		// FindSnapshotDueDates returns the oldest due dates of the customers of the snapshot, the days past due
+		// are not computed by the database since they count from the business day the installment is due on.
+		FindSnapshotDueDates(ctx context.Context, tx *sql.Tx, snapshot *DelinquencySnapshot) ([]*SnapshotDueDate, error)
+
+		// UpdateSnapshotDaysPastDue sets the days past due of the customers in the snapshot of the business date.
+		UpdateSnapshotDaysPastDue(
+			ctx context.Context,
+			tx *sql.Tx,
+			businessDate time.Time,
+			daysPastDue int,
+			userIDs []string) error
+
+		// FindSnapshotTransitions compares the snapshot of the business date with the last known delinquency,
+		// in the transaction of the snapshot so the transitions are recorded against the snapshot just saved.
+		FindSnapshotTransitions(ctx context.Context, tx *sql.Tx, businessDate time.Time) ([]*DelinquencyTransition, error)
+	}
+)
@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	querySelectDelinquency = `
+		SELECT user_id, is_delinquent, created_at, version, updated_at 
+		FROM customer_delinquency WHERE user_id = ?
+	`
+
+	queryUpsertDelinquency = `
+		INSERT INTO customer_delinquency (user_id, is_delinquent, created_at, version, updated_at) 
+		VALUES (?, ?, ?, 0, ?) 
+		ON DUPLICATE KEY UPDATE 
+			is_delinquent = VALUES(is_delinquent),
+			version = version + 1,
+			updated_at = VALUES(updated_at)
+	`
+
+	queryDeleteDelinquencySnapshot = `
+		DELETE FROM delinquency_snapshot WHERE business_date = ?
+	`
+
+	queryInsertDelinquencySnapshot = `
+		INSERT INTO delinquency_snapshot (business_date, user_id, missed_installments, missed_restructured, written_off,
+			outstanding_amount, oldest_due_date, days_past_due, is_delinquent, created_at)
+		SELECT s.business_date, s.user_id, s.missed, s.missed_restructured, s.written_off,
+			s.outstanding, s.oldest_due_date, 0,
+			s.missed > COALESCE(s.missed_tolerance, ?) OR s.missed_restructured > ? OR s.written_off > 0, ?
+		FROM (
+			SELECT ? AS business_date, l.user_id,
+				SUM(l.status IN ('PENDING', 'OVERDUE') AND COALESCE(l.restructure_id, '') = ''
+					AND l.due_date < DATE_SUB(?, INTERVAL COALESCE(p.grace_days, 0) DAY)) AS missed,
+				SUM(l.status IN ('PENDING', 'OVERDUE') AND COALESCE(l.restructure_id, '') <> ''
+					AND l.due_date < DATE_SUB(?, INTERVAL COALESCE(p.grace_days, 0) DAY)) AS missed_restructured,
+				MIN(CASE WHEN l.status IN ('PENDING', 'OVERDUE') AND COALESCE(l.restructure_id, '') = ''
+					AND l.due_date < DATE_SUB(?, INTERVAL COALESCE(p.grace_days, 0) DAY)
+					THEN COALESCE(p.delinquency_threshold, ?) END) AS missed_tolerance,
+				SUM(l.status = 'WRITTEN_OFF') AS written_off,
+				SUM(l.amount) AS outstanding,
+				MIN(DATE(l.due_date)) AS oldest_due_date
+			FROM loan l LEFT JOIN product p ON p.id = l.product_id
+			WHERE l.status IN ('PENDING', 'OVERDUE', 'WRITTEN_OFF') AND l.due_date < ?
+			GROUP BY l.user_id
+		) s
+	`
+
+	querySelectSnapshotDueDates = `
+		SELECT l.user_id, COALESCE(p.grace_days, 0), MIN(DATE(l.due_date))
+		FROM loan l LEFT JOIN product p ON p.id = l.product_id
+		WHERE l.status IN ('PENDING', 'OVERDUE', 'WRITTEN_OFF') AND l.due_date < ?
+		GROUP BY l.user_id, COALESCE(p.grace_days, 0)
+	`
+
+	queryUpdateSnapshotDaysPastDue = `
+		UPDATE delinquency_snapshot SET days_past_due = ? WHERE business_date = ? AND user_id IN 
+	`
+
+	querySelectSnapshotTransitions = `
+		SELECT s.user_id, s.is_delinquent, s.missed_installments + s.missed_restructured, s.outstanding_amount
+		FROM delinquency_snapshot s LEFT JOIN customer_delinquency c ON c.user_id = s.user_id
+		WHERE s.business_date = ? AND s.is_delinquent <> COALESCE(c.is_delinquent, FALSE)
+		UNION ALL
+		SELECT c.user_id, FALSE, 0, 0
+		FROM customer_delinquency c
+		WHERE c.is_delinquent AND NOT EXISTS (
+			SELECT 1 FROM delinquency_snapshot s WHERE s.business_date = ? AND s.user_id = c.user_id
+		)
+	`
+)
+
+type delinquencyRepository struct {
+	connectionDB *sql.DB
+}
+
+func NewDelinquencyRepository(connectionDB *sql.DB) DelinquencyRepository {
+	return &delinquencyRepository{
+		connectionDB: connectionDB,
+	}
+}
+
+func (d *delinquencyRepository) FindDelinquency(
+	ctx context.Context,
+	userID string) (*DelinquencyEntity, error) {
+	var r DelinquencyEntity
+	var createdAt, updatedAt string
+
+	err := d.connectionDB.QueryRowContext(ctx, querySelectDelinquency, userID).
+		Scan(&r.UserID, &r.IsDelinquent, &createdAt, &r.Version, &updatedAt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrorNoRows
+	}
+
+	if err != nil {
+		log.Println("unidentified error from database when query row -> ", err)
+		return nil, ErrorFromDBLoan
+	}
+
+	r.CreatedAt = parseDateTime(createdAt)
+	r.UpdatedAt = parseDateTime(updatedAt)
+
+	return &r, nil
+}
+
+func (d *delinquencyRepository) UpsertDelinquency(
+	ctx context.Context,
+	db *sql.Tx,
+	delinquency *DelinquencyEntity) error {
+	_, err := db.ExecContext(
+		ctx, queryUpsertDelinquency,
+		delinquency.UserID,
+		delinquency.IsDelinquent,
+		delinquency.UpdatedAt,
+		delinquency.UpdatedAt,
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	return nil
+}
+
+func (d *delinquencyRepository) SaveSnapshot(
+	ctx context.Context,
+	db *sql.Tx,
+	snapshot *DelinquencySnapshot) (int, error) {
+	businessDate := snapshot.BusinessDate.Format("2006-01-02")
+
+	//the snapshot taken again replaces the previous one, e.g. the customer paid meanwhile is no longer in it
+	if _, err := db.ExecContext(ctx, queryDeleteDelinquencySnapshot, businessDate); err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return 0, ErrorFromDBLoan
+	}
+
+	result, err := db.ExecContext(
+		ctx, queryInsertDelinquencySnapshot,
+		snapshot.MissedTolerance,
+		snapshot.RestructuredMissedTolerance,
+		snapshot.CreatedAt,
+		businessDate,
+		snapshot.DueBefore,
+		snapshot.DueBefore,
+		snapshot.DueBefore,
+		snapshot.MissedTolerance,
+		snapshot.DueBefore,
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return 0, ErrorFromDBLoan
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Println("unidentified error from database when rowsAffected -> ", err)
+		return 0, ErrorFromDBLoan
+	}
+
+	return int(affected), nil
+}
+
+func (d *delinquencyRepository) FindSnapshotDueDates(
+	ctx context.Context,
+	db *sql.Tx,
+	snapshot *DelinquencySnapshot) ([]*SnapshotDueDate, error) {
+	res, err := db.QueryContext(ctx, querySelectSnapshotDueDates, snapshot.DueBefore)
+	if err != nil {
+		log.Println("unidentified error from database when query context -> ", err)
+		return nil, ErrorFromDBLoan
+	}
+	defer res.Close()
+
+	var dueDates []*SnapshotDueDate
+	for res.Next() {
+		var r SnapshotDueDate
+		var oldestDueDate string
+
+		if errScan := res.Scan(&r.UserID, &r.GraceDays, &oldestDueDate); errScan != nil {
+			log.Println("unidentified error from database when scan -> ", errScan)
+			return nil, ErrorFromDBLoan
+		}
+
+		r.OldestDueDate = parseDateTime(oldestDueDate)
+		dueDates = append(dueDates, &r)
+	}
+
+	return dueDates, nil
+}
+
+func (d *delinquencyRepository) UpdateSnapshotDaysPastDue(
+	ctx context.Context,
+	db *sql.Tx,
+	businessDate time.Time,
+	daysPastDue int,
+	userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	parameters := []interface{}{daysPastDue, businessDate.Format("2006-01-02")}
+	for _, userID := range userIDs {
+		parameters = append(parameters, userID)
+	}
+
+	queryFull := queryUpdateSnapshotDaysPastDue + "(" + buildWhereIn(len(userIDs)) + ")"
+	if _, err := db.ExecContext(ctx, queryFull, parameters...); err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	return nil
+}
+
+func (d *delinquencyRepository) FindSnapshotTransitions(
+	ctx context.Context,
+	db *sql.Tx,
+	businessDate time.Time) ([]*DelinquencyTransition, error) {
+	date := businessDate.Format("2006-01-02")
+
+	res, err := db.QueryContext(ctx, querySelectSnapshotTransitions, date, date)
+	if err != nil {
+		log.Println("unidentified error from database when query context -> ", err)
+		return nil, ErrorFromDBLoan
+	}
+	defer res.Close()
+
+	var transitions []*DelinquencyTransition
+	for res.Next() {
+		var r DelinquencyTransition
+		var outstanding sql.NullFloat64
+
+		if errScan := res.Scan(&r.UserID, &r.IsDelinquent, &r.MissedInstallments, &outstanding); errScan != nil {
+			log.Println("unidentified error from database when scan -> ", errScan)
+			return nil, ErrorFromDBLoan
+		}
+
+		r.OutstandingAmount = decimal.NewFromFloat(outstanding.Float64)
+		transitions = append(transitions, &r)
+	}
+
+	return transitions, nil
+}
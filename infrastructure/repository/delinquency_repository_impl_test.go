@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_delinquencyRepository_SaveSnapshot(t *testing.T) {
+	dateRandom := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	snapshot := &DelinquencySnapshot{
+		BusinessDate:                dateRandom,
+		DueBefore:                   dateRandom.AddDate(0, 0, 1),
+		MissedTolerance:             2,
+		RestructuredMissedTolerance: 0,
+		CreatedAt:                   dateRandom,
+	}
+
+	tests := []struct {
+		name      string
+		deleteErr error
+		insertErr error
+		want      int
+		wantErr   error
+	}{
+		{
+			name: "given the customers with the due installments," +
+				"when saveSnapshot," +
+				"then the snapshot of the date is replaced",
+			want: 3,
+		},
+		{
+			name: "given negative case delete failed," +
+				"when saveSnapshot," +
+				"then return error",
+			deleteErr: sql.ErrTxDone,
+			wantErr:   ErrorFromDBLoan,
+		},
+		{
+			name: "given negative case insert failed," +
+				"when saveSnapshot," +
+				"then return error",
+			insertErr: sql.ErrTxDone,
+			wantErr:   ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error DelinquencyRepositoryImpl.SaveSnapshot() error = %v", err)
+				}
+				defer db.Close()
+
+				mock.ExpectBegin()
+				deleteExpect := mock.ExpectExec(regexp.QuoteMeta(queryDeleteDelinquencySnapshot)).WithArgs("2026-10-17")
+				if tt.deleteErr != nil {
+					deleteExpect.WillReturnError(tt.deleteErr)
+				} else {
+					deleteExpect.WillReturnResult(sqlmock.NewResult(0, 1))
+
+					insertExpect := mock.ExpectExec(regexp.QuoteMeta(queryInsertDelinquencySnapshot)).
+						WithArgs(
+							2, 0, dateRandom, "2026-10-17",
+							dateRandom.AddDate(0, 0, 1), dateRandom.AddDate(0, 0, 1), dateRandom.AddDate(0, 0, 1), 2,
+							dateRandom.AddDate(0, 0, 1))
+
+					if tt.insertErr != nil {
+						insertExpect.WillReturnError(tt.insertErr)
+					} else {
+						insertExpect.WillReturnResult(sqlmock.NewResult(0, 3))
+					}
+				}
+
+				tx, _ := db.Begin()
+				got, err := NewDelinquencyRepository(db).SaveSnapshot(context.Background(), tx, snapshot)
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.Equal(t, tt.want, got)
+				assert.NoError(t, mock.ExpectationsWereMet())
+			})
+	}
+}
+
+func Test_delinquencyRepository_FindSnapshotTransitions(t *testing.T) {
+	dateRandom := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		queryErr error
+		want     []*DelinquencyTransition
+		wantErr  error
+	}{
+		{
+			name: "given the snapshot different from the last known delinquency," +
+				"when findSnapshotTransitions," +
+				"then return the transitions",
+			want: []*DelinquencyTransition{
+				{UserID: "abc", IsDelinquent: true, MissedInstallments: 3, OutstandingAmount: decimal.NewFromFloat(330000)},
+				{UserID: "def", IsDelinquent: false, OutstandingAmount: decimal.NewFromFloat(0)},
+			},
+		},
+		{
+			name: "given negative case query failed," +
+				"when findSnapshotTransitions," +
+				"then return error",
+			queryErr: sql.ErrTxDone,
+			wantErr:  ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error DelinquencyRepositoryImpl.FindSnapshotTransitions() error = %v", err)
+				}
+				defer db.Close()
+
+				mock.ExpectBegin()
+				queryExpect := mock.ExpectQuery(regexp.QuoteMeta(querySelectSnapshotTransitions)).
+					WithArgs("2026-10-17", "2026-10-17")
+
+				if tt.queryErr != nil {
+					queryExpect.WillReturnError(tt.queryErr)
+				} else {
+					queryExpect.WillReturnRows(
+						sqlmock.NewRows([]string{"user_id", "is_delinquent", "missed", "outstanding_amount"}).
+							AddRow("abc", true, 3, 330000).
+							AddRow("def", false, 0, 0),
+					)
+				}
+
+				tx, _ := db.Begin()
+				got, err := NewDelinquencyRepository(db).FindSnapshotTransitions(context.Background(), tx, dateRandom)
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.Equal(t, tt.want, got)
+				assert.NoError(t, mock.ExpectationsWereMet())
+			})
+	}
+}
+
+func Test_delinquencyRepository_FindSnapshotDueDates(t *testing.T) {
+	dateRandom := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	snapshot := &DelinquencySnapshot{BusinessDate: dateRandom, DueBefore: dateRandom.AddDate(0, 0, 1)}
+
+	tests := []struct {
+		name     string
+		queryErr error
+		want     []*SnapshotDueDate
+		wantErr  error
+	}{
+		{
+			name: "given the customers with the due installments," +
+				"when findSnapshotDueDates," +
+				"then return the oldest due date per grace days",
+			want: []*SnapshotDueDate{
+				{UserID: "abc", OldestDueDate: time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)},
+				{UserID: "abc", GraceDays: 3, OldestDueDate: time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name: "given negative case query failed," +
+				"when findSnapshotDueDates," +
+				"then return error",
+			queryErr: sql.ErrTxDone,
+			wantErr:  ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error DelinquencyRepositoryImpl.FindSnapshotDueDates() error = %v", err)
+				}
+				defer db.Close()
+
+				mock.ExpectBegin()
+				queryExpect := mock.ExpectQuery(regexp.QuoteMeta(querySelectSnapshotDueDates)).
+					WithArgs(dateRandom.AddDate(0, 0, 1))
+
+				if tt.queryErr != nil {
+					queryExpect.WillReturnError(tt.queryErr)
+				} else {
+					queryExpect.WillReturnRows(
+						sqlmock.NewRows([]string{"user_id", "grace_days", "oldest_due_date"}).
+							AddRow("abc", 0, "2026-10-10").
+							AddRow("abc", 3, "2026-10-12"),
+					)
+				}
+
+				tx, _ := db.Begin()
+				got, err := NewDelinquencyRepository(db).FindSnapshotDueDates(context.Background(), tx, snapshot)
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.Equal(t, tt.want, got)
+				assert.NoError(t, mock.ExpectationsWereMet())
+			})
+	}
+}
+
+func Test_delinquencyRepository_UpdateSnapshotDaysPastDue(t *testing.T) {
+	dateRandom := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		userIDs []string
+		execErr error
+		wantErr error
+	}{
+		{
+			name: "given the customers," +
+				"when updateSnapshotDaysPastDue," +
+				"then the days past due are updated",
+			userIDs: []string{"abc", "def"},
+		},
+		{
+			name: "given no customer," +
+				"when updateSnapshotDaysPastDue," +
+				"then nothing is updated",
+		},
+		{
+			name: "given negative case exec failed," +
+				"when updateSnapshotDaysPastDue," +
+				"then return error",
+			userIDs: []string{"abc"},
+			execErr: sql.ErrTxDone,
+			wantErr: ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error DelinquencyRepositoryImpl.UpdateSnapshotDaysPastDue() error = %v", err)
+				}
+				defer db.Close()
+
+				mock.ExpectBegin()
+				if len(tt.userIDs) > 0 {
+					args := []driver.Value{7, "2026-10-17"}
+					for _, userID := range tt.userIDs {
+						args = append(args, userID)
+					}
+
+					execExpect := mock.ExpectExec(
+						regexp.QuoteMeta(queryUpdateSnapshotDaysPastDue + "(" + buildWhereIn(len(tt.userIDs)) + ")")).
+						WithArgs(args...)
+
+					if tt.execErr != nil {
+						execExpect.WillReturnError(tt.execErr)
+					} else {
+						execExpect.WillReturnResult(sqlmock.NewResult(0, int64(len(tt.userIDs))))
+					}
+				}
+
+				tx, _ := db.Begin()
+				err = NewDelinquencyRepository(db).UpdateSnapshotDaysPastDue(
+					context.Background(), tx, dateRandom, 7, tt.userIDs)
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.NoError(t, mock.ExpectationsWereMet())
+			})
+	}
+}
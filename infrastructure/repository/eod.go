@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	EodRunning   = "RUNNING"
+	EodFailed    = "FAILED"
+	EodCompleted = "COMPLETED"
+)
+
+type (
+	// EodRunEntity is the end of day of the business date, it is closed once and resumed
+	// from the step which is not completed when it failed.
+	EodRunEntity struct {
+		ID           uint64    `db:"id" json:"id,omitempty"`
+		BusinessDate time.Time `db:"business_date" json:"business_date,omitempty"`
+		Status       string    `db:"status" json:"status,omitempty"`
+		Error        string    `db:"error" json:"error,omitempty"`
+		StartedAt    time.Time `db:"started_at" json:"started_at,omitempty"`
+		// FinishedAt is zero while the run is RUNNING.
+		FinishedAt time.Time        `db:"finished_at" json:"finished_at,omitempty"`
+		Steps      []*EodStepEntity `json:"steps,omitempty"`
+		CreatedAt  time.Time        `db:"created_at" json:"created_at,omitempty"`
+		Version    int              `db:"version" json:"version,omitempty"`
+		UpdatedAt  time.Time        `db:"updated_at" json:"updated_at,omitempty"`
+	}
+
+	EodStepEntity struct {
+		ID           uint64    `db:"id" json:"id,omitempty"`
+		BusinessDate time.Time `db:"business_date" json:"business_date,omitempty"`
+		Step         string    `db:"step" json:"step,omitempty"`
+		Sequence     int       `db:"sequence" json:"sequence,omitempty"`
+		Status       string    `db:"status" json:"status,omitempty"`
+		// Processed is the number of the records processed by the step, e.g. the installments marked as overdue.
+		Processed  int       `db:"processed" json:"processed"`
+		Error      string    `db:"error" json:"error,omitempty"`
+		StartedAt  time.Time `db:"started_at" json:"started_at,omitempty"`
+		FinishedAt time.Time `db:"finished_at" json:"finished_at,omitempty"`
+	}
+
+	EodRunFilter struct {
+		BusinessDate time.Time `json:"business_date,omitempty"`
+		// Limit is applied ordered by the business date, the latest first.
+		Limit int `json:"limit,omitempty"`
+	}
+
+	EodRepository interface {
+		// SaveRun ignores the run which exists for the business date.
+		SaveRun(ctx context.Context, run *EodRunEntity) error
+
+		// LockRun takes the lock of the end of day, it is held until unlock is called or the connection is lost.
+		// It returns ErrorDuplicate when the lock is held by another run.
+		LockRun(ctx context.Context) (unlock func(), err error)
+
+		// UpdateRun updates the status, the error and the times of the run of the business date.
+		UpdateRun(ctx context.Context, run *EodRunEntity) error
+
+		// SaveStep inserts or replaces the step of the business date.
+		SaveStep(ctx context.Context, step *EodStepEntity) error
+
+		// FindRuns returns the runs together with their steps ordered by the sequence.
+		FindRuns(ctx context.Context, filter *EodRunFilter) ([]*EodRunEntity, error)
+	}
+)
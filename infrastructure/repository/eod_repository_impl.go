@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	queryInsertEodRun = `
+		INSERT IGNORE INTO eod_run (business_date, status, error, started_at, finished_at, created_at, version, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	queryUpdateEodRun = `
+		UPDATE eod_run SET status = ?, error = ?, started_at = ?, finished_at = ?, version = version + 1, updated_at = ?
+		WHERE business_date = ?
+	`
+
+	queryUpsertEodStep = `
+		INSERT INTO eod_step (business_date, step, sequence, status, processed, error, started_at, finished_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			status = VALUES(status),
+			processed = VALUES(processed),
+			error = VALUES(error),
+			started_at = VALUES(started_at),
+			finished_at = VALUES(finished_at)
+	`
+
+	querySelectEodRun = `
+		SELECT id, business_date, status, error, started_at, finished_at, created_at, version, updated_at
+		FROM eod_run WHERE TRUE
+	`
+
+	querySelectEodStep = `
+		SELECT id, business_date, step, sequence, status, processed, error, started_at, finished_at
+		FROM eod_step WHERE business_date IN
+	`
+
+	queryGetEodLock = `SELECT GET_LOCK(?, 0)`
+
+	queryReleaseEodLock = `SELECT RELEASE_LOCK(?)`
+
+	eodDateLayout = "2006-01-02"
+	eodLockName   = "billing_eod_run"
+)
+
+type eodRepository struct {
+	connectionDB *sql.DB
+}
+
+func NewEodRepository(connectionDB *sql.DB) EodRepository {
+	return &eodRepository{
+		connectionDB: connectionDB,
+	}
+}
+
+func (e *eodRepository) SaveRun(
+	ctx context.Context,
+	run *EodRunEntity) error {
+	_, err := e.connectionDB.ExecContext(
+		ctx, queryInsertEodRun,
+		run.BusinessDate.Format(eodDateLayout),
+		run.Status,
+		run.Error,
+		run.StartedAt,
+		nullTime(run.FinishedAt),
+		run.CreatedAt,
+		run.Version,
+		run.UpdatedAt,
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	return nil
+}
+
+func (e *eodRepository) LockRun(ctx context.Context) (func(), error) {
+	//the lock belongs to the connection, it is released by mysql as well when the connection is lost
+	conn, err := e.connectionDB.Conn(ctx)
+	if err != nil {
+		log.Println("unidentified error from database when conn -> ", err)
+		return nil, ErrorFromDBLoan
+	}
+
+	var acquired sql.NullInt64
+	if errScan := conn.QueryRowContext(ctx, queryGetEodLock, eodLockName).Scan(&acquired); errScan != nil {
+		log.Println("unidentified error from database when scan -> ", errScan)
+		_ = conn.Close()
+		return nil, ErrorFromDBLoan
+	}
+
+	if !acquired.Valid {
+		log.Println("unidentified error from database when get lock -> ", eodLockName)
+		_ = conn.Close()
+		return nil, ErrorFromDBLoan
+	}
+
+	if acquired.Int64 == 0 {
+		_ = conn.Close()
+		return nil, ErrorDuplicate
+	}
+
+	return func() {
+		if _, errExec := conn.ExecContext(context.Background(), queryReleaseEodLock, eodLockName); errExec != nil {
+			log.Println("unidentified error from database when exec -> ", errExec)
+			//the connection still holding the lock is discarded instead of being back to the pool
+			_ = conn.Raw(func(driverConn interface{}) error {
+				return driver.ErrBadConn
+			})
+		}
+
+		_ = conn.Close()
+	}, nil
+}
+
+func (e *eodRepository) UpdateRun(
+	ctx context.Context,
+	run *EodRunEntity) error {
+	_, err := e.connectionDB.ExecContext(
+		ctx, queryUpdateEodRun,
+		run.Status,
+		truncate(run.Error),
+		run.StartedAt,
+		nullTime(run.FinishedAt),
+		run.UpdatedAt,
+		run.BusinessDate.Format(eodDateLayout),
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	return nil
+}
+
+func (e *eodRepository) SaveStep(
+	ctx context.Context,
+	step *EodStepEntity) error {
+	_, err := e.connectionDB.ExecContext(
+		ctx, queryUpsertEodStep,
+		step.BusinessDate.Format(eodDateLayout),
+		step.Step,
+		step.Sequence,
+		step.Status,
+		step.Processed,
+		truncate(step.Error),
+		step.StartedAt,
+		nullTime(step.FinishedAt),
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	return nil
+}
+
+func (e *eodRepository) FindRuns(
+	ctx context.Context,
+	filter *EodRunFilter) ([]*EodRunEntity, error) {
+	var sb strings.Builder
+	var parameters []interface{}
+
+	if !filter.BusinessDate.IsZero() {
+		sb.WriteString("AND business_date = ? ")
+		parameters = append(parameters, filter.BusinessDate.Format(eodDateLayout))
+	}
+
+	sb.WriteString("ORDER BY business_date DESC ")
+	if filter.Limit > 0 {
+		sb.WriteString("LIMIT ?")
+		parameters = append(parameters, filter.Limit)
+	}
+
+	res, err := e.connectionDB.QueryContext(ctx, querySelectEodRun+sb.String(), parameters...)
+	if err != nil {
+		log.Println("unidentified error from database when query context -> ", err)
+		return nil, ErrorFromDBLoan
+	}
+	defer res.Close()
+
+	var data []*EodRunEntity
+	runs := make(map[string]*EodRunEntity)
+	for res.Next() {
+		var r EodRunEntity
+		var businessDate, startedAt, createdAt, updatedAt string
+		var finishedAt sql.NullString
+
+		errScan := res.Scan(
+			&r.ID, &businessDate,
+			&r.Status, &r.Error,
+			&startedAt, &finishedAt,
+			&createdAt, &r.Version,
+			&updatedAt,
+		)
+
+		if errScan != nil {
+			log.Println("unidentified error from database when scan -> ", errScan)
+			return nil, ErrorFromDBLoan
+		}
+
+		r.BusinessDate = parseDateTime(businessDate)
+		r.StartedAt = parseDateTime(startedAt)
+		r.FinishedAt = parseDateTime(finishedAt.String)
+		r.CreatedAt = parseDateTime(createdAt)
+		r.UpdatedAt = parseDateTime(updatedAt)
+
+		runs[r.BusinessDate.Format(eodDateLayout)] = &r
+		data = append(data, &r)
+	}
+
+	if len(data) == 0 {
+		return data, nil
+	}
+
+	if errSteps := e.findSteps(ctx, runs); errSteps != nil {
+		return nil, errSteps
+	}
+
+	return data, nil
+}
+
+func (e *eodRepository) findSteps(
+	ctx context.Context,
+	runs map[string]*EodRunEntity) error {
+	var parameters []interface{}
+	for businessDate := range runs {
+		parameters = append(parameters, businessDate)
+	}
+
+	queryFull := querySelectEodStep + "(" + buildWhereIn(len(parameters)) + ") ORDER BY sequence ASC"
+	res, err := e.connectionDB.QueryContext(ctx, queryFull, parameters...)
+	if err != nil {
+		log.Println("unidentified error from database when query context -> ", err)
+		return ErrorFromDBLoan
+	}
+	defer res.Close()
+
+	for res.Next() {
+		var r EodStepEntity
+		var businessDate, startedAt string
+		var finishedAt sql.NullString
+
+		errScan := res.Scan(
+			&r.ID, &businessDate,
+			&r.Step, &r.Sequence,
+			&r.Status, &r.Processed,
+			&r.Error, &startedAt,
+			&finishedAt,
+		)
+
+		if errScan != nil {
+			log.Println("unidentified error from database when scan -> ", errScan)
+			return ErrorFromDBLoan
+		}
+
+		r.BusinessDate = parseDateTime(businessDate)
+		r.StartedAt = parseDateTime(startedAt)
+		r.FinishedAt = parseDateTime(finishedAt.String)
+
+		if run, ok := runs[r.BusinessDate.Format(eodDateLayout)]; ok {
+			run.Steps = append(run.Steps, &r)
+		}
+	}
+
+	return nil
+}
+
+// nullTime stores the zero time as NULL.
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t
+}
+
+// truncate keeps the error within the column.
+func truncate(message string) string {
+	if len(message) > 255 {
+		return message[:255]
+	}
+
+	return message
+}
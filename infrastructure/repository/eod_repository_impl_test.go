@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_eodRepository_SaveStep(t *testing.T) {
+	dateRandom := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		step    *EodStepEntity
+		sqlErr  error
+		wantErr error
+	}{
+		{
+			name: "given the running step," +
+				"when saveStep," +
+				"then the step is saved without finished_at",
+			step: &EodStepEntity{
+				BusinessDate: dateRandom, Step: "OVERDUE_MARKING", Sequence: 1, Status: EodRunning, StartedAt: dateRandom,
+			},
+		},
+		{
+			name: "given negative case sql conn done," +
+				"when saveStep," +
+				"then return error",
+			step: &EodStepEntity{
+				BusinessDate: dateRandom, Step: "OVERDUE_MARKING", Sequence: 1, Status: EodRunning, StartedAt: dateRandom,
+			},
+			sqlErr:  sql.ErrConnDone,
+			wantErr: ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error EodRepositoryImpl.SaveStep() error = %v", err)
+				}
+				defer db.Close()
+
+				expect := mock.ExpectExec(regexp.QuoteMeta(queryUpsertEodStep)).
+					WithArgs("2026-10-17", "OVERDUE_MARKING", 1, EodRunning, 0, "", dateRandom, nil)
+
+				if tt.sqlErr != nil {
+					expect.WillReturnError(tt.sqlErr)
+				} else {
+					expect.WillReturnResult(sqlmock.NewResult(1, 1))
+				}
+
+				err = NewEodRepository(db).SaveStep(context.Background(), tt.step)
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.NoError(t, mock.ExpectationsWereMet())
+			})
+	}
+}
+
+func Test_eodRepository_LockRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		acquired interface{}
+		sqlErr   error
+		wantErr  error
+	}{
+		{
+			name: "given the lock is free," +
+				"when lockRun," +
+				"then the lock is taken until unlock",
+			acquired: int64(1),
+		},
+		{
+			name: "given the lock is held by another run," +
+				"when lockRun," +
+				"then return error duplicate",
+			acquired: int64(0),
+			wantErr:  ErrorDuplicate,
+		},
+		{
+			name: "given the lock is failed," +
+				"when lockRun," +
+				"then return error",
+			acquired: nil,
+			wantErr:  ErrorFromDBLoan,
+		},
+		{
+			name: "given negative case sql conn done," +
+				"when lockRun," +
+				"then return error",
+			sqlErr:  sql.ErrConnDone,
+			wantErr: ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error EodRepositoryImpl.LockRun() error = %v", err)
+				}
+				defer db.Close()
+
+				expect := mock.ExpectQuery(regexp.QuoteMeta(queryGetEodLock)).WithArgs(eodLockName)
+				if tt.sqlErr != nil {
+					expect.WillReturnError(tt.sqlErr)
+				} else {
+					expect.WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(tt.acquired))
+				}
+
+				if tt.wantErr == nil {
+					mock.ExpectExec(regexp.QuoteMeta(queryReleaseEodLock)).
+						WithArgs(eodLockName).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+
+				unlock, err := NewEodRepository(db).LockRun(context.Background())
+				assert.Equal(t, tt.wantErr, err)
+
+				if unlock != nil {
+					unlock()
+				}
+
+				assert.NoError(t, mock.ExpectationsWereMet())
+			})
+	}
+}
+
+func Test_eodRepository_FindRuns(t *testing.T) {
+	dateRandom := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	startedAt := time.Date(2026, 10, 18, 0, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		filter    *EodRunFilter
+		wantSql   string
+		wantArgs  []driver.Value
+		sqlErr    error
+		runRows   *sqlmock.Rows
+		stepRows  *sqlmock.Rows
+		wantRuns  int
+		wantSteps int
+		wantErr   error
+	}{
+		{
+			name: "given the business date," +
+				"when findRuns," +
+				"then return the run with its steps",
+			filter:   &EodRunFilter{BusinessDate: dateRandom},
+			wantSql:  "AND business_date = ? ORDER BY business_date DESC ",
+			wantArgs: []driver.Value{"2026-10-17"},
+			runRows: sqlmock.NewRows(
+				[]string{
+					"id", "business_date", "status", "error", "started_at", "finished_at", "created_at", "version", "updated_at",
+				}).
+				AddRow(1, "2026-10-17", EodFailed, "from database", startedAt, nil, startedAt, 1, startedAt),
+			stepRows: sqlmock.NewRows(
+				[]string{
+					"id", "business_date", "step", "sequence", "status", "processed", "error", "started_at", "finished_at",
+				}).
+				AddRow(1, "2026-10-17", "OVERDUE_MARKING", 1, EodCompleted, 12, "", startedAt, startedAt).
+				AddRow(2, "2026-10-17", "PENALTY_ACCRUAL", 2, EodFailed, 0, "from database", startedAt, startedAt),
+			wantRuns:  1,
+			wantSteps: 2,
+		},
+		{
+			name: "given no run," +
+				"when findRuns," +
+				"then return empty without the steps",
+			filter:   &EodRunFilter{Limit: 10},
+			wantSql:  "ORDER BY business_date DESC LIMIT ?",
+			wantArgs: []driver.Value{10},
+			runRows: sqlmock.NewRows(
+				[]string{
+					"id", "business_date", "status", "error", "started_at", "finished_at", "created_at", "version", "updated_at",
+				}),
+		},
+		{
+			name: "given negative case sql conn done," +
+				"when findRuns," +
+				"then return error",
+			filter:  &EodRunFilter{},
+			wantSql: "ORDER BY business_date DESC ",
+			sqlErr:  sql.ErrConnDone,
+			wantErr: ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error EodRepositoryImpl.FindRuns() error = %v", err)
+				}
+				defer db.Close()
+
+				expect := mock.ExpectQuery(regexp.QuoteMeta(querySelectEodRun + tt.wantSql)).WithArgs(tt.wantArgs...)
+				if tt.sqlErr != nil {
+					expect.WillReturnError(tt.sqlErr)
+				} else {
+					expect.WillReturnRows(tt.runRows)
+				}
+
+				if tt.stepRows != nil {
+					mock.ExpectQuery(regexp.QuoteMeta(querySelectEodStep + "(?) ORDER BY sequence ASC")).
+						WithArgs("2026-10-17").
+						WillReturnRows(tt.stepRows)
+				}
+
+				got, err := NewEodRepository(db).FindRuns(context.Background(), tt.filter)
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.Len(t, got, tt.wantRuns)
+				if tt.wantRuns > 0 {
+					assert.Equal(t, dateRandom, got[0].BusinessDate)
+					assert.True(t, got[0].FinishedAt.IsZero())
+					assert.Len(t, got[0].Steps, tt.wantSteps)
+					assert.Equal(t, 12, got[0].Steps[0].Processed)
+				}
+				assert.NoError(t, mock.ExpectationsWereMet())
+			})
+	}
+}
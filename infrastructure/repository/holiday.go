@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	HolidayEntity struct {
+		ID          uint64    `db:"id" json:"id,omitempty"`
+		Region      string    `db:"region" json:"region"`
+		HolidayDate time.Time `db:"holiday_date" json:"holiday_date"`
+		Name        string    `db:"name" json:"name"`
+	}
+
+	// HolidayRepository is the source of the holiday calendar (table holiday), managed by the operation.
+	HolidayRepository interface {
+		FindHolidays(ctx context.Context, region string) ([]*HolidayEntity, error)
+	}
+)
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+)
+
+const (
+	querySelectHoliday = `
+		SELECT id, region, holiday_date, name FROM holiday WHERE region = ? ORDER BY holiday_date
+	`
+)
+
+type holidayRepository struct {
+	connectionDB *sql.DB
+}
+
+func NewHolidayRepository(connectionDB *sql.DB) HolidayRepository {
+	return &holidayRepository{
+		connectionDB: connectionDB,
+	}
+}
+
+func (h *holidayRepository) FindHolidays(
+	ctx context.Context,
+	region string) ([]*HolidayEntity, error) {
+	res, err := h.connectionDB.QueryContext(ctx, querySelectHoliday, region)
+	if err != nil {
+		log.Println("unidentified error from database when query context -> ", err)
+		return nil, ErrorFromDBLoan
+	}
+	defer res.Close()
+
+	var data []*HolidayEntity
+	for res.Next() {
+		var holiday HolidayEntity
+		var holidayDate string
+
+		errScan := res.Scan(&holiday.ID, &holiday.Region, &holidayDate, &holiday.Name)
+		if errScan != nil {
+			log.Println("unidentified error from database when scan -> ", errScan)
+			return nil, ErrorFromDBLoan
+		}
+
+		holiday.HolidayDate = parseDateTime(holidayDate)
+		data = append(data, &holiday)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_holidayRepository_FindHolidays(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    *sqlmock.Rows
+		sqlErr  error
+		want    []*HolidayEntity
+		wantErr error
+	}{
+		{
+			name: "given the holidays of the region," +
+				"when findHolidays," +
+				"then return the holidays",
+			rows: sqlmock.NewRows([]string{"id", "region", "holiday_date", "name"}).
+				AddRow(1, "ID", "2026-03-20", "Idul Fitri").
+				AddRow(2, "ID", "2026-03-21", "Idul Fitri"),
+			want: []*HolidayEntity{
+				{ID: 1, Region: "ID", HolidayDate: time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC), Name: "Idul Fitri"},
+				{ID: 2, Region: "ID", HolidayDate: time.Date(2026, 3, 21, 0, 0, 0, 0, time.UTC), Name: "Idul Fitri"},
+			},
+		},
+		{
+			name: "given negative case sql conn done," +
+				"when findHolidays," +
+				"then return error",
+			sqlErr:  sql.ErrConnDone,
+			wantErr: ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error HolidayRepositoryImpl.FindHolidays() error = %v", err)
+				}
+				defer db.Close()
+
+				expect := mock.ExpectQuery(regexp.QuoteMeta(querySelectHoliday)).WithArgs("ID")
+				if tt.sqlErr != nil {
+					expect.WillReturnError(tt.sqlErr)
+				} else {
+					expect.WillReturnRows(tt.rows)
+				}
+
+				h := NewHolidayRepository(db)
+				got, err := h.FindHolidays(context.Background(), "ID")
+				assert.Equal(t, tt.wantErr, err)
+				assert.Equal(t, tt.want, got)
+				assert.NoError(t, mock.ExpectationsWereMet())
+			},
+		)
+	}
+}
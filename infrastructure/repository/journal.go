@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var ErrorUnbalancedEntry = errors.New("debits and credits of the journal entry are not equal")
+
+type (
+	// JournalEntryEntity is the double-entry record of a financial event, the sum of the debits
+	// should be equal to the sum of the credits. It is booked once per entry type and reference.
+	JournalEntryEntity struct {
+		ID        uint64 `db:"id" json:"id,omitempty"`
+		EntryID   string `db:"entry_id" json:"entry_id,omitempty"`
+		EntryType string `db:"entry_type" json:"entry_type,omitempty"`
+		// Reference is the id of the source of the event, e.g. the payment id or the installment id.
+		Reference   string               `db:"reference" json:"reference,omitempty"`
+		UserID      string               `db:"user_id" json:"user_id,omitempty"`
+		Branch      string               `db:"branch" json:"branch,omitempty"`
+		Description string               `db:"description" json:"description,omitempty"`
+		BookedAt    time.Time            `db:"booked_at" json:"booked_at,omitempty"`
+		Lines       []*JournalLineEntity `json:"lines,omitempty"`
+		CreatedAt   time.Time            `db:"created_at" json:"created_at,omitempty"`
+		Version     int                  `db:"version" json:"version,omitempty"`
+		UpdatedAt   time.Time            `db:"updated_at" json:"updated_at,omitempty"`
+	}
+
+	JournalLineEntity struct {
+		ID      uint64          `db:"id" json:"id,omitempty"`
+		EntryID string          `db:"entry_id" json:"entry_id,omitempty"`
+		Account string          `db:"account" json:"account,omitempty"`
+		Debit   decimal.Decimal `db:"debit" json:"debit"`
+		Credit  decimal.Decimal `db:"credit" json:"credit"`
+	}
+
+	JournalEntryFilter struct {
+		EntryType  string   `json:"entry_type,omitempty"`
+		References []string `json:"references,omitempty"`
+	}
+
+	JournalBalanceFilter struct {
+		// BookedBefore is exclusive, e.g. the start of the next day for the balance at the end of the day.
+		BookedBefore time.Time `json:"booked_before,omitempty"`
+		Account      string    `json:"account,omitempty"`
+	}
+
+	// AccountBalanceEntity is the sum of the debits and the credits of the account.
+	AccountBalanceEntity struct {
+		Account string          `db:"account" json:"account,omitempty"`
+		Debit   decimal.Decimal `db:"debit" json:"debit"`
+		Credit  decimal.Decimal `db:"credit" json:"credit"`
+	}
+
+	JournalTotalFilter struct {
+		// BookedFrom is inclusive and BookedBefore is exclusive, e.g. the start of the day and of the next day.
+		BookedFrom   time.Time `json:"booked_from,omitempty"`
+		BookedBefore time.Time `json:"booked_before,omitempty"`
+	}
+
+	// AccountTotalEntity is the sum of the debits and the credits of the account per branch,
+	// Lines is the number of the journal lines summed.
+	AccountTotalEntity struct {
+		Account string          `db:"account" json:"account,omitempty"`
+		Branch  string          `db:"branch" json:"branch,omitempty"`
+		Debit   decimal.Decimal `db:"debit" json:"debit"`
+		Credit  decimal.Decimal `db:"credit" json:"credit"`
+		Lines   int             `db:"lines" json:"lines"`
+	}
+
+	JournalRepository interface {
+		// SaveEntries returns ErrorUnbalancedEntry when any of the entries is not balanced, so nothing is saved.
+		// The entry which already exists for the same entry type and reference is ignored together with its lines.
+		SaveEntries(ctx context.Context, tx *sql.Tx, entries ...*JournalEntryEntity) error
+
+		// FindEntries returns the entries without their lines.
+		FindEntries(ctx context.Context, filter *JournalEntryFilter) ([]*JournalEntryEntity, error)
+
+		FindBalances(ctx context.Context, filter *JournalBalanceFilter) ([]*AccountBalanceEntity, error)
+
+		// FindTotals returns the totals ordered by the account and the branch.
+		FindTotals(ctx context.Context, filter *JournalTotalFilter) ([]*AccountTotalEntity, error)
+	}
+)
+
+// IsBalanced reports whether the entry has lines and the sum of the debits is equal to the sum of the credits.
+func (e *JournalEntryEntity) IsBalanced() bool {
+	if len(e.Lines) == 0 {
+		return false
+	}
+
+	debit, credit := decimal.Zero, decimal.Zero
+	for _, line := range e.Lines {
+		if line.Debit.IsNegative() || line.Credit.IsNegative() {
+			return false
+		}
+
+		debit = debit.Add(line.Debit)
+		credit = credit.Add(line.Credit)
+	}
+
+	return debit.Equal(credit)
+}
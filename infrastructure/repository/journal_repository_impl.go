@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	queryInsertJournalEntry = `
+		INSERT IGNORE INTO journal_entry (entry_id, entry_type, reference, user_id, branch, description, booked_at, created_at, version, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	queryInsertJournalLine = `
+		INSERT INTO journal_line (entry_id, account, debit, credit)
+		VALUES (?, ?, ?, ?)
+	`
+
+	querySelectJournalEntry = `
+		SELECT id, entry_id, entry_type, reference, user_id, branch, description, booked_at, created_at, version, updated_at
+		FROM journal_entry WHERE TRUE
+	`
+
+	querySelectJournalBalance = `
+		SELECT l.account, SUM(l.debit), SUM(l.credit)
+		FROM journal_line l JOIN journal_entry e ON e.entry_id = l.entry_id WHERE TRUE
+	`
+
+	querySelectJournalTotal = `
+		SELECT l.account, e.branch, SUM(l.debit), SUM(l.credit), COUNT(l.id)
+		FROM journal_line l JOIN journal_entry e ON e.entry_id = l.entry_id
+		WHERE e.booked_at >= ? AND e.booked_at < ?
+		GROUP BY l.account, e.branch ORDER BY l.account ASC, e.branch ASC
+	`
+)
+
+type journalRepository struct {
+	connectionDB *sql.DB
+}
+
+func NewJournalRepository(connectionDB *sql.DB) JournalRepository {
+	return &journalRepository{
+		connectionDB: connectionDB,
+	}
+}
+
+func (j *journalRepository) SaveEntries(
+	ctx context.Context,
+	tx *sql.Tx,
+	entries ...*JournalEntryEntity) error {
+	for _, entry := range entries {
+		if !entry.IsBalanced() {
+			log.Println("journal entry is not balanced -> ", entry.EntryType, entry.Reference)
+			return ErrorUnbalancedEntry
+		}
+	}
+
+	for _, entry := range entries {
+		result, err := tx.ExecContext(
+			ctx, queryInsertJournalEntry,
+			entry.EntryID,
+			entry.EntryType,
+			entry.Reference,
+			entry.UserID,
+			entry.Branch,
+			entry.Description,
+			entry.BookedAt,
+			entry.CreatedAt,
+			entry.Version,
+			entry.UpdatedAt,
+		)
+
+		if err != nil {
+			log.Println("unidentified error from database when exec -> ", err)
+			return ErrorFromDBLoan
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			log.Println("unidentified error from database when rowsAffected -> ", err)
+			return ErrorFromDBLoan
+		}
+
+		//the event has been booked
+		if affected == 0 {
+			continue
+		}
+
+		for _, line := range entry.Lines {
+			_, errLine := tx.ExecContext(ctx, queryInsertJournalLine, entry.EntryID, line.Account, line.Debit, line.Credit)
+			if errLine != nil {
+				log.Println("unidentified error from database when exec -> ", errLine)
+				return ErrorFromDBLoan
+			}
+		}
+	}
+
+	return nil
+}
+
+func (j *journalRepository) FindEntries(
+	ctx context.Context,
+	filter *JournalEntryFilter) ([]*JournalEntryEntity, error) {
+	var sb strings.Builder
+	var parameters []interface{}
+
+	if filter.EntryType != "" {
+		sb.WriteString("AND entry_type = ? ")
+		parameters = append(parameters, filter.EntryType)
+	}
+
+	if len(filter.References) > 0 {
+		sb.WriteString("AND reference IN (" + buildWhereIn(len(filter.References)) + ") ")
+		for _, reference := range filter.References {
+			parameters = append(parameters, reference)
+		}
+	}
+
+	res, err := j.connectionDB.QueryContext(ctx, querySelectJournalEntry+sb.String()+"ORDER BY id ASC", parameters...)
+	if err != nil {
+		log.Println("unidentified error from database when query context -> ", err)
+		return nil, ErrorFromDBLoan
+	}
+	defer res.Close()
+
+	var data []*JournalEntryEntity
+	for res.Next() {
+		var r JournalEntryEntity
+		var bookedAt, createdAt, updatedAt string
+
+		errScan := res.Scan(
+			&r.ID, &r.EntryID,
+			&r.EntryType, &r.Reference,
+			&r.UserID, &r.Branch,
+			&r.Description,
+			&bookedAt, &createdAt,
+			&r.Version, &updatedAt,
+		)
+
+		if errScan != nil {
+			log.Println("unidentified error from database when scan -> ", errScan)
+			return nil, ErrorFromDBLoan
+		}
+
+		r.BookedAt = parseDateTime(bookedAt)
+		r.CreatedAt = parseDateTime(createdAt)
+		r.UpdatedAt = parseDateTime(updatedAt)
+
+		data = append(data, &r)
+	}
+
+	return data, nil
+}
+
+func (j *journalRepository) FindBalances(
+	ctx context.Context,
+	filter *JournalBalanceFilter) ([]*AccountBalanceEntity, error) {
+	var sb strings.Builder
+	var parameters []interface{}
+
+	if !filter.BookedBefore.IsZero() {
+		sb.WriteString("AND e.booked_at < ? ")
+		parameters = append(parameters, filter.BookedBefore)
+	}
+
+	if filter.Account != "" {
+		sb.WriteString("AND l.account = ? ")
+		parameters = append(parameters, filter.Account)
+	}
+
+	res, err := j.connectionDB.QueryContext(
+		ctx, querySelectJournalBalance+sb.String()+"GROUP BY l.account ORDER BY l.account ASC", parameters...)
+
+	if err != nil {
+		log.Println("unidentified error from database when query context -> ", err)
+		return nil, ErrorFromDBLoan
+	}
+	defer res.Close()
+
+	var data []*AccountBalanceEntity
+	for res.Next() {
+		var r AccountBalanceEntity
+		var debit, credit sql.NullFloat64
+
+		if errScan := res.Scan(&r.Account, &debit, &credit); errScan != nil {
+			log.Println("unidentified error from database when scan -> ", errScan)
+			return nil, ErrorFromDBLoan
+		}
+
+		r.Debit = decimal.NewFromFloat(debit.Float64)
+		r.Credit = decimal.NewFromFloat(credit.Float64)
+
+		data = append(data, &r)
+	}
+
+	return data, nil
+}
+
+func (j *journalRepository) FindTotals(
+	ctx context.Context,
+	filter *JournalTotalFilter) ([]*AccountTotalEntity, error) {
+	res, err := j.connectionDB.QueryContext(ctx, querySelectJournalTotal, filter.BookedFrom, filter.BookedBefore)
+	if err != nil {
+		log.Println("unidentified error from database when query context -> ", err)
+		return nil, ErrorFromDBLoan
+	}
+	defer res.Close()
+
+	var data []*AccountTotalEntity
+	for res.Next() {
+		var r AccountTotalEntity
+		var debit, credit sql.NullFloat64
+
+		if errScan := res.Scan(&r.Account, &r.Branch, &debit, &credit, &r.Lines); errScan != nil {
+			log.Println("unidentified error from database when scan -> ", errScan)
+			return nil, ErrorFromDBLoan
+		}
+
+		r.Debit = decimal.NewFromFloat(debit.Float64)
+		r.Credit = decimal.NewFromFloat(credit.Float64)
+
+		data = append(data, &r)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_journalRepository_SaveEntries(t *testing.T) {
+	dateRandom := time.Date(2024, 6, 23, 21, 0, 0, 0, time.UTC)
+
+	entry := func(credit float64) *JournalEntryEntity {
+		return &JournalEntryEntity{
+			EntryID:     "je-1",
+			EntryType:   "PAYMENT",
+			Reference:   "p-1",
+			UserID:      "abc",
+			Branch:      "HQ",
+			Description: "payment p-1",
+			BookedAt:    dateRandom,
+			Lines: []*JournalLineEntity{
+				{Account: "1101", Debit: decimal.NewFromFloat(110000)},
+				{Account: "1301", Credit: decimal.NewFromFloat(100000)},
+				{Account: "1303", Credit: decimal.NewFromFloat(credit)},
+			},
+			CreatedAt: dateRandom,
+			UpdatedAt: dateRandom,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		entry     *JournalEntryEntity
+		sqlErr    error
+		sqlResult driver.Result
+		wantLines bool
+		wantErr   error
+	}{
+		{
+			name: "given balanced entry," +
+				"when saveEntries," +
+				"then the entry and its lines are saved",
+			entry:     entry(10000),
+			sqlResult: sqlmock.NewResult(1, 1),
+			wantLines: true,
+		},
+		{
+			name: "given the entry has been booked," +
+				"when saveEntries," +
+				"then the lines are not saved again",
+			entry:     entry(10000),
+			sqlResult: sqlmock.NewResult(0, 0),
+		},
+		{
+			name: "given unbalanced entry," +
+				"when saveEntries," +
+				"then return error unbalanced and nothing is saved",
+			entry:   entry(9000),
+			wantErr: ErrorUnbalancedEntry,
+		},
+		{
+			name: "given negative case sql tx done," +
+				"when saveEntries," +
+				"then return error",
+			entry:   entry(10000),
+			sqlErr:  sql.ErrTxDone,
+			wantErr: ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error JournalRepositoryImpl.SaveEntries() error = %v", err)
+				}
+				defer db.Close()
+
+				mock.ExpectBegin()
+
+				if tt.sqlErr != nil || tt.sqlResult != nil {
+					expect := mock.ExpectExec(regexp.QuoteMeta(queryInsertJournalEntry)).
+						WithArgs("je-1", "PAYMENT", "p-1", "abc", "HQ", "payment p-1", dateRandom, dateRandom, 0, dateRandom)
+
+					if tt.sqlErr != nil {
+						expect.WillReturnError(tt.sqlErr)
+					} else {
+						expect.WillReturnResult(tt.sqlResult)
+					}
+				}
+
+				if tt.wantLines {
+					for _, line := range tt.entry.Lines {
+						mock.ExpectExec(regexp.QuoteMeta(queryInsertJournalLine)).
+							WithArgs("je-1", line.Account, line.Debit, line.Credit).
+							WillReturnResult(sqlmock.NewResult(1, 1))
+					}
+				}
+
+				tx, _ := db.Begin()
+				err = NewJournalRepository(db).SaveEntries(context.Background(), tx, tt.entry)
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, mock.ExpectationsWereMet())
+			})
+	}
+}
+
+func Test_journalRepository_FindBalances(t *testing.T) {
+	dateRandom := time.Date(2024, 6, 23, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		filter   *JournalBalanceFilter
+		wantSql  string
+		wantArgs []driver.Value
+		sqlErr   error
+		sqlRows  *sqlmock.Rows
+		want     []*AccountBalanceEntity
+		wantErr  error
+	}{
+		{
+			name: "given the date and the account," +
+				"when findBalances," +
+				"then return the sum of the account booked before the date",
+			filter:   &JournalBalanceFilter{BookedBefore: dateRandom, Account: "1101"},
+			wantSql:  "AND e.booked_at < ? AND l.account = ? ",
+			wantArgs: []driver.Value{dateRandom, "1101"},
+			sqlRows: sqlmock.NewRows([]string{"account", "debit", "credit"}).
+				AddRow("1101", 220000, 110000),
+			want: []*AccountBalanceEntity{
+				{
+					Account: "1101",
+					Debit:   decimal.NewFromFloat(220000),
+					Credit:  decimal.NewFromFloat(110000),
+				},
+			},
+		},
+		{
+			name: "given negative case sql conn done," +
+				"when findBalances," +
+				"then return error",
+			filter:  &JournalBalanceFilter{},
+			sqlErr:  sql.ErrConnDone,
+			wantErr: ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error JournalRepositoryImpl.FindBalances() error = %v", err)
+				}
+				defer db.Close()
+
+				expect := mock.ExpectQuery(
+					regexp.QuoteMeta(querySelectJournalBalance + tt.wantSql + "GROUP BY l.account ORDER BY l.account ASC")).
+					WithArgs(tt.wantArgs...)
+
+				if tt.sqlErr != nil {
+					expect.WillReturnError(tt.sqlErr)
+				} else {
+					expect.WillReturnRows(tt.sqlRows)
+				}
+
+				got, err := NewJournalRepository(db).FindBalances(context.Background(), tt.filter)
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.Equal(t, tt.want, got)
+				assert.Nil(t, mock.ExpectationsWereMet())
+			})
+	}
+}
+
+func Test_journalRepository_FindTotals(t *testing.T) {
+	bookedFrom := time.Date(2024, 6, 23, 0, 0, 0, 0, time.UTC)
+	bookedBefore := bookedFrom.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name    string
+		sqlErr  error
+		sqlRows *sqlmock.Rows
+		want    []*AccountTotalEntity
+		wantErr error
+	}{
+		{
+			name: "given the lines booked in the day," +
+				"when findTotals," +
+				"then return the sum per account and branch",
+			sqlRows: sqlmock.NewRows([]string{"account", "branch", "debit", "credit", "lines"}).
+				AddRow("1101", "HQ", 220000, 0, 2).
+				AddRow("1301", "HQ", 0, 220000, 2),
+			want: []*AccountTotalEntity{
+				{Account: "1101", Branch: "HQ", Debit: decimal.NewFromFloat(220000), Credit: decimal.Zero, Lines: 2},
+				{Account: "1301", Branch: "HQ", Debit: decimal.Zero, Credit: decimal.NewFromFloat(220000), Lines: 2},
+			},
+		},
+		{
+			name: "given negative case sql conn done," +
+				"when findTotals," +
+				"then return error",
+			sqlErr:  sql.ErrConnDone,
+			wantErr: ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error JournalRepositoryImpl.FindTotals() error = %v", err)
+				}
+				defer db.Close()
+
+				expect := mock.ExpectQuery(regexp.QuoteMeta(querySelectJournalTotal)).
+					WithArgs(bookedFrom, bookedBefore)
+
+				if tt.sqlErr != nil {
+					expect.WillReturnError(tt.sqlErr)
+				} else {
+					expect.WillReturnRows(tt.sqlRows)
+				}
+
+				got, err := NewJournalRepository(db).FindTotals(
+					context.Background(), &JournalTotalFilter{BookedFrom: bookedFrom, BookedBefore: bookedBefore},
+				)
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.Equal(t, len(tt.want), len(got))
+				for i := range tt.want {
+					assert.Equal(t, tt.want[i].Account, got[i].Account)
+					assert.Equal(t, tt.want[i].Branch, got[i].Branch)
+					assert.True(t, tt.want[i].Debit.Equal(got[i].Debit))
+					assert.True(t, tt.want[i].Credit.Equal(got[i].Credit))
+					assert.Equal(t, tt.want[i].Lines, got[i].Lines)
+				}
+				assert.Nil(t, mock.ExpectationsWereMet())
+			})
+	}
+}
@@ -8,22 +8,110 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// LoanStatus is the status of the installment, it is moved only through the transitions below.
+type LoanStatus string
+
+const (
+	LoanPending  LoanStatus = "PENDING"
+	LoanOverdue  LoanStatus = "OVERDUE"
+	LoanPaid     LoanStatus = "PAID"
+	LoanClosed   LoanStatus = "CLOSED"
+	LoanReversed LoanStatus = "REVERSED"
+	// LoanRestructured is the unpaid installment cancelled by the restructuring, it is replaced by the new schedule.
+	LoanRestructured LoanStatus = "RESTRUCTURED"
+	// LoanWrittenOff is the unpaid installment charged off for accounting, it is still collected as recovery.
+	LoanWrittenOff LoanStatus = "WRITTEN_OFF"
+)
+
+// LoanUnpaid is the statuses of the installment which is not paid yet, both should be collected.
+var LoanUnpaid = []LoanStatus{LoanPending, LoanOverdue}
+
+// LoanCollectible is the statuses of the installment which is still collected from the customer,
+// the payment of the written-off installment is recorded as recovery.
+var LoanCollectible = []LoanStatus{LoanPending, LoanOverdue, LoanWrittenOff}
+
+// loanTransitions is the allowed next statuses per status, anything else is refused by UpdateLoan.
+// The reversed installment is reopened as PENDING or OVERDUE (WRITTEN_OFF for the reversed recovery),
+// so the reversal stays in the history.
+var loanTransitions = map[LoanStatus][]LoanStatus{
+	LoanPending:    {LoanOverdue, LoanPaid, LoanClosed, LoanRestructured, LoanWrittenOff},
+	LoanOverdue:    {LoanPaid, LoanClosed, LoanRestructured, LoanWrittenOff},
+	LoanPaid:       {LoanReversed},
+	LoanReversed:   {LoanPending, LoanOverdue, LoanWrittenOff},
+	LoanWrittenOff: {LoanPaid},
+}
+
+// CanTransitionTo reports whether the installment in status s can be moved to the status to.
+func (s LoanStatus) CanTransitionTo(to LoanStatus) bool {
+	for _, next := range loanTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LoanComponent is the component of the installment amount.
+type LoanComponent string
+
+const (
+	ComponentPrincipal LoanComponent = "principal"
+	ComponentInterest  LoanComponent = "interest"
+	ComponentFee       LoanComponent = "fee"
+	ComponentPenalty   LoanComponent = "penalty"
+)
+
+// LoanComponents is the default order the payment is allocated into, the principal is settled last.
+var LoanComponents = []LoanComponent{ComponentPenalty, ComponentFee, ComponentInterest, ComponentPrincipal}
+
 type (
+	// Breakdown is the components of the installment amount, the amount is always the sum of them.
+	Breakdown struct {
+		Principal decimal.Decimal `db:"principal" json:"principal"`
+		Interest  decimal.Decimal `db:"interest" json:"interest"`
+		Fee       decimal.Decimal `db:"fee" json:"fee"`
+		Penalty   decimal.Decimal `db:"penalty" json:"penalty"`
+	}
+
 	LoanEntity struct {
 		ID        uint64          `db:"id" json:"id,omitempty"`
-		Status    string          `db:"status" json:"status,omitempty"`
+		Status    LoanStatus      `db:"status" json:"status,omitempty"`
 		UserID    string          `db:"user_id" json:"user_id,omitempty"`
 		DueDate   time.Time       `db:"due_date" json:"due_date,omitempty"`
 		Amount    decimal.Decimal `db:"amount" json:"amount,omitempty"`
+		Breakdown Breakdown       `json:"breakdown"`
 		CreatedAt time.Time       `db:"created_at" json:"created_at,omitempty"`
 		Version   int             `db:"version" json:"version,omitempty"`
 		UpdatedAt time.Time       `db:"updated_at" json:"updated_at,omitempty"`
-		Statuses  []string        `json:"statuses,omitempty"`
+		// RestructureID links the installment of the new schedule to the restructuring (table loan_restructure)
+		// which keeps the original installments, it is empty for the original schedule.
+		RestructureID string `db:"restructure_id" json:"restructure_id,omitempty"`
+		// ProductID is the version of the product (table product) the loan is issued by,
+		// it is empty for the loans issued before the catalogue.
+		ProductID uint64       `db:"product_id" json:"product_id,omitempty"`
+		Statuses  []LoanStatus `json:"statuses,omitempty"`
+		// Limit is applied ordered by id when it is set, e.g. to process the installments in chunks.
+		Limit int `json:"-"`
+		// AfterID selects the installments after the id, e.g. the next chunk whose status isn't changed by the chunk.
+		AfterID uint64 `json:"-"`
+	}
+
+	// LoanPenaltyAccrual adds the penalty of the business date into the overdue installment.
+	LoanPenaltyAccrual struct {
+		ID        uint64          `db:"id" json:"id,omitempty"`
+		Amount    decimal.Decimal `db:"penalty" json:"amount"`
+		AccruedOn time.Time       `db:"penalty_accrued_on" json:"accrued_on,omitempty"`
 	}
 
 	LoanEntityUpdate struct {
-		IDs    []uint64 `db:"id" json:"id,omitempty"`
-		Status string   `db:"status" json:"status,omitempty"`
+		IDs    []uint64   `db:"id" json:"id,omitempty"`
+		Status LoanStatus `db:"status" json:"status,omitempty"`
+		// FromStatus makes the update conditional, ErrorNoRows is returned when any of the IDs
+		// is no longer in FromStatus (e.g. paid concurrently), so the caller can roll back.
+		FromStatus LoanStatus `json:"-"`
+		// Reason is recorded into the status history, e.g. the payment id.
+		Reason string `json:"-"`
 	}
 
 	LoanRepository interface {
@@ -31,6 +119,53 @@ type (
 
 		FindLoans(ctx context.Context, loanEntity *LoanEntity) ([]*LoanEntity, error)
 
-		UpdateLoan(ctx context.Context, loanEntity *LoanEntityUpdate) error
+		// UpdateLoan moves the installments to the status and records the transitions into loan_status_history,
+		// ErrorIllegalTransition is returned when any of them is not allowed, so nothing is updated.
+		UpdateLoan(ctx context.Context, tx *sql.Tx, loanEntity *LoanEntityUpdate) error
+
+		// AccruePenalty adds the penalty into both the penalty and the amount of the installment, ErrorNoRows is
+		// returned when it is no longer OVERDUE or the penalty of the date (or after) has been accrued.
+		AccruePenalty(ctx context.Context, tx *sql.Tx, accrual *LoanPenaltyAccrual) error
 	}
 )
+
+// Total is the sum of the components.
+func (b Breakdown) Total() decimal.Decimal {
+	return b.Principal.Add(b.Interest).Add(b.Fee).Add(b.Penalty)
+}
+
+// Add sums the components one by one.
+func (b Breakdown) Add(other Breakdown) Breakdown {
+	return Breakdown{
+		Principal: b.Principal.Add(other.Principal),
+		Interest:  b.Interest.Add(other.Interest),
+		Fee:       b.Fee.Add(other.Fee),
+		Penalty:   b.Penalty.Add(other.Penalty),
+	}
+}
+
+// Sub subtracts the components one by one.
+func (b Breakdown) Sub(other Breakdown) Breakdown {
+	return Breakdown{
+		Principal: b.Principal.Sub(other.Principal),
+		Interest:  b.Interest.Sub(other.Interest),
+		Fee:       b.Fee.Sub(other.Fee),
+		Penalty:   b.Penalty.Sub(other.Penalty),
+	}
+}
+
+// Component points to the component, so it can be allocated in place. It is nil for the unknown component.
+func (b *Breakdown) Component(component LoanComponent) *decimal.Decimal {
+	switch component {
+	case ComponentPrincipal:
+		return &b.Principal
+	case ComponentInterest:
+		return &b.Interest
+	case ComponentFee:
+		return &b.Fee
+	case ComponentPenalty:
+		return &b.Penalty
+	}
+
+	return nil
+}
@@ -13,12 +13,12 @@ import (
 
 const (
 	queryInsert = `
-		INSERT INTO loan (status, user_id, due_date, amount, created_at, version, updated_at) 
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO loan (status, user_id, due_date, amount, principal, interest, fee, penalty, created_at, version, updated_at, restructure_id, product_id) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	querySelect = `
-		SELECT id, status, user_id, due_date, amount, created_at, version, updated_at 
+		SELECT id, status, user_id, due_date, amount, principal, interest, fee, penalty, created_at, version, updated_at, restructure_id, product_id 
 		FROM loan WHERE TRUE
 	`
 
@@ -32,11 +32,33 @@ const (
 	WHERE 
 		id IN
 	`
+
+	querySelectForUpdate = `
+		SELECT id, status FROM loan WHERE id IN
+	`
+
+	queryUpdatePenalty = `
+		UPDATE loan SET
+			penalty = penalty + ?,
+			amount = amount + ?,
+			penalty_accrued_on = ?,
+			version = version + 1,
+			updated_at = now()
+		WHERE id = ? AND status = 'OVERDUE' AND (penalty_accrued_on IS NULL OR penalty_accrued_on < ?)
+	`
+
+	queryInsertStatusHistory = `
+		INSERT INTO loan_status_history (loan_id, from_status, to_status, reason, created_at) 
+		VALUES (?, ?, ?, ?, now())
+	`
 )
 
 var (
 	ErrorFromDBLoan = errors.New("error from database")
 	ErrorNoRows     = errors.New("no rows loan")
+	ErrorDuplicate  = errors.New("duplicate entry")
+
+	ErrorIllegalTransition = errors.New("illegal status transition")
 )
 
 type loanRepository struct {
@@ -62,9 +84,15 @@ func (l *loanRepository) SaveLoans(
 		slice[idx]["user_id"] = value.UserID
 		slice[idx]["due_date"] = value.DueDate
 		slice[idx]["amount"] = value.Amount
+		slice[idx]["principal"] = value.Breakdown.Principal
+		slice[idx]["interest"] = value.Breakdown.Interest
+		slice[idx]["fee"] = value.Breakdown.Fee
+		slice[idx]["penalty"] = value.Breakdown.Penalty
 		slice[idx]["created_at"] = value.CreatedAt
 		slice[idx]["version"] = value.Version
 		slice[idx]["updated_at"] = value.UpdatedAt
+		slice[idx]["restructure_id"] = sql.NullString{String: value.RestructureID, Valid: value.RestructureID != ""}
+		slice[idx]["product_id"] = sql.NullInt64{Int64: int64(value.ProductID), Valid: value.ProductID != 0}
 	}
 
 	statement, err := db.PrepareContext(ctx, queryInsert)
@@ -81,9 +109,15 @@ func (l *loanRepository) SaveLoans(
 			entry["user_id"],
 			entry["due_date"],
 			entry["amount"],
+			entry["principal"],
+			entry["interest"],
+			entry["fee"],
+			entry["penalty"],
 			entry["created_at"],
 			entry["version"],
 			entry["updated_at"],
+			entry["restructure_id"],
+			entry["product_id"],
 		)
 
 		if errExecContext != nil {
@@ -105,7 +139,14 @@ func (l *loanRepository) FindLoans(
 		parameters = append(parameters, sts)
 	}
 
-	var amount sql.NullFloat64
+	if loanEntity.Limit > 0 {
+		queryFull += " ORDER BY id LIMIT ?"
+		parameters = append(parameters, loanEntity.Limit)
+	}
+
+	var amount, principal, interest, fee, penalty sql.NullFloat64
+	var restructureID sql.NullString
+	var productID sql.NullInt64
 
 	res, err := l.connectionDB.QueryContext(ctx, queryFull, parameters...)
 
@@ -127,8 +168,11 @@ func (l *loanRepository) FindLoans(
 		errScan := res.Scan(
 			&r.ID, &r.Status,
 			&r.UserID, &dueDate,
-			&amount, &createdAt,
+			&amount, &principal,
+			&interest, &fee,
+			&penalty, &createdAt,
 			&r.Version, &updatedAt,
+			&restructureID, &productID,
 		)
 
 		if errScan != nil {
@@ -144,11 +188,24 @@ func (l *loanRepository) FindLoans(
 			return decimal.NewFromFloat(float64(0))
 		}()
 
-		parsedDueDate, _ := time.Parse("2006-01-02", dueDate)
-		parsedCreatedAt, _ := time.Parse("2006-01-02", createdAt)
-		parsedUpdatedAt, _ := time.Parse("2006-01-02", updatedAt)
+		parsedDueDate := parseDateTime(dueDate)
+		parsedCreatedAt := parseDateTime(createdAt)
+		parsedUpdatedAt := parseDateTime(updatedAt)
 
 		r.Amount = amt
+		r.Breakdown = Breakdown{
+			Principal: decimal.NewFromFloat(principal.Float64),
+			Interest:  decimal.NewFromFloat(interest.Float64),
+			Fee:       decimal.NewFromFloat(fee.Float64),
+			Penalty:   decimal.NewFromFloat(penalty.Float64),
+		}
+
+		//the installment recorded without the breakdown is all principal
+		if r.Breakdown.Total().IsZero() {
+			r.Breakdown.Principal = r.Amount
+		}
+		r.RestructureID = restructureID.String
+		r.ProductID = uint64(productID.Int64)
 		r.Statuses = nil
 		r.DueDate = parsedDueDate
 		r.CreatedAt = parsedCreatedAt
@@ -162,30 +219,136 @@ func (l *loanRepository) FindLoans(
 
 func (l *loanRepository) UpdateLoan(
 	ctx context.Context,
+	db *sql.Tx,
 	loanEntityUpdate *LoanEntityUpdate) error {
+	//the rows are locked until the end of transaction, so the checked status can't be changed meanwhile
+	current, err := l.lockStatuses(ctx, db, loanEntityUpdate.IDs)
+	if err != nil {
+		return err
+	}
+
+	if len(current) != len(loanEntityUpdate.IDs) {
+		return ErrorNoRows
+	}
+
+	for _, id := range loanEntityUpdate.IDs {
+		from := current[id]
+
+		if loanEntityUpdate.FromStatus != "" && from != loanEntityUpdate.FromStatus {
+			return ErrorNoRows
+		}
+
+		if !from.CanTransitionTo(loanEntityUpdate.Status) {
+			log.Println("refused status transition -> ", id, from, loanEntityUpdate.Status)
+			return ErrorIllegalTransition
+		}
+	}
+
 	querySet, parameters := builderUpdate(loanEntityUpdate)
 	for _, id := range loanEntityUpdate.IDs {
 		parameters = append(parameters, id)
 	}
 
 	queryFull := queryUpdate + querySet + queryUpdateWhere + "(" + buildWhereIn(len(loanEntityUpdate.IDs)) + ")"
-	result, err := l.connectionDB.ExecContext(ctx, queryFull, parameters...)
+	result, err := db.ExecContext(ctx, queryFull, parameters...)
 
 	if err != nil {
 		log.Println("unidentified error from database when exec -> ", err)
 		return ErrorFromDBLoan
 	}
 
-	_, err = result.RowsAffected()
+	affected, err := result.RowsAffected()
 
 	if err != nil {
 		log.Println("unidentified error from database when rowsAffected -> ", err)
 		return ErrorFromDBLoan
 	}
 
+	if affected != int64(len(loanEntityUpdate.IDs)) {
+		return ErrorNoRows
+	}
+
+	statement, err := db.PrepareContext(ctx, queryInsertStatusHistory)
+	if err != nil {
+		log.Println("unidentified error from database when prepare -> ", err)
+		return ErrorFromDBLoan
+	}
+	defer statement.Close()
+
+	for _, id := range loanEntityUpdate.IDs {
+		_, errExecContext := statement.ExecContext(
+			ctx, id, current[id], loanEntityUpdate.Status, loanEntityUpdate.Reason)
+
+		if errExecContext != nil {
+			log.Println("unidentified error from database when exec -> ", errExecContext)
+			return ErrorFromDBLoan
+		}
+	}
+
 	return nil
 }
 
+func (l *loanRepository) AccruePenalty(
+	ctx context.Context,
+	db *sql.Tx,
+	accrual *LoanPenaltyAccrual) error {
+	accruedOn := accrual.AccruedOn.Format("2006-01-02")
+	result, err := db.ExecContext(
+		ctx, queryUpdatePenalty, accrual.Amount, accrual.Amount, accruedOn, accrual.ID, accruedOn,
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Println("unidentified error from database when rowsAffected -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	if affected == 0 {
+		return ErrorNoRows
+	}
+
+	return nil
+}
+
+func (l *loanRepository) lockStatuses(
+	ctx context.Context,
+	db *sql.Tx,
+	ids []uint64) (map[uint64]LoanStatus, error) {
+	var parameters []interface{}
+	for _, id := range ids {
+		parameters = append(parameters, id)
+	}
+
+	queryFull := querySelectForUpdate + "(" + buildWhereIn(len(ids)) + ") FOR UPDATE"
+	res, err := db.QueryContext(ctx, queryFull, parameters...)
+
+	if err != nil {
+		log.Println("unidentified error from database when query context -> ", err)
+		return nil, ErrorFromDBLoan
+	}
+	defer res.Close()
+
+	statuses := make(map[uint64]LoanStatus)
+	for res.Next() {
+		var id uint64
+		var status LoanStatus
+
+		if errScan := res.Scan(&id, &status); errScan != nil {
+			log.Println("unidentified error from database when scan -> ", errScan)
+			return nil, ErrorFromDBLoan
+		}
+
+		statuses[id] = status
+	}
+
+	return statuses, nil
+}
+
 func builderWhere(loanEntity *LoanEntity) (string, []interface{}) {
 	var sb strings.Builder
 	var parameters []interface{}
@@ -205,6 +368,11 @@ func builderWhere(loanEntity *LoanEntity) (string, []interface{}) {
 		parameters = append(parameters, loanEntity.DueDate)
 	}
 
+	if loanEntity.AfterID != 0 {
+		sb.WriteString("AND id > ? ")
+		parameters = append(parameters, loanEntity.AfterID)
+	}
+
 	return sb.String(), parameters
 }
 
@@ -220,6 +388,22 @@ func builderUpdate(loanEntity *LoanEntityUpdate) (string, []interface{}) {
 	return sb.String(), parameters
 }
 
+// parseDateTime parses date, datetime and timestamp column which scanned as string.
+func parseDateTime(value string) time.Time {
+	for _, layout := range []string{
+		"2006-01-02 15:04:05",
+		time.RFC3339Nano,
+		"2006-01-02",
+	} {
+		parsed, err := time.Parse(layout, value)
+		if err == nil {
+			return parsed
+		}
+	}
+
+	return time.Time{}
+}
+
 func buildWhereIn(n int) string {
 	return strings.Trim(strings.Repeat("?,", n), ",")
 }
@@ -18,11 +18,15 @@ func Test_loanRepository_SaveLoans(t *testing.T) {
 	dateRandom := time.Date(2024, 6, 23, 21, 0, 0, 0, time.UTC)
 	le := []*LoanEntity{
 		{
-			ID:        uint64(1),
-			Status:    "PENDING",
-			UserID:    "CUSTOMER01",
-			DueDate:   dateRandom,
-			Amount:    decimal.NewFromFloat(float64(120000)),
+			ID:      uint64(1),
+			Status:  "PENDING",
+			UserID:  "CUSTOMER01",
+			DueDate: dateRandom,
+			Amount:  decimal.NewFromFloat(float64(120000)),
+			Breakdown: Breakdown{
+				Principal: decimal.NewFromFloat(float64(100000)),
+				Fee:       decimal.NewFromFloat(float64(20000)),
+			},
 			CreatedAt: dateRandom,
 			Version:   0,
 			UpdatedAt: dateRandom,
@@ -91,9 +95,15 @@ func Test_loanRepository_SaveLoans(t *testing.T) {
 							"CUSTOMER01",
 							dateRandom,
 							decimal.NewFromFloat(float64(120000)),
+							decimal.NewFromFloat(float64(100000)),
+							decimal.Decimal{},
+							decimal.NewFromFloat(float64(20000)),
+							decimal.Decimal{},
 							dateRandom,
 							0,
 							dateRandom,
+							sql.NullString{},
+							sql.NullInt64{},
 						).
 						WillReturnError(tt.sqlErr)
 				}
@@ -107,9 +117,15 @@ func Test_loanRepository_SaveLoans(t *testing.T) {
 							"CUSTOMER01",
 							dateRandom,
 							decimal.NewFromFloat(float64(120000)),
+							decimal.NewFromFloat(float64(100000)),
+							decimal.Decimal{},
+							decimal.NewFromFloat(float64(20000)),
+							decimal.Decimal{},
 							dateRandom,
 							0,
 							dateRandom,
+							sql.NullString{},
+							sql.NullInt64{},
 						).
 						WillReturnResult(tt.sqlResult)
 				}
@@ -133,14 +149,21 @@ func Test_loanRepository_FindLoan(t *testing.T) {
 
 	var data []*LoanEntity
 	le := LoanEntity{
-		ID:        uint64(10),
-		Status:    "PENDING",
-		UserID:    "customer01",
-		DueDate:   dateRandom,
-		Amount:    decimal.NewFromFloat(float64(120000)),
+		ID:      uint64(10),
+		Status:  "PENDING",
+		UserID:  "customer01",
+		DueDate: dateRandom,
+		Amount:  decimal.NewFromFloat(float64(120000)),
+		Breakdown: Breakdown{
+			Principal: decimal.NewFromFloat(float64(100000)),
+			Interest:  decimal.NewFromFloat(float64(0)),
+			Fee:       decimal.NewFromFloat(float64(20000)),
+			Penalty:   decimal.NewFromFloat(float64(0)),
+		},
 		CreatedAt: dateRandom,
 		Version:   1,
 		UpdatedAt: dateRandom,
+		ProductID: uint64(7),
 		Statuses:  nil,
 	}
 	data = append(data, &le)
@@ -167,7 +190,7 @@ func Test_loanRepository_FindLoan(t *testing.T) {
 					UserID:   "customer012",
 					DueDate:  dateRandom,
 					Amount:   decimal.NewFromFloat(float64(110000)),
-					Statuses: []string{"PENDING"},
+					Statuses: []LoanStatus{"PENDING"},
 				},
 			},
 			sqlRows: sqlmock.NewRows(
@@ -177,9 +200,15 @@ func Test_loanRepository_FindLoan(t *testing.T) {
 					"user_id",
 					"due_date",
 					"amount",
+					"principal",
+					"interest",
+					"fee",
+					"penalty",
 					"created_at",
 					"version",
 					"updated_at",
+					"restructure_id",
+					"product_id",
 				}).
 				AddRow(
 					le.ID,
@@ -187,9 +216,15 @@ func Test_loanRepository_FindLoan(t *testing.T) {
 					le.UserID,
 					le.DueDate,
 					le.Amount,
+					le.Breakdown.Principal,
+					le.Breakdown.Interest,
+					le.Breakdown.Fee,
+					le.Breakdown.Penalty,
 					le.CreatedAt,
 					le.Version,
 					le.UpdatedAt,
+					nil,
+					le.ProductID,
 				),
 			want: data,
 		},
@@ -204,7 +239,7 @@ func Test_loanRepository_FindLoan(t *testing.T) {
 					UserID:   "customer012",
 					DueDate:  dateRandom,
 					Amount:   decimal.NewFromFloat(float64(110000)),
-					Statuses: []string{"PENDING", "CLOSED"},
+					Statuses: []LoanStatus{"PENDING", "CLOSED"},
 				},
 			},
 			sqlErr:  sql.ErrNoRows,
@@ -222,7 +257,7 @@ func Test_loanRepository_FindLoan(t *testing.T) {
 					UserID:   "customer012",
 					DueDate:  dateRandom,
 					Amount:   decimal.NewFromFloat(float64(110000)),
-					Statuses: []string{"PENDING", "CLOSED"},
+					Statuses: []LoanStatus{"PENDING", "CLOSED"},
 				},
 			},
 			sqlErr:  sql.ErrTxDone,
@@ -240,7 +275,7 @@ func Test_loanRepository_FindLoan(t *testing.T) {
 					UserID:   "customer012",
 					DueDate:  dateRandom,
 					Amount:   decimal.NewFromFloat(float64(110000)),
-					Statuses: []string{"PENDING", "CLOSED"},
+					Statuses: []LoanStatus{"PENDING", "CLOSED"},
 				},
 			},
 			sqlRows: sqlmock.NewRows(
@@ -250,9 +285,15 @@ func Test_loanRepository_FindLoan(t *testing.T) {
 					"user_id",
 					"due_date",
 					"amount",
+					"principal",
+					"interest",
+					"fee",
+					"penalty",
 					"created_at",
 					"version",
 					"updated_at",
+					"restructure_id",
+					"product_id",
 				}).
 				AddRow(
 					le.ID,
@@ -260,17 +301,91 @@ func Test_loanRepository_FindLoan(t *testing.T) {
 					le.UserID,
 					le.DueDate,
 					nil,
+					nil,
+					nil,
+					nil,
+					nil,
+					le.CreatedAt,
+					le.Version,
+					le.UpdatedAt,
+					nil,
+					nil,
+				),
+			want: []*LoanEntity{
+				{
+					ID:      le.ID,
+					Status:  le.Status,
+					UserID:  le.UserID,
+					DueDate: le.DueDate,
+					Amount:  decimal.NewFromFloat(float64(0)),
+					Breakdown: Breakdown{
+						Principal: decimal.NewFromFloat(float64(0)),
+						Interest:  decimal.NewFromFloat(float64(0)),
+						Fee:       decimal.NewFromFloat(float64(0)),
+						Penalty:   decimal.NewFromFloat(float64(0)),
+					},
+					CreatedAt: le.CreatedAt,
+					Version:   le.Version,
+					UpdatedAt: le.UpdatedAt,
+				},
+			},
+		},
+		{
+			name: "given the installment recorded without the breakdown," +
+				"when findLoan," +
+				"then the whole amount is principal",
+			args: args{
+				loanEntity: &LoanEntity{
+					UserID:   "customer01",
+					Statuses: []LoanStatus{"PENDING"},
+				},
+			},
+			sqlRows: sqlmock.NewRows(
+				[]string{
+					"id",
+					"status",
+					"user_id",
+					"due_date",
+					"amount",
+					"principal",
+					"interest",
+					"fee",
+					"penalty",
+					"created_at",
+					"version",
+					"updated_at",
+					"restructure_id",
+					"product_id",
+				}).
+				AddRow(
+					le.ID,
+					le.Status,
+					le.UserID,
+					le.DueDate,
+					le.Amount,
+					0,
+					0,
+					0,
+					0,
 					le.CreatedAt,
 					le.Version,
 					le.UpdatedAt,
+					nil,
+					nil,
 				),
 			want: []*LoanEntity{
 				{
-					ID:        le.ID,
-					Status:    le.Status,
-					UserID:    le.UserID,
-					DueDate:   le.DueDate,
-					Amount:    decimal.NewFromFloat(float64(0)),
+					ID:      le.ID,
+					Status:  le.Status,
+					UserID:  le.UserID,
+					DueDate: le.DueDate,
+					Amount:  le.Amount,
+					Breakdown: Breakdown{
+						Principal: le.Amount,
+						Interest:  decimal.NewFromFloat(float64(0)),
+						Fee:       decimal.NewFromFloat(float64(0)),
+						Penalty:   decimal.NewFromFloat(float64(0)),
+					},
 					CreatedAt: le.CreatedAt,
 					Version:   le.Version,
 					UpdatedAt: le.UpdatedAt,
@@ -288,7 +403,7 @@ func Test_loanRepository_FindLoan(t *testing.T) {
 					UserID:   "customer012",
 					DueDate:  dateRandom,
 					Amount:   decimal.NewFromFloat(float64(110000)),
-					Statuses: []string{"PENDING", "CLOSED"},
+					Statuses: []LoanStatus{"PENDING", "CLOSED"},
 				},
 			},
 			sqlRows: sqlmock.NewRows(
@@ -298,11 +413,21 @@ func Test_loanRepository_FindLoan(t *testing.T) {
 					"user_id",
 					"due_date",
 					"amount",
+					"principal",
+					"interest",
+					"fee",
+					"penalty",
 					"created_at",
 					"version",
 					"updated_at",
+					"restructure_id",
+					"product_id",
 				}).
 				AddRow(
+					nil,
+					nil,
+					nil,
+					nil,
 					nil,
 					nil,
 					nil,
@@ -311,6 +436,8 @@ func Test_loanRepository_FindLoan(t *testing.T) {
 					le.CreatedAt,
 					le.Version,
 					le.UpdatedAt,
+					nil,
+					nil,
 				),
 			want:    nil,
 			wantErr: true,
@@ -361,49 +488,101 @@ func Test_loanRepository_FindLoan(t *testing.T) {
 func Test_loanRepository_UpdateLoan(t *testing.T) {
 	le := LoanEntityUpdate{
 		IDs:    []uint64{10, 20},
-		Status: "PAID",
+		Status: LoanPaid,
+		Reason: "payment abc",
 	}
 
-	type args struct {
-		loanEntity *LoanEntityUpdate
+	conditional := LoanEntityUpdate{
+		IDs:        []uint64{10, 20},
+		Status:     LoanOverdue,
+		FromStatus: LoanPending,
 	}
+
+	rows := func(statuses ...LoanStatus) *sqlmock.Rows {
+		r := sqlmock.NewRows([]string{"id", "status"})
+		for idx, status := range statuses {
+			r.AddRow(uint64(idx+1)*10, status)
+		}
+
+		return r
+	}
+
 	tests := []struct {
-		name      string
-		args      args
-		sqlErr    error
-		sqlResult driver.Result
-		want      *LoanEntityUpdate
-		wantErr   bool
+		name       string
+		loanEntity *LoanEntityUpdate
+		lockRows   *sqlmock.Rows
+		sqlErr     error
+		sqlResult  driver.Result
+		historyErr error
+		wantErr    error
 	}{
 		{
-			name: "given happy case," +
+			name: "given the transitions are allowed," +
 				"when updateLoan," +
-				"then return nil",
-			args: args{
-				loanEntity: &le,
-			},
-			sqlResult: sqlmock.NewResult(1, 1),
-			want:      &le,
+				"then update and record the history",
+			loanEntity: &le,
+			lockRows:   rows(LoanPending, LoanOverdue),
+			sqlResult:  sqlmock.NewResult(0, 2),
+		},
+		{
+			name: "given one of the installments is already paid," +
+				"when updateLoan," +
+				"then refuse the illegal transition",
+			loanEntity: &le,
+			lockRows:   rows(LoanPending, LoanPaid),
+			wantErr:    ErrorIllegalTransition,
+		},
+		{
+			name: "given one of the installments is not exists," +
+				"when updateLoan," +
+				"then return error no rows",
+			loanEntity: &le,
+			lockRows:   rows(LoanPending),
+			wantErr:    ErrorNoRows,
+		},
+		{
+			name: "given one of the installments is no longer in from status," +
+				"when updateLoan," +
+				"then return error no rows",
+			loanEntity: &conditional,
+			lockRows:   rows(LoanPending, LoanPaid),
+			wantErr:    ErrorNoRows,
+		},
+		{
+			name: "given all the installments are still in from status," +
+				"when updateLoan," +
+				"then update and record the history",
+			loanEntity: &conditional,
+			lockRows:   rows(LoanPending, LoanPending),
+			sqlResult:  sqlmock.NewResult(0, 2),
 		},
 		{
 			name: "given negative case because RowsAffected," +
 				"when updateLoan," +
 				"then return error",
-			args: args{
-				loanEntity: &le,
-			},
-			sqlResult: sqlmock.NewErrorResult(sql.ErrConnDone),
-			wantErr:   true,
+			loanEntity: &le,
+			lockRows:   rows(LoanPending, LoanPending),
+			sqlResult:  sqlmock.NewErrorResult(sql.ErrConnDone),
+			wantErr:    ErrorFromDBLoan,
 		},
 		{
 			name: "given negative case because execContext," +
 				"when updateLoan," +
 				"then return error",
-			args: args{
-				loanEntity: &le,
-			},
-			sqlErr:  sql.ErrTxDone,
-			wantErr: true,
+			loanEntity: &le,
+			lockRows:   rows(LoanPending, LoanPending),
+			sqlErr:     sql.ErrTxDone,
+			wantErr:    ErrorFromDBLoan,
+		},
+		{
+			name: "given negative case because insert history," +
+				"when updateLoan," +
+				"then return error",
+			loanEntity: &le,
+			lockRows:   rows(LoanPending, LoanPending),
+			sqlResult:  sqlmock.NewResult(0, 2),
+			historyErr: sql.ErrTxDone,
+			wantErr:    ErrorFromDBLoan,
 		},
 	}
 	for _, tt := range tests {
@@ -415,44 +594,136 @@ func Test_loanRepository_UpdateLoan(t *testing.T) {
 				}
 				defer db.Close()
 
-				defer func() {
-					if err := mock.ExpectationsWereMet(); err != nil {
-						assert.Fail(t, "there were unfulfilled expectations", err.Error())
-					}
-				}()
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(regexp.QuoteMeta(querySelectForUpdate+"(?,?) FOR UPDATE")).
+					WithArgs(uint64(10), uint64(20)).
+					WillReturnRows(tt.lockRows)
 
-				querySet, _ := builderUpdate(tt.args.loanEntity)
-				queryFull := queryUpdate + querySet + queryUpdateWhere + "(" + buildWhereIn(len(tt.args.loanEntity.IDs)) + ")"
+				querySet, _ := builderUpdate(tt.loanEntity)
+				queryFull := queryUpdate + querySet + queryUpdateWhere + "(" + buildWhereIn(len(tt.loanEntity.IDs)) + ")"
 
 				if tt.sqlErr != nil {
 					mock.ExpectExec(regexp.QuoteMeta(queryFull)).
-						WithArgs(
-							"PAID",
-							uint64(10),
-							uint64(20),
-						).
+						WithArgs(string(tt.loanEntity.Status), uint64(10), uint64(20)).
 						WillReturnError(tt.sqlErr)
 				}
 
 				if tt.sqlResult != nil {
 					mock.ExpectExec(regexp.QuoteMeta(queryFull)).
-						WithArgs(
-							"PAID",
-							uint64(10),
-							uint64(20),
-						).
+						WithArgs(string(tt.loanEntity.Status), uint64(10), uint64(20)).
 						WillReturnResult(tt.sqlResult)
 				}
 
+				if tt.sqlResult != nil && tt.wantErr == nil {
+					prepare := mock.ExpectPrepare(regexp.QuoteMeta(queryInsertStatusHistory))
+					prepare.ExpectExec().
+						WithArgs(uint64(10), sqlmock.AnyArg(), string(tt.loanEntity.Status), tt.loanEntity.Reason).
+						WillReturnResult(sqlmock.NewResult(1, 1))
+					prepare.ExpectExec().
+						WithArgs(uint64(20), sqlmock.AnyArg(), string(tt.loanEntity.Status), tt.loanEntity.Reason).
+						WillReturnResult(sqlmock.NewResult(2, 1))
+				}
+
+				if tt.historyErr != nil {
+					mock.ExpectPrepare(regexp.QuoteMeta(queryInsertStatusHistory)).
+						ExpectExec().
+						WillReturnError(tt.historyErr)
+				}
+
 				store := NewLoanRepository(db)
-				err = store.UpdateLoan(context.Background(), tt.args.loanEntity)
+				tx, _ := db.Begin()
+				err = store.UpdateLoan(context.Background(), tx, tt.loanEntity)
 
-				if (err != nil) != tt.wantErr {
-					t.Errorf(
-						"LoanRepositoryImpl.UpdateLoan() error = %v, wantErr %v", err,
-						tt.wantErr)
-					return
+				assert.Equal(t, tt.wantErr, err)
+				assert.NoError(t, mock.ExpectationsWereMet())
+			})
+	}
+}
+
+func Test_loanRepository_AccruePenalty(t *testing.T) {
+	accrual := &LoanPenaltyAccrual{
+		ID:        uint64(10),
+		Amount:    decimal.NewFromFloat(1200),
+		AccruedOn: time.Date(2026, 10, 17, 23, 59, 59, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name      string
+		sqlErr    error
+		sqlResult driver.Result
+		wantErr   error
+	}{
+		{
+			name: "given the overdue installment not yet accrued for the date," +
+				"when accruePenalty," +
+				"then the penalty is added",
+			sqlResult: sqlmock.NewResult(0, 1),
+		},
+		{
+			name: "given the penalty of the date has been accrued," +
+				"when accruePenalty," +
+				"then return error no rows",
+			sqlResult: sqlmock.NewResult(0, 0),
+			wantErr:   ErrorNoRows,
+		},
+		{
+			name: "given negative case sql tx done," +
+				"when accruePenalty," +
+				"then return error",
+			sqlErr:  sql.ErrTxDone,
+			wantErr: ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error LoanRepositoryImpl.AccruePenalty() error = %v", err)
+				}
+				defer db.Close()
+
+				mock.ExpectBegin()
+				expect := mock.ExpectExec(regexp.QuoteMeta(queryUpdatePenalty)).
+					WithArgs(accrual.Amount, accrual.Amount, "2026-10-17", uint64(10), "2026-10-17")
+
+				if tt.sqlErr != nil {
+					expect.WillReturnError(tt.sqlErr)
+				} else {
+					expect.WillReturnResult(tt.sqlResult)
 				}
+
+				tx, _ := db.Begin()
+				err = NewLoanRepository(db).AccruePenalty(context.Background(), tx, accrual)
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.NoError(t, mock.ExpectationsWereMet())
+			})
+	}
+}
+
+func Test_LoanStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from LoanStatus
+		to   LoanStatus
+		want bool
+	}{
+		{from: LoanPending, to: LoanPaid, want: true},
+		{from: LoanPending, to: LoanOverdue, want: true},
+		{from: LoanOverdue, to: LoanPaid, want: true},
+		{from: LoanPaid, to: LoanReversed, want: true},
+		{from: LoanReversed, to: LoanPending, want: true},
+		{from: LoanOverdue, to: LoanPending, want: false},
+		{from: LoanPaid, to: LoanPending, want: false},
+		{from: LoanPaid, to: LoanPaid, want: false},
+		{from: LoanClosed, to: LoanPending, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(
+			string(tt.from)+" to "+string(tt.to), func(t *testing.T) {
+				assert.Equal(t, tt.want, tt.from.CanTransitionTo(tt.to))
 			})
 	}
 }
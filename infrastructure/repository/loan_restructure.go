@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	RestructureWeekly   = "WEEKLY"
+	RestructureBiweekly = "BIWEEKLY"
+	RestructureMonthly  = "MONTHLY"
+)
+
+type (
+	// LoanRestructureEntity is the audit of the restructuring, the new installments are linked through restructure_id.
+	LoanRestructureEntity struct {
+		ID              uint64          `db:"id" json:"id,omitempty"`
+		RestructureID   string          `db:"restructure_id" json:"restructure_id,omitempty"`
+		UserID          string          `db:"user_id" json:"user_id,omitempty"`
+		InstallmentIDs  []uint64        `db:"installment_ids" json:"installment_ids,omitempty"`
+		Outstanding     decimal.Decimal `db:"outstanding" json:"outstanding,omitempty"`
+		Tenor           int             `db:"tenor" json:"tenor,omitempty"`
+		Frequency       string          `db:"frequency" json:"frequency,omitempty"`
+		GracePeriodDays int             `db:"grace_period_days" json:"grace_period_days,omitempty"`
+		Reason          string          `db:"reason" json:"reason,omitempty"`
+		RequestedBy     string          `db:"requested_by" json:"requested_by,omitempty"`
+		CreatedAt       time.Time       `db:"created_at" json:"created_at,omitempty"`
+		Version         int             `db:"version" json:"version,omitempty"`
+		UpdatedAt       time.Time       `db:"updated_at" json:"updated_at,omitempty"`
+	}
+
+	LoanRestructureRepository interface {
+		// SaveRestructure returns ErrorDuplicate when the restructure id has been saved.
+		SaveRestructure(ctx context.Context, tx *sql.Tx, restructure *LoanRestructureEntity) error
+	}
+)
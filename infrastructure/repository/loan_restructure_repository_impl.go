@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strconv"
+	"strings"
+)
+
+const (
+	queryInsertLoanRestructure = `
+		INSERT IGNORE INTO loan_restructure (restructure_id, user_id, installment_ids, outstanding, tenor, frequency, grace_period_days, reason, requested_by, created_at, version, updated_at) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+)
+
+type loanRestructureRepository struct {
+	connectionDB *sql.DB
+}
+
+func NewLoanRestructureRepository(connectionDB *sql.DB) LoanRestructureRepository {
+	return &loanRestructureRepository{
+		connectionDB: connectionDB,
+	}
+}
+
+func (l *loanRestructureRepository) SaveRestructure(
+	ctx context.Context,
+	tx *sql.Tx,
+	restructure *LoanRestructureEntity) error {
+	var installmentIDs []string
+	for _, id := range restructure.InstallmentIDs {
+		installmentIDs = append(installmentIDs, strconv.FormatUint(id, 10))
+	}
+
+	result, err := tx.ExecContext(
+		ctx, queryInsertLoanRestructure,
+		restructure.RestructureID,
+		restructure.UserID,
+		strings.Join(installmentIDs, ","),
+		restructure.Outstanding,
+		restructure.Tenor,
+		restructure.Frequency,
+		restructure.GracePeriodDays,
+		restructure.Reason,
+		restructure.RequestedBy,
+		restructure.CreatedAt,
+		restructure.Version,
+		restructure.UpdatedAt,
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Println("unidentified error from database when rowsAffected -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	if affected == 0 {
+		return ErrorDuplicate
+	}
+
+	return nil
+}
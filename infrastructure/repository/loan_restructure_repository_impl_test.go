@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_loanRestructureRepository_SaveRestructure(t *testing.T) {
+	dateRandom := time.Date(2024, 6, 23, 21, 0, 0, 0, time.UTC)
+
+	restructure := &LoanRestructureEntity{
+		RestructureID:   "rs-1",
+		UserID:          "abc",
+		InstallmentIDs:  []uint64{6, 7, 8},
+		Outstanding:     decimal.NewFromFloat(264000),
+		Tenor:           12,
+		Frequency:       RestructureMonthly,
+		GracePeriodDays: 30,
+		Reason:          "hardship",
+		RequestedBy:     "ops-1",
+		CreatedAt:       dateRandom,
+		UpdatedAt:       dateRandom,
+	}
+
+	tests := []struct {
+		name      string
+		sqlErr    error
+		sqlResult driver.Result
+		wantErr   error
+	}{
+		{
+			name: "given happy case," +
+				"when saveRestructure," +
+				"then return nil",
+			sqlResult: sqlmock.NewResult(1, 1),
+		},
+		{
+			name: "given the restructure has been saved," +
+				"when saveRestructure," +
+				"then return error duplicate",
+			sqlResult: sqlmock.NewResult(0, 0),
+			wantErr:   ErrorDuplicate,
+		},
+		{
+			name: "given negative case sql tx done," +
+				"when saveRestructure," +
+				"then return error",
+			sqlErr:  sql.ErrTxDone,
+			wantErr: ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error LoanRestructureRepositoryImpl.SaveRestructure() error = %v", err)
+				}
+				defer db.Close()
+
+				mock.ExpectBegin()
+
+				expect := mock.ExpectExec(regexp.QuoteMeta(queryInsertLoanRestructure)).
+					WithArgs(
+						"rs-1", "abc", "6,7,8", restructure.Outstanding, 12, RestructureMonthly, 30, "hardship",
+						"ops-1", dateRandom, 0, dateRandom)
+
+				if tt.sqlErr != nil {
+					expect.WillReturnError(tt.sqlErr)
+				} else {
+					expect.WillReturnResult(tt.sqlResult)
+				}
+
+				store := NewLoanRestructureRepository(db)
+				tx, _ := db.Begin()
+				err = store.SaveRestructure(context.Background(), tx, restructure)
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.NoError(t, mock.ExpectationsWereMet())
+			})
+	}
+}
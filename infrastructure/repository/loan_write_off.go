@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type (
+	// LoanWriteOffEntity is the audit of the write-off, the written-off installments are kept for the recovery.
+	LoanWriteOffEntity struct {
+		ID             uint64          `db:"id" json:"id,omitempty"`
+		WriteOffID     string          `db:"write_off_id" json:"write_off_id,omitempty"`
+		UserID         string          `db:"user_id" json:"user_id,omitempty"`
+		InstallmentIDs []uint64        `db:"installment_ids" json:"installment_ids,omitempty"`
+		Amount         decimal.Decimal `db:"amount" json:"amount,omitempty"`
+		DaysPastDue    int             `db:"days_past_due" json:"days_past_due,omitempty"`
+		Reason         string          `db:"reason" json:"reason,omitempty"`
+		ApprovedBy     string          `db:"approved_by" json:"approved_by,omitempty"`
+		CreatedAt      time.Time       `db:"created_at" json:"created_at,omitempty"`
+		Version        int             `db:"version" json:"version,omitempty"`
+		UpdatedAt      time.Time       `db:"updated_at" json:"updated_at,omitempty"`
+	}
+
+	LoanWriteOffRepository interface {
+		// SaveWriteOff returns ErrorDuplicate when the write-off id has been saved.
+		SaveWriteOff(ctx context.Context, tx *sql.Tx, writeOff *LoanWriteOffEntity) error
+	}
+)
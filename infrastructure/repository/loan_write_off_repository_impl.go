@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strconv"
+	"strings"
+)
+
+const (
+	queryInsertLoanWriteOff = `
+		INSERT IGNORE INTO loan_write_off (write_off_id, user_id, installment_ids, amount, days_past_due, reason, approved_by, created_at, version, updated_at) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+)
+
+type loanWriteOffRepository struct {
+	connectionDB *sql.DB
+}
+
+func NewLoanWriteOffRepository(connectionDB *sql.DB) LoanWriteOffRepository {
+	return &loanWriteOffRepository{
+		connectionDB: connectionDB,
+	}
+}
+
+func (l *loanWriteOffRepository) SaveWriteOff(
+	ctx context.Context,
+	tx *sql.Tx,
+	writeOff *LoanWriteOffEntity) error {
+	var installmentIDs []string
+	for _, id := range writeOff.InstallmentIDs {
+		installmentIDs = append(installmentIDs, strconv.FormatUint(id, 10))
+	}
+
+	result, err := tx.ExecContext(
+		ctx, queryInsertLoanWriteOff,
+		writeOff.WriteOffID,
+		writeOff.UserID,
+		strings.Join(installmentIDs, ","),
+		writeOff.Amount,
+		writeOff.DaysPastDue,
+		writeOff.Reason,
+		writeOff.ApprovedBy,
+		writeOff.CreatedAt,
+		writeOff.Version,
+		writeOff.UpdatedAt,
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Println("unidentified error from database when rowsAffected -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	if affected == 0 {
+		return ErrorDuplicate
+	}
+
+	return nil
+}
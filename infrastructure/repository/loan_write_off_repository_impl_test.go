@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_loanWriteOffRepository_SaveWriteOff(t *testing.T) {
+	dateRandom := time.Date(2024, 6, 23, 21, 0, 0, 0, time.UTC)
+
+	writeOff := &LoanWriteOffEntity{
+		WriteOffID:     "wo-1",
+		UserID:         "abc",
+		InstallmentIDs: []uint64{6, 7},
+		Amount:         decimal.NewFromFloat(176000),
+		DaysPastDue:    95,
+		Reason:         "uncollectible",
+		ApprovedBy:     "risk-1",
+		CreatedAt:      dateRandom,
+		UpdatedAt:      dateRandom,
+	}
+
+	tests := []struct {
+		name      string
+		sqlErr    error
+		sqlResult driver.Result
+		wantErr   error
+	}{
+		{
+			name: "given happy case," +
+				"when saveWriteOff," +
+				"then return nil",
+			sqlResult: sqlmock.NewResult(1, 1),
+		},
+		{
+			name: "given the write-off has been saved," +
+				"when saveWriteOff," +
+				"then return error duplicate",
+			sqlResult: sqlmock.NewResult(0, 0),
+			wantErr:   ErrorDuplicate,
+		},
+		{
+			name: "given negative case sql tx done," +
+				"when saveWriteOff," +
+				"then return error",
+			sqlErr:  sql.ErrTxDone,
+			wantErr: ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error LoanWriteOffRepositoryImpl.SaveWriteOff() error = %v", err)
+				}
+				defer db.Close()
+
+				mock.ExpectBegin()
+
+				expect := mock.ExpectExec(regexp.QuoteMeta(queryInsertLoanWriteOff)).
+					WithArgs("wo-1", "abc", "6,7", writeOff.Amount, 95, "uncollectible", "risk-1", dateRandom, 0, dateRandom)
+
+				if tt.sqlErr != nil {
+					expect.WillReturnError(tt.sqlErr)
+				} else {
+					expect.WillReturnResult(tt.sqlResult)
+				}
+
+				store := NewLoanWriteOffRepository(db)
+				tx, _ := db.Begin()
+				err = store.SaveWriteOff(context.Background(), tx, writeOff)
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.NoError(t, mock.ExpectationsWereMet())
+			})
+	}
+}
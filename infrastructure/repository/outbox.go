@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	OutboxStatusPending   = "PENDING"
+	OutboxStatusPublished = "PUBLISHED"
+	OutboxStatusFailed    = "FAILED"
+)
+
+type (
+	OutboxEntity struct {
+		ID          uint64     `db:"id" json:"id,omitempty"`
+		EventID     string     `db:"event_id" json:"event_id,omitempty"`
+		EventType   string     `db:"event_type" json:"event_type,omitempty"`
+		AggregateID string     `db:"aggregate_id" json:"aggregate_id,omitempty"`
+		Payload     []byte     `db:"payload" json:"payload,omitempty"`
+		Status      string     `db:"status" json:"status,omitempty"`
+		Attempts    int        `db:"attempts" json:"attempts,omitempty"`
+		LastError   string     `db:"last_error" json:"last_error,omitempty"`
+		OccurredAt  time.Time  `db:"occurred_at" json:"occurred_at,omitempty"`
+		PublishedAt *time.Time `db:"published_at" json:"published_at,omitempty"`
+		CreatedAt   time.Time  `db:"created_at" json:"created_at,omitempty"`
+		Version     int        `db:"version" json:"version,omitempty"`
+		UpdatedAt   time.Time  `db:"updated_at" json:"updated_at,omitempty"`
+	}
+
+	OutboxEntityFilter struct {
+		Status      string `json:"status,omitempty"`
+		MaxAttempts int    `json:"max_attempts,omitempty"`
+		Limit       int    `json:"limit,omitempty"`
+	}
+
+	OutboxEntityUpdate struct {
+		ID          uint64     `db:"id" json:"id,omitempty"`
+		Status      string     `db:"status" json:"status,omitempty"`
+		LastError   string     `db:"last_error" json:"last_error,omitempty"`
+		PublishedAt *time.Time `db:"published_at" json:"published_at,omitempty"`
+	}
+
+	OutboxRepository interface {
+		SaveOutbox(ctx context.Context, tx *sql.Tx, outboxEntity ...*OutboxEntity) error
+
+		FindOutbox(ctx context.Context, filter *OutboxEntityFilter) ([]*OutboxEntity, error)
+
+		UpdateOutbox(ctx context.Context, outboxEntity *OutboxEntityUpdate) error
+	}
+)
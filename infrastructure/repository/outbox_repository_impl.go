@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+)
+
+const (
+	queryInsertOutbox = `
+		INSERT INTO outbox (event_id, event_type, aggregate_id, payload, status, attempts, occurred_at, created_at, version, updated_at) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	querySelectOutbox = `
+		SELECT id, event_id, event_type, aggregate_id, payload, status, attempts, occurred_at, created_at, version 
+		FROM outbox WHERE status = ? AND attempts < ? 
+		ORDER BY id ASC LIMIT ?
+	`
+
+	queryUpdateOutbox = `
+		UPDATE outbox SET
+			status = ?,
+			last_error = ?,
+			published_at = ?,
+			attempts = attempts + 1,
+			version = version + 1,
+			updated_at = now()
+		WHERE id = ?
+	`
+)
+
+type outboxRepository struct {
+	connectionDB *sql.DB
+}
+
+func NewOutboxRepository(connectionDB *sql.DB) OutboxRepository {
+	return &outboxRepository{
+		connectionDB: connectionDB,
+	}
+}
+
+func (o *outboxRepository) SaveOutbox(
+	ctx context.Context,
+	db *sql.Tx,
+	outboxEntity ...*OutboxEntity) error {
+	statement, err := db.PrepareContext(ctx, queryInsertOutbox)
+	if err != nil {
+		log.Println("unidentified error from database when prepare -> ", err)
+		return ErrorFromDBLoan
+	}
+	defer statement.Close()
+
+	for _, entry := range outboxEntity {
+		_, errExecContext := statement.ExecContext(
+			ctx,
+			entry.EventID,
+			entry.EventType,
+			entry.AggregateID,
+			entry.Payload,
+			entry.Status,
+			entry.Attempts,
+			entry.OccurredAt,
+			entry.CreatedAt,
+			entry.Version,
+			entry.UpdatedAt,
+		)
+
+		if errExecContext != nil {
+			log.Println("unidentified error from database when exec -> ", errExecContext)
+			return errExecContext
+		}
+	}
+
+	return nil
+}
+
+func (o *outboxRepository) FindOutbox(
+	ctx context.Context,
+	filter *OutboxEntityFilter) ([]*OutboxEntity, error) {
+	res, err := o.connectionDB.QueryContext(
+		ctx, querySelectOutbox,
+		filter.Status, filter.MaxAttempts, filter.Limit)
+
+	if err != nil {
+		log.Println("unidentified error from database when query context -> ", err)
+		return nil, ErrorFromDBLoan
+	}
+	defer res.Close()
+
+	var data []*OutboxEntity
+	for res.Next() {
+		var r OutboxEntity
+		var occurredAt, createdAt string
+
+		errScan := res.Scan(
+			&r.ID, &r.EventID,
+			&r.EventType, &r.AggregateID,
+			&r.Payload, &r.Status,
+			&r.Attempts, &occurredAt,
+			&createdAt, &r.Version,
+		)
+
+		if errScan != nil {
+			log.Println("unidentified error from database when scan -> ", errScan)
+			return nil, ErrorFromDBLoan
+		}
+
+		r.OccurredAt = parseDateTime(occurredAt)
+		r.CreatedAt = parseDateTime(createdAt)
+
+		data = append(data, &r)
+	}
+
+	return data, nil
+}
+
+func (o *outboxRepository) UpdateOutbox(
+	ctx context.Context,
+	outboxEntity *OutboxEntityUpdate) error {
+	lastError := sql.NullString{
+		String: outboxEntity.LastError,
+		Valid:  outboxEntity.LastError != "",
+	}
+
+	_, err := o.connectionDB.ExecContext(
+		ctx, queryUpdateOutbox,
+		outboxEntity.Status,
+		lastError,
+		outboxEntity.PublishedAt,
+		outboxEntity.ID,
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_outboxRepository_SaveOutbox(t *testing.T) {
+	dateRandom := time.Date(2024, 6, 23, 21, 0, 0, 0, time.UTC)
+	oe := &OutboxEntity{
+		EventID:     "event-1",
+		EventType:   "PaymentSucceeded",
+		AggregateID: "CUSTOMER01",
+		Payload:     []byte(`{"user_id":"CUSTOMER01"}`),
+		Status:      OutboxStatusPending,
+		OccurredAt:  dateRandom,
+		CreatedAt:   dateRandom,
+		UpdatedAt:   dateRandom,
+	}
+
+	tests := []struct {
+		name    string
+		sqlErr  error
+		wantErr bool
+	}{
+		{
+			name: "given the happy case," +
+				"when saveOutbox," +
+				"then return nil",
+		},
+		{
+			name: "given the negative case because exec context," +
+				"when saveOutbox," +
+				"then return error",
+			sqlErr:  sql.ErrTxDone,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error OutboxRepositoryImpl.SaveOutbox() error = %v", err)
+				}
+				defer db.Close()
+
+				defer func() {
+					if err := mock.ExpectationsWereMet(); err != nil {
+						assert.Fail(t, "there were unfulfilled expectations", err.Error())
+					}
+				}()
+
+				mock.ExpectBegin()
+				exec := mock.
+					ExpectPrepare(regexp.QuoteMeta(queryInsertOutbox)).
+					ExpectExec().
+					WithArgs(
+						oe.EventID, oe.EventType, oe.AggregateID, oe.Payload, oe.Status,
+						0, dateRandom, dateRandom, 0, dateRandom,
+					)
+
+				if tt.sqlErr != nil {
+					exec.WillReturnError(tt.sqlErr)
+				} else {
+					exec.WillReturnResult(sqlmock.NewResult(1, 1))
+				}
+
+				o := NewOutboxRepository(db)
+				tx, _ := db.Begin()
+
+				err = o.SaveOutbox(context.Background(), tx, oe)
+				assert.Equal(t, tt.wantErr, err != nil)
+			})
+	}
+}
+
+func Test_outboxRepository_FindOutbox(t *testing.T) {
+	dateRandom := time.Date(2024, 6, 23, 21, 0, 0, 0, time.UTC)
+	filter := &OutboxEntityFilter{
+		Status:      OutboxStatusPending,
+		MaxAttempts: 10,
+		Limit:       100,
+	}
+
+	columns := []string{
+		"id", "event_id", "event_type", "aggregate_id", "payload",
+		"status", "attempts", "occurred_at", "created_at", "version",
+	}
+
+	tests := []struct {
+		name    string
+		sqlErr  error
+		sqlRows *sqlmock.Rows
+		want    []*OutboxEntity
+		wantErr bool
+	}{
+		{
+			name: "given happy case," +
+				"when findOutbox," +
+				"then return the result from db",
+			sqlRows: sqlmock.NewRows(columns).
+				AddRow(1, "event-1", "LoanClosed", "CUSTOMER01", []byte(`{}`), "PENDING", 0, dateRandom, dateRandom, 0),
+			want: []*OutboxEntity{
+				{
+					ID:          1,
+					EventID:     "event-1",
+					EventType:   "LoanClosed",
+					AggregateID: "CUSTOMER01",
+					Payload:     []byte(`{}`),
+					Status:      "PENDING",
+					OccurredAt:  dateRandom,
+					CreatedAt:   dateRandom,
+				},
+			},
+		},
+		{
+			name: "given negative case sql tx done," +
+				"when findOutbox," +
+				"then return error",
+			sqlErr:  sql.ErrTxDone,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error OutboxRepositoryImpl.FindOutbox() error = %v", err)
+				}
+				defer db.Close()
+
+				query := mock.ExpectQuery(regexp.QuoteMeta(querySelectOutbox)).
+					WithArgs(filter.Status, filter.MaxAttempts, filter.Limit)
+
+				if tt.sqlErr != nil {
+					query.WillReturnError(tt.sqlErr)
+				} else {
+					query.WillReturnRows(tt.sqlRows)
+				}
+
+				o := NewOutboxRepository(db)
+				got, err := o.FindOutbox(context.Background(), filter)
+
+				assert.Equal(t, tt.wantErr, err != nil)
+				assert.Equal(t, tt.want, got)
+				assert.Nil(t, mock.ExpectationsWereMet())
+			})
+	}
+}
+
+func Test_outboxRepository_UpdateOutbox(t *testing.T) {
+	dateRandom := time.Date(2024, 6, 23, 21, 0, 0, 0, time.UTC)
+	update := &OutboxEntityUpdate{
+		ID:          1,
+		Status:      OutboxStatusPublished,
+		PublishedAt: &dateRandom,
+	}
+
+	tests := []struct {
+		name    string
+		sqlErr  error
+		wantErr bool
+	}{
+		{
+			name: "given happy case," +
+				"when updateOutbox," +
+				"then return nil",
+		},
+		{
+			name: "given negative case because execContext," +
+				"when updateOutbox," +
+				"then return error",
+			sqlErr:  sql.ErrTxDone,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error OutboxRepositoryImpl.UpdateOutbox() error = %v", err)
+				}
+				defer db.Close()
+
+				exec := mock.ExpectExec(regexp.QuoteMeta(queryUpdateOutbox)).
+					WithArgs(OutboxStatusPublished, sql.NullString{}, &dateRandom, uint64(1))
+
+				if tt.sqlErr != nil {
+					exec.WillReturnError(tt.sqlErr)
+				} else {
+					exec.WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+
+				o := NewOutboxRepository(db)
+				err = o.UpdateOutbox(context.Background(), update)
+
+				assert.Equal(t, tt.wantErr, err != nil)
+				assert.Nil(t, mock.ExpectationsWereMet())
+			})
+	}
+}
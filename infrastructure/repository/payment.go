@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	PaymentPendingDebit = "PENDING_DEBIT"
+	PaymentPaid         = "PAID"
+	PaymentFailed       = "FAILED"
+	// PaymentReversed is the paid payment undone by the admin, e.g. bounced transfer or duplicate debit.
+	PaymentReversed = "REVERSED"
+	// PaymentUnapplied is the money received for the installments which are no longer collectible (e.g. restructured
+	// meanwhile), none of them is paid by it and the whole amount is held in suspense until it is reversed (refunded).
+	PaymentUnapplied = "UNAPPLIED"
+
+	PaymentChannelDirectDebit = "DIRECT_DEBIT"
+	PaymentChannelQris        = "QRIS"
+	// PaymentChannelVirtualAccount is the transfer already received by the bank, it is never charged.
+	PaymentChannelVirtualAccount = "VIRTUAL_ACCOUNT"
+)
+
+type (
+	PaymentEntity struct {
+		ID                uint64          `db:"id" json:"id,omitempty"`
+		PaymentID         string          `db:"payment_id" json:"payment_id,omitempty"`
+		UserID            string          `db:"user_id" json:"user_id,omitempty"`
+		Amount            decimal.Decimal `db:"amount" json:"amount,omitempty"`
+		Channel           string          `db:"channel" json:"channel,omitempty"`
+		PaymentCode       string          `db:"payment_code" json:"payment_code,omitempty"`
+		ExpiresAt         *time.Time      `db:"expires_at" json:"expires_at,omitempty"`
+		InstallmentIDs    []uint64        `db:"installment_ids" json:"installment_ids,omitempty"`
+		Status            string          `db:"status" json:"status,omitempty"`
+		Provider          string          `db:"provider" json:"provider,omitempty"`
+		ProviderReference string          `db:"provider_reference" json:"provider_reference,omitempty"`
+		FailureReason     string          `db:"failure_reason" json:"failure_reason,omitempty"`
+		// Recovery is the payment of the written-off installments.
+		Recovery bool `db:"recovery" json:"recovery,omitempty"`
+		// Allocation is the amount split into the components of the installments, in the configured order.
+		Allocation Breakdown `json:"allocation"`
+		CreatedAt  time.Time `db:"created_at" json:"created_at,omitempty"`
+		Version    int       `db:"version" json:"version,omitempty"`
+		UpdatedAt  time.Time `db:"updated_at" json:"updated_at,omitempty"`
+	}
+
+	PaymentFilter struct {
+		PaymentID         string   `json:"payment_id,omitempty"`
+		UserID            string   `json:"user_id,omitempty"`
+		Provider          string   `json:"provider,omitempty"`
+		ProviderReference string   `json:"provider_reference,omitempty"`
+		Statuses          []string `json:"statuses,omitempty"`
+	}
+
+	PaymentEntityUpdate struct {
+		PaymentID         string `db:"payment_id" json:"payment_id,omitempty"`
+		FromStatus        string `json:"from_status,omitempty"`
+		Status            string `db:"status" json:"status,omitempty"`
+		ProviderReference string `db:"provider_reference" json:"provider_reference,omitempty"`
+		FailureReason     string `db:"failure_reason" json:"failure_reason,omitempty"`
+	}
+
+	PaymentRepository interface {
+		SavePayment(ctx context.Context, tx *sql.Tx, payment *PaymentEntity) error
+
+		FindPayments(ctx context.Context, filter *PaymentFilter) ([]*PaymentEntity, error)
+
+		// UpdatePayment updates the payment only when the current status is FromStatus,
+		// it returns ErrorNoRows when the payment has been moved by another process (e.g. callback).
+		UpdatePayment(ctx context.Context, tx *sql.Tx, payment *PaymentEntityUpdate) error
+	}
+)
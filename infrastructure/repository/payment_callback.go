@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	PaymentCallbackQueued    = "QUEUED"
+	PaymentCallbackProcessed = "PROCESSED"
+	PaymentCallbackFailed    = "FAILED"
+)
+
+type (
+	PaymentCallbackEntity struct {
+		ID                uint64          `db:"id" json:"id,omitempty"`
+		Provider          string          `db:"provider" json:"provider,omitempty"`
+		ProviderReference string          `db:"provider_reference" json:"provider_reference,omitempty"`
+		Reference         string          `db:"reference" json:"reference,omitempty"`
+		ProviderStatus    string          `db:"provider_status" json:"provider_status,omitempty"`
+		Amount            decimal.Decimal `db:"amount" json:"amount,omitempty"`
+		FailureReason     string          `db:"failure_reason" json:"failure_reason,omitempty"`
+		Payload           []byte          `db:"payload" json:"-"`
+		Status            string          `db:"status" json:"status,omitempty"`
+		Attempts          int             `db:"attempts" json:"attempts"`
+		LastError         string          `db:"last_error" json:"last_error,omitempty"`
+		CreatedAt         time.Time       `db:"created_at" json:"created_at,omitempty"`
+		Version           int             `db:"version" json:"version,omitempty"`
+		UpdatedAt         time.Time       `db:"updated_at" json:"updated_at,omitempty"`
+	}
+
+	PaymentCallbackFilter struct {
+		Provider          string `json:"provider,omitempty"`
+		ProviderReference string `json:"provider_reference,omitempty"`
+		ProviderStatus    string `json:"provider_status,omitempty"`
+		Status            string `json:"status,omitempty"`
+		Limit             int    `json:"limit,omitempty"`
+	}
+
+	PaymentCallbackUpdate struct {
+		ID        uint64 `db:"id" json:"id,omitempty"`
+		Status    string `db:"status" json:"status,omitempty"`
+		Attempts  int    `db:"attempts" json:"attempts"`
+		LastError string `db:"last_error" json:"last_error,omitempty"`
+	}
+
+	PaymentCallbackRepository interface {
+		// SaveCallback ignores the callback which already exists for the same provider reference and status,
+		// since the provider retries the callback until it receives 2xx.
+		SaveCallback(ctx context.Context, callback *PaymentCallbackEntity) error
+
+		FindCallbacks(ctx context.Context, filter *PaymentCallbackFilter) ([]*PaymentCallbackEntity, error)
+
+		UpdateCallback(ctx context.Context, callback *PaymentCallbackUpdate) error
+	}
+)
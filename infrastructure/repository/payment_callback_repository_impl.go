@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	queryInsertPaymentCallback = `
+		INSERT IGNORE INTO payment_callback (provider, provider_reference, reference, provider_status, amount, failure_reason, payload, status, attempts, created_at, version, updated_at) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	querySelectPaymentCallback = `
+		SELECT id, provider, provider_reference, reference, provider_status, amount, failure_reason, payload, 
+			status, attempts, last_error, created_at, version, updated_at 
+		FROM payment_callback WHERE TRUE
+	`
+
+	queryUpdatePaymentCallback = `
+		UPDATE payment_callback SET
+			status = ?,
+			attempts = ?,
+			last_error = ?,
+			version = version + 1,
+			updated_at = now()
+		WHERE id = ?
+	`
+)
+
+type paymentCallbackRepository struct {
+	connectionDB *sql.DB
+}
+
+func NewPaymentCallbackRepository(connectionDB *sql.DB) PaymentCallbackRepository {
+	return &paymentCallbackRepository{
+		connectionDB: connectionDB,
+	}
+}
+
+func (p *paymentCallbackRepository) SaveCallback(
+	ctx context.Context,
+	callback *PaymentCallbackEntity) error {
+	_, err := p.connectionDB.ExecContext(
+		ctx, queryInsertPaymentCallback,
+		callback.Provider,
+		callback.ProviderReference,
+		sql.NullString{String: callback.Reference, Valid: callback.Reference != ""},
+		callback.ProviderStatus,
+		callback.Amount,
+		sql.NullString{String: callback.FailureReason, Valid: callback.FailureReason != ""},
+		callback.Payload,
+		callback.Status,
+		callback.Attempts,
+		callback.CreatedAt,
+		callback.Version,
+		callback.UpdatedAt,
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	return nil
+}
+
+func (p *paymentCallbackRepository) FindCallbacks(
+	ctx context.Context,
+	filter *PaymentCallbackFilter) ([]*PaymentCallbackEntity, error) {
+	var sb strings.Builder
+	var parameters []interface{}
+
+	if filter.Provider != "" {
+		sb.WriteString("AND provider = ? ")
+		parameters = append(parameters, filter.Provider)
+	}
+
+	if filter.ProviderReference != "" {
+		sb.WriteString("AND provider_reference = ? ")
+		parameters = append(parameters, filter.ProviderReference)
+	}
+
+	if filter.ProviderStatus != "" {
+		sb.WriteString("AND provider_status = ? ")
+		parameters = append(parameters, filter.ProviderStatus)
+	}
+
+	if filter.Status != "" {
+		sb.WriteString("AND status = ? ")
+		parameters = append(parameters, filter.Status)
+	}
+
+	sb.WriteString("ORDER BY id ASC ")
+	if filter.Limit > 0 {
+		sb.WriteString("LIMIT ?")
+		parameters = append(parameters, filter.Limit)
+	}
+
+	res, err := p.connectionDB.QueryContext(ctx, querySelectPaymentCallback+sb.String(), parameters...)
+	if err != nil {
+		log.Println("unidentified error from database when query context -> ", err)
+		return nil, ErrorFromDBLoan
+	}
+	defer res.Close()
+
+	var data []*PaymentCallbackEntity
+	for res.Next() {
+		var r PaymentCallbackEntity
+		var amount sql.NullFloat64
+		var createdAt, updatedAt string
+		var reference, failureReason, lastError sql.NullString
+
+		errScan := res.Scan(
+			&r.ID, &r.Provider,
+			&r.ProviderReference, &reference,
+			&r.ProviderStatus, &amount,
+			&failureReason, &r.Payload,
+			&r.Status, &r.Attempts,
+			&lastError, &createdAt,
+			&r.Version, &updatedAt,
+		)
+
+		if errScan != nil {
+			log.Println("unidentified error from database when scan -> ", errScan)
+			return nil, ErrorFromDBLoan
+		}
+
+		r.Reference = reference.String
+		r.Amount = decimal.NewFromFloat(amount.Float64)
+		r.FailureReason = failureReason.String
+		r.LastError = lastError.String
+		r.CreatedAt = parseDateTime(createdAt)
+		r.UpdatedAt = parseDateTime(updatedAt)
+
+		data = append(data, &r)
+	}
+
+	return data, nil
+}
+
+func (p *paymentCallbackRepository) UpdateCallback(
+	ctx context.Context,
+	callback *PaymentCallbackUpdate) error {
+	_, err := p.connectionDB.ExecContext(
+		ctx, queryUpdatePaymentCallback,
+		callback.Status,
+		callback.Attempts,
+		sql.NullString{String: callback.LastError, Valid: callback.LastError != ""},
+		callback.ID,
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	queryInsertPayment = `
+		INSERT INTO payment (payment_id, user_id, amount, channel, payment_code, expires_at, installment_ids, status, provider, provider_reference, failure_reason, recovery, principal, interest, fee, penalty, created_at, version, updated_at) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	querySelectPayment = `
+		SELECT id, payment_id, user_id, amount, channel, payment_code, expires_at, installment_ids, status, provider, provider_reference, 
+			failure_reason, recovery, principal, interest, fee, penalty, created_at, version, updated_at 
+		FROM payment WHERE TRUE
+	`
+
+	queryUpdatePayment = `
+		UPDATE payment SET
+			status = ?,
+			provider_reference = COALESCE(?, provider_reference),
+			failure_reason = COALESCE(?, failure_reason),
+			version = version + 1,
+			updated_at = now()
+		WHERE payment_id = ? AND status = ?
+	`
+)
+
+type paymentRepository struct {
+	connectionDB *sql.DB
+}
+
+func NewPaymentRepository(connectionDB *sql.DB) PaymentRepository {
+	return &paymentRepository{
+		connectionDB: connectionDB,
+	}
+}
+
+func (p *paymentRepository) SavePayment(
+	ctx context.Context,
+	tx *sql.Tx,
+	payment *PaymentEntity) error {
+	var installmentIDs []string
+	for _, id := range payment.InstallmentIDs {
+		installmentIDs = append(installmentIDs, strconv.FormatUint(id, 10))
+	}
+
+	_, err := tx.ExecContext(
+		ctx, queryInsertPayment,
+		payment.PaymentID,
+		payment.UserID,
+		payment.Amount,
+		payment.Channel,
+		sql.NullString{String: payment.PaymentCode, Valid: payment.PaymentCode != ""},
+		payment.ExpiresAt,
+		strings.Join(installmentIDs, ","),
+		payment.Status,
+		payment.Provider,
+		sql.NullString{String: payment.ProviderReference, Valid: payment.ProviderReference != ""},
+		sql.NullString{String: payment.FailureReason, Valid: payment.FailureReason != ""},
+		payment.Recovery,
+		payment.Allocation.Principal,
+		payment.Allocation.Interest,
+		payment.Allocation.Fee,
+		payment.Allocation.Penalty,
+		payment.CreatedAt,
+		payment.Version,
+		payment.UpdatedAt,
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	return nil
+}
+
+func (p *paymentRepository) FindPayments(
+	ctx context.Context,
+	filter *PaymentFilter) ([]*PaymentEntity, error) {
+	var sb strings.Builder
+	var parameters []interface{}
+
+	if filter.PaymentID != "" {
+		sb.WriteString("AND payment_id = ? ")
+		parameters = append(parameters, filter.PaymentID)
+	}
+
+	if filter.UserID != "" {
+		sb.WriteString("AND user_id = ? ")
+		parameters = append(parameters, filter.UserID)
+	}
+
+	if filter.Provider != "" {
+		sb.WriteString("AND provider = ? ")
+		parameters = append(parameters, filter.Provider)
+	}
+
+	if filter.ProviderReference != "" {
+		sb.WriteString("AND provider_reference = ? ")
+		parameters = append(parameters, filter.ProviderReference)
+	}
+
+	if len(filter.Statuses) > 0 {
+		sb.WriteString("AND status IN (" + buildWhereIn(len(filter.Statuses)) + ") ")
+		for _, sts := range filter.Statuses {
+			parameters = append(parameters, sts)
+		}
+	}
+
+	res, err := p.connectionDB.QueryContext(ctx, querySelectPayment+sb.String()+"ORDER BY id ASC", parameters...)
+	if err != nil {
+		log.Println("unidentified error from database when query context -> ", err)
+		return nil, ErrorFromDBLoan
+	}
+	defer res.Close()
+
+	var data []*PaymentEntity
+	for res.Next() {
+		var r PaymentEntity
+		var amount, principal, interest, fee, penalty sql.NullFloat64
+		var installmentIDs, createdAt, updatedAt string
+		var paymentCode, expiresAt, providerReference, failureReason sql.NullString
+
+		errScan := res.Scan(
+			&r.ID, &r.PaymentID,
+			&r.UserID, &amount,
+			&r.Channel, &paymentCode,
+			&expiresAt, &installmentIDs,
+			&r.Status,
+			&r.Provider, &providerReference,
+			&failureReason, &r.Recovery,
+			&principal, &interest,
+			&fee, &penalty,
+			&createdAt,
+			&r.Version, &updatedAt,
+		)
+
+		if errScan != nil {
+			log.Println("unidentified error from database when scan -> ", errScan)
+			return nil, ErrorFromDBLoan
+		}
+
+		for _, id := range strings.Split(installmentIDs, ",") {
+			parsed, errParse := strconv.ParseUint(id, 10, 64)
+			if errParse == nil {
+				r.InstallmentIDs = append(r.InstallmentIDs, parsed)
+			}
+		}
+
+		r.Amount = decimal.NewFromFloat(amount.Float64)
+		r.Allocation = Breakdown{
+			Principal: decimal.NewFromFloat(principal.Float64),
+			Interest:  decimal.NewFromFloat(interest.Float64),
+			Fee:       decimal.NewFromFloat(fee.Float64),
+			Penalty:   decimal.NewFromFloat(penalty.Float64),
+		}
+		r.PaymentCode = paymentCode.String
+		r.ProviderReference = providerReference.String
+		r.FailureReason = failureReason.String
+		r.CreatedAt = parseDateTime(createdAt)
+		r.UpdatedAt = parseDateTime(updatedAt)
+
+		if expiresAt.Valid {
+			parsed := parseDateTime(expiresAt.String)
+			r.ExpiresAt = &parsed
+		}
+
+		data = append(data, &r)
+	}
+
+	return data, nil
+}
+
+func (p *paymentRepository) UpdatePayment(
+	ctx context.Context,
+	tx *sql.Tx,
+	payment *PaymentEntityUpdate) error {
+	result, err := tx.ExecContext(
+		ctx, queryUpdatePayment,
+		payment.Status,
+		sql.NullString{String: payment.ProviderReference, Valid: payment.ProviderReference != ""},
+		sql.NullString{String: payment.FailureReason, Valid: payment.FailureReason != ""},
+		payment.PaymentID,
+		payment.FromStatus,
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Println("unidentified error from database when rowsAffected -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	if affected == 0 {
+		return ErrorNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_paymentRepository_FindPayments(t *testing.T) {
+	dateRandom := time.Date(2024, 6, 23, 21, 0, 0, 0, time.UTC)
+	columns := []string{
+		"id", "payment_id", "user_id", "amount", "channel", "payment_code", "expires_at", "installment_ids", "status", "provider", "provider_reference",
+		"failure_reason", "recovery", "principal", "interest", "fee", "penalty", "created_at", "version", "updated_at",
+	}
+
+	tests := []struct {
+		name    string
+		sqlErr  error
+		sqlRows *sqlmock.Rows
+		want    []*PaymentEntity
+		wantErr bool
+	}{
+		{
+			name: "given happy case," +
+				"when findPayments," +
+				"then return the result from db",
+			sqlRows: sqlmock.NewRows(columns).
+				AddRow(1, "p-1", "abc", 25, "DIRECT_DEBIT", nil, nil, "1,2", "PENDING_DEBIT", "fake", nil, nil, false, 20, 0, 5, 0,
+					dateRandom, 0, dateRandom),
+			want: []*PaymentEntity{
+				{
+					ID:             1,
+					PaymentID:      "p-1",
+					UserID:         "abc",
+					Amount:         decimal.NewFromFloat(25),
+					Channel:        PaymentChannelDirectDebit,
+					InstallmentIDs: []uint64{1, 2},
+					Status:         "PENDING_DEBIT",
+					Provider:       "fake",
+					Allocation: Breakdown{
+						Principal: decimal.NewFromFloat(20),
+						Interest:  decimal.NewFromFloat(0),
+						Fee:       decimal.NewFromFloat(5),
+						Penalty:   decimal.NewFromFloat(0),
+					},
+					CreatedAt: dateRandom,
+					UpdatedAt: dateRandom,
+				},
+			},
+		},
+		{
+			name: "given negative case sql tx done," +
+				"when findPayments," +
+				"then return error",
+			sqlErr:  sql.ErrTxDone,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error PaymentRepositoryImpl.FindPayments() error = %v", err)
+				}
+				defer db.Close()
+
+				query := mock.ExpectQuery(regexp.QuoteMeta(querySelectPayment)).
+					WithArgs("abc", PaymentPendingDebit)
+
+				if tt.sqlErr != nil {
+					query.WillReturnError(tt.sqlErr)
+				} else {
+					query.WillReturnRows(tt.sqlRows)
+				}
+
+				p := NewPaymentRepository(db)
+				got, err := p.FindPayments(
+					context.Background(), &PaymentFilter{UserID: "abc", Statuses: []string{PaymentPendingDebit}})
+
+				assert.Equal(t, tt.wantErr, err != nil)
+				assert.Equal(t, tt.want, got)
+				assert.Nil(t, mock.ExpectationsWereMet())
+			})
+	}
+}
+
+func Test_paymentRepository_UpdatePayment(t *testing.T) {
+	update := &PaymentEntityUpdate{
+		PaymentID:         "p-1",
+		FromStatus:        PaymentPendingDebit,
+		Status:            PaymentPaid,
+		ProviderReference: "fake-1",
+	}
+
+	tests := []struct {
+		name     string
+		sqlErr   error
+		affected int64
+		wantErr  error
+	}{
+		{
+			name: "given happy case," +
+				"when updatePayment," +
+				"then return nil",
+			affected: 1,
+		},
+		{
+			name: "given payment is no longer pending debit," +
+				"when updatePayment," +
+				"then return no rows",
+			affected: 0,
+			wantErr:  ErrorNoRows,
+		},
+		{
+			name: "given negative case because execContext," +
+				"when updatePayment," +
+				"then return error",
+			sqlErr:  sql.ErrTxDone,
+			wantErr: ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error PaymentRepositoryImpl.UpdatePayment() error = %v", err)
+				}
+				defer db.Close()
+
+				mock.ExpectBegin()
+				exec := mock.ExpectExec(regexp.QuoteMeta(queryUpdatePayment)).
+					WithArgs(
+						PaymentPaid,
+						sql.NullString{String: "fake-1", Valid: true},
+						sql.NullString{},
+						"p-1", PaymentPendingDebit,
+					)
+
+				if tt.sqlErr != nil {
+					exec.WillReturnError(tt.sqlErr)
+				} else {
+					exec.WillReturnResult(sqlmock.NewResult(0, tt.affected))
+				}
+
+				tx, _ := db.Begin()
+				p := NewPaymentRepository(db)
+				err = p.UpdatePayment(context.Background(), tx, update)
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, mock.ExpectationsWereMet())
+			})
+	}
+}
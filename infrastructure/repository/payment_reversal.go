@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// RefundNotApplicable is the reversal without money to return, e.g. the bounced bank transfer.
+	RefundNotApplicable = "NOT_APPLICABLE"
+	RefundPending       = "PENDING"
+	RefundSucceeded     = "REFUNDED"
+	RefundFailed        = "FAILED"
+)
+
+type (
+	PaymentReversalEntity struct {
+		ID              uint64          `db:"id" json:"id,omitempty"`
+		ReversalID      string          `db:"reversal_id" json:"reversal_id,omitempty"`
+		PaymentID       string          `db:"payment_id" json:"payment_id,omitempty"`
+		UserID          string          `db:"user_id" json:"user_id,omitempty"`
+		Amount          decimal.Decimal `db:"amount" json:"amount,omitempty"`
+		InstallmentIDs  []uint64        `db:"installment_ids" json:"installment_ids,omitempty"`
+		Reason          string          `db:"reason" json:"reason,omitempty"`
+		RequestedBy     string          `db:"requested_by" json:"requested_by,omitempty"`
+		RefundStatus    string          `db:"refund_status" json:"refund_status,omitempty"`
+		RefundReference string          `db:"refund_reference" json:"refund_reference,omitempty"`
+		FailureReason   string          `db:"failure_reason" json:"failure_reason,omitempty"`
+		CreatedAt       time.Time       `db:"created_at" json:"created_at,omitempty"`
+		Version         int             `db:"version" json:"version,omitempty"`
+		UpdatedAt       time.Time       `db:"updated_at" json:"updated_at,omitempty"`
+	}
+
+	PaymentReversalUpdate struct {
+		ReversalID       string `db:"reversal_id" json:"reversal_id,omitempty"`
+		FromRefundStatus string `json:"from_refund_status,omitempty"`
+		RefundStatus     string `db:"refund_status" json:"refund_status,omitempty"`
+		RefundReference  string `db:"refund_reference" json:"refund_reference,omitempty"`
+		FailureReason    string `db:"failure_reason" json:"failure_reason,omitempty"`
+	}
+
+	// PaymentReversalRepository keeps the audit of the reversed payments, a payment is reversed once.
+	PaymentReversalRepository interface {
+		// SaveReversal returns ErrorDuplicate when the payment has been reversed.
+		SaveReversal(ctx context.Context, tx *sql.Tx, reversal *PaymentReversalEntity) error
+
+		// UpdateReversal updates the refund only when the current refund status is FromRefundStatus.
+		UpdateReversal(ctx context.Context, reversal *PaymentReversalUpdate) error
+	}
+)
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strconv"
+	"strings"
+)
+
+const (
+	queryInsertPaymentReversal = `
+		INSERT IGNORE INTO payment_reversal (reversal_id, payment_id, user_id, amount, installment_ids, reason, requested_by, refund_status, created_at, version, updated_at) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	queryUpdatePaymentReversal = `
+		UPDATE payment_reversal SET
+			refund_status = ?,
+			refund_reference = COALESCE(?, refund_reference),
+			failure_reason = COALESCE(?, failure_reason),
+			version = version + 1,
+			updated_at = now()
+		WHERE reversal_id = ? AND refund_status = ?
+	`
+)
+
+type paymentReversalRepository struct {
+	connectionDB *sql.DB
+}
+
+func NewPaymentReversalRepository(connectionDB *sql.DB) PaymentReversalRepository {
+	return &paymentReversalRepository{
+		connectionDB: connectionDB,
+	}
+}
+
+func (p *paymentReversalRepository) SaveReversal(
+	ctx context.Context,
+	tx *sql.Tx,
+	reversal *PaymentReversalEntity) error {
+	var installmentIDs []string
+	for _, id := range reversal.InstallmentIDs {
+		installmentIDs = append(installmentIDs, strconv.FormatUint(id, 10))
+	}
+
+	result, err := tx.ExecContext(
+		ctx, queryInsertPaymentReversal,
+		reversal.ReversalID,
+		reversal.PaymentID,
+		reversal.UserID,
+		reversal.Amount,
+		strings.Join(installmentIDs, ","),
+		reversal.Reason,
+		reversal.RequestedBy,
+		reversal.RefundStatus,
+		reversal.CreatedAt,
+		reversal.Version,
+		reversal.UpdatedAt,
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Println("unidentified error from database when rowsAffected -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	if affected == 0 {
+		return ErrorDuplicate
+	}
+
+	return nil
+}
+
+func (p *paymentReversalRepository) UpdateReversal(
+	ctx context.Context,
+	reversal *PaymentReversalUpdate) error {
+	result, err := p.connectionDB.ExecContext(
+		ctx, queryUpdatePaymentReversal,
+		reversal.RefundStatus,
+		sql.NullString{String: reversal.RefundReference, Valid: reversal.RefundReference != ""},
+		sql.NullString{String: reversal.FailureReason, Valid: reversal.FailureReason != ""},
+		reversal.ReversalID,
+		reversal.FromRefundStatus,
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Println("unidentified error from database when rowsAffected -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	if affected == 0 {
+		return ErrorNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_paymentReversalRepository_SaveReversal(t *testing.T) {
+	dateRandom := time.Date(2024, 6, 23, 21, 0, 0, 0, time.UTC)
+
+	reversal := &PaymentReversalEntity{
+		ReversalID:     "r-1",
+		PaymentID:      "p-1",
+		UserID:         "abc",
+		Amount:         decimal.NewFromFloat(25),
+		InstallmentIDs: []uint64{1, 2},
+		Reason:         "duplicate debit",
+		RequestedBy:    "ops-1",
+		RefundStatus:   RefundPending,
+		CreatedAt:      dateRandom,
+		UpdatedAt:      dateRandom,
+	}
+
+	tests := []struct {
+		name      string
+		sqlErr    error
+		sqlResult driver.Result
+		wantErr   error
+	}{
+		{
+			name: "given happy case," +
+				"when saveReversal," +
+				"then return nil",
+			sqlResult: sqlmock.NewResult(1, 1),
+		},
+		{
+			name: "given the payment has been reversed," +
+				"when saveReversal," +
+				"then return error duplicate",
+			sqlResult: sqlmock.NewResult(0, 0),
+			wantErr:   ErrorDuplicate,
+		},
+		{
+			name: "given negative case sql tx done," +
+				"when saveReversal," +
+				"then return error",
+			sqlErr:  sql.ErrTxDone,
+			wantErr: ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error PaymentReversalRepositoryImpl.SaveReversal() error = %v", err)
+				}
+				defer db.Close()
+
+				mock.ExpectBegin()
+
+				expect := mock.ExpectExec(regexp.QuoteMeta(queryInsertPaymentReversal)).
+					WithArgs(
+						"r-1", "p-1", "abc", reversal.Amount, "1,2", "duplicate debit", "ops-1", RefundPending,
+						dateRandom, 0, dateRandom)
+
+				if tt.sqlErr != nil {
+					expect.WillReturnError(tt.sqlErr)
+				} else {
+					expect.WillReturnResult(tt.sqlResult)
+				}
+
+				store := NewPaymentReversalRepository(db)
+				tx, _ := db.Begin()
+				err = store.SaveReversal(context.Background(), tx, reversal)
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.NoError(t, mock.ExpectationsWereMet())
+			})
+	}
+}
+
+func Test_paymentReversalRepository_UpdateReversal(t *testing.T) {
+	update := &PaymentReversalUpdate{
+		ReversalID:       "r-1",
+		FromRefundStatus: RefundPending,
+		RefundStatus:     RefundSucceeded,
+		RefundReference:  "fake-refund-1",
+	}
+
+	tests := []struct {
+		name      string
+		sqlResult driver.Result
+		wantErr   error
+	}{
+		{
+			name: "given happy case," +
+				"when updateReversal," +
+				"then return nil",
+			sqlResult: sqlmock.NewResult(0, 1),
+		},
+		{
+			name: "given the refund has been updated," +
+				"when updateReversal," +
+				"then return error no rows",
+			sqlResult: sqlmock.NewResult(0, 0),
+			wantErr:   ErrorNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error PaymentReversalRepositoryImpl.UpdateReversal() error = %v", err)
+				}
+				defer db.Close()
+
+				mock.ExpectExec(regexp.QuoteMeta(queryUpdatePaymentReversal)).
+					WithArgs(RefundSucceeded, "fake-refund-1", nil, "r-1", RefundPending).
+					WillReturnResult(tt.sqlResult)
+
+				store := NewPaymentReversalRepository(db)
+				err = store.UpdateReversal(context.Background(), update)
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.NoError(t, mock.ExpectationsWereMet())
+			})
+	}
+}
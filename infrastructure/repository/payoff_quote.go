@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	PayoffQuoteActive = "ACTIVE"
+	// PayoffQuoteUsed is the quote which has been paid by a payment, a quote is used once.
+	PayoffQuoteUsed = "USED"
+)
+
+type (
+	PayoffQuoteEntity struct {
+		ID             uint64          `db:"id" json:"id,omitempty"`
+		QuoteID        string          `db:"quote_id" json:"quote_id,omitempty"`
+		UserID         string          `db:"user_id" json:"user_id,omitempty"`
+		InstallmentIDs []uint64        `db:"installment_ids" json:"installment_ids,omitempty"`
+		Outstanding    decimal.Decimal `db:"outstanding" json:"outstanding,omitempty"`
+		Discount       decimal.Decimal `db:"discount" json:"discount,omitempty"`
+		Amount         decimal.Decimal `db:"amount" json:"amount,omitempty"`
+		Status         string          `db:"status" json:"status,omitempty"`
+		PaymentID      string          `db:"payment_id" json:"payment_id,omitempty"`
+		ExpiresAt      time.Time       `db:"expires_at" json:"expires_at,omitempty"`
+		CreatedAt      time.Time       `db:"created_at" json:"created_at,omitempty"`
+		Version        int             `db:"version" json:"version,omitempty"`
+		UpdatedAt      time.Time       `db:"updated_at" json:"updated_at,omitempty"`
+	}
+
+	PayoffQuoteUpdate struct {
+		QuoteID    string `db:"quote_id" json:"quote_id,omitempty"`
+		FromStatus string `json:"from_status,omitempty"`
+		Status     string `db:"status" json:"status,omitempty"`
+		PaymentID  string `db:"payment_id" json:"payment_id,omitempty"`
+	}
+
+	PayoffQuoteRepository interface {
+		SaveQuote(ctx context.Context, quote *PayoffQuoteEntity) error
+
+		// FindQuote returns ErrorNoRows when the quote is not exists.
+		FindQuote(ctx context.Context, quoteID string) (*PayoffQuoteEntity, error)
+
+		// UpdateQuote updates the quote only when the current status is FromStatus,
+		// it returns ErrorNoRows when the quote has been used by another payment.
+		UpdateQuote(ctx context.Context, tx *sql.Tx, quote *PayoffQuoteUpdate) error
+	}
+)
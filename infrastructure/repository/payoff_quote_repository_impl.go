@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	queryInsertPayoffQuote = `
+		INSERT INTO payoff_quote (quote_id, user_id, installment_ids, outstanding, discount, amount, status, expires_at, created_at, version, updated_at) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	querySelectPayoffQuote = `
+		SELECT id, quote_id, user_id, installment_ids, outstanding, discount, amount, status, payment_id, expires_at, 
+			created_at, version, updated_at 
+		FROM payoff_quote WHERE quote_id = ?
+	`
+
+	queryUpdatePayoffQuote = `
+		UPDATE payoff_quote SET
+			status = ?,
+			payment_id = COALESCE(?, payment_id),
+			version = version + 1,
+			updated_at = now()
+		WHERE quote_id = ? AND status = ?
+	`
+)
+
+type payoffQuoteRepository struct {
+	connectionDB *sql.DB
+}
+
+func NewPayoffQuoteRepository(connectionDB *sql.DB) PayoffQuoteRepository {
+	return &payoffQuoteRepository{
+		connectionDB: connectionDB,
+	}
+}
+
+func (p *payoffQuoteRepository) SaveQuote(
+	ctx context.Context,
+	quote *PayoffQuoteEntity) error {
+	var installmentIDs []string
+	for _, id := range quote.InstallmentIDs {
+		installmentIDs = append(installmentIDs, strconv.FormatUint(id, 10))
+	}
+
+	_, err := p.connectionDB.ExecContext(
+		ctx, queryInsertPayoffQuote,
+		quote.QuoteID,
+		quote.UserID,
+		strings.Join(installmentIDs, ","),
+		quote.Outstanding,
+		quote.Discount,
+		quote.Amount,
+		quote.Status,
+		quote.ExpiresAt,
+		quote.CreatedAt,
+		quote.Version,
+		quote.UpdatedAt,
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	return nil
+}
+
+func (p *payoffQuoteRepository) FindQuote(
+	ctx context.Context,
+	quoteID string) (*PayoffQuoteEntity, error) {
+	var r PayoffQuoteEntity
+	var outstanding, discount, amount sql.NullFloat64
+	var installmentIDs, expiresAt, createdAt, updatedAt string
+	var paymentID sql.NullString
+
+	err := p.connectionDB.QueryRowContext(ctx, querySelectPayoffQuote, quoteID).Scan(
+		&r.ID, &r.QuoteID,
+		&r.UserID, &installmentIDs,
+		&outstanding, &discount,
+		&amount, &r.Status,
+		&paymentID, &expiresAt,
+		&createdAt, &r.Version,
+		&updatedAt,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrorNoRows
+	}
+
+	if err != nil {
+		log.Println("unidentified error from database when scan -> ", err)
+		return nil, ErrorFromDBLoan
+	}
+
+	for _, id := range strings.Split(installmentIDs, ",") {
+		parsed, errParse := strconv.ParseUint(id, 10, 64)
+		if errParse == nil {
+			r.InstallmentIDs = append(r.InstallmentIDs, parsed)
+		}
+	}
+
+	r.Outstanding = decimal.NewFromFloat(outstanding.Float64)
+	r.Discount = decimal.NewFromFloat(discount.Float64)
+	r.Amount = decimal.NewFromFloat(amount.Float64)
+	r.PaymentID = paymentID.String
+	r.ExpiresAt = parseDateTime(expiresAt)
+	r.CreatedAt = parseDateTime(createdAt)
+	r.UpdatedAt = parseDateTime(updatedAt)
+
+	return &r, nil
+}
+
+func (p *payoffQuoteRepository) UpdateQuote(
+	ctx context.Context,
+	tx *sql.Tx,
+	quote *PayoffQuoteUpdate) error {
+	result, err := tx.ExecContext(
+		ctx, queryUpdatePayoffQuote,
+		quote.Status,
+		sql.NullString{String: quote.PaymentID, Valid: quote.PaymentID != ""},
+		quote.QuoteID,
+		quote.FromStatus,
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Println("unidentified error from database when rowsAffected -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	if affected == 0 {
+		return ErrorNoRows
+	}
+
+	return nil
+}
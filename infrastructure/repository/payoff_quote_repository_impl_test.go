@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_payoffQuoteRepository_FindQuote(t *testing.T) {
+	dateRandom := time.Date(2024, 6, 23, 21, 0, 0, 0, time.UTC)
+	columns := []string{
+		"id", "quote_id", "user_id", "installment_ids", "outstanding", "discount", "amount", "status", "payment_id",
+		"expires_at", "created_at", "version", "updated_at",
+	}
+
+	tests := []struct {
+		name    string
+		sqlErr  error
+		sqlRows *sqlmock.Rows
+		want    *PayoffQuoteEntity
+		wantErr error
+	}{
+		{
+			name: "given happy case," +
+				"when findQuote," +
+				"then return the result from db",
+			sqlRows: sqlmock.NewRows(columns).
+				AddRow(1, "q-1", "abc", "1,2", 30, 1.5, 28.5, PayoffQuoteActive, nil, dateRandom, dateRandom, 0, dateRandom),
+			want: &PayoffQuoteEntity{
+				ID:             1,
+				QuoteID:        "q-1",
+				UserID:         "abc",
+				InstallmentIDs: []uint64{1, 2},
+				Outstanding:    decimal.NewFromFloat(30),
+				Discount:       decimal.NewFromFloat(1.5),
+				Amount:         decimal.NewFromFloat(28.5),
+				Status:         PayoffQuoteActive,
+				ExpiresAt:      dateRandom,
+				CreatedAt:      dateRandom,
+				UpdatedAt:      dateRandom,
+			},
+		},
+		{
+			name: "given the quote is not exists," +
+				"when findQuote," +
+				"then return error no rows",
+			sqlErr:  sql.ErrNoRows,
+			wantErr: ErrorNoRows,
+		},
+		{
+			name: "given negative case sql tx done," +
+				"when findQuote," +
+				"then return error",
+			sqlErr:  sql.ErrTxDone,
+			wantErr: ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error PayoffQuoteRepositoryImpl.FindQuote() error = %v", err)
+				}
+				defer db.Close()
+
+				expect := mock.ExpectQuery(regexp.QuoteMeta(querySelectPayoffQuote)).WithArgs("q-1")
+				if tt.sqlErr != nil {
+					expect.WillReturnError(tt.sqlErr)
+				} else {
+					expect.WillReturnRows(tt.sqlRows)
+				}
+
+				store := NewPayoffQuoteRepository(db)
+				got, err := store.FindQuote(context.Background(), "q-1")
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.Equal(t, tt.want, got)
+				assert.NoError(t, mock.ExpectationsWereMet())
+			})
+	}
+}
+
+func Test_payoffQuoteRepository_UpdateQuote(t *testing.T) {
+	update := &PayoffQuoteUpdate{
+		QuoteID:    "q-1",
+		FromStatus: PayoffQuoteActive,
+		Status:     PayoffQuoteUsed,
+		PaymentID:  "p-1",
+	}
+
+	tests := []struct {
+		name      string
+		sqlResult driver.Result
+		wantErr   error
+	}{
+		{
+			name: "given happy case," +
+				"when updateQuote," +
+				"then return nil",
+			sqlResult: sqlmock.NewResult(0, 1),
+		},
+		{
+			name: "given the quote has been used," +
+				"when updateQuote," +
+				"then return error no rows",
+			sqlResult: sqlmock.NewResult(0, 0),
+			wantErr:   ErrorNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error PayoffQuoteRepositoryImpl.UpdateQuote() error = %v", err)
+				}
+				defer db.Close()
+
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(queryUpdatePayoffQuote)).
+					WithArgs(PayoffQuoteUsed, "p-1", "q-1", PayoffQuoteActive).
+					WillReturnResult(tt.sqlResult)
+
+				store := NewPayoffQuoteRepository(db)
+				tx, _ := db.Begin()
+				err = store.UpdateQuote(context.Background(), tx, update)
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.NoError(t, mock.ExpectationsWereMet())
+			})
+	}
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	ProductActive = "ACTIVE"
+	// ProductRetired is no longer offered for the new loans, the existing loans keep their version.
+	ProductRetired = "RETIRED"
+)
+
+type (
+	// ProductEntity is a version of the product, it is never changed once saved (except retiring it),
+	// every edit is saved as the next version so the loans issued by the previous version keep their terms.
+	ProductEntity struct {
+		// ID identifies the version, it is referenced by the loan (product_id).
+		ID             uint64          `db:"id" json:"id,omitempty"`
+		Code           string          `db:"code" json:"code,omitempty"`
+		ProductVersion int             `db:"product_version" json:"product_version,omitempty"`
+		Name           string          `db:"name" json:"name,omitempty"`
+		Status         string          `db:"status" json:"status,omitempty"`
+		MinAmount      decimal.Decimal `db:"min_amount" json:"min_amount"`
+		MaxAmount      decimal.Decimal `db:"max_amount" json:"max_amount"`
+		Tenors         []int           `db:"tenors" json:"tenors,omitempty"`
+		Frequency      string          `db:"frequency" json:"frequency,omitempty"`
+		InterestMethod string          `db:"interest_method" json:"interest_method,omitempty"`
+		AnnualRate     decimal.Decimal `db:"annual_rate" json:"annual_rate"`
+		FeeRate        decimal.Decimal `db:"fee_rate" json:"fee_rate"`
+		PenaltyRate    decimal.Decimal `db:"penalty_rate" json:"penalty_rate"`
+		PenaltyCap     decimal.Decimal `db:"penalty_cap" json:"penalty_cap"`
+		GraceDays      int             `db:"grace_days" json:"grace_days"`
+		// DelinquencyThreshold is the missed installments tolerated before the customer is delinquent.
+		DelinquencyThreshold int       `db:"delinquency_threshold" json:"delinquency_threshold"`
+		CreatedAt            time.Time `db:"created_at" json:"created_at,omitempty"`
+		Version              int       `db:"version" json:"version,omitempty"`
+		UpdatedAt            time.Time `db:"updated_at" json:"updated_at,omitempty"`
+	}
+
+	ProductFilter struct {
+		IDs            []uint64 `json:"ids,omitempty"`
+		Code           string   `json:"code,omitempty"`
+		ProductVersion int      `json:"product_version,omitempty"`
+		Status         string   `json:"status,omitempty"`
+		// Latest keeps only the latest version of every product.
+		Latest bool `json:"latest,omitempty"`
+	}
+
+	ProductRepository interface {
+		// SaveProduct returns ErrorDuplicate when the version of the product has been saved,
+		// e.g. the product is edited concurrently.
+		SaveProduct(ctx context.Context, product *ProductEntity) (uint64, error)
+
+		FindProducts(ctx context.Context, filter *ProductFilter) ([]*ProductEntity, error)
+
+		// RetireProduct retires all the versions of the product, ErrorNoRows is returned when none is active.
+		RetireProduct(ctx context.Context, code string) error
+	}
+)
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	queryInsertProduct = `
+		INSERT IGNORE INTO product (code, product_version, name, status, min_amount, max_amount, tenors, frequency, interest_method,
+			annual_rate, fee_rate, penalty_rate, penalty_cap, grace_days, delinquency_threshold, created_at, version, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	querySelectProduct = `
+		SELECT id, code, product_version, name, status, min_amount, max_amount, tenors, frequency, interest_method,
+			annual_rate, fee_rate, penalty_rate, penalty_cap, grace_days, delinquency_threshold, created_at, version, updated_at
+		FROM product WHERE TRUE
+	`
+
+	queryLatestProduct = `AND product_version = (SELECT MAX(p.product_version) FROM product p WHERE p.code = product.code) `
+
+	queryRetireProduct = `
+		UPDATE product SET
+			status = ?,
+			version = version + 1,
+			updated_at = now()
+		WHERE code = ? AND status = ?
+	`
+)
+
+type productRepository struct {
+	connectionDB *sql.DB
+}
+
+func NewProductRepository(connectionDB *sql.DB) ProductRepository {
+	return &productRepository{
+		connectionDB: connectionDB,
+	}
+}
+
+func (p *productRepository) SaveProduct(
+	ctx context.Context,
+	product *ProductEntity) (uint64, error) {
+	var tenors []string
+	for _, tenor := range product.Tenors {
+		tenors = append(tenors, strconv.Itoa(tenor))
+	}
+
+	result, err := p.connectionDB.ExecContext(
+		ctx, queryInsertProduct,
+		product.Code,
+		product.ProductVersion,
+		product.Name,
+		product.Status,
+		product.MinAmount,
+		product.MaxAmount,
+		strings.Join(tenors, ","),
+		product.Frequency,
+		product.InterestMethod,
+		product.AnnualRate,
+		product.FeeRate,
+		product.PenaltyRate,
+		product.PenaltyCap,
+		product.GraceDays,
+		product.DelinquencyThreshold,
+		product.CreatedAt,
+		product.Version,
+		product.UpdatedAt,
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return 0, ErrorFromDBLoan
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Println("unidentified error from database when rowsAffected -> ", err)
+		return 0, ErrorFromDBLoan
+	}
+
+	if affected == 0 {
+		return 0, ErrorDuplicate
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		log.Println("unidentified error from database when lastInsertId -> ", err)
+		return 0, ErrorFromDBLoan
+	}
+
+	return uint64(id), nil
+}
+
+func (p *productRepository) FindProducts(
+	ctx context.Context,
+	filter *ProductFilter) ([]*ProductEntity, error) {
+	var sb strings.Builder
+	var parameters []interface{}
+
+	if len(filter.IDs) > 0 {
+		sb.WriteString("AND id IN (" + buildWhereIn(len(filter.IDs)) + ") ")
+		for _, id := range filter.IDs {
+			parameters = append(parameters, id)
+		}
+	}
+
+	if filter.Code != "" {
+		sb.WriteString("AND code = ? ")
+		parameters = append(parameters, filter.Code)
+	}
+
+	if filter.ProductVersion != 0 {
+		sb.WriteString("AND product_version = ? ")
+		parameters = append(parameters, filter.ProductVersion)
+	}
+
+	if filter.Status != "" {
+		sb.WriteString("AND status = ? ")
+		parameters = append(parameters, filter.Status)
+	}
+
+	if filter.Latest {
+		sb.WriteString(queryLatestProduct)
+	}
+
+	res, err := p.connectionDB.QueryContext(
+		ctx, querySelectProduct+sb.String()+"ORDER BY code ASC, product_version ASC", parameters...)
+
+	if err != nil {
+		log.Println("unidentified error from database when query context -> ", err)
+		return nil, ErrorFromDBLoan
+	}
+	defer res.Close()
+
+	var data []*ProductEntity
+	for res.Next() {
+		var r ProductEntity
+		var tenors, createdAt, updatedAt string
+		var minAmount, maxAmount, annualRate, feeRate, penaltyRate, penaltyCap sql.NullFloat64
+
+		errScan := res.Scan(
+			&r.ID, &r.Code,
+			&r.ProductVersion, &r.Name,
+			&r.Status, &minAmount,
+			&maxAmount, &tenors,
+			&r.Frequency, &r.InterestMethod,
+			&annualRate, &feeRate,
+			&penaltyRate, &penaltyCap,
+			&r.GraceDays, &r.DelinquencyThreshold,
+			&createdAt, &r.Version,
+			&updatedAt,
+		)
+
+		if errScan != nil {
+			log.Println("unidentified error from database when scan -> ", errScan)
+			return nil, ErrorFromDBLoan
+		}
+
+		for _, tenor := range strings.Split(tenors, ",") {
+			parsed, errParse := strconv.Atoi(tenor)
+			if errParse != nil {
+				continue
+			}
+			r.Tenors = append(r.Tenors, parsed)
+		}
+
+		r.MinAmount = decimal.NewFromFloat(minAmount.Float64)
+		r.MaxAmount = decimal.NewFromFloat(maxAmount.Float64)
+		r.AnnualRate = decimal.NewFromFloat(annualRate.Float64)
+		r.FeeRate = decimal.NewFromFloat(feeRate.Float64)
+		r.PenaltyRate = decimal.NewFromFloat(penaltyRate.Float64)
+		r.PenaltyCap = decimal.NewFromFloat(penaltyCap.Float64)
+		r.CreatedAt = parseDateTime(createdAt)
+		r.UpdatedAt = parseDateTime(updatedAt)
+
+		data = append(data, &r)
+	}
+
+	return data, nil
+}
+
+func (p *productRepository) RetireProduct(
+	ctx context.Context,
+	code string) error {
+	result, err := p.connectionDB.ExecContext(ctx, queryRetireProduct, ProductRetired, code, ProductActive)
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Println("unidentified error from database when rowsAffected -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	if affected == 0 {
+		return ErrorNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_productRepository_SaveProduct(t *testing.T) {
+	dateRandom := time.Date(2024, 6, 23, 21, 0, 0, 0, time.UTC)
+
+	product := &ProductEntity{
+		Code:                 "MODAL-50W",
+		ProductVersion:       2,
+		Name:                 "Modal 50 weeks",
+		Status:               ProductActive,
+		MinAmount:            decimal.NewFromFloat(1000000),
+		MaxAmount:            decimal.NewFromFloat(5000000),
+		Tenors:               []int{25, 50},
+		Frequency:            "WEEKLY",
+		InterestMethod:       "FLAT",
+		AnnualRate:           decimal.NewFromFloat(0.24),
+		FeeRate:              decimal.NewFromFloat(0.1),
+		PenaltyRate:          decimal.NewFromFloat(0.01),
+		PenaltyCap:           decimal.NewFromFloat(50000),
+		GraceDays:            3,
+		DelinquencyThreshold: 2,
+		CreatedAt:            dateRandom,
+		UpdatedAt:            dateRandom,
+	}
+
+	tests := []struct {
+		name      string
+		sqlErr    error
+		sqlResult driver.Result
+		want      uint64
+		wantErr   error
+	}{
+		{
+			name: "given happy case," +
+				"when saveProduct," +
+				"then return the id of the version",
+			sqlResult: sqlmock.NewResult(7, 1),
+			want:      7,
+		},
+		{
+			name: "given the version has been saved," +
+				"when saveProduct," +
+				"then return error duplicate",
+			sqlResult: sqlmock.NewResult(0, 0),
+			wantErr:   ErrorDuplicate,
+		},
+		{
+			name: "given negative case sql conn done," +
+				"when saveProduct," +
+				"then return error",
+			sqlErr:  sql.ErrConnDone,
+			wantErr: ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error ProductRepositoryImpl.SaveProduct() error = %v", err)
+				}
+				defer db.Close()
+
+				expect := mock.ExpectExec(regexp.QuoteMeta(queryInsertProduct)).
+					WithArgs(
+						"MODAL-50W", 2, "Modal 50 weeks", ProductActive, product.MinAmount, product.MaxAmount, "25,50",
+						"WEEKLY", "FLAT", product.AnnualRate, product.FeeRate, product.PenaltyRate, product.PenaltyCap,
+						3, 2, dateRandom, 0, dateRandom,
+					)
+
+				if tt.sqlErr != nil {
+					expect.WillReturnError(tt.sqlErr)
+				} else {
+					expect.WillReturnResult(tt.sqlResult)
+				}
+
+				got, err := NewProductRepository(db).SaveProduct(context.Background(), product)
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.Equal(t, tt.want, got)
+				assert.Nil(t, mock.ExpectationsWereMet())
+			})
+	}
+}
+
+func Test_productRepository_FindProducts(t *testing.T) {
+	dateRandom := time.Date(2024, 6, 23, 21, 0, 0, 0, time.UTC)
+	columns := []string{
+		"id", "code", "product_version", "name", "status", "min_amount", "max_amount", "tenors", "frequency",
+		"interest_method", "annual_rate", "fee_rate", "penalty_rate", "penalty_cap", "grace_days",
+		"delinquency_threshold", "created_at", "version", "updated_at",
+	}
+
+	tests := []struct {
+		name     string
+		filter   *ProductFilter
+		wantSql  string
+		wantArgs []driver.Value
+		sqlErr   error
+		sqlRows  *sqlmock.Rows
+		want     []*ProductEntity
+		wantErr  error
+	}{
+		{
+			name: "given the latest active version of the code," +
+				"when findProducts," +
+				"then return the result from db",
+			filter:   &ProductFilter{Code: "MODAL-50W", Status: ProductActive, Latest: true},
+			wantSql:  "AND code = ? AND status = ? " + queryLatestProduct,
+			wantArgs: []driver.Value{"MODAL-50W", ProductActive},
+			sqlRows: sqlmock.NewRows(columns).
+				AddRow(
+					7, "MODAL-50W", 2, "Modal 50 weeks", ProductActive, 1000000, 5000000, "25,50", "WEEKLY", "FLAT",
+					0.24, 0.1, 0.01, 50000, 3, 2, dateRandom, 0, dateRandom,
+				),
+			want: []*ProductEntity{
+				{
+					ID:                   7,
+					Code:                 "MODAL-50W",
+					ProductVersion:       2,
+					Name:                 "Modal 50 weeks",
+					Status:               ProductActive,
+					MinAmount:            decimal.NewFromFloat(1000000),
+					MaxAmount:            decimal.NewFromFloat(5000000),
+					Tenors:               []int{25, 50},
+					Frequency:            "WEEKLY",
+					InterestMethod:       "FLAT",
+					AnnualRate:           decimal.NewFromFloat(0.24),
+					FeeRate:              decimal.NewFromFloat(0.1),
+					PenaltyRate:          decimal.NewFromFloat(0.01),
+					PenaltyCap:           decimal.NewFromFloat(50000),
+					GraceDays:            3,
+					DelinquencyThreshold: 2,
+					CreatedAt:            dateRandom,
+					UpdatedAt:            dateRandom,
+				},
+			},
+		},
+		{
+			name: "given the versions referenced by the loans," +
+				"when findProducts," +
+				"then return nothing when there is no row",
+			filter:   &ProductFilter{IDs: []uint64{3, 7}},
+			wantSql:  "AND id IN (?,?) ",
+			wantArgs: []driver.Value{3, 7},
+			sqlRows:  sqlmock.NewRows(columns),
+		},
+		{
+			name: "given negative case sql conn done," +
+				"when findProducts," +
+				"then return error",
+			filter:  &ProductFilter{},
+			sqlErr:  sql.ErrConnDone,
+			wantErr: ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error ProductRepositoryImpl.FindProducts() error = %v", err)
+				}
+				defer db.Close()
+
+				expect := mock.ExpectQuery(regexp.QuoteMeta(querySelectProduct + tt.wantSql + "ORDER BY code ASC, product_version ASC")).
+					WithArgs(tt.wantArgs...)
+
+				if tt.sqlErr != nil {
+					expect.WillReturnError(tt.sqlErr)
+				} else {
+					expect.WillReturnRows(tt.sqlRows)
+				}
+
+				got, err := NewProductRepository(db).FindProducts(context.Background(), tt.filter)
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.Equal(t, tt.want, got)
+				assert.Nil(t, mock.ExpectationsWereMet())
+			})
+	}
+}
+
+func Test_productRepository_RetireProduct(t *testing.T) {
+	tests := []struct {
+		name      string
+		sqlErr    error
+		sqlResult driver.Result
+		wantErr   error
+	}{
+		{
+			name: "given the active product," +
+				"when retireProduct," +
+				"then return nil",
+			sqlResult: sqlmock.NewResult(0, 2),
+		},
+		{
+			name: "given the product is not exists or retired," +
+				"when retireProduct," +
+				"then return error no rows",
+			sqlResult: sqlmock.NewResult(0, 0),
+			wantErr:   ErrorNoRows,
+		},
+		{
+			name: "given negative case sql conn done," +
+				"when retireProduct," +
+				"then return error",
+			sqlErr:  sql.ErrConnDone,
+			wantErr: ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error ProductRepositoryImpl.RetireProduct() error = %v", err)
+				}
+				defer db.Close()
+
+				expect := mock.ExpectExec(regexp.QuoteMeta(queryRetireProduct)).
+					WithArgs(ProductRetired, "MODAL-50W", ProductActive)
+
+				if tt.sqlErr != nil {
+					expect.WillReturnError(tt.sqlErr)
+				} else {
+					expect.WillReturnResult(tt.sqlResult)
+				}
+
+				err = NewProductRepository(db).RetireProduct(context.Background(), "MODAL-50W")
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, mock.ExpectationsWereMet())
+			})
+	}
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	ReconciliationMatched        = "MATCHED"
+	ReconciliationUnmatched      = "UNMATCHED"
+	ReconciliationAmountMismatch = "AMOUNT_MISMATCH"
+
+	ReconciliationByReference = "REFERENCE"
+	ReconciliationByVaNumber  = "VA_NUMBER"
+)
+
+type (
+	// ReconciliationItemEntity is the result of matching a line of the bank statement against the recorded payments.
+	ReconciliationItemEntity struct {
+		ID             uint64          `db:"id" json:"id,omitempty"`
+		RunID          string          `db:"run_id" json:"run_id,omitempty"`
+		SourceFile     string          `db:"source_file" json:"source_file,omitempty"`
+		Format         string          `db:"format" json:"format,omitempty"`
+		LineNumber     int             `db:"line_number" json:"line_number,omitempty"`
+		StatementDate  time.Time       `db:"statement_date" json:"statement_date,omitempty"`
+		Reference      string          `db:"reference" json:"reference,omitempty"`
+		VaNumber       string          `db:"va_number" json:"va_number,omitempty"`
+		Amount         decimal.Decimal `db:"amount" json:"amount,omitempty"`
+		RecordedAmount decimal.Decimal `db:"recorded_amount" json:"recorded_amount,omitempty"`
+		PaymentID      string          `db:"payment_id" json:"payment_id,omitempty"`
+		MatchedBy      string          `db:"matched_by" json:"matched_by,omitempty"`
+		Status         string          `db:"status" json:"status,omitempty"`
+		Note           string          `db:"note" json:"note,omitempty"`
+		CreatedAt      time.Time       `db:"created_at" json:"created_at,omitempty"`
+	}
+
+	ReconciliationRepository interface {
+		SaveItems(ctx context.Context, tx *sql.Tx, items ...*ReconciliationItemEntity) error
+	}
+)
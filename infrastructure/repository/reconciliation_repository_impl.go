@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+)
+
+const (
+	queryInsertReconciliationItem = `
+		INSERT INTO reconciliation_item (run_id, source_file, format, line_number, statement_date, reference, va_number, amount, 
+			recorded_amount, payment_id, matched_by, status, note, created_at) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+)
+
+type reconciliationRepository struct {
+	connectionDB *sql.DB
+}
+
+func NewReconciliationRepository(connectionDB *sql.DB) ReconciliationRepository {
+	return &reconciliationRepository{
+		connectionDB: connectionDB,
+	}
+}
+
+func (r *reconciliationRepository) SaveItems(
+	ctx context.Context,
+	tx *sql.Tx,
+	items ...*ReconciliationItemEntity) error {
+	statement, err := tx.PrepareContext(ctx, queryInsertReconciliationItem)
+	if err != nil {
+		log.Println("unidentified error from database when prepare -> ", err)
+		return ErrorFromDBLoan
+	}
+	defer statement.Close()
+
+	for _, item := range items {
+		_, errExecContext := statement.ExecContext(
+			ctx,
+			item.RunID,
+			item.SourceFile,
+			item.Format,
+			item.LineNumber,
+			item.StatementDate,
+			sql.NullString{String: item.Reference, Valid: item.Reference != ""},
+			sql.NullString{String: item.VaNumber, Valid: item.VaNumber != ""},
+			item.Amount,
+			item.RecordedAmount,
+			sql.NullString{String: item.PaymentID, Valid: item.PaymentID != ""},
+			sql.NullString{String: item.MatchedBy, Valid: item.MatchedBy != ""},
+			item.Status,
+			sql.NullString{String: item.Note, Valid: item.Note != ""},
+			item.CreatedAt,
+		)
+
+		if errExecContext != nil {
+			log.Println("unidentified error from database when exec -> ", errExecContext)
+			return ErrorFromDBLoan
+		}
+	}
+
+	return nil
+}
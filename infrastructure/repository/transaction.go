@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+type (
+	// Transaction wraps the unit of work, every repository which accept *sql.Tx
+	// should be called inside the fn, so they are committed or rolled back together.
+	Transaction interface {
+		WithTransaction(ctx context.Context, fn func(tx *sql.Tx) error) error
+	}
+)
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+)
+
+type transaction struct {
+	connectionDB *sql.DB
+}
+
+func NewTransaction(connectionDB *sql.DB) Transaction {
+	return &transaction{
+		connectionDB: connectionDB,
+	}
+}
+
+func (t *transaction) WithTransaction(
+	ctx context.Context,
+	fn func(tx *sql.Tx) error) (err error) {
+	tx, err := t.connectionDB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Println("unidentified error from database when begin -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			_ = tx.Rollback()
+			panic(rec)
+		}
+
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Println("failed when rollback -> ", errRollback)
+			}
+			return
+		}
+
+		if errCommit := tx.Commit(); errCommit != nil {
+			log.Println("failed when commit -> ", errCommit)
+			err = ErrorFromDBLoan
+		}
+	}()
+
+	return fn(tx)
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	VirtualAccountActive = "ACTIVE"
+
+	VirtualAccountCreditReceived   = "RECEIVED"
+	VirtualAccountCreditProcessing = "PROCESSING"
+	VirtualAccountCreditApplied    = "APPLIED"
+	VirtualAccountCreditUnapplied  = "UNAPPLIED"
+)
+
+type (
+	VirtualAccountEntity struct {
+		ID        uint64    `db:"id" json:"id,omitempty"`
+		BankCode  string    `db:"bank_code" json:"bank_code,omitempty"`
+		Number    string    `db:"va_number" json:"va_number,omitempty"`
+		UserID    string    `db:"user_id" json:"user_id,omitempty"`
+		Status    string    `db:"status" json:"status,omitempty"`
+		CreatedAt time.Time `db:"created_at" json:"created_at,omitempty"`
+		Version   int       `db:"version" json:"version,omitempty"`
+		UpdatedAt time.Time `db:"updated_at" json:"updated_at,omitempty"`
+	}
+
+	VirtualAccountFilter struct {
+		BankCode string `json:"bank_code,omitempty"`
+		Number   string `json:"va_number,omitempty"`
+		UserID   string `json:"user_id,omitempty"`
+	}
+
+	VirtualAccountCreditEntity struct {
+		ID            uint64          `db:"id" json:"id,omitempty"`
+		BankCode      string          `db:"bank_code" json:"bank_code,omitempty"`
+		Number        string          `db:"va_number" json:"va_number,omitempty"`
+		Reference     string          `db:"reference" json:"reference,omitempty"`
+		UserID        string          `db:"user_id" json:"user_id,omitempty"`
+		Amount        decimal.Decimal `db:"amount" json:"amount,omitempty"`
+		PaymentID     string          `db:"payment_id" json:"payment_id,omitempty"`
+		Status        string          `db:"status" json:"status,omitempty"`
+		FailureReason string          `db:"failure_reason" json:"failure_reason,omitempty"`
+		CreatedAt     time.Time       `db:"created_at" json:"created_at,omitempty"`
+		Version       int             `db:"version" json:"version,omitempty"`
+		UpdatedAt     time.Time       `db:"updated_at" json:"updated_at,omitempty"`
+	}
+
+	VirtualAccountCreditFilter struct {
+		BankCode  string `json:"bank_code,omitempty"`
+		Number    string `json:"va_number,omitempty"`
+		Reference string `json:"reference,omitempty"`
+		Status    string `json:"status,omitempty"`
+		// UpdatedBefore looks for the credit not updated since, e.g. the PROCESSING one left behind by a crash.
+		UpdatedBefore time.Time `json:"updated_before,omitempty"`
+		Limit         int       `json:"limit,omitempty"`
+	}
+
+	VirtualAccountCreditUpdate struct {
+		ID            uint64 `db:"id" json:"id,omitempty"`
+		FromStatus    string `json:"from_status,omitempty"`
+		Status        string `db:"status" json:"status,omitempty"`
+		PaymentID     string `db:"payment_id" json:"payment_id,omitempty"`
+		FailureReason string `db:"failure_reason" json:"failure_reason,omitempty"`
+		// UpdatedBefore moves the credit only when it is not updated since, zero is any time.
+		UpdatedBefore time.Time `json:"updated_before,omitempty"`
+	}
+
+	VirtualAccountRepository interface {
+		// SaveVirtualAccount returns ErrorDuplicate when the number, or the bank of the customer, already exists.
+		SaveVirtualAccount(ctx context.Context, virtualAccount *VirtualAccountEntity) error
+
+		FindVirtualAccounts(ctx context.Context, filter *VirtualAccountFilter) ([]*VirtualAccountEntity, error)
+
+		// SaveCredit ignores the credit which already exists for the same bank reference,
+		// since the bank retries the notification until it receives 2xx.
+		SaveCredit(ctx context.Context, credit *VirtualAccountCreditEntity) error
+
+		FindCredits(ctx context.Context, filter *VirtualAccountCreditFilter) ([]*VirtualAccountCreditEntity, error)
+
+		// UpdateCredit moves the credit only from FromStatus (and not updated since UpdatedBefore),
+		// otherwise it returns ErrorNoRows.
+		UpdateCredit(ctx context.Context, credit *VirtualAccountCreditUpdate) error
+	}
+)
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	queryInsertVirtualAccount = `
+		INSERT IGNORE INTO virtual_account (bank_code, va_number, user_id, status, created_at, version, updated_at) 
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	querySelectVirtualAccount = `
+		SELECT id, bank_code, va_number, user_id, status, created_at, version, updated_at 
+		FROM virtual_account WHERE TRUE
+	`
+
+	queryInsertVirtualAccountCredit = `
+		INSERT IGNORE INTO virtual_account_credit (bank_code, va_number, reference, user_id, amount, status, created_at, version, updated_at) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	querySelectVirtualAccountCredit = `
+		SELECT id, bank_code, va_number, reference, user_id, amount, payment_id, status, failure_reason, created_at, version, updated_at 
+		FROM virtual_account_credit WHERE TRUE
+	`
+
+	queryUpdateVirtualAccountCredit = `
+		UPDATE virtual_account_credit SET
+			status = ?,
+			payment_id = COALESCE(?, payment_id),
+			failure_reason = COALESCE(?, failure_reason),
+			version = version + 1,
+			updated_at = now()
+		WHERE id = ? AND status = ? AND (? IS NULL OR updated_at < ?)
+	`
+)
+
+type virtualAccountRepository struct {
+	connectionDB *sql.DB
+}
+
+func NewVirtualAccountRepository(connectionDB *sql.DB) VirtualAccountRepository {
+	return &virtualAccountRepository{
+		connectionDB: connectionDB,
+	}
+}
+
+func (v *virtualAccountRepository) SaveVirtualAccount(
+	ctx context.Context,
+	virtualAccount *VirtualAccountEntity) error {
+	result, err := v.connectionDB.ExecContext(
+		ctx, queryInsertVirtualAccount,
+		virtualAccount.BankCode,
+		virtualAccount.Number,
+		virtualAccount.UserID,
+		virtualAccount.Status,
+		virtualAccount.CreatedAt,
+		virtualAccount.Version,
+		virtualAccount.UpdatedAt,
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Println("unidentified error from database when rowsAffected -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	if affected == 0 {
+		return ErrorDuplicate
+	}
+
+	return nil
+}
+
+func (v *virtualAccountRepository) FindVirtualAccounts(
+	ctx context.Context,
+	filter *VirtualAccountFilter) ([]*VirtualAccountEntity, error) {
+	var sb strings.Builder
+	var parameters []interface{}
+
+	if filter.BankCode != "" {
+		sb.WriteString("AND bank_code = ? ")
+		parameters = append(parameters, filter.BankCode)
+	}
+
+	if filter.Number != "" {
+		sb.WriteString("AND va_number = ? ")
+		parameters = append(parameters, filter.Number)
+	}
+
+	if filter.UserID != "" {
+		sb.WriteString("AND user_id = ? ")
+		parameters = append(parameters, filter.UserID)
+	}
+
+	sb.WriteString("ORDER BY id ASC")
+
+	res, err := v.connectionDB.QueryContext(ctx, querySelectVirtualAccount+sb.String(), parameters...)
+	if err != nil {
+		log.Println("unidentified error from database when query context -> ", err)
+		return nil, ErrorFromDBLoan
+	}
+	defer res.Close()
+
+	var data []*VirtualAccountEntity
+	for res.Next() {
+		var r VirtualAccountEntity
+		var createdAt, updatedAt string
+
+		errScan := res.Scan(
+			&r.ID, &r.BankCode,
+			&r.Number, &r.UserID,
+			&r.Status, &createdAt,
+			&r.Version, &updatedAt,
+		)
+
+		if errScan != nil {
+			log.Println("unidentified error from database when scan -> ", errScan)
+			return nil, ErrorFromDBLoan
+		}
+
+		r.CreatedAt = parseDateTime(createdAt)
+		r.UpdatedAt = parseDateTime(updatedAt)
+
+		data = append(data, &r)
+	}
+
+	return data, nil
+}
+
+func (v *virtualAccountRepository) SaveCredit(
+	ctx context.Context,
+	credit *VirtualAccountCreditEntity) error {
+	_, err := v.connectionDB.ExecContext(
+		ctx, queryInsertVirtualAccountCredit,
+		credit.BankCode,
+		credit.Number,
+		credit.Reference,
+		credit.UserID,
+		credit.Amount,
+		credit.Status,
+		credit.CreatedAt,
+		credit.Version,
+		credit.UpdatedAt,
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	return nil
+}
+
+func (v *virtualAccountRepository) FindCredits(
+	ctx context.Context,
+	filter *VirtualAccountCreditFilter) ([]*VirtualAccountCreditEntity, error) {
+	var sb strings.Builder
+	var parameters []interface{}
+
+	if filter.BankCode != "" {
+		sb.WriteString("AND bank_code = ? ")
+		parameters = append(parameters, filter.BankCode)
+	}
+
+	if filter.Number != "" {
+		sb.WriteString("AND va_number = ? ")
+		parameters = append(parameters, filter.Number)
+	}
+
+	if filter.Reference != "" {
+		sb.WriteString("AND reference = ? ")
+		parameters = append(parameters, filter.Reference)
+	}
+
+	if filter.Status != "" {
+		sb.WriteString("AND status = ? ")
+		parameters = append(parameters, filter.Status)
+	}
+
+	if !filter.UpdatedBefore.IsZero() {
+		sb.WriteString("AND updated_at < ? ")
+		parameters = append(parameters, filter.UpdatedBefore)
+	}
+
+	sb.WriteString("ORDER BY id ASC ")
+	if filter.Limit > 0 {
+		sb.WriteString("LIMIT ?")
+		parameters = append(parameters, filter.Limit)
+	}
+
+	res, err := v.connectionDB.QueryContext(ctx, querySelectVirtualAccountCredit+sb.String(), parameters...)
+	if err != nil {
+		log.Println("unidentified error from database when query context -> ", err)
+		return nil, ErrorFromDBLoan
+	}
+	defer res.Close()
+
+	var data []*VirtualAccountCreditEntity
+	for res.Next() {
+		var r VirtualAccountCreditEntity
+		var amount sql.NullFloat64
+		var createdAt, updatedAt string
+		var paymentID, failureReason sql.NullString
+
+		errScan := res.Scan(
+			&r.ID, &r.BankCode,
+			&r.Number, &r.Reference,
+			&r.UserID, &amount,
+			&paymentID, &r.Status,
+			&failureReason, &createdAt,
+			&r.Version, &updatedAt,
+		)
+
+		if errScan != nil {
+			log.Println("unidentified error from database when scan -> ", errScan)
+			return nil, ErrorFromDBLoan
+		}
+
+		r.Amount = decimal.NewFromFloat(amount.Float64)
+		r.PaymentID = paymentID.String
+		r.FailureReason = failureReason.String
+		r.CreatedAt = parseDateTime(createdAt)
+		r.UpdatedAt = parseDateTime(updatedAt)
+
+		data = append(data, &r)
+	}
+
+	return data, nil
+}
+
+func (v *virtualAccountRepository) UpdateCredit(
+	ctx context.Context,
+	credit *VirtualAccountCreditUpdate) error {
+	result, err := v.connectionDB.ExecContext(
+		ctx, queryUpdateVirtualAccountCredit,
+		credit.Status,
+		sql.NullString{String: credit.PaymentID, Valid: credit.PaymentID != ""},
+		sql.NullString{String: credit.FailureReason, Valid: credit.FailureReason != ""},
+		credit.ID,
+		credit.FromStatus,
+		sql.NullTime{Time: credit.UpdatedBefore, Valid: !credit.UpdatedBefore.IsZero()},
+		sql.NullTime{Time: credit.UpdatedBefore, Valid: !credit.UpdatedBefore.IsZero()},
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Println("unidentified error from database when rowsAffected -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	if affected == 0 {
+		return ErrorNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_virtualAccountRepository_SaveVirtualAccount(t *testing.T) {
+	dateRandom := time.Date(2024, 6, 23, 21, 0, 0, 0, time.UTC)
+	virtualAccount := &VirtualAccountEntity{
+		BankCode:  "bca",
+		Number:    "3935800000000018",
+		UserID:    "abc",
+		Status:    VirtualAccountActive,
+		CreatedAt: dateRandom,
+		UpdatedAt: dateRandom,
+	}
+
+	tests := []struct {
+		name     string
+		sqlErr   error
+		affected int64
+		wantErr  error
+	}{
+		{
+			name: "given happy case," +
+				"when saveVirtualAccount," +
+				"then return nil",
+			affected: 1,
+		},
+		{
+			name: "given the number is already taken," +
+				"when saveVirtualAccount," +
+				"then return duplicate",
+			affected: 0,
+			wantErr:  ErrorDuplicate,
+		},
+		{
+			name: "given negative case because execContext," +
+				"when saveVirtualAccount," +
+				"then return error",
+			sqlErr:  sql.ErrConnDone,
+			wantErr: ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error VirtualAccountRepositoryImpl.SaveVirtualAccount() error = %v", err)
+				}
+				defer db.Close()
+
+				exec := mock.ExpectExec(regexp.QuoteMeta(queryInsertVirtualAccount)).
+					WithArgs("bca", "3935800000000018", "abc", VirtualAccountActive, dateRandom, 0, dateRandom)
+
+				if tt.sqlErr != nil {
+					exec.WillReturnError(tt.sqlErr)
+				} else {
+					exec.WillReturnResult(sqlmock.NewResult(1, tt.affected))
+				}
+
+				v := NewVirtualAccountRepository(db)
+				err = v.SaveVirtualAccount(context.Background(), virtualAccount)
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, mock.ExpectationsWereMet())
+			})
+	}
+}
+
+func Test_virtualAccountRepository_UpdateCredit(t *testing.T) {
+	update := &VirtualAccountCreditUpdate{
+		ID:         1,
+		FromStatus: VirtualAccountCreditProcessing,
+		Status:     VirtualAccountCreditApplied,
+		PaymentID:  "p-1",
+	}
+
+	tests := []struct {
+		name     string
+		sqlErr   error
+		affected int64
+		wantErr  error
+	}{
+		{
+			name: "given happy case," +
+				"when updateCredit," +
+				"then return nil",
+			affected: 1,
+		},
+		{
+			name: "given credit is no longer processing," +
+				"when updateCredit," +
+				"then return no rows",
+			affected: 0,
+			wantErr:  ErrorNoRows,
+		},
+		{
+			name: "given negative case because execContext," +
+				"when updateCredit," +
+				"then return error",
+			sqlErr:  sql.ErrConnDone,
+			wantErr: ErrorFromDBLoan,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error VirtualAccountRepositoryImpl.UpdateCredit() error = %v", err)
+				}
+				defer db.Close()
+
+				exec := mock.ExpectExec(regexp.QuoteMeta(queryUpdateVirtualAccountCredit)).
+					WithArgs(
+						VirtualAccountCreditApplied,
+						sql.NullString{String: "p-1", Valid: true},
+						sql.NullString{},
+						1, VirtualAccountCreditProcessing,
+						sql.NullTime{}, sql.NullTime{},
+					)
+
+				if tt.sqlErr != nil {
+					exec.WillReturnError(tt.sqlErr)
+				} else {
+					exec.WillReturnResult(sqlmock.NewResult(0, tt.affected))
+				}
+
+				v := NewVirtualAccountRepository(db)
+				err = v.UpdateCredit(context.Background(), update)
+
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, mock.ExpectationsWereMet())
+			})
+	}
+}
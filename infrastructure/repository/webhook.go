@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	WebhookSubscriptionActive   = "ACTIVE"
+	WebhookSubscriptionInactive = "INACTIVE"
+
+	WebhookDeliveryPending   = "PENDING"
+	WebhookDeliveryRetrying  = "RETRYING"
+	WebhookDeliverySucceeded = "SUCCEEDED"
+	WebhookDeliveryDead      = "DEAD"
+)
+
+type (
+	WebhookSubscriptionEntity struct {
+		ID         uint64    `db:"id" json:"id,omitempty"`
+		Partner    string    `db:"partner" json:"partner,omitempty"`
+		URL        string    `db:"url" json:"url,omitempty"`
+		Secret     string    `db:"secret" json:"-"`
+		EventTypes []string  `db:"event_types" json:"event_types,omitempty"`
+		Status     string    `db:"status" json:"status,omitempty"`
+		CreatedAt  time.Time `db:"created_at" json:"created_at,omitempty"`
+		Version    int       `db:"version" json:"version,omitempty"`
+		UpdatedAt  time.Time `db:"updated_at" json:"updated_at,omitempty"`
+	}
+
+	WebhookSubscriptionFilter struct {
+		Status    string `json:"status,omitempty"`
+		EventType string `json:"event_type,omitempty"`
+	}
+
+	WebhookDeliveryEntity struct {
+		ID               uint64     `db:"id" json:"id,omitempty"`
+		DeliveryID       string     `db:"delivery_id" json:"delivery_id,omitempty"`
+		SubscriptionID   uint64     `db:"subscription_id" json:"subscription_id,omitempty"`
+		EventID          string     `db:"event_id" json:"event_id,omitempty"`
+		EventType        string     `db:"event_type" json:"event_type,omitempty"`
+		Payload          []byte     `db:"payload" json:"-"`
+		Status           string     `db:"status" json:"status,omitempty"`
+		Attempts         int        `db:"attempts" json:"attempts"`
+		NextAttemptAt    time.Time  `db:"next_attempt_at" json:"next_attempt_at,omitempty"`
+		LastResponseCode int        `db:"last_response_code" json:"last_response_code,omitempty"`
+		LastError        string     `db:"last_error" json:"last_error,omitempty"`
+		DeliveredAt      *time.Time `db:"delivered_at" json:"delivered_at,omitempty"`
+		CreatedAt        time.Time  `db:"created_at" json:"created_at,omitempty"`
+		Version          int        `db:"version" json:"version,omitempty"`
+		UpdatedAt        time.Time  `db:"updated_at" json:"updated_at,omitempty"`
+	}
+
+	WebhookDeliveryFilter struct {
+		DeliveryID     string    `json:"delivery_id,omitempty"`
+		SubscriptionID uint64    `json:"subscription_id,omitempty"`
+		Statuses       []string  `json:"statuses,omitempty"`
+		DueBefore      time.Time `json:"due_before,omitempty"`
+		Limit          int       `json:"limit,omitempty"`
+	}
+
+	WebhookDeliveryUpdate struct {
+		ID               uint64     `db:"id" json:"id,omitempty"`
+		Status           string     `db:"status" json:"status,omitempty"`
+		Attempts         int        `db:"attempts" json:"attempts"`
+		NextAttemptAt    time.Time  `db:"next_attempt_at" json:"next_attempt_at,omitempty"`
+		LastResponseCode int        `db:"last_response_code" json:"last_response_code,omitempty"`
+		LastError        string     `db:"last_error" json:"last_error,omitempty"`
+		DeliveredAt      *time.Time `db:"delivered_at" json:"delivered_at,omitempty"`
+	}
+
+	WebhookRepository interface {
+		SaveSubscription(ctx context.Context, subscription *WebhookSubscriptionEntity) (uint64, error)
+
+		FindSubscriptions(ctx context.Context, filter *WebhookSubscriptionFilter) ([]*WebhookSubscriptionEntity, error)
+
+		// SaveDeliveries ignores the delivery which already exists for the same subscription and event,
+		// since the relay publishes the event at least once.
+		SaveDeliveries(ctx context.Context, deliveries ...*WebhookDeliveryEntity) error
+
+		FindDeliveries(ctx context.Context, filter *WebhookDeliveryFilter) ([]*WebhookDeliveryEntity, error)
+
+		UpdateDelivery(ctx context.Context, delivery *WebhookDeliveryUpdate) error
+	}
+)
@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+)
+
+const (
+	queryInsertWebhookSubscription = `
+		INSERT INTO webhook_subscription (partner, url, secret, event_types, status, created_at, version, updated_at) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	querySelectWebhookSubscription = `
+		SELECT id, partner, url, secret, event_types, status, created_at, version, updated_at 
+		FROM webhook_subscription WHERE TRUE
+	`
+
+	queryInsertWebhookDelivery = `
+		INSERT IGNORE INTO webhook_delivery (delivery_id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, created_at, version, updated_at) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	querySelectWebhookDelivery = `
+		SELECT id, delivery_id, subscription_id, event_id, event_type, payload, status, attempts, 
+			next_attempt_at, last_response_code, last_error, delivered_at, created_at, version, updated_at 
+		FROM webhook_delivery WHERE TRUE
+	`
+
+	queryUpdateWebhookDelivery = `
+		UPDATE webhook_delivery SET
+			status = ?,
+			attempts = ?,
+			next_attempt_at = ?,
+			last_response_code = ?,
+			last_error = ?,
+			delivered_at = ?,
+			version = version + 1,
+			updated_at = now()
+		WHERE id = ?
+	`
+)
+
+type webhookRepository struct {
+	connectionDB *sql.DB
+}
+
+func NewWebhookRepository(connectionDB *sql.DB) WebhookRepository {
+	return &webhookRepository{
+		connectionDB: connectionDB,
+	}
+}
+
+func (w *webhookRepository) SaveSubscription(
+	ctx context.Context,
+	subscription *WebhookSubscriptionEntity) (uint64, error) {
+	result, err := w.connectionDB.ExecContext(
+		ctx, queryInsertWebhookSubscription,
+		subscription.Partner,
+		subscription.URL,
+		subscription.Secret,
+		strings.Join(subscription.EventTypes, ","),
+		subscription.Status,
+		subscription.CreatedAt,
+		subscription.Version,
+		subscription.UpdatedAt,
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return 0, ErrorFromDBLoan
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		log.Println("unidentified error from database when lastInsertId -> ", err)
+		return 0, ErrorFromDBLoan
+	}
+
+	return uint64(id), nil
+}
+
+func (w *webhookRepository) FindSubscriptions(
+	ctx context.Context,
+	filter *WebhookSubscriptionFilter) ([]*WebhookSubscriptionEntity, error) {
+	var sb strings.Builder
+	var parameters []interface{}
+
+	if filter.Status != "" {
+		sb.WriteString("AND status = ? ")
+		parameters = append(parameters, filter.Status)
+	}
+
+	if filter.EventType != "" {
+		sb.WriteString("AND FIND_IN_SET(?, event_types) > 0 ")
+		parameters = append(parameters, filter.EventType)
+	}
+
+	res, err := w.connectionDB.QueryContext(
+		ctx, querySelectWebhookSubscription+sb.String()+"ORDER BY id ASC", parameters...)
+
+	if err != nil {
+		log.Println("unidentified error from database when query context -> ", err)
+		return nil, ErrorFromDBLoan
+	}
+	defer res.Close()
+
+	var data []*WebhookSubscriptionEntity
+	for res.Next() {
+		var r WebhookSubscriptionEntity
+		var eventTypes, createdAt, updatedAt string
+
+		errScan := res.Scan(
+			&r.ID, &r.Partner,
+			&r.URL, &r.Secret,
+			&eventTypes, &r.Status,
+			&createdAt, &r.Version,
+			&updatedAt,
+		)
+
+		if errScan != nil {
+			log.Println("unidentified error from database when scan -> ", errScan)
+			return nil, ErrorFromDBLoan
+		}
+
+		if eventTypes != "" {
+			r.EventTypes = strings.Split(eventTypes, ",")
+		}
+		r.CreatedAt = parseDateTime(createdAt)
+		r.UpdatedAt = parseDateTime(updatedAt)
+
+		data = append(data, &r)
+	}
+
+	return data, nil
+}
+
+func (w *webhookRepository) SaveDeliveries(
+	ctx context.Context,
+	deliveries ...*WebhookDeliveryEntity) error {
+	statement, err := w.connectionDB.PrepareContext(ctx, queryInsertWebhookDelivery)
+	if err != nil {
+		log.Println("unidentified error from database when prepare -> ", err)
+		return ErrorFromDBLoan
+	}
+	defer statement.Close()
+
+	for _, entry := range deliveries {
+		_, errExecContext := statement.ExecContext(
+			ctx,
+			entry.DeliveryID,
+			entry.SubscriptionID,
+			entry.EventID,
+			entry.EventType,
+			entry.Payload,
+			entry.Status,
+			entry.Attempts,
+			entry.NextAttemptAt,
+			entry.CreatedAt,
+			entry.Version,
+			entry.UpdatedAt,
+		)
+
+		if errExecContext != nil {
+			log.Println("unidentified error from database when exec -> ", errExecContext)
+			return ErrorFromDBLoan
+		}
+	}
+
+	return nil
+}
+
+func (w *webhookRepository) FindDeliveries(
+	ctx context.Context,
+	filter *WebhookDeliveryFilter) ([]*WebhookDeliveryEntity, error) {
+	var sb strings.Builder
+	var parameters []interface{}
+
+	if filter.DeliveryID != "" {
+		sb.WriteString("AND delivery_id = ? ")
+		parameters = append(parameters, filter.DeliveryID)
+	}
+
+	if filter.SubscriptionID != 0 {
+		sb.WriteString("AND subscription_id = ? ")
+		parameters = append(parameters, filter.SubscriptionID)
+	}
+
+	if len(filter.Statuses) > 0 {
+		sb.WriteString("AND status IN (" + buildWhereIn(len(filter.Statuses)) + ") ")
+		for _, sts := range filter.Statuses {
+			parameters = append(parameters, sts)
+		}
+	}
+
+	if !filter.DueBefore.IsZero() {
+		sb.WriteString("AND next_attempt_at <= ? ")
+		parameters = append(parameters, filter.DueBefore)
+	}
+
+	sb.WriteString("ORDER BY id ASC ")
+	if filter.Limit > 0 {
+		sb.WriteString("LIMIT ?")
+		parameters = append(parameters, filter.Limit)
+	}
+
+	res, err := w.connectionDB.QueryContext(ctx, querySelectWebhookDelivery+sb.String(), parameters...)
+	if err != nil {
+		log.Println("unidentified error from database when query context -> ", err)
+		return nil, ErrorFromDBLoan
+	}
+	defer res.Close()
+
+	var data []*WebhookDeliveryEntity
+	for res.Next() {
+		var r WebhookDeliveryEntity
+		var nextAttemptAt, createdAt, updatedAt string
+		var lastResponseCode sql.NullInt64
+		var lastError, deliveredAt sql.NullString
+
+		errScan := res.Scan(
+			&r.ID, &r.DeliveryID,
+			&r.SubscriptionID, &r.EventID,
+			&r.EventType, &r.Payload,
+			&r.Status, &r.Attempts,
+			&nextAttemptAt, &lastResponseCode,
+			&lastError, &deliveredAt,
+			&createdAt, &r.Version,
+			&updatedAt,
+		)
+
+		if errScan != nil {
+			log.Println("unidentified error from database when scan -> ", errScan)
+			return nil, ErrorFromDBLoan
+		}
+
+		r.NextAttemptAt = parseDateTime(nextAttemptAt)
+		r.LastResponseCode = int(lastResponseCode.Int64)
+		r.LastError = lastError.String
+		r.CreatedAt = parseDateTime(createdAt)
+		r.UpdatedAt = parseDateTime(updatedAt)
+
+		if deliveredAt.Valid {
+			parsed := parseDateTime(deliveredAt.String)
+			r.DeliveredAt = &parsed
+		}
+
+		data = append(data, &r)
+	}
+
+	return data, nil
+}
+
+func (w *webhookRepository) UpdateDelivery(
+	ctx context.Context,
+	delivery *WebhookDeliveryUpdate) error {
+	_, err := w.connectionDB.ExecContext(
+		ctx, queryUpdateWebhookDelivery,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		sql.NullInt64{Int64: int64(delivery.LastResponseCode), Valid: delivery.LastResponseCode != 0},
+		sql.NullString{String: delivery.LastError, Valid: delivery.LastError != ""},
+		delivery.DeliveredAt,
+		delivery.ID,
+	)
+
+	if err != nil {
+		log.Println("unidentified error from database when exec -> ", err)
+		return ErrorFromDBLoan
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_webhookRepository_FindSubscriptions(t *testing.T) {
+	dateRandom := time.Date(2024, 6, 23, 21, 0, 0, 0, time.UTC)
+	columns := []string{
+		"id", "partner", "url", "secret", "event_types", "status", "created_at", "version", "updated_at",
+	}
+
+	tests := []struct {
+		name    string
+		filter  *WebhookSubscriptionFilter
+		args    []interface{}
+		sqlErr  error
+		sqlRows *sqlmock.Rows
+		want    []*WebhookSubscriptionEntity
+		wantErr bool
+	}{
+		{
+			name: "given happy case filtered by event type," +
+				"when findSubscriptions," +
+				"then return the result from db",
+			filter: &WebhookSubscriptionFilter{Status: WebhookSubscriptionActive, EventType: "LoanClosed"},
+			args:   []interface{}{WebhookSubscriptionActive, "LoanClosed"},
+			sqlRows: sqlmock.NewRows(columns).
+				AddRow(1, "lender", "https://lender", "secret", "LoanClosed,PaymentSucceeded", "ACTIVE", dateRandom, 0, dateRandom),
+			want: []*WebhookSubscriptionEntity{
+				{
+					ID:         1,
+					Partner:    "lender",
+					URL:        "https://lender",
+					Secret:     "secret",
+					EventTypes: []string{"LoanClosed", "PaymentSucceeded"},
+					Status:     "ACTIVE",
+					CreatedAt:  dateRandom,
+					UpdatedAt:  dateRandom,
+				},
+			},
+		},
+		{
+			name: "given negative case sql tx done," +
+				"when findSubscriptions," +
+				"then return error",
+			filter:  &WebhookSubscriptionFilter{},
+			sqlErr:  sql.ErrTxDone,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error WebhookRepositoryImpl.FindSubscriptions() error = %v", err)
+				}
+				defer db.Close()
+
+				query := mock.ExpectQuery(regexp.QuoteMeta(querySelectWebhookSubscription))
+				if len(tt.args) > 0 {
+					var args []driver.Value
+					for _, arg := range tt.args {
+						args = append(args, arg)
+					}
+					query = query.WithArgs(args...)
+				}
+
+				if tt.sqlErr != nil {
+					query.WillReturnError(tt.sqlErr)
+				} else {
+					query.WillReturnRows(tt.sqlRows)
+				}
+
+				w := NewWebhookRepository(db)
+				got, err := w.FindSubscriptions(context.Background(), tt.filter)
+
+				assert.Equal(t, tt.wantErr, err != nil)
+				assert.Equal(t, tt.want, got)
+				assert.Nil(t, mock.ExpectationsWereMet())
+			})
+	}
+}
+
+func Test_webhookRepository_UpdateDelivery(t *testing.T) {
+	dateRandom := time.Date(2024, 6, 23, 21, 0, 0, 0, time.UTC)
+	update := &WebhookDeliveryUpdate{
+		ID:               1,
+		Status:           WebhookDeliveryRetrying,
+		Attempts:         2,
+		NextAttemptAt:    dateRandom,
+		LastResponseCode: 503,
+		LastError:        "partner responds with status 503",
+	}
+
+	tests := []struct {
+		name    string
+		sqlErr  error
+		wantErr bool
+	}{
+		{
+			name: "given happy case," +
+				"when updateDelivery," +
+				"then return nil",
+		},
+		{
+			name: "given negative case because execContext," +
+				"when updateDelivery," +
+				"then return error",
+			sqlErr:  sql.ErrTxDone,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Errorf("DB Connection Error WebhookRepositoryImpl.UpdateDelivery() error = %v", err)
+				}
+				defer db.Close()
+
+				exec := mock.ExpectExec(regexp.QuoteMeta(queryUpdateWebhookDelivery)).
+					WithArgs(
+						WebhookDeliveryRetrying, 2, dateRandom,
+						sql.NullInt64{Int64: 503, Valid: true},
+						sql.NullString{String: "partner responds with status 503", Valid: true},
+						nil, uint64(1),
+					)
+
+				if tt.sqlErr != nil {
+					exec.WillReturnError(tt.sqlErr)
+				} else {
+					exec.WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+
+				w := NewWebhookRepository(db)
+				err = w.UpdateDelivery(context.Background(), update)
+
+				assert.Equal(t, tt.wantErr, err != nil)
+				assert.Nil(t, mock.ExpectationsWereMet())
+			})
+	}
+}
@@ -0,0 +1,80 @@
+package statement
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// the columns of the generic bank csv, the first row is the header :
+// date (YYYY-MM-DD), reference, va_number, amount, type (CR or DB), description
+const (
+	columnDate = iota
+	columnReference
+	columnVaNumber
+	columnAmount
+	columnType
+	columnDescription
+
+	totalColumns
+)
+
+type bankParser struct{}
+
+func (b *bankParser) Parse(reader io.Reader) ([]*Line, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []*Line
+	for i, record := range records {
+		//header
+		if i == 0 {
+			continue
+		}
+
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		if len(record) < totalColumns-1 {
+			return nil, fmt.Errorf("%w : row %d has %d columns", ErrorInvalidLine, i+1, len(record))
+		}
+
+		date, errDate := time.Parse("2006-01-02", strings.TrimSpace(record[columnDate]))
+		if errDate != nil {
+			return nil, fmt.Errorf("%w : row %d date %s", ErrorInvalidLine, i+1, record[columnDate])
+		}
+
+		amount, errAmount := decimal.NewFromString(strings.ReplaceAll(strings.TrimSpace(record[columnAmount]), ",", ""))
+		if errAmount != nil {
+			return nil, fmt.Errorf("%w : row %d amount %s", ErrorInvalidLine, i+1, record[columnAmount])
+		}
+
+		line := &Line{
+			Number:    i + 1,
+			Date:      date,
+			Credit:    !strings.EqualFold(strings.TrimSpace(record[columnType]), "DB"),
+			Amount:    amount.Abs(),
+			Reference: strings.TrimSpace(record[columnReference]),
+			VaNumber:  strings.TrimSpace(record[columnVaNumber]),
+		}
+
+		if len(record) > columnDescription {
+			line.Description = strings.TrimSpace(record[columnDescription])
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,110 @@
+package statement
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	tagStatementLine = ":61:"
+	tagInformation   = ":86:"
+)
+
+var (
+	// :61: value date (YYMMDD), optional entry date (MMDD), mark (C, D, RC, RD), optional funds code,
+	// amount with comma as decimal separator, transaction type (N + 3 chars), customer reference, optional //bank reference
+	statementLinePattern = regexp.MustCompile(`^(\d{6})(\d{4})?(RC|RD|C|D)([A-Z])?(\d+,\d{0,2})([NSF][A-Z0-9]{3})([^/]*)(?://(.*))?$`)
+
+	vaNumberPattern = regexp.MustCompile(`\d{10,20}`)
+)
+
+type mt940Parser struct{}
+
+func (m *mt940Parser) Parse(reader io.Reader) ([]*Line, error) {
+	scanner := bufio.NewScanner(reader)
+
+	var lines []*Line
+	var current *Line
+	var inInformation bool
+	number := 0
+
+	for scanner.Scan() {
+		text := strings.TrimRight(scanner.Text(), "\r")
+
+		switch {
+		case strings.HasPrefix(text, tagStatementLine):
+			number++
+			line, err := parseStatementLine(strings.TrimPrefix(text, tagStatementLine), number)
+			if err != nil {
+				return nil, err
+			}
+
+			lines = append(lines, line)
+			current = line
+			inInformation = false
+		case strings.HasPrefix(text, tagInformation):
+			if current != nil {
+				current.Description = strings.TrimSpace(strings.TrimPrefix(text, tagInformation))
+				inInformation = true
+			}
+		case strings.HasPrefix(text, ":") || text == "-" || strings.HasPrefix(text, "{"):
+			//other tags (:20:, :25:, :28C:, :60F:, :62F:), or the end of the message
+			inInformation = false
+		default:
+			//the continuation of :86:
+			if inInformation && current != nil {
+				current.Description += " " + strings.TrimSpace(text)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, line := range lines {
+		if line.VaNumber == "" {
+			line.VaNumber = vaNumberPattern.FindString(line.Description)
+		}
+	}
+
+	return lines, nil
+}
+
+func parseStatementLine(text string, number int) (*Line, error) {
+	match := statementLinePattern.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return nil, fmt.Errorf("%w : statement line %d %s", ErrorInvalidLine, number, text)
+	}
+
+	date, err := time.Parse("060102", match[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w : statement line %d date %s", ErrorInvalidLine, number, match[1])
+	}
+
+	amount, err := decimal.NewFromString(strings.Replace(match[5], ",", ".", 1))
+	if err != nil {
+		return nil, fmt.Errorf("%w : statement line %d amount %s", ErrorInvalidLine, number, match[5])
+	}
+
+	//the customer reference NONREF means there is no reference, then the bank reference is used
+	reference := strings.TrimSpace(match[7])
+	if reference == "" || reference == "NONREF" {
+		reference = strings.TrimSpace(match[8])
+	}
+
+	mark := match[3]
+	return &Line{
+		Number:    number,
+		Date:      date,
+		Credit:    mark == "C" || mark == "RD",
+		Amount:    amount,
+		Reference: reference,
+	}, nil
+}
@@ -0,0 +1,49 @@
+package statement
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	FormatBank  = "bank"
+	FormatMT940 = "mt940"
+)
+
+var (
+	ErrorUnknownFormat = errors.New("unknown format of statement")
+	ErrorInvalidLine   = errors.New("invalid line of statement")
+)
+
+type (
+	// Line is the single transaction of the bank statement.
+	Line struct {
+		// Number is the position in the file (csv row or :61: tag), used to point the exception back to the file.
+		Number    int             `json:"number"`
+		Date      time.Time       `json:"date"`
+		Credit    bool            `json:"credit"`
+		Amount    decimal.Decimal `json:"amount"`
+		Reference string          `json:"reference,omitempty"`
+		// VaNumber is the virtual account credited by the transfer, if the bank tells it.
+		VaNumber    string `json:"va_number,omitempty"`
+		Description string `json:"description,omitempty"`
+	}
+
+	Parser interface {
+		Parse(reader io.Reader) ([]*Line, error)
+	}
+)
+
+func NewParser(format string) (Parser, error) {
+	switch format {
+	case FormatBank:
+		return &bankParser{}, nil
+	case FormatMT940:
+		return &mt940Parser{}, nil
+	default:
+		return nil, ErrorUnknownFormat
+	}
+}
@@ -0,0 +1,106 @@
+package statement
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_bankParser_Parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []*Line
+		wantErr bool
+	}{
+		{
+			name: "given valid csv," +
+				"when parse," +
+				"then return the lines without header",
+			content: "date,reference,va_number,amount,type,description\n" +
+				"2026-10-19,TRF-1,3935800000000018,\"4,290,000.00\",CR,transfer\n" +
+				"2026-10-19,FEE-1,,5000,DB,\n",
+			want: []*Line{
+				{
+					Number:      2,
+					Date:        time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+					Credit:      true,
+					Amount:      decimal.NewFromInt(4290000),
+					Reference:   "TRF-1",
+					VaNumber:    "3935800000000018",
+					Description: "transfer",
+				},
+				{
+					Number:    3,
+					Date:      time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+					Amount:    decimal.NewFromInt(5000),
+					Reference: "FEE-1",
+				},
+			},
+		},
+		{
+			name: "given invalid amount," +
+				"when parse," +
+				"then return error",
+			content: "date,reference,va_number,amount,type,description\n" +
+				"2026-10-19,TRF-1,3935800000000018,abc,CR,transfer\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				parser, _ := NewParser(FormatBank)
+				got, err := parser.Parse(strings.NewReader(tt.content))
+
+				assert.Equal(t, tt.wantErr, err != nil)
+				assert.Equal(t, len(tt.want), len(got))
+				for i := range tt.want {
+					assert.True(t, tt.want[i].Amount.Equal(got[i].Amount))
+					tt.want[i].Amount = got[i].Amount
+					assert.Equal(t, tt.want[i], got[i])
+				}
+			})
+	}
+}
+
+func Test_mt940Parser_Parse(t *testing.T) {
+	content := ":20:STMT-20261019\n" +
+		":25:1234567890\n" +
+		":28C:00001/001\n" +
+		":60F:C261018IDR100000,00\n" +
+		":61:2610191019C4290000,00NTRFTRF-1//BANK-1\n" +
+		":86:VA 3935800000000018\n" +
+		"BUDI\n" +
+		":61:261019D5000,00NCHGNONREF//FEE-1\n" +
+		":86:ADMIN FEE\n" +
+		":62F:C261019IDR4385000,00\n" +
+		"-\n"
+
+	parser, _ := NewParser(FormatMT940)
+	got, err := parser.Parse(strings.NewReader(content))
+
+	assert.Nil(t, err)
+	assert.Len(t, got, 2)
+
+	assert.Equal(t, 1, got[0].Number)
+	assert.Equal(t, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), got[0].Date)
+	assert.True(t, got[0].Credit)
+	assert.True(t, decimal.NewFromInt(4290000).Equal(got[0].Amount))
+	assert.Equal(t, "TRF-1", got[0].Reference)
+	assert.Equal(t, "3935800000000018", got[0].VaNumber)
+	assert.Equal(t, "VA 3935800000000018 BUDI", got[0].Description)
+
+	assert.False(t, got[1].Credit)
+	assert.Equal(t, "FEE-1", got[1].Reference)
+	assert.Equal(t, "", got[1].VaNumber)
+}
+
+func Test_NewParser(t *testing.T) {
+	_, err := NewParser("xls")
+	assert.Equal(t, ErrorUnknownFormat, err)
+}
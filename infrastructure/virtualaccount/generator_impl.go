@@ -0,0 +1,154 @@
+package virtualaccount
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	// minimumDigits keeps the collision of the derived digits low enough to be solved by the next attempt.
+	minimumDigits = 6
+)
+
+type generator struct {
+	banks map[string]*Bank
+	codes []string
+}
+
+func (g *generator) Banks() []*Bank {
+	var banks []*Bank
+	for _, code := range g.codes {
+		banks = append(banks, g.banks[code])
+	}
+
+	return banks
+}
+
+func (g *generator) Number(bankCode, key string, attempt int) (string, error) {
+	bank, ok := g.banks[strings.ToLower(bankCode)]
+	if !ok {
+		return "", ErrorUnknownBank
+	}
+
+	digits := bank.Length - len(bank.Prefix)
+	if bank.CheckDigit != CheckDigitNone {
+		digits--
+	}
+
+	body := bank.Prefix + derive(fmt.Sprintf("%s:%s:%d", bank.Code, key, attempt), digits)
+	return body + checkDigit(bank.CheckDigit, body), nil
+}
+
+func (g *generator) Validate(bankCode, number string) (*Bank, error) {
+	bank, ok := g.banks[strings.ToLower(bankCode)]
+	if !ok {
+		return nil, ErrorUnknownBank
+	}
+
+	if len(number) != bank.Length || !isDigits(number) || !strings.HasPrefix(number, bank.Prefix) {
+		return nil, ErrorInvalidNumber
+	}
+
+	if bank.CheckDigit != CheckDigitNone &&
+		checkDigit(bank.CheckDigit, number[:len(number)-1]) != number[len(number)-1:] {
+		return nil, ErrorInvalidNumber
+	}
+
+	return bank, nil
+}
+
+func validateBank(bank *Bank) error {
+	if bank.Prefix == "" || !isDigits(bank.Prefix) {
+		return errors.New("prefix should be digits")
+	}
+
+	switch bank.CheckDigit {
+	case CheckDigitNone, CheckDigitLuhn, CheckDigitMod11:
+	default:
+		return errors.New("unknown check digit " + bank.CheckDigit)
+	}
+
+	digits := bank.Length - len(bank.Prefix)
+	if bank.CheckDigit != CheckDigitNone {
+		digits--
+	}
+
+	if digits < minimumDigits {
+		return fmt.Errorf("length should leave at least %d digits after the prefix", minimumDigits)
+	}
+
+	return nil
+}
+
+// derive takes the digits from the hash of the seed, so the number can't be guessed from the user id.
+func derive(seed string, digits int) string {
+	sum := sha256.Sum256([]byte(seed))
+	modulus := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	value := new(big.Int).Mod(new(big.Int).SetBytes(sum[:]), modulus)
+
+	return fmt.Sprintf("%0*s", digits, value.String())
+}
+
+func checkDigit(rule, body string) string {
+	switch rule {
+	case CheckDigitLuhn:
+		return Luhn(body)
+	case CheckDigitMod11:
+		return Mod11(body)
+	default:
+		return ""
+	}
+}
+
+// Luhn returns the check digit of the body (mod 10, doubling every second digit from the right).
+func Luhn(body string) string {
+	sum := 0
+	for i := len(body) - 1; i >= 0; i-- {
+		digit := int(body[i] - '0')
+		if (len(body)-1-i)%2 == 0 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+
+		sum += digit
+	}
+
+	return fmt.Sprint((10 - sum%10) % 10)
+}
+
+// Mod11 returns the check digit of the body with weights 2..7 from the right,
+// the remainder 10 is written as 0.
+func Mod11(body string) string {
+	sum := 0
+	weight := 2
+	for i := len(body) - 1; i >= 0; i-- {
+		sum += int(body[i]-'0') * weight
+
+		weight++
+		if weight > 7 {
+			weight = 2
+		}
+	}
+
+	digit := (11 - sum%11) % 11
+	if digit == 10 {
+		digit = 0
+	}
+
+	return fmt.Sprint(digit)
+}
+
+func isDigits(value string) bool {
+	for _, char := range value {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+
+	return value != ""
+}
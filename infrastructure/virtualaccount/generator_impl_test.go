@@ -0,0 +1,137 @@
+package virtualaccount
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Luhn(t *testing.T) {
+	assert.Equal(t, "3", Luhn("7992739871"))
+}
+
+func Test_Mod11(t *testing.T) {
+	//1*7 + 2*6 + 3*5 + 4*4 + 5*3 + 6*2 = 77, remainder 0
+	assert.Equal(t, "0", Mod11("123456"))
+	//the weight starts again from 2 after 7: 1*2 + 0*7 + ... = 2, 11 - 2 = 9
+	assert.Equal(t, "9", Mod11("1000000"))
+}
+
+func Test_generator_Number(t *testing.T) {
+	g := &generator{
+		banks: map[string]*Bank{
+			"bca": {Code: "bca", Prefix: "39358", Length: 16, CheckDigit: CheckDigitLuhn},
+			"bni": {Code: "bni", Prefix: "988", Length: 16, CheckDigit: CheckDigitMod11},
+			"bri": {Code: "bri", Prefix: "12345", Length: 15, CheckDigit: CheckDigitNone},
+		},
+		codes: []string{"bca", "bni", "bri"},
+	}
+
+	tests := []struct {
+		name     string
+		bankCode string
+		wantErr  error
+	}{
+		{
+			name: "given bank with luhn," +
+				"when number," +
+				"then return valid number with prefix",
+			bankCode: "bca",
+		},
+		{
+			name: "given bank with mod11," +
+				"when number," +
+				"then return valid number with prefix",
+			bankCode: "BNI",
+		},
+		{
+			name: "given bank without check digit," +
+				"when number," +
+				"then return valid number with prefix",
+			bankCode: "bri",
+		},
+		{
+			name: "given unknown bank," +
+				"when number," +
+				"then return error",
+			bankCode: "xyz",
+			wantErr:  ErrorUnknownBank,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := g.Number(tt.bankCode, "f02b5a3f-692e-4c33-8ebd-5cc14afead73", 0)
+				assert.Equal(t, tt.wantErr, err)
+
+				if tt.wantErr != nil {
+					return
+				}
+
+				bank, errValidate := g.Validate(tt.bankCode, got)
+				assert.Nil(t, errValidate)
+				assert.Len(t, got, bank.Length)
+				assert.True(t, strings.HasPrefix(got, bank.Prefix))
+
+				again, _ := g.Number(tt.bankCode, "f02b5a3f-692e-4c33-8ebd-5cc14afead73", 0)
+				assert.Equal(t, got, again)
+
+				next, _ := g.Number(tt.bankCode, "f02b5a3f-692e-4c33-8ebd-5cc14afead73", 1)
+				assert.NotEqual(t, got, next)
+			})
+	}
+}
+
+func Test_generator_Validate(t *testing.T) {
+	g := &generator{
+		banks: map[string]*Bank{
+			"bca": {Code: "bca", Prefix: "39358", Length: 16, CheckDigit: CheckDigitLuhn},
+		},
+		codes: []string{"bca"},
+	}
+
+	number, _ := g.Number("bca", "abc", 0)
+	wrongDigit := number[:len(number)-1] + Luhn(number[:len(number)-1]+"1")
+
+	tests := []struct {
+		name    string
+		number  string
+		wantErr error
+	}{
+		{
+			name: "given issued number," +
+				"when validate," +
+				"then return the bank",
+			number: number,
+		},
+		{
+			name: "given wrong check digit," +
+				"when validate," +
+				"then return error",
+			number:  wrongDigit,
+			wantErr: ErrorInvalidNumber,
+		},
+		{
+			name: "given other prefix," +
+				"when validate," +
+				"then return error",
+			number:  "1" + number[1:],
+			wantErr: ErrorInvalidNumber,
+		},
+		{
+			name: "given too short," +
+				"when validate," +
+				"then return error",
+			number:  number[:10],
+			wantErr: ErrorInvalidNumber,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, err := g.Validate("bca", tt.number)
+				assert.Equal(t, tt.wantErr, err)
+			})
+	}
+}
@@ -0,0 +1,77 @@
+package virtualaccount
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"gitlab.com/2024/Juni/amartha-billing-srv2/configuration"
+)
+
+// the check digit rules of the bank, appended as the last digit of the number.
+const (
+	CheckDigitNone  = "none"
+	CheckDigitLuhn  = "luhn"
+	CheckDigitMod11 = "mod11"
+)
+
+var (
+	ErrorUnknownBank   = errors.New("unknown bank of virtual account")
+	ErrorInvalidNumber = errors.New("invalid virtual account number")
+)
+
+type (
+	Bank struct {
+		Code string `json:"code"`
+		// Prefix is the company code registered at the bank, the number always starts with it.
+		Prefix string `json:"prefix"`
+		// Length is the total digits of the number, including prefix and check digit.
+		Length     int    `json:"length"`
+		CheckDigit string `json:"check_digit"`
+	}
+
+	// Generator assigns the deterministic virtual account number, the same key and attempt
+	// always produce the same number. The next attempt is used when the number is already taken.
+	Generator interface {
+		Banks() []*Bank
+
+		Number(bankCode, key string, attempt int) (string, error)
+
+		// Validate returns the bank of the number, the prefix, length and check digit should match.
+		Validate(bankCode, number string) (*Bank, error)
+	}
+)
+
+// NewGenerator reads the banks of "virtualaccount.banks" (comma separated bank code),
+// the misconfigured bank is skipped.
+func NewGenerator(cfg configuration.Configuration) Generator {
+	banks := make(map[string]*Bank)
+	var codes []string
+
+	for _, code := range strings.Split(cfg.GetString("virtualaccount.banks"), ",") {
+		code = strings.ToLower(strings.TrimSpace(code))
+		if code == "" {
+			continue
+		}
+
+		bank := &Bank{
+			Code:       code,
+			Prefix:     cfg.GetString("virtualaccount.bank." + code + ".prefix"),
+			Length:     int(cfg.GetInt("virtualaccount.bank." + code + ".length")),
+			CheckDigit: cfg.GetString("virtualaccount.bank." + code + ".check.digit"),
+		}
+
+		if err := validateBank(bank); err != nil {
+			log.Println("skip virtual account bank -> ", code, err)
+			continue
+		}
+
+		banks[code] = bank
+		codes = append(codes, code)
+	}
+
+	return &generator{
+		banks: banks,
+		codes: codes,
+	}
+}
@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	maxDrainBody = 4096
+)
+
+type httpSender struct {
+	client *http.Client
+}
+
+func NewHttpSender(timeout time.Duration) Sender {
+	return &httpSender{
+		client: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+func (h *httpSender) Send(ctx context.Context, request *Request) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, request.URL, bytes.NewReader(request.Body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range request.Headers {
+		req.Header.Set(key, value)
+	}
+
+	res, err := h.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	//drain the body, so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, maxDrainBody))
+
+	return res.StatusCode, nil
+}
@@ -0,0 +1,19 @@
+package webhook
+
+import (
+	"context"
+)
+
+type (
+	Request struct {
+		URL     string
+		Headers map[string]string
+		Body    []byte
+	}
+
+	// Sender sends the webhook to the partner, status code is returned even when the partner
+	// responds with non 2xx, error is only for the failure of sending (e.g. timeout).
+	Sender interface {
+		Send(ctx context.Context, request *Request) (int, error)
+	}
+)
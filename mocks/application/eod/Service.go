@@ -0,0 +1,149 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+	eod "gitlab.com/2024/Juni/amartha-billing-srv2/application/eod"
+)
+
+// Service is an autogenerated mock type for the Service type
+type Service struct {
+	mock.Mock
+}
+
+// AdvanceBusinessDate provides a mock function with given fields: ctx, request
+func (_m *Service) AdvanceBusinessDate(ctx context.Context, request *eod.AdvanceRequest) (*eod.BusinessDateResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AdvanceBusinessDate")
+	}
+
+	var r0 *eod.BusinessDateResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *eod.AdvanceRequest) (*eod.BusinessDateResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *eod.AdvanceRequest) *eod.BusinessDateResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*eod.BusinessDateResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *eod.AdvanceRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BusinessDate provides a mock function with given fields: ctx
+func (_m *Service) BusinessDate(ctx context.Context) (*eod.BusinessDateResponse, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BusinessDate")
+	}
+
+	var r0 *eod.BusinessDateResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*eod.BusinessDateResponse, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *eod.BusinessDateResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*eod.BusinessDateResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Close provides a mock function with given fields: ctx, request
+func (_m *Service) Close(ctx context.Context, request *eod.CloseRequest) (*eod.RunResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Close")
+	}
+
+	var r0 *eod.RunResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *eod.CloseRequest) (*eod.RunResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *eod.CloseRequest) *eod.RunResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*eod.RunResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *eod.CloseRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindRuns provides a mock function with given fields: ctx, request
+func (_m *Service) FindRuns(ctx context.Context, request *eod.RunsRequest) ([]*eod.RunResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindRuns")
+	}
+
+	var r0 []*eod.RunResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *eod.RunsRequest) ([]*eod.RunResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *eod.RunsRequest) []*eod.RunResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*eod.RunResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *eod.RunsRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewService creates a new instance of Service. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Service {
+	mock := &Service{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
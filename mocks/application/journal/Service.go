@@ -0,0 +1,89 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+	journal "gitlab.com/2024/Juni/amartha-billing-srv2/application/journal"
+)
+
+// Service is an autogenerated mock type for the Service type
+type Service struct {
+	mock.Mock
+}
+
+// ExportGeneralLedger provides a mock function with given fields: ctx, request
+func (_m *Service) ExportGeneralLedger(ctx context.Context, request *journal.ExportRequest) (*journal.ExportResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportGeneralLedger")
+	}
+
+	var r0 *journal.ExportResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *journal.ExportRequest) (*journal.ExportResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *journal.ExportRequest) *journal.ExportResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*journal.ExportResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *journal.ExportRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindBalances provides a mock function with given fields: ctx, request
+func (_m *Service) FindBalances(ctx context.Context, request *journal.BalanceRequest) (*journal.BalanceResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindBalances")
+	}
+
+	var r0 *journal.BalanceResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *journal.BalanceRequest) (*journal.BalanceResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *journal.BalanceRequest) *journal.BalanceResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*journal.BalanceResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *journal.BalanceRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewService creates a new instance of Service. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Service {
+	mock := &Service{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
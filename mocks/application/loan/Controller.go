@@ -13,16 +13,46 @@ type Controller struct {
 	mock.Mock
 }
 
+// CreateQris provides a mock function with given fields: writer, req
+func (_m *Controller) CreateQris(writer http.ResponseWriter, req *http.Request) {
+	_m.Called(writer, req)
+}
+
 // FindOutstanding provides a mock function with given fields: writer, req
 func (_m *Controller) FindOutstanding(writer http.ResponseWriter, req *http.Request) {
 	_m.Called(writer, req)
 }
 
+// FindPayment provides a mock function with given fields: writer, req
+func (_m *Controller) FindPayment(writer http.ResponseWriter, req *http.Request) {
+	_m.Called(writer, req)
+}
+
 // Payment provides a mock function with given fields: writer, req
 func (_m *Controller) Payment(writer http.ResponseWriter, req *http.Request) {
 	_m.Called(writer, req)
 }
 
+// PayoffQuote provides a mock function with given fields: writer, req
+func (_m *Controller) PayoffQuote(writer http.ResponseWriter, req *http.Request) {
+	_m.Called(writer, req)
+}
+
+// Restructure provides a mock function with given fields: writer, req
+func (_m *Controller) Restructure(writer http.ResponseWriter, req *http.Request) {
+	_m.Called(writer, req)
+}
+
+// ReversePayment provides a mock function with given fields: writer, req
+func (_m *Controller) ReversePayment(writer http.ResponseWriter, req *http.Request) {
+	_m.Called(writer, req)
+}
+
+// WriteOff provides a mock function with given fields: writer, req
+func (_m *Controller) WriteOff(writer http.ResponseWriter, req *http.Request) {
+	_m.Called(writer, req)
+}
+
 // NewController creates a new instance of Controller. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewController(t interface {
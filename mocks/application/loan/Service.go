@@ -7,6 +7,7 @@ import (
 
 	mock "github.com/stretchr/testify/mock"
 	loan "gitlab.com/2024/Juni/amartha-billing-srv2/application/loan"
+	gateway "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/gateway"
 )
 
 // Service is an autogenerated mock type for the Service type
@@ -14,6 +15,36 @@ type Service struct {
 	mock.Mock
 }
 
+// CreateQris provides a mock function with given fields: ctx, qrisRequest
+func (_m *Service) CreateQris(ctx context.Context, qrisRequest *loan.QrisRequest) (*loan.QrisResponse, error) {
+	ret := _m.Called(ctx, qrisRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateQris")
+	}
+
+	var r0 *loan.QrisResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *loan.QrisRequest) (*loan.QrisResponse, error)); ok {
+		return rf(ctx, qrisRequest)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *loan.QrisRequest) *loan.QrisResponse); ok {
+		r0 = rf(ctx, qrisRequest)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*loan.QrisResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *loan.QrisRequest) error); ok {
+		r1 = rf(ctx, qrisRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FetchOutstanding provides a mock function with given fields: ctx, uid
 func (_m *Service) FetchOutstanding(ctx context.Context, uid string) (*loan.FetchOutstandingResponse, error) {
 	ret := _m.Called(ctx, uid)
@@ -44,17 +75,167 @@ func (_m *Service) FetchOutstanding(ctx context.Context, uid string) (*loan.Fetc
 	return r0, r1
 }
 
+// FindPayment provides a mock function with given fields: ctx, paymentID
+func (_m *Service) FindPayment(ctx context.Context, paymentID string) (*loan.PaymentResponse, error) {
+	ret := _m.Called(ctx, paymentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindPayment")
+	}
+
+	var r0 *loan.PaymentResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*loan.PaymentResponse, error)); ok {
+		return rf(ctx, paymentID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *loan.PaymentResponse); ok {
+		r0 = rf(ctx, paymentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*loan.PaymentResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, paymentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Payment provides a mock function with given fields: ctx, paymentRequest
-func (_m *Service) Payment(ctx context.Context, paymentRequest *loan.PaymentRequest) error {
+func (_m *Service) Payment(ctx context.Context, paymentRequest *loan.PaymentRequest) (*loan.PaymentResponse, error) {
 	ret := _m.Called(ctx, paymentRequest)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Payment")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *loan.PaymentRequest) error); ok {
+	var r0 *loan.PaymentResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *loan.PaymentRequest) (*loan.PaymentResponse, error)); ok {
+		return rf(ctx, paymentRequest)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *loan.PaymentRequest) *loan.PaymentResponse); ok {
 		r0 = rf(ctx, paymentRequest)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*loan.PaymentResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *loan.PaymentRequest) error); ok {
+		r1 = rf(ctx, paymentRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PayoffQuote provides a mock function with given fields: ctx, uid
+func (_m *Service) PayoffQuote(ctx context.Context, uid string) (*loan.PayoffQuoteResponse, error) {
+	ret := _m.Called(ctx, uid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PayoffQuote")
+	}
+
+	var r0 *loan.PayoffQuoteResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*loan.PayoffQuoteResponse, error)); ok {
+		return rf(ctx, uid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *loan.PayoffQuoteResponse); ok {
+		r0 = rf(ctx, uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*loan.PayoffQuoteResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restructure provides a mock function with given fields: ctx, restructureRequest
+func (_m *Service) Restructure(ctx context.Context, restructureRequest *loan.RestructureRequest) (*loan.RestructureResponse, error) {
+	ret := _m.Called(ctx, restructureRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Restructure")
+	}
+
+	var r0 *loan.RestructureResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *loan.RestructureRequest) (*loan.RestructureResponse, error)); ok {
+		return rf(ctx, restructureRequest)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *loan.RestructureRequest) *loan.RestructureResponse); ok {
+		r0 = rf(ctx, restructureRequest)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*loan.RestructureResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *loan.RestructureRequest) error); ok {
+		r1 = rf(ctx, restructureRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReversePayment provides a mock function with given fields: ctx, reversalRequest
+func (_m *Service) ReversePayment(ctx context.Context, reversalRequest *loan.ReversalRequest) (*loan.ReversalResponse, error) {
+	ret := _m.Called(ctx, reversalRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReversePayment")
+	}
+
+	var r0 *loan.ReversalResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *loan.ReversalRequest) (*loan.ReversalResponse, error)); ok {
+		return rf(ctx, reversalRequest)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *loan.ReversalRequest) *loan.ReversalResponse); ok {
+		r0 = rf(ctx, reversalRequest)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*loan.ReversalResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *loan.ReversalRequest) error); ok {
+		r1 = rf(ctx, reversalRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SettlePayment provides a mock function with given fields: ctx, charge
+func (_m *Service) SettlePayment(ctx context.Context, charge *gateway.Charge) error {
+	ret := _m.Called(ctx, charge)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SettlePayment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *gateway.Charge) error); ok {
+		r0 = rf(ctx, charge)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -62,6 +243,36 @@ func (_m *Service) Payment(ctx context.Context, paymentRequest *loan.PaymentRequ
 	return r0
 }
 
+// WriteOff provides a mock function with given fields: ctx, writeOffRequest
+func (_m *Service) WriteOff(ctx context.Context, writeOffRequest *loan.WriteOffRequest) (*loan.WriteOffResponse, error) {
+	ret := _m.Called(ctx, writeOffRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WriteOff")
+	}
+
+	var r0 *loan.WriteOffResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *loan.WriteOffRequest) (*loan.WriteOffResponse, error)); ok {
+		return rf(ctx, writeOffRequest)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *loan.WriteOffRequest) *loan.WriteOffResponse); ok {
+		r0 = rf(ctx, writeOffRequest)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*loan.WriteOffResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *loan.WriteOffRequest) error); ok {
+		r1 = rf(ctx, writeOffRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewService creates a new instance of Service. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewService(t interface {
@@ -0,0 +1,167 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+	product "gitlab.com/2024/Juni/amartha-billing-srv2/application/product"
+)
+
+// Service is an autogenerated mock type for the Service type
+type Service struct {
+	mock.Mock
+}
+
+// CreateProduct provides a mock function with given fields: ctx, request
+func (_m *Service) CreateProduct(ctx context.Context, request *product.ProductRequest) (*product.ProductResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateProduct")
+	}
+
+	var r0 *product.ProductResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *product.ProductRequest) (*product.ProductResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *product.ProductRequest) *product.ProductResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*product.ProductResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *product.ProductRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindProduct provides a mock function with given fields: ctx, code, version
+func (_m *Service) FindProduct(ctx context.Context, code string, version int) (*product.ProductResponse, error) {
+	ret := _m.Called(ctx, code, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindProduct")
+	}
+
+	var r0 *product.ProductResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) (*product.ProductResponse, error)); ok {
+		return rf(ctx, code, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) *product.ProductResponse); ok {
+		r0 = rf(ctx, code, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*product.ProductResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, code, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindProducts provides a mock function with given fields: ctx, status
+func (_m *Service) FindProducts(ctx context.Context, status string) ([]*product.ProductResponse, error) {
+	ret := _m.Called(ctx, status)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindProducts")
+	}
+
+	var r0 []*product.ProductResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*product.ProductResponse, error)); ok {
+		return rf(ctx, status)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*product.ProductResponse); ok {
+		r0 = rf(ctx, status)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*product.ProductResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, status)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RetireProduct provides a mock function with given fields: ctx, code
+func (_m *Service) RetireProduct(ctx context.Context, code string) error {
+	ret := _m.Called(ctx, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetireProduct")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, code)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateProduct provides a mock function with given fields: ctx, request
+func (_m *Service) UpdateProduct(ctx context.Context, request *product.ProductRequest) (*product.ProductResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateProduct")
+	}
+
+	var r0 *product.ProductResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *product.ProductRequest) (*product.ProductResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *product.ProductRequest) *product.ProductResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*product.ProductResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *product.ProductRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewService creates a new instance of Service. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Service {
+	mock := &Service{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
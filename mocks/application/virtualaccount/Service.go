@@ -0,0 +1,152 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+	virtualaccount "gitlab.com/2024/Juni/amartha-billing-srv2/application/virtualaccount"
+)
+
+// Service is an autogenerated mock type for the Service type
+type Service struct {
+	mock.Mock
+}
+
+// Credit provides a mock function with given fields: ctx, request
+func (_m *Service) Credit(ctx context.Context, request *virtualaccount.CreditRequest) (*virtualaccount.CreditResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Credit")
+	}
+
+	var r0 *virtualaccount.CreditResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *virtualaccount.CreditRequest) (*virtualaccount.CreditResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *virtualaccount.CreditRequest) *virtualaccount.CreditResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*virtualaccount.CreditResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *virtualaccount.CreditRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Issue provides a mock function with given fields: ctx, request
+func (_m *Service) Issue(ctx context.Context, request *virtualaccount.IssueRequest) ([]*virtualaccount.VirtualAccountResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Issue")
+	}
+
+	var r0 []*virtualaccount.VirtualAccountResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *virtualaccount.IssueRequest) ([]*virtualaccount.VirtualAccountResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *virtualaccount.IssueRequest) []*virtualaccount.VirtualAccountResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*virtualaccount.VirtualAccountResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *virtualaccount.IssueRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Lookup provides a mock function with given fields: ctx, request
+func (_m *Service) Lookup(ctx context.Context, request *virtualaccount.LookupRequest) (*virtualaccount.LookupResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Lookup")
+	}
+
+	var r0 *virtualaccount.LookupResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *virtualaccount.LookupRequest) (*virtualaccount.LookupResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *virtualaccount.LookupRequest) *virtualaccount.LookupResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*virtualaccount.LookupResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *virtualaccount.LookupRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReclaimCredits provides a mock function with given fields: ctx
+func (_m *Service) ReclaimCredits(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReclaimCredits")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Run provides a mock function with given fields: ctx
+func (_m *Service) Run(ctx context.Context) {
+	_m.Called(ctx)
+}
+
+// NewService creates a new instance of Service. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Service {
+	mock := &Service{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
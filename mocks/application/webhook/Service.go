@@ -0,0 +1,189 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+	webhook "gitlab.com/2024/Juni/amartha-billing-srv2/application/webhook"
+	publisher "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/publisher"
+)
+
+// Service is an autogenerated mock type for the Service type
+type Service struct {
+	mock.Mock
+}
+
+// CreateSubscription provides a mock function with given fields: ctx, request
+func (_m *Service) CreateSubscription(ctx context.Context, request *webhook.SubscriptionRequest) (*webhook.SubscriptionResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSubscription")
+	}
+
+	var r0 *webhook.SubscriptionResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *webhook.SubscriptionRequest) (*webhook.SubscriptionResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *webhook.SubscriptionRequest) *webhook.SubscriptionResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*webhook.SubscriptionResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *webhook.SubscriptionRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DispatchOnce provides a mock function with given fields: ctx
+func (_m *Service) DispatchOnce(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DispatchOnce")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindDeliveries provides a mock function with given fields: ctx, request
+func (_m *Service) FindDeliveries(ctx context.Context, request *webhook.FindDeliveriesRequest) ([]*webhook.DeliveryResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindDeliveries")
+	}
+
+	var r0 []*webhook.DeliveryResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *webhook.FindDeliveriesRequest) ([]*webhook.DeliveryResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *webhook.FindDeliveriesRequest) []*webhook.DeliveryResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*webhook.DeliveryResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *webhook.FindDeliveriesRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindSubscriptions provides a mock function with given fields: ctx
+func (_m *Service) FindSubscriptions(ctx context.Context) ([]*webhook.SubscriptionResponse, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindSubscriptions")
+	}
+
+	var r0 []*webhook.SubscriptionResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*webhook.SubscriptionResponse, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*webhook.SubscriptionResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*webhook.SubscriptionResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Publish provides a mock function with given fields: ctx, message
+func (_m *Service) Publish(ctx context.Context, message *publisher.Message) error {
+	ret := _m.Called(ctx, message)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Publish")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *publisher.Message) error); ok {
+		r0 = rf(ctx, message)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReplayDelivery provides a mock function with given fields: ctx, deliveryID
+func (_m *Service) ReplayDelivery(ctx context.Context, deliveryID string) error {
+	ret := _m.Called(ctx, deliveryID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplayDelivery")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, deliveryID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Run provides a mock function with given fields: ctx
+func (_m *Service) Run(ctx context.Context) {
+	_m.Called(ctx)
+}
+
+// NewService creates a new instance of Service. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Service {
+	mock := &Service{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
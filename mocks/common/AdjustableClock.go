@@ -0,0 +1,82 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AdjustableClock is an autogenerated mock type for the AdjustableClock type
+type AdjustableClock struct {
+	mock.Mock
+}
+
+// Advance provides a mock function with given fields: d
+func (_m *AdjustableClock) Advance(d time.Duration) time.Time {
+	ret := _m.Called(d)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Advance")
+	}
+
+	var r0 time.Time
+	if rf, ok := ret.Get(0).(func(time.Duration) time.Time); ok {
+		r0 = rf(d)
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+
+	return r0
+}
+
+// Mode provides a mock function with given fields:
+func (_m *AdjustableClock) Mode() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Mode")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// Now provides a mock function with given fields:
+func (_m *AdjustableClock) Now() time.Time {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Now")
+	}
+
+	var r0 time.Time
+	if rf, ok := ret.Get(0).(func() time.Time); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+
+	return r0
+}
+
+// NewAdjustableClock creates a new instance of AdjustableClock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAdjustableClock(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AdjustableClock {
+	mock := &AdjustableClock{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
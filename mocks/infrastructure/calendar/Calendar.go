@@ -0,0 +1,118 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Calendar is an autogenerated mock type for the Calendar type
+type Calendar struct {
+	mock.Mock
+}
+
+// Adjust provides a mock function with given fields: date
+func (_m *Calendar) Adjust(date time.Time) time.Time {
+	ret := _m.Called(date)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Adjust")
+	}
+
+	var r0 time.Time
+	if rf, ok := ret.Get(0).(func(time.Time) time.Time); ok {
+		r0 = rf(date)
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+
+	return r0
+}
+
+// IsBusinessDay provides a mock function with given fields: date
+func (_m *Calendar) IsBusinessDay(date time.Time) bool {
+	ret := _m.Called(date)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsBusinessDay")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(time.Time) bool); ok {
+		r0 = rf(date)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// LateBefore provides a mock function with given fields: day
+func (_m *Calendar) LateBefore(day time.Time) time.Time {
+	ret := _m.Called(day)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LateBefore")
+	}
+
+	var r0 time.Time
+	if rf, ok := ret.Get(0).(func(time.Time) time.Time); ok {
+		r0 = rf(day)
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+
+	return r0
+}
+
+// NextBusinessDay provides a mock function with given fields: date
+func (_m *Calendar) NextBusinessDay(date time.Time) time.Time {
+	ret := _m.Called(date)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NextBusinessDay")
+	}
+
+	var r0 time.Time
+	if rf, ok := ret.Get(0).(func(time.Time) time.Time); ok {
+		r0 = rf(date)
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+
+	return r0
+}
+
+// Region provides a mock function with given fields:
+func (_m *Calendar) Region() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Region")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// NewCalendar creates a new instance of Calendar. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCalendar(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Calendar {
+	mock := &Calendar{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,168 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	http "net/http"
+
+	mock "github.com/stretchr/testify/mock"
+	gateway "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/gateway"
+)
+
+// PaymentGateway is an autogenerated mock type for the PaymentGateway type
+type PaymentGateway struct {
+	mock.Mock
+}
+
+// Charge provides a mock function with given fields: ctx, request
+func (_m *PaymentGateway) Charge(ctx context.Context, request *gateway.ChargeRequest) (*gateway.Charge, error) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Charge")
+	}
+
+	var r0 *gateway.Charge
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *gateway.ChargeRequest) (*gateway.Charge, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *gateway.ChargeRequest) *gateway.Charge); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*gateway.Charge)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *gateway.ChargeRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ParseCallback provides a mock function with given fields: header, body
+func (_m *PaymentGateway) ParseCallback(header http.Header, body []byte) (*gateway.Charge, error) {
+	ret := _m.Called(header, body)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ParseCallback")
+	}
+
+	var r0 *gateway.Charge
+	var r1 error
+	if rf, ok := ret.Get(0).(func(http.Header, []byte) (*gateway.Charge, error)); ok {
+		return rf(header, body)
+	}
+	if rf, ok := ret.Get(0).(func(http.Header, []byte) *gateway.Charge); ok {
+		r0 = rf(header, body)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*gateway.Charge)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(http.Header, []byte) error); ok {
+		r1 = rf(header, body)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Provider provides a mock function with given fields:
+func (_m *PaymentGateway) Provider() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Provider")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// Refund provides a mock function with given fields: ctx, request
+func (_m *PaymentGateway) Refund(ctx context.Context, request *gateway.RefundRequest) (*gateway.Refund, error) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Refund")
+	}
+
+	var r0 *gateway.Refund
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *gateway.RefundRequest) (*gateway.Refund, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *gateway.RefundRequest) *gateway.Refund); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*gateway.Refund)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *gateway.RefundRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Status provides a mock function with given fields: ctx, reference
+func (_m *PaymentGateway) Status(ctx context.Context, reference string) (*gateway.Charge, error) {
+	ret := _m.Called(ctx, reference)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Status")
+	}
+
+	var r0 *gateway.Charge
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*gateway.Charge, error)); ok {
+		return rf(ctx, reference)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *gateway.Charge); ok {
+		r0 = rf(ctx, reference)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*gateway.Charge)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, reference)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewPaymentGateway creates a new instance of PaymentGateway. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPaymentGateway(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PaymentGateway {
+	mock := &PaymentGateway{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,59 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+	ratelimit "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/ratelimit"
+)
+
+// Store is an autogenerated mock type for the Store type
+type Store struct {
+	mock.Mock
+}
+
+// Take provides a mock function with given fields: ctx, key, limit
+func (_m *Store) Take(ctx context.Context, key string, limit ratelimit.Limit) (*ratelimit.Decision, error) {
+	ret := _m.Called(ctx, key, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Take")
+	}
+
+	var r0 *ratelimit.Decision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ratelimit.Limit) (*ratelimit.Decision, error)); ok {
+		return rf(ctx, key, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ratelimit.Limit) *ratelimit.Decision); ok {
+		r0 = rf(ctx, key, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ratelimit.Decision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ratelimit.Limit) error); ok {
+		r1 = rf(ctx, key, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewStore creates a new instance of Store. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Store {
+	mock := &Store{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
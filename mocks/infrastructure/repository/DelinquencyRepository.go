@@ -0,0 +1,185 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	sql "database/sql"
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+	repository "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+// DelinquencyRepository is an autogenerated mock type for the DelinquencyRepository type
+type DelinquencyRepository struct {
+	mock.Mock
+}
+
+// FindDelinquency provides a mock function with given fields: ctx, userID
+func (_m *DelinquencyRepository) FindDelinquency(ctx context.Context, userID string) (*repository.DelinquencyEntity, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindDelinquency")
+	}
+
+	var r0 *repository.DelinquencyEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*repository.DelinquencyEntity, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *repository.DelinquencyEntity); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.DelinquencyEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindSnapshotDueDates provides a mock function with given fields: ctx, tx, snapshot
+func (_m *DelinquencyRepository) FindSnapshotDueDates(ctx context.Context, tx *sql.Tx, snapshot *repository.DelinquencySnapshot) ([]*repository.SnapshotDueDate, error) {
+	ret := _m.Called(ctx, tx, snapshot)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindSnapshotDueDates")
+	}
+
+	var r0 []*repository.SnapshotDueDate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, *repository.DelinquencySnapshot) ([]*repository.SnapshotDueDate, error)); ok {
+		return rf(ctx, tx, snapshot)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, *repository.DelinquencySnapshot) []*repository.SnapshotDueDate); ok {
+		r0 = rf(ctx, tx, snapshot)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*repository.SnapshotDueDate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sql.Tx, *repository.DelinquencySnapshot) error); ok {
+		r1 = rf(ctx, tx, snapshot)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindSnapshotTransitions provides a mock function with given fields: ctx, tx, businessDate
+func (_m *DelinquencyRepository) FindSnapshotTransitions(ctx context.Context, tx *sql.Tx, businessDate time.Time) ([]*repository.DelinquencyTransition, error) {
+	ret := _m.Called(ctx, tx, businessDate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindSnapshotTransitions")
+	}
+
+	var r0 []*repository.DelinquencyTransition
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, time.Time) ([]*repository.DelinquencyTransition, error)); ok {
+		return rf(ctx, tx, businessDate)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, time.Time) []*repository.DelinquencyTransition); ok {
+		r0 = rf(ctx, tx, businessDate)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*repository.DelinquencyTransition)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sql.Tx, time.Time) error); ok {
+		r1 = rf(ctx, tx, businessDate)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveSnapshot provides a mock function with given fields: ctx, tx, snapshot
+func (_m *DelinquencyRepository) SaveSnapshot(ctx context.Context, tx *sql.Tx, snapshot *repository.DelinquencySnapshot) (int, error) {
+	ret := _m.Called(ctx, tx, snapshot)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveSnapshot")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, *repository.DelinquencySnapshot) (int, error)); ok {
+		return rf(ctx, tx, snapshot)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, *repository.DelinquencySnapshot) int); ok {
+		r0 = rf(ctx, tx, snapshot)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sql.Tx, *repository.DelinquencySnapshot) error); ok {
+		r1 = rf(ctx, tx, snapshot)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateSnapshotDaysPastDue provides a mock function with given fields: ctx, tx, businessDate, daysPastDue, userIDs
+func (_m *DelinquencyRepository) UpdateSnapshotDaysPastDue(ctx context.Context, tx *sql.Tx, businessDate time.Time, daysPastDue int, userIDs []string) error {
+	ret := _m.Called(ctx, tx, businessDate, daysPastDue, userIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSnapshotDaysPastDue")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, time.Time, int, []string) error); ok {
+		r0 = rf(ctx, tx, businessDate, daysPastDue, userIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpsertDelinquency provides a mock function with given fields: ctx, tx, delinquency
+func (_m *DelinquencyRepository) UpsertDelinquency(ctx context.Context, tx *sql.Tx, delinquency *repository.DelinquencyEntity) error {
+	ret := _m.Called(ctx, tx, delinquency)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertDelinquency")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, *repository.DelinquencyEntity) error); ok {
+		r0 = rf(ctx, tx, delinquency)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewDelinquencyRepository creates a new instance of DelinquencyRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDelinquencyRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *DelinquencyRepository {
+	mock := &DelinquencyRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
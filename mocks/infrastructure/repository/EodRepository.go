@@ -0,0 +1,143 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+	repository "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+// EodRepository is an autogenerated mock type for the EodRepository type
+type EodRepository struct {
+	mock.Mock
+}
+
+// FindRuns provides a mock function with given fields: ctx, filter
+func (_m *EodRepository) FindRuns(ctx context.Context, filter *repository.EodRunFilter) ([]*repository.EodRunEntity, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindRuns")
+	}
+
+	var r0 []*repository.EodRunEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.EodRunFilter) ([]*repository.EodRunEntity, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.EodRunFilter) []*repository.EodRunEntity); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*repository.EodRunEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *repository.EodRunFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LockRun provides a mock function with given fields: ctx
+func (_m *EodRepository) LockRun(ctx context.Context) (func(), error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LockRun")
+	}
+
+	var r0 func()
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (func(), error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) func()); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(func())
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveRun provides a mock function with given fields: ctx, run
+func (_m *EodRepository) SaveRun(ctx context.Context, run *repository.EodRunEntity) error {
+	ret := _m.Called(ctx, run)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveRun")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.EodRunEntity) error); ok {
+		r0 = rf(ctx, run)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveStep provides a mock function with given fields: ctx, step
+func (_m *EodRepository) SaveStep(ctx context.Context, step *repository.EodStepEntity) error {
+	ret := _m.Called(ctx, step)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveStep")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.EodStepEntity) error); ok {
+		r0 = rf(ctx, step)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateRun provides a mock function with given fields: ctx, run
+func (_m *EodRepository) UpdateRun(ctx context.Context, run *repository.EodRunEntity) error {
+	ret := _m.Called(ctx, run)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateRun")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.EodRunEntity) error); ok {
+		r0 = rf(ctx, run)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewEodRepository creates a new instance of EodRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewEodRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *EodRepository {
+	mock := &EodRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
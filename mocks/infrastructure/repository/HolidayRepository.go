@@ -0,0 +1,59 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+	repository "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+// HolidayRepository is an autogenerated mock type for the HolidayRepository type
+type HolidayRepository struct {
+	mock.Mock
+}
+
+// FindHolidays provides a mock function with given fields: ctx, region
+func (_m *HolidayRepository) FindHolidays(ctx context.Context, region string) ([]*repository.HolidayEntity, error) {
+	ret := _m.Called(ctx, region)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindHolidays")
+	}
+
+	var r0 []*repository.HolidayEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*repository.HolidayEntity, error)); ok {
+		return rf(ctx, region)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*repository.HolidayEntity); ok {
+		r0 = rf(ctx, region)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*repository.HolidayEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, region)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewHolidayRepository creates a new instance of HolidayRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewHolidayRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *HolidayRepository {
+	mock := &HolidayRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,145 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	sql "database/sql"
+
+	mock "github.com/stretchr/testify/mock"
+	repository "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+// JournalRepository is an autogenerated mock type for the JournalRepository type
+type JournalRepository struct {
+	mock.Mock
+}
+
+// FindBalances provides a mock function with given fields: ctx, filter
+func (_m *JournalRepository) FindBalances(ctx context.Context, filter *repository.JournalBalanceFilter) ([]*repository.AccountBalanceEntity, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindBalances")
+	}
+
+	var r0 []*repository.AccountBalanceEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.JournalBalanceFilter) ([]*repository.AccountBalanceEntity, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.JournalBalanceFilter) []*repository.AccountBalanceEntity); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*repository.AccountBalanceEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *repository.JournalBalanceFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindEntries provides a mock function with given fields: ctx, filter
+func (_m *JournalRepository) FindEntries(ctx context.Context, filter *repository.JournalEntryFilter) ([]*repository.JournalEntryEntity, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindEntries")
+	}
+
+	var r0 []*repository.JournalEntryEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.JournalEntryFilter) ([]*repository.JournalEntryEntity, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.JournalEntryFilter) []*repository.JournalEntryEntity); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*repository.JournalEntryEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *repository.JournalEntryFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindTotals provides a mock function with given fields: ctx, filter
+func (_m *JournalRepository) FindTotals(ctx context.Context, filter *repository.JournalTotalFilter) ([]*repository.AccountTotalEntity, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindTotals")
+	}
+
+	var r0 []*repository.AccountTotalEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.JournalTotalFilter) ([]*repository.AccountTotalEntity, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.JournalTotalFilter) []*repository.AccountTotalEntity); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*repository.AccountTotalEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *repository.JournalTotalFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveEntries provides a mock function with given fields: ctx, tx, entries
+func (_m *JournalRepository) SaveEntries(ctx context.Context, tx *sql.Tx, entries ...*repository.JournalEntryEntity) error {
+	_va := make([]interface{}, len(entries))
+	for _i := range entries {
+		_va[_i] = entries[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, tx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveEntries")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, ...*repository.JournalEntryEntity) error); ok {
+		r0 = rf(ctx, tx, entries...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewJournalRepository creates a new instance of JournalRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewJournalRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *JournalRepository {
+	mock := &JournalRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -4,11 +4,10 @@ package mocks
 
 import (
 	context "context"
+	sql "database/sql"
 
 	mock "github.com/stretchr/testify/mock"
 	repository "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
-
-	sql "database/sql"
 )
 
 // LoanRepository is an autogenerated mock type for the LoanRepository type
@@ -16,6 +15,24 @@ type LoanRepository struct {
 	mock.Mock
 }
 
+// AccruePenalty provides a mock function with given fields: ctx, tx, accrual
+func (_m *LoanRepository) AccruePenalty(ctx context.Context, tx *sql.Tx, accrual *repository.LoanPenaltyAccrual) error {
+	ret := _m.Called(ctx, tx, accrual)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AccruePenalty")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, *repository.LoanPenaltyAccrual) error); ok {
+		r0 = rf(ctx, tx, accrual)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // FindLoans provides a mock function with given fields: ctx, loanEntity
 func (_m *LoanRepository) FindLoans(ctx context.Context, loanEntity *repository.LoanEntity) ([]*repository.LoanEntity, error) {
 	ret := _m.Called(ctx, loanEntity)
@@ -71,17 +88,17 @@ func (_m *LoanRepository) SaveLoans(ctx context.Context, tx *sql.Tx, loanEntity
 	return r0
 }
 
-// UpdateLoan provides a mock function with given fields: ctx, loanEntity
-func (_m *LoanRepository) UpdateLoan(ctx context.Context, loanEntity *repository.LoanEntityUpdate) error {
-	ret := _m.Called(ctx, loanEntity)
+// UpdateLoan provides a mock function with given fields: ctx, tx, loanEntity
+func (_m *LoanRepository) UpdateLoan(ctx context.Context, tx *sql.Tx, loanEntity *repository.LoanEntityUpdate) error {
+	ret := _m.Called(ctx, tx, loanEntity)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateLoan")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *repository.LoanEntityUpdate) error); ok {
-		r0 = rf(ctx, loanEntity)
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, *repository.LoanEntityUpdate) error); ok {
+		r0 = rf(ctx, tx, loanEntity)
 	} else {
 		r0 = ret.Error(0)
 	}
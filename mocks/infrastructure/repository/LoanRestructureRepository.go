@@ -0,0 +1,48 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	sql "database/sql"
+
+	mock "github.com/stretchr/testify/mock"
+	repository "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+// LoanRestructureRepository is an autogenerated mock type for the LoanRestructureRepository type
+type LoanRestructureRepository struct {
+	mock.Mock
+}
+
+// SaveRestructure provides a mock function with given fields: ctx, tx, restructure
+func (_m *LoanRestructureRepository) SaveRestructure(ctx context.Context, tx *sql.Tx, restructure *repository.LoanRestructureEntity) error {
+	ret := _m.Called(ctx, tx, restructure)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveRestructure")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, *repository.LoanRestructureEntity) error); ok {
+		r0 = rf(ctx, tx, restructure)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewLoanRestructureRepository creates a new instance of LoanRestructureRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLoanRestructureRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LoanRestructureRepository {
+	mock := &LoanRestructureRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
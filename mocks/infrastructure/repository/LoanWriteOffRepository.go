@@ -0,0 +1,48 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	sql "database/sql"
+
+	mock "github.com/stretchr/testify/mock"
+	repository "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+// LoanWriteOffRepository is an autogenerated mock type for the LoanWriteOffRepository type
+type LoanWriteOffRepository struct {
+	mock.Mock
+}
+
+// SaveWriteOff provides a mock function with given fields: ctx, tx, writeOff
+func (_m *LoanWriteOffRepository) SaveWriteOff(ctx context.Context, tx *sql.Tx, writeOff *repository.LoanWriteOffEntity) error {
+	ret := _m.Called(ctx, tx, writeOff)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveWriteOff")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, *repository.LoanWriteOffEntity) error); ok {
+		r0 = rf(ctx, tx, writeOff)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewLoanWriteOffRepository creates a new instance of LoanWriteOffRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLoanWriteOffRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LoanWriteOffRepository {
+	mock := &LoanWriteOffRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
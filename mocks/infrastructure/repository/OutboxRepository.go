@@ -0,0 +1,103 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	sql "database/sql"
+
+	mock "github.com/stretchr/testify/mock"
+	repository "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+// OutboxRepository is an autogenerated mock type for the OutboxRepository type
+type OutboxRepository struct {
+	mock.Mock
+}
+
+// FindOutbox provides a mock function with given fields: ctx, filter
+func (_m *OutboxRepository) FindOutbox(ctx context.Context, filter *repository.OutboxEntityFilter) ([]*repository.OutboxEntity, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindOutbox")
+	}
+
+	var r0 []*repository.OutboxEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.OutboxEntityFilter) ([]*repository.OutboxEntity, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.OutboxEntityFilter) []*repository.OutboxEntity); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*repository.OutboxEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *repository.OutboxEntityFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveOutbox provides a mock function with given fields: ctx, tx, outboxEntity
+func (_m *OutboxRepository) SaveOutbox(ctx context.Context, tx *sql.Tx, outboxEntity ...*repository.OutboxEntity) error {
+	_va := make([]interface{}, len(outboxEntity))
+	for _i := range outboxEntity {
+		_va[_i] = outboxEntity[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, tx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveOutbox")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, ...*repository.OutboxEntity) error); ok {
+		r0 = rf(ctx, tx, outboxEntity...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateOutbox provides a mock function with given fields: ctx, outboxEntity
+func (_m *OutboxRepository) UpdateOutbox(ctx context.Context, outboxEntity *repository.OutboxEntityUpdate) error {
+	ret := _m.Called(ctx, outboxEntity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateOutbox")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.OutboxEntityUpdate) error); ok {
+		r0 = rf(ctx, outboxEntity)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewOutboxRepository creates a new instance of OutboxRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOutboxRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OutboxRepository {
+	mock := &OutboxRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,95 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+	repository "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+// PaymentCallbackRepository is an autogenerated mock type for the PaymentCallbackRepository type
+type PaymentCallbackRepository struct {
+	mock.Mock
+}
+
+// FindCallbacks provides a mock function with given fields: ctx, filter
+func (_m *PaymentCallbackRepository) FindCallbacks(ctx context.Context, filter *repository.PaymentCallbackFilter) ([]*repository.PaymentCallbackEntity, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindCallbacks")
+	}
+
+	var r0 []*repository.PaymentCallbackEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.PaymentCallbackFilter) ([]*repository.PaymentCallbackEntity, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.PaymentCallbackFilter) []*repository.PaymentCallbackEntity); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*repository.PaymentCallbackEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *repository.PaymentCallbackFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveCallback provides a mock function with given fields: ctx, callback
+func (_m *PaymentCallbackRepository) SaveCallback(ctx context.Context, callback *repository.PaymentCallbackEntity) error {
+	ret := _m.Called(ctx, callback)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveCallback")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.PaymentCallbackEntity) error); ok {
+		r0 = rf(ctx, callback)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateCallback provides a mock function with given fields: ctx, callback
+func (_m *PaymentCallbackRepository) UpdateCallback(ctx context.Context, callback *repository.PaymentCallbackUpdate) error {
+	ret := _m.Called(ctx, callback)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCallback")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.PaymentCallbackUpdate) error); ok {
+		r0 = rf(ctx, callback)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewPaymentCallbackRepository creates a new instance of PaymentCallbackRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPaymentCallbackRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PaymentCallbackRepository {
+	mock := &PaymentCallbackRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
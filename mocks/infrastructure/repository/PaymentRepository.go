@@ -0,0 +1,96 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	sql "database/sql"
+
+	mock "github.com/stretchr/testify/mock"
+	repository "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+// PaymentRepository is an autogenerated mock type for the PaymentRepository type
+type PaymentRepository struct {
+	mock.Mock
+}
+
+// FindPayments provides a mock function with given fields: ctx, filter
+func (_m *PaymentRepository) FindPayments(ctx context.Context, filter *repository.PaymentFilter) ([]*repository.PaymentEntity, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindPayments")
+	}
+
+	var r0 []*repository.PaymentEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.PaymentFilter) ([]*repository.PaymentEntity, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.PaymentFilter) []*repository.PaymentEntity); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*repository.PaymentEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *repository.PaymentFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SavePayment provides a mock function with given fields: ctx, tx, payment
+func (_m *PaymentRepository) SavePayment(ctx context.Context, tx *sql.Tx, payment *repository.PaymentEntity) error {
+	ret := _m.Called(ctx, tx, payment)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SavePayment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, *repository.PaymentEntity) error); ok {
+		r0 = rf(ctx, tx, payment)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdatePayment provides a mock function with given fields: ctx, tx, payment
+func (_m *PaymentRepository) UpdatePayment(ctx context.Context, tx *sql.Tx, payment *repository.PaymentEntityUpdate) error {
+	ret := _m.Called(ctx, tx, payment)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePayment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, *repository.PaymentEntityUpdate) error); ok {
+		r0 = rf(ctx, tx, payment)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewPaymentRepository creates a new instance of PaymentRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPaymentRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PaymentRepository {
+	mock := &PaymentRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,66 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	sql "database/sql"
+
+	mock "github.com/stretchr/testify/mock"
+	repository "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+// PaymentReversalRepository is an autogenerated mock type for the PaymentReversalRepository type
+type PaymentReversalRepository struct {
+	mock.Mock
+}
+
+// SaveReversal provides a mock function with given fields: ctx, tx, reversal
+func (_m *PaymentReversalRepository) SaveReversal(ctx context.Context, tx *sql.Tx, reversal *repository.PaymentReversalEntity) error {
+	ret := _m.Called(ctx, tx, reversal)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveReversal")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, *repository.PaymentReversalEntity) error); ok {
+		r0 = rf(ctx, tx, reversal)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateReversal provides a mock function with given fields: ctx, reversal
+func (_m *PaymentReversalRepository) UpdateReversal(ctx context.Context, reversal *repository.PaymentReversalUpdate) error {
+	ret := _m.Called(ctx, reversal)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateReversal")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.PaymentReversalUpdate) error); ok {
+		r0 = rf(ctx, reversal)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewPaymentReversalRepository creates a new instance of PaymentReversalRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPaymentReversalRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PaymentReversalRepository {
+	mock := &PaymentReversalRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
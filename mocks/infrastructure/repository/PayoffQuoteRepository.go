@@ -0,0 +1,96 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	sql "database/sql"
+
+	mock "github.com/stretchr/testify/mock"
+	repository "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+// PayoffQuoteRepository is an autogenerated mock type for the PayoffQuoteRepository type
+type PayoffQuoteRepository struct {
+	mock.Mock
+}
+
+// FindQuote provides a mock function with given fields: ctx, quoteID
+func (_m *PayoffQuoteRepository) FindQuote(ctx context.Context, quoteID string) (*repository.PayoffQuoteEntity, error) {
+	ret := _m.Called(ctx, quoteID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindQuote")
+	}
+
+	var r0 *repository.PayoffQuoteEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*repository.PayoffQuoteEntity, error)); ok {
+		return rf(ctx, quoteID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *repository.PayoffQuoteEntity); ok {
+		r0 = rf(ctx, quoteID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.PayoffQuoteEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, quoteID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveQuote provides a mock function with given fields: ctx, quote
+func (_m *PayoffQuoteRepository) SaveQuote(ctx context.Context, quote *repository.PayoffQuoteEntity) error {
+	ret := _m.Called(ctx, quote)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveQuote")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.PayoffQuoteEntity) error); ok {
+		r0 = rf(ctx, quote)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateQuote provides a mock function with given fields: ctx, tx, quote
+func (_m *PayoffQuoteRepository) UpdateQuote(ctx context.Context, tx *sql.Tx, quote *repository.PayoffQuoteUpdate) error {
+	ret := _m.Called(ctx, tx, quote)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateQuote")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, *repository.PayoffQuoteUpdate) error); ok {
+		r0 = rf(ctx, tx, quote)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewPayoffQuoteRepository creates a new instance of PayoffQuoteRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPayoffQuoteRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PayoffQuoteRepository {
+	mock := &PayoffQuoteRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,105 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+	repository "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+// ProductRepository is an autogenerated mock type for the ProductRepository type
+type ProductRepository struct {
+	mock.Mock
+}
+
+// FindProducts provides a mock function with given fields: ctx, filter
+func (_m *ProductRepository) FindProducts(ctx context.Context, filter *repository.ProductFilter) ([]*repository.ProductEntity, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindProducts")
+	}
+
+	var r0 []*repository.ProductEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.ProductFilter) ([]*repository.ProductEntity, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.ProductFilter) []*repository.ProductEntity); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*repository.ProductEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *repository.ProductFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RetireProduct provides a mock function with given fields: ctx, code
+func (_m *ProductRepository) RetireProduct(ctx context.Context, code string) error {
+	ret := _m.Called(ctx, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetireProduct")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, code)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveProduct provides a mock function with given fields: ctx, product
+func (_m *ProductRepository) SaveProduct(ctx context.Context, product *repository.ProductEntity) (uint64, error) {
+	ret := _m.Called(ctx, product)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveProduct")
+	}
+
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.ProductEntity) (uint64, error)); ok {
+		return rf(ctx, product)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.ProductEntity) uint64); ok {
+		r0 = rf(ctx, product)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *repository.ProductEntity) error); ok {
+		r1 = rf(ctx, product)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewProductRepository creates a new instance of ProductRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewProductRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ProductRepository {
+	mock := &ProductRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
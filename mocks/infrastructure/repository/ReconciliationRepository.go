@@ -0,0 +1,55 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	sql "database/sql"
+
+	mock "github.com/stretchr/testify/mock"
+	repository "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+// ReconciliationRepository is an autogenerated mock type for the ReconciliationRepository type
+type ReconciliationRepository struct {
+	mock.Mock
+}
+
+// SaveItems provides a mock function with given fields: ctx, tx, items
+func (_m *ReconciliationRepository) SaveItems(ctx context.Context, tx *sql.Tx, items ...*repository.ReconciliationItemEntity) error {
+	_va := make([]interface{}, len(items))
+	for _i := range items {
+		_va[_i] = items[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, tx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveItems")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, ...*repository.ReconciliationItemEntity) error); ok {
+		r0 = rf(ctx, tx, items...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewReconciliationRepository creates a new instance of ReconciliationRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewReconciliationRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ReconciliationRepository {
+	mock := &ReconciliationRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
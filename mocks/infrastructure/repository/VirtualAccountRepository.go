@@ -0,0 +1,143 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+	repository "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+// VirtualAccountRepository is an autogenerated mock type for the VirtualAccountRepository type
+type VirtualAccountRepository struct {
+	mock.Mock
+}
+
+// FindCredits provides a mock function with given fields: ctx, filter
+func (_m *VirtualAccountRepository) FindCredits(ctx context.Context, filter *repository.VirtualAccountCreditFilter) ([]*repository.VirtualAccountCreditEntity, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindCredits")
+	}
+
+	var r0 []*repository.VirtualAccountCreditEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.VirtualAccountCreditFilter) ([]*repository.VirtualAccountCreditEntity, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.VirtualAccountCreditFilter) []*repository.VirtualAccountCreditEntity); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*repository.VirtualAccountCreditEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *repository.VirtualAccountCreditFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindVirtualAccounts provides a mock function with given fields: ctx, filter
+func (_m *VirtualAccountRepository) FindVirtualAccounts(ctx context.Context, filter *repository.VirtualAccountFilter) ([]*repository.VirtualAccountEntity, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindVirtualAccounts")
+	}
+
+	var r0 []*repository.VirtualAccountEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.VirtualAccountFilter) ([]*repository.VirtualAccountEntity, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.VirtualAccountFilter) []*repository.VirtualAccountEntity); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*repository.VirtualAccountEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *repository.VirtualAccountFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveCredit provides a mock function with given fields: ctx, credit
+func (_m *VirtualAccountRepository) SaveCredit(ctx context.Context, credit *repository.VirtualAccountCreditEntity) error {
+	ret := _m.Called(ctx, credit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveCredit")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.VirtualAccountCreditEntity) error); ok {
+		r0 = rf(ctx, credit)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveVirtualAccount provides a mock function with given fields: ctx, virtualAccount
+func (_m *VirtualAccountRepository) SaveVirtualAccount(ctx context.Context, virtualAccount *repository.VirtualAccountEntity) error {
+	ret := _m.Called(ctx, virtualAccount)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveVirtualAccount")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.VirtualAccountEntity) error); ok {
+		r0 = rf(ctx, virtualAccount)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateCredit provides a mock function with given fields: ctx, credit
+func (_m *VirtualAccountRepository) UpdateCredit(ctx context.Context, credit *repository.VirtualAccountCreditUpdate) error {
+	ret := _m.Called(ctx, credit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCredit")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.VirtualAccountCreditUpdate) error); ok {
+		r0 = rf(ctx, credit)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewVirtualAccountRepository creates a new instance of VirtualAccountRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewVirtualAccountRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *VirtualAccountRepository {
+	mock := &VirtualAccountRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
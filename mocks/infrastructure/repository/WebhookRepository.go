@@ -0,0 +1,160 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+	repository "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/repository"
+)
+
+// WebhookRepository is an autogenerated mock type for the WebhookRepository type
+type WebhookRepository struct {
+	mock.Mock
+}
+
+// FindDeliveries provides a mock function with given fields: ctx, filter
+func (_m *WebhookRepository) FindDeliveries(ctx context.Context, filter *repository.WebhookDeliveryFilter) ([]*repository.WebhookDeliveryEntity, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindDeliveries")
+	}
+
+	var r0 []*repository.WebhookDeliveryEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.WebhookDeliveryFilter) ([]*repository.WebhookDeliveryEntity, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.WebhookDeliveryFilter) []*repository.WebhookDeliveryEntity); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*repository.WebhookDeliveryEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *repository.WebhookDeliveryFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindSubscriptions provides a mock function with given fields: ctx, filter
+func (_m *WebhookRepository) FindSubscriptions(ctx context.Context, filter *repository.WebhookSubscriptionFilter) ([]*repository.WebhookSubscriptionEntity, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindSubscriptions")
+	}
+
+	var r0 []*repository.WebhookSubscriptionEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.WebhookSubscriptionFilter) ([]*repository.WebhookSubscriptionEntity, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.WebhookSubscriptionFilter) []*repository.WebhookSubscriptionEntity); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*repository.WebhookSubscriptionEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *repository.WebhookSubscriptionFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveDeliveries provides a mock function with given fields: ctx, deliveries
+func (_m *WebhookRepository) SaveDeliveries(ctx context.Context, deliveries ...*repository.WebhookDeliveryEntity) error {
+	_va := make([]interface{}, len(deliveries))
+	for _i := range deliveries {
+		_va[_i] = deliveries[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveDeliveries")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, ...*repository.WebhookDeliveryEntity) error); ok {
+		r0 = rf(ctx, deliveries...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveSubscription provides a mock function with given fields: ctx, subscription
+func (_m *WebhookRepository) SaveSubscription(ctx context.Context, subscription *repository.WebhookSubscriptionEntity) (uint64, error) {
+	ret := _m.Called(ctx, subscription)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveSubscription")
+	}
+
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.WebhookSubscriptionEntity) (uint64, error)); ok {
+		return rf(ctx, subscription)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.WebhookSubscriptionEntity) uint64); ok {
+		r0 = rf(ctx, subscription)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *repository.WebhookSubscriptionEntity) error); ok {
+		r1 = rf(ctx, subscription)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateDelivery provides a mock function with given fields: ctx, delivery
+func (_m *WebhookRepository) UpdateDelivery(ctx context.Context, delivery *repository.WebhookDeliveryUpdate) error {
+	ret := _m.Called(ctx, delivery)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateDelivery")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.WebhookDeliveryUpdate) error); ok {
+		r0 = rf(ctx, delivery)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewWebhookRepository creates a new instance of WebhookRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWebhookRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WebhookRepository {
+	mock := &WebhookRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
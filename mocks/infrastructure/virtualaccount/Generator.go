@@ -0,0 +1,105 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	virtualaccount "gitlab.com/2024/Juni/amartha-billing-srv2/infrastructure/virtualaccount"
+)
+
+// Generator is an autogenerated mock type for the Generator type
+type Generator struct {
+	mock.Mock
+}
+
+// Banks provides a mock function with given fields:
+func (_m *Generator) Banks() []*virtualaccount.Bank {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Banks")
+	}
+
+	var r0 []*virtualaccount.Bank
+	if rf, ok := ret.Get(0).(func() []*virtualaccount.Bank); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*virtualaccount.Bank)
+		}
+	}
+
+	return r0
+}
+
+// Number provides a mock function with given fields: bankCode, key, attempt
+func (_m *Generator) Number(bankCode string, key string, attempt int) (string, error) {
+	ret := _m.Called(bankCode, key, attempt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Number")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, int) (string, error)); ok {
+		return rf(bankCode, key, attempt)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, int) string); ok {
+		r0 = rf(bankCode, key, attempt)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, int) error); ok {
+		r1 = rf(bankCode, key, attempt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Validate provides a mock function with given fields: bankCode, number
+func (_m *Generator) Validate(bankCode string, number string) (*virtualaccount.Bank, error) {
+	ret := _m.Called(bankCode, number)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Validate")
+	}
+
+	var r0 *virtualaccount.Bank
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (*virtualaccount.Bank, error)); ok {
+		return rf(bankCode, number)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *virtualaccount.Bank); ok {
+		r0 = rf(bankCode, number)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*virtualaccount.Bank)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(bankCode, number)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewGenerator creates a new instance of Generator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewGenerator(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Generator {
+	mock := &Generator{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}